@@ -1,5 +1,9 @@
 import { Command } from 'commander';
+import * as settings from './config/settings.js';
 import { APP_NAME, DESCRIPTION, DISPLAY_NAME } from './config/branding.js';
+import { getConfigPath, getProjectConfigPath } from './core/userdata.js';
+import { installMiddleware } from './commands/middleware.js';
+import { failError } from './ui/output.js';
 import {
   registerVersion,
   registerInit,
@@ -19,8 +23,46 @@ import {
   registerPrompt,
   registerUpdate,
   registerRebrand,
+  registerHealth,
+  registerTrust,
+  registerCache,
+  registerRollback,
+  registerGraph,
+  registerTutorial,
+  registerInfo,
+  registerSources,
+  registerDeps,
+  registerVerify,
+  registerOverrides,
+  registerRegistry,
+  registerTest,
+  registerValidate,
+  registerState,
+  registerOutput,
+  registerStats,
+  registerPack,
+  registerPreset,
+  registerWhich,
+  registerBackup,
+  registerImport,
+  registerExport,
+  registerServe,
+  registerDaemon,
+  registerSchedule,
+  registerScheduler,
+  registerPipe,
+  registerVersions,
+  registerGc,
+  registerProjects,
+  registerTemplate,
+  registerGithooks,
+  registerBench,
+  registerExplain,
+  registerUi,
 } from './commands/index.js';
 
+settings.init(getConfigPath(), getProjectConfigPath(process.cwd()));
+
 const program = new Command()
   .name(APP_NAME)
   .description(
@@ -30,6 +72,8 @@ const program = new Command()
   .enablePositionalOptions()
   .showHelpAfterError(true);
 
+installMiddleware(program);
+
 // Register all commands
 registerVersion(program);
 registerInit(program);
@@ -49,5 +93,48 @@ registerConfig(program);
 registerPrompt(program);
 registerUpdate(program);
 registerRebrand(program);
+registerHealth(program);
+registerTrust(program);
+registerCache(program);
+registerRollback(program);
+registerGraph(program);
+registerTutorial(program);
+registerInfo(program);
+registerSources(program);
+registerDeps(program);
+registerVerify(program);
+registerOverrides(program);
+registerRegistry(program);
+registerTest(program);
+registerValidate(program);
+registerState(program);
+registerOutput(program);
+registerStats(program);
+registerPack(program);
+registerPreset(program);
+registerWhich(program);
+registerBackup(program);
+registerImport(program);
+registerExport(program);
+registerServe(program);
+registerDaemon(program);
+registerSchedule(program);
+registerScheduler(program);
+registerPipe(program);
+registerVersions(program);
+registerGc(program);
+registerProjects(program);
+registerTemplate(program);
+registerGithooks(program);
+registerBench(program);
+registerExplain(program);
+registerUi(program);
 
-program.parse();
+// Async so the preAction middleware and async actions are awaited; a
+// rejection is reported like any command error rather than left unhandled
+try {
+  await program.parseAsync();
+} catch (err) {
+  failError(err);
+  process.exit(1);
+}
@@ -0,0 +1,74 @@
+import type { Command } from 'commander';
+import { resolve } from 'node:path';
+import { createBackup, restoreBackup, defaultBackupName } from '../core/backup.js';
+import { emitJson, wantsJson, ok, failError, warn, info } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+function collect(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+export function registerBackup(program: Command): void {
+  const cmd = program
+    .command('backup')
+    .description('Back up and restore the whole userdata tree (env, profiles, skill registries, state)');
+
+  cmd
+    .command('create')
+    .description('Write userdata to an archive encrypted with age')
+    .option('-o, --output <file>', 'Archive to write (default: agentx-backup-<date>.tar.gz.age)')
+    .option('-r, --recipient <key>', 'age recipient to encrypt to (repeatable; default: passphrase)', collect, [])
+    .option('--plaintext', 'Write an unencrypted archive, with every secret value blanked')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        if (opts.plaintext && opts.recipient.length > 0) {
+          throw new Error(t('backup.recipientConflict'));
+        }
+        const archive = resolve(opts.output ?? defaultBackupName(Boolean(opts.plaintext)));
+        const result = createBackup(archive, { plaintext: opts.plaintext, recipients: opts.recipient });
+        if (wantsJson(opts)) {
+          emitJson({ archive, ...result });
+          return;
+        }
+        ok(t('backup.created', { count: result.files, path: archive }));
+        if (result.encrypted) {
+          info(t('backup.encrypted'));
+        } else if (result.redacted > 0) {
+          warn(t('backup.redacted', { count: result.redacted }), 'backup');
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('restore')
+    .description('Restore userdata from an archive made by `backup create`')
+    .argument('<file>', 'Backup to restore')
+    .option('-i, --identity <file>', 'age identity file, for backups encrypted to a recipient')
+    .option('--force', 'Overwrite existing files')
+    .option('--json', 'Output as JSON')
+    .action((file, opts) => {
+      try {
+        const result = restoreBackup(resolve(file), { identity: opts.identity, overwrite: opts.force });
+        if (wantsJson(opts)) {
+          emitJson(result);
+          return;
+        }
+        ok(t('backup.restored', { count: result.files }));
+        if (result.activeProfile) info(t('backup.activeProfile', { profile: result.activeProfile }));
+        if (result.redacted > 0) {
+          warn(t('backup.missingSecrets', { count: result.redacted }), 'backup');
+        }
+        if (result.skipped.length > 0) {
+          warn(t('common.keptExisting', { count: result.skipped.length }), 'backup');
+          for (const f of result.skipped) console.log(`  ${f}`);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
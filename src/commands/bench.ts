@@ -0,0 +1,138 @@
+import type { Command } from 'commander';
+import { join } from 'node:path';
+import { existsSync, readFileSync, writeFileSync } from 'node:fs';
+import chalk from 'chalk';
+import yaml from 'js-yaml';
+import { benchSkill, compareBench, type BenchReport, type BenchStats, type BenchDelta } from '../core/bench.js';
+import {
+  parseInputArgs,
+  readInputFile,
+  mergeInputs,
+  validateInputs,
+  INPUT_PRECEDENCE,
+} from '../utils/input-parser.js';
+import { formatBytes, parseCount } from '../utils/units.js';
+import { getInstalledRoot } from '../core/userdata.js';
+import { emitJson, wantsJson, ok, failError, info, warn } from '../ui/output.js';
+import { printTable } from '../ui/table.js';
+import { t } from '../ui/i18n.js';
+import { locateSkill } from './test.js';
+import { enforceManifestGuard } from './run.js';
+import type { SkillManifest } from '../types/manifest.js';
+
+export function registerBench(program: Command): void {
+  program
+    .command('bench')
+    .description('Run a skill repeatedly and report wall time, CPU time, and output size percentiles')
+    .argument('<skill>', 'Installed skill type path, or a skill source directory')
+    .option('-n, --iterations <n>', 'Measured runs', '20')
+    .option('--warmup <n>', 'Unmeasured runs first, to warm caches', '1')
+    .option('-i, --input <key=value...>', 'Input key=value pairs', collectInputs, [])
+    .option('--input-file <path>', 'Read inputs from a YAML or JSON file (-i flags override it)')
+    .option('-o, --output <file>', 'Also write the JSON report to a file')
+    .option('--baseline <file>', 'Compare against a saved JSON report')
+    .option('--json', 'Output as JSON')
+    .action(async (skill, opts) => {
+      try {
+        const iterations = parseCount(opts.iterations, '--iterations');
+        const warmup = parseCount(opts.warmup, '--warmup', 0);
+        const skillDir = locateSkill(skill);
+        const manifestPath = join(skillDir, 'skill.yaml');
+        if (!existsSync(manifestPath)) {
+          throw new Error(t('test.notSkill', { skill }));
+        }
+        // An installed skill is checked as `run` checks it; a source
+        // directory has no install record to compare with
+        const installedRoot = getInstalledRoot();
+        if (skillDir === join(installedRoot, skill)) {
+          enforceManifestGuard(skill, installedRoot, { dir: skillDir, version: null });
+        }
+        const manifest = yaml.load(readFileSync(manifestPath, 'utf-8')) as SkillManifest;
+
+        const inputs = mergeInputs(
+          {
+            flags: parseInputArgs(opts.input, manifest.inputs),
+            file: opts.inputFile ? readInputFile(opts.inputFile) : undefined,
+          },
+          manifest.inputs,
+        );
+        const errors = manifest.inputs ? validateInputs(inputs, manifest.inputs) : [];
+        if (errors.length > 0) {
+          throw new Error(`${errors.join('; ')}. ${t('common.inputPrecedence', { order: INPUT_PRECEDENCE })}`);
+        }
+        const baseline = opts.baseline
+          ? (JSON.parse(readFileSync(opts.baseline, 'utf-8')) as BenchReport)
+          : null;
+
+        const json = wantsJson(opts);
+        const report = await benchSkill(skill, skillDir, manifest, inputs, {
+          iterations,
+          warmup,
+          onRun: json ? undefined : (run, i) => {
+            const mark = run.exitCode === 0 ? chalk.dim('·') : chalk.red('✗');
+            process.stderr.write(`\r${mark} ${t('bench.progress', { n: i + 1, total: iterations })} ${chalk.dim(`(${Math.round(run.wallMs)}ms)`)}   `);
+          },
+        });
+        if (!json) process.stderr.write('\n');
+
+        const deltas = baseline ? compareBench(baseline, report) : [];
+        if (opts.output) writeFileSync(opts.output, JSON.stringify(report, null, 2) + '\n');
+
+        if (json) {
+          emitJson(baseline ? { ...report, baseline: { version: baseline.version, deltas } } : report);
+        } else {
+          printBench(report);
+          if (baseline) printDeltas(baseline, deltas);
+          if (opts.output) ok(t('bench.written', { path: opts.output }));
+        }
+        if (report.failed > 0) {
+          if (!json) warn(t('bench.failed', { failed: report.failed, total: report.iterations }), 'bench');
+          process.exitCode = 1;
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
+
+function collectInputs(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+function statRow(label: string, s: BenchStats, fmt: (n: number) => string): string[] {
+  return [label, fmt(s.min), fmt(s.mean), fmt(s.p50), fmt(s.p90), fmt(s.p95), fmt(s.p99), fmt(s.max)];
+}
+
+const ms = (n: number) => `${Math.round(n)}ms`;
+
+function printBench(report: BenchReport): void {
+  info(
+    t('bench.summary', {
+      skill: report.skill,
+      version: report.version,
+      runtime: report.runtime,
+      iterations: report.iterations,
+      warmup: report.warmup,
+    }),
+  );
+  const rows = [statRow('wall', report.wallMs, ms)];
+  if (report.cpuMs) rows.push(statRow('cpu', report.cpuMs, ms));
+  rows.push(statRow('output', report.outputBytes, (n) => formatBytes(Math.round(n))));
+  printTable(['', 'min', 'mean', 'p50', 'p90', 'p95', 'p99', 'max'], rows);
+  if (!report.cpuMs) console.log(chalk.dim(t('bench.noCpu')));
+}
+
+function printDeltas(baseline: BenchReport, deltas: BenchDelta[]): void {
+  console.log();
+  info(t('bench.against', { skill: baseline.skill, version: baseline.version }));
+  const fmt = (metric: BenchDelta['metric'], n: number) => (metric === 'outputBytes' ? formatBytes(Math.round(n)) : ms(n));
+  printTable(
+    ['', t('bench.col.baseline'), t('bench.col.current'), t('bench.col.change')],
+    deltas.map((d) => {
+      const pct = `${d.change > 0 ? '+' : ''}${(d.change * 100).toFixed(1)}%`;
+      const change = d.change > 0.1 ? chalk.red(pct) : d.change < -0.1 ? chalk.green(pct) : pct;
+      return [`${d.metric} ${d.stat}`, fmt(d.metric, d.baseline), fmt(d.metric, d.current), change];
+    }),
+  );
+}
@@ -0,0 +1,159 @@
+import type { Command } from 'commander';
+import { spawn } from 'node:child_process';
+import { statSync } from 'node:fs';
+import { runCacheStats, clearRunCache } from '../core/run-cache.js';
+import { refreshRegistryCache, clearRegistryCache, defaultCachePath } from '../core/registry.js';
+import { buildSources } from '../core/extension.js';
+import { clearRemoteCache } from '../core/context-sources.js';
+import * as settings from '../config/settings.js';
+import { findRepoRoot } from '../utils/git.js';
+import { ok, info, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { APP_NAME } from '../config/branding.js';
+import { printTable } from '../ui/table.js';
+import { clearEmbeddingIndexes } from '../core/embeddings.js';
+import { clearNodeCache, nodeCacheStats } from '../core/node-cache.js';
+import { formatBytes, parseDuration } from '../utils/units.js';
+import { logger } from '../utils/log.js';
+
+/** Whether the registry index is older than cache.refresh_interval, or missing. */
+function indexIsStale(): boolean {
+  let mtimeMs: number;
+  try {
+    mtimeMs = statSync(defaultCachePath()).mtimeMs;
+  } catch {
+    return true;
+  }
+  const raw = settings.get('cache.refresh_interval') || '1h';
+  let intervalMs: number;
+  try {
+    intervalMs = parseDuration(raw);
+  } catch {
+    logger('cache').warn(`Ignoring invalid cache.refresh_interval "${raw}"`);
+    intervalMs = parseDuration('1h');
+  }
+  return Date.now() - mtimeMs >= intervalMs;
+}
+
+/**
+ * Rebuilds the registry index in a detached process so the next search
+ * is fast without delaying the current command. Off unless
+ * `agentx config set cache.background_refresh true`; otherwise the next
+ * search refreshes the index itself. After a change to the sources the
+ * index is rebuilt regardless of age; otherwise (during a run) only once
+ * it is older than cache.refresh_interval.
+ */
+export function refreshCacheInBackground(opts: { sourcesChanged?: boolean } = {}): void {
+  if (settings.get('cache.background_refresh') !== 'true') return;
+  if (!opts.sourcesChanged && !indexIsStale()) return;
+  try {
+    const child = spawn(process.execPath, [process.argv[1], 'cache', 'refresh', '--quiet'], {
+      detached: true,
+      stdio: 'ignore',
+    });
+    child.unref();
+  } catch {
+    // Best-effort; the next search refreshes synchronously
+  }
+}
+
+export function registerCache(program: Command): void {
+  const cmd = program
+    .command('cache')
+    .description('Inspect and manage local caches');
+
+  cmd
+    .command('refresh')
+    .description('Rebuild the registry index from all sources')
+    .option('--quiet', 'Print nothing on success')
+    .action(async (opts) => {
+      try {
+        const types = await refreshRegistryCache(buildSources(findRepoRoot() ?? process.cwd()));
+        if (!opts.quiet) ok(t('cache.rebuilt', { count: types.length }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('clear')
+    .description('Delete cached data (all caches unless one is selected)')
+    .option('--registry', 'Registry index of available types')
+    .option('--runs', 'Run cache for this workspace')
+    .option('--remote', 'Downloaded remote context sources')
+    .option('--embeddings', 'Semantic search embedding indexes')
+    .option('--node', 'Shared node_modules of Node skills; never cleared by default, as installed skills link to it')
+    .action((opts) => {
+      try {
+        const all = !opts.registry && !opts.runs && !opts.remote && !opts.embeddings && !opts.node;
+        const cleared: string[] = [];
+        if (all || opts.registry) {
+          clearRegistryCache();
+          cleared.push(t('cache.name.registry'));
+        }
+        if (all || opts.runs) {
+          clearRunCache(findRepoRoot() ?? process.cwd());
+          cleared.push(t('cache.name.run'));
+        }
+        if (all || opts.remote) {
+          clearRemoteCache();
+          cleared.push(t('cache.name.remote'));
+        }
+        if (all || opts.embeddings) {
+          clearEmbeddingIndexes();
+          cleared.push(t('cache.name.embedding'));
+        }
+        if (opts.node) {
+          clearNodeCache();
+          cleared.push('node_modules');
+        }
+        ok(t('cache.cleared', { caches: cleared.join(', ') }));
+        if (opts.node) info(t('cache.reinstallNode', { command: `${APP_NAME} install <type-path>` }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('stats')
+    .description('Show run cache size and hit rates for this workspace, and the shared node_modules size')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const stats = runCacheStats(findRepoRoot() ?? process.cwd());
+        const node = nodeCacheStats();
+        if (wantsJson(opts)) {
+          emitJson({ ...stats, node });
+          return;
+        }
+
+        const row = (label: string, value: string) => console.log(`${`${label}:`.padEnd(11)}${value}`);
+        row(t('cache.stats.workspace'), stats.workspace);
+        row(t('cache.stats.entries'), t('cache.stats.entriesValue', { entries: stats.entries, blobs: stats.blobs, size: formatBytes(stats.bytes) }));
+        row(t('col.hitRate'), t('cache.stats.hitRateValue', { rate: percent(stats.hitRate), hits: stats.hits, misses: stats.misses }));
+        row(t('cache.stats.node'), t('cache.stats.nodeValue', { entries: node.entries, size: formatBytes(node.bytes) }));
+
+        const skills = Object.entries(stats.skills);
+        if (skills.length === 0) return;
+        console.log('');
+        printTable(
+          [t('col.skill'), t('col.hits'), t('col.misses'), t('col.hitRate')],
+          skills.map(([name, s]) => [
+            name,
+            String(s.hits),
+            String(s.misses),
+            percent(s.hits / Math.max(1, s.hits + s.misses)),
+          ]),
+        );
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
+
+function percent(ratio: number): string {
+  return `${Math.round(ratio * 100)}%`;
+}
@@ -11,8 +11,17 @@ import {
   repoURL,
 } from '../core/catalog.js';
 import { APP_NAME } from '../config/branding.js';
-import { ok, warn, fail } from '../ui/output.js';
+import { catalogStats, type CatalogStats } from '../core/catalog-stats.js';
+import { buildSources } from '../core/extension.js';
+import { findRepoRoot } from '../utils/git.js';
+import { parseCount } from '../utils/units.js';
+import { ok, info, warn, fail, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
 import { withSpinner } from '../ui/spinner.js';
+import { mirrorUrl, bundleUrl, createBundle } from '../core/mirror.js';
+import { isOffline, offlineSkip } from '../core/offline.js';
+import { refreshCacheInBackground } from './cache.js';
 
 export function registerCatalog(program: Command): void {
   const cmd = program
@@ -25,16 +34,21 @@ export function registerCatalog(program: Command): void {
     .action(async () => {
       const mode = detectMode();
       if (mode === 'platform-team') {
-        console.log(`Platform-team mode: use \`git pull\` in your catalog repository.`);
+        console.log(t('catalog.platformTeam'));
+        return;
+      }
+      if (isOffline()) {
+        info(offlineSkip('catalog update'));
         return;
       }
 
       const catalogRepoDir = getCatalogRepoRoot();
       try {
-        await withSpinner('Updating catalog...', () => update(catalogRepoDir));
-        ok('Catalog updated.');
+        await withSpinner(t('catalog.updating'), () => update(catalogRepoDir));
+        ok(t('catalog.updated'));
+        refreshCacheInBackground({ sourcesChanged: true });
       } catch (err) {
-        fail(`Failed to update catalog: ${err}`);
+        fail(t('catalog.updateFailed', { error: String(err) }));
         process.exit(1);
       }
     });
@@ -47,24 +61,106 @@ export function registerCatalog(program: Command): void {
       const catalogRepoDir = getCatalogRepoRoot();
       const exists = catalogExists();
 
-      console.log(`  Mode:     ${mode}`);
-      console.log(`  Path:     ${catalogRepoDir}`);
-      console.log(`  Repo URL: ${repoURL()}`);
+      const row = (label: string, value: string) => console.log(`  ${`${label}:`.padEnd(10)}${value}`);
+      row(t('catalog.status.mode'), mode);
+      row(t('catalog.status.path'), catalogRepoDir);
+      row(t('catalog.status.repo'), repoURL());
+      const mirror = mirrorUrl();
+      if (mirror) row(t('catalog.status.mirror'), bundleUrl(mirror, 'catalog', 'main'));
 
       if (!exists) {
-        warn(`Catalog not installed. Run \`${APP_NAME} catalog update\` to clone.`);
+        warn(t('catalog.notInstalled', { command: `${APP_NAME} catalog update` }));
         return;
       }
 
       const lastUpdated = readFreshnessMarker(catalogRepoDir);
       const age = Date.now() - lastUpdated.getTime();
       const days = Math.floor(age / (1000 * 60 * 60 * 24));
-      console.log(`  Updated:  ${lastUpdated.toISOString()} (${days} days ago)`);
+      row(t('catalog.status.updated'), t('catalog.daysAgo', { date: lastUpdated.toISOString(), days }));
 
       if (isStale(catalogRepoDir)) {
-        warn(`Catalog is stale. Run \`${APP_NAME} catalog update\`.`);
+        warn(t('catalog.stale', { command: `${APP_NAME} catalog update` }));
       } else {
-        ok('Catalog is up to date.');
+        ok(t('catalog.upToDate'));
+      }
+    });
+
+  cmd
+    .command('bundle')
+    .description('Pack a catalog or extension checkout into a bundle for a mirror')
+    .argument('[dir]', 'Checkout to pack (default: the local catalog)')
+    .requiredOption('-o, --output <file>', 'Bundle to write, e.g. main.tar.gz')
+    .action((dir: string | undefined, opts) => {
+      try {
+        createBundle(dir ?? getCatalogRepoRoot(), opts.output);
+        ok(t('catalog.bundleWritten', { path: opts.output }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('stats')
+    .description('Count types by category, topic, and vendor, and list the ones that need curating')
+    .option('--source <name>', 'Only this source (catalog or an extension)')
+    .option('--top <n>', 'Largest context types to show', '10')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const top = parseCount(opts.top, '--top');
+        let sources = buildSources(findRepoRoot() ?? process.cwd());
+        if (opts.source) {
+          sources = sources.filter((s) => s.name === opts.source);
+          if (sources.length === 0) throw new Error(t('common.unknownSource', { source: opts.source }));
+        }
+        const warnings: string[] = [];
+        const stats = catalogStats(sources, warnings);
+        for (const w of warnings) warn(w, 'registry');
+        if (wantsJson(opts)) {
+          emitJson(stats);
+          return;
+        }
+        printStats(stats, top);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
       }
     });
 }
+
+function printCounts(title: string, counts: Record<string, number>): void {
+  console.log(`\n${title}`);
+  printTable(
+    ['', t('catalog.col.types')],
+    Object.entries(counts)
+      .sort(([a, x], [b, y]) => y - x || a.localeCompare(b))
+      .map(([k, n]) => [k, String(n)]),
+  );
+}
+
+function printList(title: string, paths: string[]): void {
+  if (paths.length === 0) return;
+  console.log(`\n${title} (${paths.length})`);
+  for (const p of paths) console.log(`  ${p}`);
+}
+
+function printStats(stats: CatalogStats, top: number): void {
+  info(t('catalog.total', { count: stats.total, sources: stats.sources.join(', ') || t('catalog.noSources') }));
+  printCounts(t('catalog.byCategory'), stats.byCategory);
+  printCounts(t('catalog.byTopic'), stats.byTopic);
+  printCounts(t('catalog.byVendor'), stats.byVendor);
+
+  console.log(`\n${t('catalog.contextTokens', { tokens: stats.totalContextTokens, count: stats.contextTokens.length })}`);
+  if (stats.contextTokens.length > 0) {
+    printTable(
+      [t('catalog.col.context'), t('catalog.col.tokens')],
+      stats.contextTokens.slice(0, top).map((c) => [c.typePath, String(c.tokens)]),
+    );
+  }
+
+  printList(t('catalog.missingDescription'), stats.missingDescription);
+  printList(t('catalog.missingTags'), stats.missingTags);
+  printList(t('catalog.withoutTests'), stats.skillsWithoutTests);
+  printList(t('catalog.orphans'), stats.orphans);
+}
@@ -1,32 +1,108 @@
 import type { Command } from 'commander';
 import * as settings from '../config/settings.js';
-import { getConfigPath } from '../core/userdata.js';
+import { SETTINGS, parseSetting, settingSpec } from '../config/keys.js';
+import { ok, info, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+/** The layer a write goes to: the user's config unless --project. */
+function scopeOf(opts: { project?: boolean }): settings.Scope {
+  return opts.project ? 'project' : 'global';
+}
+
+function display(value: unknown): string {
+  return Array.isArray(value) ? value.join(',') : String(value);
+}
 
 export function registerConfig(program: Command): void {
   const cmd = program
     .command('config')
-    .description('Manage user settings');
+    .description('Manage user and project settings');
 
   cmd
     .command('set')
     .description('Set a config value')
-    .argument('<key>', 'Config key')
-    .argument('<value>', 'Config value')
-    .action((key, value) => {
-      settings.init(getConfigPath());
-      settings.set(key, value);
-      console.log(`Set ${key} = ${value}`);
+    .argument('<key>', 'Config key (see `config list --all`)')
+    .argument('<value>', 'Config value; lists are comma-separated')
+    .option('--global', 'In ~/.agentx/config.yaml (default)')
+    .option('--project', "In this project's .agentx/config.yaml, overriding the user's")
+    .action((key: string, value: string, opts) => {
+      try {
+        const scope = scopeOf(opts);
+        settings.set(key, parseSetting(key, value), scope);
+        ok(t('config.set', { key, value, scope }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
     });
 
   cmd
     .command('get')
-    .description('Get a config value')
+    .description('Get a config value (project over user)')
+    .argument('<key>', 'Config key')
+    .option('--global', 'Only the user config')
+    .option('--project', 'Only the project config')
+    .action((key: string, opts) => {
+      try {
+        settingSpec(key);
+        const value = opts.global || opts.project ? settings.layer(scopeOf(opts))[key] : settings.all()[key];
+        if (value != null) console.log(display(value));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('unset')
+    .description('Remove a config value')
     .argument('<key>', 'Config key')
-    .action((key) => {
-      settings.init(getConfigPath());
-      const value = settings.get(key);
-      if (value) {
-        console.log(value);
+    .option('--global', 'From ~/.agentx/config.yaml (default)')
+    .option('--project', "From this project's .agentx/config.yaml")
+    .action((key: string, opts) => {
+      try {
+        const scope = scopeOf(opts);
+        if (settings.unset(key, scope)) ok(t('config.unset', { key, scope }));
+        else info(t('config.notSet', { key, scope }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('list')
+    .description('List config values and where each comes from')
+    .option('--all', 'Include unset known keys with their defaults')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const effective = settings.all();
+        const keys = opts.all
+          ? [...new Set([...Object.keys(SETTINGS), ...Object.keys(effective)])].sort()
+          : Object.keys(effective).sort();
+        const rows = keys.map((key) => ({
+          key,
+          value: effective[key] ?? null,
+          source: settings.origin(key) ?? 'default',
+          default: SETTINGS[key]?.default ?? null,
+          description: SETTINGS[key]?.description ?? '(unknown key)',
+        }));
+
+        if (wantsJson(opts)) {
+          emitJson(rows);
+        } else if (rows.length === 0) {
+          info(t('config.none'));
+        } else {
+          printTable(
+            [t('col.key'), t('col.value'), t('col.source'), t('col.description')],
+            rows.map((r) => [r.key, r.value == null ? (r.default ?? '') : display(r.value), r.source, r.description]),
+          );
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
       }
     });
 }
@@ -1,144 +1,294 @@
 import type { Command } from 'commander';
 import { join } from 'node:path';
-import { newScaffoldData, generate } from '../core/scaffold.js';
-import { ok, fail } from '../ui/output.js';
+import { readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import {
+  newScaffoldData,
+  generate,
+  cloneType,
+  loadTemplateSet,
+  listTemplateSets,
+  type GenerateOptions,
+  type ScaffoldResult,
+} from '../core/scaffold.js';
+import { resolveType, didYouMean, discoverTypes } from '../core/registry.js';
+import { buildSources } from '../core/extension.js';
+import { ensureTrusted } from '../core/trust.js';
+import { approveContribution } from './trust.js';
+import { getInstalledRoot } from '../core/userdata.js';
+import { findRepoRoot } from '../utils/git.js';
+import * as settings from '../config/settings.js';
+import type { ResolvedType } from '../types/registry.js';
+import { ok, failError, warn, info, wantsJson, emitJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 
 const NAME_PATTERN = /^[a-z0-9][a-z0-9-]*$/;
+const SKILL_RUNTIMES = ['node', 'python'];
 
 function validateName(name: string, label: string): void {
   if (!NAME_PATTERN.test(name)) {
-    throw new Error(
-      `Invalid ${label}: "${name}". Must be lowercase alphanumeric with hyphens.`,
-    );
+    throw new Error(t('create.invalidName', { label, name }));
   }
 }
 
+function report(typeName: string, result: ScaffoldResult): void {
+  ok(t('create.created', { type: typeName, path: result.outputDir }));
+  for (const f of result.files) console.log(`  ${f}`);
+  for (const step of result.steps) ok(step);
+  for (const w of result.warnings) warn(w, 'scaffold');
+}
+
+function collectVar(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+interface CreateFlags {
+  template?: string;
+  var: string[];
+  from?: string;
+  installDeps?: boolean;
+  runHooks?: boolean;
+  gitInit?: boolean;
+}
+
+/**
+ * Template and post-generation flags as generate options. A template set
+ * from an extension is shown for approval the first time it is used.
+ */
+async function generateOptions(opts: CreateFlags): Promise<GenerateOptions> {
+  if (opts.from && (opts.template || opts.var.length > 0)) {
+    throw new Error(t('create.fromConflict'));
+  }
+  const variables: Record<string, string> = {};
+  for (const pair of opts.var) {
+    const eq = pair.indexOf('=');
+    if (eq <= 0) throw new Error(t('create.invalidVar', { pair }));
+    variables[pair.slice(0, eq)] = pair.slice(eq + 1);
+  }
+  const contribution = opts.template ? loadTemplateSet(opts.template).contribution : undefined;
+  if (contribution) await ensureTrusted(contribution, approveContribution);
+  return {
+    template: opts.template,
+    variables,
+    installDeps: opts.installDeps,
+    runHooks: opts.runHooks,
+    gitInit: opts.gitInit,
+  };
+}
+
+/** The type to clone: the installed copy if present, else the catalog's. */
+function resolveFrom(typePath: string): ResolvedType {
+  const sources = [
+    { name: 'installed', basePath: getInstalledRoot() },
+    ...buildSources(findRepoRoot() ?? process.cwd()),
+  ];
+  const resolved = resolveType(typePath, sources);
+  if (!resolved) {
+    const known = discoverTypes(sources).map((d) => d.typePath);
+    throw new Error(t('common.typeNotFound', { type: typePath, hint: didYouMean(typePath, known) }));
+  }
+  return resolved;
+}
+
+/** The template, post-generation, and output flags every `create <type>` takes. */
+function withCreateOptions(cmd: Command, typeName: string): Command {
+  return cmd
+    .option('--from <type-path>', `Start from a copy of an existing ${typeName}`)
+    .option('--template <name>', 'Template set to scaffold from (see `create templates`)')
+    .option('--var <key=value>', 'Value for a template set variable (repeatable)', collectVar, [])
+    .option('--install-deps', 'Install dependencies after generating (npm, go, or a Python venv)')
+    .option('--run-hooks', "Run the template set's post-generation hooks")
+    .option('--git-init', 'Create a git repository with an initial commit')
+    .option('--output-dir <dir>', 'Output directory');
+}
+
 export function registerCreate(program: Command): void {
   const cmd = program
     .command('create')
     .description('Scaffold new types from templates');
 
   // ── create skill ──────────────────────────────────────────────
-  cmd
-    .command('skill')
+  withCreateOptions(cmd.command('skill'), 'skill')
     .description('Create a new skill')
     .argument('<name>', 'Skill name (kebab-case)')
-    .requiredOption('--topic <topic>', 'Skill topic (kebab-case)')
+    .option('--topic <topic>', 'Skill topic (kebab-case); required unless --from')
     .option('--vendor <vendor>', 'Vendor name')
-    .option('--runtime <runtime>', 'Runtime: node or go', 'node')
-    .option('--output-dir <dir>', 'Output directory')
-    .action((name, opts) => {
+    .option('--runtime <runtime>', `Runtime: ${SKILL_RUNTIMES.join(' or ')} (default: create.default_runtime, else node)`)
+    .action(async (name, opts) => {
       try {
+        const genOpts = await generateOptions(opts);
+        // A clone keeps the original's topic, vendor, and runtime unless overridden
+        const source = opts.from ? resolveFrom(opts.from) : null;
+        const original = source
+          ? ((yaml.load(readFileSync(source.manifestPath, 'utf-8')) as Record<string, string>) ?? {})
+          : {};
+        const topic = opts.topic ?? original.topic;
+        const vendor = opts.vendor ?? original.vendor ?? '';
+        // A template set built for one runtime implies it
+        const setRuntime = genOpts.template ? loadTemplateSet(genOpts.template).runtime : undefined;
+        const runtime = source
+          ? original.runtime
+          : (opts.runtime ?? setRuntime ?? (settings.get('create.default_runtime') || 'node'));
+
         validateName(name, 'name');
-        validateName(opts.topic, 'topic');
-        if (opts.vendor) validateName(opts.vendor, 'vendor');
-        const data = newScaffoldData(name, 'skill', opts.topic, opts.vendor ?? '', opts.runtime);
+        if (!topic) throw new Error(t('create.topicRequired'));
+        validateName(topic, 'topic');
+        if (vendor) validateName(vendor, 'vendor');
+        if (!source && !SKILL_RUNTIMES.includes(runtime)) {
+          throw new Error(t('create.unsupportedRuntime', { runtime, expected: SKILL_RUNTIMES.join(' | ') }));
+        }
+        if (source && opts.runtime && opts.runtime !== runtime) {
+          throw new Error(t('create.runtimeMismatch', { runtime: opts.runtime, target: source.typePath, actual: runtime }));
+        }
+        if (setRuntime && opts.runtime && opts.runtime !== setRuntime) {
+          throw new Error(t('create.runtimeMismatchSet', { runtime: opts.runtime, set: String(genOpts.template), actual: setRuntime }));
+        }
+        const data = newScaffoldData(name, 'skill', topic, vendor, runtime);
         const outDir = opts.outputDir ?? join(process.cwd(), name);
-        const result = generate('skill', data, outDir);
-        ok(`Created skill at ${result.outputDir}`);
-        for (const f of result.files) console.log(`  ${f}`);
+        const result = source ? cloneType('skill', source, data, outDir, genOpts) : generate('skill', data, outDir, genOpts);
+        report('skill', result);
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 
   // ── create workflow ───────────────────────────────────────────
-  cmd
-    .command('workflow')
+  withCreateOptions(cmd.command('workflow'), 'workflow')
     .description('Create a new workflow')
     .argument('<name>', 'Workflow name')
-    .option('--output-dir <dir>', 'Output directory')
-    .action((name, opts) => {
+    .action(async (name, opts) => {
       try {
         validateName(name, 'name');
+        const genOpts = await generateOptions(opts);
         const data = newScaffoldData(name, 'workflow', '', '', 'node');
         const outDir = opts.outputDir ?? join(process.cwd(), name);
-        const result = generate('workflow', data, outDir);
-        ok(`Created workflow at ${result.outputDir}`);
-        for (const f of result.files) console.log(`  ${f}`);
+        const result = opts.from
+          ? cloneType('workflow', resolveFrom(opts.from), data, outDir, genOpts)
+          : generate('workflow', data, outDir, genOpts);
+        report('workflow', result);
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 
   // ── create prompt ─────────────────────────────────────────────
-  cmd
-    .command('prompt')
+  withCreateOptions(cmd.command('prompt'), 'prompt')
     .description('Create a new prompt')
     .argument('<name>', 'Prompt name')
-    .option('--output-dir <dir>', 'Output directory')
-    .action((name, opts) => {
+    .action(async (name, opts) => {
       try {
         validateName(name, 'name');
+        const genOpts = await generateOptions(opts);
         const data = newScaffoldData(name, 'prompt', '', '', '');
         const outDir = opts.outputDir ?? join(process.cwd(), name);
-        const result = generate('prompt', data, outDir);
-        ok(`Created prompt at ${result.outputDir}`);
-        for (const f of result.files) console.log(`  ${f}`);
+        const result = opts.from
+          ? cloneType('prompt', resolveFrom(opts.from), data, outDir, genOpts)
+          : generate('prompt', data, outDir, genOpts);
+        report('prompt', result);
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 
   // ── create persona ────────────────────────────────────────────
-  cmd
-    .command('persona')
+  withCreateOptions(cmd.command('persona'), 'persona')
     .description('Create a new persona')
     .argument('<name>', 'Persona name')
-    .option('--output-dir <dir>', 'Output directory')
-    .action((name, opts) => {
+    .action(async (name, opts) => {
       try {
         validateName(name, 'name');
+        const genOpts = await generateOptions(opts);
         const data = newScaffoldData(name, 'persona', '', '', '');
         const outDir = opts.outputDir ?? join(process.cwd(), name);
-        const result = generate('persona', data, outDir);
-        ok(`Created persona at ${result.outputDir}`);
-        for (const f of result.files) console.log(`  ${f}`);
+        const result = opts.from
+          ? cloneType('persona', resolveFrom(opts.from), data, outDir, genOpts)
+          : generate('persona', data, outDir, genOpts);
+        report('persona', result);
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 
   // ── create context ────────────────────────────────────────────
-  cmd
-    .command('context')
+  withCreateOptions(cmd.command('context'), 'context')
     .description('Create a new context')
     .argument('<name>', 'Context name')
-    .option('--output-dir <dir>', 'Output directory')
-    .action((name, opts) => {
+    .action(async (name, opts) => {
       try {
         validateName(name, 'name');
+        const genOpts = await generateOptions(opts);
         const data = newScaffoldData(name, 'context', '', '', '');
         const outDir = opts.outputDir ?? join(process.cwd(), name);
-        const result = generate('context', data, outDir);
-        ok(`Created context at ${result.outputDir}`);
-        for (const f of result.files) console.log(`  ${f}`);
+        const result = opts.from
+          ? cloneType('context', resolveFrom(opts.from), data, outDir, genOpts)
+          : generate('context', data, outDir, genOpts);
+        report('context', result);
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 
   // ── create template ───────────────────────────────────────────
-  cmd
-    .command('template')
+  withCreateOptions(cmd.command('template'), 'template')
     .description('Create a new template')
     .argument('<name>', 'Template name')
-    .option('--output-dir <dir>', 'Output directory')
-    .action((name, opts) => {
+    .action(async (name, opts) => {
       try {
         validateName(name, 'name');
+        const genOpts = await generateOptions(opts);
         const data = newScaffoldData(name, 'template', '', '', '');
         const outDir = opts.outputDir ?? join(process.cwd(), name);
-        const result = generate('template', data, outDir);
-        ok(`Created template at ${result.outputDir}`);
-        for (const f of result.files) console.log(`  ${f}`);
+        const result = opts.from
+          ? cloneType('template', resolveFrom(opts.from), data, outDir, genOpts)
+          : generate('template', data, outDir, genOpts);
+        report('template', result);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  // ── create templates ──────────────────────────────────────────
+  cmd
+    .command('templates')
+    .description('List template sets, built-in and from ~/.agentx/templates')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const warnings: string[] = [];
+        const sets = listTemplateSets(warnings);
+        for (const w of warnings) warn(w, 'templates');
+        if (wantsJson(opts)) {
+          emitJson(sets.map(({ dir: _dir, contribution, ...set }) => ({ ...set, extension: contribution?.extension })));
+          return;
+        }
+        for (const set of sets) {
+          const origin = set.builtin
+            ? t('create.origin.builtin')
+            : set.contribution
+              ? t('create.origin.extension', { name: set.contribution.extension })
+              : t('create.origin.user');
+          console.log(`  ${set.name.padEnd(20)} ${set.type.padEnd(10)} ${origin}${set.description ? `  ${set.description}` : ''}`);
+          for (const v of set.variables) {
+            const detail = v.required
+              ? t('create.var.required')
+              : v.default !== undefined
+                ? t('create.var.default', { value: v.default })
+                : t('create.var.optional');
+            console.log(`      --var ${v.name}=…  (${detail})${v.description ? ` ${v.description}` : ''}`);
+          }
+          for (const hook of set.hooks) console.log(`      ${t('create.hook', { hook })}`);
+        }
+        if (sets.every((s) => s.builtin)) {
+          info(t('create.addOwn'));
+        }
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -0,0 +1,168 @@
+import type { Command } from 'commander';
+import { existsSync, readFileSync, watchFile, statSync, openSync, readSync, closeSync } from 'node:fs';
+import { getInstalledRoot } from '../core/userdata.js';
+import {
+  startDaemon,
+  stopDaemon,
+  superviseDaemon,
+  daemonStatus,
+  listDaemons,
+  daemonLogPath,
+  type DaemonStatus,
+} from '../core/daemon.js';
+import { parseInputArgs } from '../utils/input-parser.js';
+import { APP_NAME } from '../config/branding.js';
+import { ok, info, warn, fail, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+const DEFAULT_LOG_LINES = 50;
+
+function collectInputs(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+function describe(s: DaemonStatus): string {
+  if (s.running) return t('daemon.running', { pid: s.state?.childPid ?? '-', restarts: s.state?.restarts ?? 0 });
+  if (s.state?.gaveUp) return t('daemon.gaveUp');
+  const exit = s.state?.lastExit;
+  if (!exit) return t('daemon.stopped');
+  const how = exit.signal ? t('daemon.exitSignal', { signal: exit.signal }) : t('daemon.exitCode', { code: String(exit.code) });
+  return t('daemon.stoppedExit', { exit: how, at: exit.at });
+}
+
+/** Prints output appended to path from offset on, and returns the new end. */
+function printFrom(path: string, offset: number): number {
+  const size = statSync(path).size;
+  if (size < offset) offset = 0; // rotated
+  if (size === offset) return offset;
+  const buf = Buffer.alloc(size - offset);
+  const fd = openSync(path, 'r');
+  try {
+    readSync(fd, buf, 0, buf.length, offset);
+  } finally {
+    closeSync(fd);
+  }
+  process.stdout.write(buf);
+  return size;
+}
+
+export function registerDaemon(program: Command): void {
+  const cmd = program
+    .command('daemon')
+    .description('Run long-running (mode: daemon) skills in the background under a supervisor');
+
+  cmd
+    .command('start')
+    .description('Start a daemon skill in the background')
+    .argument('<type-path>', 'Installed skill with mode: daemon')
+    .option('-i, --input <key=value...>', 'Input key=value pairs', collectInputs, [])
+    .action((typePath, opts) => {
+      try {
+        const inputs: string[] = opts.input;
+        parseInputArgs(inputs);
+        const pid = startDaemon(typePath, getInstalledRoot(), {
+          command: process.execPath,
+          argv: [process.argv[1], 'daemon', 'run', typePath, ...inputs.flatMap((i) => ['-i', i])],
+        });
+        ok(t('daemon.started', { type: typePath, pid }));
+        info(t('daemon.followHint', { command: `${APP_NAME} daemon logs -f ${typePath}` }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('stop')
+    .description('Stop a daemon skill and its supervisor')
+    .argument('<type-path>', 'Daemon skill')
+    .action(async (typePath) => {
+      try {
+        if (await stopDaemon(typePath)) ok(t('daemon.stoppedType', { type: typePath }));
+        else info(t('daemon.notRunning', { type: typePath }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('status')
+    .description('Show whether daemon skills are running, with restarts and last exit')
+    .argument('[type-path]', 'One daemon skill (default: all started before)')
+    .option('--json', 'Output as JSON')
+    .action((typePath, opts) => {
+      try {
+        const statuses = typePath ? [daemonStatus(typePath)] : listDaemons();
+        if (wantsJson(opts)) {
+          emitJson(statuses);
+          return;
+        }
+        if (statuses.length === 0) {
+          console.log(t('daemon.none'));
+          return;
+        }
+        printTable(
+          [t('col.skill'), t('col.status'), t('col.since')],
+          statuses.map((s) => [s.typePath, describe(s), s.running ? (s.state?.startedAt ?? '') : '']),
+        );
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('logs')
+    .description("Print a daemon skill's captured output")
+    .argument('<type-path>', 'Daemon skill')
+    .option('-n, --lines <n>', 'Lines from the end', String(DEFAULT_LOG_LINES))
+    .option('-f, --follow', 'Keep printing new output until interrupted')
+    .action((typePath, opts) => {
+      try {
+        const path = daemonLogPath(typePath);
+        if (!existsSync(path)) {
+          info(t('daemon.noLog', { type: typePath }));
+          return;
+        }
+        const lines = readFileSync(path, 'utf-8').split('\n');
+        if (lines[lines.length - 1] === '') lines.pop();
+        const tail = lines.slice(-Math.max(0, Number(opts.lines)));
+        if (tail.length) console.log(tail.join('\n'));
+        if (!opts.follow) return;
+
+        if (!daemonStatus(typePath).running) warn(t('daemon.waiting', { type: typePath }), 'daemon');
+        let offset = statSync(path).size;
+        watchFile(path, { interval: 500 }, () => {
+          offset = printFrom(path, offset);
+        });
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('run')
+    .description('Supervise a daemon skill in the foreground (what `daemon start` runs; suits systemd)')
+    .argument('<type-path>', 'Installed skill with mode: daemon')
+    .option('-i, --input <key=value...>', 'Input key=value pairs', collectInputs, [])
+    .action(async (typePath, opts) => {
+      try {
+        const stop = new AbortController();
+        process.once('SIGTERM', () => stop.abort());
+        process.once('SIGINT', () => stop.abort());
+        const state = await superviseDaemon(typePath, getInstalledRoot(), parseInputArgs(opts.input), {
+          signal: stop.signal,
+        });
+        if (state.gaveUp) {
+          fail(t('daemon.keptCrashing', { type: typePath, restarts: state.restarts }));
+          process.exit(1);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -0,0 +1,59 @@
+import type { Command } from 'commander';
+import { getInstalledRoot } from '../core/userdata.js';
+import { buildSources } from '../core/extension.js';
+import { buildDependencyTree, findDependents, indexedDependents, printTree } from '../core/registry.js';
+import { findRepoRoot } from '../utils/git.js';
+import { emitJson, wantsJson, failError, info } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import type { DependencyNode, Source } from '../types/registry.js';
+
+// JSON view of a tree without the resolved manifest paths.
+function toJson(node: DependencyNode): unknown {
+  return {
+    typePath: node.typePath,
+    category: node.category,
+    installed: node.installed,
+    deduped: node.deduped,
+    children: node.children.map(toJson),
+  };
+}
+
+export function registerDeps(program: Command): void {
+  program
+    .command('deps')
+    .description('Show what a type depends on, or with --reverse, what depends on it')
+    .argument('<type-path>', 'Type path')
+    .option('--reverse', 'Show types that reference this type')
+    .option('--direct', 'With --reverse, only direct dependents')
+    .option('--installed', 'Only consider installed types')
+    .option('--json', 'Output as JSON')
+    .action(async (typePath, opts) => {
+      try {
+        const installedRoot = getInstalledRoot();
+        const installed: Source = { name: 'installed', basePath: installedRoot };
+        const sources = opts.installed
+          ? [installed]
+          : [...buildSources(findRepoRoot() ?? process.cwd()), installed];
+
+        const tree = opts.reverse
+          ? findDependents(typePath, sources, installedRoot, {
+              transitive: !opts.direct,
+              dependents: await indexedDependents(sources),
+            })
+          : buildDependencyTree(typePath, sources, installedRoot);
+
+        if (wantsJson(opts)) {
+          emitJson(toJson(tree));
+          return;
+        }
+        if (opts.reverse && tree.children.length === 0) {
+          info(t('deps.noDependents', { type: typePath }));
+          return;
+        }
+        console.log(printTree(tree).trimEnd());
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
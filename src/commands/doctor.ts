@@ -1,16 +1,37 @@
 import type { Command } from 'commander';
 import { execFileSync } from 'node:child_process';
-import { existsSync } from 'node:fs';
+import { existsSync, readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
 import {
   getInstalledRoot,
   getUserdataRoot,
   getSkillsDir,
   getCatalogRepoRoot,
   getExtensionsRoot,
+  getHomeRoot,
   detectMode,
+  checkUserdata,
+  repairUserdata,
+  ORPHAN_ACTIONS,
+  type OrphanAction,
+  type UserdataIssue,
 } from '../core/userdata.js';
+import { acquireLock } from '../core/lock.js';
 import { discoverTypes } from '../core/registry.js';
-import { ok, fail, warn, info } from '../ui/output.js';
+import { validateManifestFile, manifestErrors, formatIssue } from '../core/manifest.js';
+import { stateOverLimits } from '../core/state.js';
+import { formatBytes } from '../utils/units.js';
+import { scrubText } from '../utils/redact.js';
+import { ok, fail, failError, warn, info, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { askSelect } from '../ui/prompts.js';
+import type { MessageKey } from '../ui/messages/en.js';
+import type { DoctorCheckJson, DoctorReportJson, DoctorStatus } from '../types/output.js';
+
+interface Check extends DoctorCheckJson {
+  /** Printed below the check in text output (e.g. manifest issues). */
+  notes?: string[];
+}
 
 function checkCommand(name: string): boolean {
   try {
@@ -32,95 +53,227 @@ export function registerDoctor(program: Command): void {
     .option('--check-userdata', 'Check userdata directory')
     .option('--check-registry', 'Check skill registries')
     .option('--check-manifest <path>', 'Validate a specific manifest file')
-    .action((opts) => {
+    .option('--fix', 'Repair the userdata tree (missing dirs, active profile, permissions, orphaned registries)')
+    .option('--orphans <action>', `With --fix, what to do with orphaned skill registries: ${ORPHAN_ACTIONS.join(', ')} (default: ask)`)
+    .option('--json', 'Output as JSON')
+    .action(async (opts) => {
+      if (opts.orphans && !ORPHAN_ACTIONS.includes(opts.orphans)) {
+        fail(t('doctor.invalidOrphans', { value: opts.orphans, actions: ORPHAN_ACTIONS.join(', ') }));
+        process.exit(1);
+      }
+      let fixed: string[] | undefined;
+      if (opts.fix) {
+        try {
+          fixed = await fixUserdata(opts.orphans);
+        } catch (err) {
+          failError(err);
+          process.exit(1);
+        }
+      }
+
       const anyCheck = opts.checkCli || opts.checkRuntime || opts.checkLinks ||
         opts.checkExtensions || opts.checkUserdata || opts.checkRegistry || opts.checkManifest;
       const runAll = !anyCheck;
 
-      console.log('\nAgentX Doctor\n');
-      console.log(`  Mode: ${detectMode()}`);
-      console.log('');
-
-      // Runtime checks
-      if (runAll || opts.checkRuntime) {
-        console.log('Runtime:');
-        for (const cmd of ['node', 'npm', 'git']) {
-          if (checkCommand(cmd)) {
-            ok(`  ${cmd} — available`);
-          } else {
-            fail(`  ${cmd} — not found`);
-          }
-        }
-        console.log('');
-      }
+      let checks: Check[] = [];
+      if (runAll || opts.checkRuntime) checks.push(...runtimeChecks());
+      if (runAll || opts.checkUserdata) checks.push(...userdataChecks());
+      if (runAll || opts.checkCli) checks.push(...cliChecks());
+      if (runAll || opts.checkRegistry) checks.push(...stateChecks());
+      if (opts.checkManifest) checks.push(manifestCheck(opts.checkManifest));
+      // Details quote paths, config, and command output; mask secrets in them
+      checks = checks.map((c) => ({ ...c, detail: c.detail && scrubText(c.detail), notes: c.notes?.map(scrubText) }));
 
-      // Userdata checks
-      if (runAll || opts.checkUserdata) {
-        console.log('Userdata:');
-        for (const [label, path] of [
-          ['Userdata root', getUserdataRoot()],
-          ['Installed root', getInstalledRoot()],
-          ['Skills dir', getSkillsDir()],
-          ['Catalog repo', getCatalogRepoRoot()],
-        ] as const) {
-          if (existsSync(path)) {
-            ok(`  ${label} — ${path}`);
-          } else {
-            warn(`  ${label} — missing (${path})`);
-          }
-        }
-        console.log('');
+      const report: DoctorReportJson = {
+        mode: detectMode(),
+        healthy: checks.every((c) => c.status !== 'fail'),
+        checks: checks.map(({ notes, ...c }) => (notes ? { ...c, detail: [c.detail, ...notes].join('\n\n') } : c)),
+        ...(fixed ? { fixed } : {}),
+      };
+      if (wantsJson(opts)) {
+        emitJson(report);
+      } else {
+        if (fixed) printFixes(fixed);
+        printReport(report.mode, checks);
       }
+      if (!report.healthy) process.exitCode = 1;
+    });
+}
 
-      // CLI dependency checks
-      if (runAll || opts.checkCli) {
-        console.log('CLI Dependencies:');
-        const installedRoot = getInstalledRoot();
-        if (!existsSync(installedRoot)) {
-          info('  No installed types found.');
-        } else {
-          const types = discoverTypes([{ name: 'installed', basePath: installedRoot }]);
-          const skills = types.filter((t) => t.category === 'skill');
-          if (skills.length === 0) {
-            info('  No skills installed.');
-          } else {
-            const { readFileSync } = require('node:fs');
-            const yaml = require('js-yaml');
-            for (const skill of skills) {
-              try {
-                const raw = readFileSync(skill.manifestPath, 'utf-8');
-                const data = yaml.load(raw) as { cli_dependencies?: { name: string }[] };
-                if (data.cli_dependencies) {
-                  for (const dep of data.cli_dependencies) {
-                    if (checkCommand(dep.name)) {
-                      ok(`  ${dep.name} (for ${skill.typePath})`);
-                    } else {
-                      fail(`  ${dep.name} (for ${skill.typePath}) — not found`);
-                    }
-                  }
-                }
-              } catch {
-                // Skip unreadable manifests
-              }
-            }
-          }
-        }
-        console.log('');
-      }
+function runtimeChecks(): Check[] {
+  const section = 'Runtime';
+  const checks: Check[] = ['node', 'npm'].map((cmd) =>
+    checkCommand(cmd)
+      ? { section, name: cmd, status: 'ok', detail: 'available' }
+      : { section, name: cmd, status: 'fail', detail: 'not found' },
+  );
+  checks.push(
+    checkCommand('git')
+      ? { section, name: 'git', status: 'ok', detail: 'available' }
+      : {
+          section,
+          name: 'git',
+          status: 'warn',
+          detail: 'not found (optional: catalog and extensions download as archives; platform-team mode needs git)',
+        },
+  );
+  return checks;
+}
 
-      // Manifest validation
-      if (opts.checkManifest) {
-        console.log('Manifest Validation:');
-        try {
-          const { parseManifestFile } = require('../core/manifest.js');
-          parseManifestFile(opts.checkManifest);
-          ok(`  Valid: ${opts.checkManifest}`);
-        } catch (err) {
-          fail(`  Invalid: ${opts.checkManifest} — ${err}`);
-        }
-        console.log('');
+const ISSUE_NAMES: Record<UserdataIssue['kind'], string> = {
+  missing: 'Missing',
+  'active-profile': 'Active profile',
+  permissions: 'Permissions',
+  orphan: 'Orphaned registry',
+};
+
+function userdataChecks(): Check[] {
+  const section = 'Userdata';
+  const dirs = [
+    ['Userdata root', getUserdataRoot()],
+    ['Installed root', getInstalledRoot()],
+    ['Skills dir', getSkillsDir()],
+    ['Catalog repo', getCatalogRepoRoot()],
+  ] as const;
+  const checks = dirs.map(([name, path]): Check =>
+    existsSync(path)
+      ? { section, name, status: 'ok', detail: path }
+      : { section, name, status: 'warn', detail: `missing (${path})` },
+  );
+  const issues = checkUserdata().filter((i) => !dirs.some(([, path]) => path === i.path));
+  checks.push(
+    ...issues.map((i): Check => ({ section, name: ISSUE_NAMES[i.kind], status: 'warn', detail: `${i.detail} (${i.path})` })),
+  );
+  if (issues.length > 0) {
+    checks.push({ section, name: `Run \`agentx doctor --fix\` to repair ${issues.length} issue(s)`, status: 'info' });
+  }
+  return checks;
+}
+
+/** Repairs the userdata tree under the userdata lock, asking about each orphan unless told. */
+async function fixUserdata(orphans: OrphanAction | undefined): Promise<string[]> {
+  const release = await acquireLock(getHomeRoot(), { command: 'doctor --fix' });
+  try {
+    const issues = checkUserdata();
+    const actions = new Map<UserdataIssue, OrphanAction>();
+    for (const issue of issues.filter((i) => i.kind === 'orphan')) {
+      actions.set(
+        issue,
+        orphans ??
+          (await askSelect<OrphanAction>(
+            t('doctor.orphanQuestion', { skill: issue.skillPath ?? issue.path }),
+            [
+              { name: t('doctor.orphanArchive'), value: 'archive' },
+              { name: t('doctor.orphanDelete'), value: 'delete' },
+              { name: t('doctor.orphanKeep'), value: 'keep' },
+            ],
+            'keep',
+            { hint: t('doctor.orphanHint') },
+          )),
+      );
+    }
+    return repairUserdata(issues, (i) => actions.get(i) ?? 'keep');
+  } finally {
+    release();
+  }
+}
+
+function printFixes(fixed: string[]): void {
+  if (fixed.length === 0) {
+    ok(t('doctor.noRepairs'));
+    return;
+  }
+  for (const line of fixed) ok(line);
+}
+
+function cliChecks(): Check[] {
+  const section = 'CLI Dependencies';
+  const installedRoot = getInstalledRoot();
+  if (!existsSync(installedRoot)) {
+    return [{ section, name: 'No installed types found.', status: 'info' }];
+  }
+  const skills = discoverTypes([{ name: 'installed', basePath: installedRoot }]).filter((t) => t.category === 'skill');
+  if (skills.length === 0) {
+    return [{ section, name: 'No skills installed.', status: 'info' }];
+  }
+
+  const checks: Check[] = [];
+  for (const skill of skills) {
+    try {
+      const data = yaml.load(readFileSync(skill.manifestPath, 'utf-8')) as { cli_dependencies?: { name: string }[] };
+      for (const dep of data.cli_dependencies ?? []) {
+        const name = `${dep.name} (for ${skill.typePath})`;
+        checks.push(checkCommand(dep.name) ? { section, name, status: 'ok' } : { section, name, status: 'fail', detail: 'not found' });
       }
+    } catch {
+      // Skip unreadable manifests
+    }
+  }
+  return checks;
+}
 
-      ok('Doctor complete.');
-    });
+function stateChecks(): Check[] {
+  const section = 'Skill State';
+  const installedRoot = getInstalledRoot();
+  const warnings: string[] = [];
+  const over = existsSync(installedRoot) ? stateOverLimits(installedRoot, warnings) : [];
+  const checks: Check[] = [
+    ...over.map((o): Check => ({
+      section,
+      name: o.typePath,
+      status: 'warn',
+      detail: `state is ${formatBytes(o.bytes)}, over its ${formatBytes(o.limit)} cap (agentx state clear ${o.typePath} --older-than 30d)`,
+    })),
+    ...warnings.map((w): Check => ({ section, name: w, status: 'warn' })),
+  ];
+  return checks.length > 0 ? checks : [{ section, name: 'All skills within their state caps', status: 'ok' }];
+}
+
+function manifestCheck(path: string): Check {
+  const section = 'Manifest Validation';
+  try {
+    const issues = validateManifestFile(path);
+    if (issues.length === 0) return { section, name: path, status: 'ok', detail: 'valid' };
+    const errors = manifestErrors(issues).length;
+    return {
+      section,
+      name: path,
+      status: errors > 0 ? 'fail' : 'warn',
+      detail: errors > 0 ? `invalid (${errors} issue(s))` : `valid, ${issues.length} warning(s)`,
+      notes: issues.map(formatIssue),
+    };
+  } catch (err) {
+    return { section, name: path, status: 'fail', detail: `invalid: ${err}` };
+  }
+}
+
+const PRINT: Record<DoctorStatus, (msg: string) => void> = { ok, warn, fail, info };
+
+// Check names and details are report data (--json) and stay English;
+// the section headings around them are translated.
+const SECTIONS: Record<string, MessageKey> = {
+  Runtime: 'doctor.section.runtime',
+  Userdata: 'doctor.section.userdata',
+  'CLI Dependencies': 'doctor.section.cli',
+  'Skill State': 'doctor.section.state',
+  'Manifest Validation': 'doctor.section.manifest',
+};
+
+function printReport(mode: string, checks: Check[]): void {
+  console.log(`\n${t('doctor.title')}\n`);
+  console.log(`  ${t('doctor.mode', { mode })}`);
+  console.log('');
+
+  let section: string | null = null;
+  for (const check of checks) {
+    if (check.section !== section) {
+      if (section !== null) console.log('');
+      section = check.section;
+      console.log(`${SECTIONS[section] ? t(SECTIONS[section]) : section}:`);
+    }
+    PRINT[check.status](`  ${check.name}${check.detail ? ` — ${check.detail}` : ''}`);
+    for (const note of check.notes ?? []) console.log(`\n${note}`);
+  }
+  if (section !== null) console.log('');
+  ok(t('doctor.complete'));
 }
@@ -2,8 +2,12 @@ import type { Command } from 'commander';
 import { readFileSync, writeFileSync, existsSync, mkdirSync } from 'node:fs';
 import { dirname } from 'node:path';
 import { execFileSync } from 'node:child_process';
-import { listEnvFiles, resolveEnvTarget } from '../core/userdata.js';
+import { listEnvFiles, resolveEnvTarget, getInstalledRoot } from '../core/userdata.js';
+import { resolveSkillEnv, redactEnv, formatEnv, ENV_FORMATS, type EnvFormat } from '../core/skill-env.js';
+import { selectAccount } from '../core/runtime.js';
 import { parseEnvFile, redactValue } from '../utils/env-parser.js';
+import { failError, note } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 
 export function registerEnv(program: Command): void {
   const cmd = program
@@ -16,15 +20,15 @@ export function registerEnv(program: Command): void {
     .action(() => {
       const { shared, skillSpecific } = listEnvFiles();
       if (shared.length) {
-        console.log('Shared:');
+        console.log(t('env.shared'));
         for (const name of shared) console.log(`  ${name}`);
       }
       if (skillSpecific.length) {
-        console.log('Skill-specific:');
+        console.log(t('env.skillSpecific'));
         for (const name of skillSpecific) console.log(`  ${name}`);
       }
       if (!shared.length && !skillSpecific.length) {
-        console.log('No environment files found.');
+        console.log(t('env.none'));
       }
     });
 
@@ -32,17 +36,25 @@ export function registerEnv(program: Command): void {
     .command('edit')
     .description('Edit an environment file')
     .argument('<target>', 'Target name (e.g., aws, cloud/aws/ssm)')
-    .action((target) => {
-      const path = resolveEnvTarget(target);
+    .option('--account <name>', "Edit a skill's token set for an account (tokens.<name>.env)")
+    .action((target, opts) => {
+      let path: string;
+      try {
+        path = resolveEnvTarget(target, opts.account);
+      } catch (err) {
+        console.error(String(err instanceof Error ? err.message : err));
+        process.exit(1);
+      }
       if (!existsSync(path)) {
         mkdirSync(dirname(path), { recursive: true });
-        writeFileSync(path, `# Environment variables for ${target}\n`, { mode: 0o600 });
+        const about = opts.account ? `${target} (account ${opts.account})` : target;
+        writeFileSync(path, `# Environment variables for ${about}\n`, { mode: 0o600 });
       }
       const editor = process.env.EDITOR ?? 'vi';
       try {
         execFileSync(editor, [path], { stdio: 'inherit' });
       } catch (err) {
-        console.error(`Failed to open editor: ${err}`);
+        console.error(t('env.editorFailed', { error: String(err) }));
         process.exit(1);
       }
     });
@@ -51,11 +63,18 @@ export function registerEnv(program: Command): void {
     .command('show')
     .description('Show environment file contents')
     .argument('<target>', 'Target name')
+    .option('--account <name>', "Show a skill's token set for an account")
     .option('--no-redact', 'Show actual values')
     .action((target, opts) => {
-      const path = resolveEnvTarget(target);
+      let path: string;
+      try {
+        path = resolveEnvTarget(target, opts.account);
+      } catch (err) {
+        console.error(String(err instanceof Error ? err.message : err));
+        process.exit(1);
+      }
       if (!existsSync(path)) {
-        console.error(`File not found: ${path}`);
+        console.error(t('common.fileNotFound', { path }));
         process.exit(1);
       }
       const content = readFileSync(path, 'utf-8');
@@ -65,4 +84,28 @@ export function registerEnv(program: Command): void {
         console.log(`${entry.key}=${value}`);
       }
     });
+
+  cmd
+    .command('export')
+    .description('Print the environment a skill runs with, to source into a shell or use as an env file')
+    .argument('<skill-path>', 'Installed skill type path (e.g., skills/scm/git/commit-analyzer)')
+    .option('-f, --format <format>', `Output format (${ENV_FORMATS.join(', ')})`, 'dotenv')
+    .option('--account <name>', 'Use the tokens.<name>.env token set')
+    .option('--no-host', 'Leave out variables passed through from your shell')
+    .option('--reveal', 'Print secret values instead of masking them')
+    .action((skillPath, opts) => {
+      try {
+        if (opts.account) selectAccount(opts.account);
+        let vars = resolveSkillEnv(skillPath, getInstalledRoot());
+        if (opts.host === false) vars = vars.filter((v) => v.from !== 'host');
+        if (!opts.reveal) {
+          vars = redactEnv(vars);
+          note(t('env.masked'));
+        }
+        process.stdout.write(formatEnv(vars, opts.format as EnvFormat));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
 }
@@ -0,0 +1,52 @@
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { explain, explanations, suggestExplanations, type Explanation } from '../core/explain.js';
+import { emitJson, wantsJson, failError } from '../ui/output.js';
+import { printTable } from '../ui/table.js';
+import { t } from '../ui/i18n.js';
+
+export function registerExplain(program: Command): void {
+  program
+    .command('explain')
+    .description('Explain an error code (AGX-REG-004) or a concept (registry, profile, skill-registry, linking)')
+    .argument('[code-or-topic]', 'Error code, its short name, or a topic; omit to list them all')
+    .option('--json', 'Output as JSON')
+    .action((query: string | undefined, opts) => {
+      try {
+        if (!query) {
+          const all = explanations();
+          if (wantsJson(opts)) {
+            emitJson(all);
+          } else {
+            printTable([t('explain.col.id'), t('explain.col.about')], all.map((e) => [e.id, e.title]));
+          }
+          return;
+        }
+
+        const found = explain(query);
+        if (!found) {
+          const suggestions = suggestExplanations(query);
+          const hint = suggestions.length ? `\n${t('common.didYouMean')}\n${suggestions.map((s) => `  ${s}`).join('\n')}` : '';
+          throw new Error(`${t('explain.notFound', { query })}${hint}`);
+        }
+        if (wantsJson(opts)) {
+          emitJson(found);
+        } else {
+          printExplanation(found);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
+
+function printExplanation(e: Explanation): void {
+  console.log(chalk.bold(`${e.id}: ${e.title}`));
+  for (const paragraph of e.body) console.log(`\n${paragraph}`);
+  if (e.fixes.length > 0) {
+    console.log(chalk.bold(`\n${t('explain.fixes')}`));
+    for (const fix of e.fixes) console.log(`  - ${fix}`);
+  }
+  if (e.see.length > 0) console.log(chalk.dim(`\n${t('explain.seeAlso', { items: e.see.join(', ') })}`));
+}
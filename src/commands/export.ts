@@ -0,0 +1,30 @@
+import type { Command } from 'commander';
+import { resolve } from 'node:path';
+import { getInstalledRoot } from '../core/userdata.js';
+import { exportProject, EXPORT_TOOLS } from '../core/project-export.js';
+import { ok, warn, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+export function registerExport(program: Command): void {
+  program
+    .command('export')
+    .description("Write the project's composed persona, context, and skills as plain Markdown, for teams without agentx")
+    .requiredOption('-o, --output <dir>', 'Directory to write to')
+    .option('--tool <tool>', `Layout: ${EXPORT_TOOLS.join(', ')}`, 'generic')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const result = exportProject(process.cwd(), resolve(opts.output), getInstalledRoot(), { tool: opts.tool });
+        for (const w of result.warnings) warn(w, 'export');
+        if (wantsJson(opts)) {
+          emitJson(result);
+          return;
+        }
+        ok(t('export.done', { count: result.files.length, path: result.outputDir }));
+        for (const f of result.files) console.log(`  ${f}`);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
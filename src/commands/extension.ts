@@ -1,14 +1,25 @@
+import { join, resolve } from 'node:path';
+import { existsSync } from 'node:fs';
 import type { Command } from 'commander';
 import {
   addExtension,
   removeExtension,
   listExtensions,
   syncExtensions,
+  listProjectExtensions,
 } from '../core/extension.js';
+import { trustProjectExtension } from '../core/trust.js';
+import { projectConfigPath, projectExtensionsDir } from '../core/linker.js';
+import { createExtension } from '../core/extension-scaffold.js';
+import { isOffline, offlineSkip } from '../core/offline.js';
 import { findRepoRoot } from '../utils/git.js';
-import { ok, fail } from '../ui/output.js';
+import { ok, info, failError, warn, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 import { printTable } from '../ui/table.js';
+import type { ExtensionListJson } from '../types/output.js';
 import { withSpinner } from '../ui/spinner.js';
+import { askApproval } from '../ui/prompts.js';
+import { refreshCacheInBackground } from './cache.js';
 
 export function registerExtension(program: Command): void {
   const cmd = program
@@ -25,12 +36,38 @@ export function registerExtension(program: Command): void {
     .action(async (name, gitURL, opts) => {
       try {
         const repoRoot = findRepoRoot() ?? process.cwd();
-        await withSpinner(`Adding extension ${name}...`, () =>
+        await withSpinner(t('extension.adding', { name }), () =>
           addExtension(repoRoot, name, gitURL, opts.branch),
         );
-        ok(`Extension added: ${name}`);
+        ok(t('extension.added', { name }));
+        refreshCacheInBackground({ sourcesChanged: true });
       } catch (err) {
-        fail(String(err));
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('create')
+    .description('Scaffold a new extension repository with sample types and a manifest lint script')
+    .argument('<name>', 'Extension name (kebab-case)')
+    .option('--description <text>', 'What the extension provides')
+    .option('--output-dir <dir>', 'Output directory (default: ./<name>)')
+    .option('--remote <git-url>', 'Commit and push the new repository to this remote')
+    .option('--branch <branch>', 'Branch to push', 'main')
+    .action(async (name, opts) => {
+      try {
+        const outputDir = resolve(opts.outputDir ?? join(process.cwd(), name));
+        const result = await withSpinner(t('extension.creating', { name }), () =>
+          createExtension(name, outputDir, { description: opts.description, remote: opts.remote, branch: opts.branch }),
+        );
+        ok(t('extension.created', { name, dir: result.outputDir }));
+        for (const f of result.files) console.log(`  ${f}`);
+        for (const w of result.warnings) warn(w, 'scaffold');
+        if (result.pushed) ok(t('extension.pushed', { remote: opts.remote, branch: opts.branch }));
+        else info(t('extension.publishHint', { command: `agentx extension add ${name} <git-url>` }));
+      } catch (err) {
+        failError(err);
         process.exit(1);
       }
     });
@@ -43,9 +80,10 @@ export function registerExtension(program: Command): void {
       try {
         const repoRoot = findRepoRoot() ?? process.cwd();
         await removeExtension(repoRoot, name);
-        ok(`Extension removed: ${name}`);
+        ok(t('extension.removed', { name }));
+        refreshCacheInBackground({ sourcesChanged: true });
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -53,20 +91,58 @@ export function registerExtension(program: Command): void {
   cmd
     .command('list')
     .description('List extensions')
-    .action(async () => {
+    .option('--json', 'Output as JSON')
+    .action(async (opts) => {
       try {
         const repoRoot = findRepoRoot() ?? process.cwd();
         const extensions = await listExtensions(repoRoot);
+        if (wantsJson(opts)) {
+          emitJson({ extensions } satisfies ExtensionListJson);
+          return;
+        }
         if (extensions.length === 0) {
-          console.log('No extensions found.');
+          console.log(t('extension.none'));
           return;
         }
         printTable(
-          ['Name', 'Path', 'Branch', 'Status'],
+          [t('col.name'), t('col.path'), t('extension.col.branch'), t('col.status')],
           extensions.map((e) => [e.name, e.path, e.branch, e.status]),
         );
       } catch (err) {
-        fail(String(err));
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('trust')
+    .description("Trust the project's local extensions (.agentx/extensions) so they are loaded as sources")
+    .argument('[name]', 'Extension name (default: every untrusted one)')
+    .option('--yes', 'Trust without asking')
+    .action(async (name, opts) => {
+      try {
+        const repoRoot = findRepoRoot() ?? process.cwd();
+        const projectPath = [process.cwd(), repoRoot].find((p) => existsSync(projectConfigPath(p))) ?? process.cwd();
+        const all = listProjectExtensions(projectPath);
+        if (name && !all.some((e) => e.name === name)) {
+          throw new Error(t('extension.trustUnknown', { name, dir: projectExtensionsDir(projectPath) }));
+        }
+        const pending = all.filter((e) => (name ? e.name === name : !e.trusted));
+        if (pending.length === 0) {
+          info(t('extension.trustNone'));
+          return;
+        }
+        for (const ext of pending) {
+          if (!opts.yes && !(await askApproval(t('extension.trustReview', { name: ext.name, path: ext.path })))) {
+            warn(t('extension.trustDeclined', { name: ext.name }));
+            continue;
+          }
+          trustProjectExtension(ext.path);
+          ok(t('extension.trusted', { name: ext.name }));
+        }
+        refreshCacheInBackground({ sourcesChanged: true });
+      } catch (err) {
+        failError(err);
         process.exit(1);
       }
     });
@@ -75,12 +151,18 @@ export function registerExtension(program: Command): void {
     .command('sync')
     .description('Sync all extensions')
     .action(async () => {
+      if (isOffline()) {
+        info(offlineSkip('extension sync'));
+        return;
+      }
       try {
         const repoRoot = findRepoRoot() ?? process.cwd();
-        await withSpinner('Syncing extensions...', () => syncExtensions(repoRoot));
-        ok('Extensions synced.');
+        const warnings = await withSpinner(t('extension.syncing'), () => syncExtensions(repoRoot));
+        for (const w of warnings) warn(w);
+        ok(t('extension.synced'));
+        refreshCacheInBackground({ sourcesChanged: true });
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -0,0 +1,82 @@
+import type { Command } from 'commander';
+import { getInstalledRoot } from '../core/userdata.js';
+import { planGc, applyGc } from '../core/gc.js';
+import { categoryFromPath } from '../core/registry.js';
+import { notifyChange } from '../core/notify.js';
+import { rebuildContentIndex } from '../core/content-index.js';
+import { parseDuration, formatBytes } from '../utils/units.js';
+import { APP_NAME } from '../config/branding.js';
+import { ok, info, warn, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+import { askConfirm } from '../ui/prompts.js';
+
+export function registerGc(program: Command): void {
+  program
+    .command('gc')
+    .description('Delete installed types, versions, and caches nothing uses')
+    .option('--older-than <age>', 'Age after which run caches and output history are stale', '30d')
+    .option('--dry-run', 'List what would be deleted')
+    .option('--force', 'Also delete types and versions that unrecorded projects may still use')
+    .option('-y, --yes', 'Do not ask for confirmation')
+    .option('--json', 'Output as JSON')
+    .action(async (opts) => {
+      try {
+        const installedRoot = getInstalledRoot();
+        const plan = planGc(installedRoot, { olderThanMs: parseDuration(opts.olderThan) });
+        if (plan.typesSkipped && !wantsJson(opts)) {
+          warn(t('gc.typesSkipped', { reason: plan.typesSkipped }), 'gc');
+        }
+        if (plan.unreachable.length > 0 && !wantsJson(opts)) {
+          warn(t('gc.unreachable', { count: plan.unreachable.length, flag: '--force', paths: plan.unreachable.join(', ') }), 'gc');
+        }
+        if (plan.items.length === 0) {
+          if (wantsJson(opts)) emitJson({ ...plan, removed: false });
+          else info(t('gc.nothing'));
+          return;
+        }
+
+        if (!wantsJson(opts)) {
+          printTable(
+            [t('col.kind'), t('col.target'), t('col.size'), t('col.why')],
+            plan.items.map((i) => [i.kind, i.target, formatBytes(i.bytes), i.reason]),
+          );
+          info(t('gc.summary', { count: plan.items.length, size: formatBytes(plan.bytes), projects: plan.projects }));
+        }
+        const forceOnly = opts.force ? [] : plan.items.filter((i) => i.force);
+        if (forceOnly.length > 0 && !wantsJson(opts)) {
+          info(t('gc.needsForce', { count: forceOnly.length, app: APP_NAME, flag: '--force' }));
+        }
+        const doomed = plan.items.filter((i) => !forceOnly.includes(i));
+        if (opts.dryRun || doomed.length === 0) {
+          if (wantsJson(opts)) emitJson({ ...plan, removed: false });
+          return;
+        }
+        const size = doomed.reduce((sum, i) => sum + i.bytes, 0);
+        const question = t('gc.confirm', { count: doomed.length, size: formatBytes(size) });
+        if (!opts.yes && !(await askConfirm(question, false, { mandatory: true }))) {
+          info(t('common.cancelled'));
+          return;
+        }
+
+        const result = applyGc(plan, installedRoot, { force: opts.force });
+        const types = doomed
+          .filter((i) => i.kind === 'type' && !result.failed.some((f) => f.item === i))
+          .map((i) => i.target);
+        if (types.some((typePath) => categoryFromPath(typePath) === 'context')) rebuildContentIndex(installedRoot);
+        if (types.length > 0) await notifyChange('uninstall', types);
+
+        if (wantsJson(opts)) {
+          emitJson({ ...plan, removed: true, reclaimed: result.bytes, kept: result.kept, failed: result.failed });
+        } else {
+          for (const f of result.failed) warn(t('gc.deleteFailed', { target: f.item.target, error: f.error }), 'gc');
+          ok(t('gc.reclaimed', { size: formatBytes(result.bytes) }));
+          if (types.length > 0) info(t('gc.reinstallHint', { command: `${APP_NAME} install <type-path>` }));
+        }
+        if (result.failed.length > 0) process.exit(1);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
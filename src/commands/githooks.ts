@@ -0,0 +1,57 @@
+import type { Command } from 'commander';
+import { existsSync } from 'node:fs';
+import { installGitHooks, uninstallGitHooks } from '../core/githooks.js';
+import { projectConfigPath } from '../core/linker.js';
+import { APP_NAME } from '../config/branding.js';
+import { findRepoRoot } from '../utils/git.js';
+import { ok, info, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+/** The repository root, which must also be the agentx project. */
+function projectRoot(): string {
+  const root = findRepoRoot();
+  if (!root) throw new Error(t('githooks.notGit'));
+  if (!existsSync(projectConfigPath(root))) {
+    throw new Error(t('githooks.notProject', { root, app: APP_NAME, command: `${APP_NAME} init` }));
+  }
+  return root;
+}
+
+export function registerGithooks(program: Command): void {
+  const cmd = program
+    .command('githooks')
+    .description('Git hooks that keep generated tool files in sync with project.yaml');
+
+  cmd
+    .command('install')
+    .description('Add pre-commit and post-merge hooks (into husky or lefthook when the repository uses them)')
+    .option('--sync', 'Run link sync after merges instead of only warning')
+    .action((opts) => {
+      try {
+        const { manager, paths } = installGitHooks(projectRoot(), { sync: opts.sync });
+        for (const path of paths) ok(t('common.wrote', { path }));
+        if (manager === 'lefthook') info(t('githooks.lefthook'));
+        info(t('githooks.installed'));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('uninstall')
+    .description(`Remove the hooks added by ${APP_NAME} githooks install`)
+    .action(() => {
+      try {
+        const paths = uninstallGitHooks(projectRoot());
+        if (paths.length === 0) {
+          info(t('githooks.none'));
+          return;
+        }
+        for (const path of paths) ok(t('githooks.removed', { path }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
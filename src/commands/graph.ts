@@ -0,0 +1,68 @@
+import type { Command } from 'commander';
+import { writeFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import { findProjects, buildGraph, graphToDot } from '../core/graph.js';
+import { projectConfigPath } from '../core/linker.js';
+import { discoverTypes } from '../core/registry.js';
+import { buildSources } from '../core/extension.js';
+import { getInstalledRoot } from '../core/userdata.js';
+import { findRepoRoot } from '../utils/git.js';
+import { fileExists } from '../utils/fs.js';
+import { ok, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+export function registerGraph(program: Command): void {
+  const cmd = program
+    .command('graph')
+    .description('Project and type dependency graphs');
+
+  cmd
+    .command('export')
+    .description('Export an anonymized project → type graph (written locally, never sent anywhere)')
+    .option('--workspace', 'Include every agentx project under the workspace root')
+    .option('--root <dir>', 'Workspace root to scan (default: git repository root)')
+    .option('--salt <salt>', 'Secret mixed into hashes (default: a random one kept for this install); share it to correlate exports across machines')
+    .option('--hash-types', 'Hash type paths as well as projects')
+    .option('--include-catalog', 'Add catalog types that no project uses')
+    .option('--format <format>', 'Output format: json or dot', 'json')
+    .option('-o, --output <file>', 'Write to a file instead of stdout')
+    .action((opts) => {
+      try {
+        if (opts.format !== 'json' && opts.format !== 'dot') {
+          throw new Error(t('graph.unknownFormat', { format: opts.format }));
+        }
+
+        const cwd = process.cwd();
+        const root = resolve(opts.root ?? findRepoRoot() ?? cwd);
+        let projects: string[];
+        if (opts.workspace) {
+          projects = findProjects(root);
+        } else if (fileExists(projectConfigPath(cwd))) {
+          projects = [cwd];
+        } else {
+          throw new Error(t('graph.notProject'));
+        }
+
+        const catalogTypes = opts.includeCatalog
+          ? discoverTypes(buildSources(root)).map((d) => d.typePath)
+          : undefined;
+
+        const graph = buildGraph(root, projects, getInstalledRoot(), {
+          salt: opts.salt,
+          hashTypes: opts.hashTypes,
+          catalogTypes,
+        });
+        const out = opts.format === 'dot' ? graphToDot(graph) : JSON.stringify(graph, null, 2) + '\n';
+
+        if (opts.output) {
+          writeFileSync(opts.output, out);
+          ok(t('graph.written', { projects: projects.length, links: graph.edges.length, path: opts.output }));
+        } else {
+          process.stdout.write(out);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
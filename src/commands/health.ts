@@ -0,0 +1,56 @@
+import type { Command } from 'commander';
+import { writeFileSync } from 'node:fs';
+import chalk from 'chalk';
+import { assessProject, badgeSvg, badgeJson } from '../core/health.js';
+import { ok, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+export function registerHealth(program: Command): void {
+  program
+    .command('health')
+    .description('Score project health: link freshness, catalog staleness, tokens, policy')
+    .option('--badge', 'Emit a badge instead of the report')
+    .option('--format <format>', 'Badge format: svg or json', 'svg')
+    .option('-o, --output <file>', 'Write output to file')
+    .option('--json', 'Output report as JSON')
+    .action(async (opts) => {
+      try {
+        const report = await assessProject(process.cwd());
+        const json = wantsJson(opts);
+
+        // The report itself goes through the JSON envelope; badges and
+        // files keep their raw form so they can be published as is
+        if (json && !opts.badge && !opts.output) {
+          emitJson(report);
+          return;
+        }
+
+        let output: string;
+        if (opts.badge) {
+          if (opts.format !== 'svg' && opts.format !== 'json') {
+            throw new Error(t('health.unknownBadgeFormat', { format: opts.format }));
+          }
+          output = opts.format === 'json' ? badgeJson(report) : badgeSvg(report);
+        } else if (json) {
+          output = JSON.stringify(report, null, 2);
+        } else {
+          const lines = [`\n${t('health.title', { score: chalk.bold(`${report.score}%`), grade: report.grade })}\n`];
+          for (const check of report.checks) {
+            lines.push(`  ${check.label.padEnd(20)} ${String(check.score).padStart(3)}%  (${t('health.weight', { weight: check.weight })})`);
+            for (const d of check.details) lines.push(chalk.dim(`      ${d}`));
+          }
+          output = lines.join('\n') + '\n';
+        }
+
+        if (opts.output) {
+          writeFileSync(opts.output, output, 'utf-8');
+          ok(t('template.written', { path: opts.output }));
+        } else {
+          process.stdout.write(output.endsWith('\n') ? output : output + '\n');
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
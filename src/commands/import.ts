@@ -0,0 +1,84 @@
+import type { Command } from 'commander';
+import { existsSync } from 'node:fs';
+import { importToolFiles, wireProject } from '../core/tool-import.js';
+import { initProject, projectConfigPath, linkTypes, sync } from '../core/linker.js';
+import { recordProject } from '../core/projects.js';
+import { buildSources } from '../core/extension.js';
+import { loadPreset, presetTypes, installPreset } from '../core/preset.js';
+import { getInstalledRoot } from '../core/userdata.js';
+import { notifyChange } from '../core/notify.js';
+import { ALL_TOOLS } from '../types/integrations.js';
+import { ok, info, warn, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { withSpinner } from '../ui/spinner.js';
+import { approveContribution } from './trust.js';
+
+export function registerImport(program: Command): void {
+  program
+    .command('import')
+    .description('Convert CLAUDE.md, .github/copilot-instructions.md, or .cursorrules into types in a local extension')
+    .option('--name <name>', 'Extension name (default: <directory>-local)')
+    .option('--force', 'Replace an extension imported before')
+    .option('--dry-run', 'Show the types that would be created without writing them')
+    .option('--no-link', 'Only write the extension; do not install and link its types')
+    .option('--json', 'Output as JSON')
+    .action(async (opts) => {
+      try {
+        const projectPath = process.cwd();
+        const result = importToolFiles(projectPath, { name: opts.name, force: opts.force, dryRun: opts.dryRun });
+        if (!wantsJson(opts)) for (const g of result.generated) info(t('import.skippedGenerated', { file: g }));
+
+        if (opts.dryRun || !opts.link) {
+          if (!opts.dryRun) wireProject(projectPath, result.extension);
+          if (wantsJson(opts)) {
+            emitJson(result);
+            return;
+          }
+          const files = result.files.map((f) => f.path).join(', ');
+          ok(t(opts.dryRun ? 'import.wouldCreate' : 'import.created', { extension: result.extension, files }));
+          if (result.persona) console.log(`  ${result.persona}`);
+          for (const c of result.context) console.log(`  ${c}`);
+          if (!opts.dryRun) {
+            const command = `agentx init --preset ${result.preset}`;
+            info(existsSync(projectConfigPath(projectPath))
+              ? t('import.linkHint', { command })
+              : t('import.linkHintInit', { command }));
+          }
+          return;
+        }
+
+        if (!existsSync(projectConfigPath(projectPath))) {
+          const tools = result.tools.length > 0 ? result.tools : ALL_TOOLS;
+          initProject(projectPath, tools);
+          recordProject(projectPath, tools);
+          ok(t('init.initialized', { tools: tools.join(', ') }));
+        }
+        wireProject(projectPath, result.extension);
+
+        const preset = loadPreset(result.preset, buildSources(projectPath));
+        const { installed, warnings } = await withSpinner(t('import.installing', { extension: result.extension }), () =>
+          installPreset(preset, buildSources(projectPath), getInstalledRoot()),
+        );
+        for (const w of warnings) warn(w, 'import');
+        if (installed.length > 0) await notifyChange('install', installed);
+
+        const linked = await linkTypes(projectPath, presetTypes(preset));
+        const overrideWarnings: string[] = [];
+        for (const r of await sync(projectPath, { warnings: overrideWarnings, approveHook: approveContribution })) {
+          for (const w of r.warnings) warn(w, r.tool);
+        }
+        for (const w of overrideWarnings) warn(w, 'sync');
+
+        if (wantsJson(opts)) {
+          emitJson({ ...result, linked });
+          return;
+        }
+        ok(t('import.imported', { files: result.files.map((f) => f.path).join(', '), extension: result.extension }));
+        for (const typePath of linked) console.log(`  ${typePath}`);
+        info(t('import.originalsCopied'));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -16,3 +16,39 @@ export { registerConfig } from './config.js';
 export { registerPrompt } from './prompt.js';
 export { registerUpdate } from './update.js';
 export { registerRebrand } from './rebrand.js';
+export { registerHealth } from './health.js';
+export { registerTrust } from './trust.js';
+export { registerCache } from './cache.js';
+export { registerRollback } from './rollback.js';
+export { registerGraph } from './graph.js';
+export { registerTutorial } from './tutorial.js';
+export { registerInfo } from './info.js';
+export { registerSources } from './sources.js';
+export { registerDeps } from './deps.js';
+export { registerVerify } from './verify.js';
+export { registerOverrides } from './overrides.js';
+export { registerRegistry } from './registry.js';
+export { registerTest } from './test.js';
+export { registerValidate } from './validate.js';
+export { registerState } from './state.js';
+export { registerOutput } from './output.js';
+export { registerStats } from './stats.js';
+export { registerPack } from './pack.js';
+export { registerPreset } from './preset.js';
+export { registerWhich } from './which.js';
+export { registerBackup } from './backup.js';
+export { registerImport } from './import.js';
+export { registerExport } from './export.js';
+export { registerServe } from './serve.js';
+export { registerDaemon } from './daemon.js';
+export { registerSchedule } from './schedule.js';
+export { registerScheduler } from './scheduler.js';
+export { registerPipe } from './pipe.js';
+export { registerVersions } from './versions.js';
+export { registerGc } from './gc.js';
+export { registerProjects } from './projects.js';
+export { registerTemplate } from './template.js';
+export { registerGithooks } from './githooks.js';
+export { registerBench } from './bench.js';
+export { registerExplain } from './explain.js';
+export { registerUi } from './ui.js';
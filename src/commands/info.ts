@@ -0,0 +1,104 @@
+import type { Command } from 'commander';
+import { dirname } from 'node:path';
+import chalk from 'chalk';
+import { getInstalledRoot } from '../core/userdata.js';
+import { buildSources } from '../core/extension.js';
+import { inspectType, type TypeInfo } from '../core/info.js';
+import { printTree } from '../core/registry.js';
+import { findRepoRoot } from '../utils/git.js';
+import { printTable } from '../ui/table.js';
+import { emitJson, wantsJson, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+const METADATA_KEYS = ['name', 'version', 'description', 'author', 'vendor', 'topic', 'runtime', 'tags'];
+
+export function registerInfo(program: Command): void {
+  program
+    .command('info')
+    .description('Show everything about a type: metadata, dependencies, tokens, config, and linking projects')
+    .argument('<type-path>', 'Type path (installed or available in a source)')
+    .option('--root <dir>', 'Directory to scan for projects linking the type (default: parent of the current repo)')
+    .option('--json', 'Output as JSON')
+    .action((typePath, opts) => {
+      try {
+        const repoRoot = findRepoRoot() ?? process.cwd();
+        const info = inspectType(typePath, {
+          installedRoot: getInstalledRoot(),
+          sources: buildSources(repoRoot),
+          projectsRoot: opts.root ?? dirname(repoRoot),
+        });
+
+        if (wantsJson(opts)) {
+          emitJson(info);
+          return;
+        }
+        printInfo(info);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
+
+function section(title: string): void {
+  console.log(`\n${chalk.bold(title)}`);
+}
+
+function printInfo(info: TypeInfo): void {
+  console.log(chalk.bold(info.typePath));
+  console.log(
+    chalk.dim(
+      `${info.category} · ${info.installed ? t('info.installed') : t('info.notInstalled')}` +
+        (info.source ? ` · ${t('info.from', { source: info.source })}` : ''),
+    ),
+  );
+
+  section(t('info.metadata'));
+  for (const key of METADATA_KEYS) {
+    const value = info.manifest[key];
+    if (value == null || value === '') continue;
+    console.log(`  ${key.padEnd(12)} ${Array.isArray(value) ? value.join(', ') : String(value)}`);
+  }
+
+  section(t('info.dependencies'));
+  console.log(printTree(info.tree).trimEnd().replace(/^/gm, '  '));
+
+  if (info.cliDeps.length > 0) {
+    section(t('info.cliDependencies'));
+    for (const dep of info.cliDeps) {
+      console.log(`  ${dep.available ? chalk.green('✓') : chalk.red('✗')} ${dep.name}`);
+    }
+  }
+
+  if (info.tokens.length > 0) {
+    section(t('info.tokens'));
+    printTable(
+      [t('col.token'), t('col.required'), t('col.status'), t('col.from')],
+      info.tokens.map((token) => [
+        token.name,
+        token.required ? t('common.yes') : t('common.no'),
+        token.set
+          ? chalk.green(t('info.set'))
+          : token.required
+            ? chalk.red(t('info.unset'))
+            : chalk.yellow(t('info.unset')),
+        token.from ?? '-',
+      ]),
+    );
+  }
+
+  if (info.config.length > 0) {
+    section(t('info.registryConfig'));
+    printTable(
+      [t('col.key'), t('col.value'), t('col.default')],
+      info.config.map((c) => [c.key, JSON.stringify(c.value), JSON.stringify(c.default)]),
+    );
+  }
+
+  section(t('info.linkedBy'));
+  if (info.linkedBy.length === 0) {
+    console.log(chalk.dim(`  ${t('info.notLinked')}`));
+  } else {
+    for (const dir of info.linkedBy) console.log(`  ${dir}`);
+  }
+}
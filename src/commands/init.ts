@@ -1,11 +1,19 @@
 import type { Command } from 'commander';
 import { existsSync } from 'node:fs';
-import { initGlobal, getCatalogRepoRoot, catalogExists } from '../core/userdata.js';
-import { initProject, projectConfigPath } from '../core/linker.js';
+import { initGlobal, getCatalogRepoRoot, catalogExists, getInstalledRoot } from '../core/userdata.js';
+import { initProject, projectConfigPath, linkTypes, sync } from '../core/linker.js';
+import { recordProject } from '../core/projects.js';
 import { clone } from '../core/catalog.js';
+import { buildSources, runDetectionRules } from '../core/extension.js';
+import { loadPreset, presetTypes, installPreset } from '../core/preset.js';
+import { notifyChange } from '../core/notify.js';
+import { findRepoRoot } from '../utils/git.js';
+import { isOffline, offlineSkip } from '../core/offline.js';
 import { ALL_TOOLS } from '../types/integrations.js';
-import { ok, warn, fail } from '../ui/output.js';
+import { ok, info, warn, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 import { withSpinner } from '../ui/spinner.js';
+import { approveContribution } from './trust.js';
 
 export function registerInit(program: Command): void {
   program
@@ -13,32 +21,76 @@ export function registerInit(program: Command): void {
     .description('Initialize AgentX configuration')
     .option('--global', 'Initialize global userdata directory')
     .option('--tools <list>', 'Comma-separated AI tools to configure', ALL_TOOLS.join(','))
-    .action(async (opts) => {
+    .option('--preset <name>', 'Install and link a preset from the catalog or extensions (see `agentx preset list`)')
+    .action(async (opts, command: Command) => {
       try {
         if (opts.global) {
-          console.log('Initializing global userdata...');
+          console.log(t('init.global'));
           initGlobal((msg) => console.log(msg));
 
           if (!catalogExists()) {
-            const catalogDir = getCatalogRepoRoot();
-            await withSpinner('Cloning catalog...', () => clone(catalogDir));
+            if (isOffline()) {
+              info(offlineSkip('catalog clone; run `agentx catalog update` once online'));
+            } else {
+              const catalogDir = getCatalogRepoRoot();
+              await withSpinner(t('init.cloningCatalog'), () => clone(catalogDir));
+            }
           }
-          ok('Global initialization complete.');
+          ok(t('init.globalDone'));
           return;
         }
 
         const projectPath = process.cwd();
         const configPath = projectConfigPath(projectPath);
-        if (existsSync(configPath)) {
-          warn('Project already initialized.');
+        const initialized = existsSync(configPath);
+        if (initialized && !opts.preset) {
+          warn(t('init.alreadyInitialized'));
           return;
         }
 
-        const tools = opts.tools.split(',').map((t: string) => t.trim());
-        initProject(projectPath, tools);
-        ok(`Project initialized with tools: ${tools.join(', ')}`);
+        const sources = buildSources(findRepoRoot() ?? projectPath);
+        const preset = opts.preset ? loadPreset(opts.preset, sources) : null;
+        // Install first, so a failed install leaves no half-set-up project
+        if (preset) {
+          const installedRoot = getInstalledRoot();
+          const { installed, warnings } = await withSpinner(t('init.installingPreset', { preset: preset.name }), () =>
+            installPreset(preset, sources, installedRoot),
+          );
+          for (const w of warnings) warn(w, 'preset');
+          if (installed.length > 0) {
+            await notifyChange('install', installed);
+            ok(t('init.presetInstalled', { count: installed.length, preset: preset.name }));
+          }
+        }
+
+        if (!initialized) {
+          const explicitTools = command.getOptionValueSource('tools') !== 'default';
+          const tools: string[] = explicitTools || !preset?.tools
+            ? opts.tools.split(',').map((t: string) => t.trim())
+            : preset.tools;
+          initProject(projectPath, tools);
+          recordProject(projectPath, tools);
+          ok(t('init.initialized', { tools: tools.join(', ') }));
+
+          // Extensions' detection rules suggest what fits this project
+          const detectWarnings: string[] = [];
+          for (const d of await runDetectionRules(projectPath, sources, approveContribution, detectWarnings)) {
+            info(t('init.suggests', { rule: `${d.extension}/${d.rule}`, suggestions: d.suggestions.join(', ') }));
+          }
+          for (const w of detectWarnings) warn(w, 'detect');
+        }
+
+        if (preset) {
+          const linked = await linkTypes(projectPath, presetTypes(preset));
+          const overrideWarnings: string[] = [];
+          for (const r of await sync(projectPath, { warnings: overrideWarnings, approveHook: approveContribution })) {
+            for (const w of r.warnings) warn(w, r.tool);
+          }
+          for (const w of overrideWarnings) warn(w, 'sync');
+          ok(t('init.presetLinked', { count: linked.length, preset: preset.name }));
+        }
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -1,18 +1,19 @@
 import type { Command } from 'commander';
-import { join } from 'node:path';
-import { getInstalledRoot, getSkillsDir } from '../core/userdata.js';
-import {
-  buildInstallPlan,
-  installType,
-  installNodeDeps,
-  initSkillRegistry,
-  printTree,
-  nameFromPath,
-} from '../core/registry.js';
+import { getInstalledRoot } from '../core/userdata.js';
+import { buildInstallPlan, printTree, nameFromPath, discoverTypes, didYouMean, deprecationOf } from '../core/registry.js';
 import { buildSources } from '../core/extension.js';
+import { installAll, InstallError } from '../core/transaction.js';
+import type { FileChanges } from '../core/store.js';
+import { notifyChange } from '../core/notify.js';
+import { runHooks } from '../core/hooks.js';
 import { findRepoRoot } from '../utils/git.js';
-import { ok, fail, warn, info } from '../ui/output.js';
-import { askConfirm } from '../ui/prompts.js';
+import { ok, fail, failError, warn, info, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { askConfirm, canPrompt } from '../ui/prompts.js';
+import type { MessageKey } from '../ui/messages/en.js';
+import type { InstallSummaryJson } from '../types/output.js';
+import type { InstallPlan } from '../types/registry.js';
+import { approveContribution } from './trust.js';
 
 export function registerInstall(program: Command): void {
   program
@@ -21,73 +22,158 @@ export function registerInstall(program: Command): void {
     .argument('<type-path>', 'Path to the type (e.g., skills/scm/git/commit-analyzer)')
     .option('--no-deps', 'Skip dependency resolution')
     .option('-y, --yes', 'Skip confirmation prompt')
+    .option('--json', 'Print a summary of what was installed as JSON (requires --yes when prompts are possible)')
     .action(async (typePath, opts) => {
       try {
+        const json = wantsJson(opts);
+        // The plan and prompt would mix with the JSON on stdout
+        if (json && !opts.yes && canPrompt()) {
+          throw new Error(t('install.jsonNeedsYes'));
+        }
+        const warnings: string[] = [];
+        const report = (w: string, scope?: string) => {
+          warnings.push(w);
+          warn(w, scope);
+        };
+
         const repoRoot = findRepoRoot() ?? process.cwd();
         const sources = buildSources(repoRoot);
         const installedRoot = getInstalledRoot();
         const noDeps = opts.deps === false;
 
-        const plan = buildInstallPlan(typePath, sources, installedRoot, noDeps);
+        let plan = buildInstallPlan(typePath, sources, installedRoot, noDeps);
 
-        if (plan.allTypes.length === 0) {
-          info('Nothing to install — all types already present.');
-          return;
+        if (!plan.root.resolved && !plan.root.installed) {
+          const known = discoverTypes(sources).map((t) => t.typePath);
+          fail(t('common.typeNotFound', { type: typePath, hint: didYouMean(typePath, known) }));
+          process.exit(1);
         }
 
-        // Show plan
-        console.log('\nInstall plan:\n');
-        console.log(printTree(plan.root));
+        const root = plan.root.resolved;
+        if (root?.aliasOf && !json) {
+          info(t('common.moved', { from: root.aliasOf, to: root.typePath }));
+        }
+        const deprecation = root ? deprecationOf(root) : null;
+        if (deprecation) {
+          const successor = deprecation.replacedBy ? t('common.useInstead', { type: deprecation.replacedBy }) : '';
+          report(t('common.deprecated', { type: deprecation.typePath, successor }), 'deprecated');
+          if (
+            deprecation.replacedBy &&
+            !opts.yes &&
+            canPrompt() &&
+            (await askConfirm(t('install.installInstead', { type: deprecation.replacedBy })))
+          ) {
+            plan = buildInstallPlan(deprecation.replacedBy, sources, installedRoot, noDeps);
+          }
+        }
+        for (const dep of plan.allTypes) {
+          if (dep === plan.root.resolved) continue;
+          const d = deprecationOf(dep);
+          if (d) {
+            const successor = d.replacedBy ? t('common.useInstead', { type: d.replacedBy }) : '';
+            report(t('install.dependencyDeprecated', { type: d.typePath, successor }), 'deprecated');
+          }
+        }
 
-        const counts = Object.entries(plan.counts)
-          .map(([k, v]) => `${v} ${k}(s)`)
-          .join(', ');
-        console.log(`Types to install: ${counts}`);
+        const summary: InstallSummaryJson = {
+          root: plan.root.typePath,
+          installed: [],
+          alreadyInstalled: plan.skipCount,
+          cliDependencies: plan.cliDeps,
+          warnings,
+        };
 
-        if (plan.skipCount > 0) {
-          console.log(`Already installed: ${plan.skipCount}`);
+        if (plan.allTypes.length === 0) {
+          if (json) emitJson(summary);
+          else info(t('install.nothingToInstall'));
+          return;
         }
 
-        if (plan.cliDeps.length > 0) {
-          console.log('\nCLI dependencies:');
-          for (const dep of plan.cliDeps) {
-            console.log(`  ${dep.available ? '✓' : '✗'} ${dep.name}`);
-          }
-        }
+        if (!json) printPlan(plan);
 
         // Confirm
         if (!opts.yes) {
-          const confirmed = await askConfirm('\nProceed with installation?');
+          const confirmed = await askConfirm(`\n${t('install.proceed')}`);
           if (!confirmed) {
-            console.log('Cancelled.');
+            console.log(t('common.cancelled'));
             return;
           }
         }
 
-        // Install
-        for (const resolved of plan.allTypes) {
-          const name = nameFromPath(resolved.typePath);
-          process.stdout.write(`Installing ${name}...`);
-          installType(resolved, installedRoot);
-
-          // npm install for Node skills/workflows
-          const typeDir = join(installedRoot, resolved.typePath);
-          const npmWarning = installNodeDeps(typeDir);
-          if (npmWarning) warn(npmWarning);
-
-          // Init skill registry
-          if (resolved.category === 'skill') {
-            const warnings = initSkillRegistry(resolved, getSkillsDir());
-            for (const w of warnings) warn(w);
-          }
+        const typePaths = plan.allTypes.map((t) => t.typePath);
+        const hookContext = { typePaths, projectPath: repoRoot };
+        const hookOpts = { approve: approveContribution };
+        await runHooks('pre-install', hookContext, hookOpts);
 
-          console.log(' done');
+        // Install, all or nothing: a failure puts back every type of the plan
+        try {
+          await installAll(plan.allTypes, installedRoot, {
+            ...hookOpts,
+            onStart: (resolved) => {
+              if (!json) process.stdout.write(t('install.installing', { name: nameFromPath(resolved.typePath) }));
+            },
+            onInstalled: (resolved, version, changes) => {
+              summary.installed.push({ typePath: resolved.typePath, category: resolved.category, version, files: changes });
+              if (!json) console.log(` ${t('install.done', { changes: describeChanges(changes) })}`);
+            },
+            onWarning: (w) => report(w),
+          });
+        } catch (err) {
+          if (!(err instanceof InstallError)) throw err;
+          if (!json) console.log(` ${t('install.failed')}`);
+          const restored = err.unrestored.length
+            ? t('install.notRestored', { types: err.unrestored.join(', ') })
+            : t('install.rolledBack');
+          const hooks = await runHooks('post-install', { ...hookContext, status: 'failed' }, hookOpts);
+          for (const w of hooks.warnings) report(w, 'hooks');
+          throw new Error(t('install.installFailed', { type: err.typePath, message: err.reason, restored }));
         }
 
-        ok(`Installed ${plan.allTypes.length} type(s).`);
+        await notifyChange('install', typePaths);
+        const hooks = await runHooks('post-install', { ...hookContext, status: 'ok' }, hookOpts);
+        for (const w of hooks.warnings) report(w, 'hooks');
+
+        if (json) emitJson(summary);
+        else ok(t('install.installed', { count: plan.allTypes.length }));
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 }
+
+function printPlan(plan: InstallPlan): void {
+  console.log(`\n${t('install.plan')}\n`);
+  console.log(printTree(plan.root));
+
+  const counts = Object.entries(plan.counts)
+    .map(([k, v]) => `${v} ${k}(s)`)
+    .join(', ');
+  console.log(t('install.toInstall', { counts }));
+
+  if (plan.skipCount > 0) {
+    console.log(t('install.alreadyInstalled', { count: plan.skipCount }));
+  }
+
+  if (plan.cliDeps.length > 0) {
+    console.log(`\n${t('install.cliDependencies')}`);
+    for (const dep of plan.cliDeps) {
+      console.log(`  ${dep.available ? '✓' : '✗'} ${dep.name}`);
+    }
+  }
+}
+
+/** "2 added, 1 updated, 40 unchanged" for a type's files. */
+function describeChanges(changes: FileChanges): string {
+  if (changes.added.length + changes.updated.length + changes.removed.length === 0) return t('install.noFileChanges');
+  const counts: [number, MessageKey][] = [
+    [changes.added.length, 'install.filesAdded'],
+    [changes.updated.length, 'install.filesUpdated'],
+    [changes.removed.length, 'install.filesRemoved'],
+    [changes.unchanged, 'install.filesUnchanged'],
+  ];
+  return counts
+    .filter(([n]) => n > 0)
+    .map(([count, key]) => t(key, { count }))
+    .join(', ');
+}
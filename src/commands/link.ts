@@ -3,10 +3,57 @@ import {
   addType,
   removeType,
   sync,
+  previewSync,
   status,
+  checkVersionSkew,
 } from '../core/linker.js';
-import { ok, fail, warn } from '../ui/output.js';
+import { APP_NAME } from '../config/branding.js';
+import { ok, info, failError, warn, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 import { printTable } from '../ui/table.js';
+import type { LinkStatusJson } from '../types/output.js';
+import { approveContribution } from './trust.js';
+
+/** link sync --dry-run: lists what would change, with diffs when asked. */
+async function printSyncPreview(projectPath: string, opts: { regenerateAll?: boolean; forceCopy?: boolean; diff?: boolean }) {
+  const warnings: string[] = [];
+  const changes = await previewSync(projectPath, { regenerateAll: opts.regenerateAll, forceCopy: opts.forceCopy, warnings });
+  for (const w of warnings) warn(w, 'sync');
+  const pending = changes.filter((c) => c.action !== 'unchanged');
+  if (pending.length === 0) {
+    ok(t('link.upToDate'));
+    return;
+  }
+  printTable(
+    [t('col.tool'), t('col.action'), t('col.path')],
+    pending.map((c) => [c.tool, c.action, c.kind === 'link' ? t('link.linkPath', { path: c.path }) : c.path]),
+  );
+  if (opts.diff) {
+    for (const c of pending) {
+      if (c.kind === 'file') {
+        process.stdout.write(`\n${c.diff}`);
+      } else {
+        console.log(`\n${c.path}: ${c.previousTarget ?? t('link.none')} → ${c.action === 'remove' ? t('link.removed') : c.target}`);
+      }
+    }
+  }
+  info(t('link.dryRun', { count: pending.length }));
+}
+
+async function warnVersionSkew(projectPath: string): Promise<void> {
+  const skew = await checkVersionSkew(projectPath);
+  if (!skew) return;
+  warn(
+    t('link.versionSkew', {
+      app: APP_NAME,
+      recorded: skew.recorded.join(', '),
+      current: skew.current,
+      direction: skew.direction,
+      command: `${APP_NAME} link sync --regenerate-all`,
+    }),
+    'version',
+  );
+}
 
 export function registerLink(program: Command): void {
   const cmd = program
@@ -16,13 +63,14 @@ export function registerLink(program: Command): void {
   cmd
     .command('add')
     .description('Add a type reference to the project')
-    .argument('<type-path>', 'Type path (e.g., personas/senior-java-dev)')
+    .argument('<type-path>', 'Type path, optionally pinned (e.g., personas/senior-java-dev or skills/x@1.4.2)')
     .action(async (typePath) => {
       try {
+        await warnVersionSkew(process.cwd());
         await addType(process.cwd(), typePath);
-        ok(`Linked: ${typePath}`);
+        ok(t('link.linked', { type: typePath }));
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -33,10 +81,11 @@ export function registerLink(program: Command): void {
     .argument('<type-path>', 'Type path to remove')
     .action(async (typePath) => {
       try {
+        await warnVersionSkew(process.cwd());
         await removeType(process.cwd(), typePath);
-        ok(`Unlinked: ${typePath}`);
+        ok(t('link.unlinked', { type: typePath }));
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -44,18 +93,42 @@ export function registerLink(program: Command): void {
   cmd
     .command('sync')
     .description('Regenerate all AI tool configuration files')
-    .action(async () => {
+    .option('--regenerate-all', 'Delete previously generated files first (normalizes output from other CLI versions)')
+    .option('--force-copy', 'Copy context instead of symlinking (for filesystems without symlinks; see link.copy)')
+    .option('--dry-run', 'Show what would be created, updated, or removed without writing anything')
+    .option('--diff', 'Also show a diff per generated file; implies --dry-run')
+    .action(async (opts) => {
       try {
-        const results = await sync(process.cwd());
+        if (!opts.regenerateAll) await warnVersionSkew(process.cwd());
+        // A diff only makes sense as a preview, so --diff alone never writes
+        if (opts.dryRun || opts.diff) {
+          await printSyncPreview(process.cwd(), opts);
+          return;
+        }
+        const warnings: string[] = [];
+        const results = await sync(process.cwd(), {
+          regenerateAll: opts.regenerateAll,
+          forceCopy: opts.forceCopy,
+          warnings,
+          approveHook: approveContribution,
+        });
+        for (const w of warnings) warn(w, 'sync');
         for (const r of results) {
           if (r.warnings.length) {
-            for (const w of r.warnings) warn(`${r.tool}: ${w}`);
+            for (const w of r.warnings) warn(w, r.tool);
           } else {
-            ok(`${r.tool}: ${r.created.length} created, ${r.updated.length} updated, ${r.symlinked.length} symlinked`);
+            ok(
+              t('link.synced', {
+                tool: r.tool,
+                created: r.created.length,
+                updated: r.updated.length,
+                symlinked: r.symlinked.length,
+              }),
+            );
           }
         }
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -63,24 +136,43 @@ export function registerLink(program: Command): void {
   cmd
     .command('status')
     .description('Show link status for all tools')
-    .action(async () => {
+    .option('--json', 'Output as JSON')
+    .option('--quiet', 'Print nothing; exit 1 when link sync would change generated files (for git hooks)')
+    .action(async (opts) => {
       try {
+        if (opts.quiet) {
+          const changes = await previewSync(process.cwd());
+          process.exit(changes.some((c) => c.action !== 'unchanged') ? 1 : 0);
+        }
+        if (wantsJson(opts)) {
+          const skew = await checkVersionSkew(process.cwd());
+          const results = await status(process.cwd());
+          emitJson({
+            tools: results.map((r) => ({ ...r, generatedBy: r.generatedBy ?? null })),
+            versionSkew: skew,
+          } satisfies LinkStatusJson);
+          return;
+        }
+        await warnVersionSkew(process.cwd());
         const results = await status(process.cwd());
         if (results.length === 0) {
-          console.log('No tools configured.');
+          console.log(t('link.noTools'));
           return;
         }
         printTable(
-          ['Tool', 'Status', 'Files', 'Symlinks'],
+          [t('col.tool'), t('col.status'), t('link.col.files'), t('link.col.symlinks')],
           results.map((r) => [
             r.tool,
             r.status,
             String(r.files.length),
-            `${r.symlinks.valid}/${r.symlinks.total}`,
+            `${r.symlinks.valid}/${r.symlinks.total}` + (r.symlinks.copies ? ` ${t('link.copied', { count: r.symlinks.copies })}` : ''),
           ]),
         );
+        if (results.some((r) => r.symlinks.drifted > 0 || r.symlinks.valid < r.symlinks.total)) {
+          warn(t('link.broken', { command: `${APP_NAME} link sync` }));
+        }
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -1,55 +1,62 @@
 import type { Command } from 'commander';
+import chalk from 'chalk';
 import { getInstalledRoot } from '../core/userdata.js';
-import { discoverTypes } from '../core/registry.js';
+import { discoverAllCached } from '../core/registry.js';
+import { buildSources } from '../core/extension.js';
+import { listInstalled } from '../core/installed.js';
+import { APP_NAME } from '../config/branding.js';
+import { findRepoRoot } from '../utils/git.js';
 import { printTable } from '../ui/table.js';
-import { parseBaseFile } from '../core/manifest.js';
+import { emitJson, wantsJson, failError, warn } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 
 export function registerList(program: Command): void {
   program
     .command('list')
     .description('List installed types')
     .option('--type <category>', 'Filter by type')
+    .option('--topic <topic>', 'Filter by topic')
+    .option('--outdated', 'Only show types with a newer version available')
+    .option('--no-check', 'Skip checking sources for newer versions')
     .option('--json', 'Output as JSON')
-    .action((opts) => {
+    .action(async (opts) => {
       try {
-        const installedRoot = getInstalledRoot();
-        const sources = [{ name: 'installed', basePath: installedRoot }];
-        let types = discoverTypes(sources);
+        const warnings: string[] = [];
+        const available = opts.check
+          ? await discoverAllCached(buildSources(findRepoRoot() ?? process.cwd()), undefined, warnings)
+          : [];
+        for (const w of warnings) warn(w, 'registry');
 
-        if (opts.type) {
-          types = types.filter((t) => t.category === opts.type);
-        }
+        let types = listInstalled(getInstalledRoot(), { type: opts.type, topic: opts.topic }, available);
+        if (opts.outdated) types = types.filter((type) => type.latest);
 
-        if (opts.json) {
-          const enriched = types.map((t) => {
-            try {
-              const base = parseBaseFile(t.manifestPath);
-              return { ...t, version: base.version, description: base.description };
-            } catch {
-              return t;
-            }
-          });
-          console.log(JSON.stringify(enriched, null, 2));
+        if (wantsJson(opts)) {
+          emitJson(types);
           return;
         }
 
         if (types.length === 0) {
-          console.log('No installed types found.');
+          console.log(t('list.none'));
           return;
         }
 
-        const rows = types.map((t) => {
-          try {
-            const base = parseBaseFile(t.manifestPath);
-            return [t.category, t.typePath, base.version];
-          } catch {
-            return [t.category, t.typePath, '?'];
-          }
-        });
+        printTable(
+          [t('col.type'), t('col.path'), t('col.version'), t('col.source'), t('col.installed')],
+          types.map((type) => [
+            type.category,
+            type.typePath,
+            type.latest ? `${type.version} ${chalk.yellow(`→ ${type.latest}`)}` : type.version,
+            type.source ?? '-',
+            type.installedAt?.slice(0, 10) ?? '-',
+          ]),
+        );
 
-        printTable(['Type', 'Path', 'Version'], rows);
+        const outdated = types.filter((type) => type.latest).length;
+        if (outdated > 0) {
+          console.log(`\n${t('list.outdated', { count: outdated, command: `${APP_NAME} install <type-path>` })}`);
+        }
       } catch (err) {
-        console.error(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -0,0 +1,250 @@
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { envVar } from '../config/branding.js';
+import * as settings from '../config/settings.js';
+import { setExecutionContext, type ExecutionContext, type Answers } from '../config/context.js';
+import { getDebugLogPath, getLogsDir, getHomeRoot } from '../core/userdata.js';
+import { acquireLock } from '../core/lock.js';
+import { startUsage, finishUsage, FLUSH_BATCH } from '../core/telemetry.js';
+import { setOutputFormat, setQuiet, info, failError, type OutputFormat } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { flushTelemetryInBackground } from './stats.js';
+import { enableFsTrace, summarizeFsOps } from '../utils/fs-trace.js';
+import { configureHttp } from '../utils/http.js';
+import { compilePatterns, configureRedaction } from '../utils/redact.js';
+import { parseDuration } from '../utils/units.js';
+import { configureLogging, isLogLevel, logger, type LogLevel } from '../utils/log.js';
+
+// ── CLI middleware ──────────────────────────────────────────────────
+//
+// Global flags are declared once on the root program. Before any action
+// runs, they (and their environment variables) are folded into the
+// ExecutionContext, and each middleware applies its part of it. Adding
+// a cross-cutting behavior means a flag here, a field on the context,
+// and a middleware, not an option on every command.
+
+/** Applies the context before a command's action runs. */
+export type Middleware = (ctx: ExecutionContext, command: Command) => void | Promise<void>;
+
+export interface GlobalOptions {
+  outputFormat?: string;
+  input?: boolean;
+  color?: boolean;
+  traceFs?: boolean;
+  verbose?: boolean;
+  quiet?: boolean;
+  logJson?: boolean;
+  nonInteractive?: boolean;
+  yes?: boolean;
+  offline?: boolean;
+}
+
+function truthy(value: string | undefined): boolean {
+  return value !== undefined && value !== '' && value !== '0' && value.toLowerCase() !== 'false';
+}
+
+function logLevel(opts: GlobalOptions, env: NodeJS.ProcessEnv): LogLevel {
+  if (opts.verbose) return 'debug';
+  if (opts.quiet) return 'error';
+  const fromEnv = env[envVar('LOG_LEVEL')];
+  return fromEnv && isLogLevel(fromEnv) ? fromEnv : 'warn';
+}
+
+function answers(opts: GlobalOptions, env: NodeJS.ProcessEnv): Answers {
+  if (opts.yes) return 'yes';
+  if (opts.nonInteractive || truthy(env[envVar('NONINTERACTIVE')])) return 'defaults';
+  return 'ask';
+}
+
+/** The context for parsed global options and the environment. */
+export function buildContext(opts: GlobalOptions, env: NodeJS.ProcessEnv = process.env): ExecutionContext {
+  const output = (opts.outputFormat ?? env[envVar('OUTPUT_FORMAT')] ?? 'text') as OutputFormat;
+  return {
+    output,
+    interactive:
+      opts.input !== false &&
+      !truthy(env[envVar('NO_INPUT')]) &&
+      !truthy(env.CI) &&
+      Boolean(process.stdin.isTTY),
+    answers: answers(opts, env),
+    color: opts.color !== false && env.NO_COLOR === undefined,
+    traceFs: Boolean(opts.traceFs) || truthy(env[envVar('TRACE_FS')]),
+    logLevel: logLevel(opts, env),
+    logJson: Boolean(opts.logJson) || truthy(env[envVar('LOG_JSON')]),
+    quiet: Boolean(opts.quiet),
+    offline: Boolean(opts.offline) || truthy(env[envVar('OFFLINE')]) || settings.get('offline') === 'true',
+  };
+}
+
+const outputFormat: Middleware = (ctx) => setOutputFormat(ctx.output);
+
+const color: Middleware = (ctx) => {
+  if (!ctx.color) chalk.level = 0;
+};
+
+const traceFs: Middleware = (ctx) => {
+  if (!ctx.traceFs) return;
+  enableFsTrace(getDebugLogPath(), (traced) => {
+    const kinds = Object.entries(summarizeFsOps(traced))
+      .map(([kind, s]) => `${s.count} ${kind} (${s.ms.toFixed(0)}ms)`)
+      .join(', ');
+    info(t('trace.summary', { count: traced.length, kinds, path: getDebugLogPath() }));
+  });
+};
+
+/** Extra secret patterns for utils/redact.ts, from redact.patterns. */
+const redaction: Middleware = () => {
+  const compiled: RegExp[] = [];
+  for (const source of settings.getList('redact.patterns')) {
+    try {
+      compiled.push(...compilePatterns([source]));
+    } catch (err) {
+      logger('redact').warn(`Ignoring ${(err as Error).message}`);
+    }
+  }
+  configureRedaction({ patterns: compiled });
+};
+
+const logging: Middleware = (ctx, command) => {
+  configureLogging({ level: ctx.logLevel, json: ctx.logJson, dir: getLogsDir() });
+  setQuiet(ctx.quiet);
+  logger('cli').info(`${commandPath(command)} started`, { pid: process.pid, cwd: process.cwd() });
+};
+
+/** Proxy, CA bundle, and timeouts for utils/http.ts, from settings. */
+const http: Middleware = () => {
+  const log = logger('http');
+  const duration = (raw: string, what: string): number | undefined => {
+    try {
+      return parseDuration(raw);
+    } catch {
+      log.warn(`Ignoring invalid ${what} "${raw}"`);
+      return undefined;
+    }
+  };
+
+  const hostTimeouts: Record<string, number> = {};
+  for (const entry of settings.getList('http.host_timeouts')) {
+    const [host, raw] = entry.split('=');
+    const ms = host && raw ? duration(raw, `timeout for ${host}`) : undefined;
+    if (ms) hostTimeouts[host] = ms;
+  }
+  const timeout = settings.get('http.timeout');
+  const timeoutMs = timeout ? duration(timeout, 'http.timeout') : undefined;
+  configureHttp({
+    caBundle: settings.get('http.ca_bundle') || process.env.NODE_EXTRA_CA_CERTS || null,
+    hostTimeouts,
+    ...(timeoutMs ? { timeoutMs } : {}),
+  });
+};
+
+// Commands that write shared state, and which locks they take: the
+// user's ~/.agentx/ (installed root, store, registries) and/or the
+// current project's .agentx/ (project.yaml, generated tool files).
+const LOCKED_COMMANDS: Record<string, ('userdata' | 'project')[]> = {
+  init: ['userdata', 'project'],
+  import: ['userdata', 'project'],
+  install: ['userdata'],
+  uninstall: ['userdata'],
+  rollback: ['userdata'],
+  'catalog update': ['userdata'],
+  'extension add': ['userdata'],
+  'extension remove': ['userdata'],
+  'extension sync': ['userdata'],
+  'registry import': ['userdata'],
+  'backup restore': ['userdata'],
+  'schedule add': ['userdata'],
+  'schedule remove': ['userdata'],
+  'pack import': ['userdata'],
+  'state clear': ['userdata'],
+  'versions add': ['userdata'],
+  'versions remove': ['userdata'],
+  'versions default': ['userdata'],
+  gc: ['userdata'],
+  'link add': ['project'],
+  'link remove': ['project'],
+  'link sync': ['project'],
+  'overrides add': ['project'],
+  'overrides remove': ['project'],
+  'overrides resolve': ['project'],
+};
+
+/** The lock.timeout setting in ms; undefined for the default. */
+export function lockTimeoutMs(): number | undefined {
+  const raw = settings.get('lock.timeout');
+  try {
+    return raw ? parseDuration(raw) : undefined;
+  } catch {
+    logger('lock').warn(`Ignoring invalid lock.timeout "${raw}"`);
+    return undefined;
+  }
+}
+
+/** Serializes commands that write shared state (see core/lock.ts). */
+const locking: Middleware = async (_ctx, command) => {
+  const path = commandPath(command);
+  const scopes = LOCKED_COMMANDS[path];
+  if (!scopes) return;
+
+  const timeoutMs = lockTimeoutMs();
+  const onWait = (owner: { pid: number; command: string }) =>
+    info(t('lock.waiting', { pid: owner.pid, command: owner.command }));
+
+  try {
+    if (scopes.includes('userdata')) {
+      await acquireLock(getHomeRoot(), { command: path, timeoutMs, onWait });
+    }
+    const projectDir = join(process.cwd(), '.agentx');
+    if (scopes.includes('project') && existsSync(projectDir)) {
+      await acquireLock(projectDir, { command: path, timeoutMs, onWait });
+    }
+  } catch (err) {
+    failError(err);
+    process.exit(1);
+  }
+};
+
+/** Records the command in usage stats when it exits (see core/telemetry.ts). */
+const telemetry: Middleware = (_ctx, command) => {
+  const path = commandPath(command);
+  if (path === 'stats flush') return;
+  startUsage(path);
+  process.once('exit', (code) => {
+    if (finishUsage(code) >= FLUSH_BATCH) flushTelemetryInBackground();
+  });
+};
+
+function commandPath(command: Command): string {
+  const names: string[] = [];
+  for (let c: Command | null = command; c?.parent; c = c.parent) names.unshift(c.name());
+  return names.join(' ');
+}
+
+const middlewares: Middleware[] = [outputFormat, color, redaction, logging, http, traceFs, telemetry, locking];
+
+/** Adds a middleware, run after the built-in ones in registration order. */
+export function use(middleware: Middleware): void {
+  middlewares.push(middleware);
+}
+
+/** Declares the global flags and runs the middleware before every action. */
+export function installMiddleware(program: Command): Command {
+  return program
+    .option('--output-format <format>', 'Output format: text, or json for a { data, warnings } envelope')
+    .option('--no-input', 'Never prompt; fail where input would be needed (also CI=true)')
+    .option('--non-interactive', 'Never prompt; take safe defaults and fail where a decision is needed (also AGENTX_NONINTERACTIVE)')
+    .option('-y, --yes', 'Like --non-interactive, and answer yes to every confirmation')
+    .option('--no-color', 'Disable colored output (also NO_COLOR)')
+    .option('--trace-fs', `Log every filesystem call with its duration to ${getDebugLogPath()}`)
+    .option('-v, --verbose', 'Show debug logs on stderr')
+    .option('-q, --quiet', 'Show only errors')
+    .option('--log-json', 'Write logs on stderr as JSON lines')
+    .option('--offline', 'Skip update checks, catalog and extension fetches, and npm installs (also AGENTX_OFFLINE)')
+    .hook('preAction', async (root, actionCommand) => {
+      const ctx = buildContext(root.opts<GlobalOptions>());
+      setExecutionContext(ctx);
+      for (const middleware of middlewares) await middleware(ctx, actionCommand);
+    });
+}
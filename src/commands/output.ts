@@ -0,0 +1,61 @@
+import type { Command } from 'commander';
+import { listHistory, readOutput } from '../core/output-history.js';
+import { skillPath } from './state.js';
+import { formatBytes } from '../utils/units.js';
+import { info, note, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+export function registerOutput(program: Command): void {
+  const cmd = program
+    .command('output')
+    .description('Browse the outputs skills saved on previous runs');
+
+  cmd
+    .command('list')
+    .description("List a skill's output history, newest first")
+    .argument('<skill>', 'Skill path (e.g. skills/org/name)')
+    .option('--json', 'Output as JSON')
+    .action((skill: string, opts) => {
+      try {
+        const history = listHistory(skillPath(skill));
+        if (wantsJson(opts)) {
+          emitJson(history);
+        } else if (history.length === 0) {
+          info(t('output.none', { skill: skillPath(skill) }));
+        } else {
+          printTable(
+            [t('col.run'), t('col.saved'), t('col.size')],
+            history.map((h) => [String(h.run), h.savedAt.toISOString(), formatBytes(h.bytes)]),
+          );
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('show')
+    .description("Print a skill's latest output, or an earlier one with --run")
+    .argument('<skill>', 'Skill path')
+    .option('--run <n>', 'Output of the nth most recent run (1 = most recent)', (v) => Number.parseInt(v, 10))
+    .option('--json', 'Output as JSON')
+    .action((skill: string, opts) => {
+      try {
+        if (opts.run !== undefined && !(opts.run >= 1)) {
+          throw new Error(t('output.invalidRun'));
+        }
+        const { entry, output } = readOutput(skillPath(skill), opts.run);
+        if (wantsJson(opts)) {
+          emitJson({ run: entry?.run ?? null, savedAt: entry?.savedAt ?? null, output });
+          return;
+        }
+        if (entry) note(t('output.entry', { run: entry.run, saved: entry.savedAt.toISOString() }));
+        console.log(typeof output === 'string' ? output : JSON.stringify(output, null, 2));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
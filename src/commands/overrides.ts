@@ -0,0 +1,124 @@
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { getInstalledRoot } from '../core/userdata.js';
+import {
+  listOverrides,
+  addOverride,
+  removeOverride,
+  resolveOverride,
+  type OverrideFile,
+  type Resolution,
+} from '../core/overrides.js';
+import { APP_NAME } from '../config/branding.js';
+import { printTable } from '../ui/table.js';
+import { askSelect } from '../ui/prompts.js';
+import { emitJson, wantsJson, ok, info, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+export function registerOverrides(program: Command): void {
+  const cmd = program
+    .command('overrides')
+    .description('Manage project overrides of installed type files');
+
+  cmd
+    .command('list')
+    .description('List overridden files and whether they have conflicts')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const overrides = listOverrides(process.cwd(), getInstalledRoot());
+        if (wantsJson(opts)) {
+          emitJson(overrides);
+          return;
+        }
+        if (overrides.length === 0) {
+          console.log(t('overrides.none'));
+          return;
+        }
+        printTable(
+          [t('col.type'), t('col.file'), t('col.state')],
+          overrides.map((o) => [o.typePath, o.file, o.conflicted ? chalk.red(t('overrides.conflict')) : t('overrides.ok')]),
+        );
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('add')
+    .description('Override a file of an installed type in this project')
+    .argument('<type-path>', 'Installed type')
+    .argument('<file>', 'File within the type (e.g., content.md)')
+    .action((typePath, file) => {
+      try {
+        const path = addOverride(process.cwd(), getInstalledRoot(), typePath, file);
+        ok(t('overrides.created', { path, command: `${APP_NAME} link sync` }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('remove')
+    .description('Stop overriding a file and use upstream again')
+    .argument('<type-path>', 'Installed type')
+    .argument('<file>', 'Overridden file')
+    .action((typePath, file) => {
+      try {
+        removeOverride(process.cwd(), typePath, file);
+        ok(t('overrides.removed', { file: `${typePath}/${file}` }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('resolve')
+    .description('Resolve overrides left with conflict markers by an upstream merge')
+    .argument('[type-path]', 'Only overrides of this type')
+    .argument('[file]', 'Only this file')
+    .option('--ours', "Keep the override's side of every conflict")
+    .option('--theirs', 'Take upstream and drop the override')
+    .option('--merged', 'Accept files you resolved by hand (no markers may remain)')
+    .action(async (typePath, file, opts) => {
+      try {
+        const projectPath = process.cwd();
+        const installedRoot = getInstalledRoot();
+        const conflicted = listOverrides(projectPath, installedRoot).filter(
+          (o) => o.conflicted && (!typePath || o.typePath === typePath) && (!file || o.file === file),
+        );
+        if (conflicted.length === 0) {
+          ok(t('overrides.noConflicts'));
+          return;
+        }
+
+        const chosen: Resolution | undefined = opts.ours ? 'ours' : opts.theirs ? 'theirs' : opts.merged ? 'merged' : undefined;
+        for (const o of conflicted) {
+          const resolution = chosen ?? (await askResolution(o));
+          if (!resolution) {
+            info(t('overrides.skipped', { file: `${o.typePath}/${o.file}` }));
+            continue;
+          }
+          resolveOverride(projectPath, installedRoot, o.typePath, o.file, resolution);
+          ok(t('overrides.resolved', { file: `${o.typePath}/${o.file}`, resolution }));
+        }
+        console.log(`\n${t('overrides.syncHint', { command: `${APP_NAME} link sync` })}`);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
+
+async function askResolution(o: OverrideFile): Promise<Resolution | undefined> {
+  const choice = await askSelect(t('overrides.askResolution', { file: `${o.typePath}/${o.file}` }), [
+    { name: t('overrides.choice.ours'), value: 'ours' },
+    { name: t('overrides.choice.theirs'), value: 'theirs' },
+    { name: t('overrides.choice.merged'), value: 'merged' },
+    { name: t('overrides.choice.skip'), value: 'skip' },
+  ], undefined, { hint: t('overrides.resolutionHint') });
+  return choice === 'skip' ? undefined : choice;
+}
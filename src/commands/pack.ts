@@ -0,0 +1,64 @@
+import type { Command } from 'commander';
+import { resolve } from 'node:path';
+import { getInstalledRoot } from '../core/userdata.js';
+import { exportPack, importPack } from '../core/pack.js';
+import { notifyChange } from '../core/notify.js';
+import { emitJson, wantsJson, ok, failError, warn, info } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+export function registerPack(program: Command): void {
+  const cmd = program
+    .command('pack')
+    .description('Share a prompt with everything it composes from, as one archive');
+
+  cmd
+    .command('export')
+    .description('Bundle an installed prompt and its resolved dependencies (no secrets)')
+    .argument('<prompt-path>', 'Installed prompt type path (e.g., prompts/code-review)')
+    .option('-o, --output <file>', 'Archive to write', 'agentx-pack.tar.gz')
+    .option('--json', 'Output as JSON')
+    .action(async (promptPath, opts) => {
+      try {
+        const archive = resolve(opts.output);
+        const result = await exportPack(promptPath, getInstalledRoot(), archive);
+        if (wantsJson(opts)) {
+          emitJson({ archive, ...result });
+          return;
+        }
+        const deps = result.types.length - 1;
+        ok(t(deps === 1 ? 'pack.packedOne' : 'pack.packed', { root: result.root, count: deps, path: archive }));
+        for (const typePath of result.types) console.log(`  ${typePath}`);
+        info(t('pack.noSecrets'));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('import')
+    .description('Install the types of a pack made by `pack export`')
+    .argument('<archive>', 'Pack to import')
+    .option('--force', 'Replace types that are already installed')
+    .option('--json', 'Output as JSON')
+    .action(async (archive, opts) => {
+      try {
+        const result = await importPack(resolve(archive), getInstalledRoot(), { overwrite: opts.force });
+        if (result.installed.length > 0) await notifyChange('install', result.installed);
+        if (wantsJson(opts)) {
+          emitJson(result);
+          return;
+        }
+        for (const w of result.warnings) warn(w, 'pack');
+        ok(t('pack.imported', { root: result.root, count: result.installed.length }));
+        for (const typePath of result.installed) console.log(`  ${typePath}`);
+        if (result.skipped.length > 0) {
+          info(t('pack.kept', { count: result.skipped.length }));
+          for (const typePath of result.skipped) console.log(`  ${typePath}`);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -0,0 +1,33 @@
+import type { Command } from 'commander';
+import { getInstalledRoot } from '../core/userdata.js';
+import { parsePipeArgs, runPipe } from '../core/pipe.js';
+import { fail, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+export function registerPipe(program: Command): void {
+  program
+    .command('pipe')
+    .description("Run skills in sequence, mapping each skill's output to the next one's inputs")
+    .argument('<stages...>', 'skill [-i key=value...] -- skill [--map output=input...] [-i key=value...] ...')
+    .passThroughOptions()
+    .action(async (args: string[]) => {
+      try {
+        const stages = parsePipeArgs(args);
+        const last = stages.length - 1;
+        const result = await runPipe(stages, getInstalledRoot(), {
+          onOutput: (stage, stream, chunk) => {
+            if (stream === 'stderr') process.stderr.write(chunk);
+            else if (stage === last) process.stdout.write(chunk);
+          },
+        });
+        if (result.exitCode !== 0) {
+          const failed = result.stages[result.stages.length - 1];
+          fail(t('pipe.failed', { type: failed.typePath, code: String(failed.exitCode) }));
+          process.exit(result.exitCode);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
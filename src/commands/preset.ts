@@ -0,0 +1,40 @@
+import type { Command } from 'commander';
+import { buildSources } from '../core/extension.js';
+import { listPresets, presetTypes } from '../core/preset.js';
+import { findRepoRoot } from '../utils/git.js';
+import { failError, warn, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+export function registerPreset(program: Command): void {
+  const cmd = program
+    .command('preset')
+    .description('Project presets: named bundles of types for `agentx init --preset`');
+
+  cmd
+    .command('list')
+    .description('List presets from the catalog and extensions')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const warnings: string[] = [];
+        const presets = listPresets(buildSources(findRepoRoot() ?? process.cwd()), warnings);
+        for (const w of warnings) warn(w, 'preset');
+        if (wantsJson(opts)) {
+          emitJson(presets.map((p) => ({ ...p, types: presetTypes(p) })));
+          return;
+        }
+        if (presets.length === 0) {
+          console.log(t('preset.none'));
+          return;
+        }
+        printTable(
+          [t('col.name'), t('col.source'), t('catalog.col.types'), t('col.description')],
+          presets.map((p) => [p.name, p.source, String(presetTypes(p).length), p.description ?? '']),
+        );
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -6,7 +6,8 @@ import {
   loadProfile,
   switchProfile,
 } from '../core/userdata.js';
-import { ok, fail } from '../ui/output.js';
+import { ok, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 
 export function registerProfile(program: Command): void {
   const cmd = program
@@ -20,11 +21,11 @@ export function registerProfile(program: Command): void {
       const profiles = listProfiles();
       const active = activeProfileName();
       if (profiles.length === 0) {
-        console.log('No profiles found. Run `agentx init --global` first.');
+        console.log(t('profile.none'));
         return;
       }
       for (const name of profiles) {
-        const marker = name === active ? ' (active)' : '';
+        const marker = name === active ? ` (${t('profile.active')})` : '';
         console.log(`  ${name}${marker}`);
       }
     });
@@ -36,9 +37,9 @@ export function registerProfile(program: Command): void {
     .action((name) => {
       try {
         switchProfile(name);
-        ok(`Switched to profile: ${name}`);
+        ok(t('profile.switched', { name }));
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -51,11 +52,11 @@ export function registerProfile(program: Command): void {
     .action((opts) => {
       const profile = loadProfile();
       if (!profile) {
-        console.log('No active profile.');
+        console.log(t('profile.noActive'));
         return;
       }
-      if (opts.json) {
-        console.log(JSON.stringify(profile, null, 2));
+      if (wantsJson(opts)) {
+        emitJson(profile);
       } else if (opts.yaml) {
         console.log(yaml.dump(profile));
       } else {
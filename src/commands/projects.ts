@@ -0,0 +1,80 @@
+import type { Command } from 'commander';
+import { knownProjects, syncKnownProjects, type ProjectSyncResult } from '../core/projects.js';
+import { loadProject } from '../core/linker.js';
+import { ok, info, warn, fail, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+import { approveContribution } from './trust.js';
+import { lockTimeoutMs } from './middleware.js';
+
+function linkedCount(projectPath: string): number {
+  try {
+    return Object.values(loadProject(projectPath).active).reduce((n, refs) => n + (refs?.length ?? 0), 0);
+  } catch {
+    return 0;
+  }
+}
+
+export function registerProjects(program: Command): void {
+  const cmd = program
+    .command('projects')
+    .description('List and re-link the projects set up on this machine');
+
+  cmd
+    .command('list')
+    .description('List known projects with their tools and last sync')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const projects = knownProjects().map((p) => ({ ...p, linked: linkedCount(p.path) }));
+        if (wantsJson(opts)) {
+          emitJson(projects);
+        } else if (projects.length === 0) {
+          info(t('projects.none'));
+        } else {
+          printTable(
+            [t('col.project'), t('col.tools'), t('col.linked'), t('col.lastSync')],
+            projects.map((p) => [p.path, p.tools.join(', ') || '-', String(p.linked), p.lastSync ?? t('schedule.never')]),
+          );
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('sync-all')
+    .description('Run link sync in every known project (e.g. after a catalog upgrade)')
+    .option('--regenerate-all', 'Delete previously generated files first')
+    .option('--json', 'Output as JSON')
+    .action(async (opts) => {
+      let results: ProjectSyncResult[] = [];
+      try {
+        results = await syncKnownProjects({
+          regenerateAll: opts.regenerateAll,
+          approveHook: approveContribution,
+          lockTimeoutMs: lockTimeoutMs(),
+        });
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+
+      if (wantsJson(opts)) {
+        emitJson(results);
+      } else if (results.length === 0) {
+        info(t('projects.noneToSync'));
+      } else {
+        for (const r of results) {
+          if (!r.ok) {
+            fail(`${r.path}: ${r.error}`);
+            continue;
+          }
+          for (const w of r.warnings) warn(`${r.path}: ${w}`, 'sync');
+          ok(t('projects.synced', { path: r.path }));
+        }
+      }
+      if (results.some((r) => !r.ok)) process.exit(1);
+    });
+}
@@ -1,65 +1,158 @@
 import type { Command } from 'commander';
-import { writeFileSync } from 'node:fs';
-import { execFileSync } from 'node:child_process';
+import { writeFileSync, existsSync } from 'node:fs';
 import { getInstalledRoot } from '../core/userdata.js';
-import { compose, render } from '../core/compose.js';
-import { ok, fail } from '../ui/output.js';
+import {
+  compose,
+  render,
+  applyBudget,
+  renderFormats,
+  resolveVariables,
+  applyVariables,
+  type ComposedPrompt,
+} from '../core/compose.js';
+import { loadProject, projectConfigPath } from '../core/linker.js';
+import { projectTypeDirs } from '../core/versions.js';
+import { diffPrompt } from '../core/prompt-diff.js';
+import { countTokens } from '../core/tokens.js';
+import { prefetchPromptContext } from '../core/context-sources.js';
+import { buildSources } from '../core/extension.js';
+import { resolveDev } from '../core/dev.js';
+import { findRepoRoot } from '../utils/git.js';
+import { copyToClipboard } from '../utils/platform.js';
+import { parseInputArgs } from '../utils/input-parser.js';
+import { ok, info, failError, warn, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { askInput } from '../ui/prompts.js';
+
+function collectVar(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+/** Fills in the prompt's variables, asking for required ones nothing gave. */
+async function fillVariables(cp: ComposedPrompt, pairs: string[], projectPath: string): Promise<ComposedPrompt> {
+  if (cp.variables.length === 0 && pairs.length === 0) return cp;
+  const project = existsSync(projectConfigPath(projectPath)) ? loadProject(projectPath).variables : undefined;
+  const { values, missing } = resolveVariables(cp, { flags: parseInputArgs(pairs), project });
+  for (const v of missing) {
+    values[v.name] = await askInput(v.description ?? v.name, undefined, { hint: t('prompt.passVar', { name: v.name }) });
+  }
+  return applyVariables(cp, values);
+}
 
 export function registerPrompt(program: Command): void {
-  program
+  const cmd = program
     .command('prompt')
     .description('Compose a prompt from installed types')
     .argument('[prompt-type-path]', 'Path to installed prompt type')
+    .option('-f, --format <format>', `Output format (${renderFormats().join(', ')})`, 'markdown')
     .option('--copy', 'Copy output to clipboard')
     .option('-o, --output <file>', 'Write output to file')
-    .action((promptPath, opts) => {
+    .option('--max-tokens <n>', 'Drop or truncate lowest-priority context to fit a token budget')
+    .option('--budget', 'Append a per-section token budget summary')
+    .option('--no-template', "Ignore the prompt's .hbs template and use the built-in layout")
+    .option('--var <key=value>', 'Value for a prompt variable (repeatable)', collectVar, [])
+    .option('--dev', 'Compose from the sources instead of installed types, so edits apply without reinstalling')
+    .action(async (promptPath, opts) => {
       try {
         if (!promptPath) {
-          console.log('Interactive mode not yet implemented. Provide a prompt type path.');
+          console.log(t('prompt.pathRequired'));
           process.exit(1);
         }
 
         const installedRoot = getInstalledRoot();
-        const composed = compose(promptPath, installedRoot);
-        const output = render(composed);
+        const projectPath = findRepoRoot() ?? process.cwd();
+        const typeDirs = opts.dev
+          ? resolveDev(promptPath, buildSources(projectPath), installedRoot).dirs
+          : projectTypeDirs(projectPath, installedRoot);
+        for (const w of await prefetchPromptContext(promptPath, installedRoot, typeDirs)) {
+          warn(w, 'context');
+        }
 
-        if (composed.warnings.length) {
-          for (const w of composed.warnings) {
-            console.error(`⚠ ${w}`);
+        let composed = compose(promptPath, installedRoot, typeDirs);
+        composed = await fillVariables(composed, opts.var, projectPath);
+        if (opts.maxTokens) {
+          const max = parseInt(opts.maxTokens, 10);
+          if (Number.isNaN(max) || max <= 0) {
+            throw new Error(t('common.invalidValue', { flag: '--max-tokens', value: opts.maxTokens }));
           }
+          composed = applyBudget(composed, max);
         }
+        const output = render(composed, {
+          format: opts.format,
+          budget: opts.budget,
+          builtin: opts.template === false,
+          partialSources: [
+            { name: 'installed', basePath: installedRoot },
+            ...buildSources(findRepoRoot() ?? process.cwd()),
+          ],
+        });
 
-        if (opts.output) {
-          writeFileSync(opts.output, output, 'utf-8');
-          ok(`Written to: ${opts.output}`);
-        } else if (opts.copy) {
+        for (const w of composed.warnings) warn(w, 'compose');
+
+        const outFile: string | undefined = opts.output;
+        const summary = t('prompt.summary', { bytes: Buffer.byteLength(output, 'utf-8'), tokens: countTokens(output) });
+
+        if (outFile) {
+          writeFileSync(outFile, output, 'utf-8');
+          ok(t('prompt.written', { path: outFile, summary }));
+        }
+        if (opts.copy) {
           copyToClipboard(output);
-          ok('Copied to clipboard.');
-        } else {
+          ok(t('prompt.copied', { summary }));
+        }
+        if (!outFile && !opts.copy) {
           console.log(output);
         }
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
-}
 
-function copyToClipboard(text: string): void {
-  const platform = process.platform;
-  let cmd: string;
-  let args: string[];
-
-  if (platform === 'darwin') {
-    cmd = 'pbcopy';
-    args = [];
-  } else if (platform === 'win32') {
-    cmd = 'clip';
-    args = [];
-  } else {
-    cmd = 'xclip';
-    args = ['-selection', 'clipboard'];
-  }
+  cmd
+    .command('diff')
+    .description('Show how the latest catalog and extension versions would change a composed prompt')
+    .argument('<prompt-type-path>', 'Path to the prompt type')
+    .option('-f, --format <format>', `Output format (${renderFormats().join(', ')})`, 'markdown')
+    .option('--no-template', "Ignore the prompt's .hbs template and use the built-in layout")
+    .option('--var <key=value>', 'Value for a prompt variable (repeatable)', collectVar, [])
+    .option('--json', 'Output as JSON')
+    .action((promptPath: string, opts) => {
+      try {
+        const installedRoot = getInstalledRoot();
+        const projectPath = findRepoRoot() ?? process.cwd();
+        const sources = buildSources(projectPath);
+        const result = diffPrompt(promptPath, installedRoot, sources, {
+          render: {
+            format: opts.format,
+            builtin: opts.template === false,
+            partialSources: [{ name: 'installed', basePath: installedRoot }, ...sources],
+          },
+          typeDirs: projectTypeDirs(projectPath, installedRoot),
+          variables: {
+            flags: parseInputArgs(opts.var),
+            project: existsSync(projectConfigPath(projectPath)) ? loadProject(projectPath).variables : undefined,
+          },
+        });
 
-  execFileSync(cmd, args, { input: text });
+        if (wantsJson(opts)) {
+          emitJson({ types: result.types, diff: result.diff });
+          return;
+        }
+        for (const type of result.types) {
+          if (type.installed === type.latest) continue;
+          const from = type.installed ?? t('prompt.notInstalled');
+          const to = type.latest ?? t('prompt.notInSources');
+          info(`${type.typePath}: ${from} → ${to}`);
+        }
+        if (!result.diff) {
+          ok(t('prompt.unchanged', { type: promptPath }));
+          return;
+        }
+        process.stdout.write(result.diff);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
 }
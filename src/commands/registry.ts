@@ -0,0 +1,89 @@
+import type { Command } from 'commander';
+import { resolve } from 'node:path';
+import { getInstalledRoot } from '../core/userdata.js';
+import { listRegistries, exportRegistries, importRegistries } from '../core/registry-archive.js';
+import { emitJson, wantsJson, ok, failError, warn, info } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+function collect(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+export function registerRegistry(program: Command): void {
+  const cmd = program
+    .command('registry')
+    .description("Move skills' registries (config, state, tokens) between machines");
+
+  cmd
+    .command('export')
+    .description('Write skill registries to an archive (tokens excluded unless --include-secrets)')
+    .argument('[type-path]', 'Skill to export')
+    .option('--all', 'Export every installed skill with a registry')
+    .option('-o, --output <file>', 'Archive to write', 'agentx-registry.tar.gz')
+    .option('--include-secrets', 'Also export token files (tokens.env, tokens.<account>.env), encrypted with age')
+    .option('-r, --recipient <key>', 'age recipient to encrypt secrets to (repeatable; default: passphrase)', collect, [])
+    .option('--json', 'Output as JSON')
+    .action((typePath, opts) => {
+      try {
+        if (Boolean(typePath) === Boolean(opts.all)) {
+          throw new Error(t('registry.targetRequired'));
+        }
+        const skills = opts.all ? listRegistries(getInstalledRoot()) : [typePath];
+        if (skills.length === 0) {
+          info(t('registry.nothingToExport'));
+          return;
+        }
+        if (opts.recipient.length > 0 && !opts.includeSecrets) {
+          warn(t('registry.recipientIgnored'), 'registry');
+        }
+
+        const archive = resolve(opts.output);
+        const result = exportRegistries(skills, archive, {
+          includeSecrets: opts.includeSecrets,
+          recipients: opts.recipient,
+        });
+        if (wantsJson(opts)) {
+          emitJson({ archive, ...result });
+          return;
+        }
+        ok(t(result.skills.length === 1 ? 'registry.exportedOne' : 'registry.exported', { count: result.skills.length, path: archive }));
+        if (result.secrets > 0) info(t('registry.secretsEncrypted', { count: result.secrets }));
+        else if (!opts.includeSecrets) info(t('registry.secretsSkipped'));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('import')
+    .description('Restore skill registries from an archive made by `registry export`')
+    .argument('<archive>', 'Archive to import')
+    .option('-i, --identity <file>', 'age identity file, for secrets encrypted to a recipient')
+    .option('--no-secrets', 'Skip encrypted tokens in the archive')
+    .option('--force', 'Overwrite existing files')
+    .option('--json', 'Output as JSON')
+    .action((archive, opts) => {
+      try {
+        const result = importRegistries(resolve(archive), {
+          identity: opts.identity,
+          skipSecrets: opts.secrets === false,
+          overwrite: opts.force,
+        });
+        if (wantsJson(opts)) {
+          emitJson(result);
+          return;
+        }
+        ok(t(result.skills.length === 1 ? 'registry.importedOne' : 'registry.imported', { count: result.skills.length }));
+        for (const s of result.skills) console.log(`  ${s}`);
+        if (result.secrets > 0) info(t('registry.secretsRestored', { count: result.secrets }));
+        if (result.skipped.length > 0) {
+          warn(t('common.keptExisting', { count: result.skipped.length }), 'registry');
+          for (const f of result.skipped) console.log(`  ${f}`);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -0,0 +1,74 @@
+import type { Command } from 'commander';
+import { join } from 'node:path';
+import { getInstalledRoot } from '../core/userdata.js';
+import { listSnapshots, readCurrent, materialize } from '../core/store.js';
+import { installNodeDeps, categoryFromPath } from '../core/registry.js';
+import { notifyChange } from '../core/notify.js';
+import { rebuildContentIndex } from '../core/content-index.js';
+import { ok, fail, failError, warn } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+export function registerRollback(program: Command): void {
+  program
+    .command('rollback')
+    .description('Switch an installed type to a previously installed version')
+    .argument('<type-path>', 'Path to the installed type')
+    .argument('[version]', 'Version to restore (default: the one before current)')
+    .option('--list', 'List stored versions')
+    .action(async (typePath, version, opts) => {
+      try {
+        const snapshots = listSnapshots(typePath);
+        if (snapshots.length === 0) {
+          fail(t('rollback.noSnapshots', { type: typePath }));
+          process.exit(1);
+        }
+        const current = readCurrent(typePath);
+
+        if (opts.list) {
+          printTable(
+            [t('col.version'), t('col.stored'), t('link.col.files'), ''],
+            snapshots.map((s) => [
+              s.version,
+              s.createdAt,
+              String(s.files.length),
+              s.version === current ? t('rollback.current') : '',
+            ]),
+          );
+          return;
+        }
+
+        let target = version
+          ? snapshots.find((s) => s.version === version)
+          : undefined;
+        if (!version) {
+          const idx = snapshots.findIndex((s) => s.version === current);
+          target = idx > 0 ? snapshots[idx - 1] : undefined;
+        }
+        if (!target) {
+          fail(
+            version
+              ? t('versions.notStored', { type: typePath, version, command: `agentx rollback ${typePath} --list` })
+              : current
+                ? t('rollback.noOlder', { type: typePath, version: current })
+                : t('rollback.noOlderThanCurrent', { type: typePath }),
+          );
+          process.exit(1);
+        }
+
+        const installedRoot = getInstalledRoot();
+        materialize(target, installedRoot);
+        const npmWarning = installNodeDeps(join(installedRoot, typePath));
+        if (npmWarning) warn(npmWarning);
+
+        if (categoryFromPath(typePath) === 'context') rebuildContentIndex(installedRoot);
+        await notifyChange('update', [typePath]);
+        ok(current
+          ? t('rollback.doneFrom', { type: typePath, version: target.version, previous: current })
+          : t('rollback.done', { type: typePath, version: target.version }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
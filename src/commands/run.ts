@@ -2,11 +2,48 @@ import type { Command } from 'commander';
 import { join } from 'node:path';
 import { existsSync, readFileSync } from 'node:fs';
 import yaml from 'js-yaml';
-import { getInstalledRoot } from '../core/userdata.js';
-import { runSkill } from '../core/runtime.js';
-import { parseInputArgs, validateInputs } from '../utils/input-parser.js';
-import { fail } from '../ui/output.js';
-import type { SkillManifest, WorkflowManifest } from '../types/manifest.js';
+import { getInstalledRoot, getSkillsDir } from '../core/userdata.js';
+import {
+  runSkill,
+  missingTokens,
+  saveToken,
+  tokensPathFor,
+  selectAccount,
+  activeAccount,
+  accountsFor,
+  isolatedEnv,
+  buildSkillEnv,
+  type RuntimeOutput,
+} from '../core/runtime.js';
+import { lookupRun, storeRun } from '../core/run-cache.js';
+import { didYouMean, installedTypePaths } from '../core/registry.js';
+import { findRepoRoot } from '../utils/git.js';
+import {
+  parseInputArgs,
+  readInputFile,
+  mergeInputs,
+  normalizeInputs,
+  validateInputs,
+  INPUT_PRECEDENCE,
+  type InputSources,
+} from '../utils/input-parser.js';
+import { fail, failError, warn, note } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { askConfirm, askSecret, canPrompt } from '../ui/prompts.js';
+import { APP_NAME, envVar } from '../config/branding.js';
+import { verifyType, manifestGuardMode } from '../core/integrity.js';
+import { runPublish, type StepOutput } from '../core/publish.js';
+import { openSandbox, sandboxChanges, closeSandbox, networkHints } from '../core/sandbox.js';
+import { runHooks } from '../core/hooks.js';
+import { findManifest } from '../core/executor.js';
+import { selectVersion, type SelectedVersion } from '../core/versions.js';
+import { loadProject, projectConfigPath, checkPins } from '../core/linker.js';
+import { buildSources } from '../core/extension.js';
+import { resolveDev, prepareDevSkills, type DevTypes } from '../core/dev.js';
+import { resolveStepInputs, workflowIssues } from '../core/workflow-inputs.js';
+import { refreshCacheInBackground } from './cache.js';
+import { approveContribution } from './trust.js';
+import type { InputField, SkillManifest, WorkflowManifest } from '../types/manifest.js';
 
 export function registerRun(program: Command): void {
   program
@@ -14,92 +51,358 @@ export function registerRun(program: Command): void {
     .description('Execute a skill or workflow')
     .argument('<type-path>', 'Path to installed skill or workflow')
     .option('-i, --input <key=value...>', 'Input key=value pairs', collectInputs, [])
+    .option('--input-file <path>', 'Read inputs from a YAML or JSON file (-i flags override it)')
+    .option('--stdin-input <name>', 'Pass everything piped on stdin as the named input')
+    .option('--cache', 'Reuse results from identical runs in this workspace')
+    .option('--sandbox', "Run against a throwaway copy of the skill's registry; warn about network use")
+    .option('--account <name>', 'Use the tokens.<name>.env token set (default: tokens.account)')
+    .option('--dev', 'Run from the sources instead of the installed copy, so edits apply without reinstalling')
     .action(async (typePath, opts) => {
       try {
+        if (opts.account) selectAccount(opts.account);
         const installedRoot = getInstalledRoot();
-        const typeDir = join(installedRoot, typePath);
+        const dev = opts.dev ? loadDev(typePath, installedRoot) : null;
+        if (dev && opts.cache) {
+          warn(t('run.cacheIgnoredDev'), 'dev');
+          opts.cache = false;
+        }
+        // The directory to run a type from: its source with --dev, else the installed version
+        const typeDirOf = async (path: string) => (dev ? dev.dirs[path] : selectTypeDir(path, installedRoot));
 
-        if (!existsSync(typeDir)) {
-          fail(`Type not installed: ${typePath}. Run \`agentx install ${typePath}\` first.`);
+        if (!dev && !existsSync(join(installedRoot, typePath))) {
+          const hint = didYouMean(typePath, installedTypePaths(installedRoot));
+          fail(hint ? t('common.notInstalledHint', { type: typePath, hint }) : t('common.notInstalled', { type: typePath }));
           process.exit(1);
         }
 
+        const typeDir = await typeDirOf(typePath);
+
         // Find and parse manifest
         const manifestPath = findManifest(typeDir);
         if (!manifestPath) {
-          fail(`No manifest found in: ${typeDir}`);
+          fail(t('run.noManifest', { dir: typeDir }));
           process.exit(1);
         }
 
         const raw = readFileSync(manifestPath, 'utf-8');
-        const data = yaml.load(raw) as { type: string };
-        const inputs = parseInputArgs(opts.input);
+        const data = yaml.load(raw) as { type: string; inputs?: InputField[] };
+        const sources: InputSources = {
+          flags: parseInputArgs(opts.input, data.inputs),
+          file: opts.inputFile ? readInputFile(opts.inputFile) : undefined,
+          stdin: opts.stdinInput ? { name: opts.stdinInput, value: readStdin() } : undefined,
+        };
+
+        // Skills can run for a while; refresh discovery alongside them
+        refreshCacheInBackground();
 
         if (data.type === 'skill') {
           const manifest = data as unknown as SkillManifest;
+          const inputs = mergeInputs(sources, manifest.inputs);
+          await askMissingSecrets(typePath, manifest.inputs ?? [], inputs);
 
           // Validate inputs
           if (manifest.inputs) {
             const errors = validateInputs(inputs, manifest.inputs);
             if (errors.length > 0) {
               for (const e of errors) fail(e);
+              fail(t('common.inputPrecedence', { order: INPUT_PRECEDENCE }));
               process.exit(1);
             }
           }
 
-          const result = await runSkill(typeDir, manifest, inputs);
+          if (opts.account) requireAccount(typePath, typeDir, opts.account);
+          await provideMissingTokens(typePath, typeDir, manifest);
+          const result = await execSkill(typePath, typeDir, manifest, inputs, opts);
           if (result.stdout) process.stdout.write(result.stdout);
           if (result.stderr) process.stderr.write(result.stderr);
           process.exit(result.exitCode);
         } else if (data.type === 'workflow') {
           const manifest = data as unknown as WorkflowManifest;
+          const issues = workflowIssues(manifest);
+          if (issues.length > 0) {
+            for (const i of issues) fail(`${i.path.join('.')}: ${i.message}`);
+            fail(t('run.invalidWorkflow', { workflow: typePath, command: `${APP_NAME} validate ${manifestPath}` }));
+            process.exit(1);
+          }
+          const inputs = mergeInputs(sources, manifest.inputs);
+          const errors = validateInputs(inputs, manifest.inputs ?? []);
+          if (errors.length > 0) {
+            for (const e of errors) fail(e);
+            fail(t('common.inputPrecedence', { order: INPUT_PRECEDENCE }));
+            process.exit(1);
+          }
           // Run workflow steps sequentially
+          const outputs = new Map<string, StepOutput>();
           for (const step of manifest.steps) {
-            const skillDir = join(installedRoot, step.skill);
-            if (!existsSync(skillDir)) {
-              fail(`Workflow step skill not installed: ${step.skill}`);
+            if ('publish' in step) {
+              if (opts.sandbox) {
+                note(t('run.publishSkipped', { step: step.id, to: step.publish.to }));
+                outputs.set(step.id, { stdout: '' });
+                continue;
+              }
+              const published = await runPublish(step.publish, {
+                workflow: manifest.name,
+                inputs,
+                outputs,
+              });
+              note(
+                t('run.published', {
+                  step: step.id,
+                  bytes: published.bytes,
+                  contentType: published.contentType,
+                  destination: published.destination,
+                }),
+              );
+              outputs.set(step.id, { stdout: published.destination });
+              continue;
+            }
+            if (dev ? !dev.dirs[step.skill] : !existsSync(join(installedRoot, step.skill))) {
+              fail(t(dev ? 'run.stepNotFound' : 'run.stepNotInstalled', { skill: step.skill }));
               process.exit(1);
             }
+            const skillDir = await typeDirOf(step.skill);
             const skillManifestPath = findManifest(skillDir);
             if (!skillManifestPath) {
-              fail(`No manifest for workflow step: ${step.skill}`);
+              fail(t('run.stepNoManifest', { skill: step.skill }));
               process.exit(1);
             }
             const skillRaw = readFileSync(skillManifestPath, 'utf-8');
             const skillManifest = yaml.load(skillRaw) as SkillManifest;
-            const stepInputs = step.inputs
-              ? Object.fromEntries(
-                  Object.entries(step.inputs).map(([k, v]) => [k, String(v)]),
-                )
-              : {};
+            const stepInputs = resolveStepInputs(step.inputs, inputs, outputs);
             // Merge workflow-level inputs
-            const mergedInputs = { ...inputs, ...stepInputs };
-            const result = await runSkill(skillDir, skillManifest, mergedInputs);
+            const mergedInputs = normalizeInputs({ ...inputs, ...stepInputs }, skillManifest.inputs);
+            await provideMissingTokens(step.skill, skillDir, skillManifest);
+            const result = await execSkill(
+              step.skill,
+              skillDir,
+              skillManifest,
+              mergedInputs,
+              opts,
+            );
             if (result.stdout) process.stdout.write(result.stdout);
             if (result.stderr) process.stderr.write(result.stderr);
             if (result.exitCode !== 0) {
               process.exit(result.exitCode);
             }
+            outputs.set(step.id, { stdout: result.stdout });
           }
         } else {
-          fail(`Cannot run type: ${data.type}. Only skills and workflows are runnable.`);
+          fail(t('run.notRunnable', { type: data.type }));
           process.exit(1);
         }
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 }
 
-function collectInputs(value: string, previous: string[]): string[] {
-  return [...previous, value];
+/**
+ * Resolves the type and what it references from the sources for --dev,
+ * preparing the skills among them to run from there.
+ */
+function loadDev(typePath: string, installedRoot: string): DevTypes {
+  const dev = resolveDev(typePath, buildSources(findRepoRoot() ?? process.cwd()), installedRoot);
+  for (const w of prepareDevSkills(dev, getSkillsDir())) warn(w, 'dev');
+  note(t('run.devFrom', { type: typePath, dir: dev.dirs[typePath] }));
+  return dev;
+}
+
+/**
+ * Refuses (or warns, per run.manifest_guard) to run a type whose
+ * manifest, in the version about to run, no longer matches what was
+ * installed.
+ */
+export function enforceManifestGuard(typePath: string, installedRoot: string, selected: SelectedVersion): void {
+  const mode = manifestGuardMode();
+  if (mode === 'off') return;
+  const at = selected.version ? { dir: selected.dir, version: selected.version } : undefined;
+  if (!verifyType(typePath, installedRoot, at).manifestChanged) return;
+
+  const message = t('run.manifestModified', {
+    type: typePath,
+    verify: `${APP_NAME} verify ${typePath}`,
+    accept: `${APP_NAME} verify --accept ${typePath}`,
+  });
+  if (mode === 'warn') {
+    warn(message, 'integrity');
+    return;
+  }
+  fail(t('run.manifestGuard', { message }), 'integrity');
+  process.exit(1);
+}
+
+/**
+ * The directory of the version to run: the one the current project pins,
+ * when it is installed side by side, or else the default (with a warning
+ * if it isn't the pinned version). Either is checked against the
+ * manifest guard.
+ */
+async function selectTypeDir(typePath: string, installedRoot: string): Promise<string> {
+  const project = findRepoRoot() ?? process.cwd();
+  const selected = selectVersion(project, installedRoot, typePath);
+  enforceManifestGuard(typePath, installedRoot, selected);
+  if (!selected.version && existsSync(projectConfigPath(project))) {
+    for (const m of await checkPins(loadProject(project), installedRoot, [typePath])) warn(m.message, 'pin');
+  }
+  return selected.dir;
+}
+
+/**
+ * Asks for required tokens the skill has no value for, so a first run
+ * doesn't fail on a missing GITHUB_TOKEN. Entered tokens apply to this
+ * session and, if the user agrees, are saved to the skill's tokens.env.
+ * Without a TTY nothing is asked and the skill reports the gap itself.
+ */
+async function provideMissingTokens(typePath: string, skillDir: string, manifest: SkillManifest): Promise<void> {
+  const missing = missingTokens(skillDir, manifest);
+  if (missing.length === 0) return;
+
+  // Save to the account's file only if it exists; otherwise to tokens.env
+  const active = activeAccount();
+  const account = active && accountsFor(skillDir).includes(active) ? active : null;
+  const tokensPath = tokensPathFor(skillDir, account);
+  if (!canPrompt() || !process.stderr.isTTY) {
+    const names = missing.map((t) => t.name).join(', ');
+    warn(t('run.tokensMissing', { type: typePath, names, path: tokensPath }), 'tokens');
+    return;
+  }
+
+  for (const token of missing) {
+    const about = token.description ? ` (${token.description})` : '';
+    const value = (await askSecret(t('run.needsValue', { type: typePath, name: token.name, about }))).trim();
+    if (!value) {
+      fail(t('run.tokenRequired', { name: token.name, type: typePath }), 'tokens');
+      process.exit(1);
+    }
+    process.env[token.name] = value;
+    if (await askConfirm(t('run.saveToken', { name: token.name, path: tokensPath }), false)) {
+      saveToken(skillDir, token.name, value, account);
+      note(t('run.tokenSaved', { name: token.name, path: tokensPath }));
+    }
+  }
 }
 
-function findManifest(dir: string): string | null {
-  for (const name of ['manifest.yaml', 'manifest.json', 'skill.yaml', 'workflow.yaml']) {
-    const path = join(dir, name);
-    if (existsSync(path)) return path;
+/**
+ * Asks for required secret inputs that were not given, without echo, so
+ * they need not be typed on the command line. Without a TTY nothing is
+ * asked and validation reports them missing.
+ */
+async function askMissingSecrets(typePath: string, schema: InputField[], inputs: Record<string, string>): Promise<void> {
+  if (!canPrompt() || !process.stderr.isTTY) return;
+  for (const field of schema) {
+    if (!field.secret || !field.required || field.name in inputs) continue;
+    const about = field.description ? ` (${field.description})` : '';
+    const value = await askSecret(t('run.needsValue', { type: typePath, name: field.name, about }));
+    if (value) inputs[field.name] = normalizeInputs({ [field.name]: value }, [field])[field.name];
   }
-  return null;
+}
+
+/** Fails when --account names a token set the skill doesn't have. */
+function requireAccount(typePath: string, skillDir: string, account: string): void {
+  const accounts = accountsFor(skillDir);
+  if (accounts.includes(account)) return;
+  const known = accounts.length ? t('run.knownAccounts', { accounts: accounts.join(', ') }) : '';
+  fail(
+    t('run.noAccount', {
+      type: typePath,
+      account,
+      path: tokensPathFor(skillDir, account),
+      known,
+      command: `${APP_NAME} env edit ${typePath.replace(/^skills\//, '')} --account ${account}`,
+    }),
+    'tokens',
+  );
+  process.exit(1);
+}
+
+interface ExecOptions {
+  cache?: boolean;
+  sandbox?: boolean;
+}
+
+/**
+ * Runs a skill between its pre-run and post-run hooks. A failing pre-run
+ * hook keeps the skill from running; post-run hooks see its exit code.
+ */
+async function execSkill(
+  skillPath: string,
+  skillDir: string,
+  manifest: SkillManifest,
+  inputs: Record<string, string>,
+  opts: ExecOptions,
+): Promise<RuntimeOutput> {
+  const hookContext = { typePaths: [skillPath], projectPath: findRepoRoot() ?? process.cwd() };
+  const hookOpts = { approve: approveContribution };
+  await runHooks('pre-run', hookContext, hookOpts);
+  const result = await execWithCache(skillPath, skillDir, manifest, inputs, opts);
+  const status = result.exitCode === 0 ? 'ok' : 'failed';
+  const hooks = await runHooks('post-run', { ...hookContext, status, exitCode: result.exitCode }, hookOpts);
+  for (const w of hooks.warnings) warn(w, 'hooks');
+  return result;
+}
+
+/**
+ * Runs a skill, consulting the workspace run cache when enabled. The
+ * workspace is the enclosing git repository so sibling projects in a
+ * monorepo share results. Sandboxed runs bypass the cache.
+ */
+async function execWithCache(
+  skillPath: string,
+  skillDir: string,
+  manifest: SkillManifest,
+  inputs: Record<string, string>,
+  opts: ExecOptions,
+): Promise<RuntimeOutput> {
+  if (opts.sandbox) return execSandboxed(skillPath, skillDir, manifest, inputs);
+  if (!opts.cache) return runSkill(skillDir, manifest, inputs);
+
+  const workspace = findRepoRoot() ?? process.cwd();
+  const env = isolatedEnv(manifest, buildSkillEnv(skillDir, manifest));
+  const key = { skillPath, version: manifest.version, inputs, account: activeAccount(), env };
+  const cached = lookupRun(workspace, key);
+  if (cached) return cached;
+
+  const result = await runSkill(skillDir, manifest, inputs);
+  storeRun(workspace, key, result);
+  return result;
+}
+
+/** Runs a skill against a copy of its registry and reports what it changed there. */
+async function execSandboxed(
+  skillPath: string,
+  skillDir: string,
+  manifest: SkillManifest,
+  inputs: Record<string, string>,
+): Promise<RuntimeOutput> {
+  const hints = networkHints(skillDir, manifest);
+  if (hints.length > 0) {
+    warn(t('run.mayUseNetwork', { type: skillPath }), 'sandbox');
+    for (const hint of hints) console.error(`  ${hint}`);
+  }
+
+  const sandbox = openSandbox(skillDir);
+  try {
+    const result = await runSkill(skillDir, manifest, inputs, { [envVar('SANDBOX')]: '1' });
+    const changes = sandboxChanges(sandbox);
+    if (changes.length === 0) {
+      note(t('run.sandboxUnchanged', { type: skillPath }));
+    } else {
+      note(t('run.sandboxChanged', { type: skillPath }));
+      for (const c of changes) console.error(`  ${c.kind.padEnd(8)} ${c.path}`);
+    }
+    return result;
+  } finally {
+    closeSandbox(sandbox);
+  }
+}
+
+/** Everything piped on stdin; a terminal has nothing to read. */
+function readStdin(): string {
+  if (process.stdin.isTTY) throw new Error(t('run.stdinNeedsPipe'));
+  return readFileSync(0, 'utf-8');
+}
+
+function collectInputs(value: string, previous: string[]): string[] {
+  return [...previous, value];
 }
@@ -0,0 +1,131 @@
+import type { Command } from 'commander';
+import { getInstalledRoot } from '../core/userdata.js';
+import { addSchedule, removeSchedule, loadSchedules, readRunHistory } from '../core/schedule.js';
+import { nextRun } from '../utils/cron.js';
+import { parseInputArgs } from '../utils/input-parser.js';
+import { parseCount } from '../utils/units.js';
+import { APP_NAME } from '../config/branding.js';
+import { ok, info, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+const DEFAULT_HISTORY = 20;
+
+function collectInputs(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+function describeResult(exitCode: number | undefined, error: string | undefined): string {
+  if (exitCode === 0) return 'ok';
+  if (exitCode === undefined) return t('schedule.error', { error: error?.split('\n')[0] ?? t('schedule.unknown') });
+  return t('schedule.exit', { code: exitCode });
+}
+
+export function registerSchedule(program: Command): void {
+  const cmd = program.command('schedule').description('Run skills and workflows on a cron schedule');
+
+  cmd
+    .command('add')
+    .description('Schedule a skill or workflow')
+    .argument('<type-path>', 'Installed skill or workflow')
+    .requiredOption('--cron <expr>', 'When to run, e.g. "0 9 * * 1-5" (weekdays at 9:00, local time)')
+    .option('-i, --input <key=value...>', 'Input key=value pairs', collectInputs, [])
+    .option('--name <id>', 'Schedule id (default: the type name and a random suffix)')
+    .action((typePath, opts) => {
+      try {
+        const schedule = addSchedule(typePath, opts.cron, getInstalledRoot(), {
+          id: opts.name,
+          inputs: parseInputArgs(opts.input),
+        });
+        ok(t('schedule.added', { type: typePath, id: schedule.id, next: nextRun(schedule.cron, new Date())?.toLocaleString() ?? t('schedule.never') }));
+        info(t('schedule.howItRuns', { run: `${APP_NAME} scheduler run`, install: `${APP_NAME} scheduler install` }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('list')
+    .description('List schedules with their next run and last result')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        const now = new Date();
+        const history = readRunHistory();
+        const rows = loadSchedules().map((s) => ({
+          ...s,
+          nextRun: nextRun(s.cron, now)?.toISOString() ?? null,
+          lastRun: history.filter((r) => r.id === s.id).pop() ?? null,
+        }));
+        if (wantsJson(opts)) {
+          emitJson(rows);
+          return;
+        }
+        if (rows.length === 0) {
+          console.log(t('schedule.none', { command: `${APP_NAME} schedule add <type-path> --cron "<expr>"` }));
+          return;
+        }
+        printTable(
+          [t('col.id'), t('col.type'), t('col.cron'), t('col.nextRun'), t('col.lastRun')],
+          rows.map((r) => [
+            r.id,
+            r.typePath,
+            r.cron,
+            r.nextRun ? new Date(r.nextRun).toLocaleString() : t('schedule.never'),
+            r.lastRun ? `${new Date(r.lastRun.startedAt).toLocaleString()} (${describeResult(r.lastRun.exitCode, r.lastRun.error)})` : '-',
+          ]),
+        );
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('remove')
+    .description('Delete a schedule')
+    .argument('<id>', 'Schedule id')
+    .action((id) => {
+      try {
+        if (!removeSchedule(id)) throw new Error(t('schedule.notFound', { id }));
+        ok(t('schedule.removed', { id }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('history')
+    .description('Show results of scheduled runs, newest first')
+    .argument('[id]', 'One schedule (default: all)')
+    .option('-n, --limit <n>', 'Runs to show', String(DEFAULT_HISTORY))
+    .option('--json', 'Output as JSON')
+    .action((id, opts) => {
+      try {
+        const runs = readRunHistory(id).reverse().slice(0, parseCount(opts.limit, '--limit'));
+        if (wantsJson(opts)) {
+          emitJson(runs);
+          return;
+        }
+        if (runs.length === 0) {
+          console.log(t('schedule.noRuns'));
+          return;
+        }
+        printTable(
+          [t('col.id'), t('col.type'), t('col.due'), t('col.took'), t('col.result')],
+          runs.map((r) => [
+            r.id,
+            r.typePath,
+            new Date(r.dueAt).toLocaleString(),
+            `${((Date.parse(r.finishedAt) - Date.parse(r.startedAt)) / 1000).toFixed(1)}s`,
+            describeResult(r.exitCode, r.error),
+          ]),
+        );
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
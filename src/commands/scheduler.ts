@@ -0,0 +1,103 @@
+import type { Command } from 'commander';
+import { mkdirSync, writeFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { getInstalledRoot } from '../core/userdata.js';
+import { tick, schedulerEntry, defaultSchedulerPlatform, type SchedulerPlatform, type ScheduledRun } from '../core/schedule.js';
+import { ok, info, warn, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+const PLATFORMS: SchedulerPlatform[] = ['systemd', 'launchd', 'windows'];
+
+function report(runs: ScheduledRun[]): void {
+  for (const r of runs) {
+    const params = { id: r.id, type: r.typePath };
+    if (r.exitCode === 0) ok(t('scheduler.finished', params));
+    else if (r.exitCode === undefined) warn(t('scheduler.failedToStart', { ...params, error: String(r.error) }), 'schedule');
+    else warn(t('scheduler.exited', { ...params, code: r.exitCode }), 'schedule');
+  }
+}
+
+export function registerScheduler(program: Command): void {
+  const cmd = program.command('scheduler').description('Run due schedules, in the foreground or from an OS timer');
+
+  cmd
+    .command('run')
+    .description('Check schedules every minute until interrupted')
+    .action(async () => {
+      try {
+        info(t('scheduler.running'));
+        let stopped = false;
+        let wake: () => void = () => {};
+        const stop = () => {
+          stopped = true;
+          wake();
+        };
+        process.once('SIGINT', stop);
+        process.once('SIGTERM', stop);
+
+        while (!stopped) {
+          const runs = await tick(getInstalledRoot());
+          if (runs === null) warn(t('scheduler.busySkipped'), 'schedule');
+          else report(runs);
+          // Wake at the start of the next minute
+          const wait = 60_000 - (Date.now() % 60_000);
+          await new Promise<void>((resolve) => {
+            wake = resolve;
+            setTimeout(resolve, wait);
+          });
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('tick')
+    .description('Run the schedules due since the last check, once (what OS timers call)')
+    .option('--json', 'Output as JSON')
+    .action(async (opts) => {
+      try {
+        const runs = await tick(getInstalledRoot());
+        if (wantsJson(opts)) {
+          emitJson(runs ?? []);
+          return;
+        }
+        if (runs === null) info(t('scheduler.busy'));
+        else report(runs);
+        if (runs?.some((r) => r.exitCode !== 0)) process.exitCode = 1;
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('install')
+    .description('Write an OS timer (systemd, launchd, or Task Scheduler) that runs due schedules every minute')
+    .option('--platform <platform>', `One of: ${PLATFORMS.join(', ')} (default: this system's)`)
+    .option('--print', 'Print the entry instead of writing it')
+    .action((opts) => {
+      try {
+        const platform: SchedulerPlatform = opts.platform ?? defaultSchedulerPlatform();
+        if (!PLATFORMS.includes(platform)) {
+          throw new Error(t('scheduler.unknownPlatform', { platform, expected: PLATFORMS.join(', ') }));
+        }
+        const entry = schedulerEntry(platform, [process.execPath, process.argv[1]]);
+        if (opts.print) {
+          for (const f of entry.files) console.log(`# ${f.path}\n${f.content}`);
+          console.log(entry.activate);
+          return;
+        }
+        for (const f of entry.files) {
+          mkdirSync(dirname(f.path), { recursive: true });
+          writeFileSync(f.path, f.content);
+          ok(t('common.wrote', { path: f.path }));
+        }
+        info(t('scheduler.activate', { command: entry.activate }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
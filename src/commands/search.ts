@@ -1,9 +1,14 @@
 import type { Command } from 'commander';
-import { discoverAllCached } from '../core/registry.js';
+import chalk from 'chalk';
+import { searchTypes, fuzzyDistance, fuzzyThreshold } from '../core/registry.js';
 import { buildSources } from '../core/extension.js';
+import { searchContent, rebuildContentIndex } from '../core/content-index.js';
+import { searchSemantic } from '../core/embeddings.js';
 import { findRepoRoot } from '../utils/git.js';
 import { printTable } from '../ui/table.js';
 import type { DiscoveredType } from '../types/registry.js';
+import { emitJson, wantsJson, failError, warn } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 
 export function registerSearch(program: Command): void {
   program
@@ -15,50 +20,135 @@ export function registerSearch(program: Command): void {
     .option('--topic <topic>', 'Filter by topic (exact)')
     .option('--vendor <vendor>', 'Filter by vendor (exact)')
     .option('--cli <dependency>', 'Filter by CLI dependency')
+    .option('--fuzzy', 'Tolerate typos in the query (ranked by closeness)')
+    .option('--content', 'Full-text search installed context content instead of metadata')
+    .option('--semantic', 'Rank installed context by meaning (local embedding index)')
+    .option('--limit <n>', 'Maximum results (with --semantic)', '10')
+    .option('--reindex', 'Rebuild the content or embedding index before searching')
     .option('--json', 'Output as JSON')
-    .action((query, opts) => {
+    .action(async (query, opts) => {
       try {
-        const repoRoot = findRepoRoot() ?? process.cwd();
-        const sources = buildSources(repoRoot);
-        let types = discoverAllCached(sources);
-
-        if (query) {
-          const q = query.toLowerCase();
-          types = types.filter(
-            (t) =>
-              t.typePath.toLowerCase().includes(q) ||
-              t.description.toLowerCase().includes(q),
-          );
+        if (opts.content) {
+          searchContentCommand(query, opts);
+          return;
         }
-
-        if (opts.type) {
-          types = types.filter((t) => t.category === opts.type);
+        if (opts.semantic) {
+          await searchSemanticCommand(query, opts);
+          return;
         }
 
-        if (opts.tag) {
-          const tags = opts.tag.split(',').map((t: string) => t.trim().toLowerCase());
-          types = types.filter((t) =>
-            t.tags.some((tag) => tags.includes(tag.toLowerCase())),
-          );
+        const repoRoot = findRepoRoot() ?? process.cwd();
+        const sources = buildSources(repoRoot);
+        const warnings: string[] = [];
+        let types = await searchTypes(
+          sources,
+          {
+            text: opts.fuzzy ? undefined : query,
+            category: opts.type,
+            tags: opts.tag?.split(',').map((t: string) => t.trim()).filter(Boolean),
+            topic: opts.topic,
+            vendor: opts.vendor,
+            cli: opts.cli,
+          },
+          { warnings },
+        );
+        for (const w of warnings) warn(w, 'registry');
+
+        if (query && opts.fuzzy) {
+          const threshold = fuzzyThreshold(query);
+          types = types
+            .map((t) => ({ t, d: fuzzyDistance(query, t.typePath) }))
+            .filter(({ d }) => d <= threshold)
+            .sort((a, b) => a.d - b.d)
+            .map(({ t }) => t);
         }
 
-        if (opts.json) {
-          console.log(JSON.stringify(types, null, 2));
+        if (wantsJson(opts)) {
+          emitJson(types);
           return;
         }
 
         if (types.length === 0) {
-          console.log('No types found.');
+          console.log(t('search.noTypes'));
           return;
         }
 
         printTable(
-          ['Type', 'Name', 'Version', 'Description'],
-          types.map((t) => [t.category, t.typePath, t.version, t.description]),
+          [t('col.type'), t('col.name'), t('col.version'), t('col.description')],
+          types.map((type) => [type.category, type.typePath, type.version, describe(type)]),
         );
       } catch (err) {
-        console.error(String(err));
+        failError(err);
         process.exit(1);
       }
     });
 }
+
+function describe(type: DiscoveredType): string {
+  if (!type.deprecated) return type.description;
+  const label = type.replacedBy ? t('search.deprecatedBy', { type: type.replacedBy }) : t('search.deprecated');
+  return `${chalk.yellow(`[${label}]`)} ${type.description}`;
+}
+
+function searchContentCommand(query: string | undefined, opts: { reindex?: boolean; json?: boolean }): void {
+  if (!query) {
+    throw new Error(t('search.queryRequired', { flag: '--content' }));
+  }
+  if (opts.reindex) rebuildContentIndex();
+
+  const hits = searchContent(query);
+  if (wantsJson(opts)) {
+    emitJson(hits);
+    return;
+  }
+
+  if (hits.length === 0) {
+    console.log(t('search.noContent'));
+    return;
+  }
+
+  const byType = new Map<string, typeof hits>();
+  for (const hit of hits) {
+    const list = byType.get(hit.typePath) ?? [];
+    list.push(hit);
+    byType.set(hit.typePath, list);
+  }
+
+  for (const [typePath, list] of byType) {
+    console.log(typePath);
+    for (const hit of list) {
+      console.log(`  ${hit.source}`);
+      if (hit.snippet) console.log(`    ${hit.snippet}`);
+    }
+    console.log('');
+  }
+}
+
+async function searchSemanticCommand(
+  query: string | undefined,
+  opts: { reindex?: boolean; json?: boolean; limit: string },
+): Promise<void> {
+  if (!query) {
+    throw new Error(t('search.queryRequired', { flag: '--semantic' }));
+  }
+  const limit = parseInt(opts.limit, 10);
+  if (Number.isNaN(limit) || limit <= 0) {
+    throw new Error(t('common.invalidValue', { flag: '--limit', value: opts.limit }));
+  }
+
+  const hits = await searchSemantic(query, { limit, reindex: opts.reindex });
+  if (wantsJson(opts)) {
+    emitJson(hits);
+    return;
+  }
+
+  if (hits.length === 0) {
+    console.log(t('search.noRelated'));
+    return;
+  }
+
+  printTable(
+    [t('col.type'), t('col.score'), t('col.source'), t('col.excerpt')],
+    hits.map((h) => [h.typePath, h.score.toFixed(3), h.source, h.preview]),
+  );
+}
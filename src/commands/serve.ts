@@ -0,0 +1,42 @@
+import type { Command } from 'commander';
+import { getInstalledRoot } from '../core/userdata.js';
+import { createApiServer, parseAddr } from '../core/api-server.js';
+import * as settings from '../config/settings.js';
+import { APP_NAME, envVar } from '../config/branding.js';
+import { addSecret } from '../utils/redact.js';
+import { ok, info, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+export function registerServe(program: Command): void {
+  const cmd = program.command('serve').description('Serve installed skills and workflows to other programs');
+
+  cmd
+    .command('http')
+    .description('Expose a REST API to list types, run skills and workflows, stream their output, and read run history')
+    .option('--addr <addr>', 'Address to listen on (":7777" listens on every interface)', '127.0.0.1:7777')
+    .option('--max-runs <n>', 'Finished runs kept in memory', '100')
+    .action(async (opts) => {
+      try {
+        const token = process.env[envVar('SERVE_TOKEN')] || settings.get('serve.token');
+        if (!token) {
+          throw new Error(t('serve.tokenRequired', { command: `${APP_NAME} config set serve.token <secret>`, env: envVar('SERVE_TOKEN') }));
+        }
+        addSecret(token);
+        const { host, port } = parseAddr(opts.addr);
+        const server = createApiServer({ token, installedRoot: getInstalledRoot(), maxRuns: Number(opts.maxRuns) });
+        await new Promise<void>((resolve, reject) => {
+          server.once('error', reject);
+          server.listen(port, host, resolve);
+        });
+
+        ok(t('serve.listening', { app: APP_NAME, url: `http://${host ?? '0.0.0.0'}:${port}/v1` }));
+        info(t('serve.authHint'));
+        const stop = () => server.close(() => process.exit(0));
+        process.once('SIGINT', stop);
+        process.once('SIGTERM', stop);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
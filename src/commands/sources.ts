@@ -0,0 +1,100 @@
+import type { Command } from 'commander';
+import { readFileSync } from 'node:fs';
+import { APP_NAME } from '../config/branding.js';
+import { repoURL } from '../core/catalog.js';
+import { extensionSourceUrl } from '../core/extension.js';
+import {
+  hostOf,
+  hostEnvVar,
+  resolveCredential,
+  storeCredential,
+  removeCredential,
+} from '../core/credentials.js';
+import { getCredentialsPath } from '../core/userdata.js';
+import { findRepoRoot } from '../utils/git.js';
+import { askSecret } from '../ui/prompts.js';
+import { ok, failError, info, warn } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+/**
+ * Maps a source name to the host its credentials are stored under:
+ * "catalog", an extension name, or a bare host name.
+ */
+function sourceHost(name: string): string {
+  const url =
+    name === 'catalog' ? repoURL() : extensionSourceUrl(findRepoRoot() ?? process.cwd(), name);
+  const host = url ? hostOf(url) : /^[\w.-]+\.[a-z]{2,}$/i.test(name) ? name.toLowerCase() : null;
+  if (!host) {
+    throw new Error(t('sources.unknown', { name }));
+  }
+  return host;
+}
+
+export function registerSources(program: Command): void {
+  const cmd = program
+    .command('sources')
+    .description('Manage credentials for private catalogs, extensions, and context hosts');
+
+  cmd
+    .command('login')
+    .description('Store a token for a source in the OS keychain')
+    .argument('<name>', 'Source: "catalog", an extension name, or a host')
+    .option('--token-stdin', 'Read the token from stdin instead of prompting')
+    .action(async (name, opts) => {
+      try {
+        const host = sourceHost(name);
+        const token = opts.tokenStdin
+          ? readFileSync(0, 'utf-8').trim()
+          : await askSecret(t('sources.tokenFor', { host }), { hint: t('sources.tokenHint') });
+        if (!token) throw new Error(t('sources.noToken'));
+
+        const where = storeCredential(host, token);
+        if (where === 'keychain') {
+          ok(t('sources.storedKeychain', { host }));
+        } else {
+          ok(t('sources.storedFile', { host, path: getCredentialsPath() }));
+          warn(t('sources.noKeychain'), 'credentials');
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('logout')
+    .description('Remove a stored token for a source')
+    .argument('<name>', 'Source: "catalog", an extension name, or a host')
+    .action((name) => {
+      try {
+        const host = sourceHost(name);
+        if (removeCredential(host)) {
+          ok(t('sources.removed', { host }));
+        } else {
+          info(t('sources.noStored', { host }));
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('status')
+    .description('Show which credential a source would use')
+    .argument('<name>', 'Source: "catalog", an extension name, or a host')
+    .action((name) => {
+      try {
+        const host = sourceHost(name);
+        const cred = resolveCredential(host);
+        if (!cred) {
+          info(t('sources.noCredentials', { host, env: hostEnvVar(host), command: `${APP_NAME} sources login ${name}` }));
+          return;
+        }
+        console.log(`${host}: ${cred.from}${cred.username ? ` (${t('sources.login', { user: cred.username })})` : ''}`);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -0,0 +1,139 @@
+import type { Command } from 'commander';
+import { existsSync } from 'node:fs';
+import { getInstalledRoot } from '../core/userdata.js';
+import { discoverTypes } from '../core/registry.js';
+import { listState, readState, clearState, stateDir } from '../core/state.js';
+import { parseDuration, formatBytes } from '../utils/units.js';
+import { ok, info, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+import { askConfirm } from '../ui/prompts.js';
+
+/** Accepts "skills/org/name" or the shorter "org/name". */
+export function skillPath(arg: string): string {
+  return arg.startsWith('skills/') ? arg : `skills/${arg}`;
+}
+
+function installedSkills(): string[] {
+  const root = getInstalledRoot();
+  if (!existsSync(root)) return [];
+  return discoverTypes([{ name: 'installed', basePath: root }])
+    .filter((t) => t.category === 'skill')
+    .map((t) => t.typePath);
+}
+
+export function registerState(program: Command): void {
+  const cmd = program
+    .command('state')
+    .description('Inspect and clear the state skills keep between runs');
+
+  cmd
+    .command('list')
+    .description('List state files of a skill, or state usage of all installed skills')
+    .argument('[skill]', 'Skill path (e.g. skills/org/name)')
+    .option('--json', 'Output as JSON')
+    .action((skill: string | undefined, opts) => {
+      try {
+        if (skill) {
+          const files = listState(skillPath(skill));
+          if (wantsJson(opts)) {
+            emitJson(files);
+          } else if (files.length === 0) {
+            info(t('state.none', { skill: skillPath(skill) }));
+          } else {
+            printTable(
+              [t('col.file'), t('col.size'), t('col.modified')],
+              files.map((f) => [f.path, formatBytes(f.bytes), f.modified.toISOString()]),
+            );
+          }
+          return;
+        }
+
+        const usage = installedSkills()
+          .map((typePath) => {
+            const files = listState(typePath);
+            return { skill: typePath, files: files.length, bytes: files.reduce((n, f) => n + f.bytes, 0) };
+          })
+          .filter((u) => u.files > 0);
+        if (wantsJson(opts)) {
+          emitJson(usage);
+        } else if (usage.length === 0) {
+          info(t('state.noneAtAll'));
+        } else {
+          printTable(
+            [t('col.skill'), t('link.col.files'), t('col.size')],
+            usage.map((u) => [u.skill, String(u.files), formatBytes(u.bytes)]),
+          );
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('show')
+    .description('Print a state file, or where a skill keeps its state')
+    .argument('<skill>', 'Skill path')
+    .argument('[file]', 'File within state/')
+    .action((skill: string, file: string | undefined) => {
+      try {
+        if (!file) {
+          console.log(stateDir(skillPath(skill)));
+          return;
+        }
+        process.stdout.write(readState(skillPath(skill), file));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('clear')
+    .description("Delete a skill's state files")
+    .argument('<skill>', 'Skill path')
+    .argument('[file]', 'Only this file')
+    .option('--older-than <age>', 'Only files not modified within this long (e.g. 12h, 7d, 2w)')
+    .option('--dry-run', 'List what would be deleted')
+    .option('-y, --yes', 'Do not ask for confirmation')
+    .option('--json', 'Output as JSON')
+    .action(async (skill: string, file: string | undefined, opts) => {
+      try {
+        const typePath = skillPath(skill);
+        const clearOpts = {
+          file,
+          olderThanMs: opts.olderThan ? parseDuration(opts.olderThan) : undefined,
+        };
+        const targets = clearState(typePath, { ...clearOpts, dryRun: true });
+        if (targets.length === 0) {
+          if (wantsJson(opts)) emitJson([]);
+          else info(t('state.nothingToClear', { skill: typePath }));
+          return;
+        }
+
+        const bytes = formatBytes(targets.reduce((n, f) => n + f.bytes, 0));
+        if (opts.dryRun) {
+          if (wantsJson(opts)) {
+            emitJson(targets);
+            return;
+          }
+          for (const f of targets) console.log(`  ${f.path}`);
+          info(t('state.wouldDelete', { count: targets.length, bytes }));
+          return;
+        }
+        const question = t('state.confirmDelete', { count: targets.length, bytes, skill: typePath });
+        if (!opts.yes && !(await askConfirm(question, false, { mandatory: true }))) {
+          info(t('common.cancelled'));
+          return;
+        }
+
+        const removed = clearState(typePath, clearOpts);
+        if (wantsJson(opts)) emitJson(removed);
+        else ok(t('state.deleted', { count: removed.length, skill: typePath }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
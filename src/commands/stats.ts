@@ -0,0 +1,93 @@
+import type { Command } from 'commander';
+import { spawn } from 'node:child_process';
+import { readStats, resetStats, flushTelemetry, telemetryMode, type UsageTotals } from '../core/telemetry.js';
+import { getStatsPath } from '../core/userdata.js';
+import { APP_NAME } from '../config/branding.js';
+import { ok, info, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+const TOP = 10;
+
+/** Sends queued telemetry from a detached process, so the command exits at once. */
+export function flushTelemetryInBackground(): void {
+  try {
+    const child = spawn(process.execPath, [process.argv[1], 'stats', 'flush'], {
+      detached: true,
+      stdio: 'ignore',
+    });
+    child.unref();
+  } catch {
+    // Best-effort; the queue is sent on a later run
+  }
+}
+
+function avg(u: UsageTotals): number {
+  return u.count ? Math.round(u.totalMs / u.count) : 0;
+}
+
+function seconds(ms: number): string {
+  return `${(ms / 1000).toFixed(2)}s`;
+}
+
+export function registerStats(program: Command): void {
+  const cmd = program
+    .command('stats')
+    .description('Show your own usage: most-used skills and slowest commands')
+    .option('--reset', 'Delete the collected stats')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      try {
+        if (opts.reset) {
+          resetStats();
+          ok(t('stats.cleared'));
+          return;
+        }
+
+        const mode = telemetryMode();
+        const stats = readStats();
+        if (wantsJson(opts)) {
+          emitJson({ mode, ...stats });
+          return;
+        }
+        if (mode === 'off' && Object.keys(stats.commands).length === 0) {
+          info(t('stats.off', { command: `${APP_NAME} config set telemetry.local true` }));
+          return;
+        }
+
+        console.log(`  ${t('stats.header', { mode, since: stats.since, path: getStatsPath() })}`);
+        const skills = Object.entries(stats.skills).sort(([, a], [, b]) => b.count - a.count).slice(0, TOP);
+        if (skills.length > 0) {
+          console.log(`\n${t('stats.mostUsed')}`);
+          printTable(
+            [t('col.skill'), t('col.runs'), t('col.failed'), t('col.avg')],
+            skills.map(([name, u]) => [name, String(u.count), String(u.failures), seconds(avg(u))]),
+          );
+        }
+        const slowest = Object.entries(stats.commands).sort(([, a], [, b]) => avg(b) - avg(a)).slice(0, TOP);
+        if (slowest.length > 0) {
+          console.log(`\n${t('stats.slowest')}`);
+          printTable(
+            [t('col.command'), t('col.runs'), t('col.failed'), t('col.avg'), t('col.max')],
+            slowest.map(([name, u]) => [name, String(u.count), String(u.failures), seconds(avg(u)), seconds(u.maxMs)]),
+          );
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('flush', { hidden: true })
+    .description('Send queued telemetry events')
+    .action(async () => {
+      try {
+        const sent = await flushTelemetry();
+        if (sent > 0) ok(t('stats.sent', { count: sent }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
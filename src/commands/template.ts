@@ -0,0 +1,58 @@
+import type { Command } from 'commander';
+import { join } from 'node:path';
+import { existsSync, writeFileSync } from 'node:fs';
+import { getInstalledRoot } from '../core/userdata.js';
+import { installedTypePaths, didYouMean } from '../core/registry.js';
+import { createEngine, renderTemplateType } from '../core/template.js';
+import { buildSources } from '../core/extension.js';
+import { findRepoRoot } from '../utils/git.js';
+import { parseInputArgs, readDataFile } from '../utils/input-parser.js';
+import { ok, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+function collectVar(value: string, previous: string[]): string[] {
+  return [...previous, value];
+}
+
+export function registerTemplate(program: Command): void {
+  const cmd = program
+    .command('template')
+    .description('Render installed template types');
+
+  cmd
+    .command('render')
+    .description('Render a template with variables from flags or a data file')
+    .argument('<type-path>', 'Path to the installed template (e.g. templates/skill-readme)')
+    .option('--var <key=value>', 'Value for a template variable (repeatable)', collectVar, [])
+    .option('--input-file <path>', 'YAML or JSON mapping of template data; --var values win')
+    .option('-o, --output <file>', 'Write to a file instead of stdout')
+    .action((typePath: string, opts) => {
+      try {
+        const installedRoot = getInstalledRoot();
+        const dir = join(installedRoot, typePath);
+        if (!typePath.startsWith('templates/') || !existsSync(dir)) {
+          const hint = didYouMean(typePath, installedTypePaths(installedRoot).filter((p) => p.startsWith('templates/')));
+          throw new Error(t('template.notInstalled', { type: typePath, hint }));
+        }
+
+        const data = {
+          ...(opts.inputFile ? readDataFile(opts.inputFile) : {}),
+          ...parseInputArgs(opts.var),
+        };
+        const engine = createEngine({
+          partialSources: [{ name: 'installed', basePath: installedRoot }, ...buildSources(findRepoRoot() ?? process.cwd())],
+        });
+        const output = renderTemplateType(dir, data, engine);
+
+        if (opts.output) {
+          writeFileSync(opts.output, output, 'utf-8');
+          ok(t('template.written', { path: opts.output }));
+        } else {
+          process.stdout.write(output);
+        }
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
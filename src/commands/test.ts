@@ -0,0 +1,69 @@
+import type { Command } from 'commander';
+import { join, resolve } from 'node:path';
+import { existsSync, readFileSync } from 'node:fs';
+import chalk from 'chalk';
+import yaml from 'js-yaml';
+import { getInstalledRoot } from '../core/userdata.js';
+import { didYouMean, installedTypePaths } from '../core/registry.js';
+import { runSkillTests, type TestReport } from '../core/skill-tests.js';
+import { emitJson, wantsJson, ok, fail, failError, info } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import type { SkillManifest } from '../types/manifest.js';
+
+export function registerTest(program: Command): void {
+  program
+    .command('test')
+    .description("Run a skill's self-tests (the manifest's tests: cases)")
+    .argument('<skill>', 'Installed skill type path, or a skill source directory')
+    .option('--case <name>', 'Only run cases whose name contains this')
+    .option('--json', 'Output as JSON')
+    .action(async (skill, opts) => {
+      try {
+        const skillDir = locateSkill(skill);
+        const manifestPath = join(skillDir, 'skill.yaml');
+        if (!existsSync(manifestPath)) {
+          throw new Error(t('test.notSkill', { skill }));
+        }
+        const manifest = yaml.load(readFileSync(manifestPath, 'utf-8')) as SkillManifest;
+
+        const report = await runSkillTests(skill, skillDir, manifest, opts.case);
+        if (wantsJson(opts)) {
+          emitJson(report);
+        } else {
+          printReport(report, Boolean(manifest.tests?.length));
+        }
+        if (report.failed > 0) process.exitCode = 1;
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
+
+/** An installed type path, else a directory holding a skill being written. */
+export function locateSkill(skill: string): string {
+  const installedRoot = getInstalledRoot();
+  const installed = join(installedRoot, skill);
+  if (existsSync(installed)) return installed;
+  if (existsSync(resolve(skill))) return resolve(skill);
+  const hint = didYouMean(skill, installedTypePaths(installedRoot));
+  throw new Error(t('test.skillNotFound', { skill, hint }));
+}
+
+function printReport(report: TestReport, declared: boolean): void {
+  if (report.cases.length === 0) {
+    info(declared ? t('test.noMatch') : t('test.noTests', { skill: report.skill }));
+    return;
+  }
+  for (const c of report.cases) {
+    const mark = c.passed ? chalk.green('✓') : chalk.red('✗');
+    console.log(`${mark} ${c.name} ${chalk.dim(`(${c.durationMs}ms)`)}`);
+    for (const f of c.failures) console.log(`    ${f}`);
+    if (!c.passed && c.stderr.trim()) {
+      console.log(chalk.dim(c.stderr.trim().split('\n').map((l) => `    | ${l}`).join('\n')));
+    }
+  }
+  const summary = t('test.summary', { passed: report.passed, failed: report.failed });
+  if (report.failed > 0) fail(summary, 'test');
+  else ok(summary);
+}
@@ -0,0 +1,56 @@
+import type { Command } from 'commander';
+import { loadTrustStore, revokeTrust, describeContribution, type Contribution } from '../core/trust.js';
+import { ok, failError, info, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { askApproval } from '../ui/prompts.js';
+import { printTable } from '../ui/table.js';
+
+/** Shows a contribution (or project hook) and asks to approve it; never assumed without a TTY. */
+export function approveContribution(c: Contribution): Promise<boolean> {
+  return askApproval(describeContribution(c));
+}
+
+export function registerTrust(program: Command): void {
+  const cmd = program
+    .command('trust')
+    .description('Manage approvals for extension-contributed scaffolds, hooks, and rules');
+
+  cmd
+    .command('list')
+    .description('List approved extension contributions')
+    .option('--json', 'Output as JSON')
+    .action((opts) => {
+      const entries = loadTrustStore();
+      if (wantsJson(opts)) {
+        emitJson(entries);
+        return;
+      }
+      if (entries.length === 0) {
+        console.log(t('trust.none'));
+        return;
+      }
+      printTable(
+        [t('col.extension'), t('col.kind'), t('col.name'), t('col.approved'), t('col.digest')],
+        entries.map((e) => [e.extension, e.kind, e.name, e.approvedAt, e.digest.slice(0, 12)]),
+      );
+    });
+
+  cmd
+    .command('revoke')
+    .description('Revoke approval for an extension (or one of its contributions)')
+    .argument('<extension>', 'Extension name')
+    .argument('[name]', 'Contribution name (default: all of the extension)')
+    .action((extension, name) => {
+      try {
+        const removed = revokeTrust(extension, name);
+        if (removed === 0) {
+          info(t('trust.nothingToRevoke'));
+          return;
+        }
+        ok(t('trust.revoked', { count: removed, target: name ? `${extension}/${name}` : extension }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
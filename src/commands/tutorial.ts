@@ -0,0 +1,123 @@
+import type { Command } from 'commander';
+import { join } from 'node:path';
+import { readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import chalk from 'chalk';
+import {
+  createSandbox,
+  enterSandbox,
+  removeSandbox,
+  TUTORIAL_PROMPT,
+  TUTORIAL_PERSONA,
+  TUTORIAL_SKILL,
+} from '../core/tutorial.js';
+import { getInstalledRoot, getSkillsDir } from '../core/userdata.js';
+import { buildInstallPlan, installType, initSkillRegistry, printTree } from '../core/registry.js';
+import { buildSources } from '../core/extension.js';
+import { initProject, addType } from '../core/linker.js';
+import { runSkill } from '../core/runtime.js';
+import { compose, render } from '../core/compose.js';
+import { listFiles } from '../utils/fs.js';
+import { ok, failError, warn, info } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { askConfirm, canPrompt } from '../ui/prompts.js';
+import type { SkillManifest } from '../types/manifest.js';
+
+const STEPS = 5;
+const SAMPLE_TEXT = 'AgentX turns reusable agent knowledge into installable, linkable types';
+
+function heading(n: number, title: string): void {
+  console.log('\n' + chalk.bold(t('tutorial.step', { n, total: STEPS, title })));
+}
+
+function explain(...lines: string[]): void {
+  for (const line of lines) console.log(chalk.dim(`  ${line}`));
+  console.log('');
+}
+
+export function registerTutorial(program: Command): void {
+  program
+    .command('tutorial')
+    .description('Guided walkthrough of installing, linking, running, and composing in a sandbox')
+    .option('--keep', 'Keep the sandbox directory afterwards')
+    .option('-y, --yes', 'Run straight through without pausing between steps')
+    .action(async (opts) => {
+      const sandbox = createSandbox();
+      const restore = enterSandbox(sandbox);
+      const proceed = async (): Promise<boolean> =>
+        opts.yes || !canPrompt() || askConfirm(t('tutorial.continue'));
+
+      try {
+        console.log(chalk.bold(t('tutorial.welcome')));
+        explain(...t('tutorial.intro', { sandbox: sandbox.root }).split('\n'));
+        if (!(await proceed())) return;
+
+        // 1. Install
+        heading(1, t('tutorial.install.title'));
+        const installedRoot = getInstalledRoot();
+        const plan = buildInstallPlan(TUTORIAL_PROMPT, buildSources(sandbox.project), installedRoot);
+        console.log(printTree(plan.root));
+        for (const resolved of plan.allTypes) {
+          installType(resolved, installedRoot);
+          for (const w of initSkillRegistry(resolved, getSkillsDir())) warn(w);
+        }
+        ok(t('tutorial.install.done', { count: plan.allTypes.length, command: `agentx install ${TUTORIAL_PROMPT}` }));
+        explain(...t('tutorial.install.explain').split('\n'));
+        if (!(await proceed())) return;
+
+        // 2. Link
+        heading(2, t('tutorial.link.title'));
+        initProject(sandbox.project, ['claude-code']);
+        await addType(sandbox.project, TUTORIAL_PERSONA);
+        await addType(sandbox.project, TUTORIAL_SKILL);
+        for (const file of listFiles(sandbox.project)) console.log(`  ${file}`);
+        console.log('');
+        ok(t('tutorial.link.done'));
+        explain(...t('tutorial.link.explain').split('\n'));
+        if (!(await proceed())) return;
+
+        // 3. Run
+        heading(3, t('tutorial.run.title'));
+        const skillDir = join(installedRoot, TUTORIAL_SKILL);
+        const manifest = yaml.load(
+          readFileSync(join(skillDir, 'manifest.yaml'), 'utf-8'),
+        ) as SkillManifest;
+        const result = await runSkill(skillDir, manifest, { text: SAMPLE_TEXT });
+        process.stdout.write(result.stdout);
+        if (result.stderr) process.stderr.write(result.stderr);
+        ok(t('tutorial.ran', { command: `agentx run ${TUTORIAL_SKILL} --input text="${SAMPLE_TEXT}"` }));
+        explain(...t('tutorial.run.explain').split('\n'));
+        if (!(await proceed())) return;
+
+        // 4. Compose
+        heading(4, t('tutorial.compose.title'));
+        const composed = compose(TUTORIAL_PROMPT, installedRoot);
+        console.log(render(composed));
+        for (const w of composed.warnings) warn(w, 'compose');
+        ok(t('tutorial.compose.done', { command: `agentx prompt ${TUTORIAL_PROMPT}`, tokens: composed.tokens.total }));
+        explain(...t('tutorial.compose.explain').split('\n'));
+        if (!(await proceed())) return;
+
+        // 5. Next steps
+        heading(5, t('tutorial.next.title'));
+        explain(
+          `agentx init --global          ${t('tutorial.next.init')}`,
+          `agentx search <query>         ${t('tutorial.next.search')}`,
+          `agentx install <type-path>    ${t('tutorial.next.install')}`,
+          `agentx link add <type-path>   ${t('tutorial.next.link')}`,
+          `agentx create <type> <name>   ${t('tutorial.next.create')}`,
+        );
+        ok(t('tutorial.complete'));
+      } catch (err) {
+        failError(err);
+        process.exitCode = 1;
+      } finally {
+        restore();
+        if (opts.keep) {
+          info(t('tutorial.kept', { path: sandbox.root }));
+        } else {
+          removeSandbox(sandbox);
+        }
+      }
+    });
+}
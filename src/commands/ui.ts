@@ -0,0 +1,21 @@
+import type { Command } from 'commander';
+import { getInstalledRoot } from '../core/userdata.js';
+import { loadDashboard } from '../core/dashboard.js';
+import { findRepoRoot } from '../utils/git.js';
+import { runDashboard } from '../ui/dashboard.js';
+import { failError } from '../ui/output.js';
+
+export function registerUi(program: Command): void {
+  program
+    .command('ui')
+    .description('Open a terminal dashboard of installed types, projects, extensions, and recent runs')
+    .action(async () => {
+      try {
+        const repoRoot = findRepoRoot() ?? process.cwd();
+        await runDashboard(() => loadDashboard(getInstalledRoot(), repoRoot));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -1,20 +1,25 @@
 import type { Command } from 'commander';
-import { removeType } from '../core/registry.js';
+import { removeType, categoryFromPath } from '../core/registry.js';
 import { getInstalledRoot } from '../core/userdata.js';
-import { ok, fail } from '../ui/output.js';
+import { notifyChange } from '../core/notify.js';
+import { rebuildContentIndex } from '../core/content-index.js';
+import { ok, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 
 export function registerUninstall(program: Command): void {
   program
     .command('uninstall')
     .description('Remove an installed type')
     .argument('<type-path>', 'Path to the type to remove')
-    .action((typePath) => {
+    .action(async (typePath) => {
       try {
         const installedRoot = getInstalledRoot();
         removeType(typePath, installedRoot);
-        ok(`Removed: ${typePath}`);
+        if (categoryFromPath(typePath) === 'context') rebuildContentIndex(installedRoot);
+        await notifyChange('uninstall', [typePath]);
+        ok(t('uninstall.removed', { type: typePath }));
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -1,6 +1,8 @@
 import type { Command } from 'commander';
 import { checkForUpdate, update, currentVersion } from '../core/updater.js';
-import { ok, info, fail } from '../ui/output.js';
+import { isOffline, offlineSkip } from '../core/offline.js';
+import { ok, info, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 import { withSpinner } from '../ui/spinner.js';
 
 export function registerUpdate(program: Command): void {
@@ -13,36 +15,41 @@ export function registerUpdate(program: Command): void {
     .option('--version <version>', 'Install specific version')
     .action(async (opts) => {
       try {
+        if (isOffline()) {
+          info(offlineSkip(opts.check ? 'update check' : 'self-update'));
+          return;
+        }
+
         if (opts.check) {
-          info(`Current version: ${currentVersion()}`);
+          info(t('update.current', { version: currentVersion() }));
           const latest = await checkForUpdate();
           if (latest) {
-            info(`New version available: ${latest}`);
-            console.log('Run `agentx update` to install.');
+            info(t('update.available', { version: latest }));
+            console.log(t('update.runHint'));
           } else {
-            ok('Already on the latest version.');
+            ok(t('update.latest'));
           }
           return;
         }
 
         if (opts.version) {
-          await withSpinner(`Installing version ${opts.version}...`, () =>
+          await withSpinner(t('update.installing', { version: opts.version }), () =>
             update(opts.version),
           );
-          ok(`Updated to ${opts.version}`);
+          ok(t('update.updatedTo', { version: opts.version }));
           return;
         }
 
         const latest = await checkForUpdate();
         if (!latest && !opts.force) {
-          ok(`Already on the latest version (${currentVersion()}).`);
+          ok(t('update.latestVersion', { version: currentVersion() }));
           return;
         }
 
-        await withSpinner('Updating...', () => update());
-        ok('Updated successfully.');
+        await withSpinner(t('update.updating'), () => update());
+        ok(t('update.updated'));
       } catch (err) {
-        fail(String(err));
+        failError(err);
         process.exit(1);
       }
     });
@@ -0,0 +1,90 @@
+import { existsSync, statSync } from 'node:fs';
+import { resolve, basename } from 'node:path';
+import type { Command } from 'commander';
+import { validateManifestFile, manifestErrors, formatIssue, type ManifestIssue } from '../core/manifest.js';
+import { validateSource, type SourceValidation } from '../core/source-lint.js';
+import { buildSources } from '../core/extension.js';
+import type { Source } from '../types/registry.js';
+import { REPORT_FORMATS, parseReportFormat, renderReport, validationSuites } from '../core/report.js';
+import { findRepoRoot } from '../utils/git.js';
+import { emitJson, wantsJson, ok, fail, failError } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+type FileResult = { file: string; issues: ManifestIssue[] };
+
+/** A directory or source name as a source, in its place in the resolution order. */
+function targetSource(target: string, sources: Source[]): Source {
+  if (existsSync(target)) {
+    const basePath = resolve(target);
+    return sources.find((s) => s.basePath === basePath) ?? { name: basename(basePath), basePath };
+  }
+  const named = sources.find((s) => s.name === target);
+  if (!named) {
+    throw new Error(t('validate.unknownTarget', { target, sources: sources.map((s) => s.name).join(', ') || t('validate.noSources') }));
+  }
+  return named;
+}
+
+function printIssues(issues: ManifestIssue[]): void {
+  for (const issue of issues) console.log(`\n${formatIssue(issue)}`);
+  console.log('');
+}
+
+export function registerValidate(program: Command): void {
+  program
+    .command('validate')
+    .description('Validate manifest files, or every type in a catalog or extension, warning about unknown fields')
+    .argument('<targets...>', 'Manifest files, source directories, or source names (catalog, an extension)')
+    .option('--strict', 'Reject fields the schema does not declare (default: manifest.strict)')
+    .option('--json', 'Output as JSON')
+    .option('--format <format>', `Output format (${REPORT_FORMATS.join(', ')})`, 'text')
+    .action((targets: string[], opts) => {
+      try {
+        const format = parseReportFormat(opts.format);
+        const root = findRepoRoot() ?? process.cwd();
+        const sources = buildSources(root);
+        const results: (FileResult | SourceValidation)[] = targets.map((target) =>
+          existsSync(target) && statSync(target).isFile()
+            ? { file: target, issues: validateManifestFile(target, { strict: opts.strict }) }
+            : validateSource(targetSource(target, sources), sources, { strict: opts.strict }),
+        );
+
+        if (format === 'junit' || format === 'sarif') {
+          process.stdout.write(renderReport(format, validationSuites(results, root)));
+        } else if (wantsJson(opts) || format === 'json') {
+          emitJson(results);
+        } else {
+          for (const r of results) {
+            if ('file' in r) {
+              const errors = manifestErrors(r.issues).length;
+              if (errors === 0) {
+                ok(t('validate.validFile', { file: r.file }));
+              } else {
+                fail(t('validate.invalidFile', { file: r.file, count: errors }));
+              }
+              if (r.issues.length > 0) printIssues(r.issues);
+              continue;
+            }
+            const errors = r.results.reduce((sum, f) => sum + manifestErrors(f.issues).length, 0);
+            if (errors === 0) {
+              ok(t('validate.validSource', { source: r.source, types: r.types, root: r.root }));
+            } else {
+              const files = r.results.filter((f) => manifestErrors(f.issues).length > 0).length;
+              fail(t('validate.invalidSource', { source: r.source, count: errors, files, types: r.types }));
+            }
+            for (const f of r.results) {
+              console.log(`\n${f.file}`);
+              printIssues(f.issues);
+            }
+          }
+        }
+        const failed = results.some((r) =>
+          ('file' in r ? [r] : r.results).some((f) => manifestErrors(f.issues).length > 0),
+        );
+        if (failed) process.exitCode = 1;
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
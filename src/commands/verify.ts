@@ -0,0 +1,103 @@
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { getInstalledRoot } from '../core/userdata.js';
+import { installedTypePaths } from '../core/registry.js';
+import { verifyType, acceptType, type IntegrityReport } from '../core/integrity.js';
+import { runVerify, type VerifyReport } from '../core/verify.js';
+import { REPORT_FORMATS, parseReportFormat, renderReport, verifySuites } from '../core/report.js';
+import { findRepoRoot } from '../utils/git.js';
+import { emitJson, wantsJson, ok, fail, failError, warn, info } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+
+const KIND_MARK: Record<string, string> = {
+  modified: chalk.yellow('M'),
+  missing: chalk.red('D'),
+  added: chalk.green('A'),
+};
+
+export function registerVerify(program: Command): void {
+  program
+    .command('verify')
+    .description('Check installed types against what was installed, run the CI checks on this project, or accept reviewed local edits')
+    .argument('[type-path]', 'Type to check (default: all installed types)')
+    .option('--accept <type-path>', 'Record the installed files of a type as reviewed')
+    .option('--ci', 'Run every CI check on this project and report them together')
+    .option('--json', 'Output as JSON')
+    .option('--format <format>', `Report format for the CI checks (${REPORT_FORMATS.join(', ')}); implies --ci`, 'text')
+    .action(async (typePath, opts) => {
+      try {
+        const installedRoot = getInstalledRoot();
+
+        if (opts.accept) {
+          const snapshot = acceptType(opts.accept, installedRoot);
+          ok(t('verify.accepted', { type: opts.accept, version: snapshot.version, count: snapshot.files.length }));
+          return;
+        }
+
+        const format = parseReportFormat(opts.format);
+        if (!typePath && (opts.ci || format !== 'text')) {
+          const report = await runVerify(findRepoRoot() ?? process.cwd(), installedRoot);
+          if (format === 'junit' || format === 'sarif') {
+            process.stdout.write(renderReport(format, verifySuites(report)));
+          } else if (wantsJson(opts) || format === 'json') {
+            emitJson(report);
+          } else {
+            printVerifyReport(report);
+          }
+          process.exitCode = report.passed ? 0 : 1;
+          return;
+        }
+
+        const paths = typePath ? [typePath] : installedTypePaths(installedRoot);
+        const reports = paths.map((p) => verifyType(p, installedRoot));
+        if (wantsJson(opts)) {
+          emitJson(reports);
+        } else {
+          printReports(reports);
+        }
+        if (reports.some((r) => r.changes.length > 0)) process.exitCode = 1;
+      } catch (err) {
+        failError(err);
+        // CI mode keeps "could not run" apart from "a check failed".
+        process.exit(opts.ci || opts.format !== 'text' ? 2 : 1);
+      }
+    });
+}
+
+function printVerifyReport(report: VerifyReport): void {
+  for (const check of report.checks) {
+    const detail = check.detail ? chalk.dim(` (${check.detail})`) : '';
+    if (check.status === 'skip') {
+      info(`${t('verify.skipped', { title: check.title })}${detail}`);
+    } else if (check.status === 'pass') {
+      ok(`${check.title}${detail}`);
+    } else {
+      fail(t('verify.problems', { title: check.title, count: check.failures.length }));
+      for (const f of check.failures) {
+        const where = f.line ? `${f.subject}:${f.line}` : f.subject;
+        console.log(`  ${chalk.bold(where)} ${f.message}`);
+      }
+    }
+  }
+  const failed = report.checks.filter((c) => c.status === 'fail').length;
+  if (failed > 0) fail(t('verify.failed', { failed, total: report.checks.length }));
+}
+
+function printReports(reports: IntegrityReport[]): void {
+  let clean = 0;
+  for (const report of reports) {
+    if (report.version === null) {
+      warn(t('verify.untracked', { type: report.typePath }), 'integrity');
+      continue;
+    }
+    if (report.changes.length === 0) {
+      clean++;
+      continue;
+    }
+    console.log(`${chalk.bold(report.typePath)}@${report.version}`);
+    for (const change of report.changes) {
+      console.log(`  ${KIND_MARK[change.kind]} ${change.path}`);
+    }
+  }
+  ok(t('verify.clean', { clean, total: reports.length }));
+}
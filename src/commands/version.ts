@@ -1,5 +1,7 @@
 import type { Command } from 'commander';
 import { APP_NAME } from '../config/branding.js';
+import { emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
 
 declare const __VERSION__: string;
 declare const __COMMIT__: string;
@@ -21,11 +23,11 @@ export function registerVersion(program: Command): void {
         return;
       }
 
-      if (opts.json) {
-        console.log(JSON.stringify({ version, commit, date }, null, 2));
+      if (wantsJson(opts)) {
+        emitJson({ version, commit, date });
         return;
       }
 
-      console.log(`${APP_NAME} version ${version} (commit: ${commit}, built: ${date})`);
+      console.log(t('version.line', { app: APP_NAME, version, commit, date }));
     });
 }
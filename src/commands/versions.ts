@@ -0,0 +1,122 @@
+import type { Command } from 'commander';
+import { join } from 'node:path';
+import { getInstalledRoot } from '../core/userdata.js';
+import { listSnapshots, loadSnapshot, materialize } from '../core/store.js';
+import { installNodeDeps, categoryFromPath } from '../core/registry.js';
+import { installedVersions, defaultVersion, addVersion, removeVersion } from '../core/versions.js';
+import { notifyChange } from '../core/notify.js';
+import { rebuildContentIndex } from '../core/content-index.js';
+import { APP_NAME } from '../config/branding.js';
+import { ok, fail, failError, warn, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+export function registerVersions(program: Command): void {
+  const cmd = program
+    .command('versions')
+    .description('Install several versions of a type side by side for projects that pin them');
+
+  cmd
+    .command('list')
+    .description('List installed and stored versions of a type')
+    .argument('<type-path>', 'Path to the installed type')
+    .option('--json', 'Output as JSON')
+    .action((typePath, opts) => {
+      try {
+        const installed = installedVersions(getInstalledRoot(), typePath);
+        const stored = listSnapshots(typePath).map((s) => s.version);
+        if (wantsJson(opts)) {
+          emitJson({ installed, stored });
+          return;
+        }
+        const rows = installed.map((v) => [v.version, t(v.default ? 'versions.default' : 'versions.sideBySide'), v.path]);
+        for (const version of stored) {
+          if (!installed.some((v) => v.version === version)) rows.push([version, t('versions.storedOnly'), '']);
+        }
+        if (rows.length === 0) {
+          fail(t('versions.notInstalled', { type: typePath }));
+          process.exit(1);
+        }
+        printTable([t('col.version'), t('col.status'), t('col.path')], rows);
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('add')
+    .description('Install a stored version next to the default one')
+    .argument('<type-path>', 'Path to the installed type')
+    .argument('<version>', `A version installed before (see \`${APP_NAME} versions list\`)`)
+    .action((typePath, version) => {
+      try {
+        const dir = addVersion(getInstalledRoot(), typePath, version);
+        const npmWarning = installNodeDeps(dir);
+        if (npmWarning) warn(npmWarning);
+        ok(t('versions.added', { type: typePath, version }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('remove')
+    .description('Remove a side-by-side version')
+    .argument('<type-path>', 'Path to the installed type')
+    .argument('<version>', 'Side-by-side version to remove')
+    .action((typePath, version) => {
+      try {
+        if (!removeVersion(getInstalledRoot(), typePath, version)) {
+          fail(t('versions.notSideBySide', { type: typePath, version }));
+          process.exit(1);
+        }
+        ok(t('versions.removed', { type: typePath, version }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+
+  cmd
+    .command('default')
+    .description('Make a stored version the default; the previous default stays installed side by side')
+    .argument('<type-path>', 'Path to the installed type')
+    .argument('<version>', 'Version to use when no pin says otherwise')
+    .action(async (typePath, version) => {
+      try {
+        const installedRoot = getInstalledRoot();
+        const target = loadSnapshot(typePath, version);
+        if (!target) {
+          fail(t('versions.notStored', { type: typePath, version, command: `${APP_NAME} versions list ${typePath}` }));
+          process.exit(1);
+        }
+        const previous = defaultVersion(installedRoot, typePath);
+        if (previous === version) {
+          ok(t('versions.alreadyDefault', { type: typePath, version }));
+          return;
+        }
+
+        materialize(target, installedRoot);
+        removeVersion(installedRoot, typePath, version);
+        const dirs = [join(installedRoot, typePath)];
+        // Projects pinning the old default keep working
+        const kept = previous !== null && loadSnapshot(typePath, previous) !== null;
+        if (kept) dirs.push(addVersion(installedRoot, typePath, previous));
+        for (const dir of dirs) {
+          const npmWarning = installNodeDeps(dir);
+          if (npmWarning) warn(npmWarning);
+        }
+
+        if (categoryFromPath(typePath) === 'context') rebuildContentIndex(installedRoot);
+        await notifyChange('update', [typePath]);
+        ok(kept
+          ? t('versions.nowDefaultKept', { type: typePath, version, previous: String(previous) })
+          : t('versions.nowDefault', { type: typePath, version }));
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -0,0 +1,40 @@
+import type { Command } from 'commander';
+import { buildSources } from '../core/extension.js';
+import { explainResolution } from '../core/registry.js';
+import { sourceLabel } from '../core/merge.js';
+import { findRepoRoot } from '../utils/git.js';
+import { ok, fail, failError, emitJson, wantsJson } from '../ui/output.js';
+import { t } from '../ui/i18n.js';
+import { printTable } from '../ui/table.js';
+
+export function registerWhich(program: Command): void {
+  program
+    .command('which')
+    .description('Show which source provides a type, and why it won')
+    .argument('<type-path>', 'Type path (e.g., context/security/oauth)')
+    .option('--json', 'Output as JSON')
+    .action((typePath: string, opts) => {
+      try {
+        const r = explainResolution(typePath, buildSources(findRepoRoot() ?? process.cwd()));
+        if (wantsJson(opts)) {
+          emitJson(r);
+        } else {
+          printTable(
+            [t('col.source'), t('col.priority'), t('col.provides'), t('col.notes')],
+            r.candidates.map((c) => [
+              c.source,
+              String(c.priority),
+              c.provides ? t('common.yes') : '-',
+              [c.pinnedBy ? `prefer: ${c.pinnedBy}` : '', c.merge ? `merge: ${c.merge}` : ''].filter(Boolean).join(', '),
+            ]),
+          );
+          if (r.resolved) ok(`${r.resolved.typePath} ← ${sourceLabel(r.resolved)}: ${r.reason}`);
+          else fail(r.reason);
+        }
+        if (!r.resolved) process.exitCode = 1;
+      } catch (err) {
+        failError(err);
+        process.exit(1);
+      }
+    });
+}
@@ -0,0 +1,57 @@
+import type { OutputFormat } from '../ui/output.js';
+import type { LogLevel } from '../utils/log.js';
+
+// ── Execution context ───────────────────────────────────────────────
+//
+// Global flags, parsed once per invocation by the CLI middleware (see
+// commands/middleware.ts) and readable from anywhere, so a cross-cutting
+// behavior is one field here instead of an option on every command.
+
+export type Answers = 'ask' | 'defaults' | 'yes';
+
+export interface ExecutionContext {
+  output: OutputFormat;
+  /** Prompts may be shown: a terminal, not CI, and no --no-input. */
+  interactive: boolean;
+  /**
+   * How prompts are answered: by asking, with their defaults
+   * (--non-interactive), or with yes to every confirmation (--yes).
+   */
+  answers: Answers;
+  color: boolean;
+  traceFs: boolean;
+  /** Lowest log level shown on stderr (--verbose, --quiet). */
+  logLevel: LogLevel;
+  logJson: boolean;
+  /** Suppress progress and success messages. */
+  quiet: boolean;
+  /** Skip network operations (see core/offline.ts). */
+  offline: boolean;
+}
+
+const DEFAULTS: ExecutionContext = {
+  output: 'text',
+  interactive: Boolean(process.stdin.isTTY),
+  answers: 'ask',
+  color: true,
+  traceFs: false,
+  logLevel: 'warn',
+  logJson: false,
+  quiet: false,
+  offline: false,
+};
+
+let current: ExecutionContext = { ...DEFAULTS };
+
+export function executionContext(): Readonly<ExecutionContext> {
+  return current;
+}
+
+export function setExecutionContext(ctx: ExecutionContext): void {
+  current = { ...ctx };
+}
+
+/** Restores the defaults; for tests. */
+export function resetExecutionContext(): void {
+  current = { ...DEFAULTS };
+}
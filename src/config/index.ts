@@ -1,3 +1,4 @@
 export * from './branding.js';
 export * from './schema.js';
 export * as settings from './settings.js';
+export * from './keys.js';
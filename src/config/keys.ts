@@ -0,0 +1,253 @@
+// ── Known settings ──────────────────────────────────────────────────
+//
+// Every key `agentx config set` accepts, with its type. Values typed on
+// the command line are parsed here into what is stored in config.yaml
+// (true, 20, [a, b]), so a typo is caught when it is set rather than
+// when something reads it.
+//
+// A project's .agentx/config.yaml is committed and arrives with every
+// clone, so it may only override keys marked scope: 'project' (layout
+// and preference, nothing that runs commands, picks endpoints, or relaxes
+// a check). Every other key is read from the user's config alone.
+
+import { parseDuration } from '../utils/units.js';
+import { compilePatterns } from '../utils/redact.js';
+
+export type SettingType = 'string' | 'boolean' | 'integer' | 'duration' | 'url' | 'list' | 'enum';
+
+export interface SettingSpec {
+  type: SettingType;
+  description: string;
+  /** Allowed values of an enum. */
+  values?: string[];
+  /** Shown by `config list --all` when unset. */
+  default?: string;
+  /** 'project' when a project's config may override it; default 'global'. */
+  scope?: 'global' | 'project';
+}
+
+export const SETTINGS: Record<string, SettingSpec> = {
+  catalog_url: { type: 'url', description: 'Git URL of the catalog repository' },
+  mirror: { type: 'url', description: 'Nexus/Artifactory raw repository for updates and catalog and extension bundles' },
+  'mirror.auth_header': {
+    type: 'string',
+    description: 'Header carrying the mirror token (e.g. X-JFrog-Art-Api)',
+    default: 'Authorization',
+  },
+  locale: {
+    type: 'enum',
+    values: ['en', 'es'],
+    description: 'Language of CLI messages; unset follows LC_ALL, LC_MESSAGES, or LANG',
+    scope: 'project',
+  },
+  offline: { type: 'boolean', description: 'Skip network operations, like --offline', default: 'false' },
+  'http.ca_bundle': {
+    type: 'string',
+    description: 'PEM file of extra CA certificates to trust (also NODE_EXTRA_CA_CERTS)',
+  },
+  'http.timeout': {
+    type: 'duration',
+    description: 'Connect and response timeout for HTTP requests',
+    default: '30s',
+    scope: 'project',
+  },
+  'http.host_timeouts': {
+    type: 'list',
+    description: 'Per-host timeouts as host=duration, e.g. nexus.acme.io=2m',
+    scope: 'project',
+  },
+  'telemetry.enabled': {
+    type: 'boolean',
+    description: 'Send anonymous usage statistics to telemetry.endpoint',
+    default: 'false',
+  },
+  'telemetry.local': {
+    type: 'boolean',
+    description: 'Keep usage statistics in ~/.agentx/stats.json without sending them',
+    default: 'false',
+  },
+  'telemetry.endpoint': { type: 'url', description: 'Where telemetry.enabled sends usage events' },
+  'create.default_runtime': {
+    type: 'enum',
+    values: ['node', 'python'],
+    description: 'Runtime of new skills when --runtime is not given',
+    default: 'node',
+    scope: 'project',
+  },
+  'extensions.allowed_hosts': {
+    type: 'list',
+    description: 'Hosts extensions may be added from (empty allows any)',
+  },
+  'install.node_cache': {
+    type: 'boolean',
+    description: 'Share node_modules between Node skills with the same package-lock.json',
+    default: 'true',
+    scope: 'project',
+  },
+  'cache.background_refresh': {
+    type: 'boolean',
+    description: 'Rebuild the registry index in a background process after changes and during runs',
+    default: 'false',
+    scope: 'project',
+  },
+  'cache.refresh_interval': {
+    type: 'duration',
+    description: 'How old the registry index must be before a run refreshes it in the background',
+    default: '1h',
+    scope: 'project',
+  },
+  'embeddings.provider': {
+    type: 'enum',
+    values: ['local', 'api'],
+    description: 'Embedder for semantic search',
+    default: 'local',
+    scope: 'project',
+  },
+  'embeddings.url': { type: 'url', description: 'OpenAI-compatible embeddings endpoint (provider api)' },
+  'embeddings.model': { type: 'string', description: 'Embedding model name (provider api)', scope: 'project' },
+  'manifest.strict': {
+    type: 'boolean',
+    description: 'Reject unknown manifest fields everywhere',
+    default: 'false',
+    scope: 'project',
+  },
+  'overrides.merge_driver': {
+    type: 'string',
+    description: 'builtin, git, or a command merging override files',
+    default: 'builtin',
+  },
+  'run.manifest_guard': {
+    type: 'enum',
+    values: ['block', 'warn', 'off'],
+    description: 'What run does when an installed manifest was edited',
+    default: 'block',
+  },
+  'run.output_schema': {
+    type: 'enum',
+    values: ['fail', 'warn', 'off'],
+    description: "What run does when a skill's output breaks its outputs.schema",
+    default: 'warn',
+    scope: 'project',
+  },
+  'link.copy': {
+    type: 'boolean',
+    description: 'Copy context into tool directories instead of symlinking (like link sync --force-copy)',
+    default: 'false',
+    scope: 'project',
+  },
+  'lock.timeout': {
+    type: 'duration',
+    description: 'How long a command waits for another agentx process to finish',
+    default: '30s',
+    scope: 'project',
+  },
+  'run.env_isolation': {
+    type: 'enum',
+    values: ['scrub', 'inherit'],
+    description: 'Whether skills see only allowed host variables or the whole environment',
+    default: 'scrub',
+  },
+  'tokens.account': {
+    type: 'string',
+    description: 'Token set skills use by default (tokens.<account>.env), like run --account',
+    scope: 'project',
+  },
+  'run.env_allow': { type: 'list', description: 'Extra host variables passed to every skill' },
+  'publish.env_allow': { type: 'list', description: 'Host variables workflow publish headers may read as {{env.NAME}}' },
+  'publish.auth_hosts': {
+    type: 'list',
+    description: 'Hosts workflow publish steps may send stored credentials to',
+  },
+  'redact.patterns': {
+    type: 'list',
+    description: 'Regexes of secret names and values to mask in output and logs',
+  },
+  'output.history': {
+    type: 'integer',
+    description: 'Past skill outputs kept under output/history/',
+    default: '20',
+    scope: 'project',
+  },
+  'hooks.pre-install': { type: 'list', description: 'Commands run before types are installed; a failure cancels the install' },
+  'hooks.post-install': { type: 'list', description: 'Commands run after an install succeeds or fails' },
+  'hooks.post-link-sync': { type: 'list', description: 'Commands run after link sync regenerates tool configs' },
+  'hooks.pre-run': { type: 'list', description: 'Commands run before a skill; a failure keeps it from running' },
+  'hooks.post-run': { type: 'list', description: 'Commands run after a skill, with its exit code' },
+  'serve.token': { type: 'string', description: 'Bearer token `serve http` requires (or AGENTX_SERVE_TOKEN)' },
+  'hooks.timeout': {
+    type: 'duration',
+    description: 'How long one hook may run before it is stopped',
+    default: '60s',
+    scope: 'project',
+  },
+};
+
+/** Whether a project's .agentx/config.yaml may set key. */
+export function projectOverridable(key: string): boolean {
+  return SETTINGS[key]?.scope === 'project';
+}
+
+export function settingSpec(key: string): SettingSpec {
+  const spec = SETTINGS[key];
+  if (!spec) {
+    throw new Error(`Unknown setting "${key}". Known settings: ${Object.keys(SETTINGS).sort().join(', ')}`);
+  }
+  return spec;
+}
+
+/** Parses a command-line value into the stored form of key's type. */
+export function parseSetting(key: string, raw: string): string | number | boolean | string[] {
+  const spec = settingSpec(key);
+  const invalid = (expected: string) => new Error(`Invalid value for ${key}: "${raw}" (expected ${expected})`);
+  switch (spec.type) {
+    case 'boolean':
+      if (/^(true|yes|on|1)$/i.test(raw)) return true;
+      if (/^(false|no|off|0)$/i.test(raw)) return false;
+      throw invalid('true or false');
+    case 'integer':
+      if (!/^\d+$/.test(raw)) throw invalid('a whole number');
+      return Number(raw);
+    case 'duration':
+      try {
+        parseDuration(raw);
+      } catch {
+        throw invalid('a duration like 30s, 5m');
+      }
+      return raw;
+    case 'url':
+      try {
+        new URL(raw);
+      } catch {
+        // git@host:org/repo is not a URL but is a valid git remote
+        if (!/^[\w.-]+@[\w.-]+:/.test(raw)) throw invalid('a URL');
+      }
+      return raw;
+    case 'enum':
+      if (!spec.values?.includes(raw)) throw invalid(spec.values?.join(', ') ?? '');
+      return raw;
+    case 'list': {
+      const items = raw.split(',').map((s) => s.trim()).filter(Boolean);
+      if (key === 'redact.patterns') {
+        try {
+          compilePatterns(items);
+        } catch (err) {
+          throw invalid(`regexes; ${(err as Error).message}`);
+        }
+      }
+      if (key === 'http.host_timeouts') {
+        for (const item of items) {
+          const [host, duration] = item.split('=');
+          try {
+            if (!host) throw new Error();
+            parseDuration(duration ?? '');
+          } catch {
+            throw invalid('host=duration pairs like nexus.acme.io=2m');
+          }
+        }
+      }
+      return items;
+    }
+    default:
+      return raw;
+  }
+}
@@ -1,4 +1,6 @@
 import { z } from 'zod';
+import { ALL_TOOLS } from '../types/integrations.js';
+import type { ActiveConfig, ExtensionConfig, ProjectFile } from '../core/linker.js';
 
 // ── Shared sub-schemas ──────────────────────────────────────────────
 
@@ -9,15 +11,21 @@ export const CLIDependencySchema = z.object({
 
 export const InputFieldSchema = z.object({
   name: z.string(),
-  type: z.enum(['string', 'number', 'boolean', 'array', 'object']),
+  /** file: must exist, passed as an absolute path. array: -i may repeat, passed as a JSON array. */
+  type: z.enum(['string', 'number', 'boolean', 'array', 'object', 'file']),
   required: z.boolean().optional(),
   default: z.unknown().optional(),
   description: z.string().optional(),
+  /** Masked in logs and reports, like a token. */
+  sensitive: z.boolean().optional(),
+  /** Sensitive, asked for without echo, and passed in AGENTX_INPUT_<NAME> rather than the command line. */
+  secret: z.boolean().optional(),
 });
 
 export const OutputDeclarationSchema = z.object({
   format: z.string(),
-  schema: z.string().optional(),
+  /** JSON Schema for the output: a JSON or YAML file in the type's directory, or the schema itself. */
+  schema: z.union([z.string(), z.record(z.string(), z.unknown())]).optional(),
 });
 
 export const RegistryTokenSchema = z.object({
@@ -25,6 +33,10 @@ export const RegistryTokenSchema = z.object({
   required: z.boolean().optional(),
   default: z.string().optional(),
   description: z.string().optional(),
+  /** Masked in logs and reports even if the name doesn't look secret. */
+  sensitive: z.boolean().optional(),
+  /** Former name; an upgrade moves the saved value over. */
+  renamed_from: z.string().optional(),
 });
 
 export const RegistryTemplatesSchema = z.object({
@@ -32,20 +44,66 @@ export const RegistryTemplatesSchema = z.object({
   description: z.string().optional(),
 });
 
+/** Caps on a skill's process; unsupported ones are skipped with a warning. */
+export const SkillLimitsSchema = z.object({
+  /** Memory, e.g. "512MB": address space on Linux, V8 heap for Node skills. */
+  memory: z.string().regex(/^\d+(\.\d+)?\s*(B|KB|KiB|MB|MiB|GB|GiB)?$/i, 'A size, e.g. 512MB').optional(),
+  /** CPU time, e.g. "60s" (Linux and macOS). */
+  cpu: z.string().regex(/^\d+\s*(s|m|h)$/, 'A duration, e.g. 60s or 5m').optional(),
+  /** Wall-clock time before the skill is killed, e.g. "10m". */
+  timeout: z.string().regex(/^\d+\s*(s|m|h)$/, 'A duration, e.g. 60s or 5m').optional(),
+});
+
+/** How a `mode: daemon` skill is supervised (see core/daemon.ts). */
+export const DaemonPolicySchema = z.object({
+  /** When the supervisor starts the skill again after it exits. */
+  restart: z.enum(['always', 'on-failure', 'never']).optional(),
+  /** Restarts in a row, each after a short run, before the supervisor gives up. */
+  max_restarts: z.number().int().min(0).optional(),
+  /** Wait before a restart, e.g. "5s". */
+  restart_delay: z.string().regex(/^\d+\s*(s|m|h)$/, 'A duration, e.g. 5s or 1m').optional(),
+});
+
 export const RegistryBlockSchema = z.object({
   tokens: z.array(RegistryTokenSchema).optional(),
   config: z.record(z.string(), z.unknown()).optional(),
   state: z.array(z.string()).optional(),
-  output: z.object({ schema: z.string().optional() }).optional(),
+  /** Size state/ may grow to before doctor warns, e.g. "50MB". */
+  state_max_size: z.string().regex(/^\d+(\.\d+)?\s*(B|KB|KiB|MB|MiB|GB|GiB)?$/i, 'A size, e.g. 512KB or 50MB').optional(),
+  output: z.object({
+    schema: z.string().optional(),
+    /** Past outputs to keep under output/history/ (0 disables). */
+    history: z.number().int().nonnegative().optional(),
+  }).optional(),
   templates: RegistryTemplatesSchema.nullable().optional(),
+  /** Script run on upgrade to carry an existing registry forward. */
+  migrate: z.string().optional(),
 });
 
-export const WorkflowStepSchema = z.object({
+export const SkillStepSchema = z.object({
   id: z.string(),
   skill: z.string().regex(/^skills\/[a-z0-9-]+(\/[a-z0-9-]+)*$/),
   inputs: z.record(z.string(), z.unknown()).optional(),
 });
 
+/** Delivers earlier steps' outputs to a file, an HTTP endpoint, or S3. */
+export const PublishSchema = z.object({
+  /** Destination: a path, file://, http(s)://, or s3:// URL. Templated. */
+  to: z.string().min(1),
+  /** Step ids whose outputs to publish (default: the previous step). */
+  from: z.union([z.string(), z.array(z.string()).min(1)]).optional(),
+  method: z.enum(['POST', 'PUT']).optional(),
+  content_type: z.string().optional(),
+  headers: z.record(z.string(), z.string()).optional(),
+});
+
+export const PublishStepSchema = z.object({
+  id: z.string(),
+  publish: PublishSchema,
+});
+
+export const WorkflowStepSchema = z.union([SkillStepSchema, PublishStepSchema]);
+
 export const TemplateVariableSchema = z.object({
   name: z.string(),
   description: z.string().optional(),
@@ -56,6 +114,12 @@ export const TemplateVariableSchema = z.object({
 // ── Base fields (shared by all manifest types) ──────────────────────
 
 const namePattern = /^[a-z0-9][a-z0-9-]*$/;
+
+/** A node_modules archive shipped with a Node skill or workflow (core/vendored-deps.ts). */
+const VendoredDepsSchema = z
+  .string()
+  .regex(/^(?!\/)(?!.*(^|\/)\.\.(\/|$)).+\.(tar\.zst|tar\.gz|tgz)$/, 'A .tar.zst, .tar.gz, or .tgz path inside the type');
+const typePathPattern = /^[a-z]+(\/[a-z0-9-]+)+$/;
 const versionPattern = /^v?[0-9]+(\.[0-9]+)*(-[a-zA-Z0-9.-]+)?$/;
 
 const BaseFields = {
@@ -65,6 +129,9 @@ const BaseFields = {
   tags: z.array(z.string()).optional(),
   author: z.string().optional(),
   vendor: z.string().nullable().optional(),
+  deprecated: z.boolean().optional(),
+  replaced_by: z.string().regex(typePathPattern, 'A type path, e.g. skills/scm/git/commit').optional(),
+  aliases: z.array(z.string().regex(typePathPattern, 'A type path, e.g. skills/scm/git/commit')).optional(),
 };
 
 // ── Manifest type schemas ───────────────────────────────────────────
@@ -100,15 +167,46 @@ export const PersonaManifestSchema = z.object({
   template: z.string().optional(),
 });
 
+/** What a skill test case expects; every given check must hold. */
+export const SkillTestExpectSchema = z.object({
+  exit_code: z.number().int().optional(),
+  /** Exact stdout, ignoring surrounding whitespace. */
+  stdout: z.string().optional(),
+  contains: z.union([z.string(), z.array(z.string())]).optional(),
+  /** Regular expression stdout must match. */
+  matches: z.string().optional(),
+  /** Values stdout, parsed as JSON, must contain. */
+  json: z.unknown().optional(),
+});
+
+export const SkillTestSchema = z.object({
+  name: z.string(),
+  inputs: z.record(z.string(), z.unknown()).optional(),
+  /** Extra environment variables, e.g. fake tokens. */
+  env: z.record(z.string(), z.string()).optional(),
+  expect: SkillTestExpectSchema.optional(),
+});
+
 export const SkillManifestSchema = z.object({
   ...BaseFields,
   type: z.literal('skill'),
-  runtime: z.enum(['node', 'go']),
+  runtime: z.enum(['node', 'go', 'python']),
   topic: z.string(),
   cli_dependencies: z.array(CLIDependencySchema).optional(),
   inputs: z.array(InputFieldSchema).optional(),
   outputs: OutputDeclarationSchema.optional(),
   registry: RegistryBlockSchema.optional(),
+  tests: z.array(SkillTestSchema).optional(),
+  limits: SkillLimitsSchema.optional(),
+  /** Host environment variables passed through besides tokens and the safe defaults. */
+  host_env: z.array(z.string()).optional(),
+  /** false: tell the skill and its tools not to use the network (a hint, not enforced). */
+  network: z.boolean().optional(),
+  /** daemon: a long-running poller, started with `agentx daemon start` instead of `run`. */
+  mode: z.enum(['run', 'daemon']).optional(),
+  daemon: DaemonPolicySchema.optional(),
+  /** Installed by unpacking this archive instead of running npm. */
+  vendored_deps: VendoredDepsSchema.optional(),
 });
 
 export const WorkflowManifestSchema = z.object({
@@ -118,6 +216,7 @@ export const WorkflowManifestSchema = z.object({
   steps: z.array(WorkflowStepSchema).min(1),
   inputs: z.array(InputFieldSchema).optional(),
   outputs: OutputDeclarationSchema.optional(),
+  vendored_deps: VendoredDepsSchema.optional(),
 });
 
 export const PromptManifestSchema = z.object({
@@ -134,6 +233,8 @@ export const PromptManifestSchema = z.object({
     .array(z.string().regex(/^workflows\/[a-z0-9-]+(\/[a-z0-9-]+)*$/))
     .optional(),
   template: z.string().optional(),
+  /** Values filled in at compose time: {{name}} in the rendered prompt. */
+  variables: z.array(TemplateVariableSchema).optional(),
 });
 
 export const TemplateManifestSchema = z.object({
@@ -143,6 +244,98 @@ export const TemplateManifestSchema = z.object({
   variables: z.array(TemplateVariableSchema).optional(),
 });
 
+// ── Extension manifest ──────────────────────────────────────────────
+
+export const CAPABILITY_KINDS = ['scaffold', 'hook', 'detection'] as const;
+
+export type CapabilityKind = (typeof CAPABILITY_KINDS)[number];
+
+export const CapabilitySchema = z.object({
+  kind: z.enum(CAPABILITY_KINDS),
+  name: z.string(),
+  description: z.string().optional(),
+  path: z.string().optional(),
+  command: z.string().optional(),
+  /** hook: the lifecycle event it runs on (post-install, pre-run, ...). */
+  event: z.string().optional(),
+});
+
+/**
+ * How an extension's copy of a type combines with the copy an earlier
+ * source provides: `override` replaces it, `append` (context only) adds
+ * this extension's content after it.
+ */
+export const MERGE_STRATEGIES = ['append', 'override'] as const;
+
+export type MergeStrategy = (typeof MERGE_STRATEGIES)[number];
+
+export const ExtensionManifestSchema = z.object({
+  name: z.string().regex(namePattern, 'Lowercase alphanumeric with hyphens'),
+  description: z.string().optional(),
+  capabilities: z.array(CapabilitySchema).optional(),
+  /** Merge strategy by type path; unlisted types are shadowed by earlier sources. */
+  merge: z.record(z.string(), z.enum(MERGE_STRATEGIES)).optional(),
+});
+
+// ── Scaffold template sets ──────────────────────────────────────────
+
+export const ScaffoldSetSchema = z.object({
+  name: z.string().regex(namePattern, 'Lowercase alphanumeric with hyphens'),
+  type: z.enum(MANIFEST_TYPES),
+  description: z.string().optional(),
+  runtime: z.string().optional(),
+  variables: z.array(TemplateVariableSchema).optional(),
+  hooks: z.array(z.string()).optional(),
+});
+
+// ── Presets ─────────────────────────────────────────────────────────
+
+export const PresetSchema = z.object({
+  name: z.string().regex(namePattern, 'Lowercase alphanumeric with hyphens'),
+  description: z.string().optional(),
+  /** AI tools a project initialized from the preset configures. */
+  tools: z.array(z.string()).optional(),
+  personas: z.array(z.string()).optional(),
+  context: z.array(z.string()).optional(),
+  skills: z.array(z.string()).optional(),
+  workflows: z.array(z.string()).optional(),
+  prompts: z.array(z.string()).optional(),
+});
+
+// ── Project config ──────────────────────────────────────────────────
+
+// Each shape is checked against the ProjectFile type from core/linker.ts:
+// a field added to ProjectConfig, or removed from it, fails to compile
+// here until the schema follows, so verify can't drift from what
+// saveProject writes.
+
+const activeShape = {
+  personas: z.array(z.string()).optional(),
+  context: z.array(z.string()).optional(),
+  skills: z.array(z.string()).optional(),
+  workflows: z.array(z.string()).optional(),
+  prompts: z.array(z.string()).optional(),
+} satisfies Record<keyof ActiveConfig, z.ZodType>;
+
+const extensionShape = {
+  name: z.string(),
+  priority: z.number().optional(),
+} satisfies Record<keyof ExtensionConfig, z.ZodType>;
+
+const projectFileShape = {
+  tools: z.array(z.enum(ALL_TOOLS)),
+  active: z.object(activeShape).optional(),
+  generated_by: z.string().optional(),
+  resolution: z.array(z.string()).optional(),
+  extensions: z.array(z.object(extensionShape)).optional(),
+  prefer: z.record(z.string(), z.string()).optional(),
+  hooks: z.record(z.string(), z.union([z.string(), z.array(z.string())])).optional(),
+  variables: z.record(z.string(), z.string()).optional(),
+} satisfies Record<keyof ProjectFile, z.ZodType>;
+
+/** .agentx/project.yaml as written by core/linker.ts. */
+export const ProjectFileSchema = z.object(projectFileShape) satisfies z.ZodType<ProjectFile>;
+
 // ── Discriminated union ─────────────────────────────────────────────
 
 export const ManifestSchema = z.discriminatedUnion('type', [
@@ -1,31 +1,107 @@
 import { readFileSync, writeFileSync, mkdirSync } from 'node:fs';
 import { dirname } from 'node:path';
 import yaml from 'js-yaml';
+import { projectOverridable } from './keys.js';
+import { logger } from '../utils/log.js';
 
-let configPath = '';
-let configData: Record<string, unknown> = {};
+// ── Settings ────────────────────────────────────────────────────────
+//
+// Flat keys ("run.manifest_guard") in two layers: the user's
+// ~/.agentx/config.yaml and, when the working directory is in a project,
+// <project>/.agentx/config.yaml, which overrides it key by key. The
+// project file comes with the repository, so it is only read for keys
+// that allow it (see projectOverridable in keys.ts); the rest are
+// ignored with a warning.
 
-export function init(path: string): void {
-  configPath = path;
+export type Scope = 'global' | 'project';
+
+const paths: Record<Scope, string> = { global: '', project: '' };
+const layers: Record<Scope, Record<string, unknown>> = { global: {}, project: {} };
+
+function load(path: string): Record<string, unknown> {
+  if (!path) return {};
   try {
     const raw = readFileSync(path, 'utf-8');
-    configData = (yaml.load(raw) as Record<string, unknown>) ?? {};
+    return (yaml.load(raw) as Record<string, unknown>) ?? {};
   } catch {
-    configData = {};
+    return {};
+  }
+}
+
+export function init(path: string, projectPath = ''): void {
+  paths.global = path;
+  paths.project = projectPath;
+  layers.global = load(path);
+  layers.project = {};
+  const ignored: string[] = [];
+  for (const [key, value] of Object.entries(load(projectPath))) {
+    if (projectOverridable(key)) layers.project[key] = value;
+    else ignored.push(key);
+  }
+  if (ignored.length > 0) {
+    logger('config').warn(
+      `Ignoring ${ignored.join(', ')} in ${projectPath}: only the user config (${path}) may set ${ignored.length === 1 ? 'it' : 'them'}`,
+    );
   }
 }
 
+function lookup(key: string): unknown {
+  return layers.project[key] ?? layers.global[key];
+}
+
 export function get(key: string): string {
-  const value = configData[key];
-  return value != null ? String(value) : '';
+  const value = lookup(key);
+  if (value == null) return '';
+  return Array.isArray(value) ? value.join(',') : String(value);
 }
 
-export function set(key: string, value: string): void {
-  configData[key] = value;
-  mkdirSync(dirname(configPath), { recursive: true });
-  writeFileSync(configPath, yaml.dump(configData), 'utf-8');
+/** A list setting; a plain string is split on commas. */
+export function getList(key: string): string[] {
+  const value = lookup(key);
+  if (value == null) return [];
+  const items = Array.isArray(value) ? value.map(String) : String(value).split(',');
+  return items.map((s) => s.trim()).filter(Boolean);
 }
 
+/** Which layer a key's effective value comes from, or null when unset. */
+export function origin(key: string): Scope | null {
+  if (layers.project[key] != null) return 'project';
+  if (layers.global[key] != null) return 'global';
+  return null;
+}
+
+function save(scope: Scope): void {
+  const path = paths[scope];
+  if (!path) throw new Error(`No ${scope} config file (settings are not initialized)`);
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, yaml.dump(layers[scope]), 'utf-8');
+}
+
+export function set(key: string, value: unknown, scope: Scope = 'global'): void {
+  if (scope === 'project' && !projectOverridable(key)) {
+    throw new Error(`${key} can only be set in the user config, not per project`);
+  }
+  layers[scope][key] = value;
+  save(scope);
+}
+
+/** Removes a key from a layer; false when it was not set there. */
+export function unset(key: string, scope: Scope = 'global'): boolean {
+  if (!(key in layers[scope])) return false;
+  delete layers[scope][key];
+  save(scope);
+  return true;
+}
+
+/** Effective settings, project over global. */
 export function all(): Record<string, unknown> {
-  return { ...configData };
+  return { ...layers.global, ...layers.project };
+}
+
+export function layer(scope: Scope): Record<string, unknown> {
+  return { ...layers[scope] };
+}
+
+export function pathOf(scope: Scope): string {
+  return paths[scope];
 }
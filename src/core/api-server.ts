@@ -0,0 +1,270 @@
+import { createServer, type Server, type IncomingMessage, type ServerResponse } from 'node:http';
+import { randomUUID, timingSafeEqual } from 'node:crypto';
+import { EventEmitter } from 'node:events';
+import { discoverAll } from './registry.js';
+import { subscribe } from './notify.js';
+import type { DiscoveredType } from '../types/registry.js';
+import { executeType, inputSchema, type StepResult } from './executor.js';
+import { maskInputs } from '../utils/input-parser.js';
+import { listHistory } from './output-history.js';
+import { logger } from '../utils/log.js';
+import { errorCode } from './errors.js';
+
+const log = logger('serve');
+
+// ── HTTP API ────────────────────────────────────────────────────────
+//
+// `agentx serve http` lets internal web tools run the skills developers
+// run locally. Every request needs `Authorization: Bearer <serve.token>`.
+//
+//   GET  /v1/types[?category=skill]   installed types
+//   POST /v1/runs                     {"typePath", "inputs"} → 202 and the run
+//   GET  /v1/runs                     runs started by this server, newest first
+//   GET  /v1/runs/<id>                one run, with its output so far
+//   GET  /v1/runs/<id>/events         output as server-sent events, then "exit"
+//   GET  /v1/history/<type-path>      the skill's saved output history
+//
+// Runs are kept in memory for the life of the server, up to maxRuns.
+// The installed types are read once and kept until another agentx
+// process installs or uninstalls something (core/notify.ts).
+
+const MAX_BODY_BYTES = 1024 * 1024;
+const DEFAULT_MAX_RUNS = 100;
+
+export type RunStatus = 'running' | 'succeeded' | 'failed';
+
+export interface RunEvent {
+  event: 'stdout' | 'stderr' | 'step' | 'exit';
+  data: string;
+}
+
+export interface RunRecord {
+  id: string;
+  typePath: string;
+  /** As given, with secret values masked. */
+  inputs: Record<string, string>;
+  status: RunStatus;
+  exitCode?: number;
+  error?: string;
+  /** Set when error has a code (core/errors.ts). */
+  errorCode?: string;
+  steps: StepResult[];
+  startedAt: string;
+  finishedAt?: string;
+  events: RunEvent[];
+}
+
+export interface ApiServerOptions {
+  token: string;
+  installedRoot: string;
+  /** Finished runs kept for GET /v1/runs. */
+  maxRuns?: number;
+}
+
+class HttpError extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+  ) {
+    super(message);
+  }
+}
+
+function authorized(req: IncomingMessage, token: string): boolean {
+  const header = req.headers.authorization ?? '';
+  const given = Buffer.from(header.replace(/^Bearer\s+/i, ''));
+  const expected = Buffer.from(token);
+  return given.length === expected.length && timingSafeEqual(given, expected);
+}
+
+function sendJson(res: ServerResponse, status: number, body: unknown): void {
+  res.writeHead(status, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify(body));
+}
+
+async function readJson(req: IncomingMessage): Promise<Record<string, unknown>> {
+  let size = 0;
+  const chunks: Buffer[] = [];
+  for await (const chunk of req) {
+    size += (chunk as Buffer).length;
+    if (size > MAX_BODY_BYTES) throw new HttpError(413, 'Request body too large');
+    chunks.push(chunk as Buffer);
+  }
+  try {
+    const body = JSON.parse(Buffer.concat(chunks).toString('utf-8') || '{}');
+    if (body && typeof body === 'object' && !Array.isArray(body)) return body as Record<string, unknown>;
+  } catch {
+    // Reported below
+  }
+  throw new HttpError(400, 'Expected a JSON object');
+}
+
+/** A run without its event log, for listings. */
+function summary(run: RunRecord): Omit<RunRecord, 'events'> {
+  const { events: _events, ...rest } = run;
+  return rest;
+}
+
+function output(run: RunRecord, stream: 'stdout' | 'stderr'): string {
+  return run.events.filter((e) => e.event === stream).map((e) => e.data).join('');
+}
+
+export function createApiServer(opts: ApiServerOptions): Server {
+  if (!opts.token) throw new Error('The HTTP API needs a token');
+  const maxRuns = opts.maxRuns ?? DEFAULT_MAX_RUNS;
+  const runs = new Map<string, RunRecord>();
+  const live = new EventEmitter();
+  live.setMaxListeners(0);
+
+  let types: DiscoveredType[] | null = null;
+  const installedTypes = () => (types ??= discoverAll([{ name: 'installed', basePath: opts.installedRoot }]));
+  const changes = subscribe(
+    (event) => {
+      types = null;
+      log.info('installed types changed', { kind: event.kind, types: event.typePaths });
+    },
+    { socket: true },
+  );
+
+  function emit(run: RunRecord, event: RunEvent): void {
+    run.events.push(event);
+    live.emit(run.id, event);
+  }
+
+  function prune(): void {
+    const finished = [...runs.values()].filter((r) => r.status !== 'running');
+    for (const stale of finished.slice(0, Math.max(0, runs.size - maxRuns))) runs.delete(stale.id);
+  }
+
+  function startRun(typePath: string, inputs: Record<string, string>): RunRecord {
+    const run: RunRecord = {
+      id: randomUUID(),
+      typePath,
+      // Run records are listed and streamed; only executeType sees the values
+      inputs: maskInputs(inputs, inputSchema(typePath, opts.installedRoot)),
+      status: 'running',
+      steps: [],
+      startedAt: new Date().toISOString(),
+      events: [],
+    };
+    runs.set(run.id, run);
+    prune();
+    log.info('run', { id: run.id, type: typePath });
+
+    executeType(typePath, inputs, opts.installedRoot, {
+      onOutput: (stream, data) => emit(run, { event: stream, data }),
+      onStep: (step) => {
+        run.steps.push(step);
+        emit(run, { event: 'step', data: JSON.stringify(step) });
+      },
+    })
+      .then((result) => {
+        run.exitCode = result.exitCode;
+        run.status = result.exitCode === 0 ? 'succeeded' : 'failed';
+      })
+      .catch((err: unknown) => {
+        run.error = err instanceof Error ? err.message : String(err);
+        const code = errorCode(err);
+        if (code) run.errorCode = code;
+        run.status = 'failed';
+      })
+      .finally(() => {
+        run.finishedAt = new Date().toISOString();
+        log.info('run finished', { id: run.id, status: run.status, exitCode: run.exitCode });
+        emit(run, { event: 'exit', data: JSON.stringify(summary(run)) });
+      });
+    return run;
+  }
+
+  function streamEvents(req: IncomingMessage, res: ServerResponse, run: RunRecord): void {
+    res.writeHead(200, { 'Content-Type': 'text/event-stream', 'Cache-Control': 'no-cache', Connection: 'keep-alive' });
+    const send = (e: RunEvent) => {
+      res.write(`event: ${e.event}\n${e.data.split('\n').map((line) => `data: ${line}`).join('\n')}\n\n`);
+      if (e.event === 'exit') res.end();
+    };
+    for (const e of run.events) send(e);
+    if (run.status !== 'running') return;
+
+    live.on(run.id, send);
+    const stop = () => live.off(run.id, send);
+    res.on('close', stop);
+    req.on('close', stop);
+  }
+
+  async function route(req: IncomingMessage, res: ServerResponse): Promise<void> {
+    const url = new URL(req.url ?? '/', 'http://localhost');
+    const path = url.pathname.replace(/\/+$/, '');
+    const method = req.method ?? 'GET';
+
+    if (method === 'GET' && path === '/v1/types') {
+      const category = url.searchParams.get('category');
+      const listed = installedTypes()
+        .filter((t) => !category || t.category === category)
+        .map((t) => ({ typePath: t.typePath, category: t.category, version: t.version, description: t.description }));
+      sendJson(res, 200, listed);
+      return;
+    }
+    if (path === '/v1/runs') {
+      if (method === 'POST') {
+        const body = await readJson(req);
+        if (typeof body.typePath !== 'string' || !body.typePath) throw new HttpError(400, 'typePath is required');
+        if (body.typePath.split('/').includes('..')) throw new HttpError(400, `Invalid type path: ${body.typePath}`);
+        const inputs = (body.inputs ?? {}) as Record<string, unknown>;
+        if (typeof inputs !== 'object' || Array.isArray(inputs)) throw new HttpError(400, 'inputs must be an object');
+        const run = startRun(body.typePath, Object.fromEntries(Object.entries(inputs).map(([k, v]) => [k, String(v)])));
+        sendJson(res, 202, summary(run));
+        return;
+      }
+      if (method === 'GET') {
+        sendJson(res, 200, [...runs.values()].reverse().map(summary));
+        return;
+      }
+    }
+    const runMatch = /^\/v1\/runs\/([^/]+)(\/events)?$/.exec(path);
+    if (method === 'GET' && runMatch) {
+      const run = runs.get(runMatch[1]);
+      if (!run) throw new HttpError(404, `No run ${runMatch[1]}`);
+      if (runMatch[2]) {
+        streamEvents(req, res, run);
+        return;
+      }
+      sendJson(res, 200, { ...summary(run), stdout: output(run, 'stdout'), stderr: output(run, 'stderr') });
+      return;
+    }
+    if (method === 'GET' && path.startsWith('/v1/history/')) {
+      const typePath = decodeURIComponent(path.slice('/v1/history/'.length));
+      if (typePath.split('/').includes('..')) throw new HttpError(400, `Invalid type path: ${typePath}`);
+      sendJson(res, 200, listHistory(typePath));
+      return;
+    }
+    throw new HttpError(404, `No route for ${method} ${url.pathname}`);
+  }
+
+  const server = createServer((req, res) => {
+    if (!authorized(req, opts.token)) {
+      sendJson(res, 401, { error: 'Missing or invalid bearer token' });
+      return;
+    }
+    route(req, res).catch((err: unknown) => {
+      const status = err instanceof HttpError ? err.status : 500;
+      if (status === 500) log.error('request failed', { url: req.url, error: String(err) });
+      const code = errorCode(err);
+      if (!res.headersSent) {
+        sendJson(res, status, { error: err instanceof Error ? err.message : String(err), ...(code ? { code } : {}) });
+      }
+      else res.end();
+    });
+  });
+  server.on('close', () => changes.close());
+  return server;
+}
+
+/** Splits "--addr" values like ":7777" or "127.0.0.1:7777"; an empty host means every interface. */
+export function parseAddr(addr: string): { host?: string; port: number } {
+  const m = /^(?:\[([^\]]+)\]|([^:]*))?:(\d+)$/.exec(addr.trim());
+  if (!m) throw new Error(`Invalid address "${addr}" (expected host:port or :port)`);
+  const port = Number(m[3]);
+  if (port > 65535) throw new Error(`Invalid port in "${addr}"`);
+  const host = m[1] ?? m[2];
+  return host ? { host, port } : { port };
+}
@@ -0,0 +1,300 @@
+import { execFileSync } from 'node:child_process';
+import { join, dirname, relative, sep, basename } from 'node:path';
+import {
+  readFileSync,
+  writeFileSync,
+  existsSync,
+  mkdirSync,
+  cpSync,
+  chmodSync,
+  rmSync,
+  openSync,
+  readSync,
+  closeSync,
+  statSync,
+} from 'node:fs';
+import { parseDocument, visit, isScalar } from 'yaml';
+import {
+  getUserdataRoot,
+  getEnvDir,
+  getProfilesDir,
+  activeProfileName,
+  listProfiles,
+  switchProfile,
+  initGlobal,
+} from './userdata.js';
+import { withTempDir, requireAge, listFiles, TOKEN_FILES } from './registry-archive.js';
+import { storedCredentialSecrets } from './credentials.js';
+import { isSensitiveKey } from '../utils/redact.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('backup');
+
+// ── Userdata backups ────────────────────────────────────────────────
+//
+// `agentx backup create` snapshots the whole userdata tree (shared env
+// files, profiles, preferences, and every skill registry with its state)
+// so it can be restored on a new machine:
+//
+//   agentx-backup.json     format, creation time, active profile
+//   userdata/...           the tree, minus run output, daemons, and archive/
+//
+// The archive is a .tar.gz encrypted with age, to recipients if given,
+// else to a passphrase age prompts for. --plaintext skips encryption but
+// then blanks every secret value: all of a token file, keys in .env and
+// YAML files that the redaction policy masks (redact.patterns included),
+// and anywhere a token or a credentials.yaml secret was copied to.
+
+const BACKUP_FORMAT = 1;
+const MANIFEST_FILE = 'agentx-backup.json';
+const TREE_DIR = 'userdata';
+// Registries doctor --fix set aside
+const ARCHIVE_DIR = 'archive';
+// Run results, and daemon pidfiles and logs, are per machine
+const REGISTRY_OUTPUT = /^skills\/.+\/(output|daemon)(\/|$)/;
+// The active link is recorded by name and recreated on restore
+const ACTIVE_LINK = 'profiles/active';
+const AGE_HEADERS = ['age-encryption.org/', '-----BEGIN AGE ENCRYPTED FILE-----'];
+const REDACTED_NOTE = '# Value removed by a plaintext agentx backup';
+const REDACTED_COMMENT = ' Value removed by a plaintext agentx backup';
+
+interface BackupManifest {
+  format: number;
+  createdAt: string;
+  activeProfile: string | null;
+  /** Secret values blanked in a plaintext backup. */
+  redacted: number;
+}
+
+export interface BackupOptions {
+  /** Write an unencrypted archive with secret values blanked. */
+  plaintext?: boolean;
+  /** age recipients (public keys); without any, age asks for a passphrase. */
+  recipients?: string[];
+}
+
+export interface BackupResult {
+  files: number;
+  encrypted: boolean;
+  redacted: number;
+}
+
+export interface RestoreOptions {
+  /** age identity file for backups encrypted to a recipient. */
+  identity?: string;
+  /** Replace files that already exist. */
+  overwrite?: boolean;
+}
+
+export interface RestoreResult {
+  files: number;
+  /** Existing files left in place, relative to userdata. */
+  skipped: string[];
+  /** Secret values the backup was made without. */
+  redacted: number;
+  activeProfile: string | null;
+}
+
+export function defaultBackupName(plaintext: boolean): string {
+  const day = new Date().toISOString().slice(0, 10);
+  return `agentx-backup-${day}.tar.gz${plaintext ? '' : '.age'}`;
+}
+
+function isSecretFile(rel: string): boolean {
+  return rel.startsWith('env/') || rel.startsWith('profiles/') || TOKEN_FILES.test(basename(rel));
+}
+
+/** Token file values and stored host credentials: secret wherever they appear. */
+function knownSecrets(tree: string): Set<string> {
+  const values = new Set(storedCredentialSecrets());
+  for (const rel of listFiles(tree).filter((f) => TOKEN_FILES.test(basename(f)))) {
+    for (const { value } of parseEnvFile(readFileSync(join(tree, rel), 'utf-8'))) {
+      if (value) values.add(value);
+    }
+  }
+  return values;
+}
+
+/** Blanks secret values in an env file; returns the new content and how many were blanked. */
+function redactEnvFile(content: string, all: boolean, known: Set<string>): { content: string; count: number } {
+  let count = 0;
+  const lines = content.split('\n').map((line) => {
+    const trimmed = line.trim();
+    const eq = trimmed.indexOf('=');
+    if (!trimmed || trimmed.startsWith('#') || eq === -1) return line;
+    const key = trimmed.slice(0, eq).trim();
+    const value = trimmed.slice(eq + 1).trim();
+    if (!value || (!all && !isSensitiveKey(key) && !known.has(value))) return line;
+    count++;
+    return `${REDACTED_NOTE}\n${key}=`;
+  });
+  return { content: lines.join('\n'), count };
+}
+
+/** Blanks scalar values under sensitive keys, or equal to a known secret, in a YAML file. */
+function redactYamlFile(content: string, known: Set<string>): { content: string; count: number } {
+  const doc = parseDocument(content);
+  if (doc.errors.length > 0) return { content, count: 0 };
+  let count = 0;
+  visit(doc, {
+    Pair(_, pair) {
+      if (!isScalar(pair.value) || pair.value.value === null || pair.value.value === '') return;
+      const key = isScalar(pair.key) ? String(pair.key.value) : '';
+      if (!isSensitiveKey(key) && !known.has(String(pair.value.value))) return;
+      pair.value.value = '';
+      pair.value.comment = REDACTED_COMMENT;
+      count++;
+    },
+    Scalar(key, node) {
+      // Sequence items have no key to judge by; only known values count
+      if (typeof key !== 'number' || typeof node.value !== 'string' || !known.has(node.value)) return;
+      node.value = '';
+      count++;
+    },
+  });
+  return { content: count > 0 ? doc.toString() : content, count };
+}
+
+function isEncrypted(path: string): boolean {
+  const fd = openSync(path, 'r');
+  try {
+    const buf = Buffer.alloc(64);
+    const head = buf.subarray(0, readSync(fd, buf, 0, buf.length, 0)).toString('utf-8');
+    return AGE_HEADERS.some((h) => head.startsWith(h));
+  } finally {
+    closeSync(fd);
+  }
+}
+
+export function createBackup(archivePath: string, opts: BackupOptions = {}): BackupResult {
+  const root = getUserdataRoot();
+  if (!existsSync(root)) {
+    throw new Error(`No userdata to back up (${root} does not exist); run agentx init first`);
+  }
+  if (!opts.plaintext) requireAge();
+
+  return withTempDir((staging) => {
+    const content = join(staging, 'content');
+    const tree = join(content, TREE_DIR);
+    cpSync(root, tree, {
+      recursive: true,
+      filter: (src) => {
+        const rel = relative(root, src).split(sep).join('/');
+        return rel !== ACTIVE_LINK && rel.split('/')[0] !== ARCHIVE_DIR && !REGISTRY_OUTPUT.test(rel);
+      },
+    });
+
+    let redacted = 0;
+    if (opts.plaintext) {
+      const known = knownSecrets(tree);
+      for (const rel of listFiles(tree)) {
+        const path = join(tree, rel);
+        let result: { content: string; count: number };
+        if (rel.endsWith('.env')) {
+          result = redactEnvFile(readFileSync(path, 'utf-8'), TOKEN_FILES.test(basename(rel)), known);
+        } else if (rel.endsWith('.yaml') || rel.endsWith('.yml')) {
+          result = redactYamlFile(readFileSync(path, 'utf-8'), known);
+        } else {
+          continue;
+        }
+        if (result.count === 0) continue;
+        writeFileSync(path, result.content);
+        redacted += result.count;
+      }
+    }
+
+    const manifest: BackupManifest = {
+      format: BACKUP_FORMAT,
+      createdAt: new Date().toISOString(),
+      activeProfile: activeProfileName(),
+      redacted,
+    };
+    writeFileSync(join(content, MANIFEST_FILE), `${JSON.stringify(manifest, null, 2)}\n`);
+
+    mkdirSync(dirname(archivePath), { recursive: true });
+    const packed = opts.plaintext ? archivePath : join(staging, 'backup.tar.gz');
+    execFileSync('tar', ['-czf', packed, '-C', content, '.'], { stdio: 'ignore' });
+    if (!opts.plaintext) {
+      const recipients = (opts.recipients ?? []).flatMap((r) => ['-r', r]);
+      const args = recipients.length > 0 ? recipients : ['-p'];
+      // Inherit stdio so age can prompt for a passphrase on the terminal
+      execFileSync('age', ['-e', ...args, '-o', archivePath, packed], { stdio: 'inherit' });
+    }
+
+    const files = listFiles(tree).length;
+    log.info('backup created', { archive: archivePath, files, encrypted: !opts.plaintext, redacted });
+    return { files, encrypted: !opts.plaintext, redacted };
+  });
+}
+
+function readManifest(dir: string): BackupManifest {
+  const path = join(dir, MANIFEST_FILE);
+  if (!existsSync(path)) {
+    throw new Error(`Not an agentx backup (missing ${MANIFEST_FILE})`);
+  }
+  const manifest = JSON.parse(readFileSync(path, 'utf-8')) as BackupManifest;
+  if (manifest.format > BACKUP_FORMAT) {
+    throw new Error(`Backup format ${manifest.format} is newer than this CLI supports; upgrade first`);
+  }
+  return manifest;
+}
+
+export function restoreBackup(archivePath: string, opts: RestoreOptions = {}): RestoreResult {
+  if (!existsSync(archivePath)) {
+    throw new Error(`Backup not found: ${archivePath}`);
+  }
+
+  return withTempDir((staging) => {
+    let packed = archivePath;
+    if (isEncrypted(archivePath)) {
+      requireAge();
+      packed = join(staging, 'backup.tar.gz');
+      const identity = opts.identity ? ['-i', opts.identity] : [];
+      execFileSync('age', ['-d', ...identity, '-o', packed, archivePath], { stdio: 'inherit' });
+    }
+    const content = join(staging, 'content');
+    mkdirSync(content);
+    execFileSync('tar', ['-xzf', packed, '-C', content], { stdio: 'ignore' });
+    const manifest = readManifest(content);
+
+    const root = getUserdataRoot();
+    const hadActive = activeProfileName() !== null;
+    const skipped: string[] = [];
+    // env/ and profiles/ are owner-only before anything is written into them
+    for (const dir of [getEnvDir(), getProfilesDir()]) {
+      mkdirSync(dir, { recursive: true, mode: 0o700 });
+      chmodSync(dir, 0o700);
+    }
+    const files = listFiles(join(content, TREE_DIR));
+    for (const rel of files) {
+      const target = join(root, rel);
+      if (existsSync(target) && !opts.overwrite) {
+        skipped.push(rel);
+        continue;
+      }
+      mkdirSync(dirname(target), { recursive: true });
+      // An existing file keeps its mode when rewritten, so replace it
+      rmSync(target, { force: true });
+      const source = join(content, TREE_DIR, rel);
+      writeFileSync(target, readFileSync(source), {
+        mode: isSecretFile(rel) ? 0o600 : statSync(source).mode & 0o777,
+      });
+    }
+    // Fills in anything the backup lacked
+    initGlobal(() => {});
+
+    const active = manifest.activeProfile;
+    if (active && listProfiles().includes(active) && (!hadActive || opts.overwrite)) {
+      switchProfile(active);
+    }
+
+    log.info('backup restored', { archive: archivePath, files: files.length - skipped.length, skipped: skipped.length });
+    return {
+      files: files.length - skipped.length,
+      skipped,
+      redacted: manifest.redacted,
+      activeProfile: activeProfileName(),
+    };
+  });
+}
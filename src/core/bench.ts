@@ -0,0 +1,223 @@
+import { spawn } from 'node:child_process';
+import type { SkillManifest } from '../types/manifest.js';
+import { skillCommand, skillTimeoutMs, TIMEOUT_EXIT_CODE, type SkillCommand } from './runtime.js';
+import { currentVersion } from './updater.js';
+
+// ── Skill benchmarks ────────────────────────────────────────────────
+//
+// `agentx bench <skill>` runs a skill the way the runtime does (same
+// command line, limits, and isolated environment) a number of times and
+// reports wall time, CPU time, and output size as percentiles. Runs skip
+// what `run` adds around the process (telemetry, output history), which
+// would only add noise. A run that exceeds limits.timeout is killed and
+// counts as failed, with the runtime's timeout exit code. A saved JSON report can be passed back as a
+// baseline to compare another version against.
+//
+// CPU time is the user + system time of the skill and everything it
+// waited for, read from the `times` builtin of a wrapping shell. Windows
+// has no such shell, so cpuMs is null there.
+
+export interface BenchRun {
+  exitCode: number;
+  /** Killed for exceeding limits.timeout. */
+  timedOut?: boolean;
+  wallMs: number;
+  /** null where CPU time can't be measured. */
+  cpuMs: number | null;
+  /** stdout and stderr, in bytes. */
+  outputBytes: number;
+}
+
+export interface BenchStats {
+  min: number;
+  mean: number;
+  p50: number;
+  p90: number;
+  p95: number;
+  p99: number;
+  max: number;
+}
+
+export interface BenchReport {
+  skill: string;
+  version: string;
+  runtime: string;
+  iterations: number;
+  warmup: number;
+  /** Input names only; values may be secrets. */
+  inputs: string[];
+  /** Runs that exited non-zero. */
+  failed: number;
+  wallMs: BenchStats;
+  cpuMs: BenchStats | null;
+  outputBytes: BenchStats;
+  runs: BenchRun[];
+  platform: string;
+  cliVersion: string;
+  at: string;
+}
+
+export interface BenchOptions {
+  iterations: number;
+  /** Runs before the measured ones, to warm caches; not reported. */
+  warmup?: number;
+  /** Called after each measured run. */
+  onRun?: (run: BenchRun, index: number) => void;
+}
+
+export type BenchMetric = 'wallMs' | 'cpuMs' | 'outputBytes';
+
+export interface BenchDelta {
+  metric: BenchMetric;
+  stat: 'p50' | 'p95';
+  baseline: number;
+  current: number;
+  /** Relative change, e.g. 0.25 for 25% more. */
+  change: number;
+}
+
+// ── Statistics ──────────────────────────────────────────────────────
+
+/** Nearest-rank percentile of sorted values (p in 0-100). */
+export function percentile(sorted: number[], p: number): number {
+  if (sorted.length === 0) return 0;
+  const rank = Math.ceil((p / 100) * sorted.length);
+  return sorted[Math.min(sorted.length, Math.max(1, rank)) - 1];
+}
+
+export function summarize(values: number[]): BenchStats {
+  const sorted = [...values].sort((a, b) => a - b);
+  const mean = sorted.length ? sorted.reduce((n, v) => n + v, 0) / sorted.length : 0;
+  const round = (v: number) => Math.round(v * 100) / 100;
+  return {
+    min: sorted[0] ?? 0,
+    mean: round(mean),
+    p50: percentile(sorted, 50),
+    p90: percentile(sorted, 90),
+    p95: percentile(sorted, 95),
+    p99: percentile(sorted, 99),
+    max: sorted[sorted.length - 1] ?? 0,
+  };
+}
+
+/** p50 and p95 of each metric against a baseline report. */
+export function compareBench(baseline: BenchReport, current: BenchReport): BenchDelta[] {
+  const deltas: BenchDelta[] = [];
+  for (const metric of ['wallMs', 'cpuMs', 'outputBytes'] as const) {
+    const before = baseline[metric];
+    const after = current[metric];
+    if (!before || !after) continue;
+    for (const stat of ['p50', 'p95'] as const) {
+      const change = before[stat] === 0 ? (after[stat] === 0 ? 0 : 1) : (after[stat] - before[stat]) / before[stat];
+      deltas.push({ metric, stat, baseline: before[stat], current: after[stat], change: Math.round(change * 1000) / 1000 });
+    }
+  }
+  return deltas;
+}
+
+// ── Running ─────────────────────────────────────────────────────────
+
+/** Children's user + system time from `times` output, in ms. */
+export function parseTimes(output: string): number | null {
+  // The second line is the children's: "0m0.120s 0m0.030s"
+  const line = output.trim().split('\n')[1];
+  const match = line && /^(\d+)m([\d.]+)s\s+(\d+)m([\d.]+)s$/.exec(line.trim());
+  if (!match) return null;
+  const seconds = Number(match[1]) * 60 + Number(match[2]) + Number(match[3]) * 60 + Number(match[4]);
+  return Math.round(seconds * 1000);
+}
+
+function measure(proc: SkillCommand, timeoutMs: number): Promise<BenchRun> {
+  const timed = process.platform !== 'win32';
+  // fd 3 carries the `times` report, so the skill's own output is untouched
+  const [command, argv] = timed
+    ? ['/bin/sh', ['-c', '"$0" "$@" 3>&-; status=$?; times >&3; exit $status', proc.command, ...proc.argv]]
+    : [proc.command, proc.argv];
+
+  return new Promise((resolve, reject) => {
+    const started = process.hrtime.bigint();
+    const child = spawn(command, argv, {
+      env: proc.env,
+      stdio: timed ? ['ignore', 'pipe', 'pipe', 'pipe'] : ['ignore', 'pipe', 'pipe'],
+      // Its own process group, so a timeout kills the skill, not just the shell
+      detached: timed,
+    });
+    let timedOut = false;
+    const timer = timeoutMs
+      ? setTimeout(() => {
+          timedOut = true;
+          try {
+            if (timed && child.pid) process.kill(-child.pid, 'SIGKILL');
+            else child.kill('SIGKILL');
+          } catch {
+            // Already gone
+          }
+        }, timeoutMs)
+      : undefined;
+    let outputBytes = 0;
+    let times = '';
+    child.stdout!.on('data', (data: Buffer) => (outputBytes += data.length));
+    child.stderr!.on('data', (data: Buffer) => (outputBytes += data.length));
+    if (timed) (child.stdio[3] as NodeJS.ReadableStream).on('data', (data: Buffer) => (times += data.toString()));
+
+    child.on('error', (err) => {
+      clearTimeout(timer);
+      reject(err);
+    });
+    child.on('close', (code) => {
+      clearTimeout(timer);
+      const wallMs = Number(process.hrtime.bigint() - started) / 1e6;
+      resolve({
+        exitCode: timedOut ? TIMEOUT_EXIT_CODE : (code ?? 1),
+        ...(timedOut ? { timedOut } : {}),
+        wallMs: Math.round(wallMs * 100) / 100,
+        cpuMs: timed ? parseTimes(times) : null,
+        outputBytes,
+      });
+    });
+  });
+}
+
+export async function benchSkill(
+  skill: string,
+  skillDir: string,
+  manifest: SkillManifest,
+  inputs: Record<string, string>,
+  opts: BenchOptions,
+): Promise<BenchReport> {
+  if (!Number.isInteger(opts.iterations) || opts.iterations < 1) {
+    throw new Error(`iterations must be a positive integer, got ${opts.iterations}`);
+  }
+  const warmup = opts.warmup ?? 0;
+  if (!Number.isInteger(warmup) || warmup < 0) {
+    throw new Error(`warmup must be a non-negative integer, got ${warmup}`);
+  }
+  const proc = skillCommand(skillDir, manifest, inputs);
+  const timeoutMs = skillTimeoutMs(manifest);
+
+  for (let i = 0; i < warmup; i++) await measure(proc, timeoutMs);
+  const runs: BenchRun[] = [];
+  for (let i = 0; i < opts.iterations; i++) {
+    const run = await measure(proc, timeoutMs);
+    runs.push(run);
+    opts.onRun?.(run, i);
+  }
+
+  const cpu = runs.map((r) => r.cpuMs);
+  return {
+    skill,
+    version: manifest.version,
+    runtime: manifest.runtime,
+    iterations: opts.iterations,
+    warmup,
+    inputs: Object.keys(inputs).sort(),
+    failed: runs.filter((r) => r.exitCode !== 0).length,
+    wallMs: summarize(runs.map((r) => r.wallMs)),
+    cpuMs: cpu.every((c) => c !== null) ? summarize(cpu as number[]) : null,
+    outputBytes: summarize(runs.map((r) => r.outputBytes)),
+    runs,
+    platform: `${process.platform}-${process.arch}`,
+    cliVersion: currentVersion(),
+    at: new Date().toISOString(),
+  };
+}
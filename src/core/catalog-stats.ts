@@ -0,0 +1,120 @@
+import { readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { DiscoveredType, Source } from '../types/registry.js';
+import { discoverAll, extractDependencies } from './registry.js';
+import { loadContext } from './compose.js';
+import { classify } from './registry-index.js';
+
+// ── Catalog statistics ──────────────────────────────────────────────
+//
+// `agentx catalog stats` summarizes the types in the catalog and
+// extensions for whoever curates them: how many there are per category,
+// topic, and vendor, and which need attention. A type's topic and vendor
+// come from its manifest, else from its path by convention
+// (skills/<topic>/<vendor>/<name>). An orphan is a persona, context,
+// skill, or workflow that no prompt reaches, directly or through
+// another type.
+
+export interface ContextTokens {
+  typePath: string;
+  tokens: number;
+}
+
+export interface CatalogStats {
+  sources: string[];
+  total: number;
+  byCategory: Record<string, number>;
+  byTopic: Record<string, number>;
+  byVendor: Record<string, number>;
+  missingDescription: string[];
+  missingTags: string[];
+  skillsWithoutTests: string[];
+  /** Context types by composed size, largest first. */
+  contextTokens: ContextTokens[];
+  totalContextTokens: number;
+  orphans: string[];
+}
+
+const ORPHAN_CATEGORIES = new Set(['persona', 'context', 'skill', 'workflow']);
+
+function readManifest(t: DiscoveredType): Record<string, unknown> {
+  try {
+    return (yaml.load(readFileSync(t.manifestPath, 'utf-8')) as Record<string, unknown>) ?? {};
+  } catch {
+    return {};
+  }
+}
+
+function classifyType(t: DiscoveredType, data: Record<string, unknown>, key: 'topic' | 'vendor', index: number): string {
+  return classify(t.typePath, data[key], index) ?? '(none)';
+}
+
+function tally(values: string[]): Record<string, number> {
+  const counts: Record<string, number> = {};
+  for (const v of [...values].sort()) counts[v] = (counts[v] ?? 0) + 1;
+  return counts;
+}
+
+/** Two sources can provide the same type path, each with its own manifest. */
+function typeKey(t: DiscoveredType): string {
+  return `${t.sourceName}\0${t.typePath}`;
+}
+
+/** A context's composed size; unreadable ones are reported and count as 0. */
+function contextSize(t: DiscoveredType, warnings?: string[]): number {
+  try {
+    const loaded = loadContext(t.typePath, '', t.sourceDir);
+    warnings?.push(...loaded.warnings.map((w) => `${t.sourceName}: ${w}`));
+    return loaded.sections.reduce((n, s) => n + s.tokens, 0);
+  } catch (err) {
+    warnings?.push(`${t.sourceName}: ${t.typePath}: ${err instanceof Error ? err.message : String(err)}`);
+    return 0;
+  }
+}
+
+/** Every type a prompt reaches, following dependencies transitively. */
+function reachedFromPrompts(types: DiscoveredType[]): Set<string> {
+  const byPath = new Map(types.map((t) => [t.typePath, t]));
+  const reached = new Set<string>();
+  const queue = types.filter((t) => t.category === 'prompt').map((t) => t.typePath);
+  while (queue.length > 0) {
+    const typePath = queue.pop()!;
+    const t = byPath.get(typePath);
+    if (!t) continue;
+    for (const dep of extractDependencies(t.manifestPath)) {
+      if (reached.has(dep)) continue;
+      reached.add(dep);
+      queue.push(dep);
+    }
+  }
+  return reached;
+}
+
+export function catalogStats(sources: Source[], warnings?: string[]): CatalogStats {
+  const types = discoverAll(sources, warnings).sort((a, b) => a.typePath.localeCompare(b.typePath));
+  const manifests = new Map(types.map((t) => [typeKey(t), readManifest(t)]));
+  const data = (t: DiscoveredType) => manifests.get(typeKey(t))!;
+
+  const contextTokens = types
+    .filter((t) => t.category === 'context')
+    .map((t) => ({ typePath: t.typePath, tokens: contextSize(t, warnings) }))
+    .sort((a, b) => b.tokens - a.tokens || a.typePath.localeCompare(b.typePath));
+
+  const reached = reachedFromPrompts(types);
+
+  return {
+    sources: sources.map((s) => s.name),
+    total: types.length,
+    byCategory: tally(types.map((t) => t.category)),
+    byTopic: tally(types.map((t) => classifyType(t, data(t), 'topic', 1))),
+    byVendor: tally(types.map((t) => classifyType(t, data(t), 'vendor', 2))),
+    missingDescription: types.filter((t) => !t.description.trim()).map((t) => t.typePath),
+    missingTags: types.filter((t) => t.tags.length === 0).map((t) => t.typePath),
+    skillsWithoutTests: types
+      .filter((t) => t.category === 'skill' && !(Array.isArray(data(t).tests) && (data(t).tests as unknown[]).length > 0))
+      .map((t) => t.typePath),
+    contextTokens,
+    totalContextTokens: contextTokens.reduce((n, c) => n + c.tokens, 0),
+    orphans: types.filter((t) => ORPHAN_CATEGORIES.has(t.category) && !reached.has(t.typePath)).map((t) => t.typePath),
+  };
+}
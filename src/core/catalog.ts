@@ -1,18 +1,26 @@
 import { existsSync, writeFileSync, readFileSync, renameSync, rmSync } from 'node:fs';
 import { simpleGit } from 'simple-git';
+import { join } from 'node:path';
 import { CATALOG_REPO_URL, envVar } from '../config/branding.js';
 import * as settings from '../config/settings.js';
+import { hasGit } from '../utils/git.js';
+import { downloadArchive } from '../utils/archive.js';
+import { authorizationFor } from './credentials.js';
+import { mirrorUrl, bundleUrl, fetchBundle } from './mirror.js';
+import { requireOnline } from './offline.js';
 
 const FRESHNESS_FILE = '.catalog-updated';
 const DEFAULT_MAX_AGE_MS = 7 * 24 * 60 * 60 * 1000; // 7 days
+const ARCHIVE_REF = 'main';
 
 export function repoURL(): string {
   return process.env[envVar('CATALOG_URL')]
-    ?? settings.get('catalog_url')
-    ?? CATALOG_REPO_URL;
+    || settings.get('catalog_url')
+    || CATALOG_REPO_URL;
 }
 
 export async function clone(targetDir: string): Promise<void> {
+  requireOnline('Fetching the catalog');
   const url = repoURL();
   const tmpDir = targetDir + '.tmp';
 
@@ -21,6 +29,21 @@ export async function clone(targetDir: string): Promise<void> {
     rmSync(tmpDir, { recursive: true });
   }
 
+  const mirror = mirrorUrl();
+  if (mirror) {
+    // Behind a mirror the origin may be unreachable; take its bundle
+    await fetchBundle(bundleUrl(mirror, 'catalog', ARCHIVE_REF), tmpDir);
+    swapInto(tmpDir, targetDir);
+    return;
+  }
+
+  if (!hasGit()) {
+    // No git: fetch a snapshot instead. `catalog update` re-downloads it.
+    await downloadArchive(url, ARCHIVE_REF, tmpDir, authorizationFor(url));
+    swapInto(tmpDir, targetDir);
+    return;
+  }
+
   const git = simpleGit();
 
   // Try sparse checkout first (git >= 2.25.0)
@@ -40,6 +63,10 @@ export async function clone(targetDir: string): Promise<void> {
     await git.clone(url, tmpDir, ['--depth', '1']);
   }
 
+  swapInto(tmpDir, targetDir);
+}
+
+function swapInto(tmpDir: string, targetDir: string): void {
   // Atomic rename
   if (existsSync(targetDir)) {
     rmSync(targetDir, { recursive: true });
@@ -49,7 +76,9 @@ export async function clone(targetDir: string): Promise<void> {
 }
 
 export async function update(catalogRepoDir: string): Promise<void> {
-  if (!existsSync(catalogRepoDir)) {
+  requireOnline('Updating the catalog');
+  // Snapshot installs (no .git), mirrors, and machines without git re-download
+  if (!existsSync(join(catalogRepoDir, '.git')) || !hasGit() || mirrorUrl()) {
     await clone(catalogRepoDir);
     return;
   }
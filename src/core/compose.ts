@@ -1,10 +1,18 @@
 import { join } from 'node:path';
 import { readFileSync, existsSync } from 'node:fs';
 import yaml from 'js-yaml';
-import type { PromptManifest, PersonaManifest, ContextManifest } from '../types/manifest.js';
+import type { PromptManifest, PersonaManifest, ContextManifest, TemplateVariable } from '../types/manifest.js';
+import { countTokens, truncateToTokens } from './tokens.js';
+import { renderFile, createEngine } from './template.js';
+import { expandSources } from './context-sources.js';
+import { getSkillRegistryPath } from './userdata.js';
+import { nameFromPath } from './registry.js';
+import type { Source } from '../types/registry.js';
+import type { TypeDirs } from './versions.js';
 
 export interface PersonaSection {
   name: string;
+  description: string;
   expertise: string[];
   tone: string;
   conventions: string[];
@@ -12,28 +20,58 @@ export interface PersonaSection {
 
 export interface ContextSection {
   name: string;
+  source: string;
   content: string;
+  tokens: number;
 }
 
 export interface SkillRef {
   name: string;
+  path: string;
   description: string;
+  output: unknown;
 }
 
 export interface WorkflowRef {
   name: string;
+  path: string;
   description: string;
 }
 
+export interface TokenBudget {
+  persona: number;
+  context: number;
+  skills: number;
+  workflows: number;
+  total: number;
+  max: number | null;
+}
+
 export interface ComposedPrompt {
   promptName: string;
   persona: PersonaSection | null;
   context: ContextSection[];
   skills: SkillRef[];
   workflows: WorkflowRef[];
+  template: string | null;
+  /** Variables the prompt declares. */
+  variables: TemplateVariable[];
+  /** Their values once applyVariables has run. */
+  values: Record<string, string>;
+  tokens: TokenBudget;
   warnings: string[];
 }
 
+export interface RenderOptions {
+  format?: string;
+  budget?: boolean;
+  builtin?: boolean;
+  /** Sources providing templates/_partials/ for custom prompt templates. */
+  partialSources?: Source[];
+}
+
+export type Renderer = (cp: ComposedPrompt, opts: RenderOptions) => string;
+
 function findManifest(dir: string): string | null {
   for (const name of ['manifest.yaml', 'manifest.json']) {
     const path = join(dir, name);
@@ -49,11 +87,11 @@ function formatContextName(name: string): string {
     .join(' ');
 }
 
-function loadPersona(
+export function loadPersona(
   personaPath: string,
   installedRoot: string,
+  dir = join(installedRoot, personaPath),
 ): { section: PersonaSection | null; warnings: string[] } {
-  const dir = join(installedRoot, personaPath);
   const manifestPath = findManifest(dir);
   if (!manifestPath) {
     return { section: null, warnings: [`Persona not found: ${personaPath}`] };
@@ -65,6 +103,7 @@ function loadPersona(
     return {
       section: {
         name: data.name,
+        description: data.description ?? '',
         expertise: data.expertise ?? [],
         tone: data.tone ?? '',
         conventions: data.conventions ?? [],
@@ -76,11 +115,12 @@ function loadPersona(
   }
 }
 
-function loadContext(
+/** A context's sections; dir replaces the installed copy (e.g. a project overlay). */
+export function loadContext(
   ctxPath: string,
   installedRoot: string,
+  dir = join(installedRoot, ctxPath),
 ): { sections: ContextSection[]; warnings: string[] } {
-  const dir = join(installedRoot, ctxPath);
   const manifestPath = findManifest(dir);
   if (!manifestPath) {
     return { sections: [], warnings: [`Context not found: ${ctxPath}`] };
@@ -91,26 +131,50 @@ function loadContext(
     const data = yaml.load(raw) as ContextManifest;
     const sections: ContextSection[] = [];
 
-    for (const source of data.sources) {
-      const filePath = join(dir, source);
+    const expanded = expandSources(dir, data.sources);
+    const warnings = [...expanded.warnings];
+    const name = formatContextName(data.name);
+
+    for (const file of expanded.files) {
+      let content: string;
       try {
-        const content = readFileSync(filePath, 'utf-8');
-        sections.push({ name: formatContextName(data.name), content });
+        content = readFileSync(file.path, 'utf-8');
       } catch {
-        // Skip missing source files
+        if (file.remote) {
+          warnings.push(`Remote context source not fetched yet: ${file.source}`);
+        }
+        continue; // Skip missing source files
       }
+      sections.push({
+        name,
+        source: file.source,
+        content,
+        tokens: countTokens(renderContext({ name, source: file.source, content, tokens: 0 })),
+      });
     }
-    return { sections, warnings: [] };
+    return { sections, warnings };
   } catch {
     return { sections: [], warnings: [`Failed to parse context: ${ctxPath}`] };
   }
 }
 
+// The latest output a skill saved to its registry, exposed to prompt
+// templates as skills.<name>.output. Missing or unparseable output is
+// normal (the skill simply hasn't run yet) and yields null.
+function loadSkillOutput(skillPath: string): unknown {
+  const outputPath = join(getSkillRegistryPath(nameFromPath(skillPath)), 'output', 'latest.json');
+  try {
+    return JSON.parse(readFileSync(outputPath, 'utf-8'));
+  } catch {
+    return null;
+  }
+}
+
 function loadSkillRef(
   skillPath: string,
   installedRoot: string,
+  dir = join(installedRoot, skillPath),
 ): { ref: SkillRef | null; warnings: string[] } {
-  const dir = join(installedRoot, skillPath);
   const manifestPath = findManifest(dir);
   if (!manifestPath) {
     return { ref: null, warnings: [`Skill not found: ${skillPath}`] };
@@ -118,7 +182,15 @@ function loadSkillRef(
   try {
     const raw = readFileSync(manifestPath, 'utf-8');
     const data = yaml.load(raw) as { name: string; description: string };
-    return { ref: { name: data.name, description: data.description }, warnings: [] };
+    return {
+      ref: {
+        name: data.name,
+        path: skillPath,
+        description: data.description,
+        output: loadSkillOutput(skillPath),
+      },
+      warnings: [],
+    };
   } catch {
     return { ref: null, warnings: [`Failed to parse skill: ${skillPath}`] };
   }
@@ -127,8 +199,8 @@ function loadSkillRef(
 function loadWorkflowRef(
   wfPath: string,
   installedRoot: string,
+  dir = join(installedRoot, wfPath),
 ): { ref: WorkflowRef | null; warnings: string[] } {
-  const dir = join(installedRoot, wfPath);
   const manifestPath = findManifest(dir);
   if (!manifestPath) {
     return { ref: null, warnings: [`Workflow not found: ${wfPath}`] };
@@ -136,17 +208,22 @@ function loadWorkflowRef(
   try {
     const raw = readFileSync(manifestPath, 'utf-8');
     const data = yaml.load(raw) as { name: string; description: string };
-    return { ref: { name: data.name, description: data.description }, warnings: [] };
+    return {
+      ref: { name: data.name, path: wfPath, description: data.description },
+      warnings: [],
+    };
   } catch {
     return { ref: null, warnings: [`Failed to parse workflow: ${wfPath}`] };
   }
 }
 
+/** typeDirs selects pinned side-by-side versions (core/versions.ts). */
 export function compose(
   promptPath: string,
   installedRoot: string,
+  typeDirs: TypeDirs = {},
 ): ComposedPrompt {
-  const dir = join(installedRoot, promptPath);
+  const dir = typeDirs[promptPath] ?? join(installedRoot, promptPath);
   const manifestPath = findManifest(dir);
   if (!manifestPath) {
     throw new Error(`Prompt not found: ${promptPath}`);
@@ -158,7 +235,7 @@ export function compose(
 
   let persona: PersonaSection | null = null;
   if (data.persona) {
-    const res = loadPersona(data.persona, installedRoot);
+    const res = loadPersona(data.persona, installedRoot, typeDirs[data.persona]);
     persona = res.section;
     warnings.push(...res.warnings);
   }
@@ -166,7 +243,7 @@ export function compose(
   const context: ContextSection[] = [];
   if (data.context) {
     for (const ctxPath of data.context) {
-      const res = loadContext(ctxPath, installedRoot);
+      const res = loadContext(ctxPath, installedRoot, typeDirs[ctxPath]);
       context.push(...res.sections);
       warnings.push(...res.warnings);
     }
@@ -175,7 +252,7 @@ export function compose(
   const skills: SkillRef[] = [];
   if (data.skills) {
     for (const skillPath of data.skills) {
-      const res = loadSkillRef(skillPath, installedRoot);
+      const res = loadSkillRef(skillPath, installedRoot, typeDirs[skillPath]);
       if (res.ref) skills.push(res.ref);
       warnings.push(...res.warnings);
     }
@@ -184,63 +261,368 @@ export function compose(
   const workflows: WorkflowRef[] = [];
   if (data.workflows) {
     for (const wfPath of data.workflows) {
-      const res = loadWorkflowRef(wfPath, installedRoot);
+      const res = loadWorkflowRef(wfPath, installedRoot, typeDirs[wfPath]);
       if (res.ref) workflows.push(res.ref);
       warnings.push(...res.warnings);
     }
   }
 
-  return {
+  let template: string | null = null;
+  if (data.template) {
+    const templatePath = join(dir, data.template);
+    if (existsSync(templatePath)) {
+      template = templatePath;
+    } else {
+      warnings.push(`Prompt template not found: ${data.template} — using built-in layout`);
+    }
+  }
+
+  const composed: ComposedPrompt = {
     promptName: data.name,
     persona,
     context,
     skills,
     workflows,
+    template,
+    variables: data.variables ?? [],
+    values: {},
+    tokens: { persona: 0, context: 0, skills: 0, workflows: 0, total: 0, max: null },
     warnings,
   };
+  composed.tokens = tallyTokens(composed, null);
+  return composed;
 }
 
-export function render(cp: ComposedPrompt): string {
-  const parts: string[] = [];
+// ── Variables ───────────────────────────────────────────────────────
+//
+// A prompt declares variables like templates do:
+//
+//   variables:
+//     - name: pr_number
+//       required: true
+//     - name: service_name
+//       default: api
+//
+// Values come from --var flags, then project.yaml `variables:`, then the
+// declared default; the command asks for any required one still missing.
+// {{pr_number}} is replaced in the persona, context, and descriptions,
+// and templates also see the values as {{pr_number}} and {{vars.pr_number}}.
+// Only declared names are replaced, so other {{...}} in context (say,
+// Go or Jinja templates) is left as is.
 
-  if (cp.persona) {
-    parts.push(`# Persona: ${cp.persona.name}`);
-    if (cp.persona.expertise.length) {
-      parts.push(`\n**Expertise:** ${cp.persona.expertise.join(', ')}`);
+export interface VariableSources {
+  flags?: Record<string, string>;
+  project?: Record<string, unknown>;
+}
+
+/** Resolved values, and required variables nothing gave a value. */
+export function resolveVariables(
+  cp: ComposedPrompt,
+  sources: VariableSources,
+): { values: Record<string, string>; missing: TemplateVariable[] } {
+  const declared = new Set(cp.variables.map((v) => v.name));
+  const unknown = Object.keys(sources.flags ?? {}).filter((k) => !declared.has(k));
+  if (unknown.length > 0) {
+    const known = [...declared].join(', ') || 'none';
+    throw new Error(`Prompt ${cp.promptName} has no variable(s): ${unknown.join(', ')} (its variables: ${known})`);
+  }
+
+  const values: Record<string, string> = {};
+  const missing: TemplateVariable[] = [];
+  for (const v of cp.variables) {
+    const project = sources.project?.[v.name];
+    const value = sources.flags?.[v.name] ?? (project !== undefined && project !== null ? String(project) : v.default);
+    if (value !== undefined) values[v.name] = value;
+    else if (v.required) missing.push(v);
+  }
+  return { values, missing };
+}
+
+function substitute(text: string, values: Record<string, string>): string {
+  return text.replace(/\{\{\s*([\w.-]+)\s*\}\}/g, (match, name: string) =>
+    Object.prototype.hasOwnProperty.call(values, name) ? values[name] : match,
+  );
+}
+
+/** Fills variable values into the prompt's text and recounts its tokens. */
+export function applyVariables(cp: ComposedPrompt, values: Record<string, string>): ComposedPrompt {
+  const sub = (text: string) => substitute(text, values);
+  const persona = cp.persona && {
+    ...cp.persona,
+    description: sub(cp.persona.description),
+    tone: sub(cp.persona.tone),
+    conventions: cp.persona.conventions.map(sub),
+  };
+  const context = cp.context.map((c) => {
+    const section = { ...c, content: sub(c.content) };
+    return { ...section, tokens: countTokens(renderContext(section)) };
+  });
+  const applied: ComposedPrompt = {
+    ...cp,
+    persona,
+    context,
+    skills: cp.skills.map((s) => ({ ...s, description: sub(s.description ?? '') })),
+    workflows: cp.workflows.map((w) => ({ ...w, description: sub(w.description ?? '') })),
+    values,
+  };
+  applied.tokens = tallyTokens(applied, cp.tokens.max);
+  return applied;
+}
+
+// ── Token budget ────────────────────────────────────────────────────
+
+function tallyTokens(cp: ComposedPrompt, max: number | null): TokenBudget {
+  const persona = cp.persona ? countTokens(renderPersona(cp.persona)) : 0;
+  const context = cp.context.reduce((sum, c) => sum + c.tokens, 0);
+  const skills = cp.skills.length ? countTokens(renderSkills(cp.skills)) : 0;
+  const workflows = cp.workflows.length ? countTokens(renderWorkflows(cp.workflows)) : 0;
+  return {
+    persona,
+    context,
+    skills,
+    workflows,
+    total: persona + context + skills + workflows,
+    max,
+  };
+}
+
+/**
+ * Fits a composed prompt into maxTokens. Context sections are the only
+ * elastic part of a prompt: they are trimmed from the end of the list
+ * (lowest priority first), truncating the last survivor when a partial
+ * section still fits. Every truncation or drop is reported as a warning.
+ */
+export function applyBudget(cp: ComposedPrompt, maxTokens: number): ComposedPrompt {
+  const fixed = cp.tokens.persona + cp.tokens.skills + cp.tokens.workflows;
+  const warnings = [...cp.warnings];
+
+  if (fixed > maxTokens) {
+    warnings.push(
+      `Persona, skills, and workflows alone use ${fixed} tokens, exceeding the ${maxTokens} token budget`,
+    );
+  }
+
+  let remaining = Math.max(0, maxTokens - fixed);
+  const context: ContextSection[] = [];
+
+  for (const section of cp.context) {
+    if (section.tokens <= remaining) {
+      context.push(section);
+      remaining -= section.tokens;
+      continue;
     }
-    if (cp.persona.tone) {
-      parts.push(`**Tone:** ${cp.persona.tone}`);
+
+    const header = countTokens(renderContext({ ...section, content: '' }));
+    if (remaining > header) {
+      const content = truncateToTokens(section.content, remaining - header);
+      const truncated = { ...section, content };
+      truncated.tokens = countTokens(renderContext(truncated));
+      context.push(truncated);
+      remaining = Math.max(0, remaining - truncated.tokens);
+      warnings.push(
+        `Truncated context "${section.name}" (${section.source}) from ${section.tokens} to ${truncated.tokens} tokens to fit budget`,
+      );
+    } else {
+      warnings.push(
+        `Dropped context "${section.name}" (${section.source}, ${section.tokens} tokens) to fit budget`,
+      );
     }
-    if (cp.persona.conventions.length) {
-      parts.push(`\n**Conventions:**`);
-      for (const c of cp.persona.conventions) {
-        parts.push(`- ${c}`);
-      }
+  }
+
+  const budgeted: ComposedPrompt = { ...cp, context, warnings };
+  budgeted.tokens = tallyTokens(budgeted, maxTokens);
+  return budgeted;
+}
+
+export function renderBudget(cp: ComposedPrompt): string {
+  const t = cp.tokens;
+  const lines = ['## Token Budget\n'];
+  lines.push(`| Section | Tokens |`);
+  lines.push(`|---------|--------|`);
+  if (cp.persona) lines.push(`| Persona | ${t.persona} |`);
+  for (const ctx of cp.context) {
+    lines.push(`| Context: ${ctx.name} (${ctx.source}) | ${ctx.tokens} |`);
+  }
+  if (cp.skills.length) lines.push(`| Skills | ${t.skills} |`);
+  if (cp.workflows.length) lines.push(`| Workflows | ${t.workflows} |`);
+  lines.push(`| **Total** | **${t.total}**${t.max != null ? ` / ${t.max}` : ''} |`);
+  lines.push('');
+  return lines.join('\n');
+}
+
+// ── Rendering ───────────────────────────────────────────────────────
+
+function renderPersona(persona: PersonaSection): string {
+  const parts: string[] = [];
+  parts.push(`# Persona: ${persona.name}`);
+  if (persona.expertise.length) {
+    parts.push(`\n**Expertise:** ${persona.expertise.join(', ')}`);
+  }
+  if (persona.tone) {
+    parts.push(`**Tone:** ${persona.tone}`);
+  }
+  if (persona.conventions.length) {
+    parts.push(`\n**Conventions:**`);
+    for (const c of persona.conventions) {
+      parts.push(`- ${c}`);
     }
-    parts.push('');
+  }
+  parts.push('');
+  return parts.join('\n');
+}
+
+function renderContext(ctx: ContextSection): string {
+  return [`## Context: ${ctx.name}\n`, ctx.content, ''].join('\n');
+}
+
+function renderSkills(skills: SkillRef[]): string {
+  const parts = ['## Available Skills\n'];
+  for (const s of skills) {
+    parts.push(`- **${s.name}**: ${s.description}`);
+  }
+  parts.push('');
+  return parts.join('\n');
+}
+
+function renderWorkflows(workflows: WorkflowRef[]): string {
+  const parts = ['## Available Workflows\n'];
+  for (const w of workflows) {
+    parts.push(`- **${w.name}**: ${w.description}`);
+  }
+  parts.push('');
+  return parts.join('\n');
+}
+
+function keyByName<T extends { name: string }>(items: T[]): Record<string, T> {
+  const map: Record<string, T> = {};
+  for (const item of items) map[item.name] = item;
+  return map;
+}
+
+/**
+ * The data model handed to prompt .hbs templates. Skills and workflows are
+ * keyed by name so templates can address them directly
+ * (e.g. {{#if skills.commit-analyzer}}) while {{#each}} still iterates them.
+ * Variable values sit at the top level and under vars.
+ */
+export function templateData(cp: ComposedPrompt): Record<string, unknown> {
+  return {
+    ...cp.values,
+    vars: cp.values,
+    promptName: cp.promptName,
+    persona: cp.persona,
+    context: cp.context,
+    skills: keyByName(cp.skills),
+    workflows: keyByName(cp.workflows),
+    tokens: cp.tokens,
+  };
+}
+
+function renderMarkdown(cp: ComposedPrompt, opts: RenderOptions): string {
+  if (cp.template && !opts.builtin) {
+    const engine = createEngine({ partialSources: opts.partialSources });
+    let output = renderFile(cp.template, templateData(cp), engine);
+    if (opts.budget) output += '\n' + renderBudget(cp);
+    return output;
+  }
+
+  const parts: string[] = [];
+
+  if (cp.persona) parts.push(renderPersona(cp.persona));
+  for (const ctx of cp.context) parts.push(renderContext(ctx));
+  if (cp.skills.length) parts.push(renderSkills(cp.skills));
+  if (cp.workflows.length) parts.push(renderWorkflows(cp.workflows));
+  if (opts.budget) parts.push(renderBudget(cp));
+
+  return parts.join('\n');
+}
+
+function xmlAttr(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/"/g, '&quot;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;');
+}
+
+// Anthropic-style document tags. Element bodies are left unescaped on
+// purpose: context is usually Markdown or code, which models read best
+// verbatim, and the tags only need to delimit sections.
+function renderXml(cp: ComposedPrompt, opts: RenderOptions): string {
+  const lines: string[] = [`<prompt name="${xmlAttr(cp.promptName)}">`];
+
+  if (cp.persona) {
+    const p = cp.persona;
+    lines.push(`<persona name="${xmlAttr(p.name)}">`);
+    if (p.description) lines.push(`<description>${p.description}</description>`);
+    if (p.expertise.length) lines.push(`<expertise>${p.expertise.join(', ')}</expertise>`);
+    if (p.tone) lines.push(`<tone>${p.tone}</tone>`);
+    if (p.conventions.length) {
+      lines.push('<conventions>');
+      for (const c of p.conventions) lines.push(`<convention>${c}</convention>`);
+      lines.push('</conventions>');
+    }
+    lines.push('</persona>');
   }
 
   for (const ctx of cp.context) {
-    parts.push(`## Context: ${ctx.name}\n`);
-    parts.push(ctx.content);
-    parts.push('');
+    lines.push(`<context name="${xmlAttr(ctx.name)}" source="${xmlAttr(ctx.source)}">`);
+    lines.push(ctx.content.trimEnd());
+    lines.push('</context>');
   }
 
   if (cp.skills.length) {
-    parts.push('## Available Skills\n');
+    lines.push('<skills>');
     for (const s of cp.skills) {
-      parts.push(`- **${s.name}**: ${s.description}`);
+      lines.push(`<skill name="${xmlAttr(s.name)}" path="${xmlAttr(s.path)}">${s.description}</skill>`);
     }
-    parts.push('');
+    lines.push('</skills>');
   }
 
   if (cp.workflows.length) {
-    parts.push('## Available Workflows\n');
+    lines.push('<workflows>');
     for (const w of cp.workflows) {
-      parts.push(`- **${w.name}**: ${w.description}`);
+      lines.push(`<workflow name="${xmlAttr(w.name)}" path="${xmlAttr(w.path)}">${w.description}</workflow>`);
     }
-    parts.push('');
+    lines.push('</workflows>');
   }
 
-  return parts.join('\n');
+  if (opts.budget) {
+    const t = cp.tokens;
+    const max = t.max != null ? ` max="${t.max}"` : '';
+    lines.push(
+      `<token_budget persona="${t.persona}" context="${t.context}" skills="${t.skills}" workflows="${t.workflows}" total="${t.total}"${max}/>`,
+    );
+  }
+
+  lines.push('</prompt>');
+  return lines.join('\n') + '\n';
+}
+
+function renderJson(cp: ComposedPrompt): string {
+  const { template: _template, ...rest } = cp;
+  return JSON.stringify(rest, null, 2) + '\n';
+}
+
+const RENDERERS = new Map<string, Renderer>([
+  ['markdown', renderMarkdown],
+  ['xml', renderXml],
+  ['json', renderJson],
+]);
+
+export function registerRenderer(format: string, renderer: Renderer): void {
+  RENDERERS.set(format, renderer);
+}
+
+export function renderFormats(): string[] {
+  return [...RENDERERS.keys()];
+}
+
+export function render(cp: ComposedPrompt, opts: RenderOptions = {}): string {
+  const format = opts.format ?? 'markdown';
+  const renderer = RENDERERS.get(format);
+  if (!renderer) {
+    throw new Error(`Unknown output format: "${format}". Expected one of: ${renderFormats().join(', ')}`);
+  }
+  return renderer(cp, opts);
 }
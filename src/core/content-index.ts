@@ -0,0 +1,186 @@
+import { join, dirname } from 'node:path';
+import { readFileSync, writeFileSync, mkdirSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { ContextManifest } from '../types/manifest.js';
+import { discoverTypes } from './registry.js';
+import { expandSources } from './context-sources.js';
+import { getInstalledRoot, getUserdataRoot } from './userdata.js';
+
+// ── Types ───────────────────────────────────────────────────────────
+
+interface IndexedDoc {
+  typePath: string;
+  source: string;
+  path: string;
+}
+
+/** Inverted index: term -> [docId, term frequency] postings. */
+interface ContentIndex {
+  /** INDEX_FORMAT the index was built with; older indexes are rebuilt. */
+  format?: number;
+  builtAt: string;
+  docs: IndexedDoc[];
+  terms: Record<string, [number, number][]>;
+}
+
+export interface ContentHit {
+  typePath: string;
+  source: string;
+  score: number;
+  snippet: string;
+}
+
+const INDEX_FILE = 'content-index.json';
+/** Bump when tokenize changes, so indexes built by the old one are rebuilt. */
+const INDEX_FORMAT = 2;
+const MIN_TERM_LENGTH = 2;
+const SNIPPET_BEFORE = 40;
+const SNIPPET_AFTER = 80;
+
+export function contentIndexPath(): string {
+  return join(getUserdataRoot(), INDEX_FILE);
+}
+
+/**
+ * Lowercased runs of letters and digits in any script. Text is NFKC
+ * normalized first, so composed and decomposed accents (and full-width
+ * forms) produce the same term; combining marks stay part of a word.
+ */
+export function tokenize(text: string): string[] {
+  return text
+    .normalize('NFKC')
+    .toLowerCase()
+    .split(/[^\p{L}\p{M}\p{N}]+/u)
+    .filter((t) => [...t].length >= MIN_TERM_LENGTH);
+}
+
+// ── Build ───────────────────────────────────────────────────────────
+
+export interface ContextFile {
+  typePath: string;
+  source: string;
+  path: string;
+  content: string;
+}
+
+/** Source files of every installed context type, with their content. */
+export function installedContextFiles(installedRoot = getInstalledRoot()): ContextFile[] {
+  const files: ContextFile[] = [];
+  const contexts = discoverTypes([{ name: 'installed', basePath: installedRoot }])
+    .filter((t) => t.category === 'context');
+
+  for (const ctx of contexts) {
+    let manifest: ContextManifest;
+    try {
+      manifest = yaml.load(readFileSync(ctx.manifestPath, 'utf-8')) as ContextManifest;
+    } catch {
+      continue;
+    }
+
+    for (const file of expandSources(ctx.sourceDir, manifest.sources ?? []).files) {
+      try {
+        files.push({
+          typePath: ctx.typePath,
+          source: file.source,
+          path: file.path,
+          content: readFileSync(file.path, 'utf-8'),
+        });
+      } catch {
+        continue;
+      }
+    }
+  }
+  return files;
+}
+
+/**
+ * Indexes the source files of every installed context type. Run after
+ * anything that changes installed/ so searches never read stale content.
+ */
+export function rebuildContentIndex(installedRoot = getInstalledRoot()): ContentIndex {
+  const index: ContentIndex = { format: INDEX_FORMAT, builtAt: new Date().toISOString(), docs: [], terms: {} };
+
+  for (const file of installedContextFiles(installedRoot)) {
+    const docId = index.docs.length;
+    index.docs.push({ typePath: file.typePath, source: file.source, path: file.path });
+
+    const freq = new Map<string, number>();
+    for (const term of tokenize(file.content)) freq.set(term, (freq.get(term) ?? 0) + 1);
+    for (const [term, tf] of freq) {
+      (index.terms[term] ??= []).push([docId, tf]);
+    }
+  }
+
+  mkdirSync(dirname(contentIndexPath()), { recursive: true });
+  writeFileSync(contentIndexPath(), JSON.stringify(index));
+  return index;
+}
+
+export function loadContentIndex(): ContentIndex | null {
+  try {
+    const index = JSON.parse(readFileSync(contentIndexPath(), 'utf-8')) as ContentIndex;
+    return index.format === INDEX_FORMAT ? index : null;
+  } catch {
+    return null;
+  }
+}
+
+// ── Query ───────────────────────────────────────────────────────────
+
+function makeSnippet(content: string, query: string, terms: string[]): string {
+  const lower = content.toLowerCase();
+  let at = lower.indexOf(query.normalize('NFKC').toLowerCase());
+  if (at === -1) {
+    at = Math.min(...terms.map((t) => lower.indexOf(t)).filter((i) => i >= 0));
+  }
+  if (!Number.isFinite(at)) return '';
+
+  const start = Math.max(0, at - SNIPPET_BEFORE);
+  const end = Math.min(content.length, at + SNIPPET_AFTER);
+  const text = content.slice(start, end).replace(/\s+/g, ' ').trim();
+  return `${start > 0 ? '…' : ''}${text}${end < content.length ? '…' : ''}`;
+}
+
+/**
+ * Files containing every query term, ranked by tf-idf with a bonus for
+ * the exact phrase. Builds the index on first use.
+ */
+export function searchContent(query: string, limit = 20): ContentHit[] {
+  const index = loadContentIndex() ?? rebuildContentIndex();
+  const terms = [...new Set(tokenize(query))];
+  if (terms.length === 0) return [];
+
+  const total = index.docs.length;
+  let scores: Map<number, number> | null = null;
+  for (const term of terms) {
+    const postings = index.terms[term] ?? [];
+    const idf = Math.log(1 + total / Math.max(1, postings.length));
+    const next = new Map<number, number>();
+    for (const [docId, tf] of postings) {
+      if (scores && !scores.has(docId)) continue;
+      next.set(docId, (scores?.get(docId) ?? 0) + tf * idf);
+    }
+    scores = next;
+  }
+
+  const hits: ContentHit[] = [];
+  for (const [docId, score] of scores ?? []) {
+    const doc = index.docs[docId];
+    let content = '';
+    try {
+      // Normalized as terms are, so phrases and snippets match either form
+      content = readFileSync(doc.path, 'utf-8').normalize('NFKC');
+    } catch {
+      // Indexed file removed since the last rebuild
+    }
+    const phrase = content.toLowerCase().includes(query.normalize('NFKC').toLowerCase());
+    hits.push({
+      typePath: doc.typePath,
+      source: doc.source,
+      score: phrase ? score * 2 : score,
+      snippet: makeSnippet(content, query, terms),
+    });
+  }
+
+  return hits.sort((a, b) => b.score - a.score).slice(0, limit);
+}
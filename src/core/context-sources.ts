@@ -0,0 +1,259 @@
+import { join, dirname, basename } from 'node:path';
+import { createHash } from 'node:crypto';
+import {
+  readFileSync,
+  writeFileSync,
+  existsSync,
+  statSync,
+  mkdirSync,
+  rmSync,
+  openSync,
+  readSync,
+  closeSync,
+} from 'node:fs';
+import yaml from 'js-yaml';
+import type { ContextManifest, PromptManifest } from '../types/manifest.js';
+import { getCacheDir } from './userdata.js';
+import { isGlob, globFiles, listFiles } from '../utils/fs.js';
+import { authorizationFor } from './credentials.js';
+import { fetchWithCredentials } from '../utils/http.js';
+import { isOffline, offlineSkip } from './offline.js';
+
+// ── Constants ───────────────────────────────────────────────────────
+
+const REMOTE_CACHE_DIR = 'context';
+const REMOTE_TTL_MS = 24 * 60 * 60 * 1000;
+const MAX_REMOTE_BYTES = 1024 * 1024;
+const BINARY_SNIFF_BYTES = 8000;
+const INDEX_NAMES = /^(index|readme)\.[a-z0-9]+$/i;
+
+// ── Types ───────────────────────────────────────────────────────────
+
+export interface ExpandedSource {
+  /** Display label: the relative file path, or the URL for remote sources. */
+  source: string;
+  /** Local file to read; for remote sources this is the cache entry. */
+  path: string;
+  remote: boolean;
+}
+
+// ── Local expansion ─────────────────────────────────────────────────
+
+export function isRemoteSource(source: string): boolean {
+  return /^https?:\/\//i.test(source);
+}
+
+function isTextFile(path: string): boolean {
+  const fd = openSync(path, 'r');
+  try {
+    const buf = Buffer.alloc(BINARY_SNIFF_BYTES);
+    const n = readSync(fd, buf, 0, BINARY_SNIFF_BYTES, 0);
+    return !buf.subarray(0, n).includes(0);
+  } finally {
+    closeSync(fd);
+  }
+}
+
+/**
+ * Directory ordering rule: files in a directory come before its
+ * subdirectories, index/README files lead their directory, and the rest
+ * sort naturally so 2-setup.md precedes 10-advanced.md.
+ */
+export function orderSourceFiles(files: string[]): string[] {
+  const collator = new Intl.Collator(undefined, { numeric: true, sensitivity: 'base' });
+  return [...files].sort((a, b) => {
+    const da = dirname(a);
+    const db = dirname(b);
+    if (da !== db) {
+      if (db.startsWith(da + '/') || da === '.') return -1;
+      if (da.startsWith(db + '/') || db === '.') return 1;
+      return collator.compare(da, db);
+    }
+    const ia = INDEX_NAMES.test(basename(a)) ? 0 : 1;
+    const ib = INDEX_NAMES.test(basename(b)) ? 0 : 1;
+    if (ia !== ib) return ia - ib;
+    return collator.compare(basename(a), basename(b));
+  });
+}
+
+/**
+ * Expands a context manifest's sources into concrete files. Entries may
+ * be plain files, globs (`docs/**\/*.md`), directories (all text files,
+ * in directory order), or https:// URLs served from the remote cache.
+ */
+export function expandSources(dir: string, sources: string[]): {
+  files: ExpandedSource[];
+  warnings: string[];
+} {
+  const files: ExpandedSource[] = [];
+  const warnings: string[] = [];
+  const seen = new Set<string>();
+
+  const add = (rel: string) => {
+    if (seen.has(rel)) return;
+    seen.add(rel);
+    files.push({ source: rel, path: join(dir, rel), remote: false });
+  };
+
+  for (const source of sources) {
+    if (isRemoteSource(source)) {
+      files.push({ source, path: remoteCachePath(source), remote: true });
+      continue;
+    }
+
+    if (isGlob(source)) {
+      const matches = orderSourceFiles(globFiles(dir, source));
+      if (matches.length === 0) warnings.push(`Context glob matched no files: ${source}`);
+      for (const m of matches) add(m);
+      continue;
+    }
+
+    const full = join(dir, source);
+    if (existsSync(full) && statSync(full).isDirectory()) {
+      const prefix = source.replace(/\/+$/, '');
+      const matches = listFiles(full).filter((f) => isTextFile(join(full, f)));
+      for (const m of orderSourceFiles(matches)) add(`${prefix}/${m}`);
+      continue;
+    }
+
+    add(source);
+  }
+
+  return { files, warnings };
+}
+
+// ── Remote sources ──────────────────────────────────────────────────
+
+export function remoteCachePath(url: string): string {
+  const key = createHash('sha256').update(url).digest('hex');
+  return join(getCacheDir(), REMOTE_CACHE_DIR, key);
+}
+
+export function clearRemoteCache(): void {
+  rmSync(join(getCacheDir(), REMOTE_CACHE_DIR), { recursive: true, force: true });
+}
+
+function isFresh(path: string): boolean {
+  try {
+    return Date.now() - statSync(path).mtimeMs < REMOTE_TTL_MS;
+  } catch {
+    return false;
+  }
+}
+
+async function fetchCapped(url: string): Promise<string> {
+  const authorization = authorizationFor(url);
+  const res = await fetchWithCredentials(url, authorization ? { Authorization: authorization } : {});
+  if (!res.ok) {
+    throw new Error(`HTTP ${res.status} ${res.statusText}`);
+  }
+
+  const declared = Number(res.headers.get('content-length') ?? 0);
+  if (declared > MAX_REMOTE_BYTES) {
+    throw new Error(`exceeds ${MAX_REMOTE_BYTES} byte limit (${declared} bytes)`);
+  }
+
+  if (!res.body) return '';
+  const chunks: Uint8Array[] = [];
+  let total = 0;
+  const reader = res.body.getReader();
+  for (;;) {
+    const { done, value } = await reader.read();
+    if (done) break;
+    total += value.byteLength;
+    if (total > MAX_REMOTE_BYTES) {
+      await reader.cancel();
+      throw new Error(`exceeds ${MAX_REMOTE_BYTES} byte limit`);
+    }
+    chunks.push(value);
+  }
+  return Buffer.concat(chunks).toString('utf-8');
+}
+
+/**
+ * Downloads remote sources into the cache. Fresh entries are reused; a
+ * failed refresh keeps the stale copy so composition still works offline.
+ */
+export async function fetchRemoteSources(
+  urls: string[],
+  force = false,
+): Promise<string[]> {
+  const warnings: string[] = [];
+  for (const url of urls) {
+    if (!/^https:\/\//i.test(url)) {
+      warnings.push(`Refusing non-https context source: ${url}`);
+      continue;
+    }
+    const cachePath = remoteCachePath(url);
+    if (!force && isFresh(cachePath)) continue;
+    if (isOffline()) {
+      const fallback = existsSync(cachePath) ? ' (using cached copy)' : '';
+      warnings.push(offlineSkip(`fetch ${url}${fallback}`));
+      continue;
+    }
+
+    try {
+      const body = await fetchCapped(url);
+      mkdirSync(dirname(cachePath), { recursive: true });
+      writeFileSync(cachePath, body, 'utf-8');
+    } catch (err) {
+      const fallback = existsSync(cachePath) ? ' (using cached copy)' : '';
+      warnings.push(`Failed to fetch ${url}: ${(err as Error).message}${fallback}`);
+    }
+  }
+  return warnings;
+}
+
+function findManifest(dir: string): string | null {
+  for (const name of ['manifest.yaml', 'manifest.json', 'context.yaml', 'prompt.yaml']) {
+    const path = join(dir, name);
+    if (existsSync(path)) return path;
+  }
+  return null;
+}
+
+function remoteSourcesOf(ctxDir: string): string[] {
+  const manifestPath = findManifest(ctxDir);
+  if (!manifestPath) return [];
+  try {
+    const data = yaml.load(readFileSync(manifestPath, 'utf-8')) as ContextManifest;
+    return (data.sources ?? []).filter(isRemoteSource);
+  } catch {
+    return [];
+  }
+}
+
+/** Refreshes remote sources for one installed context type. */
+export async function prefetchContext(
+  ctxPath: string,
+  installedRoot: string,
+  force = false,
+): Promise<string[]> {
+  return fetchRemoteSources(remoteSourcesOf(join(installedRoot, ctxPath)), force);
+}
+
+/**
+ * Refreshes remote sources for every context a prompt references.
+ * typeDirs overrides where types are read from, as compose's does.
+ */
+export async function prefetchPromptContext(
+  promptPath: string,
+  installedRoot: string,
+  typeDirs: Record<string, string> = {},
+): Promise<string[]> {
+  const manifestPath = findManifest(typeDirs[promptPath] ?? join(installedRoot, promptPath));
+  if (!manifestPath) return [];
+
+  let data: PromptManifest;
+  try {
+    data = yaml.load(readFileSync(manifestPath, 'utf-8')) as PromptManifest;
+  } catch {
+    return [];
+  }
+
+  const warnings: string[] = [];
+  for (const ctxPath of data.context ?? []) {
+    warnings.push(...(await fetchRemoteSources(remoteSourcesOf(typeDirs[ctxPath] ?? join(installedRoot, ctxPath)))));
+  }
+  return warnings;
+}
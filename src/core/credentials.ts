@@ -0,0 +1,255 @@
+import { join, dirname } from 'node:path';
+import { homedir } from 'node:os';
+import { readFileSync, writeFileSync, mkdirSync } from 'node:fs';
+import { execFileSync } from 'node:child_process';
+import yaml from 'js-yaml';
+import { APP_NAME, envVar } from '../config/branding.js';
+import { getCredentialsPath } from './userdata.js';
+
+// ── Credential chain ────────────────────────────────────────────────
+//
+// Credentials for private catalogs and context hosts are looked up per
+// host, first match wins:
+//
+//   1. AGENTX_TOKEN_<HOST>   e.g. AGENTX_TOKEN_GIT_ACME_COM
+//   2. OS keychain           macOS Keychain or libsecret (secret-tool)
+//   3. credentials.yaml      0600 file, only where no keychain exists
+//   4. ~/.netrc              or $NETRC
+//
+// `agentx sources login` writes to 2, or 3 as a fallback. Nothing is
+// ever read from or written to config.yaml.
+
+export type CredentialOrigin = 'env' | 'keychain' | 'file' | 'netrc';
+
+export interface Credential {
+  host: string;
+  /** Set for netrc entries with a login; the secret is then a password. */
+  username?: string;
+  secret: string;
+  from: CredentialOrigin;
+}
+
+const KEYCHAIN_SERVICE = APP_NAME;
+
+/** Host of an https:// or scp-style (git@host:path) URL. */
+export function hostOf(url: string): string | null {
+  const scp = /^[\w.-]+@([^:/]+):/.exec(url);
+  if (scp) return scp[1].toLowerCase();
+  try {
+    return new URL(url).hostname.toLowerCase() || null;
+  } catch {
+    return null;
+  }
+}
+
+export function hostEnvVar(host: string): string {
+  return envVar(`TOKEN_${host.replace(/[^a-zA-Z0-9]/g, '_')}`);
+}
+
+// ── Keychain ────────────────────────────────────────────────────────
+
+type KeychainBackend = 'macos' | 'secret-tool' | null;
+
+function keychainBackend(): KeychainBackend {
+  const has = (cmd: string) => {
+    try {
+      execFileSync('which', [cmd], { stdio: 'ignore' });
+      return true;
+    } catch {
+      return false;
+    }
+  };
+  if (process.platform === 'darwin' && has('security')) return 'macos';
+  if (process.platform === 'linux' && has('secret-tool')) return 'secret-tool';
+  return null;
+}
+
+function keychainGet(host: string): string | null {
+  try {
+    switch (keychainBackend()) {
+      case 'macos':
+        return execFileSync(
+          'security',
+          ['find-generic-password', '-s', KEYCHAIN_SERVICE, '-a', host, '-w'],
+          { encoding: 'utf-8', stdio: ['ignore', 'pipe', 'ignore'] },
+        ).trim() || null;
+      case 'secret-tool':
+        return execFileSync(
+          'secret-tool',
+          ['lookup', 'service', KEYCHAIN_SERVICE, 'host', host],
+          { encoding: 'utf-8', stdio: ['ignore', 'pipe', 'ignore'] },
+        ).trim() || null;
+      default:
+        return null;
+    }
+  } catch {
+    return null; // No entry, or the keychain is locked
+  }
+}
+
+function keychainSet(host: string, secret: string): boolean {
+  switch (keychainBackend()) {
+    case 'macos':
+      // `security -i` reads the command from stdin, which keeps the secret
+      // out of argv where `ps` would show it
+      execFileSync('security', ['-i'], {
+        input: `add-generic-password -U -s ${securityQuote(KEYCHAIN_SERVICE)} -a ${securityQuote(host)} -w ${securityQuote(secret)}\n`,
+        stdio: ['pipe', 'ignore', 'ignore'],
+      });
+      return true;
+    case 'secret-tool':
+      // The secret goes over stdin so it never shows up in `ps`
+      execFileSync(
+        'secret-tool',
+        ['store', `--label=${APP_NAME} ${host}`, 'service', KEYCHAIN_SERVICE, 'host', host],
+        { input: secret, stdio: ['pipe', 'ignore', 'ignore'] },
+      );
+      return true;
+    default:
+      return false;
+  }
+}
+
+/** Quotes a word for the `security -i` command line. */
+export function securityQuote(word: string): string {
+  return `"${word.replace(/[\\"]/g, '\\$&')}"`;
+}
+
+function keychainDelete(host: string): boolean {
+  try {
+    switch (keychainBackend()) {
+      case 'macos':
+        execFileSync('security', ['delete-generic-password', '-s', KEYCHAIN_SERVICE, '-a', host], {
+          stdio: 'ignore',
+        });
+        return true;
+      case 'secret-tool':
+        execFileSync('secret-tool', ['clear', 'service', KEYCHAIN_SERVICE, 'host', host], {
+          stdio: 'ignore',
+        });
+        return true;
+      default:
+        return false;
+    }
+  } catch {
+    return false;
+  }
+}
+
+// ── File fallback ───────────────────────────────────────────────────
+
+function loadCredentialsFile(): Record<string, string> {
+  try {
+    const data = yaml.load(readFileSync(getCredentialsPath(), 'utf-8')) as { hosts?: Record<string, string> };
+    return data?.hosts ?? {};
+  } catch {
+    return {};
+  }
+}
+
+/** Secrets stored in credentials.yaml, so other files can be checked for copies. */
+export function storedCredentialSecrets(): string[] {
+  return Object.values(loadCredentialsFile());
+}
+
+function saveCredentialsFile(hosts: Record<string, string>): void {
+  const path = getCredentialsPath();
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, yaml.dump({ hosts }), { mode: 0o600 });
+}
+
+// ── netrc ───────────────────────────────────────────────────────────
+
+export interface NetrcEntry {
+  login?: string;
+  password?: string;
+}
+
+/**
+ * Parses netrc content into machine → entry. A "default" entry is kept
+ * under that name, but lookups never use it: it would send one password
+ * to every host a catalog or context source names.
+ */
+export function parseNetrc(content: string): Map<string, NetrcEntry> {
+  const entries = new Map<string, NetrcEntry>();
+  const tokens = content.replace(/#.*$/gm, '').split(/\s+/).filter(Boolean);
+  let current: NetrcEntry | null = null;
+
+  for (let i = 0; i < tokens.length; i++) {
+    const token = tokens[i];
+    if (token === 'machine') {
+      current = {};
+      entries.set(tokens[++i]?.toLowerCase(), current);
+    } else if (token === 'default') {
+      current = {};
+      entries.set('default', current);
+    } else if (token === 'macdef') {
+      current = null; // Macro bodies run to a blank line; not credentials
+    } else if (current && (token === 'login' || token === 'password')) {
+      current[token] = tokens[++i];
+    }
+  }
+  return entries;
+}
+
+function netrcGet(host: string): NetrcEntry | null {
+  const path = process.env.NETRC ?? join(homedir(), process.platform === 'win32' ? '_netrc' : '.netrc');
+  try {
+    const entries = parseNetrc(readFileSync(path, 'utf-8'));
+    const entry = entries.get(host);
+    return entry?.password ? entry : null;
+  } catch {
+    return null;
+  }
+}
+
+// ── Public API ──────────────────────────────────────────────────────
+
+export function resolveCredential(host: string): Credential | null {
+  const fromEnv = process.env[hostEnvVar(host)];
+  if (fromEnv) return { host, secret: fromEnv, from: 'env' };
+
+  const fromKeychain = keychainGet(host);
+  if (fromKeychain) return { host, secret: fromKeychain, from: 'keychain' };
+
+  const fromFile = loadCredentialsFile()[host];
+  if (fromFile) return { host, secret: fromFile, from: 'file' };
+
+  const netrc = netrcGet(host);
+  if (netrc?.password) {
+    return { host, username: netrc.login, secret: netrc.password, from: 'netrc' };
+  }
+  return null;
+}
+
+/** Authorization header value for a credential. */
+export function authorizationHeader(cred: Credential): string {
+  if (cred.username) {
+    return `Basic ${Buffer.from(`${cred.username}:${cred.secret}`).toString('base64')}`;
+  }
+  return `Bearer ${cred.secret}`;
+}
+
+/** Authorization header for a URL's host, or undefined when none is configured. */
+export function authorizationFor(url: string): string | undefined {
+  const host = hostOf(url);
+  const cred = host ? resolveCredential(host) : null;
+  return cred ? authorizationHeader(cred) : undefined;
+}
+
+/** Stores a token for host. Returns where it went. */
+export function storeCredential(host: string, secret: string): 'keychain' | 'file' {
+  if (keychainSet(host, secret)) return 'keychain';
+  saveCredentialsFile({ ...loadCredentialsFile(), [host]: secret });
+  return 'file';
+}
+
+/** Removes a stored token for host from the keychain and the file. */
+export function removeCredential(host: string): boolean {
+  const fromKeychain = keychainDelete(host);
+  const hosts = loadCredentialsFile();
+  if (!(host in hosts)) return fromKeychain;
+  delete hosts[host];
+  saveCredentialsFile(hosts);
+  return true;
+}
@@ -0,0 +1,421 @@
+import { join } from 'node:path';
+import { spawn } from 'node:child_process';
+import { Writable } from 'node:stream';
+import {
+  readFileSync,
+  writeFileSync,
+  readdirSync,
+  existsSync,
+  mkdirSync,
+  rmSync,
+  statSync,
+  renameSync,
+  openSync,
+  closeSync,
+  createWriteStream,
+  type WriteStream,
+} from 'node:fs';
+import yaml from 'js-yaml';
+import { getSkillRegistryPath, getSkillsDir } from './userdata.js';
+import { nameFromPath } from './registry.js';
+import { skillCommand } from './runtime.js';
+import { findManifest } from './executor.js';
+import { selectVersion } from './versions.js';
+import { guardManifest } from './integrity.js';
+import { acquireLock, readLockOwner } from './lock.js';
+import { subscribe } from './notify.js';
+import { APP_NAME } from '../config/branding.js';
+import { maskInputs } from '../utils/input-parser.js';
+import { parseDuration } from '../utils/units.js';
+import { logger } from '../utils/log.js';
+import type { SkillManifest } from '../types/manifest.js';
+
+const log = logger('daemon');
+
+// ── Daemon skills ───────────────────────────────────────────────────
+//
+// A skill with `mode: daemon` (a poller such as an error-spike monitor)
+// runs until stopped. `agentx daemon start` launches a detached
+// supervisor (`agentx daemon run`), which starts the skill, appends its
+// output to a log, and restarts it per the manifest's daemon policy:
+//
+//   daemon:
+//     restart: on-failure     # always | on-failure | never
+//     max_restarts: 5         # in a row, each after a run shorter than a minute
+//     restart_delay: 5s
+//
+// Everything lives in the skill's registry, under daemon/: the
+// supervisor's pidfile, state.json, and daemon.log, which moves to
+// daemon.log.1 whenever it grows past 10 MiB. The pidfile is taken like
+// a lock (core/lock.ts), so two supervisors can't both claim the skill.
+// The skill's manifest goes through run.manifest_guard at every start,
+// as it does for `agentx run`.
+//
+// The supervisor listens for change notifications (core/notify.ts): when
+// the skill is installed again or its version switched, the running copy
+// is stopped and the new one started right away, outside the restart
+// policy; when the skill is uninstalled, the supervisor stops.
+
+const DAEMON_DIR = 'daemon';
+const PID_FILE = 'daemon.pid';
+const STATE_FILE = 'state.json';
+const LOG_FILE = 'daemon.log';
+const MAX_LOG_BYTES = 10 * 1024 * 1024;
+// A run at least this long resets the restart count
+const STABLE_MS = 60_000;
+const STOP_TIMEOUT_MS = 10_000;
+const STOP_POLL_MS = 100;
+
+const DEFAULT_POLICY: Required<NonNullable<SkillManifest['daemon']>> = {
+  restart: 'on-failure',
+  max_restarts: 5,
+  restart_delay: '5s',
+};
+
+export interface DaemonExit {
+  code: number | null;
+  signal: string | null;
+  at: string;
+}
+
+export interface DaemonState {
+  typePath: string;
+  inputs: Record<string, string>;
+  supervisorPid: number;
+  childPid?: number;
+  startedAt: string;
+  /** Restarts since the supervisor started. */
+  restarts: number;
+  lastExit?: DaemonExit;
+  /** The restart policy ran out, so the supervisor stopped. */
+  gaveUp?: boolean;
+}
+
+export interface DaemonStatus {
+  typePath: string;
+  running: boolean;
+  logPath: string;
+  state: DaemonState | null;
+}
+
+/** The command line that runs `agentx daemon run` for a skill. */
+export interface Launcher {
+  command: string;
+  argv: string[];
+}
+
+export interface SuperviseOptions {
+  /** Aborting stops the skill and the supervisor. */
+  signal?: AbortSignal;
+}
+
+// ── Paths and state ─────────────────────────────────────────────────
+
+export function daemonDir(typePath: string): string {
+  return join(getSkillRegistryPath(nameFromPath(typePath)), DAEMON_DIR);
+}
+
+export function daemonLogPath(typePath: string): string {
+  return join(daemonDir(typePath), LOG_FILE);
+}
+
+function isAlive(pid: number): boolean {
+  try {
+    process.kill(pid, 0);
+    return true;
+  } catch (err) {
+    return (err as NodeJS.ErrnoException).code === 'EPERM';
+  }
+}
+
+function readPid(typePath: string): number | null {
+  const pid = readLockOwner(join(daemonDir(typePath), PID_FILE))?.pid;
+  return typeof pid === 'number' ? pid : null;
+}
+
+export function readDaemonState(typePath: string): DaemonState | null {
+  try {
+    return JSON.parse(readFileSync(join(daemonDir(typePath), STATE_FILE), 'utf-8')) as DaemonState;
+  } catch {
+    return null;
+  }
+}
+
+function writeState(state: DaemonState): void {
+  const path = join(daemonDir(state.typePath), STATE_FILE);
+  writeFileSync(`${path}.tmp`, JSON.stringify(state, null, 2));
+  renameSync(`${path}.tmp`, path);
+}
+
+/** The supervisor's pid when it is running; a stale pidfile is removed. */
+function runningPid(typePath: string): number | null {
+  const pid = readPid(typePath);
+  if (pid === null) return null;
+  if (isAlive(pid)) return pid;
+  rmSync(join(daemonDir(typePath), PID_FILE), { force: true });
+  return null;
+}
+
+export function daemonStatus(typePath: string): DaemonStatus {
+  return {
+    typePath,
+    running: runningPid(typePath) !== null,
+    logPath: daemonLogPath(typePath),
+    state: readDaemonState(typePath),
+  };
+}
+
+/** Every skill that has been started as a daemon, running or not. */
+export function listDaemons(): DaemonStatus[] {
+  const found: DaemonStatus[] = [];
+  const walk = (dir: string) => {
+    let entries;
+    try {
+      entries = readdirSync(dir, { withFileTypes: true });
+    } catch {
+      return;
+    }
+    for (const entry of entries) {
+      if (!entry.isDirectory()) continue;
+      const full = join(dir, entry.name);
+      if (entry.name === DAEMON_DIR && existsSync(join(full, STATE_FILE))) {
+        const state = JSON.parse(readFileSync(join(full, STATE_FILE), 'utf-8')) as DaemonState;
+        found.push(daemonStatus(state.typePath));
+      } else if (entry.name !== 'output' && entry.name !== 'state') {
+        walk(full);
+      }
+    }
+  };
+  walk(getSkillsDir());
+  return found.sort((a, b) => a.typePath.localeCompare(b.typePath));
+}
+
+/**
+ * The manifest of an installed `mode: daemon` skill, and the directory of
+ * the version that runs: the current project's pin, else the default.
+ * Throws when run.manifest_guard blocks that version.
+ */
+export function loadDaemonSkill(typePath: string, installedRoot: string): { manifest: SkillManifest; dir: string } {
+  const { dir, version } = selectVersion(process.cwd(), installedRoot, typePath);
+  const manifestPath = existsSync(dir) ? findManifest(dir) : null;
+  if (!manifestPath) throw new Error(`Type not installed: ${typePath}`);
+  guardManifest(typePath, installedRoot, version ? { dir, version } : undefined);
+  const manifest = yaml.load(readFileSync(manifestPath, 'utf-8')) as SkillManifest;
+  if (manifest.type !== 'skill' || manifest.mode !== 'daemon') {
+    throw new Error(`${typePath} is not a daemon skill (no \`mode: daemon\` in its manifest); use \`${APP_NAME} run\``);
+  }
+  return { manifest, dir };
+}
+
+// ── Control ─────────────────────────────────────────────────────────
+
+/**
+ * Launches a detached supervisor for the skill and returns its pid. The
+ * supervisor's own output (and the skill's) goes to daemon.log.
+ */
+export function startDaemon(typePath: string, installedRoot: string, launcher: Launcher): number {
+  loadDaemonSkill(typePath, installedRoot);
+  const running = runningPid(typePath);
+  if (running !== null) throw new Error(`${typePath} is already running (supervisor pid ${running})`);
+
+  mkdirSync(daemonDir(typePath), { recursive: true });
+  const fd = openSync(daemonLogPath(typePath), 'a');
+  try {
+    const child = spawn(launcher.command, launcher.argv, { detached: true, stdio: ['ignore', fd, fd] });
+    child.unref();
+    log.info('started', { skill: typePath, pid: child.pid });
+    return child.pid ?? 0;
+  } finally {
+    closeSync(fd);
+  }
+}
+
+/**
+ * Stops a daemon's supervisor, which stops the skill. Kills both when
+ * the supervisor doesn't exit within 10 seconds. Returns false when it
+ * wasn't running.
+ */
+export async function stopDaemon(typePath: string): Promise<boolean> {
+  const pid = runningPid(typePath);
+  if (pid === null) return false;
+  process.kill(pid, 'SIGTERM');
+
+  const deadline = Date.now() + STOP_TIMEOUT_MS;
+  while (isAlive(pid) && Date.now() < deadline) {
+    await new Promise((resolve) => setTimeout(resolve, STOP_POLL_MS));
+  }
+  if (isAlive(pid)) {
+    const child = readDaemonState(typePath)?.childPid;
+    for (const p of [child, pid]) {
+      if (p && isAlive(p)) process.kill(p, 'SIGKILL');
+    }
+    rmSync(join(daemonDir(typePath), PID_FILE), { force: true });
+    log.warn('killed', { skill: typePath, pid });
+  }
+  log.info('stopped', { skill: typePath, pid });
+  return true;
+}
+
+// ── Supervisor ──────────────────────────────────────────────────────
+
+/** daemon.log, moved to daemon.log.1 whenever a write would take it past MAX_LOG_BYTES. */
+class RotatingLog extends Writable {
+  private out: WriteStream;
+  private size: number;
+
+  constructor(private readonly path: string) {
+    super();
+    this.size = existsSync(path) ? statSync(path).size : 0;
+    this.out = createWriteStream(path, { flags: 'a' });
+  }
+
+  override _write(chunk: Buffer, _encoding: BufferEncoding, callback: (err?: Error | null) => void): void {
+    const write = () => {
+      this.size += chunk.length;
+      this.out.write(chunk, callback);
+    };
+    if (this.size === 0 || this.size + chunk.length <= MAX_LOG_BYTES) {
+      write();
+      return;
+    }
+    this.out.end(() => {
+      try {
+        renameSync(this.path, `${this.path}.1`);
+      } catch (err) {
+        callback(err as Error);
+        return;
+      }
+      this.out = createWriteStream(this.path, { flags: 'a' });
+      this.size = 0;
+      write();
+    });
+  }
+
+  override _final(callback: (err?: Error | null) => void): void {
+    this.out.end(() => callback());
+  }
+}
+
+function delay(ms: number, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve) => {
+    const timer = setTimeout(resolve, ms);
+    signal?.addEventListener('abort', () => {
+      clearTimeout(timer);
+      resolve();
+    });
+  });
+}
+
+/**
+ * Runs the skill in the foreground and restarts it per its policy,
+ * until it exits for good or opts.signal aborts. This is what
+ * `agentx daemon run` does; it also suits a systemd unit.
+ */
+export async function superviseDaemon(
+  typePath: string,
+  installedRoot: string,
+  inputs: Record<string, string>,
+  opts: SuperviseOptions = {},
+): Promise<DaemonState> {
+  let { manifest, dir: skillDir } = loadDaemonSkill(typePath, installedRoot);
+  let policy = { ...DEFAULT_POLICY, ...manifest.daemon };
+  let restartDelay = parseDuration(policy.restart_delay);
+
+  const dir = daemonDir(typePath);
+  mkdirSync(dir, { recursive: true });
+  const other = runningPid(typePath);
+  if (other !== null && other !== process.pid) {
+    throw new Error(`${typePath} is already running (supervisor pid ${other})`);
+  }
+  // Linked into place, so of two supervisors starting at once only one gets it
+  const releasePid = await acquireLock(dir, { command: `daemon run ${typePath}`, file: PID_FILE, timeoutMs: 0 });
+
+  const state: DaemonState = {
+    typePath,
+    // state.json is shown by daemon status; the child gets the real values
+    inputs: maskInputs(inputs, manifest.inputs),
+    supervisorPid: process.pid,
+    startedAt: new Date().toISOString(),
+    restarts: 0,
+  };
+  const logPath = daemonLogPath(typePath);
+  let stopping = opts.signal?.aborted ?? false;
+  let current: ReturnType<typeof spawn> | null = null;
+  opts.signal?.addEventListener('abort', () => {
+    stopping = true;
+    current?.kill('SIGTERM');
+  });
+
+  // Set when the installed skill changed under the running child
+  let changed = null as 'reload' | 'removed' | null;
+  const changes = subscribe(
+    (event) => {
+      if (!event.typePaths.includes(typePath)) return;
+      changed = event.kind === 'uninstall' ? 'removed' : 'reload';
+      if (changed === 'removed') stopping = true;
+      log.info('skill changed', { skill: typePath, kind: event.kind });
+      current?.kill('SIGTERM');
+    },
+    { socket: true },
+  );
+
+  let streak = 0;
+  try {
+    while (!stopping) {
+      if (changed === 'reload') {
+        changed = null;
+        ({ manifest, dir: skillDir } = loadDaemonSkill(typePath, installedRoot));
+        policy = { ...DEFAULT_POLICY, ...manifest.daemon };
+        restartDelay = parseDuration(policy.restart_delay);
+        streak = 0;
+      }
+      const proc = skillCommand(skillDir, manifest, inputs);
+      const out = new RotatingLog(logPath);
+      const note = (message: string) => out.write(`[${new Date().toISOString()}] ${APP_NAME}: ${message}\n`);
+      const started = Date.now();
+
+      const child = spawn(proc.command, proc.argv, { env: proc.env, stdio: ['ignore', 'pipe', 'pipe'] });
+      current = child;
+      state.childPid = child.pid;
+      writeState(state);
+      note(`started ${typePath} (pid ${child.pid})`);
+      child.stdout?.pipe(out, { end: false });
+      child.stderr?.pipe(out, { end: false });
+
+      const exit = await new Promise<DaemonExit>((resolve) => {
+        child.on('error', (err) => {
+          note(`failed to start: ${err.message}`);
+          resolve({ code: null, signal: null, at: new Date().toISOString() });
+        });
+        child.on('close', (code, signal) => resolve({ code, signal, at: new Date().toISOString() }));
+      });
+      current = null;
+      state.childPid = undefined;
+      state.lastExit = exit;
+      note(`exited with ${exit.signal ? `signal ${exit.signal}` : `code ${exit.code}`}`);
+      if (changed === 'removed') note(`${typePath} was uninstalled; stopping`);
+      else if (changed === 'reload') note(`${typePath} changed; starting the installed version`);
+      await new Promise((resolve) => out.end(resolve));
+
+      if (stopping) break;
+      if (changed === 'reload') continue;
+      if (policy.restart === 'never' || (policy.restart === 'on-failure' && exit.code === 0)) break;
+      streak = Date.now() - started >= STABLE_MS ? 0 : streak;
+      if (streak >= policy.max_restarts) {
+        state.gaveUp = true;
+        log.warn('gave up', { skill: typePath, restarts: streak });
+        break;
+      }
+      streak++;
+      state.restarts++;
+      writeState(state);
+      log.info('restarting', { skill: typePath, restart: state.restarts, delayMs: restartDelay });
+      await delay(restartDelay, opts.signal);
+    }
+  } finally {
+    changes.close();
+    writeState(state);
+    releasePid();
+  }
+  return state;
+}
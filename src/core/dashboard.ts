@@ -0,0 +1,84 @@
+import { listInstalled, type InstalledType } from './installed.js';
+import { knownProjects } from './projects.js';
+import { loadProject, previewSync } from './linker.js';
+import { buildSources, listExtensions, type ExtensionStatus } from './extension.js';
+import { discoverAllCached } from './registry.js';
+import { listHistory } from './output-history.js';
+
+// ── Dashboard data ──────────────────────────────────────────────────
+//
+// What `agentx ui` shows, gathered in one pass so the panes agree with
+// each other: installed types (with newer versions from the sources),
+// every known project with how many generated files a sync would
+// change, extension status, and the most recent skill runs, taken from
+// the output history the runtime keeps. Nothing here writes; the
+// dashboard's actions run the regular commands.
+
+export interface ProjectLinks {
+  path: string;
+  tools: string[];
+  linked: number;
+  /** Generated files and links a sync would create, update, or remove. */
+  pending: number;
+  lastSync: string | null;
+  /** Set when the project couldn't be read. */
+  error?: string;
+}
+
+export interface RecentRun {
+  typePath: string;
+  /** The skill's nth most recent run, as `output show --run` counts. */
+  run: number;
+  at: string;
+  bytes: number;
+}
+
+export interface DashboardData {
+  installed: InstalledType[];
+  projects: ProjectLinks[];
+  extensions: ExtensionStatus[];
+  runs: RecentRun[];
+  warnings: string[];
+}
+
+export const RECENT_RUNS = 20;
+
+async function projectLinks(path: string, tools: string[], lastSync?: string): Promise<ProjectLinks> {
+  const base = { path, tools, lastSync: lastSync ?? null };
+  try {
+    const linked = Object.values(loadProject(path).active).reduce((n, refs) => n + (refs?.length ?? 0), 0);
+    const pending = (await previewSync(path)).filter((c) => c.action !== 'unchanged').length;
+    return { ...base, linked, pending };
+  } catch (err) {
+    return { ...base, linked: 0, pending: 0, error: err instanceof Error ? err.message : String(err) };
+  }
+}
+
+/** Latest runs across installed skills, newest first. */
+export function recentRuns(skills: string[], limit = RECENT_RUNS): RecentRun[] {
+  return skills
+    .flatMap((typePath) =>
+      listHistory(typePath)
+        .slice(0, limit)
+        .map((e) => ({ typePath, run: e.run, at: e.savedAt.toISOString(), bytes: e.bytes })),
+    )
+    .sort((a, b) => b.at.localeCompare(a.at) || a.typePath.localeCompare(b.typePath))
+    .slice(0, limit);
+}
+
+export async function loadDashboard(installedRoot: string, repoRoot: string): Promise<DashboardData> {
+  const warnings: string[] = [];
+  const available = await discoverAllCached(buildSources(repoRoot), undefined, warnings);
+  const installed = listInstalled(installedRoot, {}, available);
+
+  const projects: ProjectLinks[] = [];
+  for (const p of knownProjects()) projects.push(await projectLinks(p.path, p.tools, p.lastSync));
+
+  return {
+    installed,
+    projects,
+    extensions: await listExtensions(repoRoot),
+    runs: recentRuns(installed.filter((t) => t.category === 'skill').map((t) => t.typePath)),
+    warnings,
+  };
+}
@@ -0,0 +1,57 @@
+import type { DependencyNode, ResolvedType, Source } from '../types/registry.js';
+import { buildDependencyTree, discoverTypes, didYouMean, initSkillRegistry } from './registry.js';
+import { contentDir } from './merge.js';
+import { mapSkillDirs } from './runtime.js';
+import type { TypeDirs } from './versions.js';
+
+// ── Development mode ────────────────────────────────────────────────
+//
+// `agentx run --dev` and `agentx prompt --dev` read a type, and every
+// type it references, from the sources (the catalog checkout in
+// AGENTX_HOME and the extensions) instead of ~/.agentx/installed/, so an
+// author's edits apply on the next run without reinstalling. Types are
+// resolved as install resolves them: source order, prefer: pins, and
+// merge strategies. Nothing is copied into installed/. Skills keep the
+// registry (tokens, config, output history) their installed copy uses;
+// a skill that was never installed gets that registry created on first
+// use, the one thing --dev writes. Node skills run with the node_modules
+// of their source directory.
+
+export interface DevTypes {
+  /** Source directory of each type, by type path. */
+  dirs: TypeDirs;
+  types: ResolvedType[];
+}
+
+/** typePath and the types it references, resolved in sources. */
+export function resolveDev(typePath: string, sources: Source[], installedRoot: string): DevTypes {
+  const dev: DevTypes = { dirs: {}, types: [] };
+  const visit = (node: DependencyNode) => {
+    const resolved = node.resolved;
+    if (resolved && !(node.typePath in dev.dirs)) {
+      dev.dirs[node.typePath] = contentDir(resolved);
+      dev.types.push(resolved);
+    }
+    // References by a former path find the moved type too
+    if (resolved?.aliasOf) dev.dirs[resolved.aliasOf] = dev.dirs[node.typePath];
+    node.children.forEach(visit);
+  };
+  visit(buildDependencyTree(typePath, sources, installedRoot));
+
+  if (!(typePath in dev.dirs)) {
+    const known = discoverTypes(sources).map((t) => t.typePath);
+    throw new Error(`Type not found in any source: ${typePath}${didYouMean(typePath, known)}`);
+  }
+  return dev;
+}
+
+/**
+ * Makes the skills among dev's types runnable from their sources: a
+ * skill's registry is created if it was never installed, and runs use
+ * it. Returns warnings from creating registries.
+ */
+export function prepareDevSkills(dev: DevTypes, skillsDir: string): string[] {
+  const skills = dev.types.filter((t) => t.category === 'skill');
+  mapSkillDirs(Object.fromEntries(skills.map((t) => [t.typePath, dev.dirs[t.typePath]])));
+  return skills.flatMap((t) => initSkillRegistry(t, skillsDir));
+}
@@ -0,0 +1,261 @@
+import { join, dirname } from 'node:path';
+import { readFileSync, writeFileSync, mkdirSync, rmSync } from 'node:fs';
+import { createHash } from 'node:crypto';
+import * as settings from '../config/settings.js';
+import { getCacheDir, getInstalledRoot } from './userdata.js';
+import { installedContextFiles, tokenize } from './content-index.js';
+import { authorizationFor } from './credentials.js';
+import { httpFetch } from '../utils/http.js';
+
+// ── Embedders ───────────────────────────────────────────────────────
+//
+// An embedder turns text into unit-length vectors. The default is a
+// local feature-hashing model: no downloads, no network, deterministic.
+// An OpenAI-compatible endpoint can be configured instead:
+//
+//   embeddings.provider: api
+//   embeddings.url: https://llm.internal.acme.com/v1/embeddings
+//   embeddings.model: text-embedding-3-small
+//
+// Credentials for the endpoint come from the source credential chain.
+
+export interface Embedder {
+  /** Identifies the model; indexes built by different embedders never mix. */
+  id: string;
+  embed(texts: string[]): Promise<number[][]>;
+}
+
+const LOCAL_DIMENSIONS = 512;
+const API_BATCH = 64;
+
+// FNV-1a: fast, stable 32-bit hash for feature hashing.
+function fnv1a(text: string): number {
+  let h = 0x811c9dc5;
+  for (let i = 0; i < text.length; i++) {
+    h ^= text.charCodeAt(i);
+    h = Math.imul(h, 0x01000193);
+  }
+  return h >>> 0;
+}
+
+function normalize(v: number[]): number[] {
+  const norm = Math.sqrt(v.reduce((sum, x) => sum + x * x, 0));
+  return norm === 0 ? v : v.map((x) => x / norm);
+}
+
+/**
+ * Bag of words and bigrams hashed into a fixed-size vector, with
+ * sublinear term weights. Captures topical overlap, not synonyms; good
+ * enough to rank a team's context without shipping a model.
+ */
+export function localEmbedder(dimensions = LOCAL_DIMENSIONS): Embedder {
+  const embedOne = (text: string): number[] => {
+    const terms = tokenize(text);
+    const features = [...terms, ...terms.slice(1).map((t, i) => `${terms[i]} ${t}`)];
+    const counts = new Map<string, number>();
+    for (const f of features) counts.set(f, (counts.get(f) ?? 0) + 1);
+
+    const v = new Array<number>(dimensions).fill(0);
+    for (const [feature, count] of counts) {
+      const h = fnv1a(feature);
+      // The top bit picks a sign so collisions cancel out instead of piling up
+      v[h % dimensions] += (h & 0x80000000 ? -1 : 1) * (1 + Math.log(count));
+    }
+    return normalize(v);
+  };
+  return {
+    id: `local-hash-${dimensions}`,
+    embed: async (texts) => texts.map(embedOne),
+  };
+}
+
+export function apiEmbedder(url: string, model: string): Embedder {
+  return {
+    id: `api-${createHash('sha256').update(`${url}\0${model}`).digest('hex').slice(0, 12)}`,
+    embed: async (texts) => {
+      const out: number[][] = [];
+      for (let i = 0; i < texts.length; i += API_BATCH) {
+        const authorization = authorizationFor(url);
+        const res = await httpFetch(url, {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            ...(authorization ? { Authorization: authorization } : {}),
+          },
+          body: JSON.stringify({ model, input: texts.slice(i, i + API_BATCH) }),
+        });
+        if (!res.ok) {
+          throw new Error(`Embedding API ${url} returned HTTP ${res.status} ${res.statusText}`);
+        }
+        const body = (await res.json()) as { data: { embedding: number[] }[] };
+        out.push(...body.data.map((d) => normalize(d.embedding)));
+      }
+      return out;
+    },
+  };
+}
+
+/** The embedder selected in config.yaml (embeddings.provider), local by default. */
+export function configuredEmbedder(): Embedder {
+  const provider = settings.get('embeddings.provider') || 'local';
+  if (provider === 'local') return localEmbedder();
+  if (provider === 'api') {
+    const url = settings.get('embeddings.url');
+    const model = settings.get('embeddings.model');
+    if (!url || !model) {
+      throw new Error('embeddings.provider is "api" but embeddings.url or embeddings.model is not set');
+    }
+    return apiEmbedder(url, model);
+  }
+  throw new Error(`Unknown embeddings.provider "${provider}" (expected local or api)`);
+}
+
+// ── Index ───────────────────────────────────────────────────────────
+
+interface Chunk {
+  typePath: string;
+  source: string;
+  /** sha256 of the chunk text; unchanged chunks keep their vector. */
+  hash: string;
+  preview: string;
+  vector: number[];
+}
+
+interface EmbeddingIndex {
+  embedder: string;
+  builtAt: string;
+  chunks: Chunk[];
+}
+
+export interface SemanticHit {
+  typePath: string;
+  /** Cosine similarity of the best-matching chunk, -1..1. */
+  score: number;
+  source: string;
+  preview: string;
+}
+
+export interface IndexUpdate {
+  chunks: number;
+  embedded: number;
+}
+
+const CHUNK_CHARS = 1200;
+const PREVIEW_CHARS = 160;
+const VECTOR_PRECISION = 1e4;
+
+export function embeddingIndexPath(embedder: Embedder): string {
+  return join(getCacheDir(), 'embeddings', `${embedder.id}.json`);
+}
+
+export function clearEmbeddingIndexes(): void {
+  rmSync(join(getCacheDir(), 'embeddings'), { recursive: true, force: true });
+}
+
+/** Splits on blank lines, packing paragraphs into chunks of about CHUNK_CHARS. */
+export function chunkText(content: string): string[] {
+  const chunks: string[] = [];
+  let current = '';
+  for (const para of content.split(/\n\s*\n/)) {
+    const text = para.trim();
+    if (!text) continue;
+    if (current && current.length + text.length > CHUNK_CHARS) {
+      chunks.push(current);
+      current = '';
+    }
+    current = current ? `${current}\n\n${text}` : text;
+  }
+  if (current) chunks.push(current);
+  return chunks;
+}
+
+function loadIndex(path: string): EmbeddingIndex | null {
+  try {
+    return JSON.parse(readFileSync(path, 'utf-8')) as EmbeddingIndex;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Brings the index in line with installed context. Only chunks whose
+ * text changed since the last run are embedded again.
+ */
+export async function updateEmbeddingIndex(
+  embedder: Embedder = configuredEmbedder(),
+  opts: { full?: boolean; installedRoot?: string } = {},
+): Promise<IndexUpdate> {
+  const path = embeddingIndexPath(embedder);
+  const previous = opts.full ? null : loadIndex(path);
+  const known = new Map(previous?.chunks.map((c) => [c.hash, c.vector]));
+
+  const pending: Omit<Chunk, 'vector'>[] = [];
+  const missing = new Map<string, string>(); // hash -> text
+  for (const file of installedContextFiles(opts.installedRoot ?? getInstalledRoot())) {
+    for (const text of chunkText(file.content)) {
+      const hash = createHash('sha256').update(text).digest('hex');
+      pending.push({
+        typePath: file.typePath,
+        source: file.source,
+        hash,
+        preview: text.replace(/\s+/g, ' ').slice(0, PREVIEW_CHARS),
+      });
+      if (!known.has(hash)) missing.set(hash, text);
+    }
+  }
+
+  const hashes = [...missing.keys()];
+  const vectors = hashes.length > 0 ? await embedder.embed([...missing.values()]) : [];
+  hashes.forEach((hash, i) => {
+    known.set(hash, vectors[i].map((x) => Math.round(x * VECTOR_PRECISION) / VECTOR_PRECISION));
+  });
+
+  const index: EmbeddingIndex = {
+    embedder: embedder.id,
+    builtAt: new Date().toISOString(),
+    chunks: pending.map((c) => ({ ...c, vector: known.get(c.hash)! })),
+  };
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, JSON.stringify(index));
+  return { chunks: index.chunks.length, embedded: hashes.length };
+}
+
+function dot(a: number[], b: number[]): number {
+  let sum = 0;
+  for (let i = 0; i < Math.min(a.length, b.length); i++) sum += a[i] * b[i];
+  return sum;
+}
+
+/**
+ * Context types ranked by their best chunk's similarity to the query.
+ * Updates the index first, so results reflect what is installed now.
+ */
+export async function searchSemantic(
+  query: string,
+  opts: { limit?: number; embedder?: Embedder; reindex?: boolean; installedRoot?: string } = {},
+): Promise<SemanticHit[]> {
+  const embedder = opts.embedder ?? configuredEmbedder();
+  await updateEmbeddingIndex(embedder, { full: opts.reindex, installedRoot: opts.installedRoot });
+  const index = loadIndex(embeddingIndexPath(embedder));
+  if (!index) return [];
+
+  const [q] = await embedder.embed([query]);
+  const best = new Map<string, SemanticHit>();
+  for (const chunk of index.chunks) {
+    const score = dot(q, chunk.vector);
+    const current = best.get(chunk.typePath);
+    if (!current || score > current.score) {
+      best.set(chunk.typePath, {
+        typePath: chunk.typePath,
+        score,
+        source: chunk.source,
+        preview: chunk.preview,
+      });
+    }
+  }
+
+  return [...best.values()]
+    .filter((h) => h.score > 0)
+    .sort((a, b) => b.score - a.score)
+    .slice(0, opts.limit ?? 10);
+}
@@ -0,0 +1,59 @@
+// ── Error codes ─────────────────────────────────────────────────────
+//
+// Errors users are expected to act on carry a stable code, so scripts
+// can tell them apart without matching message text, and support can
+// ask for the code instead of a screenshot. Codes are AGX-<AREA>-<NNN>:
+//
+//   REG   type paths, pins, and installed types
+//   LNK   projects and linking
+//   EXT   extensions
+//   RUN   running skills
+//
+// A code is never reused for a different error; retired codes stay in
+// ERROR_CODES so old reports still mean something. The CLI prints the
+// code with a hint to look it up (ui/output.ts failError).
+
+export const ERROR_CODES = {
+  'AGX-REG-001': 'unknown-type-prefix',
+  'AGX-REG-002': 'invalid-pin',
+  'AGX-REG-003': 'type-not-installed',
+  'AGX-REG-004': 'type-not-found',
+  'AGX-LNK-001': 'already-linked',
+  'AGX-LNK-002': 'not-linked',
+  'AGX-LNK-003': 'project-not-initialized',
+  'AGX-EXT-001': 'host-not-allowed',
+  'AGX-RUN-001': 'unsupported-runtime',
+  'AGX-RUN-002': 'unknown-runtime',
+  'AGX-RUN-003': 'entry-point-not-found',
+  'AGX-RUN-004': 'output-schema-mismatch',
+} as const;
+
+export type ErrorCode = keyof typeof ERROR_CODES;
+
+export class AgentxError extends Error {
+  constructor(
+    readonly code: ErrorCode,
+    message: string,
+  ) {
+    super(message);
+    this.name = 'AgentxError';
+  }
+
+  /** The short name for the code, e.g. type-not-found. */
+  get slug(): string {
+    return ERROR_CODES[this.code];
+  }
+
+  toString(): string {
+    return `${this.code}: ${this.message}`;
+  }
+}
+
+export function isAgentxError(err: unknown): err is AgentxError {
+  return err instanceof AgentxError;
+}
+
+/** The code of an AgentxError, else null. */
+export function errorCode(err: unknown): ErrorCode | null {
+  return isAgentxError(err) ? err.code : null;
+}
@@ -0,0 +1,157 @@
+import { join } from 'node:path';
+import { existsSync, readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { runSkill, type OutputListener } from './runtime.js';
+import { runPublish, type StepOutput } from './publish.js';
+import { guardManifest } from './integrity.js';
+import { runHooks } from './hooks.js';
+import { mergeInputs, normalizeInputs, validateInputs } from '../utils/input-parser.js';
+import { resolveStepInputs, workflowIssues } from './workflow-inputs.js';
+import { logger } from '../utils/log.js';
+import type { InputField, SkillManifest, WorkflowManifest } from '../types/manifest.js';
+
+const log = logger('executor');
+
+// ── Non-interactive execution ───────────────────────────────────────
+//
+// Runs an installed skill or workflow without a terminal, for callers
+// such as `agentx serve http`. It behaves like `agentx run` with prompts
+// disabled: missing tokens are left for the skill to report, a modified
+// manifest is refused unless run.manifest_guard allows it, and project
+// hooks run only when already approved.
+
+export interface ExecuteOptions {
+  onOutput?: OutputListener;
+  /** Called as each workflow step finishes. */
+  onStep?: (step: StepResult) => void;
+}
+
+export interface StepResult {
+  id: string;
+  exitCode: number;
+  /** The destination of a publish step. */
+  published?: string;
+}
+
+export interface ExecuteResult {
+  type: 'skill' | 'workflow';
+  exitCode: number;
+  stdout: string;
+  stderr: string;
+  steps: StepResult[];
+}
+
+/** The manifest file of an installed type, or null. */
+export function findManifest(dir: string): string | null {
+  for (const name of ['manifest.yaml', 'manifest.json', 'skill.yaml', 'workflow.yaml']) {
+    const path = join(dir, name);
+    if (existsSync(path)) return path;
+  }
+  return null;
+}
+
+/** The inputs an installed skill or workflow declares; none when it can't be read. */
+export function inputSchema(typePath: string, installedRoot: string): InputField[] {
+  const manifestPath = findManifest(join(installedRoot, typePath));
+  if (!manifestPath) return [];
+  try {
+    return (yaml.load(readFileSync(manifestPath, 'utf-8')) as { inputs?: InputField[] } | null)?.inputs ?? [];
+  } catch {
+    return [];
+  }
+}
+
+interface Runnable {
+  manifest: SkillManifest | WorkflowManifest;
+  /** The directory of the version that runs. */
+  dir: string;
+}
+
+/**
+ * Loads the version of an installed type that `agentx run` would pick
+ * for the current project (its pin, else the default) and applies the
+ * manifest guard to that version.
+ */
+async function loadRunnable(typePath: string, installedRoot: string): Promise<Runnable> {
+  // versions.ts imports this module; load it lazily to avoid the cycle
+  const { selectVersion } = await import('./versions.js');
+  const selected = selectVersion(process.cwd(), installedRoot, typePath);
+  const dir = selected.dir;
+  if (!existsSync(dir)) throw new Error(`Type not installed: ${typePath}`);
+  const manifestPath = findManifest(dir);
+  if (!manifestPath) throw new Error(`No manifest found in: ${dir}`);
+
+  guardManifest(typePath, installedRoot, selected.version ? { dir, version: selected.version } : undefined);
+  return { manifest: yaml.load(readFileSync(manifestPath, 'utf-8')) as SkillManifest | WorkflowManifest, dir };
+}
+
+async function execSkill(
+  typePath: string,
+  dir: string,
+  manifest: SkillManifest,
+  inputs: Record<string, string>,
+  opts: ExecuteOptions,
+): Promise<{ exitCode: number; stdout: string; stderr: string }> {
+  inputs = normalizeInputs(inputs, manifest.inputs);
+  const errors = validateInputs(inputs, manifest.inputs ?? []);
+  if (errors.length > 0) throw new Error(errors.join('; '));
+
+  const hookContext = { typePaths: [typePath], projectPath: process.cwd() };
+  await runHooks('pre-run', hookContext);
+  const result = await runSkill(dir, manifest, inputs, {}, { onOutput: opts.onOutput });
+  const status = result.exitCode === 0 ? 'ok' : 'failed';
+  const hooks = await runHooks('post-run', { ...hookContext, status, exitCode: result.exitCode });
+  for (const w of hooks.warnings) log.warn(w);
+  return result;
+}
+
+/** Runs an installed skill or workflow; a workflow stops at its first failing step. */
+export async function executeType(
+  typePath: string,
+  inputs: Record<string, string>,
+  installedRoot: string,
+  opts: ExecuteOptions = {},
+): Promise<ExecuteResult> {
+  const { manifest, dir } = await loadRunnable(typePath, installedRoot);
+  if (manifest.type === 'skill') {
+    const result = await execSkill(typePath, dir, manifest as SkillManifest, inputs, opts);
+    return { type: 'skill', ...result, steps: [] };
+  }
+  if (manifest.type !== 'workflow') {
+    throw new Error(`Cannot run type: ${manifest.type}. Only skills and workflows are runnable.`);
+  }
+
+  const workflow = manifest as WorkflowManifest;
+  const issues = workflowIssues(workflow);
+  if (issues.length > 0) {
+    throw new Error(`Invalid workflow ${typePath}: ${issues.map((i) => `${i.path.join('.')}: ${i.message}`).join('; ')}`);
+  }
+  inputs = mergeInputs({ flags: inputs }, workflow.inputs);
+  const errors = validateInputs(inputs, workflow.inputs ?? []);
+  if (errors.length > 0) throw new Error(errors.join('; '));
+  const result: ExecuteResult = { type: 'workflow', exitCode: 0, stdout: '', stderr: '', steps: [] };
+  const outputs = new Map<string, StepOutput>();
+  for (const step of workflow.steps) {
+    let done: StepResult;
+    if ('publish' in step) {
+      const published = await runPublish(step.publish, { workflow: workflow.name, inputs, outputs });
+      outputs.set(step.id, { stdout: published.destination });
+      done = { id: step.id, exitCode: 0, published: published.destination };
+    } else {
+      const stepInputs = resolveStepInputs(step.inputs, inputs, outputs);
+      const skill = await loadRunnable(step.skill, installedRoot);
+      const out = await execSkill(step.skill, skill.dir, skill.manifest as SkillManifest, { ...inputs, ...stepInputs }, opts);
+      result.stdout += out.stdout;
+      result.stderr += out.stderr;
+      outputs.set(step.id, { stdout: out.stdout });
+      done = { id: step.id, exitCode: out.exitCode };
+    }
+    result.steps.push(done);
+    opts.onStep?.(done);
+    if (done.exitCode !== 0) {
+      result.exitCode = done.exitCode;
+      break;
+    }
+  }
+  return result;
+}
@@ -0,0 +1,230 @@
+import { ERROR_CODES, type ErrorCode } from './errors.js';
+import { suggestTypePaths } from './registry.js';
+
+// ── Explanations ────────────────────────────────────────────────────
+//
+// `agentx explain <code|topic>` prints what an error code means and how
+// to fix it, or what a core concept is, from text compiled into the CLI
+// so it works offline and matches the installed version. Every code in
+// core/errors.ts must have an entry here (CODE_EXPLANATIONS is keyed by
+// ErrorCode, so a missing one fails to compile). A code's slug
+// (type-not-found) finds it too.
+
+export interface Explanation {
+  /** The code or topic name. */
+  id: string;
+  title: string;
+  /** Paragraphs of explanation. */
+  body: string[];
+  /** Suggested fixes, most likely first. */
+  fixes: string[];
+  /** Related codes, topics, and commands. */
+  see: string[];
+}
+
+type Entry = Omit<Explanation, 'id'>;
+
+const CODE_EXPLANATIONS: Record<ErrorCode, Entry> = {
+  'AGX-REG-001': {
+    title: 'Unknown type prefix',
+    body: [
+      'Type paths start with the category directory they live in: personas/, context/, skills/, workflows/, or prompts/.',
+      'The path given starts with something else, often a singular form (skill/) or a name without its category.',
+    ],
+    fixes: [
+      'Write the full type path, e.g. skills/scm/git/commit-analyzer.',
+      'Run `agentx search <name>` to find the exact path.',
+    ],
+    see: ['registry', 'linking'],
+  },
+  'AGX-REG-002': {
+    title: 'Pin without a version',
+    body: [
+      'A type path followed by @ pins it to a version (skills/x@1.4.2). Nothing followed the @, so there is no version to pin.',
+    ],
+    fixes: [
+      'Add the version after @, or drop the @ to use the installed default.',
+      'Run `agentx versions list <type-path>` to see the versions available.',
+    ],
+    see: ['linking'],
+  },
+  'AGX-REG-003': {
+    title: 'Type not installed',
+    body: [
+      'Only installed types can be linked into a project. The type exists in no directory under ~/.agentx/installed/.',
+    ],
+    fixes: [
+      'Run `agentx install <type-path>`, then link it again.',
+      'If the path is close to an installed one, use the suggestion printed with the error.',
+    ],
+    see: ['registry', 'linking', 'AGX-REG-004'],
+  },
+  'AGX-REG-004': {
+    title: 'Type not found',
+    body: [
+      'The type path names nothing installed, so there is nothing to remove or read.',
+    ],
+    fixes: [
+      'Run `agentx list` to see installed types and their exact paths.',
+      'Run `agentx search <name>` if the type was never installed.',
+    ],
+    see: ['registry', 'AGX-REG-003'],
+  },
+  'AGX-LNK-001': {
+    title: 'Type already linked',
+    body: [
+      'The project already links this type, at the same pin if one was given, so there is nothing to add.',
+    ],
+    fixes: [
+      'Give a version (`agentx link add <type-path>@<version>`) to change its pin.',
+      'Run `agentx link sync` if the tool files look out of date.',
+    ],
+    see: ['linking'],
+  },
+  'AGX-LNK-002': {
+    title: 'Type not linked',
+    body: [
+      'The project does not link this type, so it cannot be removed. Links are listed under active: in .agentx/project.yaml.',
+    ],
+    fixes: ['Run `agentx link status` to see what the project links, with the exact paths.'],
+    see: ['linking'],
+  },
+  'AGX-LNK-003': {
+    title: 'Not an agentx project',
+    body: [
+      'Project commands read .agentx/project.yaml in the current directory, and there is none.',
+    ],
+    fixes: [
+      'Run the command from the project root.',
+      'Run `agentx init` to set up the project first.',
+    ],
+    see: ['linking'],
+  },
+  'AGX-EXT-001': {
+    title: 'Extension host not allowed',
+    body: [
+      'The extensions.allowed_hosts setting limits where extensions may be added from, and the URL is on another host.',
+      'Organizations set this to keep extensions to their own Git servers.',
+    ],
+    fixes: [
+      'Use a mirror of the extension on an allowed host.',
+      'Ask whoever manages your config.yaml to allow the host (`agentx config get extensions.allowed_hosts`).',
+    ],
+    see: ['registry'],
+  },
+  'AGX-RUN-001': {
+    title: 'Runtime not supported yet',
+    body: [
+      'The skill declares a runtime this version of agentx cannot run yet. Node and Python skills are supported.',
+    ],
+    fixes: ['Check for an agentx update (`agentx update --check`).', 'Use a version of the skill with a supported runtime.'],
+    see: ['AGX-RUN-002'],
+  },
+  'AGX-RUN-002': {
+    title: 'Unknown runtime',
+    body: ['runtime: in skill.yaml is not one agentx knows. It must be node or python.'],
+    fixes: ['Fix runtime: in the skill manifest; `agentx validate <skill-dir>` checks it.'],
+    see: ['AGX-RUN-001'],
+  },
+  'AGX-RUN-003': {
+    title: 'Skill entry point not found',
+    body: [
+      'Node skills start from index.mjs and Python skills from main.py in the skill directory. The file is missing, often because the skill was not built before it was packed or installed.',
+    ],
+    fixes: [
+      'Build the skill (`make build` in its source) and install it again.',
+      'Run `agentx verify <type-path>` to see whether installed files were removed.',
+    ],
+    see: ['skill-registry'],
+  },
+  'AGX-RUN-004': {
+    title: 'Skill output does not match its schema',
+    body: [
+      "The skill exited successfully, but its output doesn't parse or doesn't match the JSON Schema in its manifest's outputs.schema. The run fails because run.output_schema is set to fail; the violations are listed on stderr, with paths like items.0.id.",
+    ],
+    fixes: [
+      'Fix the skill so its output matches the schema, or update the schema if the output is right.',
+      'Set run.output_schema to warn to report mismatches without failing the run.',
+    ],
+    see: [],
+  },
+};
+
+const TOPICS: Record<string, Entry> = {
+  registry: {
+    title: 'The type registry',
+    body: [
+      'Types (personas, context, skills, workflows, prompts) come from sources: the catalog and any extensions. When more than one source provides a type path, the one earliest in resolution order wins, unless a prefer: pin in project.yaml names another.',
+      'Installing copies a type from its source into ~/.agentx/installed/<type-path>, and records its files so later edits can be detected.',
+    ],
+    fixes: [],
+    see: ['`agentx which <type-path>`', '`agentx extension list`', 'linking'],
+  },
+  profile: {
+    title: 'Profiles',
+    body: [
+      'A profile is a named set of user-wide values, such as a cloud account, region, and Git organization, in userdata/profiles/<name>.yaml. The active profile is the one the profiles/active link points at.',
+      'Profiles let one machine switch between contexts like work and personal without editing every skill.',
+    ],
+    fixes: [],
+    see: ['`agentx profile list`', '`agentx profile use <name>`', 'skill-registry'],
+  },
+  'skill-registry': {
+    title: 'Skill registries',
+    body: [
+      'Each installed skill gets a registry under userdata/skills/<type-path>/: its tokens (tokens.env, and tokens.<account>.env per account), config.yaml, persisted state/, and output/ with run history.',
+      'Registries belong to the user, not the skill, so they survive upgrades; new tokens and config keys are appended on install, and nothing is deleted.',
+    ],
+    fixes: [],
+    see: ['`agentx env edit <skill>`', '`agentx info <skill>`', 'profile'],
+  },
+  linking: {
+    title: 'Linking',
+    body: [
+      'A project links installed types under active: in .agentx/project.yaml. `agentx link sync` then writes the files each configured AI tool reads, such as CLAUDE.md or copilot-instructions.md, from the linked types.',
+      'A link can pin a version (skills/x@1.4.2); otherwise the installed default is used.',
+    ],
+    fixes: [],
+    see: ['`agentx link status`', '`agentx link sync --dry-run`', 'registry'],
+  },
+};
+
+const TOPIC_ALIASES: Record<string, string> = {
+  registries: 'registry',
+  profiles: 'profile',
+  'skill registry': 'skill-registry',
+  'skill-registries': 'skill-registry',
+  link: 'linking',
+  links: 'linking',
+};
+
+const bySlug = new Map(Object.entries(ERROR_CODES).map(([code, slug]) => [slug as string, code as ErrorCode]));
+
+/** The explanation for a code, a code's slug, or a topic; null when there is none. */
+export function explain(query: string): Explanation | null {
+  const q = query.trim();
+  const code = (q.toUpperCase() in CODE_EXPLANATIONS ? q.toUpperCase() : bySlug.get(q.toLowerCase())) as
+    | ErrorCode
+    | undefined;
+  if (code) return { id: code, ...CODE_EXPLANATIONS[code] };
+
+  const lower = q.toLowerCase();
+  const topic = TOPIC_ALIASES[lower] ?? lower;
+  return topic in TOPICS ? { id: topic, ...TOPICS[topic] } : null;
+}
+
+/** Every code and topic, codes first. */
+export function explanations(): Explanation[] {
+  return [
+    ...Object.entries(CODE_EXPLANATIONS).map(([id, e]) => ({ id, ...e })),
+    ...Object.entries(TOPICS).map(([id, e]) => ({ id, ...e })),
+  ];
+}
+
+/** Close codes, slugs, and topics for a query explain() didn't find. */
+export function suggestExplanations(query: string): string[] {
+  const ids = [...Object.keys(CODE_EXPLANATIONS), ...bySlug.keys(), ...Object.keys(TOPICS)];
+  return suggestTypePaths(query.toLowerCase(), ids.map((id) => id.toLowerCase())).map(
+    (id) => ids.find((i) => i.toLowerCase() === id) ?? id,
+  );
+}
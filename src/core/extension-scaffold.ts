@@ -0,0 +1,160 @@
+import { join, dirname } from 'node:path';
+import { existsSync, readdirSync, readFileSync, mkdirSync, writeFileSync, chmodSync } from 'node:fs';
+import { simpleGit } from 'simple-git';
+import { ExtensionManifestSchema } from '../config/schema.js';
+import { APP_NAME, NPM_PACKAGE } from '../config/branding.js';
+import { requireGit } from '../utils/git.js';
+import { generate, newScaffoldData } from './scaffold.js';
+import { checkAllowedHost } from './extension.js';
+import { requireOnline } from './offline.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('extension');
+
+// ── Extension scaffolding ───────────────────────────────────────────
+//
+// `extension create <name>` lays out a new extension repository:
+//
+//   extension.yaml                      name, description, merge rules
+//   README.md                           how to add and develop it
+//   context/<name>/getting-started/     sample context
+//   personas/<name>-developer/          sample persona using it
+//   scripts/lint-manifests.sh           validates every manifest
+//   .github/workflows/lint.yml          runs the script in CI
+//
+// The sample types come from the built-in template sets, so they are as
+// valid as what `create` produces. With a remote, the repository is
+// committed and pushed.
+
+export interface CreateExtensionOptions {
+  description?: string;
+  /** Git URL to push the new repository to. */
+  remote?: string;
+  branch?: string;
+}
+
+export interface CreateExtensionResult {
+  outputDir: string;
+  files: string[];
+  warnings: string[];
+  pushed: boolean;
+}
+
+function extensionYaml(name: string, description: string): string {
+  return [
+    `name: ${name}`,
+    `description: ${JSON.stringify(description)}`,
+    '# Types this extension merges with a source found earlier (the catalog):',
+    '#   append (context only) adds our content after it; override replaces it.',
+    '# merge:',
+    '#   context/spring-boot/security: append',
+    '',
+  ].join('\n');
+}
+
+function readme(name: string, description: string, remote: string | undefined): string {
+  return `# ${name}
+
+${description}
+
+An extension for [${APP_NAME}](https://www.npmjs.com/package/${NPM_PACKAGE}). Types live in the
+same directories as the catalog: \`context/\`, \`personas/\`, \`skills/\`, \`workflows/\`,
+\`prompts/\` and \`templates/\`.
+
+## Using it
+
+\`\`\`bash
+${APP_NAME} extension add ${name} ${remote ?? '<git-url>'}
+${APP_NAME} install context/${name}/getting-started
+\`\`\`
+
+## Adding types
+
+\`\`\`bash
+${APP_NAME} create context <topic> --output-dir context/${name}/<topic>
+${APP_NAME} create persona <role> --output-dir personas/<role>
+\`\`\`
+
+Run \`scripts/lint-manifests.sh\` before committing; CI runs it on every push.
+`;
+}
+
+const LINT_SCRIPT = `#!/bin/sh
+# Validates every type in this extension: schemas (rejecting unknown
+# fields), references, duplicate paths, and extension.yaml.
+set -e
+cd "$(dirname "$0")/.."
+npx --yes ${NPM_PACKAGE} validate .
+`;
+
+const LINT_WORKFLOW = `name: Lint manifests
+on: [push, pull_request]
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v4
+        with:
+          node-version: 22
+      - run: sh scripts/lint-manifests.sh
+`;
+
+/** Scaffolds an extension repository in outputDir, which must be empty or absent. */
+export async function createExtension(
+  name: string,
+  outputDir: string,
+  opts: CreateExtensionOptions = {},
+): Promise<CreateExtensionResult> {
+  const description = opts.description ?? `${name} types for ${APP_NAME}`;
+  const parsed = ExtensionManifestSchema.safeParse({ name, description });
+  if (!parsed.success) {
+    throw new Error(`Invalid extension name "${name}": ${parsed.error.issues[0].message}`);
+  }
+  if (existsSync(outputDir) && readdirSync(outputDir).length > 0) {
+    throw new Error(`Output directory is not empty: ${outputDir}`);
+  }
+  if (opts.remote) {
+    checkAllowedHost(opts.remote);
+    requireGit('Pushing a new extension');
+    requireOnline('Pushing a new extension');
+  }
+
+  const result: CreateExtensionResult = { outputDir, files: [], warnings: [], pushed: false };
+  const write = (rel: string, content: string) => {
+    mkdirSync(dirname(join(outputDir, rel)), { recursive: true });
+    writeFileSync(join(outputDir, rel), content);
+    result.files.push(rel);
+  };
+
+  write('extension.yaml', extensionYaml(name, description));
+  write('README.md', readme(name, description, opts.remote));
+  write('scripts/lint-manifests.sh', LINT_SCRIPT);
+  chmodSync(join(outputDir, 'scripts/lint-manifests.sh'), 0o755);
+  write('.github/workflows/lint.yml', LINT_WORKFLOW);
+  write('.gitignore', 'node_modules/\n.DS_Store\n');
+
+  const contextPath = `context/${name}/getting-started`;
+  const personaPath = `personas/${name}-developer`;
+  for (const [typeName, typePath] of [['context', contextPath], ['persona', personaPath]]) {
+    const data = newScaffoldData(typePath.split('/').pop()!, typeName, name, '', '');
+    const generated = generate(typeName, data, join(outputDir, typePath));
+    result.files.push(...generated.files.map((f) => `${typePath}/${f}`));
+    result.warnings.push(...generated.warnings);
+  }
+  const personaManifest = join(outputDir, personaPath, 'persona.yaml');
+  writeFileSync(personaManifest, readFileSync(personaManifest, 'utf-8').replace('context: []', `context:\n  - ${contextPath}`));
+
+  if (opts.remote) {
+    const branch = opts.branch ?? 'main';
+    const git = simpleGit(outputDir);
+    await git.init(['--initial-branch', branch]);
+    await git.add('-A');
+    await git.commit(`Scaffold ${name} extension`);
+    await git.addRemote('origin', opts.remote);
+    await git.push(['-u', 'origin', branch]);
+    result.pushed = true;
+  }
+  log.info('created', { name, dir: outputDir, pushed: result.pushed });
+  return result;
+}
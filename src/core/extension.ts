@@ -1,14 +1,69 @@
 import { join } from 'node:path';
-import { existsSync, rmSync } from 'node:fs';
+import { existsSync, rmSync, readFileSync, writeFileSync, renameSync, readdirSync } from 'node:fs';
 import { simpleGit } from 'simple-git';
+import yaml from 'js-yaml';
 import type { Source } from '../types/registry.js';
-import { getExtensionsRoot, detectMode } from './userdata.js';
+import type { ExtensionManifest } from '../types/manifest.js';
+import type { CapabilityKind } from '../config/schema.js';
+import { ExtensionManifestSchema } from '../config/schema.js';
+import { getExtensionsRoot, getCatalogRoot, detectMode } from './userdata.js';
+import { spawnSync } from 'node:child_process';
+import { ensureTrusted, isProjectExtensionTrusted, type Contribution } from './trust.js';
+import { APP_NAME, envVar } from '../config/branding.js';
+import { hasGit, requireGit, remoteUrl } from '../utils/git.js';
+import { downloadArchive } from '../utils/archive.js';
+import { mirrorUrl, bundleUrl, fetchBundle } from './mirror.js';
+import { isOffline, offlineSkip, requireOnline } from './offline.js';
+import { authorizationFor } from './credentials.js';
+import * as settings from '../config/settings.js';
+import { projectConfigPath, projectExtensionsDir, loadProject, type ProjectConfig } from './linker.js';
+import { logger } from '../utils/log.js';
+import { AgentxError } from './errors.js';
+import { forgetIndexedSource } from './registry.js';
+
+const log = logger('extension');
+
+const EXTENSION_MANIFEST = 'extension.yaml';
+// Written into extensions installed from an archive (no git), so sync
+// knows where to re-download them from.
+const ARCHIVE_MARKER = '.agentx-archive.json';
+
+interface ArchiveMarker {
+  url: string;
+  branch: string;
+  /** Set when downloaded from a mirror, which sync fetches from again. */
+  bundle?: string;
+}
 
 export interface ExtensionStatus {
   name: string;
   path: string;
   branch: string;
-  status: string; // 'ok' | 'uninitialized' | 'modified' | 'missing'
+  status: string; // 'ok' | 'uninitialized' | 'modified' | 'missing' | 'archive'
+}
+
+/** Host of an https or scp-style (git@host:org/repo) git URL. */
+function gitHost(gitURL: string): string {
+  const scp = /^[\w.-]+@([\w.-]+):/.exec(gitURL);
+  if (scp) return scp[1];
+  try {
+    return new URL(gitURL).hostname;
+  } catch {
+    return '';
+  }
+}
+
+/** Rejects URLs whose host is not in extensions.allowed_hosts, when it is set. */
+export function checkAllowedHost(gitURL: string): void {
+  const allowed = settings.getList('extensions.allowed_hosts');
+  if (allowed.length === 0) return;
+  const host = gitHost(gitURL);
+  if (!allowed.includes(host)) {
+    throw new AgentxError(
+      'AGX-EXT-001',
+      `Extensions may only be added from ${allowed.join(', ')} (extensions.allowed_hosts); ${gitURL} is on ${host || 'an unknown host'}`,
+    );
+  }
 }
 
 export async function addExtension(
@@ -17,8 +72,12 @@ export async function addExtension(
   gitURL: string,
   branch = 'main',
 ): Promise<void> {
+  checkAllowedHost(gitURL);
+  requireOnline('Adding an extension');
   const mode = detectMode();
+  log.info('add', { name, branch, mode });
   if (mode === 'platform-team') {
+    requireGit('Adding an extension in platform-team mode (git submodules)');
     const git = simpleGit(repoRoot);
     const extPath = join('extensions', name);
     await git.submoduleAdd(gitURL, extPath);
@@ -28,6 +87,20 @@ export async function addExtension(
     }
   } else {
     const extDir = join(getExtensionsRoot(), name);
+    const mirror = mirrorUrl();
+    if (mirror) {
+      const bundle = bundleUrl(mirror, 'extensions', branch, name);
+      await fetchBundle(bundle, extDir);
+      const marker: ArchiveMarker = { url: gitURL, branch, bundle };
+      writeFileSync(join(extDir, ARCHIVE_MARKER), JSON.stringify(marker, null, 2));
+      return;
+    }
+    if (!hasGit()) {
+      await downloadArchive(gitURL, branch, extDir, authorizationFor(gitURL));
+      const marker: ArchiveMarker = { url: gitURL, branch };
+      writeFileSync(join(extDir, ARCHIVE_MARKER), JSON.stringify(marker, null, 2));
+      return;
+    }
     const git = simpleGit();
     await git.clone(gitURL, extDir, ['--branch', branch, '--depth', '1']);
   }
@@ -39,6 +112,7 @@ export async function removeExtension(
 ): Promise<void> {
   const mode = detectMode();
   if (mode === 'platform-team') {
+    requireGit('Removing an extension in platform-team mode (git submodules)');
     const git = simpleGit(repoRoot);
     const extPath = join('extensions', name);
     await git.raw(['submodule', 'deinit', '-f', extPath]);
@@ -53,6 +127,30 @@ export async function removeExtension(
       rmSync(extDir, { recursive: true });
     }
   }
+
+  // The index is a cache; a failure here only leaves rows that the next
+  // discovery prunes, as the extension's directory is gone
+  try {
+    forgetIndexedSource(name);
+  } catch (err) {
+    log.warn('could not drop the extension from the registry index', { name, error: String(err) });
+  }
+}
+
+/** Where an extension was fetched from: its archive URL or git remote. */
+export function extensionSourceUrl(repoRoot: string, name: string): string | null {
+  const dirs = [join(getExtensionsRoot(), name), join(repoRoot, 'extensions', name)];
+  for (const dir of dirs) {
+    const markerPath = join(dir, ARCHIVE_MARKER);
+    if (existsSync(markerPath)) {
+      return (JSON.parse(readFileSync(markerPath, 'utf-8')) as ArchiveMarker).url;
+    }
+    if (existsSync(dir)) {
+      const url = remoteUrl(dir);
+      if (url) return url;
+    }
+  }
+  return null;
 }
 
 export async function listExtensions(
@@ -62,6 +160,7 @@ export async function listExtensions(
   const results: ExtensionStatus[] = [];
 
   if (mode === 'platform-team') {
+    requireGit('Listing extensions in platform-team mode (git submodules)');
     const git = simpleGit(repoRoot);
     try {
       const output = await git.raw(['submodule', 'status']);
@@ -88,28 +187,80 @@ export async function listExtensions(
       if (!entry.isDirectory()) continue;
       const extDir = join(extRoot, entry.name);
       let status = 'ok';
-      if (!existsSync(join(extDir, '.git'))) status = 'missing';
+      if (existsSync(join(extDir, ARCHIVE_MARKER))) status = 'archive';
+      else if (!existsSync(join(extDir, '.git'))) status = 'missing';
       results.push({ name: entry.name, path: extDir, branch: '', status });
     }
   }
   return results;
 }
 
-export async function syncExtensions(repoRoot: string): Promise<void> {
+/**
+ * Pulls every extension. Archive-installed extensions are downloaded
+ * again; git clones on a machine without git are skipped with a warning.
+ */
+export async function syncExtensions(repoRoot: string): Promise<string[]> {
+  if (isOffline()) return [offlineSkip('extension sync')];
   const mode = detectMode();
+  const warnings: string[] = [];
   if (mode === 'platform-team') {
+    requireGit('Syncing extensions in platform-team mode (git submodules)');
     const git = simpleGit(repoRoot);
     await git.raw(['submodule', 'update', '--init', '--recursive']);
   } else {
     const extRoot = getExtensionsRoot();
-    if (!existsSync(extRoot)) return;
+    if (!existsSync(extRoot)) return warnings;
     const { readdirSync } = await import('node:fs');
     for (const entry of readdirSync(extRoot, { withFileTypes: true })) {
       if (!entry.isDirectory()) continue;
-      const extGit = simpleGit(join(extRoot, entry.name));
+      const extDir = join(extRoot, entry.name);
+
+      const markerPath = join(extDir, ARCHIVE_MARKER);
+      if (existsSync(markerPath)) {
+        const marker = JSON.parse(readFileSync(markerPath, 'utf-8')) as ArchiveMarker;
+        log.debug('re-downloading archive', { name: entry.name, branch: marker.branch });
+        const tmpDir = `${extDir}.tmp`;
+        rmSync(tmpDir, { recursive: true, force: true });
+        if (marker.bundle) await fetchBundle(marker.bundle, tmpDir);
+        else await downloadArchive(marker.url, marker.branch, tmpDir, authorizationFor(marker.url));
+        writeFileSync(join(tmpDir, ARCHIVE_MARKER), JSON.stringify(marker, null, 2));
+        rmSync(extDir, { recursive: true });
+        renameSync(tmpDir, extDir);
+        continue;
+      }
+
+      if (!hasGit()) {
+        warnings.push(
+          `Skipped ${entry.name}: it is a git clone and git is not installed ` +
+            '(install git, or remove and re-add the extension to use an archive).',
+        );
+        continue;
+      }
+      log.debug('git pull', { name: entry.name });
+      const extGit = simpleGit(extDir);
       await extGit.pull(['--rebase']);
     }
   }
+  return warnings;
+}
+
+export interface ProjectExtension {
+  name: string;
+  path: string;
+  trusted: boolean;
+}
+
+/** Extensions under the project's .agentx/extensions, and whether each is trusted. */
+export function listProjectExtensions(projectPath: string): ProjectExtension[] {
+  const localRoot = projectExtensionsDir(projectPath);
+  if (!existsSync(localRoot)) return [];
+  return readdirSync(localRoot, { withFileTypes: true })
+    .filter((entry) => entry.isDirectory())
+    .map((entry) => {
+      const path = join(localRoot, entry.name);
+      return { name: entry.name, path, trusted: isProjectExtensionTrusted(path) };
+    })
+    .sort((a, b) => a.name.localeCompare(b.name));
 }
 
 export function buildSources(repoRoot: string): Source[] {
@@ -117,18 +268,16 @@ export function buildSources(repoRoot: string): Source[] {
   const mode = detectMode();
 
   // Catalog source
-  const { getCatalogRoot, getExtensionsRoot: getExtRoot } = require('./userdata.js');
   const catalogRoot = getCatalogRoot();
   if (existsSync(catalogRoot)) {
     sources.push({ name: 'catalog', basePath: catalogRoot });
   }
 
   // Extension sources
-  const extRoot = getExtRoot();
+  const extRoot = getExtensionsRoot();
   if (existsSync(extRoot)) {
     try {
-      const { readdirSync: readdir } = require('node:fs');
-      for (const entry of readdir(extRoot, { withFileTypes: true })) {
+      for (const entry of readdirSync(extRoot, { withFileTypes: true })) {
         if (entry.isDirectory()) {
           sources.push({ name: entry.name, basePath: join(extRoot, entry.name) });
         }
@@ -138,5 +287,166 @@ export function buildSources(repoRoot: string): Source[] {
     }
   }
 
-  return sources;
+  const projectPath = [process.cwd(), repoRoot].find((p) => existsSync(projectConfigPath(p)));
+  // Project-local extensions, e.g. from `agentx import`. They arrive with
+  // a clone, so only ones the user trusted (as they are now) are loaded.
+  for (const ext of projectPath ? listProjectExtensions(projectPath) : []) {
+    if (sources.some((s) => s.name === ext.name)) continue;
+    if (!ext.trusted) {
+      log.warn(
+        `Skipping untrusted project extension ${ext.name} (${ext.path}); ` +
+          `review it and run \`${APP_NAME} extension trust ${ext.name}\` to use it`,
+      );
+      continue;
+    }
+    sources.push({ name: ext.name, basePath: ext.path, local: true });
+  }
+  let project: ProjectConfig | null = null;
+  try {
+    project = projectPath ? loadProject(projectPath) : null;
+  } catch (err) {
+    log.warn('ignoring unreadable project.yaml for source order', { error: String(err) });
+  }
+  return orderSources(sources, project);
+}
+
+// ── Source order ────────────────────────────────────────────────────
+//
+// Sources are consulted highest priority first. In project.yaml, a
+// `resolution:` list gives each source its index as priority (later
+// wins; `core` is the catalog), `extensions[].priority` sets one
+// explicitly, and `prefer:` pins type paths to a source:
+//
+//   extensions:
+//     - name: corp-ext
+//       priority: 20
+//   prefer:
+//     context/security/*: corp-ext
+//
+// Unlisted sources are priority 0. Ties keep the catalog first, then
+// extensions by name, so the order never depends on the filesystem. A
+// pinned source is consulted first for the paths it is pinned to; pins
+// to an untrusted project-local extension are ignored.
+
+export function orderSources(
+  sources: Source[],
+  project: Pick<ProjectConfig, 'resolution' | 'extensions' | 'prefer'> | null,
+): Source[] {
+  const priorities = new Map<string, number>(
+    (project?.resolution ?? []).map((name, i) => [name === 'core' ? 'catalog' : name, i]),
+  );
+  for (const e of project?.extensions ?? []) {
+    if (e.priority !== undefined) priorities.set(e.name, e.priority);
+  }
+  // project.yaml is committed, so a pin may only favor a source the user
+  // trusts: the catalog, an installed extension, or a trusted local one
+  const pins = Object.entries(project?.prefer ?? {}).filter(([glob, name]) => {
+    const source = sources.find((s) => s.name === name);
+    if (!source) {
+      log.warn('prefer names an unknown source', { glob, source: name });
+      return false;
+    }
+    if (source.local && !isProjectExtensionTrusted(source.basePath)) {
+      log.warn('ignoring prefer pin to an untrusted project extension', { glob, source: name });
+      return false;
+    }
+    return true;
+  });
+  const tier = (s: Source) => (s.name === 'catalog' ? 0 : 1);
+  return sources
+    .map((s) => {
+      const pinned = pins.filter(([, name]) => name === s.name).map(([glob]) => glob);
+      return { ...s, priority: priorities.get(s.name) ?? 0, ...(pinned.length ? { pinned } : {}) };
+    })
+    .sort((a, b) => b.priority - a.priority || tier(a) - tier(b) || a.name.localeCompare(b.name));
+}
+
+// ── Capabilities ────────────────────────────────────────────────────
+
+export function loadExtensionManifest(extDir: string): ExtensionManifest | null {
+  const path = join(extDir, EXTENSION_MANIFEST);
+  if (!existsSync(path)) return null;
+  const data = yaml.load(readFileSync(path, 'utf-8'));
+  return ExtensionManifestSchema.parse(data);
+}
+
+/**
+ * Executable contributions declared by extension sources. The catalog is
+ * first-party and never contributes through this path, so only extension
+ * sources are consulted.
+ */
+export function listContributions(
+  sources: Source[],
+  kind?: CapabilityKind,
+): Contribution[] {
+  const results: Contribution[] = [];
+  for (const source of sources) {
+    if (source.name === 'catalog') continue;
+    let manifest: ExtensionManifest | null;
+    try {
+      manifest = loadExtensionManifest(source.basePath);
+    } catch {
+      continue; // Invalid extension.yaml contributes nothing
+    }
+    for (const cap of manifest?.capabilities ?? []) {
+      if (kind && cap.kind !== kind) continue;
+      results.push({
+        extension: source.name,
+        kind: cap.kind,
+        name: cap.name,
+        description: cap.description ?? '',
+        baseDir: source.basePath,
+        path: cap.path,
+        command: cap.command,
+        event: cap.event,
+      });
+    }
+  }
+  return results;
+}
+
+export interface Detection {
+  extension: string;
+  rule: string;
+  /** Type paths or preset names the rule suggests, one per line of its output. */
+  suggestions: string[];
+}
+
+/**
+ * Runs the extensions' detection rules against a project. A rule is a
+ * command, run from its extension's checkout with AGENTX_PROJECT set,
+ * that prints what suits the project; no output means it doesn't
+ * apply. Each rule must be approved before it first runs; declined or
+ * failing rules are reported in warnings and skipped.
+ */
+export async function runDetectionRules(
+  projectPath: string,
+  sources: Source[],
+  approve: (c: Contribution) => Promise<boolean>,
+  warnings: string[] = [],
+): Promise<Detection[]> {
+  const detections: Detection[] = [];
+  for (const rule of listContributions(sources, 'detection')) {
+    if (!rule.command) continue;
+    try {
+      await ensureTrusted(rule, approve);
+    } catch (err) {
+      warnings.push(`Skipped detection rule ${rule.extension}/${rule.name}: ${err instanceof Error ? err.message : String(err)}`);
+      continue;
+    }
+    const proc = spawnSync(rule.command, {
+      cwd: rule.baseDir,
+      env: { ...process.env, [envVar('PROJECT')]: projectPath },
+      shell: true,
+      encoding: 'utf-8',
+      timeout: 30_000,
+    });
+    if (proc.status !== 0) {
+      warnings.push(`Detection rule ${rule.extension}/${rule.name} failed (${proc.error?.message ?? `exit ${proc.status}`})`);
+      continue;
+    }
+    const suggestions = proc.stdout.split('\n').map((l) => l.trim()).filter(Boolean);
+    if (suggestions.length > 0) detections.push({ extension: rule.extension, rule: rule.name, suggestions });
+  }
+  return detections;
 }
@@ -0,0 +1,285 @@
+import { join, relative, sep } from 'node:path';
+import { readdirSync, existsSync, statSync } from 'node:fs';
+import { getCacheDir, getSkillsDir } from './userdata.js';
+import { installedTypePaths, extractDependencies, canonicalTypePath, nameFromPath, removeType } from './registry.js';
+import { removeTree } from './store.js';
+import { findManifest } from './executor.js';
+import { loadProject, projectConfigPath } from './linker.js';
+import { loadKnownProjects } from './projects.js';
+import { loadSchedules } from './schedule.js';
+import { listDaemons } from './daemon.js';
+import { installedVersions, removeVersion } from './versions.js';
+import { nodeCacheDir, nodeCacheKey } from './node-cache.js';
+import { workspaceCacheDir } from './run-cache.js';
+import { compareVersions } from '../utils/version.js';
+import { findRepoRoot } from '../utils/git.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('gc');
+
+// ── Garbage collection ──────────────────────────────────────────────
+//
+// `agentx gc` finds what nothing on this machine uses any more:
+//
+//   type            installed types no known project, schedule, or
+//                   daemon references, directly or as a dependency
+//   version         side-by-side versions no known project pins
+//   node-cache      shared node_modules no installed skill's lockfile uses
+//   run-cache       run caches of other workspaces unused for a while
+//   output-history  archived skill outputs older than the cutoff
+//
+// Known projects come from the projects registry (core/projects.ts).
+// With none recorded every type would look unused, so types and
+// versions are only collected once at least one project is known.
+// Even then a project that was never recorded, or one on a disk that
+// isn't mounted, may still use them, so they are marked `force` and
+// only deleted when the caller asks for it. Skill registries hold
+// tokens and credentials and are never collected.
+
+export const DEFAULT_GC_AGE_MS = 30 * 86_400_000;
+
+export type GcKind = 'type' | 'version' | 'node-cache' | 'run-cache' | 'output-history';
+
+export interface GcItem {
+  kind: GcKind;
+  /** A type path, type@version, or a path under ~/.agentx. */
+  target: string;
+  paths: string[];
+  bytes: number;
+  reason: string;
+  /** Something gc can't see may still use it; only deleted with force. */
+  force?: boolean;
+}
+
+export interface GcPlan {
+  items: GcItem[];
+  bytes: number;
+  projects: number;
+  /** Recorded projects that couldn't be reached, and so weren't checked. */
+  unreachable: string[];
+  /** Why installed types were left out, when they were. */
+  typesSkipped?: string;
+}
+
+export interface GcOptions {
+  /** Run caches and output history older than this are collected. */
+  olderThanMs?: number;
+  now?: Date;
+}
+
+interface TreeStats {
+  bytes: number;
+  newest: number;
+}
+
+function treeStats(path: string): TreeStats {
+  let st;
+  try {
+    st = statSync(path);
+  } catch {
+    return { bytes: 0, newest: 0 };
+  }
+  if (!st.isDirectory()) return { bytes: st.size, newest: st.mtimeMs };
+  const stats = { bytes: 0, newest: st.mtimeMs };
+  for (const entry of readdirSync(path)) {
+    const child = treeStats(join(path, entry));
+    stats.bytes += child.bytes;
+    stats.newest = Math.max(stats.newest, child.newest);
+  }
+  return stats;
+}
+
+function listDirs(dir: string): string[] {
+  try {
+    return readdirSync(dir, { withFileTypes: true })
+      .filter((e) => e.isDirectory())
+      .map((e) => e.name)
+      .sort();
+  } catch {
+    return [];
+  }
+}
+
+// ── References ──────────────────────────────────────────────────────
+
+interface References {
+  /** Types in use, dependencies included. */
+  types: Set<string>;
+  /** Type path → versions pinned by known projects. */
+  pins: Map<string, string[]>;
+}
+
+function collectReferences(installedRoot: string, projects: string[]): References {
+  const roots: string[] = [];
+  const pins = new Map<string, string[]>();
+  for (const project of projects) {
+    try {
+      const config = loadProject(project);
+      roots.push(...Object.values(config.active).flatMap((refs) => refs ?? []));
+      for (const [typePath, version] of Object.entries(config.pins ?? {})) {
+        pins.set(typePath, [...(pins.get(typePath) ?? []), version]);
+      }
+    } catch (err) {
+      log.warn('unreadable project', { project, error: String(err) });
+    }
+  }
+  roots.push(...loadSchedules().map((s) => s.typePath));
+  roots.push(...listDaemons().map((d) => d.typePath));
+
+  const types = new Set<string>();
+  const queue = roots.map((ref) => canonicalTypePath(ref, installedRoot));
+  while (queue.length > 0) {
+    const typePath = queue.pop()!;
+    if (types.has(typePath)) continue;
+    types.add(typePath);
+    // Every installed version, since a pinned one may depend on other types
+    for (const v of installedVersions(installedRoot, typePath)) {
+      const manifestPath = findManifest(v.path);
+      if (!manifestPath) continue;
+      for (const dep of extractDependencies(manifestPath)) queue.push(canonicalTypePath(dep, installedRoot));
+    }
+  }
+  return { types, pins };
+}
+
+// ── Planning ────────────────────────────────────────────────────────
+
+function typeItems(installedRoot: string, installed: string[], refs: References): GcItem[] {
+  const items: GcItem[] = [];
+  for (const typePath of installed) {
+    const versions = installedVersions(installedRoot, typePath);
+    if (!refs.types.has(typePath)) {
+      const paths = versions.map((v) => v.path);
+      const bytes = paths.reduce((sum, p) => sum + treeStats(p).bytes, 0);
+      items.push({ kind: 'type', target: typePath, paths, bytes, reason: 'not used by any known project', force: true });
+      continue;
+    }
+    const pinned = refs.pins.get(typePath) ?? [];
+    for (const v of versions) {
+      if (v.default || pinned.some((p) => compareVersions(p, v.version) === 0)) continue;
+      items.push({
+        kind: 'version',
+        target: `${typePath}@${v.version}`,
+        paths: [v.path],
+        bytes: treeStats(v.path).bytes,
+        reason: 'not pinned by any known project',
+        force: true,
+      });
+    }
+  }
+  return items;
+}
+
+function nodeCacheItems(installedRoot: string, kept: string[]): GcItem[] {
+  const keys = new Set<string>();
+  for (const typePath of kept) {
+    for (const v of installedVersions(installedRoot, typePath)) {
+      if (!existsSync(join(v.path, 'package.json'))) continue;
+      try {
+        const key = nodeCacheKey(v.path);
+        if (key) keys.add(key);
+      } catch {
+        // An unreadable package.json; its skill can't be using the cache
+      }
+    }
+  }
+  return listDirs(nodeCacheDir())
+    .filter((name) => !keys.has(name))
+    .map((name): GcItem => {
+      const path = join(nodeCacheDir(), name);
+      const reason = name.includes('.partial-') ? 'left by an interrupted install' : 'no installed skill uses it';
+      return { kind: 'node-cache', target: relative(getCacheDir(), path).split(sep).join('/'), paths: [path], bytes: treeStats(path).bytes, reason };
+    });
+}
+
+function runCacheItems(projects: string[], cutoff: number): GcItem[] {
+  const runsDir = join(getCacheDir(), 'runs');
+  const live = new Set(projects.map((p) => workspaceCacheDir(findRepoRoot(p) ?? p)));
+  const items: GcItem[] = [];
+  for (const name of listDirs(runsDir)) {
+    const path = join(runsDir, name);
+    if (live.has(path)) continue;
+    const stats = treeStats(path);
+    if (stats.newest >= cutoff) continue;
+    items.push({ kind: 'run-cache', target: `cache/runs/${name}`, paths: [path], bytes: stats.bytes, reason: 'workspace unused since the cutoff' });
+  }
+  return items;
+}
+
+function outputHistoryItems(kept: string[], cutoff: number): GcItem[] {
+  const items: GcItem[] = [];
+  for (const typePath of kept.filter((t) => t.startsWith('skills/'))) {
+    const dir = join(getSkillsDir(), nameFromPath(typePath), 'output', 'history');
+    const old = (existsSync(dir) ? readdirSync(dir) : [])
+      .map((f) => join(dir, f))
+      .filter((p) => statSync(p).mtimeMs < cutoff);
+    if (old.length === 0) continue;
+    const bytes = old.reduce((sum, p) => sum + statSync(p).size, 0);
+    items.push({ kind: 'output-history', target: typePath, paths: old, bytes, reason: `${old.length} archived output(s) older than the cutoff` });
+  }
+  return items;
+}
+
+/** Everything gc would delete; nothing is changed. */
+export function planGc(installedRoot: string, opts: GcOptions = {}): GcPlan {
+  const cutoff = (opts.now ?? new Date()).getTime() - (opts.olderThanMs ?? DEFAULT_GC_AGE_MS);
+  // Read-only: an unreachable project is reported, not forgotten
+  const recorded = loadKnownProjects().map((p) => p.path);
+  const projects = recorded.filter((p) => existsSync(projectConfigPath(p)));
+  const unreachable = recorded.filter((p) => !projects.includes(p));
+  const installed = installedTypePaths(installedRoot);
+
+  const items: GcItem[] = [];
+  let typesSkipped: string | undefined;
+  if (projects.length === 0) {
+    typesSkipped = 'no known projects; types are only collected once link sync has run in a project';
+  } else {
+    items.push(...typeItems(installedRoot, installed, collectReferences(installedRoot, projects)));
+  }
+  const removed = new Set(items.filter((i) => i.kind === 'type').map((i) => i.target));
+  const kept = installed.filter((t) => !removed.has(t));
+
+  items.push(...nodeCacheItems(installedRoot, kept));
+  items.push(...runCacheItems([...projects, ...unreachable], cutoff));
+  items.push(...outputHistoryItems(kept, cutoff));
+  return { items, bytes: items.reduce((sum, i) => sum + i.bytes, 0), projects: projects.length, unreachable, typesSkipped };
+}
+
+export interface GcApplyOptions {
+  /** Also delete items marked force. */
+  force?: boolean;
+}
+
+export interface GcResult {
+  bytes: number;
+  /** Items marked force that were kept because force wasn't given. */
+  kept: GcItem[];
+  /** Items that couldn't be deleted, with why. */
+  failed: { item: GcItem; error: string }[];
+}
+
+/** Deletes the plan's items, carrying on past ones that fail. */
+export function applyGc(plan: GcPlan, installedRoot: string, opts: GcApplyOptions = {}): GcResult {
+  const result: GcResult = { bytes: 0, kept: [], failed: [] };
+  for (const item of plan.items) {
+    if (item.force && !opts.force) {
+      result.kept.push(item);
+      continue;
+    }
+    try {
+      if (item.kind === 'type') {
+        removeType(item.target, installedRoot);
+      } else if (item.kind === 'version') {
+        const at = item.target.lastIndexOf('@');
+        removeVersion(installedRoot, item.target.slice(0, at), item.target.slice(at + 1));
+      } else {
+        for (const path of item.paths) removeTree(path);
+      }
+      result.bytes += item.bytes;
+      log.info('collected', { kind: item.kind, target: item.target, bytes: item.bytes });
+    } catch (err) {
+      result.failed.push({ item, error: err instanceof Error ? err.message : String(err) });
+    }
+  }
+  return result;
+}
@@ -0,0 +1,152 @@
+import { join } from 'node:path';
+import { existsSync, readFileSync, writeFileSync, mkdirSync, chmodSync, rmSync } from 'node:fs';
+import { parseDocument, isMap, type Document } from 'yaml';
+import { APP_NAME } from '../config/branding.js';
+import { hooksDir } from '../utils/git.js';
+
+// ── Git hooks ───────────────────────────────────────────────────────
+//
+// `agentx githooks install` keeps generated tool files (CLAUDE.md,
+// copilot-instructions.md, context links) from being committed behind
+// project.yaml. pre-commit fails when `link status --quiet` says a sync
+// would change them; post-merge warns, or with sync set, runs link sync.
+//
+// The hooks go where the repository already manages hooks: .husky/<hook>
+// when husky is set up, lefthook.yml when lefthook is, and the git hooks
+// directory otherwise. Shell hooks get a marked block, so existing hooks
+// keep working and installing again replaces only the block. lefthook.yml
+// is edited in place, keeping its comments and formatting.
+
+export const GIT_HOOKS = ['pre-commit', 'post-merge'] as const;
+export type GitHookName = (typeof GIT_HOOKS)[number];
+
+export type HookManager = 'husky' | 'lefthook' | 'git';
+
+export interface GitHooksOptions {
+  /** post-merge runs link sync instead of only warning. */
+  sync?: boolean;
+}
+
+export interface InstalledGitHooks {
+  manager: HookManager;
+  /** Files written. */
+  paths: string[];
+}
+
+const BEGIN = `# >>> ${APP_NAME} links >>>`;
+const END = `# <<< ${APP_NAME} links <<<`;
+const BLOCK = new RegExp(`\\n*${BEGIN}[\\s\\S]*?${END}\\n?`);
+const LEFTHOOK_FILES = ['lefthook.yml', 'lefthook.yaml', '.lefthook.yml', '.lefthook.yaml'];
+const LEFTHOOK_COMMAND = `${APP_NAME}-links`;
+
+/** The shell command a hook runs. */
+export function gitHookCommand(hook: GitHookName, opts: GitHooksOptions = {}): string {
+  const stale = `${APP_NAME}: generated tool files are out of date; run '${APP_NAME} link sync'`;
+  if (hook === 'pre-commit') {
+    return `${APP_NAME} link status --quiet || { echo "${stale} and stage them" >&2; exit 1; }`;
+  }
+  return opts.sync ? `${APP_NAME} link sync` : `${APP_NAME} link status --quiet || echo "${stale}" >&2`;
+}
+
+function lefthookFile(projectPath: string): string | null {
+  const name = LEFTHOOK_FILES.find((f) => existsSync(join(projectPath, f)));
+  return name ? join(projectPath, name) : null;
+}
+
+export function detectHookManager(projectPath: string): HookManager {
+  if (existsSync(join(projectPath, '.husky'))) return 'husky';
+  if (lefthookFile(projectPath)) return 'lefthook';
+  return 'git';
+}
+
+function shellHooksDir(projectPath: string, manager: HookManager): string {
+  return manager === 'husky' ? join(projectPath, '.husky') : hooksDir(projectPath);
+}
+
+function withBlock(path: string, existing: string | null, command: string): string {
+  // Skipped where agentx isn't installed, so teammates without it can commit
+  const block = [BEGIN, `if command -v ${APP_NAME} >/dev/null 2>&1; then`, `  ${command}`, 'fi', END].join('\n');
+  if (existing === null) return `#!/bin/sh\n\n${block}\n`;
+
+  const shebang = /^#!(.*)/.exec(existing)?.[1];
+  if (shebang && !/\b(sh|bash|zsh|dash)\b/.test(shebang)) {
+    throw new Error(`${path} is not a shell script; add \`${command}\` to it yourself`);
+  }
+  return `${existing.replace(BLOCK, '\n').trimEnd()}\n\n${block}\n`;
+}
+
+function readLefthook(path: string): Document {
+  const doc = parseDocument(readFileSync(path, 'utf-8'));
+  if (doc.errors.length > 0) throw new Error(`${path}: ${doc.errors[0].message}`);
+  return doc;
+}
+
+/** Deletes the map at path in doc when removing our command left it empty. */
+function deleteIfEmpty(doc: Document, path: string[]): void {
+  const node = doc.getIn(path, true);
+  if (isMap(node) && node.items.length === 0) doc.deleteIn(path);
+}
+
+/** Installs the pre-commit and post-merge hooks for the project's repository. */
+export function installGitHooks(projectPath: string, opts: GitHooksOptions = {}): InstalledGitHooks {
+  const manager = detectHookManager(projectPath);
+
+  if (manager === 'lefthook') {
+    const path = lefthookFile(projectPath)!;
+    const doc = readLefthook(path);
+    for (const hook of GIT_HOOKS) {
+      doc.setIn([hook, 'commands', LEFTHOOK_COMMAND, 'run'], gitHookCommand(hook, opts));
+    }
+    writeFileSync(path, doc.toString());
+    return { manager, paths: [path] };
+  }
+
+  const dir = shellHooksDir(projectPath, manager);
+  mkdirSync(dir, { recursive: true });
+  const paths = GIT_HOOKS.map((hook) => {
+    const path = join(dir, hook);
+    const existing = existsSync(path) ? readFileSync(path, 'utf-8') : null;
+    writeFileSync(path, withBlock(path, existing, gitHookCommand(hook, opts)));
+    chmodSync(path, 0o755);
+    return path;
+  });
+  return { manager, paths };
+}
+
+/** Removes what installGitHooks added; returns the files changed. */
+export function uninstallGitHooks(projectPath: string): string[] {
+  const manager = detectHookManager(projectPath);
+
+  if (manager === 'lefthook') {
+    const path = lefthookFile(projectPath)!;
+    const doc = readLefthook(path);
+    let changed = false;
+    for (const hook of GIT_HOOKS) {
+      if (!doc.hasIn([hook, 'commands', LEFTHOOK_COMMAND])) continue;
+      doc.deleteIn([hook, 'commands', LEFTHOOK_COMMAND]);
+      // Hooks and command lists install created stay behind otherwise
+      deleteIfEmpty(doc, [hook, 'commands']);
+      deleteIfEmpty(doc, [hook]);
+      changed = true;
+    }
+    if (changed) writeFileSync(path, doc.toString());
+    return changed ? [path] : [];
+  }
+
+  const changed: string[] = [];
+  for (const hook of GIT_HOOKS) {
+    const path = join(shellHooksDir(projectPath, manager), hook);
+    if (!existsSync(path)) continue;
+    const text = readFileSync(path, 'utf-8');
+    if (!BLOCK.test(text)) continue;
+    const rest = text.replace(BLOCK, '\n').trimEnd();
+    // A hook that only ever held our block goes away entirely
+    if (rest === '' || rest === '#!/bin/sh') {
+      rmSync(path);
+    } else {
+      writeFileSync(path, `${rest}\n`);
+    }
+    changed.push(path);
+  }
+  return changed;
+}
@@ -0,0 +1,204 @@
+import { join, relative, sep } from 'node:path';
+import { readFileSync, writeFileSync, readdirSync, existsSync, mkdirSync } from 'node:fs';
+import { createHash, randomBytes } from 'node:crypto';
+import yaml from 'js-yaml';
+import type { BaseManifest } from '../types/manifest.js';
+import { loadProject, projectConfigPath } from './linker.js';
+import { readCurrent } from './store.js';
+import { getHomeRoot } from './userdata.js';
+import { findRepoRoot, remoteUrl } from '../utils/git.js';
+
+// ── Types ───────────────────────────────────────────────────────────
+
+/** Project ids are always hashes; type ids are type paths unless hashTypes is set. */
+export interface GraphNode {
+  id: string;
+  kind: 'project' | 'type';
+}
+
+export interface GraphEdge {
+  project: string;
+  type: string;
+  version: string;
+}
+
+export interface DependencyGraph {
+  generatedAt: string;
+  nodes: GraphNode[];
+  edges: GraphEdge[];
+}
+
+export interface GraphOptions {
+  /**
+   * Mixed into every hash so labels can't be reversed by guessing repo
+   * URLs. Defaults to this install's random salt (see graphSalt).
+   */
+  salt?: string;
+  /** Hash type paths too, for catalogs whose names are themselves sensitive. */
+  hashTypes?: boolean;
+  /** Add catalog types no project activates, so unused entries show up. */
+  catalogTypes?: string[];
+}
+
+const PROJECT_SCAN_SKIP = new Set(['node_modules', '.git', 'dist', 'vendor']);
+const MAX_SCAN_DEPTH = 8;
+const LABEL_LENGTH = 12;
+const SALT_FILE = 'graph-salt';
+const SALT_BYTES = 32;
+
+// ── Discovery ───────────────────────────────────────────────────────
+
+function scan(dir: string, depth: number, out: string[]): void {
+  if (existsSync(projectConfigPath(dir))) out.push(dir);
+  if (depth >= MAX_SCAN_DEPTH) return;
+
+  let entries;
+  try {
+    entries = readdirSync(dir, { withFileTypes: true });
+  } catch {
+    return;
+  }
+  for (const entry of entries) {
+    if (!entry.isDirectory() || PROJECT_SCAN_SKIP.has(entry.name) || entry.name.startsWith('.')) {
+      continue;
+    }
+    scan(join(dir, entry.name), depth + 1, out);
+  }
+}
+
+/** Directories under root (inclusive) that contain an agentx project. */
+export function findProjects(root: string): string[] {
+  const out: string[] = [];
+  scan(root, 0, out);
+  return out.sort();
+}
+
+// ── Graph ───────────────────────────────────────────────────────────
+
+export function graphSaltPath(): string {
+  return join(getHomeRoot(), SALT_FILE);
+}
+
+/**
+ * This install's salt for graph hashes: random, created on first use,
+ * and readable only by its owner, so a leaked export can't be undone by
+ * hashing guessed repo URLs. Exports from one install correlate; pass
+ * an explicit salt to correlate them across machines.
+ */
+export function graphSalt(): string {
+  const path = graphSaltPath();
+  try {
+    const existing = readFileSync(path, 'utf-8').trim();
+    if (existing) return existing;
+  } catch {
+    // Not created yet
+  }
+  mkdirSync(getHomeRoot(), { recursive: true });
+  const salt = randomBytes(SALT_BYTES).toString('hex');
+  try {
+    writeFileSync(path, `${salt}\n`, { mode: 0o600, flag: 'wx' });
+    return salt;
+  } catch (err) {
+    if ((err as NodeJS.ErrnoException).code !== 'EEXIST') throw err;
+  }
+  // Another process created it first (use theirs), or it was left empty
+  const theirs = readFileSync(path, 'utf-8').trim();
+  if (theirs) return theirs;
+  writeFileSync(path, `${salt}\n`, { mode: 0o600 });
+  return salt;
+}
+
+function hashLabel(value: string, salt: string): string {
+  return createHash('sha256').update(`${salt}\0${value}`).digest('hex').slice(0, LABEL_LENGTH);
+}
+
+function installedVersion(typePath: string, installedRoot: string): string {
+  const stored = readCurrent(typePath);
+  if (stored) return stored;
+
+  for (const name of ['manifest.yaml', 'manifest.json']) {
+    const path = join(installedRoot, typePath, name);
+    if (!existsSync(path)) continue;
+    try {
+      const data = yaml.load(readFileSync(path, 'utf-8')) as BaseManifest;
+      return String(data.version ?? 'unknown');
+    } catch {
+      return 'unknown';
+    }
+  }
+  return 'not-installed';
+}
+
+/**
+ * A stable identity for a project: its git remote plus its path inside
+ * the repository, so clones on different machines collapse to one node
+ * while monorepo siblings stay distinct. Falls back to the path under root.
+ */
+function projectIdentity(root: string, dir: string): string {
+  const remote = remoteUrl(dir);
+  const repo = findRepoRoot(dir);
+  if (remote && repo) {
+    return `${remote}#${relative(repo, dir).split(sep).join('/')}`;
+  }
+  return relative(root, dir).split(sep).join('/');
+}
+
+/**
+ * Builds the project → type graph for the given projects. Projects are
+ * only ever emitted as salted hashes of their identity.
+ */
+export function buildGraph(
+  root: string,
+  projects: string[],
+  installedRoot: string,
+  opts: GraphOptions = {},
+): DependencyGraph {
+  const salt = opts.salt || graphSalt();
+  const typeId = (t: string) => (opts.hashTypes ? hashLabel(t, salt) : t);
+
+  const nodes = new Map<string, GraphNode>();
+  const edges: GraphEdge[] = [];
+
+  for (const dir of projects) {
+    const projectId = hashLabel(projectIdentity(root, dir), salt);
+    nodes.set(projectId, { id: projectId, kind: 'project' });
+
+    let active;
+    try {
+      active = loadProject(dir).active;
+    } catch {
+      continue;
+    }
+    for (const refs of Object.values(active)) {
+      for (const ref of refs ?? []) {
+        const id = typeId(ref);
+        nodes.set(id, { id, kind: 'type' });
+        edges.push({ project: projectId, type: id, version: installedVersion(ref, installedRoot) });
+      }
+    }
+  }
+
+  for (const t of opts.catalogTypes ?? []) {
+    const id = typeId(t);
+    if (!nodes.has(id)) nodes.set(id, { id, kind: 'type' });
+  }
+
+  return {
+    generatedAt: new Date().toISOString(),
+    nodes: [...nodes.values()],
+    edges,
+  };
+}
+
+export function graphToDot(graph: DependencyGraph): string {
+  const lines = ['digraph agentx {', '  rankdir=LR;'];
+  for (const n of graph.nodes) {
+    const shape = n.kind === 'project' ? 'box' : 'ellipse';
+    lines.push(`  "${n.id}" [shape=${shape}];`);
+  }
+  for (const e of graph.edges) {
+    lines.push(`  "${e.project}" -> "${e.type}" [label="${e.version}"];`);
+  }
+  lines.push('}');
+  return lines.join('\n') + '\n';
+}
@@ -0,0 +1,249 @@
+import { join } from 'node:path';
+import { readFileSync, existsSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { SkillManifest } from '../types/manifest.js';
+import { ALL_TOOLS, type ToolName } from '../types/integrations.js';
+import { loadProject, status, type ProjectConfig } from './linker.js';
+import {
+  getInstalledRoot,
+  getCatalogRepoRoot,
+  getSkillRegistryPath,
+  catalogExists,
+} from './userdata.js';
+import { readFreshnessMarker } from './catalog.js';
+import { nameFromPath } from './registry.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { tokenFiles } from './runtime.js';
+
+// ── Types ───────────────────────────────────────────────────────────
+
+export interface HealthCheck {
+  name: string;
+  label: string;
+  score: number;
+  weight: number;
+  details: string[];
+}
+
+export interface HealthReport {
+  score: number;
+  grade: string;
+  checks: HealthCheck[];
+}
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+const CATALOG_FRESH_DAYS = 7;
+const CATALOG_EXPIRED_DAYS = 30;
+
+// ── Checks ──────────────────────────────────────────────────────────
+
+async function checkLinks(projectPath: string): Promise<HealthCheck> {
+  const results = await status(projectPath);
+  const details: string[] = [];
+  if (results.length === 0) {
+    return { name: 'links', label: 'Link freshness', score: 0, weight: 3, details: ['No tools configured'] };
+  }
+
+  let points = 0;
+  for (const r of results) {
+    const linksOk = r.symlinks.total === r.symlinks.valid;
+    if (r.status === 'up-to-date' && linksOk) {
+      points += 1;
+    } else {
+      // Bad links alone also make a tool stale; report them instead
+      if (r.status !== 'up-to-date' && (linksOk || r.status !== 'stale')) details.push(`${r.tool}: ${r.status}`);
+      const broken = r.symlinks.total - r.symlinks.valid - r.symlinks.drifted;
+      if (broken > 0) details.push(`${r.tool}: ${broken} broken symlink(s)`);
+      if (r.symlinks.drifted > 0) details.push(`${r.tool}: ${r.symlinks.drifted} copied link(s) out of date`);
+    }
+  }
+  return {
+    name: 'links',
+    label: 'Link freshness',
+    score: Math.round((points / results.length) * 100),
+    weight: 3,
+    details,
+  };
+}
+
+function checkCatalog(): HealthCheck {
+  const base = { name: 'catalog', label: 'Catalog freshness', weight: 1 };
+  if (!catalogExists()) {
+    return { ...base, score: 0, details: ['Catalog not installed'] };
+  }
+
+  const updated = readFreshnessMarker(getCatalogRepoRoot());
+  const days = Math.floor((Date.now() - updated.getTime()) / DAY_MS);
+  if (days <= CATALOG_FRESH_DAYS) {
+    return { ...base, score: 100, details: [] };
+  }
+
+  // Linear decay from fresh to expired
+  const span = CATALOG_EXPIRED_DAYS - CATALOG_FRESH_DAYS;
+  const score = Math.max(0, Math.round(100 - ((days - CATALOG_FRESH_DAYS) / span) * 100));
+  return { ...base, score, details: [`Catalog last updated ${days} days ago`] };
+}
+
+function loadSkillManifest(installedRoot: string, skillPath: string): SkillManifest | null {
+  for (const name of ['manifest.yaml', 'skill.yaml']) {
+    const path = join(installedRoot, skillPath, name);
+    if (!existsSync(path)) continue;
+    try {
+      return yaml.load(readFileSync(path, 'utf-8')) as SkillManifest;
+    } catch {
+      return null;
+    }
+  }
+  return null;
+}
+
+function checkTokens(config: ProjectConfig, installedRoot: string): HealthCheck {
+  const details: string[] = [];
+  let required = 0;
+  let set = 0;
+
+  for (const skillPath of config.active.skills ?? []) {
+    const manifest = loadSkillManifest(installedRoot, skillPath);
+    const tokens = manifest?.registry?.tokens?.filter((t) => t.required) ?? [];
+    if (tokens.length === 0) continue;
+
+    const values = new Map<string, string>();
+    // tokens.env first, so the active account's values win
+    for (const tokensPath of tokenFiles(getSkillRegistryPath(nameFromPath(skillPath))).reverse()) {
+      if (!existsSync(tokensPath)) continue;
+      for (const e of parseEnvFile(readFileSync(tokensPath, 'utf-8'))) values.set(e.key, e.value);
+    }
+
+    for (const token of tokens) {
+      required++;
+      if (values.get(token.name) || token.default) {
+        set++;
+      } else {
+        details.push(`${skillPath}: ${token.name} not set`);
+      }
+    }
+  }
+
+  return {
+    name: 'tokens',
+    label: 'Token completeness',
+    score: required === 0 ? 100 : Math.round((set / required) * 100),
+    weight: 2,
+    details,
+  };
+}
+
+// Policy: every linked type is installed and every configured tool is a
+// supported integration.
+function checkPolicy(config: ProjectConfig, installedRoot: string): HealthCheck {
+  const details: string[] = [];
+  let total = 0;
+  let passing = 0;
+
+  for (const tool of config.tools) {
+    total++;
+    if (ALL_TOOLS.includes(tool as ToolName)) passing++;
+    else details.push(`Unknown tool: ${tool}`);
+  }
+
+  for (const refs of Object.values(config.active)) {
+    for (const ref of refs ?? []) {
+      total++;
+      if (existsSync(join(installedRoot, ref))) passing++;
+      else details.push(`Linked but not installed: ${ref}`);
+    }
+  }
+
+  return {
+    name: 'policy',
+    label: 'Policy compliance',
+    score: total === 0 ? 100 : Math.round((passing / total) * 100),
+    weight: 2,
+    details,
+  };
+}
+
+// ── Report ──────────────────────────────────────────────────────────
+
+export function gradeFor(score: number): string {
+  if (score >= 90) return 'A';
+  if (score >= 80) return 'B';
+  if (score >= 70) return 'C';
+  if (score >= 60) return 'D';
+  return 'F';
+}
+
+export async function assessProject(projectPath: string): Promise<HealthReport> {
+  const config = loadProject(projectPath);
+  const installedRoot = getInstalledRoot();
+
+  const checks = [
+    await checkLinks(projectPath),
+    checkCatalog(),
+    checkTokens(config, installedRoot),
+    checkPolicy(config, installedRoot),
+  ];
+
+  const totalWeight = checks.reduce((sum, c) => sum + c.weight, 0);
+  const weighted = checks.reduce((sum, c) => sum + c.score * c.weight, 0);
+  const score = Math.round(weighted / totalWeight);
+  return { score, grade: gradeFor(score), checks };
+}
+
+// ── Badges ──────────────────────────────────────────────────────────
+
+const BADGE_LABEL = 'agentx health';
+
+function badgeColor(score: number): string {
+  if (score >= 90) return '#4c1';
+  if (score >= 80) return '#97ca00';
+  if (score >= 70) return '#dfb317';
+  if (score >= 60) return '#fe7d37';
+  return '#e05d44';
+}
+
+/** shields.io endpoint format, for `https://img.shields.io/endpoint?url=...`. */
+export function badgeJson(report: HealthReport): string {
+  return JSON.stringify(
+    {
+      schemaVersion: 1,
+      label: BADGE_LABEL,
+      message: `${report.score}% (${report.grade})`,
+      color: badgeColor(report.score).slice(1),
+    },
+    null,
+    2,
+  );
+}
+
+// Approximates Verdana 11px glyph widths closely enough for short labels.
+function textWidth(text: string): number {
+  return Math.round(text.length * 6.5) + 10;
+}
+
+export function badgeSvg(report: HealthReport): string {
+  const message = `${report.score}% (${report.grade})`;
+  const lw = textWidth(BADGE_LABEL);
+  const mw = textWidth(message);
+  const w = lw + mw;
+  const color = badgeColor(report.score);
+
+  return `<svg xmlns="http://www.w3.org/2000/svg" width="${w}" height="20" role="img" aria-label="${BADGE_LABEL}: ${message}">
+  <title>${BADGE_LABEL}: ${message}</title>
+  <linearGradient id="s" x2="0" y2="100%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r"><rect width="${w}" height="20" rx="3" fill="#fff"/></clipPath>
+  <g clip-path="url(#r)">
+    <rect width="${lw}" height="20" fill="#555"/>
+    <rect x="${lw}" width="${mw}" height="20" fill="${color}"/>
+    <rect width="${w}" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="${lw / 2}" y="14">${BADGE_LABEL}</text>
+    <text x="${lw + mw / 2}" y="14">${message}</text>
+  </g>
+</svg>
+`;
+}
@@ -0,0 +1,182 @@
+import { join, basename } from 'node:path';
+import { existsSync } from 'node:fs';
+import { spawnSync } from 'node:child_process';
+import * as settings from '../config/settings.js';
+import { envVar } from '../config/branding.js';
+import { loadProject, projectConfigPath } from './linker.js';
+import { isTrusted, recordTrust, type Contribution } from './trust.js';
+import { buildSources, listContributions } from './extension.js';
+import { parseDuration } from '../utils/units.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('hooks');
+
+// ── Lifecycle hooks ─────────────────────────────────────────────────
+//
+// Shell commands run around install, link sync, and run, so a team can
+// refresh a cache or post a notification without forking the CLI:
+//
+//   ~/.agentx/config.yaml           .agentx/project.yaml
+//     hooks.post-install:             hooks:
+//       - ./notify.sh                   post-link-sync:
+//                                         - make docs-index
+//
+// User hooks run first, from the current directory; project hooks run
+// from the project root, and hooks extensions declare in extension.yaml
+// (kind: hook, with an event) from the extension's checkout. Every hook gets AGENTX_HOOK_EVENT,
+// AGENTX_TYPE_PATH (the first type), AGENTX_TYPE_PATHS (one per line),
+// and AGENTX_PROJECT; post-* hooks also get AGENTX_HOOK_STATUS (ok or
+// failed) and, for post-run, AGENTX_HOOK_EXIT_CODE. A failing pre-*
+// hook stops the operation; a failing post-* hook only warns.
+//
+// project.yaml arrives with a clone and an extension with a sync, so
+// their hooks are approved through the trust store, and skipped until then.
+
+export const HOOK_EVENTS = ['pre-install', 'post-install', 'post-link-sync', 'pre-run', 'post-run'] as const;
+export type HookEvent = (typeof HOOK_EVENTS)[number];
+
+const DEFAULT_TIMEOUT = '60s';
+
+export interface HookContext {
+  typePaths?: string[];
+  /** Outcome of the operation, for post-* events. */
+  status?: 'ok' | 'failed';
+  /** The skill's exit code, for post-run. */
+  exitCode?: number;
+  /** Project whose project.yaml hooks apply; none when omitted. */
+  projectPath?: string;
+}
+
+export interface HookOptions {
+  /** Asks whether an unapproved project or extension hook may run; the default declines. */
+  approve?: (c: Contribution) => Promise<boolean>;
+}
+
+export interface Hook {
+  event: HookEvent;
+  scope: 'user' | 'project' | 'extension';
+  command: string;
+  cwd: string;
+  /** The extension capability an extension hook comes from. */
+  contribution?: Contribution;
+}
+
+export interface HookRun extends Hook {
+  exitCode: number;
+}
+
+export interface HookResult {
+  ran: HookRun[];
+  warnings: string[];
+}
+
+function commandsOf(value: unknown): string[] {
+  if (value == null) return [];
+  const items = Array.isArray(value) ? value : [value];
+  return items.map((c) => String(c).trim()).filter(Boolean);
+}
+
+/** The hooks declared for event: user, then project, then extension hooks. */
+export function listHooks(event: HookEvent, projectPath?: string): Hook[] {
+  // Only the user's config.yaml: a project's config.yaml is committed like
+  // project.yaml, but would skip the approval project hooks need
+  const hooks: Hook[] = commandsOf(settings.layer('global')[`hooks.${event}`]).map((command) => ({
+    event,
+    scope: 'user',
+    command,
+    cwd: process.cwd(),
+  }));
+  if (projectPath && existsSync(projectConfigPath(projectPath))) {
+    const declared = loadProject(projectPath).hooks ?? {};
+    for (const command of commandsOf(declared[event])) {
+      hooks.push({ event, scope: 'project', command, cwd: projectPath });
+    }
+  }
+  for (const c of listContributions(buildSources(projectPath ?? process.cwd()), 'hook')) {
+    if (c.event === event && c.command) {
+      hooks.push({ event, scope: 'extension', command: c.command, cwd: c.baseDir, contribution: c });
+    }
+  }
+  return hooks;
+}
+
+/** A project hook as a trust-store contribution, keyed by its project. */
+export function hookContribution(hook: Hook): Contribution {
+  const contribution: Contribution = {
+    extension: `project:${hook.cwd}`,
+    kind: 'hook',
+    name: `${hook.event}: ${hook.command}`,
+    description: `${basename(hook.cwd)} runs this ${hook.event} hook`,
+    baseDir: hook.cwd,
+    command: hook.command,
+  };
+  // A script in the project is fingerprinted too, so editing it needs approval again
+  const script = hook.command.split(/\s+/)[0];
+  if (/^\.{1,2}\//.test(script) && existsSync(join(hook.cwd, script))) contribution.path = script;
+  return contribution;
+}
+
+function hookTimeout(): number {
+  const raw = settings.get('hooks.timeout') || DEFAULT_TIMEOUT;
+  try {
+    return parseDuration(raw);
+  } catch {
+    log.warn(`Ignoring invalid hooks.timeout "${raw}"`);
+    return parseDuration(DEFAULT_TIMEOUT);
+  }
+}
+
+function hookEnv(event: HookEvent, ctx: HookContext): NodeJS.ProcessEnv {
+  const typePaths = ctx.typePaths ?? [];
+  const env: NodeJS.ProcessEnv = {
+    ...process.env,
+    [envVar('HOOK_EVENT')]: event,
+    [envVar('TYPE_PATH')]: typePaths[0] ?? '',
+    [envVar('TYPE_PATHS')]: typePaths.join('\n'),
+    [envVar('PROJECT')]: ctx.projectPath ?? '',
+  };
+  if (event.startsWith('post-')) env[envVar('HOOK_STATUS')] = ctx.status ?? 'ok';
+  if (ctx.exitCode !== undefined) env[envVar('HOOK_EXIT_CODE')] = String(ctx.exitCode);
+  return env;
+}
+
+/**
+ * Runs the hooks declared for event. Hook output goes to stderr so it
+ * never mixes with --json on stdout. Throws when a pre-* hook fails.
+ */
+export async function runHooks(event: HookEvent, ctx: HookContext = {}, opts: HookOptions = {}): Promise<HookResult> {
+  const result: HookResult = { ran: [], warnings: [] };
+  const hooks = listHooks(event, ctx.projectPath);
+  if (hooks.length === 0) return result;
+
+  const env = hookEnv(event, ctx);
+  const timeout = hookTimeout();
+  for (const hook of hooks) {
+    if (hook.scope !== 'user') {
+      const contribution = hook.contribution ?? hookContribution(hook);
+      if (!isTrusted(contribution)) {
+        const approved = opts.approve ? await opts.approve(contribution) : false;
+        if (!approved) {
+          const owner = hook.scope === 'project' ? "the project's" : `extension ${contribution.extension}'s`;
+          result.warnings.push(
+            `Skipped ${owner} ${event} hook \`${hook.command}\`: not approved yet (run the command in a terminal to review it)`,
+          );
+          continue;
+        }
+        recordTrust(contribution);
+      }
+    }
+
+    const proc = spawnSync(hook.command, { cwd: hook.cwd, env, shell: true, stdio: ['ignore', 2, 2], timeout });
+    const exitCode = proc.status ?? 1;
+    result.ran.push({ ...hook, exitCode });
+    log.info('hook', { event, scope: hook.scope, command: hook.command, exitCode });
+    if (exitCode === 0) continue;
+
+    const why = proc.error ? proc.error.message : `exit ${exitCode}`;
+    const message = `${event} hook \`${hook.command}\` failed (${why})`;
+    if (event.startsWith('pre-')) throw new Error(message);
+    result.warnings.push(message);
+  }
+  return result;
+}
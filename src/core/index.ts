@@ -7,8 +7,14 @@ export {
   discoverTypes,
   discoverByCategory,
   discoverAll,
+  discoverAllAsync,
+  discoverAllStream,
   discoverAllCached,
+  searchTypes,
+  indexedDependents,
   buildDependencyTree,
+  findDependents,
+  reverseDependencyIndex,
   flattenTree,
   buildInstallPlan,
   installType,
@@ -18,6 +24,9 @@ export {
   categoryFromPath,
   nameFromPath,
   printTree,
+  suggestTypePaths,
+  didYouMean,
+  levenshtein,
   defaultCachePath,
 } from './registry.js';
 
@@ -30,10 +39,36 @@ export {
   removeType as unlinkType,
   sync,
   status,
+  parsePin,
+  checkPins,
 } from './linker.js';
 
-export { compose, render } from './compose.js';
+export {
+  compose,
+  render,
+  applyBudget,
+  renderBudget,
+  templateData,
+  registerRenderer,
+  renderFormats,
+} from './compose.js';
+export { createEngine, renderString, renderFile, registerPartials, renderTemplateType } from './template.js';
+export { countTokens, truncateToTokens } from './tokens.js';
+export {
+  expandSources,
+  orderSourceFiles,
+  fetchRemoteSources,
+  prefetchContext,
+  prefetchPromptContext,
+} from './context-sources.js';
 export { runSkill } from './runtime.js';
+export { checkOutput, loadOutputSchema, outputSchemaMode } from './output-schema.js';
+export {
+  notifyChange,
+  subscribe as subscribeToChanges,
+  currentSequence,
+  readLastChange,
+} from './notify.js';
 
 export {
   clone as cloneCatalog,
@@ -43,7 +78,7 @@ export {
   repoURL,
 } from './catalog.js';
 
-export { generate as generateScaffold, newScaffoldData } from './scaffold.js';
+export { generate as generateScaffold, newScaffoldData, loadTemplateSet, listTemplateSets } from './scaffold.js';
 
 export {
   addExtension,
@@ -51,10 +86,92 @@ export {
   listExtensions,
   syncExtensions,
   buildSources,
+  loadExtensionManifest,
+  listContributions,
+  runDetectionRules,
 } from './extension.js';
 
+export {
+  ensureTrusted,
+  isTrusted,
+  recordTrust,
+  revokeTrust,
+  loadTrustStore,
+  describeContribution,
+  contributionDigest,
+} from './trust.js';
+
 export {
   checkForUpdate,
   update as updateCli,
   currentVersion,
 } from './updater.js';
+
+export { assessProject, badgeSvg, badgeJson, gradeFor } from './health.js';
+
+export { lookupRun, storeRun, runCacheStats, clearRunCache } from './run-cache.js';
+
+export {
+  storeType,
+  materialize,
+  listSnapshots,
+  loadSnapshot,
+  readCurrent,
+  ingestDir,
+  diffFiles,
+  currentSnapshot,
+} from './store.js';
+
+export { searchContent, rebuildContentIndex } from './content-index.js';
+
+export { findProjects, buildGraph, graphToDot } from './graph.js';
+
+export { listInstalled } from './installed.js';
+
+export { inspectType, projectsLinking } from './info.js';
+
+export { searchSemantic, updateEmbeddingIndex, localEmbedder, apiEmbedder } from './embeddings.js';
+
+export { verifyType, acceptType, manifestGuardMode } from './integrity.js';
+
+export { listOverrides, addOverride, mergeOverrides, resolveOverride, buildOverlays } from './overrides.js';
+export { listRegistries, exportRegistries, importRegistries } from './registry-archive.js';
+export { runSkillTests } from './skill-tests.js';
+export { listState, readState, clearState, stateOverLimits } from './state.js';
+export { listHistory, readOutput } from './output-history.js';
+export { isOffline, offlineSkip, requireOnline } from './offline.js';
+export { telemetryMode, readStats, resetStats, recordUsage, flushTelemetry } from './telemetry.js';
+export { InstallTransaction, installAll } from './transaction.js';
+export { exportPack, importPack } from './pack.js';
+export { listPresets, loadPreset, presetTypes, installPreset } from './preset.js';
+export { migrateSkillRegistry, describeMigration } from './registry-migrate.js';
+export { resolveSkillEnv, redactEnv, formatEnv } from './skill-env.js';
+export { mergeStrategy, contentDir, sourceLabel } from './merge.js';
+export { createExtension } from './extension-scaffold.js';
+export { validateSource } from './source-lint.js';
+export { orderSources } from './extension.js';
+export { explainResolution, sourcesFor } from './registry.js';
+export { createBackup, restoreBackup } from './backup.js';
+export { importToolFiles, parseInstructions } from './tool-import.js';
+export { exportProject } from './project-export.js';
+export { HOOK_EVENTS, listHooks, runHooks, hookContribution } from './hooks.js';
+export { executeType, findManifest } from './executor.js';
+export { createApiServer, parseAddr } from './api-server.js';
+export { startDaemon, stopDaemon, superviseDaemon, daemonStatus, listDaemons } from './daemon.js';
+export { addSchedule, removeSchedule, loadSchedules, tick as tickSchedules, schedulerEntry } from './schedule.js';
+export { parsePipeArgs, validatePipe, runPipe } from './pipe.js';
+export { nodeCacheKey, linkCachedModules, populateNodeCache, nodeCacheStats, clearNodeCache } from './node-cache.js';
+export { vendoredArchive, unpackVendoredDeps } from './vendored-deps.js';
+export { installedVersions, resolveTypeDir, pinnedTypeDirs, projectTypeDirs, addVersion, removeVersion } from './versions.js';
+export { planGc, applyGc } from './gc.js';
+export { knownProjects, recordProject } from './projects.js';
+export { diffPrompt, latestTypeDirs } from './prompt-diff.js';
+export { installGitHooks, uninstallGitHooks, detectHookManager } from './githooks.js';
+export { runVerify } from './verify.js';
+export { toJUnit, toSarif, verifySuites, validationSuites } from './report.js';
+export { catalogStats } from './catalog-stats.js';
+export { benchSkill, compareBench } from './bench.js';
+export { AgentxError, ERROR_CODES, errorCode } from './errors.js';
+export { explain, explanations } from './explain.js';
+export { loadDashboard } from './dashboard.js';
+export { RegistryIndex, type IndexQuery, type IndexEntry, type IndexedSource } from './registry-index.js';
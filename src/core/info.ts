@@ -0,0 +1,167 @@
+import { join } from 'node:path';
+import { readFileSync, existsSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { ManifestType } from '../config/schema.js';
+import type { SkillManifest } from '../types/manifest.js';
+import type { CLIDepStatus, DependencyNode, Source } from '../types/registry.js';
+import {
+  resolveType,
+  buildDependencyTree,
+  checkCLIDeps,
+  nameFromPath,
+  didYouMean,
+  installedTypePaths,
+} from './registry.js';
+import { readCurrent, loadSnapshot } from './store.js';
+import { sourceLabel } from './merge.js';
+import { findProjects } from './graph.js';
+import { loadProject } from './linker.js';
+import { getEnvDir, getVendorEnvPath, getSkillRegistryPath } from './userdata.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { tokenFiles } from './runtime.js';
+
+// ── Types ───────────────────────────────────────────────────────────
+
+export interface TokenStatus {
+  name: string;
+  required: boolean;
+  description: string;
+  set: boolean;
+  /** Where the value comes from: an env file path or "environment". */
+  from: string | null;
+}
+
+export interface ConfigKey {
+  key: string;
+  default: unknown;
+  /** Effective value: the user's registry config.yaml, else the default. */
+  value: unknown;
+}
+
+export interface TypeInfo {
+  typePath: string;
+  category: ManifestType;
+  installed: boolean;
+  source: string | null;
+  manifest: Record<string, unknown>;
+  tree: DependencyNode;
+  cliDeps: CLIDepStatus[];
+  tokens: TokenStatus[];
+  config: ConfigKey[];
+  /** Projects (under the scanned root) that link the type. */
+  linkedBy: string[];
+}
+
+export interface InfoOptions {
+  installedRoot: string;
+  /** Catalog and extension sources, for types that aren't installed. */
+  sources: Source[];
+  /** Directory to scan for projects linking the type. */
+  projectsRoot?: string;
+}
+
+// ── Tokens & config ─────────────────────────────────────────────────
+
+function readEnv(path: string): Map<string, string> {
+  try {
+    return new Map(
+      parseEnvFile(readFileSync(path, 'utf-8'))
+        .filter((e) => e.value)
+        .map((e) => [e.key, e.value]),
+    );
+  } catch {
+    return new Map();
+  }
+}
+
+/**
+ * Resolves each declared token in the order a skill's process sees it,
+ * the same order `agentx env export` reports (core/skill-env.ts). The
+ * runtime lays the active account's tokens.<account>.env and then
+ * tokens.env over the environment; a scaffolded skill then loads
+ * env/<vendor>.env, which beats the environment, and env/default.env,
+ * which only fills what the environment leaves unset. Layers are listed
+ * here from highest precedence down.
+ */
+function tokenStatuses(manifest: SkillManifest, registryPath: string): TokenStatus[] {
+  const fromFile = (path: string): [string, Map<string, string>] => [path, readEnv(path)];
+  const environment = new Map(
+    Object.entries(process.env).filter((e): e is [string, string] => Boolean(e[1])),
+  );
+  const layers: [string, Map<string, string>][] = tokenFiles(registryPath).map(fromFile);
+  if (manifest.vendor) layers.push(fromFile(getVendorEnvPath(manifest.vendor)));
+  layers.push(['environment', environment], fromFile(join(getEnvDir(), 'default.env')));
+
+  return (manifest.registry?.tokens ?? []).map((token) => {
+    const from = layers.find(([, values]) => values.has(token.name))?.[0] ?? null;
+    return {
+      name: token.name,
+      required: token.required ?? false,
+      description: token.description ?? '',
+      set: from !== null,
+      from,
+    };
+  });
+}
+
+function configKeys(manifest: SkillManifest, registryPath: string): ConfigKey[] {
+  const defaults = manifest.registry?.config ?? {};
+  let user: Record<string, unknown> = {};
+  try {
+    user = (yaml.load(readFileSync(join(registryPath, 'config.yaml'), 'utf-8')) as Record<string, unknown>) ?? {};
+  } catch {
+    // Not initialized yet
+  }
+  return Object.entries(defaults).map(([key, value]) => ({
+    key,
+    default: value,
+    value: key in user ? user[key] : value,
+  }));
+}
+
+// ── Inspection ──────────────────────────────────────────────────────
+
+/** Projects under root whose project.yaml links typePath. */
+export function projectsLinking(typePath: string, root: string): string[] {
+  return findProjects(root).filter((dir) => {
+    try {
+      return Object.values(loadProject(dir).active).some((refs) => refs?.includes(typePath));
+    } catch {
+      return false;
+    }
+  });
+}
+
+/**
+ * Gathers everything known about a type. The installed copy is
+ * described when present; otherwise the copy the sources would install.
+ */
+export function inspectType(typePath: string, opts: InfoOptions): TypeInfo {
+  const installedSource: Source = { name: 'installed', basePath: opts.installedRoot };
+  const resolved = resolveType(typePath, [installedSource, ...opts.sources]);
+  if (!resolved) {
+    const hint = didYouMean(typePath, installedTypePaths(opts.installedRoot));
+    throw new Error(`Type not found: ${typePath}.${hint}`);
+  }
+
+  const manifest = (yaml.load(readFileSync(resolved.manifestPath, 'utf-8')) as Record<string, unknown>) ?? {};
+  const installed = existsSync(join(opts.installedRoot, typePath));
+  const current = readCurrent(typePath);
+  const snapshot = current ? loadSnapshot(typePath, current) : null;
+
+  const skill = resolved.category === 'skill' ? (manifest as SkillManifest) : null;
+  const registryPath = getSkillRegistryPath(nameFromPath(typePath));
+
+  return {
+    typePath,
+    category: resolved.category,
+    installed,
+    source: installed ? (snapshot?.source ?? null) : sourceLabel(resolved),
+    manifest,
+    tree: buildDependencyTree(typePath, [...opts.sources, installedSource], opts.installedRoot),
+    cliDeps: checkCLIDeps([resolved]),
+    tokens: skill ? tokenStatuses(skill, registryPath) : [],
+    config: skill ? configKeys(skill, registryPath) : [],
+    linkedBy: opts.projectsRoot ? projectsLinking(typePath, opts.projectsRoot) : [],
+  };
+}
@@ -0,0 +1,98 @@
+import { join } from 'node:path';
+import { readFileSync, statSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { ManifestType } from '../config/schema.js';
+import type { DiscoveredType, Source } from '../types/registry.js';
+import { discoverTypes } from './registry.js';
+import { readCurrent, loadSnapshot } from './store.js';
+import { compareVersions } from '../utils/version.js';
+
+// ── Types ───────────────────────────────────────────────────────────
+
+export interface InstalledType {
+  typePath: string;
+  category: ManifestType;
+  version: string;
+  description: string;
+  topic: string | null;
+  /** Source the type was installed from, when the store recorded it. */
+  source: string | null;
+  installedAt: string | null;
+  /** Newest version available across sources, when newer than installed. */
+  latest: string | null;
+}
+
+export interface InstalledFilter {
+  type?: string;
+  topic?: string;
+}
+
+// ── Listing ─────────────────────────────────────────────────────────
+
+// Skills and context declare a topic; otherwise the first path segment
+// after the category is the topic by convention (skills/<topic>/...).
+function topicOf(typePath: string, declared: unknown): string | null {
+  if (typeof declared === 'string' && declared) return declared;
+  const segments = typePath.split('/');
+  return segments.length >= 3 ? segments[1] : null;
+}
+
+function installedAt(typePath: string, dir: string, version: string): string | null {
+  const snapshot = loadSnapshot(typePath, readCurrent(typePath) ?? version);
+  if (snapshot) return snapshot.createdAt;
+  try {
+    return statSync(dir).mtime.toISOString();
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Lists installed types with where they came from and when. When
+ * `available` is given (the catalog and extensions, typically from
+ * discoverAllCached), types with a newer version there get `latest`.
+ */
+export function listInstalled(
+  installedRoot: string,
+  filter: InstalledFilter = {},
+  available: DiscoveredType[] = [],
+): InstalledType[] {
+  // Several sources may offer a type; the newest version among them counts
+  const latestByPath = new Map<string, string>();
+  for (const t of available) {
+    const seen = latestByPath.get(t.typePath);
+    if (!seen || compareVersions(t.version, seen) > 0) latestByPath.set(t.typePath, t.version);
+  }
+  const results: InstalledType[] = [];
+
+  for (const t of discoverTypes([{ name: 'installed', basePath: installedRoot } satisfies Source])) {
+    if (filter.type && t.category !== filter.type) continue;
+
+    let data: Record<string, unknown> = {};
+    try {
+      data = (yaml.load(readFileSync(t.manifestPath, 'utf-8')) as Record<string, unknown>) ?? {};
+    } catch {
+      // Listed with unknown version; the manifest is broken, not missing
+    }
+
+    const topic = topicOf(t.typePath, data.topic);
+    if (filter.topic && topic !== filter.topic) continue;
+
+    const version = data.version != null ? String(data.version) : '?';
+    const current = readCurrent(t.typePath);
+    const snapshot = current ? loadSnapshot(t.typePath, current) : null;
+    const latest = latestByPath.get(t.typePath);
+
+    results.push({
+      typePath: t.typePath,
+      category: t.category,
+      version,
+      description: String(data.description ?? ''),
+      topic,
+      source: snapshot?.source ?? null,
+      installedAt: installedAt(t.typePath, join(installedRoot, t.typePath), version),
+      latest: latest && version !== '?' && compareVersions(latest, version) > 0 ? latest : null,
+    });
+  }
+  return results;
+}
@@ -0,0 +1,135 @@
+import { join, relative, sep } from 'node:path';
+import { readFileSync, readdirSync, existsSync } from 'node:fs';
+import { createHash } from 'node:crypto';
+import * as settings from '../config/settings.js';
+import { APP_NAME } from '../config/branding.js';
+import { logger } from '../utils/log.js';
+import { isManifestFile } from './registry.js';
+import {
+  readCurrent,
+  loadSnapshot,
+  storeType,
+  materialize,
+  isSkippedDir,
+  objectDigest,
+  type TypeSnapshot,
+} from './store.js';
+
+// ── Installed-tree integrity ────────────────────────────────────────
+//
+// The store snapshot taken at install time records the sha256 of every
+// file. Comparing the installed tree against it reveals local edits,
+// whether by hand or by a rogue process, before an executable type runs.
+
+export type ChangeKind = 'modified' | 'missing' | 'added';
+
+export interface FileChange {
+  path: string;
+  kind: ChangeKind;
+}
+
+export interface IntegrityReport {
+  typePath: string;
+  /** Installed version per the store, or null for types installed before it existed. */
+  version: string | null;
+  changes: FileChange[];
+  manifestChanged: boolean;
+}
+
+export type ManifestGuard = 'block' | 'warn' | 'off';
+
+const GUARD_SETTING = 'run.manifest_guard';
+
+/** How `agentx run` treats an edited manifest (config.yaml run.manifest_guard). */
+export function manifestGuardMode(): ManifestGuard {
+  const mode = settings.get(GUARD_SETTING) || 'block';
+  if (mode === 'block' || mode === 'warn' || mode === 'off') return mode;
+  throw new Error(`Invalid ${GUARD_SETTING} "${mode}" (expected block, warn, or off)`);
+}
+
+function sha256(path: string): string {
+  return createHash('sha256').update(readFileSync(path)).digest('hex');
+}
+
+function listFiles(root: string, dir: string, out: string[]): void {
+  for (const entry of readdirSync(dir, { withFileTypes: true })) {
+    const full = join(dir, entry.name);
+    if (entry.isDirectory()) {
+      if (!isSkippedDir(entry.name)) listFiles(root, full, out);
+    } else if (entry.isFile()) {
+      out.push(relative(root, full).split(sep).join('/'));
+    }
+  }
+}
+
+/**
+ * Compares an installed type with the snapshot it was installed from:
+ * the default version, or the side-by-side version `at` names.
+ */
+export function verifyType(
+  typePath: string,
+  installedRoot: string,
+  at?: { dir: string; version: string },
+): IntegrityReport {
+  const dir = at?.dir ?? join(installedRoot, typePath);
+  if (!existsSync(dir)) {
+    throw new Error(`Type not installed: ${typePath}`);
+  }
+
+  const version = at?.version ?? readCurrent(typePath);
+  const snapshot = version ? loadSnapshot(typePath, version) : null;
+  if (!snapshot) {
+    return { typePath, version: null, changes: [], manifestChanged: false };
+  }
+
+  const changes: FileChange[] = [];
+  const expected = new Map(snapshot.files.map((f) => [f.path, objectDigest(f.object)]));
+  for (const [path, digest] of expected) {
+    const full = join(dir, path);
+    if (!existsSync(full)) changes.push({ path, kind: 'missing' });
+    else if (sha256(full) !== digest) changes.push({ path, kind: 'modified' });
+  }
+
+  const present: string[] = [];
+  listFiles(dir, dir, present);
+  for (const path of present) {
+    if (!expected.has(path)) changes.push({ path, kind: 'added' });
+  }
+
+  return {
+    typePath,
+    version,
+    changes: changes.sort((a, b) => a.path.localeCompare(b.path)),
+    // Only top-level manifests; nested ones belong to bundled fixtures
+    manifestChanged: changes.some((c) => !c.path.includes('/') && isManifestFile(c.path)),
+  };
+}
+
+/**
+ * Applies run.manifest_guard outside the CLI (API, daemon, pipe) to the
+ * version about to run: throws under block, logs a warning under warn.
+ */
+export function guardManifest(typePath: string, installedRoot: string, at?: { dir: string; version: string }): void {
+  const mode = manifestGuardMode();
+  if (mode === 'off' || !verifyType(typePath, installedRoot, at).manifestChanged) return;
+  const message = `The manifest of ${typePath} was modified after install`;
+  if (mode === 'block') throw new Error(`${message}; review it with \`${APP_NAME} verify ${typePath}\``);
+  logger('integrity').warn(message);
+}
+
+/**
+ * Records the installed tree, edits included, as the reviewed state of
+ * its version, and relinks it from the store so it is read-only again.
+ */
+export function acceptType(typePath: string, installedRoot: string): TypeSnapshot {
+  const version = readCurrent(typePath);
+  const previous = version ? loadSnapshot(typePath, version) : null;
+  const snapshot = storeType(
+    typePath,
+    join(installedRoot, typePath),
+    version ?? '0.0.0',
+    previous?.source,
+  );
+  materialize(snapshot, installedRoot);
+  return snapshot;
+}
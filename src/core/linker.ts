@@ -1,8 +1,20 @@
-import { join, dirname } from 'node:path';
+import { join, dirname, relative, resolve } from 'node:path';
 import { readFileSync, writeFileSync, mkdirSync, existsSync } from 'node:fs';
 import yaml from 'js-yaml';
-import type { ToolName, GenerateResult, StatusResult } from '../types/integrations.js';
+import type { ToolName, GenerateResult, StatusResult, SyncChange } from '../types/integrations.js';
 import { ALL_TOOLS } from '../types/integrations.js';
+import * as settings from '../config/settings.js';
+import { APP_NAME } from '../config/branding.js';
+import { currentVersion } from './updater.js';
+import { compareVersions } from '../utils/version.js';
+import { checkLink } from '../utils/platform.js';
+import { unifiedDiff } from '../utils/diff.js';
+import { logger } from '../utils/log.js';
+import { AgentxError } from './errors.js';
+import type { HookOptions } from './hooks.js';
+import type { GenerateInput, GeneratePlan } from '../integrations/index.js';
+
+const log = logger('linker');
 
 // ── Project config ──────────────────────────────────────────────────
 
@@ -14,32 +26,98 @@ export interface ActiveConfig {
   prompts?: string[];
 }
 
+export interface ExtensionConfig {
+  name: string;
+  /** Higher is consulted first; the catalog and unlisted extensions are 0. */
+  priority?: number;
+}
+
 export interface ProjectConfig {
   tools: string[];
   active: ActiveConfig;
+  /** CLI version that last generated this project's configs. */
+  generatedBy?: string;
+  /** Sources from lowest to highest priority; `core` is the catalog. */
+  resolution?: string[];
+  /** Source priorities, overriding resolution (the catalog may be listed too). */
+  extensions?: ExtensionConfig[];
+  /** Type path glob → the source that provides it regardless of priority. */
+  prefer?: Record<string, string>;
+  /** Lifecycle event → shell commands (see core/hooks.ts). */
+  hooks?: Record<string, string | string[]>;
+  /**
+   * Type path → the version the project needs, from `path@version`
+   * entries under active. active itself holds plain paths.
+   */
+  pins?: Record<string, string>;
+  /** Values for prompt variables, used when `--var` doesn't give one. */
+  variables?: Record<string, string>;
 }
 
+/**
+ * project.yaml as saveProject writes it: pins folded back into active
+ * entries, and generatedBy as generated_by. ProjectFileSchema in
+ * config/schema.ts is checked against it.
+ */
+export type ProjectFile = Omit<ProjectConfig, 'active' | 'generatedBy' | 'pins'> & {
+  active?: ActiveConfig;
+  generated_by?: string;
+};
+
 const PROJECT_DIR = '.agentx';
 const PROJECT_FILE = 'project.yaml';
+const PROJECT_EXTENSIONS_DIR = 'extensions';
 
 export function projectConfigPath(projectPath: string): string {
   return join(projectPath, PROJECT_DIR, PROJECT_FILE);
 }
 
+/** Extensions kept in the project itself (e.g. written by `agentx import`). */
+export function projectExtensionsDir(projectPath: string): string {
+  return join(projectPath, PROJECT_DIR, PROJECT_EXTENSIONS_DIR);
+}
+
+/** Splits an active entry like `skills/x@1.4.2` into its path and pinned version. */
+export function parsePin(ref: string): { typePath: string; version?: string } {
+  const at = ref.lastIndexOf('@');
+  if (at <= 0) return { typePath: ref };
+  const version = ref.slice(at + 1);
+  if (!version) throw new AgentxError('AGX-REG-002', `Missing version after "@" in "${ref}"`);
+  return { typePath: ref.slice(0, at), version };
+}
+
 export function loadProject(projectPath: string): ProjectConfig {
   const path = projectConfigPath(projectPath);
+  if (!existsSync(path)) {
+    throw new AgentxError('AGX-LNK-003', 'No .agentx/project.yaml here; run `agentx init` first');
+  }
   const raw = readFileSync(path, 'utf-8');
   const data = yaml.load(raw) as ProjectConfig;
-  return {
+  const pins: Record<string, string> = {};
+  const unpin = (refs: string[] | undefined) =>
+    (refs ?? []).map((ref) => {
+      const { typePath, version } = parsePin(ref);
+      if (version) pins[typePath] = version;
+      return typePath;
+    });
+  const config: ProjectConfig = {
     tools: data.tools ?? [],
     active: {
-      personas: data.active?.personas ?? [],
-      context: data.active?.context ?? [],
-      skills: data.active?.skills ?? [],
-      workflows: data.active?.workflows ?? [],
-      prompts: data.active?.prompts ?? [],
+      personas: unpin(data.active?.personas),
+      context: unpin(data.active?.context),
+      skills: unpin(data.active?.skills),
+      workflows: unpin(data.active?.workflows),
+      prompts: unpin(data.active?.prompts),
     },
+    generatedBy: (data as { generated_by?: string }).generated_by,
   };
+  if (data.resolution) config.resolution = data.resolution;
+  if (data.extensions) config.extensions = data.extensions;
+  if (data.prefer) config.prefer = data.prefer;
+  if (data.hooks) config.hooks = data.hooks;
+  if (data.variables) config.variables = data.variables;
+  if (Object.keys(pins).length > 0) config.pins = pins;
+  return config;
 }
 
 export function saveProject(
@@ -47,8 +125,18 @@ export function saveProject(
   config: ProjectConfig,
 ): void {
   const path = projectConfigPath(projectPath);
+  const { generatedBy, pins, ...rest } = config;
+  if (pins) {
+    rest.active = Object.fromEntries(
+      Object.entries(rest.active).map(([section, refs]) => [
+        section,
+        refs?.map((ref) => (pins[ref] ? `${ref}@${pins[ref]}` : ref)),
+      ]),
+    ) as ActiveConfig;
+  }
+  const data = generatedBy ? { ...rest, generated_by: generatedBy } : rest;
   mkdirSync(dirname(path), { recursive: true });
-  writeFileSync(path, yaml.dump(config, { lineWidth: -1 }), 'utf-8');
+  writeFileSync(path, yaml.dump(data, { lineWidth: -1 }), 'utf-8');
 }
 
 export function initProject(projectPath: string, tools: string[]): void {
@@ -82,56 +170,177 @@ function typeSection(typeRef: string): keyof ActiveConfig {
   };
   const section = map[prefix];
   if (!section) {
-    throw new Error(`Unknown type prefix: "${prefix}". Expected: personas, context, skills, workflows, or prompts.`);
+    throw new AgentxError('AGX-REG-001', `Unknown type prefix: "${prefix}". Expected: personas, context, skills, workflows, or prompts.`);
   }
   return section;
 }
 
-export async function addType(projectPath: string, typeRef: string): Promise<void> {
+/**
+ * Links an installed type (optionally pinned, type@version) to the
+ * project and syncs. A type that is not installed is refused with
+ * AGX-REG-003 and close matches, rather than linked for sync to skip.
+ */
+export async function addType(projectPath: string, ref: string): Promise<void> {
   const config = loadProject(projectPath);
+
+  const { getInstalledRoot } = await import('./userdata.js');
+  const { canonicalTypePath } = await import('./registry.js');
+  const installedRoot = getInstalledRoot();
+  const { typePath, version } = parsePin(ref);
+  const typeRef = canonicalTypePath(typePath, installedRoot);
   const section = typeSection(typeRef);
+  if (!existsSync(join(installedRoot, typeRef))) {
+    const { didYouMean, installedTypePaths } = await import('./registry.js');
+    const hint = didYouMean(typeRef, installedTypePaths(installedRoot));
+    throw new AgentxError(
+      'AGX-REG-003',
+      `Type "${typeRef}" is not installed.${hint || ` Run \`agentx install ${typeRef}\` first.`}`,
+    );
+  }
+
   const list = config.active[section] ?? [];
-  if (list.includes(typeRef)) {
-    throw new Error(`Type "${typeRef}" is already linked.`);
+  if (list.includes(typeRef) && (!version || config.pins?.[typeRef] === version)) {
+    throw new AgentxError('AGX-LNK-001', `Type "${typeRef}" is already linked.`);
   }
-  list.push(typeRef);
+  if (!list.includes(typeRef)) list.push(typeRef);
   config.active[section] = list;
+  setPin(config, typeRef, version);
   saveProject(projectPath, config);
   await sync(projectPath);
 }
 
-export async function removeType(projectPath: string, typeRef: string): Promise<void> {
+/** Pins typeRef to version; linking again without a version keeps an existing pin. */
+function setPin(config: ProjectConfig, typeRef: string, version: string | undefined): void {
+  if (!version) return;
+  config.pins = { ...config.pins, [typeRef]: version };
+}
+
+/**
+ * Links installed types in one project.yaml write, skipping those
+ * already linked, without syncing. Returns the newly linked refs.
+ */
+export async function linkTypes(projectPath: string, refs: string[]): Promise<string[]> {
   const config = loadProject(projectPath);
+  const { getInstalledRoot } = await import('./userdata.js');
+  const { canonicalTypePath } = await import('./registry.js');
+  const installedRoot = getInstalledRoot();
+
+  const added: string[] = [];
+  for (const ref of refs) {
+    const { typePath, version } = parsePin(ref);
+    const typeRef = canonicalTypePath(typePath, installedRoot);
+    const section = typeSection(typeRef);
+    if (!existsSync(join(installedRoot, typeRef))) {
+      throw new AgentxError('AGX-REG-003', `Type "${typeRef}" is not installed. Run \`agentx install ${typeRef}\` first.`);
+    }
+    setPin(config, typeRef, version);
+    const list = config.active[section] ?? [];
+    if (list.includes(typeRef)) continue;
+    list.push(typeRef);
+    config.active[section] = list;
+    added.push(typeRef);
+  }
+  saveProject(projectPath, config);
+  await trackProject(projectPath, config);
+  return added;
+}
+
+export async function removeType(projectPath: string, ref: string): Promise<void> {
+  const config = loadProject(projectPath);
+  const typeRef = parsePin(ref).typePath;
   const section = typeSection(typeRef);
   const list = config.active[section] ?? [];
   if (!list.includes(typeRef)) {
-    throw new Error(`Type "${typeRef}" is not linked.`);
+    throw new AgentxError('AGX-LNK-002', `Type "${typeRef}" is not linked.`);
   }
   config.active[section] = list.filter((t) => t !== typeRef);
+  if (config.pins) delete config.pins[typeRef];
   saveProject(projectPath, config);
   await sync(projectPath);
 }
 
 // ── Sync & Status ───────────────────────────────────────────────────
 
-export async function sync(projectPath: string): Promise<GenerateResult[]> {
+/** Records the project in the machine's project registry (core/projects.ts). */
+async function trackProject(projectPath: string, config: ProjectConfig, synced?: Date): Promise<void> {
+  const { recordProject } = await import('./projects.js');
+  recordProject(projectPath, config.tools, synced);
+}
+
+export interface SyncOptions {
+  /** Delete previously generated files first, normalizing output from other CLI versions. */
+  regenerateAll?: boolean;
+  /** Copy context instead of symlinking; defaults to the link.copy setting. */
+  forceCopy?: boolean;
+  /** Receives override merge and post-link-sync hook outcomes that need attention. */
+  warnings?: string[];
+  /** Asks whether an unapproved post-link-sync project hook may run. */
+  approveHook?: HookOptions['approve'];
+}
+
+export async function sync(
+  projectPath: string,
+  opts: SyncOptions = {},
+): Promise<GenerateResult[]> {
   const config = loadProject(projectPath);
   const { getInstalledRoot } = await import('./userdata.js');
   const installedPath = getInstalledRoot();
 
-  const { generate } = await import('../integrations/index.js');
+  const cliVersion = currentVersion();
+
+  const { generate, clean } = await import('../integrations/index.js');
+  const projectConfig = await followAliases(config, installedPath);
+
+  const { mergeOverrides, buildOverlays } = await import('./overrides.js');
+  for (const m of mergeOverrides(projectPath, installedPath)) {
+    const where = `${m.typePath}/${m.file}`;
+    if (m.status === 'conflict') {
+      opts.warnings?.push(`Override ${where} conflicts with upstream changes; run \`agentx overrides resolve\``);
+    } else if (m.status === 'upstream-removed') {
+      opts.warnings?.push(`Override ${where} no longer matches an upstream file`);
+    }
+  }
+  const { pinnedTypeDirs } = await import('./versions.js');
+  const typeDirs = pinnedTypeDirs(installedPath, config.pins);
+  const overlays = buildOverlays(projectPath, installedPath, projectConfig.active.context ?? [], typeDirs);
+  for (const m of await checkPins(config, installedPath)) opts.warnings?.push(m.message);
   const results: GenerateResult[] = [];
+  let failed = false;
+  const input = (toolName: string) => ({
+    toolName,
+    projectConfig,
+    installedPath,
+    typeDirs,
+    overlays,
+    projectPath,
+    cliVersion,
+    copyLinks: opts.forceCopy ?? settings.get('link.copy') === 'true',
+  });
 
+  // The generating version is recorded only when this CLI is about to
+  // change a generated file; an upgrade alone leaves project.yaml alone.
+  // It is saved first so the generated files stay newer than it, which
+  // is what status() checks for staleness.
+  if (config.generatedBy !== cliVersion && (opts.regenerateAll || (await wouldChange(config.tools.map(input))))) {
+    saveProject(projectPath, { ...config, generatedBy: cliVersion });
+  }
+
+  log.debug('sync', { project: projectPath, tools: config.tools, overlays: overlays.length });
   for (const toolName of config.tools) {
     try {
-      const result = await generate({
-        toolName,
-        projectConfig: config,
-        installedPath,
-        projectPath,
+      if (opts.regenerateAll) await clean({ toolName, projectPath });
+      const result = await generate(input(toolName));
+      const generated = result as GenerateResult;
+      results.push(generated);
+      log.info('generated', {
+        tool: toolName,
+        created: generated.created.length,
+        updated: generated.updated.length,
+        symlinked: generated.symlinked.length,
       });
-      results.push(result as GenerateResult);
     } catch (err) {
+      failed = true;
+      log.error('generate failed', { tool: toolName, error: String(err) });
       results.push({
         tool: toolName as ToolName,
         created: [],
@@ -141,9 +350,196 @@ export async function sync(projectPath: string): Promise<GenerateResult[]> {
       });
     }
   }
+
+  const { runHooks } = await import('./hooks.js');
+  const hooks = await runHooks(
+    'post-link-sync',
+    {
+      typePaths: Object.values(projectConfig.active).flatMap((refs) => refs ?? []),
+      status: failed ? 'failed' : 'ok',
+      projectPath,
+    },
+    { approve: opts.approveHook },
+  );
+  opts.warnings?.push(...hooks.warnings);
+
+  await trackProject(projectPath, config, new Date());
   return results;
 }
 
+/** Whether generating for any of inputs would create or change a file; tools that fail to plan are skipped. */
+async function wouldChange(inputs: GenerateInput[]): Promise<boolean> {
+  const { planGenerate, sameGenerated } = await import('../integrations/index.js');
+  for (const input of inputs) {
+    let plan: GeneratePlan;
+    try {
+      plan = await planGenerate(input);
+    } catch {
+      continue;
+    }
+    for (const file of plan.files) {
+      if (!existsSync(file.path) || !sameGenerated(readFileSync(file.path, 'utf-8'), file.content)) return true;
+    }
+  }
+  return false;
+}
+
+/**
+ * What sync would change, per tool and generated file, without writing
+ * anything (`link sync --dry-run`). Files are compared with what is on
+ * disk, links with where they point now. Override merges don't run, so
+ * overlays reflect the overrides as they are.
+ */
+export async function previewSync(
+  projectPath: string,
+  opts: Pick<SyncOptions, 'regenerateAll' | 'forceCopy' | 'warnings'> = {},
+): Promise<SyncChange[]> {
+  const config = loadProject(projectPath);
+  const { getInstalledRoot } = await import('./userdata.js');
+  const installedPath = getInstalledRoot();
+
+  const { planGenerate, cleanTargets, sameGenerated } = await import('../integrations/index.js');
+  const projectConfig = await followAliases(config, installedPath);
+  const { overlayDirs } = await import('./overrides.js');
+  const { pinnedTypeDirs } = await import('./versions.js');
+  const typeDirs = pinnedTypeDirs(installedPath, config.pins);
+  const overlays = overlayDirs(projectPath, installedPath, projectConfig.active.context ?? []);
+  for (const m of await checkPins(config, installedPath)) opts.warnings?.push(m.message);
+  const copy = opts.forceCopy ?? settings.get('link.copy') === 'true';
+  const rel = (path: string) => relative(projectPath, path);
+  const readText = (path: string) => (existsSync(path) ? readFileSync(path, 'utf-8') : null);
+
+  const changes: SyncChange[] = [];
+  for (const toolName of config.tools) {
+    const tool = toolName as ToolName;
+    const plan = await planGenerate({
+      toolName,
+      projectConfig,
+      installedPath,
+      typeDirs,
+      overlays,
+      projectPath,
+      cliVersion: currentVersion(),
+    });
+    opts.warnings?.push(...plan.warnings.map((w) => `${tool}: ${w}`));
+
+    for (const file of plan.files) {
+      const before = readText(file.path);
+      changes.push({
+        tool,
+        path: rel(file.path),
+        kind: 'file',
+        action: before === null ? 'create' : sameGenerated(before, file.content) ? 'unchanged' : 'update',
+        diff: unifiedDiff(before ?? '', file.content, {
+          fromLabel: before === null ? '/dev/null' : `a/${rel(file.path)}`,
+          toLabel: `b/${rel(file.path)}`,
+        }),
+      });
+    }
+
+    for (const link of plan.links) {
+      const current = checkLink(link.path);
+      const previousTarget = current ? resolve(dirname(link.path), current.target) : undefined;
+      const same =
+        previousTarget === resolve(link.target) && current?.health === 'valid' && (current.kind === 'copy') === copy;
+      changes.push({
+        tool,
+        path: rel(link.path),
+        kind: 'link',
+        action: !current ? 'create' : same ? 'unchanged' : 'update',
+        diff: '',
+        target: link.target,
+        ...(previousTarget && !same ? { previousTarget } : {}),
+      });
+    }
+
+    // --regenerate-all deletes generated files first; what isn't
+    // generated again is removed
+    if (!opts.regenerateAll) continue;
+    const kept = new Set([...plan.files, ...plan.links].map((e) => e.path));
+    for (const path of cleanTargets({ toolName, projectPath })) {
+      if (kept.has(path)) continue;
+      const link = checkLink(path);
+      changes.push({
+        tool,
+        path: rel(path),
+        kind: link ? 'link' : 'file',
+        action: 'remove',
+        diff: link ? '' : unifiedDiff(readText(path) ?? '', '', { fromLabel: `a/${rel(path)}`, toLabel: '/dev/null' }),
+        ...(link ? { previousTarget: resolve(dirname(path), link.target) } : {}),
+      });
+    }
+  }
+  return changes;
+}
+
+// ── Version pins ────────────────────────────────────────────────────
+//
+// `skills/scm/git/commit-analyzer@1.4.2` under active pins the project
+// to that version. A pin selects a side-by-side install of the version
+// when there is one (core/versions.ts); otherwise link sync and run
+// check the default version against it and say how to get a match.
+
+export interface PinMismatch {
+  typePath: string;
+  pinned: string;
+  /** The default version; null when the type isn't installed. */
+  installed: string | null;
+  message: string;
+}
+
+/** Pinned types whose installed version differs, optionally limited to typePaths. */
+export async function checkPins(
+  config: ProjectConfig,
+  installedRoot: string,
+  typePaths?: string[],
+): Promise<PinMismatch[]> {
+  if (!config.pins) return [];
+  const { listSnapshots } = await import('./store.js');
+  const { installedVersions, defaultVersion } = await import('./versions.js');
+
+  const mismatches: PinMismatch[] = [];
+  for (const [typePath, pinned] of Object.entries(config.pins)) {
+    if (typePaths && !typePaths.includes(typePath)) continue;
+    if (installedVersions(installedRoot, typePath).some((v) => compareVersions(v.version, pinned) === 0)) continue;
+    const installed = defaultVersion(installedRoot, typePath);
+
+    const stored = listSnapshots(typePath).some((s) => compareVersions(s.version, pinned) === 0);
+    let hint: string;
+    if (stored) {
+      hint = `install it side by side with \`${APP_NAME} versions add ${typePath} ${pinned}\``;
+    } else if (installed === null || compareVersions(installed, pinned) < 0) {
+      hint = `upgrade with \`${APP_NAME} catalog update && ${APP_NAME} install ${typePath}\``;
+    } else {
+      hint = `${pinned} is not stored on this machine; downgrade by installing it from a catalog at that version, or re-pin with \`${APP_NAME} link add ${typePath}@${installed}\``;
+    }
+    const what = installed === null ? 'is not installed' : `is installed at ${installed}`;
+    mismatches.push({
+      typePath,
+      pinned,
+      installed,
+      message: `${typePath} is pinned to ${pinned} but ${what}; ${hint}`,
+    });
+  }
+  return mismatches;
+}
+
+/**
+ * Points references to renamed types at their new paths, so project.yaml
+ * files written before a catalog reorganization keep generating.
+ * project.yaml itself is left alone.
+ */
+async function followAliases(config: ProjectConfig, installedRoot: string): Promise<ProjectConfig> {
+  const { canonicalTypePath } = await import('./registry.js');
+  const active = Object.fromEntries(
+    Object.entries(config.active).map(([section, refs]) => [
+      section,
+      refs?.map((ref) => canonicalTypePath(ref, installedRoot)),
+    ]),
+  ) as ActiveConfig;
+  return { ...config, active };
+}
+
 export async function status(projectPath: string): Promise<StatusResult[]> {
   const config = loadProject(projectPath);
 
@@ -159,9 +555,62 @@ export async function status(projectPath: string): Promise<StatusResult[]> {
         tool: toolName,
         status: 'error',
         files: [],
-        symlinks: { total: 0, valid: 0 },
+        symlinks: { total: 0, valid: 0, copies: 0, drifted: 0 },
       });
     }
   }
   return results;
 }
+
+// ── Version skew ────────────────────────────────────────────────────
+//
+// Shared repos get synced by whatever CLI each teammate has installed.
+// Output formats can change between releases, so a much older or newer
+// CLI regenerating next to another's files can leave a mix of both.
+
+export interface VersionSkew {
+  current: string;
+  /** Distinct versions recorded in project.yaml and generated files. */
+  recorded: string[];
+  /** Whether this CLI is older or newer than what generated the artifacts. */
+  direction: 'older' | 'newer';
+}
+
+function majorMinor(v: string): [number, number] | null {
+  const m = /^v?(\d+)(?:\.(\d+))?/.exec(v);
+  return m ? [Number(m[1]), Number(m[2] ?? 0)] : null;
+}
+
+/**
+ * Whether two CLI versions are far enough apart that their generated
+ * output may differ: a different major version, or a different minor
+ * version while still on 0.x. Development builds never skew.
+ */
+export function isSignificantSkew(a: string, b: string): boolean {
+  const pa = majorMinor(a);
+  const pb = majorMinor(b);
+  if (!pa || !pb) return false;
+  if (pa[0] !== pb[0]) return true;
+  return pa[0] === 0 && pa[1] !== pb[1];
+}
+
+/** Compares this CLI's version against what generated the project's artifacts. */
+export async function checkVersionSkew(projectPath: string): Promise<VersionSkew | null> {
+  const config = loadProject(projectPath);
+  const current = currentVersion();
+
+  const recorded = new Set<string>();
+  if (config.generatedBy) recorded.add(config.generatedBy);
+  for (const s of await status(projectPath)) {
+    if (s.generatedBy) recorded.add(s.generatedBy);
+  }
+
+  const skewed = [...recorded].filter((v) => isSignificantSkew(v, current));
+  if (skewed.length === 0) return null;
+
+  return {
+    current,
+    recorded: [...recorded],
+    direction: skewed.some((v) => compareVersions(v, current) > 0) ? 'older' : 'newer',
+  };
+}
@@ -0,0 +1,170 @@
+import { writeFileSync, linkSync, renameSync, readFileSync, rmSync, existsSync, mkdirSync } from 'node:fs';
+import { randomBytes } from 'node:crypto';
+import { join } from 'node:path';
+import { APP_NAME } from '../config/branding.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('lock');
+
+// ── Advisory locks ──────────────────────────────────────────────────
+//
+// Commands that change the installed root or a project's generated
+// files take a lock first, so two invocations (say, an editor hook and a
+// terminal) can't interleave their writes. A lock is a file holding the
+// owner's PID. It is written to a private file first and then hard-linked
+// into place, so the link either fails because the lock exists or
+// publishes a complete owner record. A lock whose process is gone is
+// stale: the taker renames it aside under a unique name, so only one
+// process can win it, and re-checks the owner before deleting it. A
+// waiting process polls until the timeout, then fails naming the owner.
+// Locks are released when the process exits.
+
+export const LOCK_FILE = `${APP_NAME}.lock`;
+export const DEFAULT_LOCK_TIMEOUT_MS = 30_000;
+const POLL_MS = 200;
+
+export interface LockOwner {
+  pid: number;
+  command: string;
+  startedAt: string;
+}
+
+export interface LockOptions {
+  /** Command taking the lock, shown to processes waiting on it. */
+  command: string;
+  timeoutMs?: number;
+  /** Called once if the lock is busy and the caller starts waiting. */
+  onWait?: (owner: LockOwner) => void;
+  /** The lock file's name in dir; LOCK_FILE by default. */
+  file?: string;
+}
+
+/** Lock files held by this process, released on exit. */
+const held = new Set<string>();
+let exitHookInstalled = false;
+
+function releaseAll(): void {
+  for (const path of held) rmSync(path, { force: true });
+  held.clear();
+}
+
+export function readLockOwner(path: string): LockOwner | null {
+  try {
+    return JSON.parse(readFileSync(path, 'utf-8')) as LockOwner;
+  } catch {
+    return null;
+  }
+}
+
+function isAlive(pid: number): boolean {
+  try {
+    process.kill(pid, 0);
+    return true;
+  } catch (err) {
+    // EPERM: the process exists but belongs to another user
+    return (err as NodeJS.ErrnoException).code === 'EPERM';
+  }
+}
+
+const uniquePath = (path: string, tag: string) => `${path}.${tag}-${process.pid}-${randomBytes(4).toString('hex')}`;
+
+function tryCreate(path: string, owner: LockOwner): boolean {
+  const tmp = uniquePath(path, 'new');
+  writeFileSync(tmp, JSON.stringify(owner));
+  try {
+    linkSync(tmp, path);
+    return true;
+  } catch (err) {
+    if ((err as NodeJS.ErrnoException).code === 'EEXIST') return false;
+    throw err;
+  } finally {
+    rmSync(tmp, { force: true });
+  }
+}
+
+const sameOwner = (a: LockOwner | null, b: LockOwner) =>
+  a !== null && a.pid === b.pid && a.startedAt === b.startedAt && a.command === b.command;
+
+/**
+ * Removes a stale lock held by stale. The lock is renamed aside first, so
+ * of several processes racing for it only one moves it; if what it moved
+ * is no longer the stale owner's (another process took the lock in the
+ * meantime), that lock is put back.
+ */
+function takeOverStale(path: string, stale: LockOwner): void {
+  const aside = uniquePath(path, 'stale');
+  try {
+    renameSync(path, aside);
+  } catch (err) {
+    if ((err as NodeJS.ErrnoException).code === 'ENOENT') return;
+    throw err;
+  }
+  try {
+    if (sameOwner(readLockOwner(aside), stale)) return;
+    try {
+      linkSync(aside, path);
+    } catch (err) {
+      if ((err as NodeJS.ErrnoException).code !== 'EEXIST') throw err;
+    }
+  } finally {
+    rmSync(aside, { force: true });
+  }
+}
+
+const sleep = (ms: number) => new Promise((r) => setTimeout(r, ms));
+
+/**
+ * Takes the lock in dir, waiting up to timeoutMs for another process to
+ * release it. Re-entrant within a process. Returns a release function.
+ */
+export async function acquireLock(dir: string, opts: LockOptions): Promise<() => void> {
+  const path = join(dir, opts.file ?? LOCK_FILE);
+  const release = () => {
+    if (held.delete(path)) rmSync(path, { force: true });
+  };
+  if (held.has(path)) return () => {};
+
+  mkdirSync(dir, { recursive: true });
+  const owner: LockOwner = { pid: process.pid, command: opts.command, startedAt: new Date().toISOString() };
+  const deadline = Date.now() + (opts.timeoutMs ?? DEFAULT_LOCK_TIMEOUT_MS);
+  let waited = false;
+
+  for (;;) {
+    if (tryCreate(path, owner)) break;
+
+    const current = readLockOwner(path);
+    if (current && !isAlive(current.pid)) {
+      log.warn('removing stale lock', { path, pid: current.pid, command: current.command });
+      takeOverStale(path, current);
+      continue;
+    }
+    if (Date.now() >= deadline) {
+      const who = current ? ` (pid ${current.pid}, \`${APP_NAME} ${current.command}\`, since ${current.startedAt})` : '';
+      throw new Error(
+        `Another ${APP_NAME} process is running${who}. Wait for it to finish, ` +
+          `or delete ${path} if that process is gone.`,
+      );
+    }
+    if (!waited && current) {
+      waited = true;
+      opts.onWait?.(current);
+    }
+    await sleep(POLL_MS);
+  }
+
+  held.add(path);
+  if (!exitHookInstalled) {
+    exitHookInstalled = true;
+    process.once('exit', releaseAll);
+  }
+  log.debug('locked', { path, command: opts.command });
+  return release;
+}
+
+/** Whether a lock in dir is held by a live process. */
+export function isLocked(dir: string): boolean {
+  const path = join(dir, LOCK_FILE);
+  if (!existsSync(path)) return false;
+  const owner = readLockOwner(path);
+  return owner !== null && isAlive(owner.pid);
+}
@@ -1,16 +1,263 @@
 import { readFileSync } from 'node:fs';
 import yaml from 'js-yaml';
+import { parseDocument, LineCounter, isNode, type Node } from 'yaml';
+import { z } from 'zod';
 import { ManifestSchema, type ManifestType } from '../config/schema.js';
-import type { Manifest, BaseManifest } from '../types/manifest.js';
+import type { Manifest, BaseManifest, WorkflowManifest } from '../types/manifest.js';
+import * as settings from '../config/settings.js';
+import { levenshtein, fuzzyThreshold } from './registry.js';
+import { workflowIssues } from './workflow-inputs.js';
 
-export function parseManifest(raw: string): Manifest {
-  const data = yaml.load(raw) as Record<string, unknown>;
-  return ManifestSchema.parse(data);
+// ── Diagnostics ─────────────────────────────────────────────────────
+
+/** A parse error or schema violation, located in the manifest source. */
+export interface ManifestIssue {
+  file: string;
+  line: number;
+  col: number;
+  /** Dotted schema path (`steps.2.skill`); empty for syntax errors. */
+  path: string;
+  message: string;
+  snippet: string;
+  /** An unknown field outside strict mode: reported, but not a failure. */
+  warning?: boolean;
+}
+
+/** The issues that make a manifest invalid, leaving warnings out. */
+export function manifestErrors(issues: ManifestIssue[]): ManifestIssue[] {
+  return issues.filter((i) => !i.warning);
+}
+
+export class ManifestError extends Error {
+  constructor(public readonly issues: ManifestIssue[]) {
+    super(issues.map(formatIssue).join('\n\n'));
+    this.name = 'ManifestError';
+  }
+}
+
+const SNIPPET_CONTEXT = 2;
+
+/** Renders the lines around line:col with a gutter and a caret under col. */
+export function sourceSnippet(raw: string, line: number, col: number): string {
+  const lines = raw.split(/\r?\n/);
+  const first = Math.max(1, line - SNIPPET_CONTEXT);
+  const last = Math.min(lines.length, line);
+  const width = String(last).length;
+
+  const out: string[] = [];
+  for (let n = first; n <= last; n++) {
+    const marker = n === line ? '>' : ' ';
+    out.push(`${marker} ${String(n).padStart(width)} | ${lines[n - 1] ?? ''}`);
+  }
+  out.push(`  ${' '.repeat(width)} | ${' '.repeat(Math.max(0, col - 1))}^`);
+  return out.join('\n');
+}
+
+export function formatIssue(issue: ManifestIssue): string {
+  const where = issue.path ? ` (at ${issue.path})` : '';
+  const level = issue.warning ? 'warning: ' : '';
+  return `${issue.file}:${issue.line}:${issue.col}: ${level}${issue.message}${where}\n${issue.snippet}`;
+}
+
+/**
+ * Finds the source node for a schema path. Paths into missing keys
+ * resolve to the closest existing ancestor, so "required" errors point
+ * at the mapping that lacks the key.
+ */
+function locate(raw: string, path: (string | number)[]): { line: number; col: number } {
+  const lineCounter = new LineCounter();
+  const doc = parseDocument(raw, { lineCounter });
+
+  for (let depth = path.length; depth >= 0; depth--) {
+    const node = depth === 0 ? doc.contents : doc.getIn(path.slice(0, depth), true);
+    if (isNode(node) && (node as Node).range) {
+      return lineCounter.linePos((node as Node).range![0]);
+    }
+  }
+  return { line: 1, col: 1 };
+}
+
+function issueAt(
+  raw: string,
+  file: string,
+  line: number,
+  col: number,
+  path: string,
+  message: string,
+): ManifestIssue {
+  return { file, line, col, path, message, snippet: sourceSnippet(raw, line, col) };
+}
+
+// ── Strict mode ─────────────────────────────────────────────────────
+//
+// The schemas accept and drop keys they don't declare, so a typo like
+// `regitry:` passes silently. Unknown keys are reported with the closest
+// declared key as a suggestion: as warnings by default, and as errors in
+// strict mode, which treats every object schema as closed
+// (additionalProperties: false). Strict is opt-in, per call or with
+// manifest.strict, so manifests with extra keys keep loading.
+
+export interface ValidateOptions {
+  /** Reject keys the schema doesn't declare. Defaults to the manifest.strict setting. */
+  strict?: boolean;
+}
+
+/** Whether parse paths validate strictly (manifest.strict in config.yaml). */
+export function strictByDefault(): boolean {
+  return settings.get('manifest.strict') === 'true';
+}
+
+interface UnknownKey {
+  path: (string | number)[];
+  suggestion: string | null;
+}
+
+function closestKey(key: string, known: string[]): string | null {
+  const best = known
+    .map((k) => ({ k, d: levenshtein(key.toLowerCase(), k.toLowerCase()) }))
+    .sort((a, b) => a.d - b.d)[0];
+  return best && best.d <= fuzzyThreshold(key) ? best.k : null;
+}
+
+// Union members are tried in order: the one that accepts the value, else
+// the one whose literal `type` matches, so a broken manifest is still
+// checked against the schema it was meant for.
+function unionMember(options: readonly z.ZodType[], data: unknown): z.ZodType | undefined {
+  const accepted = options.find((o) => o.safeParse(data).success);
+  if (accepted) return accepted;
+  const type = (data as Record<string, unknown> | null)?.type;
+  return options.find((o) => {
+    const literal = o instanceof z.ZodObject ? o.shape.type : undefined;
+    return literal instanceof z.ZodLiteral && literal.values.has(type as never);
+  });
+}
+
+/** Keys in data that schema doesn't declare, at any depth. */
+export function findUnknownKeys(schema: z.ZodType, data: unknown, path: (string | number)[] = []): UnknownKey[] {
+  if (schema instanceof z.ZodOptional || schema instanceof z.ZodNullable || schema instanceof z.ZodDefault) {
+    return data === undefined || data === null ? [] : findUnknownKeys(schema.unwrap() as z.ZodType, data, path);
+  }
+  if (schema instanceof z.ZodUnion) {
+    const member = unionMember(schema.options as readonly z.ZodType[], data);
+    return member ? findUnknownKeys(member, data, path) : [];
+  }
+  if (schema instanceof z.ZodArray) {
+    if (!Array.isArray(data)) return [];
+    return data.flatMap((item, i) => findUnknownKeys(schema.element as z.ZodType, item, [...path, i]));
+  }
+  if (schema instanceof z.ZodRecord) {
+    if (data === null || typeof data !== 'object') return [];
+    return Object.entries(data).flatMap(([k, v]) => findUnknownKeys(schema.valueType as z.ZodType, v, [...path, k]));
+  }
+  if (schema instanceof z.ZodObject) {
+    if (data === null || typeof data !== 'object' || Array.isArray(data)) return [];
+    const shape = schema.shape as Record<string, z.ZodType>;
+    const known = Object.keys(shape);
+    return Object.entries(data).flatMap(([k, v]) =>
+      k in shape
+        ? findUnknownKeys(shape[k], v, [...path, k])
+        : [{ path: [...path, k], suggestion: closestKey(k, known) }],
+    );
+  }
+  return [];
+}
+
+function unknownKeyIssues(
+  schema: z.ZodType,
+  raw: string,
+  file: string,
+  data: unknown,
+  strict: boolean,
+): ManifestIssue[] {
+  return findUnknownKeys(schema, data).map(({ path, suggestion }) => {
+    const { line, col } = locate(raw, path);
+    const key = String(path[path.length - 1]);
+    const hint = suggestion ? ` Did you mean "${suggestion}"?` : '';
+    const issue = issueAt(raw, file, line, col, path.join('.'), `Unknown field "${key}".${hint}`);
+    return strict ? issue : { ...issue, warning: true };
+  });
+}
+
+/** Workflow references and defaults, checked once the schema accepts the manifest. */
+function workflowReferenceIssues(value: unknown, raw: string, file: string): ManifestIssue[] {
+  if ((value as { type?: unknown } | null)?.type !== 'workflow') return [];
+  return workflowIssues(value as WorkflowManifest).map(({ path, message }) => {
+    const { line, col } = locate(raw, path);
+    return issueAt(raw, file, line, col, path.join('.'), message);
+  });
+}
+
+function check<T>(
+  schema: z.ZodType<T>,
+  raw: string,
+  file: string,
+  opts: ValidateOptions = {},
+): { value: T | null; issues: ManifestIssue[] } {
+  let data: unknown;
+  try {
+    data = yaml.load(raw);
+  } catch (err) {
+    if (err instanceof yaml.YAMLException) {
+      const line = err.mark ? err.mark.line + 1 : 1;
+      const col = err.mark ? err.mark.column + 1 : 1;
+      return { value: null, issues: [issueAt(raw, file, line, col, '', err.reason || err.message)] };
+    }
+    throw err;
+  }
+
+  const strict = opts.strict ?? strictByDefault();
+  const unknown = unknownKeyIssues(schema, raw, file, data, strict);
+
+  const result = schema.safeParse(data);
+  if (result.success) {
+    const issues = [...workflowReferenceIssues(result.data, raw, file), ...unknown];
+    return manifestErrors(issues).length > 0 ? { value: null, issues } : { value: result.data, issues };
+  }
+
+  const issues = result.error.issues.map((i) => {
+    const path = i.path.filter((p): p is string | number => typeof p !== 'symbol');
+    const { line, col } = locate(raw, path);
+    return issueAt(raw, file, line, col, path.join('.'), i.message);
+  });
+  return { value: null, issues: [...issues, ...unknown] };
+}
+
+/**
+ * Validates a manifest and returns every issue with its file:line:col.
+ * Unknown fields are warnings unless strict (see ValidateOptions).
+ */
+export function validateManifest(
+  raw: string,
+  file = '<manifest>',
+  opts: ValidateOptions = {},
+): ManifestIssue[] {
+  return check(ManifestSchema, raw, file, opts).issues;
+}
+
+export function validateManifestFile(path: string, opts: ValidateOptions = {}): ManifestIssue[] {
+  return validateManifest(readFileSync(path, 'utf-8'), path, opts);
+}
+
+/**
+ * Validates another YAML file the CLI reads (project.yaml) against its
+ * schema, reporting issues the way manifests do. Always strict.
+ */
+export function validateYamlFile(path: string, schema: z.ZodType): ManifestIssue[] {
+  return check(schema, readFileSync(path, 'utf-8'), path, { strict: true }).issues;
+}
+
+// ── Parsing ─────────────────────────────────────────────────────────
+
+/** Parses a manifest; unknown keys are only errors when strict (opt-in), and warnings are dropped. */
+export function parseManifest(raw: string, file = '<manifest>', opts: ValidateOptions = {}): Manifest {
+  const { value, issues } = check(ManifestSchema, raw, file, opts);
+  if (!value) throw new ManifestError(manifestErrors(issues));
+  return value;
 }
 
-export function parseManifestFile(path: string): Manifest {
+export function parseManifestFile(path: string, opts: ValidateOptions = {}): Manifest {
   const raw = readFileSync(path, 'utf-8');
-  return parseManifest(raw);
+  return parseManifest(raw, path, opts);
 }
 
 export function detectType(raw: string): ManifestType | null {
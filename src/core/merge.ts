@@ -0,0 +1,98 @@
+import { join, dirname, basename, extname } from 'node:path';
+import { existsSync, readFileSync, writeFileSync, mkdirSync, rmSync, cpSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { Source, ResolvedType } from '../types/registry.js';
+import type { ContextManifest } from '../types/manifest.js';
+import type { MergeStrategy } from '../config/schema.js';
+import { getCacheDir } from './userdata.js';
+import { loadExtensionManifest } from './extension.js';
+import { isProjectExtensionTrusted } from './trust.js';
+import { expandSources } from './context-sources.js';
+import { isSkippedDir } from './store.js';
+
+// ── Merge strategies ────────────────────────────────────────────────
+//
+// Sources are consulted in order (catalog first, then extensions) and
+// the first to provide a type path wins. An extension changes that for
+// the types it lists under `merge:` in extension.yaml:
+//
+//   merge:
+//     context/spring-boot/security: append
+//     personas/java-dev: override
+//
+// `override` makes its copy win over earlier sources'. `append` keeps
+// the earlier copy of a context and adds this extension's sources after
+// it, each file under a "From <extension>" heading; other types can't be
+// concatenated, so append is ignored for them.
+//
+// Only extensions the user chose can take a type over: installed ones,
+// and project-local ones approved with `agentx extension trust`. A
+// combined copy that involves a project-local extension is written into
+// that project's .agentx/merge, not the shared cache, so one project's
+// overrides never reach another.
+
+const MERGE_DIR = 'merge';
+/** Where appended files live inside a merged context. */
+const APPENDED_DIR = 'merged';
+
+/** The strategy source declares for typePath, or null to be shadowed. */
+export function mergeStrategy(source: Source, typePath: string): MergeStrategy | null {
+  if (source.name === 'catalog') return null;
+  if (source.local && !isProjectExtensionTrusted(source.basePath)) return null;
+  try {
+    return loadExtensionManifest(source.basePath)?.merge?.[typePath] ?? null;
+  } catch {
+    return null; // Invalid extension.yaml declares nothing
+  }
+}
+
+/** "catalog", or "catalog+acme" for a context other sources append to. */
+export function sourceLabel(resolved: ResolvedType): string {
+  return [resolved.sourceName, ...(resolved.appended ?? []).map((a) => a.sourceName)].join('+');
+}
+
+/**
+ * The directory to store for a resolved type: its source directory, or
+ * for an appended context a combined copy under the cache (or the
+ * project, for a project-local source), whose manifest lists the base
+ * sources followed by every appended source.
+ */
+export function contentDir(resolved: ResolvedType): string {
+  if (!resolved.appended?.length) return resolved.sourceDir;
+
+  const dir = join(resolved.projectDir ?? getCacheDir(), MERGE_DIR, resolved.typePath);
+  rmSync(dir, { recursive: true, force: true });
+  mkdirSync(dirname(dir), { recursive: true });
+  cpSync(resolved.sourceDir, dir, { recursive: true, filter: (src) => !isSkippedDir(basename(src)) });
+
+  const manifestPath = join(dir, basename(resolved.manifestPath));
+  const manifest = readManifest(manifestPath);
+  const sources = [...(manifest.sources ?? [])];
+
+  for (const extra of resolved.appended) {
+    const extraManifest = readManifest(extra.manifestPath);
+    for (const file of expandSources(extra.sourceDir, extraManifest.sources ?? []).files) {
+      if (file.remote) {
+        sources.push(file.source);
+        continue;
+      }
+      if (!existsSync(file.path)) continue;
+      const rel = `${APPENDED_DIR}/${extra.sourceName}/${file.source}`;
+      mkdirSync(dirname(join(dir, rel)), { recursive: true });
+      writeFileSync(join(dir, rel), `### From ${extra.sourceName}\n\n${readFileSync(file.path, 'utf-8')}`);
+      sources.push(rel);
+    }
+  }
+
+  const merged = { ...manifest, sources };
+  writeFileSync(
+    manifestPath,
+    extname(manifestPath) === '.json' ? JSON.stringify(merged, null, 2) + '\n' : yaml.dump(merged),
+  );
+  return dir;
+}
+
+function readManifest(path: string): ContextManifest {
+  // JSON manifests are valid YAML
+  return yaml.load(readFileSync(path, 'utf-8')) as ContextManifest;
+}
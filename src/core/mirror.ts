@@ -0,0 +1,67 @@
+import { execFileSync } from 'node:child_process';
+import { basename, dirname, resolve } from 'node:path';
+import { envVar } from '../config/branding.js';
+import * as settings from '../config/settings.js';
+import { downloadTarball } from '../utils/archive.js';
+import { hostOf, resolveCredential, authorizationHeader } from './credentials.js';
+
+// ── Artifact mirrors ────────────────────────────────────────────────
+//
+// Air-gapped networks can't reach GitHub, but usually have a Nexus or
+// Artifactory raw repository. With `mirror` set (or AGENTX_MIRROR),
+// the catalog and extensions are fetched from it as prebuilt bundles
+// instead of from their origins:
+//
+//   <mirror>/catalog/<ref>.tar.gz
+//   <mirror>/extensions/<name>/<ref>.tar.gz
+//
+// `agentx catalog bundle` produces such a tarball from a checkout. The
+// mirror host authenticates through the usual credential chain
+// (`agentx sources login <host>`). Artifactory API keys go in a custom
+// header: set mirror.auth_header (e.g. X-JFrog-Art-Api) and the stored
+// token is sent there as is.
+
+export type BundleKind = 'catalog' | 'extensions';
+
+/** The configured mirror's base URL, without a trailing slash, or null. */
+export function mirrorUrl(): string | null {
+  const url = process.env[envVar('MIRROR')] || settings.get('mirror');
+  return url ? url.replace(/\/+$/, '') : null;
+}
+
+/** URL of a bundle on the mirror; name is the extension for extensions. */
+export function bundleUrl(base: string, kind: BundleKind, ref: string, name?: string): string {
+  const path = kind === 'catalog' ? `catalog/${ref}` : `extensions/${name}/${ref}`;
+  return `${base}/${path.split('/').map(encodeURIComponent).join('/')}.tar.gz`;
+}
+
+/** Headers authenticating requests to the mirror, if a credential is stored. */
+export function mirrorHeaders(url: string): Record<string, string> {
+  const host = hostOf(url);
+  const cred = host ? resolveCredential(host) : null;
+  if (!cred) return {};
+  const header = settings.get('mirror.auth_header') || 'Authorization';
+  return header === 'Authorization' ? { Authorization: authorizationHeader(cred) } : { [header]: cred.secret };
+}
+
+/** Downloads a bundle from the mirror into targetDir (which must not exist). */
+export async function fetchBundle(url: string, targetDir: string): Promise<void> {
+  try {
+    await downloadTarball(url, targetDir, mirrorHeaders(url));
+  } catch (err) {
+    throw new Error(`Mirror ${mirrorUrl() ?? ''}: ${err instanceof Error ? err.message : String(err)}`);
+  }
+}
+
+/**
+ * Packs dir into a bundle a mirror can serve: a .tar.gz with one
+ * top-level directory, without git metadata.
+ */
+export function createBundle(dir: string, outFile: string): void {
+  const abs = resolve(dir);
+  execFileSync(
+    'tar',
+    ['-czf', resolve(outFile), '--exclude=.git', '--exclude=node_modules', '-C', dirname(abs), basename(abs)],
+    { stdio: 'ignore' },
+  );
+}
@@ -0,0 +1,148 @@
+import { join } from 'node:path';
+import { createHash, randomBytes } from 'node:crypto';
+import { execFileSync } from 'node:child_process';
+import {
+  readFileSync,
+  copyFileSync,
+  existsSync,
+  mkdirSync,
+  readdirSync,
+  renameSync,
+  rmSync,
+  symlinkSync,
+  cpSync,
+  lstatSync,
+  statSync,
+} from 'node:fs';
+import { getCacheDir } from './userdata.js';
+import * as settings from '../config/settings.js';
+import { childEnv } from '../utils/http.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('node-cache');
+
+// ── Shared node_modules cache ───────────────────────────────────────
+//
+// Node skills with a package-lock.json share their dependencies. The
+// first install of a lockfile runs `npm ci` into
+//
+//   ~/.agentx/cache/node/<key>/node_modules
+//
+// and every skill with the same lockfile (on the same Node major,
+// platform, and architecture) links its node_modules there, so a
+// reinstall downloads nothing. Entries are built in a private directory
+// and renamed into place, so concurrent installs never see a partial
+// entry; when two build the same key, the first rename wins and the
+// other discards its copy. Skills without a lockfile, with install
+// scripts of their own (which need the skill's files), or with
+// install.node_cache off get a plain `npm install`.
+
+const NODE_CACHE_DIR = 'node';
+const LOCKFILE = 'package-lock.json';
+const PARTIAL_MARK = '.partial-';
+const INSTALL_SCRIPTS = ['preinstall', 'install', 'postinstall', 'prepare'];
+
+export function nodeCacheDir(): string {
+  return join(getCacheDir(), NODE_CACHE_DIR);
+}
+
+export function nodeCacheEnabled(): boolean {
+  return settings.get('install.node_cache') !== 'false';
+}
+
+/** The cache key of a skill's dependencies, or null when they can't be shared. */
+export function nodeCacheKey(typeDir: string): string | null {
+  const lockPath = join(typeDir, LOCKFILE);
+  if (!existsSync(lockPath)) return null;
+  const pkg = JSON.parse(readFileSync(join(typeDir, 'package.json'), 'utf-8')) as { scripts?: Record<string, string> };
+  if (INSTALL_SCRIPTS.some((s) => pkg.scripts?.[s])) return null;
+  const nodeMajor = process.versions.node.split('.')[0];
+  return createHash('sha256')
+    .update(readFileSync(lockPath))
+    .update(`\0node${nodeMajor}-${process.platform}-${process.arch}`)
+    .digest('hex')
+    .slice(0, 32);
+}
+
+function entryModules(key: string): string {
+  return join(nodeCacheDir(), key, 'node_modules');
+}
+
+/** Points typeDir/node_modules at the cache entry, copying where links aren't allowed. */
+function linkModules(key: string, typeDir: string): void {
+  const target = join(typeDir, 'node_modules');
+  if (existsSync(target) || isLink(target)) rmSync(target, { recursive: true, force: true });
+  try {
+    symlinkSync(entryModules(key), target, process.platform === 'win32' ? 'junction' : 'dir');
+  } catch (err) {
+    log.debug('link failed, copying', { dir: typeDir, error: String(err) });
+    cpSync(entryModules(key), target, { recursive: true, verbatimSymlinks: true });
+  }
+}
+
+function isLink(path: string): boolean {
+  try {
+    return lstatSync(path).isSymbolicLink();
+  } catch {
+    return false;
+  }
+}
+
+/** Links a cached entry into typeDir; false when there is none yet. */
+export function linkCachedModules(typeDir: string): boolean {
+  const key = nodeCacheKey(typeDir);
+  if (!key || !existsSync(entryModules(key))) return false;
+  linkModules(key, typeDir);
+  log.debug('cache hit', { dir: typeDir, key });
+  return true;
+}
+
+/** Runs `npm ci` for typeDir's lockfile into the cache and links the result in. */
+export function populateNodeCache(typeDir: string): void {
+  const key = nodeCacheKey(typeDir);
+  if (!key) throw new Error(`Dependencies of ${typeDir} can't be cached`);
+  const entry = join(nodeCacheDir(), key);
+  if (!existsSync(entry)) {
+    const partial = `${entry}${PARTIAL_MARK}${process.pid}-${randomBytes(4).toString('hex')}`;
+    mkdirSync(partial, { recursive: true });
+    try {
+      copyFileSync(join(typeDir, 'package.json'), join(partial, 'package.json'));
+      copyFileSync(join(typeDir, LOCKFILE), join(partial, LOCKFILE));
+      log.debug('npm ci', { dir: partial, key });
+      execFileSync('npm', ['ci', '--prefer-offline'], { cwd: partial, env: childEnv(), stdio: 'ignore' });
+      mkdirSync(join(partial, 'node_modules'), { recursive: true });
+      renameSync(partial, entry);
+    } catch (err) {
+      rmSync(partial, { recursive: true, force: true });
+      // Another install finished the same entry first
+      if (!existsSync(entry)) throw err;
+    }
+  }
+  linkModules(key, typeDir);
+}
+
+export interface NodeCacheStats {
+  entries: number;
+  bytes: number;
+}
+
+function treeSize(dir: string): number {
+  let bytes = 0;
+  for (const entry of readdirSync(dir, { withFileTypes: true })) {
+    const full = join(dir, entry.name);
+    if (entry.isDirectory()) bytes += treeSize(full);
+    else if (entry.isFile()) bytes += statSync(full).size;
+  }
+  return bytes;
+}
+
+export function nodeCacheStats(): NodeCacheStats {
+  const dir = nodeCacheDir();
+  if (!existsSync(dir)) return { entries: 0, bytes: 0 };
+  const entries = readdirSync(dir).filter((name) => !name.includes(PARTIAL_MARK));
+  return { entries: entries.length, bytes: treeSize(dir) };
+}
+
+export function clearNodeCache(): void {
+  rmSync(nodeCacheDir(), { recursive: true, force: true });
+}
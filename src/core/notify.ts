@@ -0,0 +1,242 @@
+import { join } from 'node:path';
+import { randomBytes } from 'node:crypto';
+import {
+  readFileSync,
+  openSync,
+  writeSync,
+  fsyncSync,
+  closeSync,
+  renameSync,
+  readdirSync,
+  rmSync,
+  mkdirSync,
+  watchFile,
+  unwatchFile,
+} from 'node:fs';
+import { createConnection, createServer, type Server } from 'node:net';
+import { getHomeRoot } from './userdata.js';
+
+// ── Constants ───────────────────────────────────────────────────────
+
+const SEQUENCE_FILE = 'installed.seq';
+const SOCKETS_DIR = 'notify';
+const BROADCAST_TIMEOUT_MS = 250;
+const POLL_INTERVAL_MS = 1000;
+
+// ── Types ───────────────────────────────────────────────────────────
+
+export type ChangeKind = 'install' | 'uninstall' | 'update';
+
+export interface ChangeEvent {
+  seq: number;
+  kind: ChangeKind;
+  typePaths: string[];
+  pid: number;
+  at: string;
+}
+
+export interface SubscribeOptions {
+  /** Also listen on a unix socket for immediate delivery (ignored on Windows). */
+  socket?: boolean;
+}
+
+export interface Subscription {
+  close(): void;
+}
+
+// ── Paths ───────────────────────────────────────────────────────────
+
+export function sequencePath(): string {
+  return join(getHomeRoot(), SEQUENCE_FILE);
+}
+
+function socketsDir(): string {
+  return join(getHomeRoot(), SOCKETS_DIR);
+}
+
+// ── Sequence file ───────────────────────────────────────────────────
+
+export function readLastChange(): ChangeEvent | null {
+  try {
+    return JSON.parse(readFileSync(sequencePath(), 'utf-8')) as ChangeEvent;
+  } catch {
+    return null;
+  }
+}
+
+export function currentSequence(): number {
+  return readLastChange()?.seq ?? 0;
+}
+
+/**
+ * Replaces the sequence file in one step: the event is written and synced
+ * to a temp file unique to this call, then renamed over the old file, so
+ * pollers never read a half-written event.
+ */
+function writeSequence(event: ChangeEvent): void {
+  const path = sequencePath();
+  const tmp = `${path}.${process.pid}-${randomBytes(4).toString('hex')}.tmp`;
+  mkdirSync(getHomeRoot(), { recursive: true });
+  const fd = openSync(tmp, 'w');
+  try {
+    writeSync(fd, JSON.stringify(event));
+    fsyncSync(fd);
+  } finally {
+    closeSync(fd);
+  }
+  try {
+    renameSync(tmp, path);
+  } catch (err) {
+    rmSync(tmp, { force: true });
+    throw err;
+  }
+}
+
+// ── Publish ─────────────────────────────────────────────────────────
+
+/**
+ * Records that installed types changed and tells any listening agentx
+ * processes. The sequence file is the source of truth; the socket
+ * broadcast is a best-effort fast path, so failures are swallowed and
+ * dead sockets from crashed subscribers are cleaned up along the way.
+ */
+export async function notifyChange(
+  kind: ChangeKind,
+  typePaths: string[],
+): Promise<ChangeEvent> {
+  const event: ChangeEvent = {
+    seq: currentSequence() + 1,
+    kind,
+    typePaths,
+    pid: process.pid,
+    at: new Date().toISOString(),
+  };
+
+  try {
+    writeSequence(event);
+  } catch {
+    // Best-effort: a read-only home must not fail the install itself
+  }
+
+  await broadcast(event);
+  return event;
+}
+
+async function broadcast(event: ChangeEvent): Promise<void> {
+  if (process.platform === 'win32') return;
+
+  let entries: string[];
+  try {
+    entries = readdirSync(socketsDir()).filter((f) => f.endsWith('.sock'));
+  } catch {
+    return;
+  }
+
+  const payload = JSON.stringify(event) + '\n';
+  await Promise.all(
+    entries
+      .filter((f) => f !== `${process.pid}.sock`)
+      .map((f) => sendTo(join(socketsDir(), f), payload)),
+  );
+}
+
+function sendTo(socketPath: string, payload: string): Promise<void> {
+  return new Promise((resolve) => {
+    const conn = createConnection(socketPath);
+    const timer = setTimeout(() => {
+      conn.destroy();
+      resolve();
+    }, BROADCAST_TIMEOUT_MS);
+
+    conn.on('connect', () => {
+      conn.end(payload);
+    });
+    conn.on('close', () => {
+      clearTimeout(timer);
+      resolve();
+    });
+    conn.on('error', (err: NodeJS.ErrnoException) => {
+      if (err.code === 'ECONNREFUSED' || err.code === 'ENOENT') {
+        rmSync(socketPath, { force: true });
+      }
+    });
+  });
+}
+
+// ── Subscribe ───────────────────────────────────────────────────────
+
+/**
+ * Calls onChange whenever another process bumps the sequence file. Events
+ * may arrive twice (socket + file poll); callers dedupe by seq, which
+ * this wrapper already does.
+ */
+export function subscribe(
+  onChange: (event: ChangeEvent) => void,
+  opts: SubscribeOptions = {},
+): Subscription {
+  let lastSeq = currentSequence();
+
+  const deliver = (event: ChangeEvent | null) => {
+    if (!event || event.seq <= lastSeq || event.pid === process.pid) return;
+    lastSeq = event.seq;
+    onChange(event);
+  };
+
+  const path = sequencePath();
+  const onPoll = () => deliver(readLastChange());
+  watchFile(path, { interval: POLL_INTERVAL_MS, persistent: false }, onPoll);
+
+  let server: Server | null = null;
+  let socketPath = '';
+  if (opts.socket && process.platform !== 'win32') {
+    socketPath = join(socketsDir(), `${process.pid}.sock`);
+    try {
+      mkdirSync(socketsDir(), { recursive: true, mode: 0o700 });
+      rmSync(socketPath, { force: true });
+      server = createServer((conn) => {
+        let buf = '';
+        conn.on('data', (chunk) => {
+          buf += chunk.toString();
+        });
+        conn.on('end', () => {
+          for (const line of buf.split('\n')) {
+            if (!line.trim()) continue;
+            try {
+              deliver(JSON.parse(line) as ChangeEvent);
+            } catch {
+              // Ignore malformed messages
+            }
+          }
+        });
+      });
+      server.unref();
+      // A socket file left by a crashed process with our PID makes listen
+      // fail with EADDRINUSE: unlink it and retry once. Any other failure
+      // drops the fast path quietly; the file poll still delivers events.
+      let retried = false;
+      server.on('error', (err: NodeJS.ErrnoException) => {
+        if (err.code === 'EADDRINUSE' && !retried && server) {
+          retried = true;
+          rmSync(socketPath, { force: true });
+          server.listen(socketPath);
+          return;
+        }
+        server?.close();
+        server = null;
+      });
+      server.listen(socketPath);
+    } catch {
+      server = null;
+    }
+  }
+
+  return {
+    close() {
+      unwatchFile(path, onPoll);
+      if (server) {
+        server.close();
+        rmSync(socketPath, { force: true });
+      }
+    },
+  };
+}
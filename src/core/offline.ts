@@ -0,0 +1,25 @@
+import { executionContext } from '../config/context.js';
+
+// ── Offline mode ────────────────────────────────────────────────────
+//
+// With --offline (or AGENTX_OFFLINE, or the `offline` setting) nothing
+// reaches for the network: update checks, catalog fetches, extension
+// syncs and npm installs are skipped with a "[SKIP] offline" message
+// instead of hanging until a timeout, and what can run from local state
+// does. Operations that only make sense online fail fast instead.
+
+export function isOffline(): boolean {
+  return executionContext().offline;
+}
+
+/** The message shown in place of a network operation skipped offline. */
+export function offlineSkip(what: string): string {
+  return `[SKIP] offline: ${what}`;
+}
+
+/** Throws when offline; for operations that cannot be skipped. */
+export function requireOnline(what: string): void {
+  if (isOffline()) {
+    throw new Error(`${what} needs the network, but offline mode is on (drop --offline, AGENTX_OFFLINE, or the offline setting).`);
+  }
+}
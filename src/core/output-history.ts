@@ -0,0 +1,101 @@
+import { join } from 'node:path';
+import { readFileSync, readdirSync, statSync, existsSync, mkdirSync, copyFileSync, rmSync } from 'node:fs';
+import type { SkillManifest } from '../types/manifest.js';
+import * as settings from '../config/settings.js';
+import { getSkillRegistryPath } from './userdata.js';
+import { nameFromPath } from './registry.js';
+
+// ── Output history ──────────────────────────────────────────────────
+//
+// Skills save their result to output/latest.json. After each run the
+// runtime copies a new latest.json to output/history/<timestamp>.json and
+// keeps the newest N copies: registry.output.history in the manifest,
+// else the output.history setting, else DEFAULT_RETENTION. 0 disables it.
+
+export const DEFAULT_RETENTION = 20;
+
+export interface HistoryEntry {
+  /** 1 is the most recent run. */
+  run: number;
+  file: string;
+  savedAt: Date;
+  bytes: number;
+}
+
+/** Identifies a version of latest.json, or null when there is none. */
+export type OutputStamp = string | null;
+
+export function outputDir(typePath: string): string {
+  return join(getSkillRegistryPath(nameFromPath(typePath)), 'output');
+}
+
+export function historyRetention(manifest: SkillManifest): number {
+  const declared = manifest.registry?.output?.history;
+  if (declared !== undefined) return declared;
+  const configured = Number.parseInt(settings.get('output.history'), 10);
+  return Number.isNaN(configured) || configured < 0 ? DEFAULT_RETENTION : configured;
+}
+
+export function outputStamp(registryPath: string): OutputStamp {
+  const latest = join(registryPath, 'output', 'latest.json');
+  if (!existsSync(latest)) return null;
+  const st = statSync(latest);
+  return `${st.mtimeMs}:${st.size}`;
+}
+
+function historyFiles(registryPath: string): string[] {
+  const dir = join(registryPath, 'output', 'history');
+  if (!existsSync(dir)) return [];
+  return readdirSync(dir).filter((f) => f.endsWith('.json')).sort().reverse();
+}
+
+/**
+ * Archives latest.json when it changed since before (a stamp taken before
+ * the run), then prunes history to the retention count. Returns the
+ * archived path, if any.
+ */
+export function recordOutput(registryPath: string, before: OutputStamp, retention: number): string | null {
+  const after = outputStamp(registryPath);
+  let archived: string | null = null;
+  if (retention > 0 && after !== null && after !== before) {
+    const dir = join(registryPath, 'output', 'history');
+    mkdirSync(dir, { recursive: true });
+    archived = join(dir, `${new Date().toISOString().replace(/[:.]/g, '-')}.json`);
+    copyFileSync(join(registryPath, 'output', 'latest.json'), archived);
+  }
+  for (const stale of historyFiles(registryPath).slice(retention)) {
+    rmSync(join(registryPath, 'output', 'history', stale), { force: true });
+  }
+  return archived;
+}
+
+/** A skill's archived outputs, newest first. */
+export function listHistory(typePath: string): HistoryEntry[] {
+  const registryPath = getSkillRegistryPath(nameFromPath(typePath));
+  return historyFiles(registryPath).map((file, i) => {
+    const st = statSync(join(registryPath, 'output', 'history', file));
+    return { run: i + 1, file, savedAt: st.mtime, bytes: st.size };
+  });
+}
+
+/** The output of the run-th most recent run, or latest.json without run. */
+export function readOutput(typePath: string, run?: number): { entry: HistoryEntry | null; output: unknown } {
+  let path = join(outputDir(typePath), 'latest.json');
+  let entry: HistoryEntry | null = null;
+  if (run !== undefined) {
+    const history = listHistory(typePath);
+    entry = history[run - 1] ?? null;
+    if (!entry) {
+      throw new Error(`${typePath} has ${history.length} run(s) in its output history, not ${run}`);
+    }
+    path = join(outputDir(typePath), 'history', entry.file);
+  } else if (!existsSync(path)) {
+    throw new Error(`${typePath} has not saved any output`);
+  }
+  const raw = readFileSync(path, 'utf-8');
+  try {
+    return { entry, output: JSON.parse(raw) };
+  } catch {
+    return { entry, output: raw };
+  }
+}
@@ -0,0 +1,69 @@
+import { join } from 'node:path';
+import { readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import * as settings from '../config/settings.js';
+import { validateJson, type JsonSchema } from '../utils/json-schema.js';
+import type { OutputDeclaration } from '../types/manifest.js';
+
+// ── Output schemas ──────────────────────────────────────────────────
+//
+// A skill may declare the shape of its output: outputs.schema is a JSON
+// Schema, embedded in the manifest or in a JSON or YAML file next to it.
+// The runtime parses each successful run's output (as YAML when
+// outputs.format is yaml, else as JSON) and checks it against the schema.
+// run.output_schema decides what a mismatch does: warn notes it on
+// stderr, fail also fails the run, off skips the check.
+
+export type OutputSchemaMode = 'fail' | 'warn' | 'off';
+
+const MODE_SETTING = 'run.output_schema';
+
+/** How a run treats output that breaks its schema (config.yaml run.output_schema). */
+export function outputSchemaMode(): OutputSchemaMode {
+  const mode = settings.get(MODE_SETTING) || 'warn';
+  if (mode === 'fail' || mode === 'warn' || mode === 'off') return mode;
+  throw new Error(`Invalid ${MODE_SETTING} "${mode}" (expected fail, warn, or off)`);
+}
+
+/** The declared output schema, read from its file when it is a path; null when none is declared. */
+export function loadOutputSchema(typeDir: string, outputs: OutputDeclaration | undefined): JsonSchema | null {
+  const schema = outputs?.schema;
+  if (schema === undefined) return null;
+  if (typeof schema !== 'string') return schema;
+  const path = join(typeDir, schema);
+  try {
+    return yaml.load(readFileSync(path, 'utf-8'), { schema: yaml.JSON_SCHEMA }) as JsonSchema;
+  } catch (err) {
+    throw new Error(`Cannot read outputs.schema ${path}: ${err instanceof Error ? err.message : String(err)}`);
+  }
+}
+
+/**
+ * The ways a run's output breaks the declared schema; empty when it
+ * conforms or no schema is declared. Output that doesn't parse, or a
+ * schema that can't be read, is reported as one violation.
+ */
+export function checkOutput(typeDir: string, outputs: OutputDeclaration | undefined, stdout: string): string[] {
+  let schema: JsonSchema | null;
+  try {
+    schema = loadOutputSchema(typeDir, outputs);
+  } catch (err) {
+    return [err instanceof Error ? err.message : String(err)];
+  }
+  if (schema === null) return [];
+
+  const format = outputs?.format === 'yaml' || outputs?.format === 'yml' ? 'YAML' : 'JSON';
+  let value: unknown;
+  try {
+    // JSON_SCHEMA keeps YAML to JSON's types: a date stays a string
+    // rather than becoming a Date the validator would see as an object
+    value = format === 'YAML' ? yaml.load(stdout, { schema: yaml.JSON_SCHEMA }) : JSON.parse(stdout);
+  } catch (err) {
+    return [`output is not valid ${format}: ${err instanceof Error ? err.message : String(err)}`];
+  }
+  try {
+    return validateJson(value, schema);
+  } catch (err) {
+    return [`outputs.schema: ${err instanceof Error ? err.message : String(err)}`];
+  }
+}
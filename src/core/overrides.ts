@@ -0,0 +1,258 @@
+import { join, dirname, relative, sep } from 'node:path';
+import {
+  readFileSync,
+  writeFileSync,
+  readdirSync,
+  existsSync,
+  mkdirSync,
+  rmSync,
+  symlinkSync,
+} from 'node:fs';
+import * as settings from '../config/settings.js';
+import { installedTypePaths } from './registry.js';
+import { readCurrent } from './store.js';
+import { mergeDriverFor, hasConflictMarkers, type MergeDriver } from '../utils/merge.js';
+
+// ── Project overrides ───────────────────────────────────────────────
+//
+// A project can replace individual files of an installed type:
+//
+//   .agentx/overrides/<type-path>/<file>         the project's version
+//   .agentx/overrides/.base/<type-path>/<file>   upstream when last merged
+//
+// When upstream changes, `link sync` three-way merges the change into
+// the override (base = old upstream, theirs = new upstream, ours = the
+// override) so overrides don't silently go stale. Conflicts are left in
+// the override with markers for `agentx overrides resolve`.
+
+const OVERRIDES_DIR = join('.agentx', 'overrides');
+const BASE_DIR = '.base';
+const OVERLAY_DIR = join('.agentx', 'overlay');
+
+export interface OverrideFile {
+  typePath: string;
+  /** Path within the type, '/'-separated. */
+  file: string;
+  conflicted: boolean;
+}
+
+export type MergeStatus = 'unchanged' | 'merged' | 'conflict' | 'upstream-removed';
+
+export interface OverrideMerge {
+  typePath: string;
+  file: string;
+  status: MergeStatus;
+}
+
+export function overridesDir(projectPath: string): string {
+  return join(projectPath, OVERRIDES_DIR);
+}
+
+function overridePath(projectPath: string, typePath: string, file: string): string {
+  return join(overridesDir(projectPath), typePath, file);
+}
+
+function basePath(projectPath: string, typePath: string, file: string): string {
+  return join(overridesDir(projectPath), BASE_DIR, typePath, file);
+}
+
+function writeFile(path: string, content: string): void {
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, content);
+}
+
+function readOrNull(path: string): string | null {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return null;
+  }
+}
+
+function listFiles(dir: string, skip: (name: string) => boolean = () => false): string[] {
+  const out: string[] = [];
+  const walk = (d: string) => {
+    for (const entry of readdirSync(d, { withFileTypes: true })) {
+      if (skip(entry.name)) continue;
+      const full = join(d, entry.name);
+      if (entry.isDirectory()) walk(full);
+      else if (entry.isFile()) out.push(relative(dir, full).split(sep).join('/'));
+    }
+  };
+  if (existsSync(dir)) walk(dir);
+  return out.sort();
+}
+
+/** Override files, each attributed to the installed type containing it. */
+export function listOverrides(projectPath: string, installedRoot: string): OverrideFile[] {
+  const root = overridesDir(projectPath);
+  // Longest first, so nested type paths win over their parents
+  const types = installedTypePaths(installedRoot).sort((a, b) => b.length - a.length);
+
+  return listFiles(root, (name) => name === BASE_DIR).flatMap((rel) => {
+    const typePath = types.find((t) => rel.startsWith(`${t}/`));
+    if (!typePath) return [];
+    const file = rel.slice(typePath.length + 1);
+    const content = readOrNull(join(root, rel)) ?? '';
+    return [{ typePath, file, conflicted: hasConflictMarkers(content) }];
+  });
+}
+
+/** Starts overriding a file: copies the installed version into the project. */
+export function addOverride(projectPath: string, installedRoot: string, typePath: string, file: string): string {
+  const upstream = readOrNull(join(installedRoot, typePath, file));
+  if (upstream === null) {
+    throw new Error(`${typePath} has no file ${file}`);
+  }
+  const path = overridePath(projectPath, typePath, file);
+  if (existsSync(path)) {
+    throw new Error(`${typePath}/${file} is already overridden`);
+  }
+  writeFile(path, upstream);
+  writeFile(basePath(projectPath, typePath, file), upstream);
+  return path;
+}
+
+export function configuredMergeDriver(): MergeDriver {
+  return mergeDriverFor(settings.get('overrides.merge_driver'));
+}
+
+/**
+ * Folds upstream changes into every override whose upstream file
+ * changed since it was last merged. Overrides with unresolved conflicts
+ * are left alone until resolved.
+ */
+export function mergeOverrides(
+  projectPath: string,
+  installedRoot: string,
+  driver: MergeDriver = configuredMergeDriver(),
+): OverrideMerge[] {
+  const results: OverrideMerge[] = [];
+
+  for (const { typePath, file, conflicted } of listOverrides(projectPath, installedRoot)) {
+    const upstream = readOrNull(join(installedRoot, typePath, file));
+    if (upstream === null) {
+      results.push({ typePath, file, status: 'upstream-removed' });
+      continue;
+    }
+
+    const baseFile = basePath(projectPath, typePath, file);
+    const base = readOrNull(baseFile);
+    if (conflicted || base === upstream) {
+      results.push({ typePath, file, status: conflicted ? 'conflict' : 'unchanged' });
+      continue;
+    }
+
+    const ours = readFileSync(overridePath(projectPath, typePath, file), 'utf-8');
+    const version = readCurrent(typePath);
+    // No recorded base (override created by hand): diff against empty
+    const merged = driver(base ?? '', ours, upstream, {
+      ours: 'override',
+      base: 'previous upstream',
+      theirs: version ? `upstream ${version}` : 'upstream',
+    });
+
+    writeFile(overridePath(projectPath, typePath, file), merged.text);
+    writeFile(baseFile, upstream);
+    results.push({ typePath, file, status: merged.conflicts > 0 ? 'conflict' : 'merged' });
+  }
+  return results;
+}
+
+export type Resolution = 'ours' | 'theirs' | 'merged';
+
+// A conflict with or without the diff3 base section (|||||||), so
+// two-way markers from an external driver resolve too.
+const CONFLICT_BLOCK = /^<<<<<<<(?: [^\n]*)?\n([\s\S]*?)(?:^\|{7}(?: [^\n]*)?\n[\s\S]*?)?^=======\n([\s\S]*?)^>>>>>>>(?: [^\n]*)?(?:\n|$)/gm;
+
+/**
+ * Settles a conflicted override: keep the project's side of each
+ * conflict, take upstream (dropping the override), or accept the file
+ * as hand-edited once no markers remain.
+ */
+export function resolveOverride(
+  projectPath: string,
+  installedRoot: string,
+  typePath: string,
+  file: string,
+  resolution: Resolution,
+): void {
+  const path = overridePath(projectPath, typePath, file);
+  const content = readOrNull(path);
+  if (content === null) {
+    throw new Error(`${typePath}/${file} is not overridden`);
+  }
+
+  switch (resolution) {
+    case 'ours': {
+      const resolved = content.replace(CONFLICT_BLOCK, '$1');
+      if (hasConflictMarkers(resolved)) {
+        throw new Error(`${typePath}/${file} has conflict markers that could not be resolved; edit it by hand`);
+      }
+      writeFileSync(path, resolved);
+      return;
+    }
+    case 'theirs':
+      removeOverride(projectPath, typePath, file);
+      return;
+    case 'merged':
+      if (hasConflictMarkers(content)) {
+        throw new Error(`${typePath}/${file} still has conflict markers`);
+      }
+  }
+}
+
+export function removeOverride(projectPath: string, typePath: string, file: string): void {
+  rmSync(overridePath(projectPath, typePath, file), { force: true });
+  rmSync(basePath(projectPath, typePath, file), { force: true });
+}
+
+// ── Overlays ────────────────────────────────────────────────────────
+
+function overlayDir(projectPath: string, typePath: string): string {
+  return join(projectPath, OVERLAY_DIR, typePath.replace(/\//g, '--'));
+}
+
+/** typePath → overlay directory that buildOverlays would make, without making them. */
+export function overlayDirs(projectPath: string, installedRoot: string, typePaths: string[]): Record<string, string> {
+  const overridden = new Set(listOverrides(projectPath, installedRoot).map((o) => o.typePath));
+  return Object.fromEntries(typePaths.filter((t) => overridden.has(t)).map((t) => [t, overlayDir(projectPath, t)]));
+}
+
+/**
+ * For each type with overrides, a directory mirroring the installed type
+ * with overridden files swapped in (all symlinks), to link in place of
+ * the installed directory. Returns typePath → overlay directory.
+ * typeDirs points pinned types at their side-by-side versions.
+ */
+export function buildOverlays(
+  projectPath: string,
+  installedRoot: string,
+  typePaths: string[],
+  typeDirs: Record<string, string> = {},
+): Record<string, string> {
+  const overlays: Record<string, string> = {};
+  const overridden = new Map<string, Set<string>>();
+  for (const o of listOverrides(projectPath, installedRoot)) {
+    if (!overridden.has(o.typePath)) overridden.set(o.typePath, new Set());
+    overridden.get(o.typePath)!.add(o.file);
+  }
+
+  rmSync(join(projectPath, OVERLAY_DIR), { recursive: true, force: true });
+  for (const typePath of typePaths) {
+    const files = overridden.get(typePath);
+    if (!files) continue;
+
+    const dir = overlayDir(projectPath, typePath);
+    const source = typeDirs[typePath] ?? join(installedRoot, typePath);
+    for (const file of listFiles(source)) {
+      const target = files.has(file)
+        ? overridePath(projectPath, typePath, file)
+        : join(source, file);
+      mkdirSync(dirname(join(dir, file)), { recursive: true });
+      symlinkSync(target, join(dir, file));
+    }
+    overlays[typePath] = dir;
+  }
+  return overlays;
+}
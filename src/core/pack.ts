@@ -0,0 +1,165 @@
+import { execFileSync } from 'node:child_process';
+import { join, dirname, basename } from 'node:path';
+import { tmpdir } from 'node:os';
+import { readFileSync, writeFileSync, existsSync, mkdirSync, mkdtempSync, rmSync, cpSync } from 'node:fs';
+import type { DependencyNode, ResolvedType, Source } from '../types/registry.js';
+import { buildDependencyTree, flattenTree, categoryFromPath, resolveType } from './registry.js';
+import { isSkippedDir } from './store.js';
+import { installAll } from './transaction.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('pack');
+
+// ── Prompt packs ────────────────────────────────────────────────────
+//
+// `agentx pack export` bundles an installed prompt with every type it
+// resolves to (personas, context, skills, workflows, templates) into a
+// .tar.gz, so a teammate can compose the same prompt without the
+// catalog or extensions it came from:
+//
+//   agentx-pack.json     format, export time, root prompt, types in order
+//   types/<typePath>/... each type's installed files
+//
+// Secrets stay behind: skill registries (tokens.env, config, state) are
+// not types, and .env/.npmrc files inside a type are skipped. `agentx
+// pack import` installs the types through the usual install transaction.
+
+const PACK_FORMAT = 1;
+const MANIFEST_FILE = 'agentx-pack.json';
+const TYPES_DIR = 'types';
+
+interface PackManifest {
+  format: number;
+  exportedAt: string;
+  root: string;
+  /** Type paths, dependencies before dependents. */
+  types: string[];
+}
+
+export interface PackExportResult {
+  root: string;
+  types: string[];
+}
+
+export interface PackImportOptions {
+  /** Replace types that are already installed. */
+  overwrite?: boolean;
+}
+
+export interface PackImportResult {
+  root: string;
+  installed: string[];
+  /** Already installed and left alone. */
+  skipped: string[];
+  warnings: string[];
+}
+
+function isSecretFile(name: string): boolean {
+  return name === '.env' || name.endsWith('.env') || name === '.npmrc';
+}
+
+function withTempDir<T>(fn: (dir: string) => Promise<T> | T): Promise<T> {
+  const dir = mkdtempSync(join(tmpdir(), 'agentx-pack-'));
+  return Promise.resolve()
+    .then(() => fn(dir))
+    .finally(() => rmSync(dir, { recursive: true, force: true }));
+}
+
+/** Dependencies the tree references that don't resolve. */
+function unresolved(node: DependencyNode, parent: string | null, out: string[]): string[] {
+  if (!node.deduped && !node.resolved) {
+    out.push(parent ? `${node.typePath} (needed by ${parent})` : node.typePath);
+  }
+  for (const child of node.children) unresolved(child, node.typePath, out);
+  return out;
+}
+
+export async function exportPack(
+  promptPath: string,
+  installedRoot: string,
+  archivePath: string,
+): Promise<PackExportResult> {
+  if (categoryFromPath(promptPath) !== 'prompt') {
+    throw new Error(`${promptPath} is not a prompt; pack export takes a prompts/ type path`);
+  }
+  const sources: Source[] = [{ name: 'installed', basePath: installedRoot }];
+
+  return withTempDir((staging) => {
+    // Resolved against an empty root, so installed types aren't skipped
+    const tree = buildDependencyTree(promptPath, sources, join(staging, 'none'));
+    const missing = unresolved(tree, null, []);
+    if (missing.length > 0) {
+      throw new Error(`Cannot pack ${promptPath}; not installed: ${missing.join(', ')}`);
+    }
+    const types = flattenTree(tree);
+
+    const content = join(staging, 'content');
+    for (const t of types) {
+      cpSync(t.sourceDir, join(content, TYPES_DIR, t.typePath), {
+        recursive: true,
+        filter: (src) => !isSkippedDir(basename(src)) && !isSecretFile(basename(src)),
+      });
+    }
+
+    const manifest: PackManifest = {
+      format: PACK_FORMAT,
+      exportedAt: new Date().toISOString(),
+      root: tree.typePath,
+      types: types.map((t) => t.typePath),
+    };
+    writeFileSync(join(content, MANIFEST_FILE), `${JSON.stringify(manifest, null, 2)}\n`);
+
+    mkdirSync(dirname(archivePath), { recursive: true });
+    execFileSync('tar', ['-czf', archivePath, '-C', content, '.'], { stdio: 'ignore' });
+    log.info('exported', { root: manifest.root, types: manifest.types.length, archive: archivePath });
+    return { root: manifest.root, types: manifest.types };
+  });
+}
+
+function readManifest(dir: string): PackManifest {
+  const path = join(dir, MANIFEST_FILE);
+  if (!existsSync(path)) {
+    throw new Error(`Not a prompt pack (missing ${MANIFEST_FILE})`);
+  }
+  const manifest = JSON.parse(readFileSync(path, 'utf-8')) as PackManifest;
+  if (manifest.format > PACK_FORMAT) {
+    throw new Error(`Pack format ${manifest.format} is newer than this CLI supports; upgrade first`);
+  }
+  return manifest;
+}
+
+/**
+ * Installs a pack's types into installedRoot, all or nothing. Types
+ * already installed are kept unless overwrite is set.
+ */
+export async function importPack(
+  archivePath: string,
+  installedRoot: string,
+  opts: PackImportOptions = {},
+): Promise<PackImportResult> {
+  if (!existsSync(archivePath)) {
+    throw new Error(`Pack not found: ${archivePath}`);
+  }
+
+  return withTempDir(async (staging) => {
+    execFileSync('tar', ['-xzf', archivePath, '-C', staging], { stdio: 'ignore' });
+    const manifest = readManifest(staging);
+    const source: Source = { name: `pack:${basename(archivePath)}`, basePath: join(staging, TYPES_DIR) };
+    const result: PackImportResult = { root: manifest.root, installed: [], skipped: [], warnings: [] };
+
+    const toInstall: ResolvedType[] = [];
+    for (const typePath of manifest.types) {
+      if (existsSync(join(installedRoot, typePath)) && !opts.overwrite) {
+        result.skipped.push(typePath);
+        continue;
+      }
+      const resolved = resolveType(typePath, [source]);
+      if (!resolved) throw new Error(`The pack lists ${typePath} but has no manifest for it`);
+      toInstall.push(resolved);
+    }
+
+    result.warnings.push(...(await installAll(toInstall, installedRoot)));
+    result.installed = toInstall.map((t) => t.typePath);
+    return result;
+  });
+}
@@ -0,0 +1,188 @@
+import { join } from 'node:path';
+import { existsSync, readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { executeType, findManifest } from './executor.js';
+import { selectVersion } from './versions.js';
+import { loadOutputSchema } from './output-schema.js';
+import { extractOutput, pathSegments } from './workflow-inputs.js';
+import type { OutputListener } from './runtime.js';
+import type { SkillManifest } from '../types/manifest.js';
+
+// ── Ad-hoc pipelines ────────────────────────────────────────────────
+//
+// `agentx pipe skills/a -i k=v -- skills/b --map summary=text` runs
+// skills in sequence without a workflow manifest. Each `--map` belongs
+// to the stage after it and copies a value of the previous skill's
+// output into one of its inputs:
+//
+//   --map summary=text          output field "summary" into input "text"
+//   --map items.0.id=ticket     a path into the output JSON
+//   --map .=text                the whole output
+//
+// Everything is checked before the first skill runs: every stage is an
+// installed skill, every mapped input is declared, output paths are only
+// used on JSON output (and exist in its schema, when one is declared),
+// and every required input is given.
+
+export interface PipeMapping {
+  /** Dot path into the previous skill's output; "." is all of it. */
+  from: string;
+  to: string;
+}
+
+export interface PipeStage {
+  typePath: string;
+  inputs: Record<string, string>;
+  maps: PipeMapping[];
+}
+
+export interface PipeOptions {
+  /** Receives each stage's output as it is written. */
+  onOutput?: (stage: number, ...args: Parameters<OutputListener>) => void;
+}
+
+export interface PipeResult {
+  /** Stages that ran; the last may have failed. */
+  stages: { typePath: string; exitCode: number }[];
+  exitCode: number;
+  /** The last stage's stdout. */
+  stdout: string;
+}
+
+/** Splits `a [opts] -- b [opts] ...` into stages. */
+export function parsePipeArgs(args: string[]): PipeStage[] {
+  const stages: PipeStage[] = [];
+  let current: PipeStage | null = null;
+  for (let i = 0; i < args.length; i++) {
+    const arg = args[i];
+    if (arg === '--') {
+      if (!current) throw new Error('Expected a skill before `--`');
+      current = null;
+      continue;
+    }
+    if (arg === '--map' || arg === '-i' || arg === '--input') {
+      const value = args[++i];
+      if (!current || value === undefined) throw new Error(`${arg} needs a value after a skill`);
+      const eq = value.indexOf('=');
+      if (eq <= 0) throw new Error(`Invalid ${arg} "${value}": expected ${arg === '--map' ? 'output=input' : 'key=value'}`);
+      if (arg === '--map') current.maps.push({ from: value.slice(0, eq), to: value.slice(eq + 1) });
+      else current.inputs[value.slice(0, eq)] = value.slice(eq + 1);
+      continue;
+    }
+    if (arg.startsWith('-')) throw new Error(`Unknown pipe option ${arg}`);
+    if (current) throw new Error(`Expected \`--\` between ${current.typePath} and ${arg}`);
+    current = { typePath: arg, inputs: {}, maps: [] };
+    stages.push(current);
+  }
+  if (stages.length === 0) throw new Error('Nothing to run: give at least one skill');
+  return stages;
+}
+
+/** The directory of the version of typePath that runs here (see core/versions.ts). */
+function skillDir(typePath: string, installedRoot: string): string {
+  return selectVersion(process.cwd(), installedRoot, typePath).dir;
+}
+
+function loadSkill(typePath: string, installedRoot: string): SkillManifest {
+  const dir = skillDir(typePath, installedRoot);
+  const manifestPath = existsSync(dir) ? findManifest(dir) : null;
+  if (!manifestPath) throw new Error(`Type not installed: ${typePath}`);
+  const manifest = yaml.load(readFileSync(manifestPath, 'utf-8')) as SkillManifest;
+  if (manifest.type !== 'skill') throw new Error(`${typePath} is a ${manifest.type}; only skills can be piped`);
+  return manifest;
+}
+
+/**
+ * Whether path can exist under a JSON schema. Only listed properties
+ * rule a path out; a schema that doesn't describe a level allows it.
+ */
+function schemaHasPath(schema: Record<string, unknown>, segments: string[]): boolean {
+  let node = schema;
+  for (const segment of segments) {
+    const properties = node.properties as Record<string, Record<string, unknown>> | undefined;
+    if (node.items && /^\d+$/.test(segment)) {
+      node = node.items as Record<string, unknown>;
+    } else if (properties) {
+      if (!(segment in properties)) return false;
+      node = properties[segment];
+    } else {
+      return true;
+    }
+  }
+  return true;
+}
+
+/** Checks the stages against their manifests; returns every problem found. */
+export function validatePipe(stages: PipeStage[], installedRoot: string): string[] {
+  const errors: string[] = [];
+  const manifests: (SkillManifest | null)[] = stages.map((s) => {
+    try {
+      return loadSkill(s.typePath, installedRoot);
+    } catch (err) {
+      errors.push(err instanceof Error ? err.message : String(err));
+      return null;
+    }
+  });
+
+  stages.forEach((stage, i) => {
+    const manifest = manifests[i];
+    if (!manifest) return;
+    if (i === 0 && stage.maps.length > 0) {
+      errors.push(`${stage.typePath}: --map needs a skill before it to map from`);
+    }
+    const declared = new Map((manifest.inputs ?? []).map((f) => [f.name, f]));
+    const previous = i > 0 ? manifests[i - 1] : null;
+    for (const m of stage.maps) {
+      if (!declared.has(m.to)) {
+        const known = [...declared.keys()].join(', ') || 'none';
+        errors.push(`${stage.typePath} has no input "${m.to}" (its inputs: ${known})`);
+      }
+      if (!previous || m.from === '.') continue;
+      const from = stages[i - 1].typePath;
+      if (previous.outputs?.format !== 'json') {
+        errors.push(`${from} does not declare JSON output, so only --map .=${m.to} can use it`);
+        continue;
+      }
+      const fromDir = skillDir(from, installedRoot);
+      const schemaFile = typeof previous.outputs.schema === 'string' ? join(fromDir, previous.outputs.schema) : null;
+      if (!schemaFile || existsSync(schemaFile)) {
+        const schema = loadOutputSchema(fromDir, previous.outputs);
+        if (schema && typeof schema === 'object' && !schemaHasPath(schema, pathSegments(m.from))) {
+          errors.push(`${from}'s output schema has no "${m.from}"`);
+        }
+      }
+    }
+    const mapped = new Set(stage.maps.map((m) => m.to));
+    for (const field of manifest.inputs ?? []) {
+      if (field.required && field.default === undefined && !(field.name in stage.inputs) && !mapped.has(field.name)) {
+        errors.push(`${stage.typePath} requires input "${field.name}"; pass -i ${field.name}=... or --map <output>=${field.name}`);
+      }
+    }
+  });
+  return errors;
+}
+
+/** Validates, then runs the stages in order, stopping at the first failure. */
+export async function runPipe(stages: PipeStage[], installedRoot: string, opts: PipeOptions = {}): Promise<PipeResult> {
+  const errors = validatePipe(stages, installedRoot);
+  if (errors.length > 0) throw new Error(`Invalid pipe:\n  ${errors.join('\n  ')}`);
+
+  const result: PipeResult = { stages: [], exitCode: 0, stdout: '' };
+  let previous = '';
+  for (const [i, stage] of stages.entries()) {
+    const inputs = { ...stage.inputs };
+    for (const m of stage.maps) inputs[m.to] = extractOutput(previous, m.from, stages[i - 1].typePath);
+
+    const out = await executeType(stage.typePath, inputs, installedRoot, {
+      onOutput: (stream, chunk) => opts.onOutput?.(i, stream, chunk),
+    });
+    result.stages.push({ typePath: stage.typePath, exitCode: out.exitCode });
+    previous = out.stdout;
+    result.stdout = out.stdout;
+    if (out.exitCode !== 0) {
+      result.exitCode = out.exitCode;
+      break;
+    }
+  }
+  return result;
+}
@@ -0,0 +1,113 @@
+import { join, basename, extname } from 'node:path';
+import { readFileSync, readdirSync, existsSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { PresetSchema } from '../config/schema.js';
+import type { Preset } from '../types/manifest.js';
+import type { ResolvedType, Source } from '../types/registry.js';
+import { buildInstallPlan, didYouMean } from './registry.js';
+import { installAll } from './transaction.js';
+
+// ── Presets ─────────────────────────────────────────────────────────
+//
+// A preset is a named starting point for a project, kept in a catalog's
+// or extension's presets/ directory:
+//
+//   # presets/java-spring.yaml
+//   name: java-spring
+//   description: Spring Boot services
+//   tools: [claude-code, copilot]
+//   personas: [personas/senior-java-dev]
+//   context: [context/spring-boot]
+//   skills: [skills/scm/git/commit-analyzer]
+//
+// `agentx init --preset java-spring` installs every listed type with its
+// dependencies, then links the listed ones into the new project. When
+// two sources define the same name, the first in source order wins.
+
+const PRESETS_DIR = 'presets';
+
+export interface LoadedPreset extends Preset {
+  /** Source the preset came from (catalog, an extension). */
+  source: string;
+  path: string;
+}
+
+function readPreset(path: string, source: string): LoadedPreset {
+  const parsed = PresetSchema.safeParse(yaml.load(readFileSync(path, 'utf-8')));
+  if (!parsed.success) {
+    const issue = parsed.error.issues[0];
+    throw new Error(`Invalid ${path}: ${issue.path.join('.')}: ${issue.message}`);
+  }
+  const expected = basename(path, extname(path));
+  if (parsed.data.name !== expected) {
+    throw new Error(`Invalid ${path}: name "${parsed.data.name}" does not match the file name`);
+  }
+  return { ...parsed.data, source, path };
+}
+
+/** Presets of all sources. Unreadable ones are reported in warnings. */
+export function listPresets(sources: Source[], warnings?: string[]): LoadedPreset[] {
+  const presets = new Map<string, LoadedPreset>();
+  for (const source of sources) {
+    const dir = join(source.basePath, PRESETS_DIR);
+    if (!existsSync(dir)) continue;
+    for (const file of readdirSync(dir).sort()) {
+      if (!/\.ya?ml$/.test(file)) continue;
+      try {
+        const preset = readPreset(join(dir, file), source.name);
+        if (!presets.has(preset.name)) presets.set(preset.name, preset);
+      } catch (err) {
+        warnings?.push(err instanceof Error ? err.message : String(err));
+      }
+    }
+  }
+  return [...presets.values()].sort((a, b) => a.name.localeCompare(b.name));
+}
+
+export function loadPreset(name: string, sources: Source[]): LoadedPreset {
+  for (const source of sources) {
+    for (const ext of ['yaml', 'yml']) {
+      const path = join(source.basePath, PRESETS_DIR, `${name}.${ext}`);
+      if (existsSync(path)) return readPreset(path, source.name);
+    }
+  }
+  const known = listPresets(sources).map((p) => p.name);
+  throw new Error(`Preset not found: ${name}${didYouMean(name, known) || ' (see `agentx preset list`)'}`);
+}
+
+/** The type paths a preset links, in project.yaml section order. */
+export function presetTypes(preset: Preset): string[] {
+  return [
+    ...(preset.personas ?? []),
+    ...(preset.context ?? []),
+    ...(preset.skills ?? []),
+    ...(preset.workflows ?? []),
+    ...(preset.prompts ?? []),
+  ];
+}
+
+/**
+ * Installs the preset's types and their dependencies, all or nothing.
+ * Types already installed are left alone. Returns the installed type
+ * paths and any warnings.
+ */
+export async function installPreset(
+  preset: Preset,
+  sources: Source[],
+  installedRoot: string,
+): Promise<{ installed: string[]; warnings: string[] }> {
+  const toInstall = new Map<string, ResolvedType>();
+  const missing: string[] = [];
+  for (const typePath of presetTypes(preset)) {
+    const plan = buildInstallPlan(typePath, sources, installedRoot);
+    if (!plan.root.resolved && !plan.root.installed) missing.push(typePath);
+    for (const t of plan.allTypes) if (!toInstall.has(t.typePath)) toInstall.set(t.typePath, t);
+  }
+  if (missing.length > 0) {
+    throw new Error(`Preset ${preset.name} lists types no source provides: ${missing.join(', ')}`);
+  }
+
+  const types = [...toInstall.values()];
+  const warnings = await installAll(types, installedRoot);
+  return { installed: types.map((t) => t.typePath), warnings };
+}
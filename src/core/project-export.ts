@@ -0,0 +1,156 @@
+import { join, dirname } from 'node:path';
+import { existsSync, mkdirSync, writeFileSync } from 'node:fs';
+import { loadProject, projectConfigPath } from './linker.js';
+import { loadPersona, loadContext, type PersonaSection } from './compose.js';
+import { buildOverlays } from './overrides.js';
+import { canonicalTypePath } from './registry.js';
+import { loadManifest, flattenRef } from '../integrations/helpers.js';
+import { renderMainDoc, toolLayout } from '../integrations/index.js';
+import { ALL_TOOLS } from '../types/integrations.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('export');
+
+// ── Plain exports ───────────────────────────────────────────────────
+//
+// `agentx export -o <dir>` writes a project's composed configuration as
+// ordinary files, for repositories whose teams don't install the CLI:
+// no symlinks, no generated-by header, no `agentx run` commands. Project
+// overrides are applied, as in `link sync`.
+//
+//   --tool generic (default)    INSTRUCTIONS.md and context/<name>.md
+//   --tool <ai tool>            that tool's layout, e.g. .claude/CLAUDE.md
+//                               and .claude/context/<name>.md
+//
+// Skills and workflows are listed by name and description only, since
+// running them needs the CLI.
+
+export const EXPORT_TOOLS = ['generic', ...ALL_TOOLS];
+
+const GENERIC_MAIN_DOC = 'INSTRUCTIONS.md';
+const GENERIC_CONTEXT_DIR = 'context';
+
+export interface ExportOptions {
+  tool?: string;
+}
+
+export interface ExportResult {
+  tool: string;
+  outputDir: string;
+  /** Written files, relative to outputDir. */
+  files: string[];
+  warnings: string[];
+}
+
+interface Listed {
+  name: string;
+  description: string;
+}
+
+interface ExportedContext {
+  ref: string;
+  title: string;
+  file: string;
+  content: string;
+}
+
+function renderGeneric(persona: PersonaSection | null, context: ExportedContext[], skills: Listed[], workflows: Listed[]): string {
+  const lines = ['# Project Instructions', ''];
+  if (persona) {
+    lines.push('## Persona', '', persona.description, '');
+    if (persona.expertise.length) lines.push(`Expertise: ${persona.expertise.join(', ')}.`, '');
+    if (persona.tone) lines.push(`Tone: ${persona.tone}.`, '');
+    if (persona.conventions.length) {
+      lines.push('### Conventions', '', ...persona.conventions.map((c) => `- ${c}`), '');
+    }
+  }
+  if (context.length) {
+    lines.push('## Context', '', 'Read these before making changes:', '');
+    lines.push(...context.map((c) => `- [${c.title}](${c.file})`), '');
+  }
+  for (const [title, items] of [['Skills', skills], ['Workflows', workflows]] as const) {
+    if (!items.length) continue;
+    lines.push(`## ${title}`, '', ...items.map((i) => `- **${i.name}**: ${i.description}`), '');
+  }
+  return lines.join('\n');
+}
+
+function readListed(installedRoot: string, refs: string[], kind: string, warnings: string[]): Listed[] {
+  const out: Listed[] = [];
+  for (const ref of refs) {
+    const loaded = loadManifest(installedRoot, ref);
+    if (!loaded) {
+      warnings.push(`${kind} not found: ${ref}`);
+      continue;
+    }
+    out.push({ name: String(loaded.manifest.name), description: String(loaded.manifest.description ?? '') });
+  }
+  return out;
+}
+
+/** Writes the project's composed configuration to outputDir as plain files. */
+export function exportProject(
+  projectPath: string,
+  outputDir: string,
+  installedRoot: string,
+  opts: ExportOptions = {},
+): ExportResult {
+  const tool = opts.tool ?? 'generic';
+  if (!EXPORT_TOOLS.includes(tool)) {
+    throw new Error(`Unknown export tool "${tool}". Expected one of: ${EXPORT_TOOLS.join(', ')}`);
+  }
+  if (!existsSync(projectConfigPath(projectPath))) {
+    throw new Error('No .agentx/project.yaml here; run `agentx init` first');
+  }
+  const config = loadProject(projectPath);
+  const active = Object.fromEntries(
+    Object.entries(config.active).map(([section, refs]) => [section, (refs ?? []).map((r) => canonicalTypePath(r, installedRoot))]),
+  ) as Record<string, string[]>;
+  const warnings: string[] = [];
+
+  let persona: PersonaSection | null = null;
+  if (active.personas?.length) {
+    const res = loadPersona(active.personas[0], installedRoot);
+    persona = res.section;
+    warnings.push(...res.warnings);
+  }
+
+  const layout = tool === 'generic' ? null : toolLayout(tool);
+  const contextDir = layout?.contextDir ?? GENERIC_CONTEXT_DIR;
+  const overlays = buildOverlays(projectPath, installedRoot, active.context ?? []);
+  const context: ExportedContext[] = [];
+  for (const ref of active.context ?? []) {
+    const res = loadContext(ref, installedRoot, overlays[ref]);
+    warnings.push(...res.warnings);
+    if (res.sections.length === 0) continue;
+    const title = res.sections[0].name;
+    context.push({
+      ref,
+      title,
+      file: `${contextDir}/${flattenRef(ref.replace(/^context\//, ''))}.md`,
+      content: `# ${title}\n\n${res.sections.map((s) => s.content.trimEnd()).join('\n\n')}\n`,
+    });
+  }
+  const skills = readListed(installedRoot, active.skills ?? [], 'Skill', warnings);
+  const workflows = readListed(installedRoot, active.workflows ?? [], 'Workflow', warnings);
+
+  const files: [string, string][] = context.map((c) => [c.file, c.content]);
+  if (layout) {
+    const doc = renderMainDoc(tool, {
+      persona: persona as Record<string, unknown> | null,
+      skills: skills.length ? (skills as unknown as Record<string, unknown>[]) : null,
+      workflows: workflows.length ? (workflows as unknown as Record<string, unknown>[]) : null,
+      hasContext: context.length > 0,
+    });
+    files.unshift([layout.mainDoc, doc]);
+  } else {
+    files.unshift([GENERIC_MAIN_DOC, renderGeneric(persona, context, skills, workflows)]);
+  }
+
+  for (const [rel, content] of files) {
+    mkdirSync(dirname(join(outputDir, rel)), { recursive: true });
+    writeFileSync(join(outputDir, rel), content);
+  }
+  log.info('exported', { tool, dir: outputDir, files: files.length, warnings: warnings.length });
+  return { tool, outputDir, files: files.map(([rel]) => rel), warnings };
+}
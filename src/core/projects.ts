@@ -0,0 +1,102 @@
+import { join, resolve } from 'node:path';
+import { readFileSync, writeFileSync, mkdirSync, renameSync, existsSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { getUserdataRoot } from './userdata.js';
+import { projectConfigPath, sync, type SyncOptions } from './linker.js';
+import { acquireLock } from './lock.js';
+
+// ── Known projects ──────────────────────────────────────────────────
+//
+// userdata/projects.yaml lists the projects set up on this machine, so
+// machine-wide commands (`projects sync-all`, gc) can reach them without
+// scanning the disk. init, import, and the link commands record the
+// project with its tools; link sync also records when it ran. Entries
+// are never dropped on read: a project whose .agentx/project.yaml can't
+// be found may sit on a disk that isn't mounted right now.
+
+const PROJECTS_FILE = 'projects.yaml';
+
+export interface KnownProject {
+  path: string;
+  tools: string[];
+  /** When link sync last ran in the project. */
+  lastSync?: string;
+}
+
+export function projectsRegistryPath(): string {
+  return join(getUserdataRoot(), PROJECTS_FILE);
+}
+
+/** Every recorded project, including ones that no longer exist. */
+export function loadKnownProjects(): KnownProject[] {
+  try {
+    const data = yaml.load(readFileSync(projectsRegistryPath(), 'utf-8')) as { projects?: KnownProject[] } | undefined;
+    return (data?.projects ?? []).map((p) => ({ ...p, tools: p.tools ?? [] }));
+  } catch {
+    return [];
+  }
+}
+
+function saveKnownProjects(projects: KnownProject[]): void {
+  const path = projectsRegistryPath();
+  mkdirSync(getUserdataRoot(), { recursive: true });
+  // Syncs in different projects don't share a lock; a rename keeps
+  // readers from seeing a half-written file
+  const tmp = `${path}.tmp-${process.pid}`;
+  writeFileSync(tmp, yaml.dump({ projects }, { lineWidth: -1 }));
+  renameSync(tmp, path);
+}
+
+/** Adds or updates a project; without synced, its last sync is kept. */
+export function recordProject(projectPath: string, tools: string[], synced?: Date): void {
+  const path = resolve(projectPath);
+  const all = loadKnownProjects();
+  const lastSync = synced?.toISOString() ?? all.find((p) => p.path === path)?.lastSync;
+  const projects = all.filter((p) => p.path !== path);
+  projects.push({ path, tools, ...(lastSync ? { lastSync } : {}) });
+  saveKnownProjects(projects.sort((a, b) => a.path.localeCompare(b.path)));
+}
+
+/** Recorded projects that can be reached now; the rest stay recorded. */
+export function knownProjects(): KnownProject[] {
+  return loadKnownProjects().filter((p) => existsSync(projectConfigPath(p.path)));
+}
+
+export interface ProjectSyncResult {
+  path: string;
+  ok: boolean;
+  warnings: string[];
+  error?: string;
+}
+
+export interface SyncAllOptions extends Pick<SyncOptions, 'regenerateAll' | 'approveHook'> {
+  /** How long to wait for each project's lock. */
+  lockTimeoutMs?: number;
+}
+
+/**
+ * Runs link sync in every known project, each under its own lock. A
+ * project that fails, including one whose lock can't be taken in time,
+ * is reported in its result and the rest still sync.
+ */
+export async function syncKnownProjects(opts: SyncAllOptions = {}): Promise<ProjectSyncResult[]> {
+  const results: ProjectSyncResult[] = [];
+  for (const project of knownProjects()) {
+    const warnings: string[] = [];
+    let release: (() => void) | undefined;
+    try {
+      release = await acquireLock(join(project.path, '.agentx'), {
+        command: 'projects sync-all',
+        timeoutMs: opts.lockTimeoutMs,
+      });
+      const synced = await sync(project.path, { regenerateAll: opts.regenerateAll, warnings, approveHook: opts.approveHook });
+      warnings.push(...synced.flatMap((r) => r.warnings.map((w) => `${r.tool}: ${w}`)));
+      results.push({ path: project.path, ok: true, warnings });
+    } catch (err) {
+      results.push({ path: project.path, ok: false, warnings, error: err instanceof Error ? err.message : String(err) });
+    } finally {
+      release?.();
+    }
+  }
+  return results;
+}
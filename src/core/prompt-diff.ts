@@ -0,0 +1,104 @@
+import { dirname, join } from 'node:path';
+import { existsSync, readFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { Source } from '../types/registry.js';
+import { resolveType, extractDependencies } from './registry.js';
+import { contentDir } from './merge.js';
+import { compose, render, resolveVariables, applyVariables, type RenderOptions, type VariableSources } from './compose.js';
+import { defaultVersion, type TypeDirs } from './versions.js';
+import { unifiedDiff } from '../utils/diff.js';
+
+// ── Prompt diff ─────────────────────────────────────────────────────
+//
+// `agentx prompt diff <prompt>` composes a prompt twice: from what is
+// installed (honoring the project's pins) and from what the catalog and
+// extensions provide now, then diffs the rendered text. The latest
+// copies are read in place from their sources (through a merged copy for
+// appended context), so nothing is installed or stored. Types the
+// sources don't have fall back to the installed copy.
+
+export interface TypeChange {
+  typePath: string;
+  /** null when it isn't installed. */
+  installed: string | null;
+  /** null when no source provides it. */
+  latest: string | null;
+}
+
+export interface PromptDiff {
+  current: string;
+  latest: string;
+  /** Unified diff from current to latest; empty when they match. */
+  diff: string;
+  /** The prompt and the types it uses, with both versions. */
+  types: TypeChange[];
+}
+
+export interface PromptDiffOptions {
+  render?: RenderOptions;
+  /** Directories of pinned versions for the current side. */
+  typeDirs?: TypeDirs;
+  variables?: VariableSources;
+}
+
+function manifestVersion(manifestPath: string): string | null {
+  const data = yaml.load(readFileSync(manifestPath, 'utf-8')) as { version?: string } | undefined;
+  return data?.version ?? null;
+}
+
+/** Source directories of the latest prompt and every type it uses. */
+export function latestTypeDirs(promptPath: string, sources: Source[]): TypeDirs {
+  const prompt = resolveType(promptPath, sources);
+  if (!prompt) throw new Error(`No source provides ${promptPath}`);
+  const dirs: TypeDirs = { [promptPath]: dirname(prompt.manifestPath) };
+  for (const dep of extractDependencies(prompt.manifestPath)) {
+    const resolved = resolveType(dep, sources);
+    if (resolved) dirs[dep] = contentDir(resolved);
+  }
+  return dirs;
+}
+
+function renderSide(
+  promptPath: string,
+  installedRoot: string,
+  typeDirs: TypeDirs,
+  opts: PromptDiffOptions,
+): string {
+  let composed = compose(promptPath, installedRoot, typeDirs);
+  if (opts.variables) {
+    // Missing values are left as {{name}} on both sides rather than asked for
+    composed = applyVariables(composed, resolveVariables(composed, opts.variables).values);
+  }
+  return render(composed, opts.render);
+}
+
+export function diffPrompt(
+  promptPath: string,
+  installedRoot: string,
+  sources: Source[],
+  opts: PromptDiffOptions = {},
+): PromptDiff {
+  const latestDirs = latestTypeDirs(promptPath, sources);
+  const installed = existsSync(join(installedRoot, promptPath));
+  const current = installed ? renderSide(promptPath, installedRoot, opts.typeDirs ?? {}, opts) : '';
+  const latest = renderSide(promptPath, installedRoot, latestDirs, opts);
+
+  // latestTypeDirs has checked that a source provides the prompt
+  const prompt = resolveType(promptPath, sources)!;
+  const typePaths = [...new Set([promptPath, ...extractDependencies(prompt.manifestPath)])];
+  const types = typePaths.map((typePath): TypeChange => {
+    const resolved = resolveType(typePath, sources);
+    return {
+      typePath,
+      installed: defaultVersion(installedRoot, typePath),
+      latest: resolved ? manifestVersion(resolved.manifestPath) : null,
+    };
+  });
+
+  return {
+    current,
+    latest,
+    diff: unifiedDiff(current, latest, { fromLabel: `${promptPath} (installed)`, toLabel: `${promptPath} (latest)` }),
+    types,
+  };
+}
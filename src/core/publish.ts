@@ -0,0 +1,206 @@
+import { spawnSync } from 'node:child_process';
+import { writeFileSync, mkdirSync } from 'node:fs';
+import { dirname, resolve, extname, sep } from 'node:path';
+import { fileURLToPath } from 'node:url';
+import type { Publish } from '../types/manifest.js';
+import { renderString } from './template.js';
+import { authorizationFor } from './credentials.js';
+import { httpFetch } from '../utils/http.js';
+import * as settings from '../config/settings.js';
+
+// ── Publish steps ───────────────────────────────────────────────────
+//
+// A workflow step of the form
+//
+//   - id: deliver
+//     publish:
+//       from: [analyze, summarize]
+//       to: reports/{{workflow}}/{{date}}.json
+//
+// sends earlier steps' outputs somewhere: a local path (or file://), an
+// HTTP endpoint (POST by default), or an s3:// URL via the AWS CLI. `to`
+// and header values are Handlebars templates over the run context.
+//
+// Workflows come from catalogs and extensions, so a publish step only
+// gets what the user grants: headers see the host variables listed in
+// publish.env_allow, stored credentials are sent only to hosts in
+// publish.auth_hosts, and a local destination must be inside the
+// directory the workflow runs from.
+
+export interface StepOutput {
+  stdout: string;
+}
+
+export interface PublishContext {
+  workflow: string;
+  inputs: Record<string, string>;
+  /** Outputs of the steps run so far, in order. */
+  outputs: Map<string, StepOutput>;
+  /** Directory local destinations must stay inside (default: the current directory). */
+  root?: string;
+}
+
+export interface PublishResult {
+  destination: string;
+  contentType: string;
+  bytes: number;
+}
+
+const TYPES_BY_EXTENSION: Record<string, string> = {
+  '.json': 'application/json',
+  '.md': 'text/markdown',
+  '.html': 'text/html',
+  '.csv': 'text/csv',
+  '.xml': 'application/xml',
+  '.yaml': 'application/yaml',
+  '.yml': 'application/yaml',
+};
+
+function parseJson(text: string): unknown {
+  try {
+    return JSON.parse(text);
+  } catch {
+    return undefined;
+  }
+}
+
+function sourceSteps(publish: Publish, ctx: PublishContext): string[] {
+  if (publish.from === undefined) {
+    const previous = [...ctx.outputs.keys()].pop();
+    if (!previous) throw new Error('publish has no earlier step to publish');
+    return [previous];
+  }
+  const ids = Array.isArray(publish.from) ? publish.from : [publish.from];
+  for (const id of ids) {
+    if (!ctx.outputs.has(id)) throw new Error(`publish refers to ${id}, which has not run before it`);
+  }
+  return ids;
+}
+
+/**
+ * The payload: a single step's output as-is, or several steps' outputs
+ * as one JSON object keyed by step id (parsed where they are JSON).
+ */
+export function publishBody(ids: string[], ctx: PublishContext): { body: string; json: boolean } {
+  if (ids.length === 1) {
+    const stdout = ctx.outputs.get(ids[0])!.stdout;
+    return { body: stdout, json: parseJson(stdout) !== undefined };
+  }
+  const combined: Record<string, unknown> = {};
+  for (const id of ids) {
+    const stdout = ctx.outputs.get(id)!.stdout;
+    combined[id] = parseJson(stdout) ?? stdout;
+  }
+  return { body: `${JSON.stringify(combined, null, 2)}\n`, json: true };
+}
+
+/** Explicit content_type, else the destination's extension, else sniffed. */
+export function contentTypeFor(destination: string, json: boolean, explicit?: string): string {
+  if (explicit) return explicit;
+  const path = destination.replace(/[?#].*$/, '');
+  return TYPES_BY_EXTENSION[extname(path).toLowerCase()] ?? (json ? 'application/json' : 'text/plain');
+}
+
+function templateData(ctx: PublishContext): Record<string, unknown> {
+  const steps: Record<string, unknown> = {};
+  for (const [id, out] of ctx.outputs) {
+    // outputs, as in step inputs; output is the older name
+    const value = parseJson(out.stdout) ?? out.stdout.trim();
+    steps[id] = { outputs: value, output: value };
+  }
+  return {
+    workflow: ctx.workflow,
+    inputs: ctx.inputs,
+    steps,
+    timestamp: new Date().toISOString().replace(/[:.]/g, '-'),
+  };
+}
+
+/** Whether publish.auth_hosts lets stored credentials go to url's host. */
+function authHostAllowed(url: string): boolean {
+  const host = new URL(url).hostname.toLowerCase();
+  return settings.getList('publish.auth_hosts').some((h) => h.toLowerCase() === host);
+}
+
+/** The host variables publish.env_allow exposes to header templates. */
+function allowedEnv(): Record<string, string> {
+  const env: Record<string, string> = {};
+  for (const name of settings.getList('publish.env_allow')) {
+    const value = process.env[name];
+    if (value !== undefined) env[name] = value;
+  }
+  return env;
+}
+
+/** Resolves a local destination, refusing one outside root. */
+function localPath(destination: string, root: string): string {
+  const base = resolve(root);
+  const path = destination.startsWith('file://') ? fileURLToPath(destination) : resolve(base, destination);
+  if (path !== base && !path.startsWith(base + sep)) {
+    throw new Error(`Publishing to ${path} is not allowed: local destinations must be inside ${base}`);
+  }
+  return path;
+}
+
+async function postHttp(
+  url: string,
+  body: string,
+  contentType: string,
+  publish: Publish,
+  headers: Record<string, string>,
+): Promise<void> {
+  // Stored credentials for the host apply unless the step sets its own
+  const hasAuth = Object.keys(headers).some((k) => k.toLowerCase() === 'authorization');
+  const authorization = hasAuth || !authHostAllowed(url) ? undefined : authorizationFor(url);
+  const res = await httpFetch(url, {
+    method: publish.method ?? 'POST',
+    body,
+    headers: {
+      'Content-Type': contentType,
+      ...(authorization ? { Authorization: authorization } : {}),
+      ...headers,
+    },
+  });
+  if (!res.ok) {
+    throw new Error(`Publishing to ${url} failed: HTTP ${res.status} ${res.statusText}`);
+  }
+}
+
+function copyToS3(url: string, body: string, contentType: string): void {
+  const res = spawnSync('aws', ['s3', 'cp', '-', url, '--content-type', contentType], {
+    input: body,
+    encoding: 'utf-8',
+  });
+  if (res.error) {
+    throw new Error(`Publishing to ${url} requires the AWS CLI: ${res.error.message}`);
+  }
+  if (res.status !== 0) {
+    throw new Error(`Publishing to ${url} failed: ${res.stderr.trim()}`);
+  }
+}
+
+export async function runPublish(publish: Publish, ctx: PublishContext): Promise<PublishResult> {
+  const ids = sourceSteps(publish, ctx);
+  const { body, json } = publishBody(ids, ctx);
+
+  const data = templateData(ctx);
+  const destination = renderString(publish.to, data).trim();
+  const contentType = contentTypeFor(destination, json, publish.content_type);
+  // Header values may reference allowed host variables ({{env.API_TOKEN}})
+  const env = allowedEnv();
+  const headers = Object.fromEntries(
+    Object.entries(publish.headers ?? {}).map(([k, v]) => [k, renderString(v, { ...data, env })]),
+  );
+
+  if (/^https?:\/\//.test(destination)) {
+    await postHttp(destination, body, contentType, publish, headers);
+  } else if (destination.startsWith('s3://')) {
+    copyToS3(destination, body, contentType);
+  } else {
+    const path = localPath(destination, ctx.root ?? process.cwd());
+    mkdirSync(dirname(path), { recursive: true });
+    writeFileSync(path, body);
+    return { destination: path, contentType, bytes: Buffer.byteLength(body) };
+  }
+  return { destination, contentType, bytes: Buffer.byteLength(body) };
+}
@@ -0,0 +1,234 @@
+import { execFileSync } from 'node:child_process';
+import { join, dirname, relative, sep } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  readFileSync,
+  writeFileSync,
+  readdirSync,
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  rmSync,
+  cpSync,
+  chmodSync,
+} from 'node:fs';
+import { getSkillsDir } from './userdata.js';
+import { installedTypePaths, nameFromPath } from './registry.js';
+
+// ── Skill registry archives ─────────────────────────────────────────
+//
+// `agentx registry export` packs skills' registries (config.yaml, state,
+// templates) into a .tar.gz so a new machine doesn't need every skill
+// set up again by hand:
+//
+//   agentx-registry.json          format, export time, skill type paths
+//   skills/<registry-name>/...    registry files, minus tokens and output
+//   secrets.tar.gz.age            tokens.env and tokens.<account>.env files,
+//                                 only with --include-secrets
+//
+// Tokens never go into the archive in the clear: they're packed
+// separately and encrypted with age (https://age-encryption.org), to
+// recipients if given, else to a passphrase age prompts for.
+
+const ARCHIVE_FORMAT = 1;
+const MANIFEST_FILE = 'agentx-registry.json';
+const SECRETS_FILE = 'secrets.tar.gz.age';
+// tokens.env and per-account tokens.<account>.env
+export const TOKEN_FILES = /^tokens(\.[\w-]+)?\.env$/;
+// Run results are per machine
+const EXCLUDED = new Set(['output']);
+
+interface ArchiveManifest {
+  format: number;
+  exportedAt: string;
+  skills: string[];
+  secrets: boolean;
+}
+
+export interface ExportOptions {
+  includeSecrets?: boolean;
+  /** age recipients (public keys); without any, age asks for a passphrase. */
+  recipients?: string[];
+}
+
+export interface ExportResult {
+  skills: string[];
+  /** Number of token files encrypted into the archive. */
+  secrets: number;
+}
+
+export interface ImportOptions {
+  /** age identity file for archives encrypted to a recipient. */
+  identity?: string;
+  /** Leave secrets in the archive alone. */
+  skipSecrets?: boolean;
+  /** Replace files that already exist. */
+  overwrite?: boolean;
+}
+
+export interface ImportResult {
+  skills: string[];
+  secrets: number;
+  /** Existing files left in place, relative to the skills directory. */
+  skipped: string[];
+}
+
+function registryDir(typePath: string): string {
+  return join(getSkillsDir(), nameFromPath(typePath));
+}
+
+/** Installed skills that have a registry directory. */
+export function listRegistries(installedRoot: string): string[] {
+  return installedTypePaths(installedRoot)
+    .filter((t) => t.startsWith('skills/') && existsSync(registryDir(t)))
+    .sort();
+}
+
+export function withTempDir<T>(fn: (dir: string) => T): T {
+  const dir = mkdtempSync(join(tmpdir(), 'agentx-registry-'));
+  try {
+    return fn(dir);
+  } finally {
+    rmSync(dir, { recursive: true, force: true });
+  }
+}
+
+export function requireAge(): void {
+  try {
+    execFileSync('age', ['--version'], { stdio: 'ignore' });
+  } catch {
+    throw new Error('Secrets are encrypted with age, which was not found. Install it from https://age-encryption.org.');
+  }
+}
+
+/** Files under dir, relative and /-separated, sorted. */
+export function listFiles(dir: string): string[] {
+  const out: string[] = [];
+  const walk = (d: string) => {
+    for (const entry of readdirSync(d, { withFileTypes: true })) {
+      const full = join(d, entry.name);
+      if (entry.isDirectory()) walk(full);
+      else if (entry.isFile()) out.push(relative(dir, full).split(sep).join('/'));
+    }
+  };
+  if (existsSync(dir)) walk(dir);
+  return out.sort();
+}
+
+export function exportRegistries(
+  typePaths: string[],
+  archivePath: string,
+  opts: ExportOptions = {},
+): ExportResult {
+  for (const typePath of typePaths) {
+    if (!existsSync(registryDir(typePath))) {
+      throw new Error(`${typePath} has no registry to export`);
+    }
+  }
+  if (opts.includeSecrets) requireAge();
+
+  return withTempDir((staging) => {
+    const content = join(staging, 'content');
+    const secrets = join(staging, 'secrets');
+    let secretCount = 0;
+
+    for (const typePath of typePaths) {
+      const name = nameFromPath(typePath);
+      cpSync(registryDir(typePath), join(content, 'skills', name), {
+        recursive: true,
+        filter: (src) => {
+          const top = relative(registryDir(typePath), src).split(sep)[0];
+          return !EXCLUDED.has(top) && !TOKEN_FILES.test(top);
+        },
+      });
+      if (!opts.includeSecrets) continue;
+      for (const file of readdirSync(registryDir(typePath)).filter((f) => TOKEN_FILES.test(f))) {
+        mkdirSync(join(secrets, 'skills', name), { recursive: true });
+        cpSync(join(registryDir(typePath), file), join(secrets, 'skills', name, file));
+        secretCount++;
+      }
+    }
+    mkdirSync(content, { recursive: true });
+
+    if (secretCount > 0) {
+      const packed = join(staging, 'secrets.tar.gz');
+      execFileSync('tar', ['-czf', packed, '-C', secrets, '.'], { stdio: 'ignore' });
+      const recipients = (opts.recipients ?? []).flatMap((r) => ['-r', r]);
+      const args = recipients.length > 0 ? recipients : ['-p'];
+      // Inherit stdio so age can prompt for a passphrase on the terminal
+      execFileSync('age', ['-e', ...args, '-o', join(content, SECRETS_FILE), packed], { stdio: 'inherit' });
+    }
+
+    const manifest: ArchiveManifest = {
+      format: ARCHIVE_FORMAT,
+      exportedAt: new Date().toISOString(),
+      skills: typePaths,
+      secrets: secretCount > 0,
+    };
+    writeFileSync(join(content, MANIFEST_FILE), `${JSON.stringify(manifest, null, 2)}\n`);
+
+    mkdirSync(dirname(archivePath), { recursive: true });
+    execFileSync('tar', ['-czf', archivePath, '-C', content, '.'], { stdio: 'ignore' });
+    return { skills: typePaths, secrets: secretCount };
+  });
+}
+
+function readManifest(dir: string): ArchiveManifest {
+  const path = join(dir, MANIFEST_FILE);
+  if (!existsSync(path)) {
+    throw new Error(`Not a registry archive (missing ${MANIFEST_FILE})`);
+  }
+  const manifest = JSON.parse(readFileSync(path, 'utf-8')) as ArchiveManifest;
+  if (manifest.format > ARCHIVE_FORMAT) {
+    throw new Error(`Registry archive format ${manifest.format} is newer than this CLI supports; upgrade first`);
+  }
+  return manifest;
+}
+
+/** Copies files from an unpacked tree into the skills directory. */
+function place(fromDir: string, overwrite: boolean, skipped: string[], mode?: number): void {
+  const skillsDir = getSkillsDir();
+  for (const rel of listFiles(fromDir)) {
+    const target = join(skillsDir, rel);
+    if (existsSync(target) && !overwrite) {
+      skipped.push(rel);
+      continue;
+    }
+    mkdirSync(dirname(target), { recursive: true });
+    cpSync(join(fromDir, rel), target);
+    if (mode !== undefined) chmodSync(target, mode);
+  }
+}
+
+export function importRegistries(archivePath: string, opts: ImportOptions = {}): ImportResult {
+  if (!existsSync(archivePath)) {
+    throw new Error(`Archive not found: ${archivePath}`);
+  }
+
+  return withTempDir((staging) => {
+    const content = join(staging, 'content');
+    mkdirSync(content);
+    execFileSync('tar', ['-xzf', archivePath, '-C', content], { stdio: 'ignore' });
+    const manifest = readManifest(content);
+
+    const skipped: string[] = [];
+    place(join(content, 'skills'), opts.overwrite ?? false, skipped);
+
+    let secrets = 0;
+    const encrypted = join(content, SECRETS_FILE);
+    if (existsSync(encrypted) && !opts.skipSecrets) {
+      requireAge();
+      const packed = join(staging, 'secrets.tar.gz');
+      const identity = opts.identity ? ['-i', opts.identity] : [];
+      execFileSync('age', ['-d', ...identity, '-o', packed, encrypted], { stdio: 'inherit' });
+
+      const secretsDir = join(staging, 'secrets');
+      mkdirSync(secretsDir);
+      execFileSync('tar', ['-xzf', packed, '-C', secretsDir], { stdio: 'ignore' });
+      secrets = listFiles(join(secretsDir, 'skills')).length;
+      place(join(secretsDir, 'skills'), opts.overwrite ?? false, skipped, 0o600);
+    }
+
+    return { skills: manifest.skills, secrets, skipped };
+  });
+}
@@ -0,0 +1,386 @@
+import { createRequire } from 'node:module';
+import { mkdirSync, rmSync } from 'node:fs';
+import { dirname } from 'node:path';
+import type { DatabaseSync } from 'node:sqlite';
+import type { DiscoveredType, Source } from '../types/registry.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('registry-index');
+
+// ── Registry index ──────────────────────────────────────────────────
+//
+// What discovery found in each source, kept in an SQLite database
+// (node:sqlite, built into Node) at ~/.agentx/registry-index.db. Besides
+// the parsed type, each row keeps its topic, vendor, tags, the types it
+// references, and the CLIs it needs, so search
+// filters and reverse dependencies are queries instead of a re-read of
+// every manifest.
+//
+// A source is re-indexed when its fingerprint changes, and then only
+// manifests whose size or mtime changed are parsed again; the rest of
+// its rows are kept. Each source is updated in one transaction, so a
+// crash leaves the previous index rather than half of a new one. A
+// source whose directory is gone (a removed extension) is pruned. The
+// database is a cache: one that is corrupt or from another format is
+// deleted and rebuilt. Any other failure (another agentx holding it
+// busy past the timeout, a read-only home) leaves the file alone, since
+// deleting a WAL another process has open can corrupt it; callers scan
+// into an in-memory index instead.
+
+/** Bumped when the schema or DiscoveredType changes, so older indexes are rebuilt. */
+const INDEX_FORMAT = 4;
+
+const SCHEMA = `
+CREATE TABLE IF NOT EXISTS sources (
+  name TEXT PRIMARY KEY,
+  base_path TEXT NOT NULL,
+  fingerprint TEXT NOT NULL,
+  indexed_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS types (
+  source TEXT NOT NULL,
+  type_path TEXT NOT NULL,
+  category TEXT NOT NULL,
+  version TEXT NOT NULL,
+  description TEXT NOT NULL,
+  aliases TEXT NOT NULL,
+  topic TEXT,
+  vendor TEXT,
+  stamp TEXT NOT NULL,
+  data TEXT NOT NULL,
+  PRIMARY KEY (source, type_path)
+);
+CREATE TABLE IF NOT EXISTS tags (
+  source TEXT NOT NULL,
+  type_path TEXT NOT NULL,
+  tag TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tags_by_tag ON tags (tag);
+CREATE INDEX IF NOT EXISTS tags_by_type ON tags (source, type_path);
+CREATE TABLE IF NOT EXISTS dependencies (
+  source TEXT NOT NULL,
+  type_path TEXT NOT NULL,
+  dependency TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS dependencies_by_dependency ON dependencies (dependency);
+CREATE INDEX IF NOT EXISTS dependencies_by_type ON dependencies (source, type_path);
+CREATE TABLE IF NOT EXISTS cli_dependencies (
+  source TEXT NOT NULL,
+  type_path TEXT NOT NULL,
+  name TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS cli_dependencies_by_type ON cli_dependencies (source, type_path);
+`;
+
+const TYPE_TABLES = ['types', 'tags', 'dependencies', 'cli_dependencies'];
+
+// Primary SQLite result codes (the low byte of an extended code)
+const SQLITE_BUSY = 5;
+const SQLITE_LOCKED = 6;
+const SQLITE_CORRUPT = 11;
+const SQLITE_NOTADB = 26;
+
+/** An index written by another INDEX_FORMAT. */
+class IndexFormatError extends Error {}
+
+function sqliteCode(err: unknown): number | null {
+  const code = (err as { errcode?: unknown } | null)?.errcode;
+  return typeof code === 'number' ? code & 0xff : null;
+}
+
+/** Whether err came from SQLite, as opposed to the caller's own code. */
+export function isSqliteError(err: unknown): boolean {
+  return sqliteCode(err) !== null;
+}
+
+/** Whether err means the file can't be an index, so rebuilding it is safe. */
+function isCorrupt(err: unknown): boolean {
+  const code = sqliteCode(err);
+  return err instanceof IndexFormatError || code === SQLITE_CORRUPT || code === SQLITE_NOTADB;
+}
+
+/** Whether err means another connection holds the index. */
+function isBusy(err: unknown): boolean {
+  const code = sqliteCode(err);
+  return code === SQLITE_BUSY || code === SQLITE_LOCKED;
+}
+
+const OPEN_ATTEMPTS = 3;
+
+export interface IndexedSource {
+  name: string;
+  basePath: string;
+  fingerprint: string;
+  indexedAt: string;
+}
+
+/** A parsed manifest with what the index stores beside it. */
+export interface IndexEntry {
+  type: DiscoveredType;
+  topic: string | null;
+  vendor: string | null;
+  /** Size and mtime of the manifest when it was parsed. */
+  stamp: string;
+  /** Type paths the manifest references. */
+  dependencies: string[];
+  /** Names of the CLIs a skill needs. */
+  cliDependencies: string[];
+}
+
+/** Filters for query(); every one given must match. */
+export interface IndexQuery {
+  /** Case-insensitive substring of the path, description, or an alias. */
+  text?: string;
+  category?: string;
+  /** Matches any, case-insensitively. */
+  tags?: string[];
+  topic?: string;
+  vendor?: string;
+  cli?: string;
+}
+
+/** A type, named by its source and path, that references another. */
+export interface IndexedReference {
+  sourceName: string;
+  typePath: string;
+  dependency: string;
+}
+
+/** Manifest value, else the path segment at index (category/topic/vendor/...). */
+export function classify(typePath: string, declared: unknown, index: number): string | null {
+  if (typeof declared === 'string' && declared) return declared;
+  const segments = typePath.split('/');
+  // The last segment is the name, never a topic or vendor
+  return index < segments.length - 1 ? segments[index] : null;
+}
+
+type SqliteModule = typeof import('node:sqlite');
+
+let sqlite: SqliteModule | undefined;
+
+/** node:sqlite, loaded without printing its experimental-feature warning. */
+function loadSqlite(): SqliteModule {
+  if (sqlite) return sqlite;
+  // Only the require's own ExperimentalWarning is dropped; anything else
+  // emitted meanwhile goes through
+  const emitWarning = process.emitWarning;
+  process.emitWarning = ((warning: string | Error, ...rest: unknown[]) => {
+    const type = warning instanceof Error ? warning.name : typeof rest[0] === 'string' ? rest[0] : (rest[0] as { type?: string })?.type;
+    const message = warning instanceof Error ? warning.message : warning;
+    if (type === 'ExperimentalWarning' && /sqlite/i.test(message)) return;
+    (emitWarning as (...args: unknown[]) => void).call(process, warning, ...rest);
+  }) as typeof process.emitWarning;
+  try {
+    sqlite = createRequire(import.meta.url)('node:sqlite') as SqliteModule;
+  } finally {
+    process.emitWarning = emitWarning;
+  }
+  return sqlite;
+}
+
+const escapeLike = (text: string) => text.replace(/[\\%_]/g, (c) => `\\${c}`);
+
+export class RegistryIndex {
+  private constructor(private readonly db: DatabaseSync) {}
+
+  /**
+   * Opens the index at path, creating it if needed. An index from another
+   * format, or a corrupt one, is deleted and created again; a busy one is
+   * retried. Other errors are thrown with the file left as it is.
+   */
+  static open(path: string): RegistryIndex {
+    const mod = loadSqlite();
+    if (path !== ':memory:') mkdirSync(dirname(path), { recursive: true });
+    let rebuilt = false;
+    for (let attempt = 1; ; attempt++) {
+      let db: DatabaseSync | null = null;
+      try {
+        db = new mod.DatabaseSync(path);
+        // Another agentx may be refreshing the index; wait for it rather than fail
+        db.exec('PRAGMA busy_timeout = 5000; PRAGMA journal_mode = WAL;');
+        const { user_version: format } = db.prepare('PRAGMA user_version').get() as { user_version: number };
+        if (format === 0) {
+          db.exec(SCHEMA);
+          db.exec(`PRAGMA user_version = ${INDEX_FORMAT}`);
+        } else if (format !== INDEX_FORMAT) {
+          throw new IndexFormatError(`index format ${format}, expected ${INDEX_FORMAT}`);
+        }
+        return new RegistryIndex(db);
+      } catch (err) {
+        db?.close();
+        const reason = err instanceof Error ? err.message : String(err);
+        if (isCorrupt(err) && !rebuilt) {
+          log.debug('rebuilding registry index', { path, reason });
+          removeIndex(path);
+          rebuilt = true;
+        } else if (isBusy(err) && attempt < OPEN_ATTEMPTS) {
+          log.debug('registry index busy; retrying', { path, attempt, reason });
+        } else {
+          throw err;
+        }
+      }
+    }
+  }
+
+  close(): void {
+    this.db.close();
+  }
+
+  source(name: string): IndexedSource | null {
+    return this.sources().find((s) => s.name === name) ?? null;
+  }
+
+  sources(): IndexedSource[] {
+    const rows = this.db
+      .prepare('SELECT name, base_path, fingerprint, indexed_at FROM sources ORDER BY name')
+      .all() as { name: string; base_path: string; fingerprint: string; indexed_at: string }[];
+    return rows.map((r) => ({ name: r.name, basePath: r.base_path, fingerprint: r.fingerprint, indexedAt: r.indexed_at }));
+  }
+
+  /** Manifest stamps of a source's indexed types, by type path. */
+  stamps(sourceName: string): Map<string, string> {
+    const rows = this.db
+      .prepare('SELECT type_path, stamp FROM types WHERE source = ?')
+      .all(sourceName) as { type_path: string; stamp: string }[];
+    return new Map(rows.map((r) => [r.type_path, r.stamp]));
+  }
+
+  /** A source's types, by path. */
+  types(sourceName: string): DiscoveredType[] {
+    const rows = this.db
+      .prepare('SELECT data FROM types WHERE source = ? ORDER BY type_path')
+      .all(sourceName) as { data: string }[];
+    return rows.map((r) => JSON.parse(r.data) as DiscoveredType);
+  }
+
+  /**
+   * Brings a source up to date in one transaction: types not in keep are
+   * removed, and entries are written over whatever was there for their
+   * paths.
+   */
+  updateSource(source: Source, fingerprint: string, keep: string[], entries: IndexEntry[]): void {
+    const db = this.db;
+    db.exec('BEGIN IMMEDIATE');
+    try {
+      const known = this.source(source.name);
+      if (known && known.basePath !== source.basePath) this.deleteTypes(source.name);
+
+      const kept = new Set(keep);
+      const written = new Set(entries.map((e) => e.type.typePath));
+      for (const typePath of this.stamps(source.name).keys()) {
+        if (!kept.has(typePath) || written.has(typePath)) this.deleteTypes(source.name, typePath);
+      }
+
+      const insertType = db.prepare(
+        `INSERT INTO types (source, type_path, category, version, description, aliases, topic, vendor, stamp, data)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+      );
+      const insertTag = db.prepare('INSERT INTO tags (source, type_path, tag) VALUES (?, ?, ?)');
+      const insertDep = db.prepare('INSERT INTO dependencies (source, type_path, dependency) VALUES (?, ?, ?)');
+      const insertCli = db.prepare('INSERT INTO cli_dependencies (source, type_path, name) VALUES (?, ?, ?)');
+      for (const e of entries) {
+        const t = e.type;
+        insertType.run(
+          source.name,
+          t.typePath,
+          t.category,
+          t.version,
+          t.description,
+          (t.aliases ?? []).join('\n'),
+          e.topic,
+          e.vendor,
+          e.stamp,
+          JSON.stringify(t),
+        );
+        for (const tag of new Set(t.tags.map((tag) => tag.toLowerCase()))) insertTag.run(source.name, t.typePath, tag);
+        for (const dep of new Set(e.dependencies)) insertDep.run(source.name, t.typePath, dep);
+        for (const name of new Set(e.cliDependencies)) insertCli.run(source.name, t.typePath, name);
+      }
+
+      db.prepare(
+        `INSERT INTO sources (name, base_path, fingerprint, indexed_at) VALUES (?, ?, ?, ?)
+         ON CONFLICT (name) DO UPDATE SET base_path = excluded.base_path, fingerprint = excluded.fingerprint, indexed_at = excluded.indexed_at`,
+      ).run(source.name, source.basePath, fingerprint, new Date().toISOString());
+      db.exec('COMMIT');
+    } catch (err) {
+      db.exec('ROLLBACK');
+      throw err;
+    }
+  }
+
+  /** Removes a source and everything indexed from it. */
+  removeSource(name: string): void {
+    this.db.exec('BEGIN IMMEDIATE');
+    try {
+      this.deleteTypes(name);
+      this.db.prepare('DELETE FROM sources WHERE name = ?').run(name);
+      this.db.exec('COMMIT');
+    } catch (err) {
+      this.db.exec('ROLLBACK');
+      throw err;
+    }
+  }
+
+  /** Source and type path of every indexed type matching the query. */
+  query(q: IndexQuery): { sourceName: string; typePath: string }[] {
+    const where: string[] = [];
+    const params: string[] = [];
+    if (q.text) {
+      const like = `%${escapeLike(q.text)}%`;
+      where.push("(t.type_path LIKE ? ESCAPE '\\' OR t.description LIKE ? ESCAPE '\\' OR t.aliases LIKE ? ESCAPE '\\')");
+      params.push(like, like, like);
+    }
+    if (q.category) {
+      where.push('t.category = ?');
+      params.push(q.category);
+    }
+    if (q.topic) {
+      where.push('t.topic = ?');
+      params.push(q.topic);
+    }
+    if (q.vendor) {
+      where.push('t.vendor = ?');
+      params.push(q.vendor);
+    }
+    if (q.tags?.length) {
+      where.push(
+        `EXISTS (SELECT 1 FROM tags g WHERE g.source = t.source AND g.type_path = t.type_path AND g.tag IN (${q.tags.map(() => '?').join(', ')}))`,
+      );
+      params.push(...q.tags.map((tag) => tag.toLowerCase()));
+    }
+    if (q.cli) {
+      where.push('EXISTS (SELECT 1 FROM cli_dependencies c WHERE c.source = t.source AND c.type_path = t.type_path AND c.name = ?)');
+      params.push(q.cli);
+    }
+    const sql = `SELECT t.source, t.type_path FROM types t${where.length ? ` WHERE ${where.join(' AND ')}` : ''} ORDER BY t.type_path`;
+    const rows = this.db.prepare(sql).all(...params) as { source: string; type_path: string }[];
+    return rows.map((r) => ({ sourceName: r.source, typePath: r.type_path }));
+  }
+
+  /** Every reference between indexed types, optionally only those to one type path. */
+  references(dependency?: string): IndexedReference[] {
+    const rows = (
+      dependency
+        ? this.db
+            .prepare('SELECT source, type_path, dependency FROM dependencies WHERE dependency = ? ORDER BY type_path')
+            .all(dependency)
+        : this.db.prepare('SELECT source, type_path, dependency FROM dependencies ORDER BY type_path').all()
+    ) as { source: string; type_path: string; dependency: string }[];
+    return rows.map((r) => ({ sourceName: r.source, typePath: r.type_path, dependency: r.dependency }));
+  }
+
+  private deleteTypes(sourceName: string, typePath?: string): void {
+    for (const table of TYPE_TABLES) {
+      if (typePath === undefined) {
+        this.db.prepare(`DELETE FROM ${table} WHERE source = ?`).run(sourceName);
+      } else {
+        this.db.prepare(`DELETE FROM ${table} WHERE source = ? AND type_path = ?`).run(sourceName, typePath);
+      }
+    }
+  }
+}
+
+/** Deletes the index database and its WAL files. */
+export function removeIndex(path: string): void {
+  for (const suffix of ['', '-wal', '-shm']) rmSync(`${path}${suffix}`, { force: true });
+}
@@ -0,0 +1,254 @@
+import { execFileSync } from 'node:child_process';
+import { join, extname, resolve, relative, sep } from 'node:path';
+import { readFileSync, writeFileSync, appendFileSync, existsSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { SkillManifest } from '../types/manifest.js';
+import type { ResolvedType } from '../types/registry.js';
+import { envVar } from '../config/branding.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { nameFromPath } from './registry.js';
+import { isolatedEnv } from './runtime.js';
+import { isTrusted, recordTrust, type Contribution } from './trust.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('registry');
+
+// ── Registry migration ──────────────────────────────────────────────
+//
+// A skill's registry (tokens.env, config.yaml) is written once, on first
+// install. When a later version declares new tokens or config keys, the
+// registry would silently lack them, so an upgrade migrates it:
+//
+//   - declared tokens and top-level config keys that are missing are
+//     appended with their defaults and a comment naming the version;
+//   - a token with `renamed_from: OLD` takes over OLD's saved value;
+//   - keys no longer declared are reported, never deleted;
+//   - registry.migrate, a script in the skill, then runs with
+//     AGENTX_SKILL_REGISTRY, AGENTX_MIGRATE_FROM and AGENTX_MIGRATE_TO
+//     set, and the keys it changed are reported. It must lie inside the
+//     skill, is approved through the trust store like an extension hook
+//     (skipped until then), and sees only the environment the skill
+//     itself would (run.env_isolation).
+//
+// Values are never reported, only key names.
+
+export interface RegistryMigration {
+  typePath: string;
+  from: string | null;
+  to: string;
+  addedTokens: string[];
+  renamedTokens: { from: string; to: string }[];
+  /** Saved tokens the new version no longer declares. */
+  removedTokens: string[];
+  addedConfig: string[];
+  removedConfig: string[];
+  /** Keys the migration script added, removed, or changed. */
+  scriptChanges: string[];
+  /** The migration script, when it was not run because it isn't approved. */
+  skippedScript?: string;
+}
+
+export interface MigrateOptions {
+  /** Asks whether an unapproved migration script may run; the default declines. */
+  approve?: (c: Contribution) => Promise<boolean>;
+}
+
+interface RegistryKeys {
+  tokens: Record<string, string>;
+  config: Record<string, string>;
+}
+
+function readKeys(regDir: string): RegistryKeys {
+  const tokens: Record<string, string> = {};
+  const tokensPath = join(regDir, 'tokens.env');
+  if (existsSync(tokensPath)) {
+    for (const e of parseEnvFile(readFileSync(tokensPath, 'utf-8'))) tokens[e.key] = e.value;
+  }
+  const config: Record<string, string> = {};
+  const configPath = join(regDir, 'config.yaml');
+  if (existsSync(configPath)) {
+    const data = (yaml.load(readFileSync(configPath, 'utf-8')) ?? {}) as Record<string, unknown>;
+    for (const [k, v] of Object.entries(data)) config[k] = JSON.stringify(v);
+  }
+  return { tokens, config };
+}
+
+/** Keys whose presence or value differs, as "tokens.X" / "config.Y". */
+function changedKeys(before: RegistryKeys, after: RegistryKeys): string[] {
+  const changed: string[] = [];
+  for (const kind of ['tokens', 'config'] as const) {
+    const keys = new Set([...Object.keys(before[kind]), ...Object.keys(after[kind])]);
+    for (const k of [...keys].sort()) {
+      if (before[kind][k] !== after[kind][k]) changed.push(`${kind}.${k}`);
+    }
+  }
+  return changed;
+}
+
+function runScript(
+  script: string,
+  manifest: SkillManifest,
+  skillDir: string,
+  regDir: string,
+  from: string | null,
+  to: string,
+): void {
+  const ext = extname(script);
+  const [cmd, args] =
+    ext === '.mjs' || ext === '.js' ? ['node', [script]] : ext === '.py' ? ['python3', [script]] : [script, []];
+  try {
+    execFileSync(cmd, args, {
+      cwd: skillDir,
+      env: isolatedEnv(manifest, {
+        [envVar('SKILL_REGISTRY')]: regDir,
+        [envVar('MIGRATE_FROM')]: from ?? '',
+        [envVar('MIGRATE_TO')]: to,
+      }),
+      stdio: ['ignore', 'ignore', 'pipe'],
+    });
+  } catch (err) {
+    const stderr = (err as { stderr?: Buffer }).stderr?.toString().trim();
+    throw new Error(`Registry migration script ${script} failed${stderr ? `: ${stderr}` : ''}`);
+  }
+}
+
+/** The migration script's path, refusing one outside the skill directory. */
+function scriptPath(skillDir: string, migrate: string): string {
+  const base = resolve(skillDir);
+  const script = resolve(base, migrate);
+  if (!script.startsWith(base + sep)) {
+    throw new Error(`Registry migration script ${migrate} is outside the skill directory ${base}`);
+  }
+  return script;
+}
+
+/**
+ * Brings an existing skill registry up to the manifest's declarations.
+ * from is the version being replaced (null if unknown); the script only
+ * runs when it differs from the new version. Returns null when the skill
+ * has no registry or nothing changed.
+ */
+export async function migrateSkillRegistry(
+  resolved: ResolvedType,
+  skillsDir: string,
+  from: string | null,
+  opts: MigrateOptions = {},
+): Promise<RegistryMigration | null> {
+  if (resolved.category !== 'skill') return null;
+  const manifest = yaml.load(readFileSync(resolved.manifestPath, 'utf-8')) as SkillManifest;
+  const registry = manifest.registry;
+  const regDir = join(skillsDir, nameFromPath(resolved.typePath));
+  if (!registry || !existsSync(regDir)) return null;
+
+  const to = String(manifest.version);
+  const m: RegistryMigration = {
+    typePath: resolved.typePath,
+    from,
+    to,
+    addedTokens: [],
+    renamedTokens: [],
+    removedTokens: [],
+    addedConfig: [],
+    removedConfig: [],
+    scriptChanges: [],
+  };
+  const before = readKeys(regDir);
+
+  // Tokens
+  const tokensPath = join(regDir, 'tokens.env');
+  const declared = registry.tokens ?? [];
+  if (existsSync(tokensPath) && declared.length > 0) {
+    let content = readFileSync(tokensPath, 'utf-8');
+    for (const token of declared) {
+      if (token.name in before.tokens) continue;
+      const old = token.renamed_from;
+      if (old && old in before.tokens) {
+        content = content
+          .split('\n')
+          .map((line) => (line.trim().startsWith(`${old}=`) ? `# Renamed from ${old} in ${to}\n${token.name}=${before.tokens[old]}` : line))
+          .join('\n');
+        m.renamedTokens.push({ from: old, to: token.name });
+        continue;
+      }
+      const lines = ['', `# Added in ${to}${token.description ? `: ${token.description}` : ''}`];
+      if (token.required) lines.push('# (required)');
+      lines.push(`${token.name}=${token.default ?? ''}`, '');
+      content = content.replace(/\n*$/, '\n') + lines.join('\n');
+      m.addedTokens.push(token.name);
+    }
+    writeFileSync(tokensPath, content, { mode: 0o600 });
+  }
+  const names = new Set(declared.map((t) => t.name));
+  const renamed = new Set(m.renamedTokens.map((r) => r.from));
+  m.removedTokens = Object.keys(before.tokens).filter((k) => !names.has(k) && !renamed.has(k));
+
+  // Config
+  const configPath = join(regDir, 'config.yaml');
+  const defaults = registry.config ?? {};
+  if (existsSync(configPath) && Object.keys(defaults).length > 0) {
+    const missing = Object.keys(defaults).filter((k) => !(k in before.config));
+    if (missing.length > 0) {
+      const added = Object.fromEntries(missing.map((k) => [k, defaults[k]]));
+      const existing = readFileSync(configPath, 'utf-8');
+      appendFileSync(configPath, `${existing.endsWith('\n') || !existing ? '' : '\n'}# Added in ${to}\n${yaml.dump(added)}`);
+      m.addedConfig = missing;
+    }
+    m.removedConfig = Object.keys(before.config).filter((k) => !(k in defaults));
+  }
+
+  // Script
+  if (registry.migrate && from !== to) {
+    const script = scriptPath(resolved.sourceDir, registry.migrate);
+    const contribution: Contribution = {
+      extension: resolved.sourceName,
+      kind: 'migration',
+      name: resolved.typePath,
+      description: `Migrates the ${nameFromPath(resolved.typePath)} registry from ${from ?? 'an unknown version'} to ${to}`,
+      baseDir: resolved.sourceDir,
+      path: relative(resolve(resolved.sourceDir), script),
+    };
+    let approved = isTrusted(contribution);
+    if (!approved && opts.approve && (await opts.approve(contribution))) {
+      recordTrust(contribution);
+      approved = true;
+    }
+    if (approved) {
+      const beforeScript = readKeys(regDir);
+      runScript(script, manifest, resolved.sourceDir, regDir, from, to);
+      m.scriptChanges = changedKeys(beforeScript, readKeys(regDir));
+    } else {
+      m.skippedScript = registry.migrate;
+    }
+  }
+
+  const changed =
+    m.addedTokens.length + m.renamedTokens.length + m.removedTokens.length +
+    m.addedConfig.length + m.removedConfig.length + m.scriptChanges.length + (m.skippedScript ? 1 : 0);
+  if (changed === 0) return null;
+  log.info('registry migrated', {
+    type: m.typePath,
+    from,
+    to,
+    added: [...m.addedTokens, ...m.addedConfig],
+    renamed: m.renamedTokens.map((r) => `${r.from}->${r.to}`),
+    script: m.scriptChanges,
+  });
+  return m;
+}
+
+/** One line per change, for install output. */
+export function describeMigration(m: RegistryMigration): string[] {
+  const name = nameFromPath(m.typePath);
+  const lines: string[] = [];
+  const list = (keys: string[]) => keys.join(', ');
+  if (m.addedTokens.length) lines.push(`${name}: added token(s) ${list(m.addedTokens)} to tokens.env; set them with \`agentx env edit ${name}\``);
+  for (const r of m.renamedTokens) lines.push(`${name}: token ${r.from} is now ${r.to}; its value was kept`);
+  if (m.addedConfig.length) lines.push(`${name}: added config key(s) ${list(m.addedConfig)} with defaults`);
+  if (m.removedTokens.length) lines.push(`${name}: token(s) ${list(m.removedTokens)} are no longer used by ${m.to}; remove them if unneeded`);
+  if (m.removedConfig.length) lines.push(`${name}: config key(s) ${list(m.removedConfig)} are no longer used by ${m.to}`);
+  if (m.scriptChanges.length) lines.push(`${name}: migration ${m.from ?? '?'} → ${m.to} changed ${list(m.scriptChanges)}`);
+  if (m.skippedScript) {
+    lines.push(`${name}: skipped migration script ${m.skippedScript}: not approved yet (run the install in a terminal to review it)`);
+  }
+  return lines;
+}
@@ -1,15 +1,16 @@
-import { join, relative, sep } from 'node:path';
+import { join, dirname, relative, sep } from 'node:path';
 import {
   existsSync,
   readdirSync,
   readFileSync,
   writeFileSync,
-  mkdirSync,
   rmSync,
   statSync,
   copyFileSync,
 } from 'node:fs';
+import { readdir, readFile, stat } from 'node:fs/promises';
 import { execFileSync } from 'node:child_process';
+import { createHash } from 'node:crypto';
 import yaml from 'js-yaml';
 import type {
   Source,
@@ -20,7 +21,7 @@ import type {
   DiscoveredType,
   InstallResult,
 } from '../types/registry.js';
-import type { ManifestType } from '../config/schema.js';
+import type { ManifestType, MergeStrategy } from '../config/schema.js';
 import type {
   BaseManifest,
   SkillManifest,
@@ -29,7 +30,29 @@ import type {
   PromptManifest,
 } from '../types/manifest.js';
 import { getHomeRoot } from './userdata.js';
-import { copyDir as copyDirUtil, ensureDir } from '../utils/fs.js';
+import { AgentxError } from './errors.js';
+import { RegistryIndex, classify, removeIndex, isSqliteError, type IndexEntry, type IndexQuery } from './registry-index.js';
+import { ensureDir, globToRegExp } from '../utils/fs.js';
+import { mapConcurrent } from '../utils/concurrency.js';
+import {
+  storeType,
+  materialize,
+  clearCurrent,
+  stageSnapshot,
+  currentSnapshot,
+  diffFiles,
+  removeTree,
+  type TypeSnapshot,
+  type FileChanges,
+} from './store.js';
+import { mergeStrategy, contentDir, sourceLabel } from './merge.js';
+import { isOffline, offlineSkip } from './offline.js';
+import { vendoredArchive, unpackVendoredDeps } from './vendored-deps.js';
+import { nodeCacheEnabled, nodeCacheKey, linkCachedModules, populateNodeCache } from './node-cache.js';
+import { childEnv } from '../utils/http.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('registry');
 
 // ── Constants ───────────────────────────────────────────────────────
 
@@ -53,7 +76,12 @@ const MANIFEST_FILES = new Set([
   'template.yaml',
 ]);
 
-const EXCLUDED_NAMES = new Set(['node_modules', '.git', '.DS_Store']);
+// .versions holds side-by-side versions of installed types (core/versions.ts)
+const EXCLUDED_NAMES = new Set(['node_modules', '.git', '.DS_Store', '.versions']);
+
+export function isManifestFile(name: string): boolean {
+  return MANIFEST_FILES.has(name);
+}
 
 const PLURAL_TO_SINGULAR: Record<string, ManifestType> = {
   context: 'context',
@@ -104,28 +132,170 @@ function findManifest(dir: string, typePath: string): string | null {
   return null;
 }
 
+function resolveIn(source: Source, typePath: string): ResolvedType | null {
+  const dir = join(source.basePath, typePath);
+  const manifestPath = findManifest(dir, typePath);
+  if (!manifestPath) return null;
+  return {
+    typePath,
+    manifestPath,
+    sourceDir: dir,
+    sourceName: source.name,
+    category: categoryFromPath(typePath),
+    // <project>/.agentx/extensions/<name> → <project>/.agentx
+    ...(source.local ? { projectDir: dirname(dirname(source.basePath)) } : {}),
+  };
+}
+
+/** Whether project.yaml `prefer:` pins typePath to source. */
+export function isPinned(source: Source, typePath: string): boolean {
+  return (source.pinned ?? []).some((glob) => globToRegExp(glob).test(typePath));
+}
+
+/** sources in the order they are consulted for typePath: pinned ones first. */
+export function sourcesFor(typePath: string, sources: Source[]): Source[] {
+  const pinned = sources.filter((s) => isPinned(s, typePath));
+  if (pinned.length === 0) return sources;
+  return [...pinned, ...sources.filter((s) => !pinned.includes(s))];
+}
+
 export function resolveType(
   typePath: string,
   sources: Source[],
 ): ResolvedType | null {
-  const category = categoryFromPath(typePath);
+  const ordered = sourcesFor(typePath, sources);
+  for (let i = 0; i < ordered.length; i++) {
+    const resolved = resolveIn(ordered[i], typePath);
+    if (resolved) return applyMergeStrategies(resolved, ordered.slice(i + 1));
+  }
+  return resolveAlias(typePath, sources);
+}
+
+export interface SourceCandidate {
+  source: string;
+  priority: number;
+  /** The prefer: glob pinning the type to this source. */
+  pinnedBy: string | null;
+  provides: boolean;
+  /** Its extension.yaml merge strategy for the type. */
+  merge: MergeStrategy | null;
+}
 
-  for (const source of sources) {
-    const dir = join(source.basePath, typePath);
-    const manifestPath = findManifest(dir, typePath);
-    if (manifestPath) {
-      return {
-        typePath,
-        manifestPath,
-        sourceDir: dir,
-        sourceName: source.name,
-        category,
-      };
+export interface TypeResolution {
+  typePath: string;
+  resolved: ResolvedType | null;
+  /** Every source, in the order consulted for typePath. */
+  candidates: SourceCandidate[];
+  reason: string;
+}
+
+/** Which source resolveType picks for typePath, and why. */
+export function explainResolution(typePath: string, sources: Source[]): TypeResolution {
+  const candidates = sourcesFor(typePath, sources).map((s) => ({
+    source: s.name,
+    priority: s.priority ?? 0,
+    pinnedBy: (s.pinned ?? []).find((glob) => globToRegExp(glob).test(typePath)) ?? null,
+    provides: resolveIn(s, typePath) !== null,
+    merge: mergeStrategy(s, typePath),
+  }));
+  const resolved = resolveType(typePath, sources);
+  return { typePath, resolved, candidates, reason: resolutionReason(typePath, resolved, candidates) };
+}
+
+function resolutionReason(typePath: string, resolved: ResolvedType | null, candidates: SourceCandidate[]): string {
+  if (!resolved) return `No source provides ${typePath}`;
+  if (resolved.aliasOf) return `${typePath} is an alias of ${resolved.typePath}, provided by ${resolved.sourceName}`;
+
+  const providers = candidates.filter((c) => c.provides);
+  const first = providers[0];
+  let reason: string;
+  if (resolved.sourceName !== first.source) {
+    reason = `${resolved.sourceName} overrides ${first.source} (merge: override in its extension.yaml)`;
+  } else if (first.pinnedBy) {
+    reason = `pinned to ${first.source} by prefer: ${first.pinnedBy}`;
+  } else if (providers.length === 1) {
+    reason = `${first.source} is the only source that provides it`;
+  } else if (first.priority > providers[1].priority) {
+    reason = `${first.source} has the highest priority (${first.priority}) of the ${providers.length} sources that provide it`;
+  } else {
+    const tied = providers.filter((c) => c.priority === first.priority).map((c) => c.source);
+    reason = `${tied.join(', ')} tie at priority ${first.priority}; the catalog comes first, then extensions by name`;
+  }
+  const appended = (resolved.appended ?? []).map((a) => a.sourceName);
+  if (appended.length) reason += `; ${appended.join(', ')} append to it (merge: append)`;
+  return reason;
+}
+
+/**
+ * Lets later sources that declare a merge strategy for the type override
+ * or append to the first source's copy. See core/merge.ts.
+ */
+function applyMergeStrategies(first: ResolvedType, later: Source[]): ResolvedType {
+  let resolved = first;
+  for (const source of later) {
+    const strategy = mergeStrategy(source, first.typePath);
+    if (!strategy) continue;
+    const other = resolveIn(source, first.typePath);
+    if (!other) continue;
+    if (strategy === 'override') {
+      resolved = other;
+    } else if (first.category !== 'context') {
+      log.warn('append ignored for non-context type', { type: first.typePath, source: source.name });
+    } else {
+      const appended = { sourceName: other.sourceName, sourceDir: other.sourceDir, manifestPath: other.manifestPath };
+      const projectDir = resolved.projectDir ?? other.projectDir;
+      resolved = { ...resolved, appended: [...(resolved.appended ?? []), appended], ...(projectDir ? { projectDir } : {}) };
+    }
+  }
+  return resolved;
+}
+
+// ── Aliases & Deprecation ───────────────────────────────────────────
+//
+// Catalogs get reorganized. A moved type lists its former paths under
+// `aliases:`, so project.yaml files and dependency lists written against
+// the old paths keep resolving. A retired type sets `deprecated: true`
+// and, when there is one, `replaced_by:` its successor.
+
+function readBase(manifestPath: string): BaseManifest | null {
+  try {
+    return yaml.load(readFileSync(manifestPath, 'utf-8')) as BaseManifest;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * The type that declares typePath as an alias, in source precedence
+ * order. Only consulted when no type lives at typePath itself.
+ */
+export function resolveAlias(typePath: string, sources: Source[]): ResolvedType | null {
+  for (const t of discoverTypes(sources)) {
+    if (readBase(t.manifestPath)?.aliases?.includes(typePath)) {
+      return { ...t, aliasOf: typePath };
     }
   }
   return null;
 }
 
+/** The installed path a reference points to, following aliases; ref itself if unknown. */
+export function canonicalTypePath(ref: string, installedRoot: string): string {
+  if (existsSync(join(installedRoot, ref))) return ref;
+  return resolveAlias(ref, [{ name: 'installed', basePath: installedRoot }])?.typePath ?? ref;
+}
+
+export interface Deprecation {
+  typePath: string;
+  replacedBy: string | null;
+}
+
+/** Deprecation notice for a resolved type, or null when it is current. */
+export function deprecationOf(resolved: ResolvedType): Deprecation | null {
+  const base = readBase(resolved.manifestPath);
+  if (!base?.deprecated) return null;
+  return { typePath: resolved.typePath, replacedBy: base.replaced_by ?? null };
+}
+
 // ── Discovery ───────────────────────────────────────────────────────
 
 function walkSource(source: Source): ResolvedType[] {
@@ -182,19 +352,26 @@ function walkDir(
   }
 }
 
-export function discoverTypes(sources: Source[]): ResolvedType[] {
-  const seen = new Set<string>();
-  const results: ResolvedType[] = [];
-
-  for (const source of sources) {
-    for (const resolved of walkSource(source)) {
-      if (!seen.has(resolved.typePath)) {
-        seen.add(resolved.typePath);
-        results.push(resolved);
-      }
-    }
+/**
+ * One entry per type path, in first-seen order: the first source's, or
+ * the one from a source pinned for that path.
+ */
+function dedupe<T extends ResolvedType>(found: T[], sources: Source[]): T[] {
+  const byName = new Map(sources.map((s) => [s.name, s]));
+  const pinned = (t: T) => {
+    const source = byName.get(t.sourceName);
+    return source ? isPinned(source, t.typePath) : false;
+  };
+  const chosen = new Map<string, T>();
+  for (const t of found) {
+    const current = chosen.get(t.typePath);
+    if (!current || (!pinned(current) && pinned(t))) chosen.set(t.typePath, t);
   }
-  return results;
+  return [...chosen.values()];
+}
+
+export function discoverTypes(sources: Source[]): ResolvedType[] {
+  return dedupe(sources.flatMap(walkSource), sources);
 }
 
 export function discoverByCategory(
@@ -204,35 +381,211 @@ export function discoverByCategory(
   return discoverTypes(sources).filter((t) => t.category === category);
 }
 
-export function discoverAll(sources: Source[]): DiscoveredType[] {
+/**
+ * Discovers and parses every type. Manifests that fail to parse are
+ * skipped; pass `warnings` to hear about them.
+ */
+export function discoverAll(sources: Source[], warnings?: string[]): DiscoveredType[] {
   const resolved = discoverTypes(sources);
   const enriched: DiscoveredType[] = [];
 
   for (const r of resolved) {
     try {
       const raw = readFileSync(r.manifestPath, 'utf-8');
-      const data = yaml.load(raw) as Record<string, unknown>;
-      const base = data as BaseManifest;
-
-      const d: DiscoveredType = {
-        ...r,
-        version: String(base.version ?? ''),
-        description: String(base.description ?? ''),
-        tags: Array.isArray(base.tags) ? base.tags.map(String) : [],
-      };
-      enriched.push(d);
-    } catch {
-      // Skip types with unparseable manifests
+      enriched.push(toDiscovered(r, yaml.load(raw) as BaseManifest));
+    } catch (err) {
+      warnings?.push(skippedManifest(r, err));
     }
   }
   return enriched;
 }
 
+function toDiscovered(r: ResolvedType, base: BaseManifest): DiscoveredType {
+  const d: DiscoveredType = {
+    ...r,
+    version: String(base.version ?? ''),
+    description: String(base.description ?? ''),
+    tags: Array.isArray(base.tags) ? base.tags.map(String) : [],
+  };
+  if (base.deprecated) d.deprecated = true;
+  if (base.replaced_by) d.replacedBy = base.replaced_by;
+  if (Array.isArray(base.aliases) && base.aliases.length > 0) d.aliases = base.aliases.map(String);
+  return d;
+}
+
+function skippedManifest(r: ResolvedType, err: unknown): string {
+  const reason = err instanceof Error ? err.message.split('\n')[0] : String(err);
+  return `Skipping ${r.typePath} (${r.sourceName}): unparseable manifest: ${reason}`;
+}
+
+// ── Parallel Discovery ──────────────────────────────────────────────
+//
+// Large extension catalogs hold thousands of types. These variants walk
+// directories and parse manifests with bounded concurrency instead of
+// one blocking call at a time.
+
+const DISCOVERY_CONCURRENCY = 64;
+const STREAM_BATCH = 256;
+
+/** Async walkSource: breadth-first, reading each level's directories in parallel. */
+async function walkSourceAsync(source: Source): Promise<ResolvedType[]> {
+  const results: ResolvedType[] = [];
+  let frontier = KNOWN_CATEGORIES.map((cat) => join(source.basePath, cat));
+
+  while (frontier.length > 0) {
+    const children = await mapConcurrent(frontier, DISCOVERY_CONCURRENCY, async (dir) => {
+      let entries;
+      try {
+        entries = await readdir(dir, { withFileTypes: true });
+      } catch {
+        return [];
+      }
+
+      const manifest = entries.find((e) => e.isFile() && MANIFEST_FILES.has(e.name));
+      if (manifest) {
+        const rel = relative(source.basePath, dir).split(sep).join('/');
+        results.push({
+          typePath: rel,
+          manifestPath: join(dir, manifest.name),
+          sourceDir: dir,
+          sourceName: source.name,
+          category: categoryFromPath(rel),
+        });
+        return []; // Don't recurse deeper once manifest found
+      }
+
+      return entries
+        .filter((e) => e.isDirectory() && !EXCLUDED_NAMES.has(e.name))
+        .map((e) => join(dir, e.name));
+    });
+    frontier = children.flat();
+  }
+
+  // Completion order is arbitrary; restore the sequential walk's ordering
+  const catIndex = (t: ResolvedType) => KNOWN_CATEGORIES.indexOf(t.typePath.split('/')[0]);
+  return results.sort((a, b) => catIndex(a) - catIndex(b) || a.typePath.localeCompare(b.typePath));
+}
+
+async function parseDiscovered(
+  r: ResolvedType,
+  warnings?: string[],
+): Promise<DiscoveredType | null> {
+  try {
+    return toDiscovered(r, yaml.load(await readFile(r.manifestPath, 'utf-8')) as BaseManifest);
+  } catch (err) {
+    warnings?.push(skippedManifest(r, err));
+    return null;
+  }
+}
+
+async function parseAll(resolved: ResolvedType[], warnings?: string[]): Promise<DiscoveredType[]> {
+  const parsed = await mapConcurrent(resolved, DISCOVERY_CONCURRENCY, (r) =>
+    parseDiscovered(r, warnings),
+  );
+  return parsed.filter((d): d is DiscoveredType => d !== null);
+}
+
+/**
+ * Streams discovered types as batches finish parsing. Every source is
+ * walked first so a later source pinned for a path can win it; the
+ * winners are exactly discoverAll's.
+ */
+export async function* discoverAllStream(
+  sources: Source[],
+  warnings?: string[],
+): AsyncGenerator<DiscoveredType> {
+  const found: ResolvedType[] = [];
+  for (const source of sources) found.push(...(await walkSourceAsync(source)));
+  const resolved = dedupe(found, sources);
+
+  for (let i = 0; i < resolved.length; i += STREAM_BATCH) {
+    yield* await parseAll(resolved.slice(i, i + STREAM_BATCH), warnings);
+  }
+}
+
+export async function discoverAllAsync(
+  sources: Source[],
+  warnings?: string[],
+): Promise<DiscoveredType[]> {
+  const results: DiscoveredType[] = [];
+  for await (const t of discoverAllStream(sources, warnings)) results.push(t);
+  return results;
+}
+
+// ── Fuzzy Matching ──────────────────────────────────────────────────
+
+const MAX_SUGGESTIONS = 3;
+
+export function levenshtein(a: string, b: string): number {
+  if (a === b) return 0;
+  let prev = Array.from({ length: b.length + 1 }, (_, i) => i);
+  for (let i = 1; i <= a.length; i++) {
+    const curr = [i];
+    for (let j = 1; j <= b.length; j++) {
+      const cost = a[i - 1] === b[j - 1] ? 0 : 1;
+      curr[j] = Math.min(prev[j] + 1, curr[j - 1] + 1, prev[j - 1] + cost);
+    }
+    prev = curr;
+  }
+  return prev[b.length];
+}
+
+function lastSegment(path: string): string {
+  return path.slice(path.lastIndexOf('/') + 1);
+}
+
+/**
+ * Edit distance from a query to a type path, taking the better of the
+ * whole path and its last segment so both a misspelled name and a wrong
+ * category directory score low. Substring matches score 0.
+ */
+export function fuzzyDistance(query: string, typePath: string): number {
+  const q = query.toLowerCase();
+  const t = typePath.toLowerCase();
+  if (t.includes(q)) return 0;
+  return Math.min(levenshtein(q, t), levenshtein(lastSegment(q), lastSegment(t)));
+}
+
+/** Largest distance still considered a match: about a third of the name. */
+export function fuzzyThreshold(query: string): number {
+  return Math.max(2, Math.floor(lastSegment(query).length / 3));
+}
+
+export function suggestTypePaths(
+  input: string,
+  candidates: string[],
+  limit = MAX_SUGGESTIONS,
+): string[] {
+  const threshold = fuzzyThreshold(input);
+  return candidates
+    .filter((c) => c !== input)
+    .map((c) => ({ c, d: fuzzyDistance(input, c) }))
+    .filter(({ d }) => d <= threshold)
+    .sort((a, b) => a.d - b.d || a.c.localeCompare(b.c))
+    .slice(0, limit)
+    .map(({ c }) => c);
+}
+
+/** Error-message suffix listing close matches, or '' when there are none. */
+export function didYouMean(input: string, candidates: string[]): string {
+  const suggestions = suggestTypePaths(input, candidates);
+  if (suggestions.length === 0) return '';
+  return `\nDid you mean:\n${suggestions.map((s) => `  ${s}`).join('\n')}`;
+}
+
+/** Type paths currently installed, for suggestions on install-relative errors. */
+export function installedTypePaths(installedRoot: string): string[] {
+  return discoverTypes([{ name: 'installed', basePath: installedRoot }]).map((t) => t.typePath);
+}
+
 // ── Dependency Tree ─────────────────────────────────────────────────
 
-function extractDependencies(manifestPath: string): string[] {
-  const raw = readFileSync(manifestPath, 'utf-8');
-  const data = yaml.load(raw) as Record<string, unknown>;
+/** Type paths a manifest references: persona, context, skills, workflow steps. */
+export function extractDependencies(manifestPath: string): string[] {
+  return manifestDependencies(yaml.load(readFileSync(manifestPath, 'utf-8')) as Record<string, unknown>);
+}
+
+function manifestDependencies(data: Record<string, unknown>): string[] {
   const type = data.type as string;
   const deps: string[] = [];
 
@@ -249,7 +602,7 @@ function extractDependencies(manifestPath: string): string[] {
       const w = data as unknown as WorkflowManifest;
       if (w.steps) {
         for (const step of w.steps) {
-          deps.push(step.skill);
+          if ('skill' in step) deps.push(step.skill);
         }
       }
       break;
@@ -295,6 +648,16 @@ function buildNode(
   const resolved = resolveType(typePath, sources);
   if (!resolved) return node;
   node.resolved = resolved;
+  if (resolved.aliasOf) {
+    node.typePath = resolved.typePath;
+    node.category = resolved.category;
+    node.installed = existsSync(join(installedRoot, resolved.typePath));
+    if (seen.has(resolved.typePath)) {
+      node.deduped = true;
+      return node;
+    }
+    seen.set(resolved.typePath, true);
+  }
 
   const deps = extractDependencies(resolved.manifestPath);
   for (const dep of deps) {
@@ -337,6 +700,65 @@ function flattenRecursive(
   }
 }
 
+// ── Reverse Dependencies ────────────────────────────────────────────
+
+/** Maps each referenced type path to the types whose manifests reference it. */
+export function reverseDependencyIndex(sources: Source[]): Map<string, ResolvedType[]> {
+  const index = new Map<string, ResolvedType[]>();
+  for (const t of discoverTypes(sources)) {
+    let deps: string[];
+    try {
+      deps = extractDependencies(t.manifestPath);
+    } catch {
+      continue; // Unparseable manifests reference nothing we can see
+    }
+    for (const dep of new Set(deps)) {
+      const list = index.get(dep) ?? [];
+      list.push(t);
+      index.set(dep, list);
+    }
+  }
+  return index;
+}
+
+/**
+ * Types that reference typePath, as a tree rooted at it whose children
+ * are dependents. With transitive, dependents of dependents are
+ * included (a prompt reaching a context through a persona). dependents,
+ * from indexedDependents, saves reading every manifest.
+ */
+export function findDependents(
+  typePath: string,
+  sources: Source[],
+  installedRoot: string,
+  opts: { transitive?: boolean; dependents?: Map<string, ResolvedType[]> } = {},
+): DependencyNode {
+  const index = opts.dependents ?? reverseDependencyIndex(sources);
+  const seen = new Set<string>();
+
+  const build = (path: string, resolved: ResolvedType | null, depth: number): DependencyNode => {
+    const node: DependencyNode = {
+      typePath: path,
+      category: categoryFromPath(path),
+      resolved,
+      children: [],
+      deduped: seen.has(path),
+      installed: existsSync(join(installedRoot, path)),
+    };
+    if (node.deduped) return node;
+    seen.add(path);
+
+    if (depth === 0 || opts.transitive) {
+      for (const dependent of index.get(path) ?? []) {
+        node.children.push(build(dependent.typePath, dependent, depth + 1));
+      }
+    }
+    return node;
+  };
+
+  return build(typePath, resolveType(typePath, sources), 0);
+}
+
 // ── Install Plan ────────────────────────────────────────────────────
 
 function countByCategory(types: ResolvedType[]): Record<string, number> {
@@ -355,7 +777,8 @@ function countInstalled(node: DependencyNode): number {
   return count;
 }
 
-function checkCLIDeps(types: ResolvedType[]): CLIDepStatus[] {
+/** Checks PATH for the CLI dependencies declared by the given skills. */
+export function checkCLIDeps(types: ResolvedType[]): CLIDepStatus[] {
   const seen = new Set<string>();
   const results: CLIDepStatus[] = [];
 
@@ -423,20 +846,62 @@ export function buildInstallPlan(
 
 // ── Install / Remove ────────────────────────────────────────────────
 
+const UNVERSIONED = '0.0.0';
+
+function manifestVersion(manifestPath: string): string {
+  try {
+    const data = yaml.load(readFileSync(manifestPath, 'utf-8')) as BaseManifest;
+    return data.version ? String(data.version) : UNVERSIONED;
+  } catch {
+    return UNVERSIONED;
+  }
+}
+
+/**
+ * Stores the type's files in the content-addressable store and links
+ * that version into installedRoot. Returns the installed version.
+ */
 export function installType(
   resolved: ResolvedType,
   installedRoot: string,
-): void {
-  const dst = join(installedRoot, resolved.typePath);
-  if (existsSync(dst)) {
-    rmSync(dst, { recursive: true });
-  }
-  copyDirUtil(resolved.sourceDir, dst);
+): string {
+  const version = manifestVersion(resolved.manifestPath);
+  const snapshot = storeType(resolved.typePath, contentDir(resolved), version, sourceLabel(resolved));
+  materialize(snapshot, installedRoot);
+  log.info('installed', { type: resolved.typePath, version, source: snapshot.source });
+  return version;
+}
+
+/**
+ * Stores the type's files and builds its tree in a staging directory
+ * next to its destination, without touching the installed copy. changes
+ * compares the files with the installed version's. See
+ * core/transaction.ts.
+ */
+export function stageType(
+  resolved: ResolvedType,
+  installedRoot: string,
+): { snapshot: TypeSnapshot; staged: string; changes: FileChanges } {
+  const version = manifestVersion(resolved.manifestPath);
+  const before = currentSnapshot(resolved.typePath);
+  const snapshot = storeType(resolved.typePath, contentDir(resolved), version, sourceLabel(resolved));
+  const changes = diffFiles(before?.files ?? [], snapshot.files);
+  return { snapshot, staged: stageSnapshot(snapshot, installedRoot), changes };
 }
 
 export function installNodeDeps(typeDir: string): string | null {
   const pkgPath = join(typeDir, 'package.json');
   if (!existsSync(pkgPath)) return null;
+  // Shipped dependencies need neither npm nor the network
+  const vendored = vendoredArchive(typeDir);
+  if (vendored) {
+    unpackVendoredDeps(typeDir, vendored);
+    return null;
+  }
+  const cached = nodeCacheEnabled() && nodeCacheKey(typeDir) !== null;
+  // A cached entry needs no network, so it is used offline too
+  if (cached && linkCachedModules(typeDir)) return null;
+  if (isOffline()) return offlineSkip(`npm install in ${typeDir}`);
 
   try {
     execFileSync('which', ['node'], { stdio: 'ignore' });
@@ -450,8 +915,14 @@ export function installNodeDeps(typeDir: string): string | null {
     return 'npm not found — skipping npm install';
   }
 
+  if (cached) {
+    populateNodeCache(typeDir);
+    return null;
+  }
+  log.debug('npm install', { dir: typeDir });
   execFileSync('npm', ['install', '--prefer-offline'], {
     cwd: typeDir,
+    env: childEnv(),
     stdio: 'ignore',
   });
   return null;
@@ -463,9 +934,12 @@ export function removeType(
 ): void {
   const dir = join(installedRoot, typePath);
   if (!existsSync(dir)) {
-    throw new Error(`Type not found: ${typePath}`);
+    throw new AgentxError('AGX-REG-004', `Type not found: ${typePath}`);
   }
-  rmSync(dir, { recursive: true });
+  removeTree(dir);
+  removeTree(join(installedRoot, '.versions', typePath));
+  clearCurrent(typePath);
+  log.info('removed', { type: typePath });
 }
 
 // ── Skill Registry Init ─────────────────────────────────────────────
@@ -523,105 +997,208 @@ export function initSkillRegistry(
 
 // ── Cache ───────────────────────────────────────────────────────────
 
-interface CachedIndex {
-  types: DiscoveredType[];
-  sourceMods: Record<string, number>;
-  cachedAt: string;
-}
-
 export function defaultCachePath(): string {
-  return join(getHomeRoot(), 'registry-cache.json');
+  return join(getHomeRoot(), 'registry-index.db');
 }
 
-function latestMtime(basePath: string): number {
-  let latest = 0;
-  try {
-    latest = statSync(basePath).mtimeMs;
-  } catch {
-    return 0;
-  }
-
-  for (const cat of KNOWN_CATEGORIES) {
-    const catPath = join(basePath, cat);
+/** `size:mtime` of each manifest; '' for one removed mid-walk. */
+async function manifestStamps(found: ResolvedType[]): Promise<string[]> {
+  return mapConcurrent(found, DISCOVERY_CONCURRENCY, async (t) => {
     try {
-      const st = statSync(catPath);
-      if (st.mtimeMs > latest) latest = st.mtimeMs;
-      // One level deeper
-      for (const entry of readdirSync(catPath, { withFileTypes: true })) {
-        if (entry.isDirectory()) {
-          try {
-            const sub = statSync(join(catPath, entry.name));
-            if (sub.mtimeMs > latest) latest = sub.mtimeMs;
-          } catch {
-            // ignore
-          }
-        }
-      }
+      const st = await stat(t.manifestPath);
+      return `${st.size}:${st.mtimeMs}`;
     } catch {
-      // Category dir doesn't exist
+      return '';
     }
-  }
-  return latest;
+  });
+}
+
+/**
+ * Hash of the path, size, and mtime of every manifest found in a source.
+ * Directory mtimes miss in-place edits to a manifest; this does not, and
+ * still avoids reading manifest contents.
+ */
+function fingerprint(found: ResolvedType[], stamps: string[]): string {
+  const lines = found.map((t, i) => (stamps[i] ? `${t.manifestPath}:${stamps[i]}\n` : ''));
+  return createHash('sha256').update(lines.join('')).digest('hex');
 }
 
-function loadCache(path: string): CachedIndex | null {
+async function indexEntry(r: ResolvedType, stamp: string, warnings?: string[]): Promise<IndexEntry | null> {
   try {
-    const raw = readFileSync(path, 'utf-8');
-    return JSON.parse(raw) as CachedIndex;
-  } catch {
+    const raw = await readFile(r.manifestPath, 'utf-8');
+    const data = (yaml.load(raw) as Record<string, unknown>) ?? {};
+    const cli = Array.isArray(data.cli_dependencies) ? (data.cli_dependencies as { name?: unknown }[]) : [];
+    return {
+      type: toDiscovered(r, data as BaseManifest),
+      topic: classify(r.typePath, data.topic, 1),
+      vendor: classify(r.typePath, data.vendor, 2),
+      stamp,
+      dependencies: manifestDependencies(data),
+      cliDependencies: cli.map((d) => String(d?.name ?? '')).filter(Boolean),
+    };
+  } catch (err) {
+    warnings?.push(skippedManifest(r, err));
     return null;
   }
 }
 
-function isCacheValid(
-  cached: CachedIndex,
-  sources: Source[],
-): boolean {
-  if (Object.keys(cached.sourceMods).length !== sources.length) return false;
-  for (const source of sources) {
-    const cachedMtime = cached.sourceMods[source.name];
-    if (cachedMtime == null) return false;
-    if (Math.floor(latestMtime(source.basePath)) !== Math.floor(cachedMtime)) {
-      return false;
+/**
+ * Brings one source's rows up to date. Unless the source's fingerprint
+ * is unchanged, manifests whose stamp differs from the indexed one are
+ * parsed again; unparseable ones are reported to warnings and left out.
+ * A source with an unparseable manifest is stored without a fingerprint,
+ * so the next command parses that manifest again and reports it again
+ * until it is fixed.
+ */
+async function refreshSource(index: RegistryIndex, source: Source, warnings?: string[]): Promise<DiscoveredType[]> {
+  const found = await walkSourceAsync(source);
+  const stamps = await manifestStamps(found);
+  const fp = fingerprint(found, stamps);
+  const indexed = index.source(source.name);
+  if (indexed && indexed.basePath === source.basePath && indexed.fingerprint === fp) {
+    return index.types(source.name);
+  }
+
+  const previous = indexed?.basePath === source.basePath ? index.stamps(source.name) : new Map<string, string>();
+  const keep: string[] = [];
+  const changed: [ResolvedType, string][] = [];
+  found.forEach((t, i) => {
+    if (!stamps[i]) return;
+    if (previous.get(t.typePath) === stamps[i]) keep.push(t.typePath);
+    else changed.push([t, stamps[i]]);
+  });
+  log.debug('registry index update', { source: source.name, manifests: found.length, changed: changed.length });
+
+  const entries = await mapConcurrent(changed, DISCOVERY_CONCURRENCY, ([t, stamp]) => indexEntry(t, stamp, warnings));
+  const parsed = entries.filter((e): e is IndexEntry => e !== null);
+  index.updateSource(source, parsed.length === entries.length ? fp : '', keep, parsed);
+  return index.types(source.name);
+}
+
+/**
+ * Drops indexed sources whose directory no longer exists. Sources that
+ * exist but aren't in this command's list are kept: a project-local
+ * extension is only listed from inside its project.
+ */
+function pruneMissingSources(index: RegistryIndex): void {
+  for (const s of index.sources()) {
+    if (!existsSync(s.basePath)) {
+      log.debug('registry index prune', { source: s.name, basePath: s.basePath });
+      index.removeSource(s.name);
     }
   }
-  return true;
 }
 
-function writeCache(
-  path: string,
-  types: DiscoveredType[],
+/** Removes a source's rows from the registry index, if the index exists (after `extension remove`). */
+export function forgetIndexedSource(name: string, cachePath = defaultCachePath()): void {
+  if (!existsSync(cachePath)) return;
+  const index = RegistryIndex.open(cachePath);
+  try {
+    index.removeSource(name);
+  } finally {
+    index.close();
+  }
+}
+
+/**
+ * Runs fn on the registry index after bringing every source up to date,
+ * with the winning type for each path (as discoverAll picks it).
+ */
+async function withIndex<T>(
   sources: Source[],
-): void {
-  const sourceMods: Record<string, number> = {};
-  for (const source of sources) {
-    sourceMods[source.name] = latestMtime(source.basePath);
-  }
-  const index: CachedIndex = {
-    types,
-    sourceMods,
-    cachedAt: new Date().toISOString(),
+  cachePath: string | undefined,
+  warnings: string[] | undefined,
+  fn: (types: DiscoveredType[], index: RegistryIndex) => T,
+): Promise<T> {
+  const path = cachePath ?? defaultCachePath();
+  const run = async (index: RegistryIndex, prune: boolean) => {
+    // Collected per attempt, so a fallback doesn't report twice
+    const seen: string[] = [];
+    try {
+      if (prune) pruneMissingSources(index);
+      const all: DiscoveredType[] = [];
+      for (const source of sources) all.push(...(await refreshSource(index, source, seen)));
+      const result = fn(dedupe(all, sources), index);
+      warnings?.push(...seen);
+      return result;
+    } finally {
+      index.close();
+    }
   };
   try {
-    mkdirSync(join(path, '..'), { recursive: true });
-    writeFileSync(path, JSON.stringify(index, null, 2));
-  } catch {
-    // Best-effort cache write
+    return await run(RegistryIndex.open(path), true);
+  } catch (err) {
+    if (!isSqliteError(err)) throw err;
+    // Busy or unwritable: scan the sources into a throwaway index
+    log.warn('registry index unavailable; scanning sources', { path, error: err instanceof Error ? err.message : String(err) });
+    return run(RegistryIndex.open(':memory:'), false);
   }
 }
 
-export function discoverAllCached(
+/**
+ * discoverAll backed by the registry index. Only sources whose
+ * fingerprint changed are re-read; results keep source precedence.
+ * Unparseable manifests are reported to `warnings` when re-parsed.
+ */
+export async function discoverAllCached(
   sources: Source[],
   cachePath?: string,
-): DiscoveredType[] {
-  const path = cachePath ?? defaultCachePath();
-  const cached = loadCache(path);
-  if (cached && isCacheValid(cached, sources)) {
-    return cached.types;
-  }
-  const types = discoverAll(sources);
-  writeCache(path, types, sources);
-  return types;
+  warnings?: string[],
+): Promise<DiscoveredType[]> {
+  return withIndex(sources, cachePath, warnings, (types) => types);
+}
+
+/**
+ * Available types matching every filter given, answered from the
+ * registry index. Only the type that wins each path is considered, so a
+ * shadowed copy in a later source never matches in its place.
+ */
+export async function searchTypes(
+  sources: Source[],
+  filter: IndexQuery,
+  opts: { cachePath?: string; warnings?: string[] } = {},
+): Promise<DiscoveredType[]> {
+  return withIndex(sources, opts.cachePath, opts.warnings, (types, index) => {
+    const hits = new Set(index.query(filter).map((h) => `${h.sourceName}\0${h.typePath}`));
+    return types.filter((t) => hits.has(`${t.sourceName}\0${t.typePath}`));
+  });
+}
+
+/**
+ * Dependents of every type (type path -> types referencing it) from the
+ * registry index, for findDependents without re-reading manifests.
+ */
+export async function indexedDependents(
+  sources: Source[],
+  cachePath?: string,
+): Promise<Map<string, ResolvedType[]>> {
+  return withIndex(sources, cachePath, undefined, (types, index) => {
+    const winners = new Map(types.map((t) => [`${t.sourceName}\0${t.typePath}`, t]));
+    const dependents = new Map<string, ResolvedType[]>();
+    for (const ref of index.references()) {
+      const t = winners.get(`${ref.sourceName}\0${ref.typePath}`);
+      if (!t) continue;
+      const list = dependents.get(ref.dependency) ?? [];
+      list.push(t);
+      dependents.set(ref.dependency, list);
+    }
+    return dependents;
+  });
+}
+
+/** Rebuilds the registry index from scratch. */
+export async function refreshRegistryCache(
+  sources: Source[],
+  cachePath?: string,
+): Promise<DiscoveredType[]> {
+  clearRegistryCache(cachePath);
+  return discoverAllCached(sources, cachePath);
+}
+
+export function clearRegistryCache(cachePath?: string): void {
+  removeIndex(cachePath ?? defaultCachePath());
+  // The JSON cache earlier versions kept
+  rmSync(join(getHomeRoot(), 'registry-cache.json'), { force: true });
 }
 
 // ── Print Helpers ───────────────────────────────────────────────────
@@ -0,0 +1,206 @@
+import { relative, isAbsolute } from 'node:path';
+import { APP_NAME, GITHUB_REPO } from '../config/branding.js';
+import { currentVersion } from './updater.js';
+import { projectConfigPath } from './linker.js';
+import type { ManifestIssue } from './manifest.js';
+import type { SourceValidation, FileIssues } from './source-lint.js';
+import type { VerifyReport } from './verify.js';
+
+// ── CI reports ──────────────────────────────────────────────────────
+//
+// `verify` and `validate` results as JUnit XML or SARIF 2.1.0, for CI
+// dashboards and code scanning that already read those formats. Both go
+// through one model: suites of test cases, each passing, skipped, or
+// failing with located problems. A verify check or a validated manifest
+// file is a test case; each problem is a SARIF result under a rule named
+// for the check (`agentx/links`) or `agentx/manifest`. Code scanning
+// rejects a result without a location, so every problem names a file:
+// a verify problem about a type or source, rather than a file, is
+// placed on .agentx/project.yaml, where the project declares it.
+
+export const REPORT_FORMATS = ['text', 'json', 'junit', 'sarif'] as const;
+export type ReportFormat = (typeof REPORT_FORMATS)[number];
+
+export interface ReportProblem {
+  message: string;
+  /** Relative to the directory the report is for. */
+  file: string;
+  line?: number;
+  col?: number;
+  /** Reported without failing the case. */
+  warning?: boolean;
+}
+
+export interface ReportCase {
+  name: string;
+  /** SARIF rule id; also the JUnit failure type. */
+  rule: string;
+  problems: ReportProblem[];
+  /** Set when the case was skipped, with why. */
+  skipped?: string;
+}
+
+export interface ReportSuite {
+  name: string;
+  cases: ReportCase[];
+}
+
+export interface ReportRule {
+  id: string;
+  description: string;
+}
+
+export function parseReportFormat(format: string): ReportFormat {
+  if (!(REPORT_FORMATS as readonly string[]).includes(format)) {
+    throw new Error(`Unknown format "${format}" (use ${REPORT_FORMATS.join(', ')})`);
+  }
+  return format as ReportFormat;
+}
+
+// ── Adapters ────────────────────────────────────────────────────────
+
+export function verifySuites(report: VerifyReport): { suites: ReportSuite[]; rules: ReportRule[] } {
+  const projectFile = relative(report.project, projectConfigPath(report.project));
+  const cases = report.checks.map(
+    (c): ReportCase => ({
+      name: c.title,
+      rule: `${APP_NAME}/${c.name}`,
+      problems: c.failures.map((f) => ({
+        message: f.file ? f.message : `${f.subject}: ${f.message}`,
+        file: f.file ?? projectFile,
+        ...(f.line ? { line: f.line } : {}),
+      })),
+      ...(c.status === 'skip' ? { skipped: c.detail ?? 'skipped' } : {}),
+    }),
+  );
+  const rules = report.checks.map((c) => ({ id: `${APP_NAME}/${c.name}`, description: c.title }));
+  return { suites: [{ name: `${APP_NAME} verify`, cases }], rules };
+}
+
+const MANIFEST_RULE = `${APP_NAME}/manifest`;
+
+function manifestCase(root: string, f: FileIssues): ReportCase {
+  const file = isAbsolute(f.file) ? relative(root, f.file) : f.file;
+  return {
+    name: file,
+    rule: MANIFEST_RULE,
+    problems: f.issues.map((i: ManifestIssue) => ({
+      message: i.path ? `${i.message} (at ${i.path})` : i.message,
+      file,
+      line: i.line,
+      col: i.col,
+      ...(i.warning ? { warning: true } : {}),
+    })),
+  };
+}
+
+/** validate results: a suite per target, with a case per manifest file. */
+export function validationSuites(
+  results: (FileIssues | SourceValidation)[],
+  root: string,
+): { suites: ReportSuite[]; rules: ReportRule[] } {
+  const suites = results.map((r): ReportSuite => {
+    if ('file' in r) return { name: r.file, cases: [manifestCase(root, r)] };
+    const cases = r.results.map((f) => manifestCase(root, f));
+    // Files without issues aren't listed; one case stands for them
+    if (cases.length === 0) cases.push({ name: `${r.types} type(s)`, rule: MANIFEST_RULE, problems: [] });
+    return { name: r.source, cases };
+  });
+  return { suites, rules: [{ id: MANIFEST_RULE, description: 'Manifests match the type schema' }] };
+}
+
+// ── JUnit XML ───────────────────────────────────────────────────────
+
+function xmlEscape(s: string): string {
+  return s
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;');
+}
+
+function location(p: ReportProblem): string {
+  const at = p.line ? `${p.file}:${p.line}${p.col ? `:${p.col}` : ''}` : p.file;
+  return `${at}: ${p.message}`;
+}
+
+export function toJUnit(suites: ReportSuite[]): string {
+  const count = (cases: ReportCase[], pred: (c: ReportCase) => boolean) => cases.filter(pred).length;
+  const all = suites.flatMap((s) => s.cases);
+  const errors = (c: ReportCase) => c.problems.filter((p) => !p.warning);
+  const failed = (c: ReportCase) => errors(c).length > 0;
+  const skipped = (c: ReportCase) => c.skipped !== undefined;
+
+  const out = [
+    '<?xml version="1.0" encoding="UTF-8"?>',
+    `<testsuites name="${APP_NAME}" tests="${all.length}" failures="${count(all, failed)}" skipped="${count(all, skipped)}">`,
+  ];
+  for (const suite of suites) {
+    out.push(
+      `  <testsuite name="${xmlEscape(suite.name)}" tests="${suite.cases.length}" failures="${count(suite.cases, failed)}" skipped="${count(suite.cases, skipped)}">`,
+    );
+    for (const c of suite.cases) {
+      const open = `    <testcase classname="${xmlEscape(suite.name)}" name="${xmlEscape(c.name)}"`;
+      if (c.skipped !== undefined) {
+        out.push(`${open}>`, `      <skipped message="${xmlEscape(c.skipped)}"/>`, '    </testcase>');
+      } else if (failed(c)) {
+        const body = errors(c).map((p) => xmlEscape(location(p))).join('\n');
+        out.push(
+          `${open}>`,
+          `      <failure message="${errors(c).length} problem(s)" type="${xmlEscape(c.rule)}">${body}</failure>`,
+          '    </testcase>',
+        );
+      } else {
+        out.push(`${open}/>`);
+      }
+    }
+    out.push('  </testsuite>');
+  }
+  out.push('</testsuites>');
+  return out.join('\n') + '\n';
+}
+
+// ── SARIF ───────────────────────────────────────────────────────────
+
+export function toSarif(suites: ReportSuite[], rules: ReportRule[]): string {
+  const results = suites.flatMap((s) =>
+    s.cases.flatMap((c) =>
+      c.problems.map((p) => ({
+        ruleId: c.rule,
+        level: p.warning ? 'warning' : 'error',
+        message: { text: p.message },
+        locations: [
+          {
+            physicalLocation: {
+              artifactLocation: { uri: p.file.split('\\').join('/') },
+              ...(p.line ? { region: { startLine: p.line, ...(p.col ? { startColumn: p.col } : {}) } } : {}),
+            },
+          },
+        ],
+      })),
+    ),
+  );
+  const sarif = {
+    $schema: 'https://json.schemastore.org/sarif-2.1.0.json',
+    version: '2.1.0',
+    runs: [
+      {
+        tool: {
+          driver: {
+            name: APP_NAME,
+            version: currentVersion(),
+            informationUri: `https://github.com/${GITHUB_REPO}`,
+            rules: rules.map((r) => ({ id: r.id, shortDescription: { text: r.description } })),
+          },
+        },
+        results,
+      },
+    ],
+  };
+  return JSON.stringify(sarif, null, 2) + '\n';
+}
+
+/** Renders suites as junit or sarif. */
+export function renderReport(format: 'junit' | 'sarif', report: { suites: ReportSuite[]; rules: ReportRule[] }): string {
+  return format === 'junit' ? toJUnit(report.suites) : toSarif(report.suites, report.rules);
+}
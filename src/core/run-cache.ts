@@ -0,0 +1,259 @@
+import { join, resolve, relative, sep } from 'node:path';
+import { createHash } from 'node:crypto';
+import {
+  readFileSync,
+  writeFileSync,
+  existsSync,
+  statSync,
+  readdirSync,
+  mkdirSync,
+  rmSync,
+} from 'node:fs';
+import { getCacheDir } from './userdata.js';
+import type { RuntimeOutput } from './runtime.js';
+
+// ── Layout ──────────────────────────────────────────────────────────
+//
+// ~/.agentx/cache/runs/<workspace-id>/
+//   entries/<key>.json    run metadata pointing at output blobs
+//   blobs/<sha256>        stdout/stderr, deduplicated by content
+//   stats.json            hit/miss counters per skill
+
+const RUNS_DIR = 'runs';
+const ENTRIES_DIR = 'entries';
+const BLOBS_DIR = 'blobs';
+const STATS_FILE = 'stats.json';
+const SKIP_DIRS = new Set(['.git', 'node_modules', '.agentx']);
+
+export interface RunCacheKey {
+  skillPath: string;
+  version: string;
+  inputs: Record<string, string>;
+  /** Token account the run used; results differ per account. */
+  account?: string | null;
+  /**
+   * The environment the skill runs with (allowed host variables and its
+   * tokens), so a changed token or variable is a different entry. Only
+   * its hash is kept.
+   */
+  env?: Record<string, string>;
+}
+
+interface CacheEntry {
+  skillPath: string;
+  version: string;
+  stdout: string;
+  stderr: string;
+  exitCode: number;
+  createdAt: string;
+}
+
+interface SkillStats {
+  hits: number;
+  misses: number;
+}
+
+export interface RunCacheStats {
+  workspace: string;
+  entries: number;
+  blobs: number;
+  bytes: number;
+  hits: number;
+  misses: number;
+  hitRate: number;
+  skills: Record<string, SkillStats>;
+}
+
+// ── Workspace ───────────────────────────────────────────────────────
+
+export function workspaceCacheDir(workspaceRoot: string): string {
+  const id = createHash('sha256').update(resolve(workspaceRoot)).digest('hex').slice(0, 16);
+  return join(getCacheDir(), RUNS_DIR, id);
+}
+
+// ── Keys ────────────────────────────────────────────────────────────
+
+function hashDir(hash: ReturnType<typeof createHash>, root: string, dir: string): void {
+  let entries;
+  try {
+    entries = readdirSync(dir, { withFileTypes: true });
+  } catch {
+    return;
+  }
+  entries.sort((a, b) => a.name.localeCompare(b.name));
+  for (const entry of entries) {
+    if (SKIP_DIRS.has(entry.name)) continue;
+    const full = join(dir, entry.name);
+    if (entry.isDirectory()) {
+      hashDir(hash, root, full);
+    } else if (entry.isFile()) {
+      // Contents, not mtimes: a checkout or copy that keeps the bytes
+      // still hits, and an edit that keeps size and mtime still misses
+      const content = createHash('sha256').update(readFileSync(full)).digest('hex');
+      hash.update(`${relative(root, full).split(sep).join('/')}:${content}\n`);
+    }
+  }
+}
+
+/**
+ * Inputs that name an existing file or directory are keyed by what they
+ * point at, relative to the workspace, so `../shared/lib` from one
+ * project and `shared/lib` from the workspace root hit the same entry.
+ */
+function inputFingerprint(value: string, cwd: string, workspaceRoot: string): string {
+  const abs = resolve(cwd, value);
+  if (!value || !existsSync(abs)) return `v:${value}`;
+
+  const rel = relative(workspaceRoot, abs).split(sep).join('/');
+  const hash = createHash('sha256');
+  if (statSync(abs).isDirectory()) {
+    hashDir(hash, abs, abs);
+  } else {
+    hash.update(readFileSync(abs));
+  }
+  return `p:${rel}:${hash.digest('hex')}`;
+}
+
+export function runCacheKey(key: RunCacheKey, cwd: string, workspaceRoot: string): string {
+  const hash = createHash('sha256');
+  hash.update(`${key.skillPath}@${key.version}\n`);
+  if (key.account) hash.update(`account ${key.account}\n`);
+  for (const name of Object.keys(key.env ?? {}).sort()) {
+    hash.update(`env ${name}=${key.env![name]}\n`);
+  }
+  for (const name of Object.keys(key.inputs).sort()) {
+    hash.update(`${name}=${inputFingerprint(key.inputs[name], cwd, workspaceRoot)}\n`);
+  }
+  return hash.digest('hex');
+}
+
+// ── Blobs ───────────────────────────────────────────────────────────
+
+function writeBlob(dir: string, content: string): string {
+  const sha = createHash('sha256').update(content).digest('hex');
+  const path = join(dir, BLOBS_DIR, sha);
+  if (!existsSync(path)) {
+    mkdirSync(join(dir, BLOBS_DIR), { recursive: true });
+    writeFileSync(path, content);
+  }
+  return sha;
+}
+
+function readBlob(dir: string, sha: string): string {
+  return readFileSync(join(dir, BLOBS_DIR, sha), 'utf-8');
+}
+
+// ── Stats ───────────────────────────────────────────────────────────
+
+function loadStats(dir: string): Record<string, SkillStats> {
+  try {
+    return JSON.parse(readFileSync(join(dir, STATS_FILE), 'utf-8')) as Record<string, SkillStats>;
+  } catch {
+    return {};
+  }
+}
+
+function bumpStats(dir: string, skillPath: string, hit: boolean): void {
+  const stats = loadStats(dir);
+  const s = stats[skillPath] ?? { hits: 0, misses: 0 };
+  if (hit) s.hits++;
+  else s.misses++;
+  stats[skillPath] = s;
+  try {
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, STATS_FILE), JSON.stringify(stats, null, 2));
+  } catch {
+    // Stats are advisory
+  }
+}
+
+// ── Lookup / store ──────────────────────────────────────────────────
+
+export function lookupRun(
+  workspaceRoot: string,
+  key: RunCacheKey,
+  cwd = process.cwd(),
+): RuntimeOutput | null {
+  const dir = workspaceCacheDir(workspaceRoot);
+  const id = runCacheKey(key, cwd, workspaceRoot);
+  try {
+    const entry = JSON.parse(
+      readFileSync(join(dir, ENTRIES_DIR, `${id}.json`), 'utf-8'),
+    ) as CacheEntry;
+    const output = {
+      exitCode: entry.exitCode,
+      stdout: readBlob(dir, entry.stdout),
+      stderr: readBlob(dir, entry.stderr),
+    };
+    bumpStats(dir, key.skillPath, true);
+    return output;
+  } catch {
+    bumpStats(dir, key.skillPath, false);
+    return null;
+  }
+}
+
+/** Stores a successful run. Failed runs are never cached. */
+export function storeRun(
+  workspaceRoot: string,
+  key: RunCacheKey,
+  output: RuntimeOutput,
+  cwd = process.cwd(),
+): void {
+  if (output.exitCode !== 0) return;
+  const dir = workspaceCacheDir(workspaceRoot);
+  const id = runCacheKey(key, cwd, workspaceRoot);
+  const entry: CacheEntry = {
+    skillPath: key.skillPath,
+    version: key.version,
+    stdout: writeBlob(dir, output.stdout),
+    stderr: writeBlob(dir, output.stderr),
+    exitCode: output.exitCode,
+    createdAt: new Date().toISOString(),
+  };
+  mkdirSync(join(dir, ENTRIES_DIR), { recursive: true });
+  writeFileSync(join(dir, ENTRIES_DIR, `${id}.json`), JSON.stringify(entry, null, 2));
+}
+
+function countFiles(dir: string): { count: number; bytes: number } {
+  let count = 0;
+  let bytes = 0;
+  try {
+    for (const name of readdirSync(dir)) {
+      count++;
+      bytes += statSync(join(dir, name)).size;
+    }
+  } catch {
+    // Missing dir
+  }
+  return { count, bytes };
+}
+
+export function runCacheStats(workspaceRoot: string): RunCacheStats {
+  const dir = workspaceCacheDir(workspaceRoot);
+  const entries = countFiles(join(dir, ENTRIES_DIR));
+  const blobs = countFiles(join(dir, BLOBS_DIR));
+  const skills = loadStats(dir);
+
+  let hits = 0;
+  let misses = 0;
+  for (const s of Object.values(skills)) {
+    hits += s.hits;
+    misses += s.misses;
+  }
+
+  return {
+    workspace: resolve(workspaceRoot),
+    entries: entries.count,
+    blobs: blobs.count,
+    bytes: entries.bytes + blobs.bytes,
+    hits,
+    misses,
+    hitRate: hits + misses === 0 ? 0 : hits / (hits + misses),
+    skills,
+  };
+}
+
+export function clearRunCache(workspaceRoot: string): void {
+  rmSync(workspaceCacheDir(workspaceRoot), { recursive: true, force: true });
+}
@@ -1,68 +1,380 @@
 import { spawn } from 'node:child_process';
-import { join } from 'node:path';
-import { readFileSync, existsSync } from 'node:fs';
-import type { SkillManifest } from '../types/manifest.js';
-import { getSkillRegistryPath, getUserdataRoot } from './userdata.js';
+import { join, dirname } from 'node:path';
+import { readFileSync, writeFileSync, readdirSync, existsSync, mkdirSync, chmodSync } from 'node:fs';
+import type { SkillManifest, RegistryToken } from '../types/manifest.js';
+import { getSkillRegistryPath, getUserdataRoot, checkAccountName } from './userdata.js';
 import { parseEnvFile } from '../utils/env-parser.js';
 import { envVar } from '../config/branding.js';
 import { nameFromPath } from './registry.js';
+import { outputStamp, recordOutput, historyRetention } from './output-history.js';
+import { noteSkill } from './telemetry.js';
+import { logger } from '../utils/log.js';
+import { parseSize, parseDuration } from '../utils/units.js';
+import { addSecret, isSensitiveKey } from '../utils/redact.js';
+import * as settings from '../config/settings.js';
+import { AgentxError } from './errors.js';
+import { checkOutput, outputSchemaMode } from './output-schema.js';
+
+const log = logger('runtime');
 
 export interface RuntimeOutput {
   exitCode: number;
   stdout: string;
   stderr: string;
+  /** Where the output breaks outputs.schema, when it was checked and does. */
+  outputErrors?: string[];
+}
+
+export interface RunSkillOptions {
+  /** Receives output as the skill writes it, e.g. to stream it to a client. */
+  onOutput?: OutputListener;
 }
 
+export type OutputListener = (stream: 'stdout' | 'stderr', chunk: string) => void;
+
+/** env is added to the skill's environment, over tokens.env. */
 export async function runSkill(
   skillPath: string,
   manifest: SkillManifest,
   args: Record<string, string>,
+  env: Record<string, string> = {},
+  opts: RunSkillOptions = {},
 ): Promise<RuntimeOutput> {
+  const registryPath = skillRegistryPath(skillPath);
+  const before = outputStamp(registryPath);
+  const started = Date.now();
+  noteSkill(skillPath);
+  registerSecrets(registryPath, manifest, args, env);
+  log.debug('run', { skill: skillPath, runtime: manifest.runtime, inputs: Object.keys(args) });
+  const out = await dispatch(skillPath, manifest, args, env, opts.onOutput);
+  log.info('ran', { skill: manifest.name, exitCode: out.exitCode, ms: Date.now() - started });
+  const archived = recordOutput(registryPath, before, historyRetention(manifest));
+  if (archived) log.debug('output archived', { path: archived });
+  return enforceOutputSchema(skillPath, manifest, out);
+}
+
+/** Exit code reported when run.output_schema is fail and the output breaks outputs.schema. */
+export const OUTPUT_SCHEMA_EXIT_CODE = 65;
+
+/**
+ * Checks a successful run's output against outputs.schema. The
+ * violations are noted on stderr; with run.output_schema fail, the run
+ * also fails.
+ */
+function enforceOutputSchema(skillPath: string, manifest: SkillManifest, out: RuntimeOutput): RuntimeOutput {
+  if (out.exitCode !== 0 || manifest.outputs?.schema === undefined) return out;
+  const mode = outputSchemaMode();
+  if (mode === 'off') return out;
+  const violations = checkOutput(skillPath, manifest.outputs, out.stdout);
+  if (violations.length === 0) return out;
+
+  log.warn('output breaks schema', { skill: manifest.name, violations: violations.length, mode });
+  const heading = mode === 'fail' ? 'AGX-RUN-004: Output does not match' : 'Warning: output does not match';
+  const report = `\n${heading} outputs.schema:\n${violations.map((v) => `  ${v}\n`).join('')}`;
+  return {
+    ...out,
+    exitCode: mode === 'fail' ? OUTPUT_SCHEMA_EXIT_CODE : out.exitCode,
+    stderr: out.stderr + report,
+    outputErrors: violations,
+  };
+}
+
+async function dispatch(
+  skillPath: string,
+  manifest: SkillManifest,
+  args: Record<string, string>,
+  env: Record<string, string>,
+  onOutput?: OutputListener,
+): Promise<RuntimeOutput> {
+  return spawnSkill(skillCommand(skillPath, manifest, args, env), manifest, onOutput);
+}
+
+/** A skill's process: the limited command line and its isolated environment. */
+export interface SkillCommand {
+  command: string;
+  argv: string[];
+  env: Record<string, string>;
+}
+
+/** The variable a secret input is passed in: AGENTX_INPUT_<NAME>. */
+export function secretInputVar(name: string): string {
+  return envVar(`INPUT_${name.toUpperCase().replace(/[^A-Z0-9]/g, '_')}`);
+}
+
+/**
+ * How to start a skill with args, without starting it. Daemon skills are
+ * started this way by the supervisor (core/daemon.ts). Secret inputs
+ * are left off the command line, where any user can read them (ps), and
+ * passed in the environment instead.
+ */
+export function skillCommand(
+  skillPath: string,
+  manifest: SkillManifest,
+  allArgs: Record<string, string>,
+  env: Record<string, string> = {},
+): SkillCommand {
+  const secret = new Set((manifest.inputs ?? []).filter((f) => f.secret).map((f) => f.name));
+  const args: Record<string, string> = {};
+  const secretEnv: Record<string, string> = {};
+  for (const [name, value] of Object.entries(allArgs)) {
+    if (secret.has(name)) secretEnv[secretInputVar(name)] = value;
+    else args[name] = value;
+  }
+
+  let line: { command: string; argv: string[] };
   switch (manifest.runtime) {
     case 'node':
-      return runNodeSkill(skillPath, manifest, args);
+      line = nodeCommand(skillPath, manifest, args);
+      break;
+    case 'python':
+      line = pythonCommand(skillPath, args);
+      break;
     case 'go':
-      throw new Error('Go runtime is not yet supported');
+      throw new AgentxError('AGX-RUN-001', 'Go runtime is not yet supported');
     default:
-      throw new Error(`Unknown runtime: ${manifest.runtime}`);
+      throw new AgentxError('AGX-RUN-002', `Unknown runtime: ${manifest.runtime}`);
   }
+  const limited = withLimits(line.command, line.argv, manifest);
+  return { ...limited, env: isolatedEnv(manifest, { ...buildSkillEnv(skillPath, manifest), ...env, ...secretEnv }) };
 }
 
-async function runNodeSkill(
+function nodeCommand(
   skillPath: string,
   manifest: SkillManifest,
   args: Record<string, string>,
-): Promise<RuntimeOutput> {
+): { command: string; argv: string[] } {
   const entryPoint = join(skillPath, 'index.mjs');
   if (!existsSync(entryPoint)) {
-    throw new Error(`Skill entry point not found: ${entryPoint}`);
+    throw new AgentxError('AGX-RUN-003', `Skill entry point not found: ${entryPoint}`);
   }
 
-  const env = buildNodeEnv(skillPath, manifest);
+  // The V8 heap cap works everywhere, unlike ulimit
+  const memory = manifest.limits?.memory;
+  const nodeArgs = memory ? [`--max-old-space-size=${Math.max(16, Math.floor(parseSize(memory) / 1024 ** 2))}`] : [];
+  return { command: 'node', argv: [...nodeArgs, entryPoint, 'run', JSON.stringify(args)] };
+}
+
+function pythonCommand(skillPath: string, args: Record<string, string>): { command: string; argv: string[] } {
+  const entryPoint = join(skillPath, 'main.py');
+  if (!existsSync(entryPoint)) {
+    throw new AgentxError('AGX-RUN-003', `Skill entry point not found: ${entryPoint}`);
+  }
+
+  // Prefer the skill's own virtualenv (created by `make build`)
+  const venvPython = process.platform === 'win32'
+    ? join(skillPath, '.venv', 'Scripts', 'python.exe')
+    : join(skillPath, '.venv', 'bin', 'python');
+  const python = existsSync(venvPython) ? venvPython : process.platform === 'win32' ? 'python' : 'python3';
+  return { command: python, argv: [entryPoint, 'run', JSON.stringify(args)] };
+}
+
+/** Exit code reported when a skill is killed for exceeding limits.timeout. */
+export const TIMEOUT_EXIT_CODE = 124;
 
+/** limits.timeout in ms, or 0 when the skill may run as long as it likes. */
+export function skillTimeoutMs(manifest: SkillManifest): number {
+  return manifest.limits?.timeout ? parseDuration(manifest.limits.timeout) : 0;
+}
+
+function spawnSkill(
+  proc: SkillCommand,
+  manifest: SkillManifest,
+  onOutput?: OutputListener,
+): Promise<RuntimeOutput> {
+  const timeoutMs = skillTimeoutMs(manifest);
   return new Promise((resolve, reject) => {
-    const child = spawn('node', [entryPoint, 'run', JSON.stringify(args)], {
-      env: { ...process.env, ...env },
+    const child = spawn(proc.command, proc.argv, {
+      env: proc.env,
       stdio: ['pipe', 'pipe', 'pipe'],
     });
 
     let stdout = '';
     let stderr = '';
+    let timedOut = false;
+    const timer = timeoutMs
+      ? setTimeout(() => {
+          timedOut = true;
+          child.kill('SIGKILL');
+        }, timeoutMs)
+      : undefined;
     child.stdout.on('data', (data: Buffer) => {
       stdout += data.toString();
+      onOutput?.('stdout', data.toString());
     });
     child.stderr.on('data', (data: Buffer) => {
       stderr += data.toString();
+      onOutput?.('stderr', data.toString());
     });
 
-    child.on('error', reject);
+    child.on('error', (err) => {
+      clearTimeout(timer);
+      reject(err);
+    });
     child.on('close', (code) => {
+      clearTimeout(timer);
+      if (timedOut) {
+        stderr += `\nKilled: exceeded limits.timeout (${manifest.limits?.timeout})\n`;
+        resolve({ exitCode: TIMEOUT_EXIT_CODE, stdout, stderr });
+        return;
+      }
       resolve({ exitCode: code ?? 1, stdout, stderr });
     });
   });
 }
 
-function buildNodeEnv(
+// ── Isolation ───────────────────────────────────────────────────────
+//
+// Skills don't inherit the whole environment: a stray AWS_SECRET_KEY in
+// the user's shell is not theirs to read. They get the variables
+// runtimes and common tools need (HOST_ENV_ALLOWLIST), their declared
+// tokens, host_env from the manifest, and run.env_allow from settings.
+// run.env_isolation: inherit restores full inheritance.
+
+export const HOST_ENV_ALLOWLIST = [
+  'PATH', 'HOME', 'USER', 'LOGNAME', 'SHELL', 'TERM', 'COLORTERM', 'NO_COLOR',
+  'LANG', 'LANGUAGE', 'TZ', 'TMPDIR', 'TMP', 'TEMP',
+  'HTTP_PROXY', 'HTTPS_PROXY', 'NO_PROXY', 'http_proxy', 'https_proxy', 'no_proxy',
+  'NODE_EXTRA_CA_CERTS', 'SSL_CERT_FILE', 'SSL_CERT_DIR', 'REQUESTS_CA_BUNDLE',
+  // Windows needs these to start most programs
+  'SYSTEMROOT', 'SystemRoot', 'WINDIR', 'COMSPEC', 'PATHEXT', 'USERPROFILE',
+  'APPDATA', 'LOCALAPPDATA', 'HOMEDRIVE', 'HOMEPATH', 'PROGRAMDATA', 'PROGRAMFILES',
+];
+
+/** Variables that tell the skill and common tools to stay offline. */
+const NO_NETWORK_ENV: Record<string, string> = {
+  [envVar('NO_NETWORK')]: '1',
+  // Port 9 (discard) on loopback: requests through a proxy fail at once
+  HTTP_PROXY: 'http://127.0.0.1:9',
+  HTTPS_PROXY: 'http://127.0.0.1:9',
+  http_proxy: 'http://127.0.0.1:9',
+  https_proxy: 'http://127.0.0.1:9',
+  NO_PROXY: '',
+  no_proxy: '',
+  npm_config_offline: 'true',
+  PIP_NO_INDEX: '1',
+  GIT_TERMINAL_PROMPT: '0',
+};
+
+/**
+ * The host variables a child process of a catalog or extension sees:
+ * all of them under run.env_isolation: inherit, else HOST_ENV_ALLOWLIST,
+ * LC_*, run.env_allow, and extra.
+ */
+export function hostEnv(extra: string[] = [], host: NodeJS.ProcessEnv = process.env): Record<string, string> {
+  const env: Record<string, string> = {};
+  const inherit = settings.get('run.env_isolation') === 'inherit';
+  const allowed = new Set([...HOST_ENV_ALLOWLIST, ...settings.getList('run.env_allow'), ...extra]);
+  for (const [k, v] of Object.entries(host)) {
+    if (v !== undefined && (inherit || allowed.has(k) || k.startsWith('LC_'))) env[k] = v;
+  }
+  return env;
+}
+
+/**
+ * The environment a skill runs with: allowed host variables, then
+ * skillEnv (AGENTX_* paths, tokens, caller extras), then the no-network
+ * hints when the manifest sets network: false.
+ */
+export function isolatedEnv(
+  manifest: SkillManifest,
+  skillEnv: Record<string, string>,
+  host: NodeJS.ProcessEnv = process.env,
+): Record<string, string> {
+  const declared = [...(manifest.registry?.tokens ?? []).map((t) => t.name), ...(manifest.host_env ?? [])];
+  const env = hostEnv(declared, host);
+  Object.assign(env, skillEnv);
+  if (manifest.network === false) Object.assign(env, NO_NETWORK_ENV);
+  return env;
+}
+
+/**
+ * Wraps command in a shell that sets limits.cpu, and limits.memory for
+ * non-Node skills, with ulimit where the OS supports it; otherwise
+ * returns it as is.
+ */
+export function withLimits(
+  command: string,
+  argv: string[],
+  manifest: SkillManifest,
+  platform: NodeJS.Platform = process.platform,
+): { command: string; argv: string[] } {
+  const { memory, cpu } = manifest.limits ?? {};
+  const ulimits: string[] = [];
+  if (cpu && platform !== 'win32') {
+    ulimits.push(`ulimit -t ${Math.max(1, Math.ceil(parseDuration(cpu) / 1000))}`);
+  }
+  // macOS ignores address-space limits, and V8 reserves far more address
+  // space than it uses, so Node skills get a heap cap instead
+  if (memory && platform === 'linux' && manifest.runtime !== 'node') {
+    ulimits.push(`ulimit -v ${Math.ceil(parseSize(memory) / 1024)}`);
+  }
+  if ((cpu || memory) && platform === 'win32') {
+    log.warn('limits.memory and limits.cpu are not supported on Windows; running without them', { skill: manifest.name });
+  }
+  if (ulimits.length === 0) return { command, argv };
+  return { command: '/bin/sh', argv: ['-c', `${ulimits.join(' && ')} && exec "$0" "$@"`, command, ...argv] };
+}
+
+/** Type paths of skill directories run from a source, by directory. */
+const sourceSkillDirs = new Map<string, string>();
+
+/**
+ * Lets skills run from their source directories (`run --dev`) use their
+ * type's registry, as the installed copy does. dirs maps type paths to
+ * directories.
+ */
+export function mapSkillDirs(dirs: Record<string, string>): void {
+  for (const [typePath, dir] of Object.entries(dirs)) sourceSkillDirs.set(dir, typePath);
+}
+
+function skillRegistryPath(skillPath: string): string {
+  const source = sourceSkillDirs.get(skillPath);
+  if (source) return getSkillRegistryPath(nameFromPath(source));
+  const rel = skillPath.includes('/installed/') ? skillPath.split('/installed/')[1] : skillPath;
+  // Side-by-side versions (.versions/<type-path>/<version>) share the type's registry
+  const versioned = /^\.versions\/(.+)\/[^/]+$/.exec(rel);
+  return getSkillRegistryPath(nameFromPath(versioned ? versioned[1] : rel));
+}
+
+function readTokenFile(path: string): Record<string, string> {
+  if (!existsSync(path)) return {};
+  const tokens: Record<string, string> = {};
+  for (const entry of parseEnvFile(readFileSync(path, 'utf-8'))) {
+    if (entry.value) tokens[entry.key] = entry.value;
+  }
+  return tokens;
+}
+
+/** The skill's saved tokens: tokens.env, overlaid by the active account's file. */
+function readTokens(registryPath: string): Record<string, string> {
+  return Object.assign({}, ...tokenFiles(registryPath).reverse().map(readTokenFile));
+}
+
+/**
+ * Registers the run's secret values with utils/redact.ts, so logs and
+ * reports mask them: tokens (all saved ones, plus declared tokens taken
+ * from the environment) and inputs marked sensitive or secret.
+ */
+function registerSecrets(
+  registryPath: string,
+  manifest: SkillManifest,
+  args: Record<string, string>,
+  env: Record<string, string>,
+): void {
+  for (const value of Object.values(readTokens(registryPath))) addSecret(value);
+  for (const token of manifest.registry?.tokens ?? []) {
+    if (token.sensitive || isSensitiveKey(token.name)) addSecret(process.env[token.name] ?? env[token.name]);
+  }
+  for (const input of manifest.inputs ?? []) {
+    if (input.sensitive || input.secret) addSecret(args[input.name]);
+  }
+  for (const [key, value] of Object.entries(env)) {
+    if (isSensitiveKey(key)) addSecret(value);
+  }
+}
+
+/** AGENTX_* paths and saved tokens for a run, before isolatedEnv. */
+export function buildSkillEnv(
   skillPath: string,
   manifest: SkillManifest,
 ): Record<string, string> {
@@ -71,24 +383,104 @@ function buildNodeEnv(
   env[envVar('USERDATA')] = getUserdataRoot();
   env[envVar('SKILL_PATH')] = skillPath;
 
-  const registryName = nameFromPath(
-    skillPath.includes('/installed/')
-      ? skillPath.split('/installed/')[1]
-      : skillPath,
-  );
-  const registryPath = getSkillRegistryPath(registryName);
+  const registryPath = skillRegistryPath(skillPath);
   env[envVar('SKILL_REGISTRY')] = registryPath;
 
-  // Load tokens.env
-  const tokensPath = join(registryPath, 'tokens.env');
-  if (existsSync(tokensPath)) {
-    const content = readFileSync(tokensPath, 'utf-8');
-    for (const entry of parseEnvFile(content)) {
-      if (entry.value) {
-        env[entry.key] = entry.value;
-      }
-    }
-  }
+  // Load tokens.env and the active account's tokens.<account>.env
+  Object.assign(env, readTokens(registryPath));
+  // Skills that load their token files themselves read the account's too
+  const account = activeAccount();
+  if (account) env[envVar('ACCOUNT')] = account;
 
   return env;
 }
+
+// ── Tokens ──────────────────────────────────────────────────────────
+//
+// A skill's tokens live in its registry's tokens.env. Named accounts sit
+// beside it as tokens.<account>.env (tokens.work.env, tokens.client-a.env);
+// with an account active, its file is read over tokens.env, so it only
+// needs the tokens that differ. The account is `run --account`, else
+// AGENTX_ACCOUNT, else the tokens.account setting. Account names are
+// lowercase letters, digits, ".", "_", and "-", so they can't reach
+// outside the registry.
+
+const ACCOUNT_FILE = /^tokens\.([a-z0-9._-]+)\.env$/;
+
+let selectedAccount: string | null = null;
+
+/** Sets the account for this process, over AGENTX_ACCOUNT and tokens.account. */
+export function selectAccount(name: string | null): void {
+  selectedAccount = name === null ? null : checkAccountName(name);
+}
+
+export function activeAccount(): string | null {
+  const name = selectedAccount ?? (process.env[envVar('ACCOUNT')] || settings.get('tokens.account') || null);
+  return name === null ? null : checkAccountName(name);
+}
+
+/** Accounts the skill has token files for. */
+export function accountsFor(skillPath: string): string[] {
+  const registryPath = skillRegistryPath(skillPath);
+  if (!existsSync(registryPath)) return [];
+  return readdirSync(registryPath)
+    .map((f) => ACCOUNT_FILE.exec(f)?.[1])
+    .filter((a): a is string => Boolean(a))
+    .sort();
+}
+
+/** Token files in precedence order: the account's file, if it exists, then tokens.env. */
+export function tokenFiles(registryPath: string, account = activeAccount()): string[] {
+  const files = [join(registryPath, 'tokens.env')];
+  const accountFile = account ? join(registryPath, `tokens.${checkAccountName(account)}.env`) : null;
+  if (accountFile && existsSync(accountFile)) files.unshift(accountFile);
+  return files;
+}
+
+/**
+ * Where a token's value comes from for the skill: the file that sets it
+ * (the account's or tokens.env), else "environment". Null when unset.
+ */
+export function resolveTokenValue(skillPath: string, name: string): { value: string; from: string } | null {
+  for (const file of tokenFiles(skillRegistryPath(skillPath))) {
+    const value = readTokenFile(file)[name];
+    if (value) return { value, from: file };
+  }
+  const value = process.env[name];
+  return value ? { value, from: 'environment' } : null;
+}
+
+/**
+ * Required tokens (registry.tokens) with no default that are set neither
+ * in the environment nor in the skill's token files.
+ */
+export function missingTokens(skillPath: string, manifest: SkillManifest): RegistryToken[] {
+  const saved = readTokens(skillRegistryPath(skillPath));
+  return (manifest.registry?.tokens ?? []).filter(
+    (t) => t.required && !t.default && !process.env[t.name] && !saved[t.name],
+  );
+}
+
+/** The skill's tokens.env, or tokens.<account>.env for an account. */
+export function tokensPathFor(skillPath: string, account?: string | null): string {
+  return join(skillRegistryPath(skillPath), account ? `tokens.${checkAccountName(account)}.env` : 'tokens.env');
+}
+
+/** Sets a token in the skill's (or account's) token file, readable only by the owner. */
+export function saveToken(skillPath: string, name: string, value: string, account?: string | null): string {
+  const path = tokensPathFor(skillPath, account);
+  const lines = existsSync(path) ? readFileSync(path, 'utf-8').split('\n') : [];
+  const at = lines.findIndex((l) => l.trim().startsWith(`${name}=`));
+  if (at >= 0) {
+    lines[at] = `${name}=${value}`;
+  } else {
+    if (lines.length > 0 && lines[lines.length - 1] === '') lines.pop();
+    lines.push(`${name}=${value}`, '');
+  }
+
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, lines.join('\n'), { mode: 0o600 });
+  // mode only applies when the file is created
+  chmodSync(path, 0o600);
+  return path;
+}
@@ -0,0 +1,126 @@
+import { join, dirname, relative, sep } from 'node:path';
+import { tmpdir } from 'node:os';
+import { readFileSync, readdirSync, existsSync, mkdirSync, mkdtempSync, rmSync, cpSync } from 'node:fs';
+import type { SkillManifest } from '../types/manifest.js';
+import { envVar } from '../config/branding.js';
+import { tokensPathFor } from './runtime.js';
+
+// ── Sandboxed runs ──────────────────────────────────────────────────
+//
+// `agentx run --sandbox` points a skill at a throwaway userdata holding
+// a copy of its registry (tokens, config, state), so whatever it writes
+// to output/ and state/ is discarded afterward instead of landing in the
+// real registry. It is not a security boundary: the skill still runs as
+// the user, with their network and filesystem. What it can do there is
+// surfaced up front by a scan of its sources.
+
+export interface Sandbox {
+  userdata: string;
+  /** The real registry directory and its sandboxed copy. */
+  realRegistry: string;
+  registry: string;
+  previousUserdata: string | undefined;
+}
+
+export type SandboxChangeKind = 'added' | 'modified' | 'deleted';
+
+export interface SandboxChange {
+  path: string;
+  kind: SandboxChangeKind;
+}
+
+const SCAN_SKIP = new Set(['node_modules', '.venv', '__pycache__', '.git', 'dist']);
+const SOURCE_EXT = /\.(m?js|cjs|ts|py|go)$/;
+
+// What a line looks like when it reaches for the network
+const NETWORK_PATTERNS: [RegExp, string][] = [
+  [/\bfetch\s*\(/, 'fetch()'],
+  [/(?:from\s+|require\s*\(\s*)['"](?:node:)?(https?|http2|net|dgram|tls)['"]/, 'node:$1'],
+  [/(?:from\s+|require\s*\(\s*)['"](axios|node-fetch|undici|got|octokit|@octokit\/[\w-]+)['"]/, '$1'],
+  [/^\s*(?:import|from)\s+(requests|urllib\d?|httpx|aiohttp|socket|http\.client)\b/, '$1'],
+  [/"(net\/http|net)"/, '$1'],
+];
+
+function userdataVar(): string {
+  return envVar('USERDATA');
+}
+
+function listFiles(dir: string, skip = new Set<string>()): string[] {
+  const out: string[] = [];
+  const walk = (d: string) => {
+    for (const entry of readdirSync(d, { withFileTypes: true })) {
+      if (skip.has(entry.name)) continue;
+      const full = join(d, entry.name);
+      if (entry.isDirectory()) walk(full);
+      else if (entry.isFile()) out.push(relative(dir, full).split(sep).join('/'));
+    }
+  };
+  if (existsSync(dir)) walk(dir);
+  return out.sort();
+}
+
+/**
+ * Places in a skill's sources that may reach the network, as
+ * "file:line: what", plus the CLIs it declares it runs.
+ */
+export function networkHints(skillDir: string, manifest: SkillManifest): string[] {
+  const hints: string[] = [];
+  for (const file of listFiles(skillDir, SCAN_SKIP)) {
+    if (!SOURCE_EXT.test(file)) continue;
+    const lines = readFileSync(join(skillDir, file), 'utf-8').split('\n');
+    lines.forEach((line, i) => {
+      for (const [pattern, label] of NETWORK_PATTERNS) {
+        const m = pattern.exec(line);
+        if (m) {
+          hints.push(`${file}:${i + 1}: ${label.replace('$1', m[1] ?? '')}`);
+          break;
+        }
+      }
+    });
+  }
+  for (const dep of manifest.cli_dependencies ?? []) {
+    hints.push(`runs ${dep.name}`);
+  }
+  return hints;
+}
+
+/** Redirects userdata to a temporary copy of the skill's registry. */
+export function openSandbox(skillDir: string): Sandbox {
+  const realRegistry = dirname(tokensPathFor(skillDir));
+  const previousUserdata = process.env[userdataVar()];
+  const userdata = mkdtempSync(join(tmpdir(), 'agentx-sandbox-'));
+
+  process.env[userdataVar()] = userdata;
+  const registry = dirname(tokensPathFor(skillDir));
+  if (existsSync(realRegistry)) {
+    cpSync(realRegistry, registry, { recursive: true });
+  } else {
+    mkdirSync(registry, { recursive: true });
+  }
+  return { userdata, realRegistry, registry, previousUserdata };
+}
+
+/** What the run changed in the sandboxed registry, relative to the real one. */
+export function sandboxChanges(sandbox: Sandbox): SandboxChange[] {
+  const before = new Set(listFiles(sandbox.realRegistry));
+  const after = listFiles(sandbox.registry);
+  const changes: SandboxChange[] = [];
+
+  for (const path of after) {
+    if (!before.has(path)) {
+      changes.push({ path, kind: 'added' });
+    } else if (!readFileSync(join(sandbox.registry, path)).equals(readFileSync(join(sandbox.realRegistry, path)))) {
+      changes.push({ path, kind: 'modified' });
+    }
+    before.delete(path);
+  }
+  for (const path of before) changes.push({ path, kind: 'deleted' });
+  return changes.sort((a, b) => a.path.localeCompare(b.path));
+}
+
+/** Restores the real userdata and discards the sandbox. */
+export function closeSandbox(sandbox: Sandbox): void {
+  if (sandbox.previousUserdata === undefined) delete process.env[userdataVar()];
+  else process.env[userdataVar()] = sandbox.previousUserdata;
+  rmSync(sandbox.userdata, { recursive: true, force: true });
+}
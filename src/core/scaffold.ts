@@ -1,13 +1,26 @@
-import { join, dirname } from 'node:path';
+import { join, dirname, relative, sep } from 'node:path';
 import {
   readFileSync,
   writeFileSync,
   readdirSync,
   mkdirSync,
   existsSync,
-  statSync,
 } from 'node:fs';
 import { fileURLToPath } from 'node:url';
+import { spawnSync } from 'node:child_process';
+import { parseDocument } from 'yaml';
+import yaml from 'js-yaml';
+import { ScaffoldSetSchema } from '../config/schema.js';
+import type { TemplateVariable } from '../types/manifest.js';
+import { getScaffoldTemplatesDir } from './userdata.js';
+import { envVar } from '../config/branding.js';
+import { hostEnv } from './runtime.js';
+import { hasGit, findRepoRoot } from '../utils/git.js';
+import { buildSources, listContributions } from './extension.js';
+import { isTrusted, type Contribution } from './trust.js';
+import { validateManifest, formatIssue } from './manifest.js';
+import { nameFromPath } from './registry.js';
+import type { ResolvedType } from '../types/registry.js';
 
 export interface ScaffoldData {
   name: string;
@@ -25,6 +38,8 @@ export interface ScaffoldResult {
   outputDir: string;
   files: string[];
   warnings: string[];
+  /** Post-generation steps that ran, in order. */
+  steps: string[];
 }
 
 export function newScaffoldData(
@@ -66,60 +81,483 @@ function getScaffoldsDir(): string {
   const projectRoot = join(dirname(thisFile), '..');
   const candidate = join(projectRoot, 'src', 'scaffolds');
   if (existsSync(candidate)) return candidate;
+  // Running from source (tests, tsx): src/core/ → src/scaffolds/
+  const fromSource = join(projectRoot, 'scaffolds');
+  if (existsSync(fromSource)) return fromSource;
   throw new Error('Scaffolds directory not found');
 }
 
-function renderTemplate(
-  template: string,
-  data: ScaffoldData,
-): string {
-  let result = template;
-  for (const [key, value] of Object.entries(data)) {
-    const pattern = new RegExp(`\\{\\{\\s*\\.${key.charAt(0).toUpperCase() + key.slice(1)}\\s*\\}\\}`, 'g');
-    result = result.replace(pattern, String(value));
+// ── Template sets ───────────────────────────────────────────────────
+//
+// Built-in sets ship in src/scaffolds/<set>/. Users add their own under
+// ~/.agentx/templates/<set>/, with a scaffold.yaml declaring what the
+// set creates and any extra variables its files use:
+//
+//   name: corp-node
+//   type: skill
+//   runtime: node
+//   variables:
+//     - name: team
+//       required: true
+//   hooks:
+//     - npx prettier --write .
+//
+// Hooks run in the generated directory, and only with --run-hooks.
+// Extensions contribute sets too (kind: scaffold in extension.yaml,
+// with a scaffold.yaml in the set's directory); such a set must be
+// approved in the trust store before it generates anything. A user
+// set takes precedence over an extension's, and both over a built-in.
+
+const SET_MANIFEST = 'scaffold.yaml';
+
+export interface TemplateSet {
+  name: string;
+  type: string;
+  description: string;
+  runtime?: string;
+  variables: TemplateVariable[];
+  hooks: string[];
+  dir: string;
+  builtin: boolean;
+  /** The extension capability the set comes from. */
+  contribution?: Contribution;
+}
+
+function builtinSet(name: string, dir: string): TemplateSet {
+  const [type, runtime] = name.split('-');
+  return { name, type, description: '', runtime, variables: [], hooks: [], dir, builtin: true };
+}
+
+function userSet(name: string, dir: string): TemplateSet {
+  const manifestPath = join(dir, SET_MANIFEST);
+  const parsed = ScaffoldSetSchema.safeParse(yaml.load(readFileSync(manifestPath, 'utf-8')));
+  if (!parsed.success) {
+    const issue = parsed.error.issues[0];
+    throw new Error(`Invalid ${manifestPath}: ${issue.path.join('.')}: ${issue.message}`);
   }
-  return result;
+  const m = parsed.data;
+  return {
+    name,
+    type: m.type,
+    description: m.description ?? '',
+    runtime: m.runtime,
+    variables: m.variables ?? [],
+    hooks: m.hooks ?? [],
+    dir,
+    builtin: false,
+  };
+}
+
+function extensionSet(c: Contribution): TemplateSet {
+  const dir = join(c.baseDir, c.path ?? '');
+  if (!existsSync(join(dir, SET_MANIFEST))) {
+    throw new Error(`Template set ${c.name} from extension ${c.extension} has no ${SET_MANIFEST} in ${dir}`);
+  }
+  return { ...userSet(c.name, dir), contribution: c };
+}
+
+/** Template sets contributed by the extensions of the current project. */
+function extensionContributions(): Contribution[] {
+  return listContributions(buildSources(findRepoRoot() ?? process.cwd()), 'scaffold');
+}
+
+/** Loads a template set by name: the user's, else an extension's, else a built-in. */
+export function loadTemplateSet(name: string): TemplateSet {
+  const userDir = join(getScaffoldTemplatesDir(), name);
+  if (existsSync(join(userDir, SET_MANIFEST))) return userSet(name, userDir);
+  const contributed = extensionContributions().find((c) => c.name === name);
+  if (contributed) return extensionSet(contributed);
+  const builtinDir = join(getScaffoldsDir(), name);
+  if (existsSync(builtinDir)) return builtinSet(name, builtinDir);
+  throw new Error(`Template set not found: ${name}`);
+}
+
+/** All template sets, user sets first, then extensions'. Unreadable ones are reported in warnings. */
+export function listTemplateSets(warnings?: string[]): TemplateSet[] {
+  const sets: TemplateSet[] = [];
+  const userRoot = getScaffoldTemplatesDir();
+  for (const name of existsSync(userRoot) ? readdirSync(userRoot).sort() : []) {
+    if (!existsSync(join(userRoot, name, SET_MANIFEST))) continue;
+    try {
+      sets.push(userSet(name, join(userRoot, name)));
+    } catch (err) {
+      warnings?.push(err instanceof Error ? err.message : String(err));
+    }
+  }
+  for (const c of extensionContributions()) {
+    if (sets.some((s) => s.name === c.name)) continue;
+    try {
+      sets.push(extensionSet(c));
+    } catch (err) {
+      warnings?.push(err instanceof Error ? err.message : String(err));
+    }
+  }
+  const taken = new Set(sets.map((s) => s.name));
+  for (const name of readdirSync(getScaffoldsDir()).sort()) {
+    if (!taken.has(name)) sets.push(builtinSet(name, join(getScaffoldsDir(), name)));
+  }
+  return sets;
+}
+
+/**
+ * Values for a set's declared variables: given ones, then defaults.
+ * Missing required and undeclared variables are errors.
+ */
+export function resolveVariables(set: TemplateSet, given: Record<string, string>): Record<string, string> {
+  const declared = new Set(set.variables.map((v) => v.name));
+  const unknown = Object.keys(given).filter((k) => !declared.has(k));
+  if (unknown.length > 0) {
+    throw new Error(`Template set ${set.name} has no variable(s): ${unknown.join(', ')}`);
+  }
+
+  const values: Record<string, string> = {};
+  const missing: string[] = [];
+  for (const v of set.variables) {
+    const value = given[v.name] ?? v.default;
+    if (value !== undefined) values[v.name] = value;
+    else if (v.required) missing.push(v.name);
+  }
+  if (missing.length > 0) {
+    throw new Error(`Template set ${set.name} requires: ${missing.map((m) => `--var ${m}=...`).join(' ')}`);
+  }
+  return values;
+}
+
+// ── Rendering ───────────────────────────────────────────────────────
+
+type TemplateVars = Record<string, unknown>;
+
+// {{.Name}} looks up "Name", then "name"
+function lookup(vars: TemplateVars, field: string): unknown {
+  return field in vars ? vars[field] : vars[field.charAt(0).toLowerCase() + field.slice(1)];
+}
+
+// Go-template conditionals: {{if .Field}}…{{else}}…{{end}}, where a
+// leading or trailing dash ({{- … -}}) trims whitespace on that side.
+const DIRECTIVE = /\{\{(-?)\s*(?:if\s+\.(\w+)|(else)|(end))\s*(-?)\}\}/g;
+const FIELD = /\{\{\s*\.(\w+)\s*\}\}/g;
+
+function renderConditionals(template: string, vars: TemplateVars): string {
+  const stack: { cond: boolean; inElse: boolean }[] = [];
+  const emitting = () => stack.every((f) => f.cond !== f.inElse);
+  let out = '';
+  let last = 0;
+  let trimNext = false;
+
+  const emit = (text: string, trimEnd: boolean) => {
+    if (trimNext) text = text.trimStart();
+    if (trimEnd) text = text.trimEnd();
+    if (emitting()) out += text;
+  };
+
+  for (const m of template.matchAll(DIRECTIVE)) {
+    const [whole, trimBefore, field, isElse, isEnd, trimAfter] = m;
+    emit(template.slice(last, m.index), trimBefore === '-');
+    if (field) {
+      stack.push({ cond: Boolean(lookup(vars, field)), inElse: false });
+    } else if (isElse && stack.length > 0) {
+      stack[stack.length - 1].inElse = true;
+    } else if (isEnd && stack.length > 0) {
+      stack.pop();
+    } else {
+      throw new Error(`Unexpected {{${isElse ?? 'end'}}} in scaffold template`);
+    }
+    trimNext = trimAfter === '-';
+    last = m.index! + whole.length;
+  }
+  if (stack.length > 0) throw new Error('Unclosed {{if}} in scaffold template');
+  emit(template.slice(last), false);
+  return out;
+}
+
+function renderTemplate(template: string, vars: TemplateVars): string {
+  // Unknown fields are left as written
+  return renderConditionals(template, vars).replace(FIELD, (whole, field: string) => {
+    const value = lookup(vars, field);
+    return value === undefined ? whole : String(value);
+  });
+}
+
+function listSetFiles(dir: string, prefix = ''): string[] {
+  const files: string[] = [];
+  for (const entry of readdirSync(join(dir, prefix), { withFileTypes: true })) {
+    const rel = prefix ? `${prefix}/${entry.name}` : entry.name;
+    if (entry.isDirectory()) files.push(...listSetFiles(dir, rel));
+    else if (entry.isFile() && rel !== SET_MANIFEST) files.push(rel);
+  }
+  return files.sort();
+}
+
+export interface PostGenerateOptions {
+  /** Install dependencies: npm install, go mod tidy, or a Python venv. */
+  installDeps?: boolean;
+  /** Run the template set's hooks. They never run otherwise. */
+  runHooks?: boolean;
+  /** Create a git repository with an initial commit. */
+  gitInit?: boolean;
+}
+
+export interface GenerateOptions extends PostGenerateOptions {
+  /** Template set to use instead of the type's built-in one. */
+  template?: string;
+  /** Values for the set's declared variables. */
+  variables?: Record<string, string>;
 }
 
 export function generate(
   typeName: string,
   data: ScaffoldData,
   outputDir: string,
+  opts: GenerateOptions = {},
 ): ScaffoldResult {
-  const setName = templateSetName(typeName, data.runtime);
-  const scaffoldsDir = getScaffoldsDir();
-  const templateDir = join(scaffoldsDir, setName);
-
-  if (!existsSync(templateDir)) {
-    throw new Error(`Template set not found: ${setName}`);
+  const set = loadTemplateSet(opts.template ?? templateSetName(typeName, data.runtime));
+  if (set.contribution && !isTrusted(set.contribution)) {
+    throw new Error(
+      `Template set ${set.name} from extension ${set.contribution.extension} is not trusted. ` +
+        'Run the command interactively to review and approve it.',
+    );
   }
-
-  // Prevent overwriting non-empty directories
-  if (existsSync(outputDir)) {
-    const entries = readdirSync(outputDir);
-    if (entries.length > 0) {
-      throw new Error(`Output directory is not empty: ${outputDir}`);
-    }
+  if (set.type !== typeName) {
+    throw new Error(`Template set ${set.name} creates a ${set.type}, not a ${typeName}`);
   }
+  const vars: TemplateVars = { ...resolveVariables(set, opts.variables ?? {}), ...data };
 
-  mkdirSync(outputDir, { recursive: true });
+  // Prevent overwriting non-empty directories
+  ensureEmptyDir(outputDir);
   const files: string[] = [];
 
-  for (const entry of readdirSync(templateDir)) {
-    const srcPath = join(templateDir, entry);
-    if (!statSync(srcPath).isFile()) continue;
-
+  for (const rel of listSetFiles(set.dir)) {
     // Strip .tmpl extension
-    const outName = entry.endsWith('.tmpl') ? entry.slice(0, -5) : entry;
-    const content = readFileSync(srcPath, 'utf-8');
+    const outName = rel.endsWith('.tmpl') ? rel.slice(0, -5) : rel;
+    const content = readFileSync(join(set.dir, rel), 'utf-8');
 
     // .hbs files are copied verbatim, others are rendered
-    const rendered = entry.endsWith('.hbs.tmpl') ? content : renderTemplate(content, data);
+    const rendered = rel.endsWith('.hbs.tmpl') ? content : renderTemplate(content, vars);
 
     const outPath = join(outputDir, outName);
+    mkdirSync(dirname(outPath), { recursive: true });
     writeFileSync(outPath, rendered, 'utf-8');
     files.push(outName);
   }
 
-  return { outputDir, files, warnings: [] };
+  // A template that renders an invalid manifest is a bug worth surfacing
+  const manifestName = [manifestFileName(typeName), 'manifest.yaml'].find((f) => files.includes(f));
+  const warnings = manifestName ? manifestWarnings(join(outputDir, manifestName)) : [];
+
+  const result: ScaffoldResult = { outputDir, files, warnings, steps: [] };
+  postGenerate(result, set.hooks, opts);
+  return result;
+}
+
+// ── Post-generation ─────────────────────────────────────────────────
+//
+// Steps run in the output directory after the files are written:
+// dependencies, then template hooks, then git (so the initial commit
+// includes lockfiles and anything hooks produced). A failing step is
+// reported as a warning; the generated files stay. Template hooks come
+// from template sets, possibly an extension's, so like skills they only
+// see the host variables run.env_isolation allows.
+
+const GITIGNORE = ['node_modules/', '.venv/', '__pycache__/', '*.egg-info/', 'dist/', 'state/', 'output/', ''].join('\n');
+
+function runStep(
+  result: ScaffoldResult,
+  label: string,
+  command: string,
+  args: string[],
+  shell = false,
+  env: NodeJS.ProcessEnv = process.env,
+): boolean {
+  const res = spawnSync(command, args, {
+    cwd: result.outputDir,
+    shell,
+    encoding: 'utf-8',
+    stdio: ['ignore', 'pipe', 'pipe'],
+    env: { ...env, [envVar('SCAFFOLD_DIR')]: result.outputDir },
+  });
+  if (res.error || res.status !== 0) {
+    const detail = res.error?.message ?? (res.stderr.trim().split('\n').pop() || `exit ${res.status}`);
+    result.warnings.push(`${label} failed: ${detail}`);
+    return false;
+  }
+  result.steps.push(label);
+  return true;
+}
+
+function installDeps(result: ScaffoldResult): void {
+  const has = (f: string) => existsSync(join(result.outputDir, f));
+  if (has('package.json')) runStep(result, 'npm install', 'npm', ['install'], process.platform === 'win32');
+  if (has('go.mod')) runStep(result, 'go mod tidy', 'go', ['mod', 'tidy']);
+  if (has('pyproject.toml')) {
+    const python = process.platform === 'win32' ? 'python' : 'python3';
+    const pip = process.platform === 'win32' ? join('.venv', 'Scripts', 'pip') : join('.venv', 'bin', 'pip');
+    if (runStep(result, 'python venv', python, ['-m', 'venv', '.venv'])) {
+      runStep(result, 'pip install', pip, ['install', '--quiet', '.']);
+    }
+  }
+}
+
+function gitInit(result: ScaffoldResult): void {
+  if (!hasGit()) {
+    result.warnings.push('git init skipped: git was not found on PATH');
+    return;
+  }
+  const gitignore = join(result.outputDir, '.gitignore');
+  if (!existsSync(gitignore)) writeFileSync(gitignore, GITIGNORE);
+  if (!runStep(result, 'git init', 'git', ['init', '--quiet'])) return;
+  if (!runStep(result, 'git add', 'git', ['add', '-A'])) return;
+  runStep(result, 'initial commit', 'git', ['commit', '--quiet', '-m', 'Initial scaffold']);
+}
+
+function postGenerate(result: ScaffoldResult, hooks: string[], opts: PostGenerateOptions): void {
+  if (opts.installDeps) installDeps(result);
+  if (hooks.length > 0) {
+    if (opts.runHooks) {
+      for (const hook of hooks) runStep(result, hook, hook, [], true, hostEnv());
+    } else {
+      result.warnings.push(`Template hooks not run (pass --run-hooks to run them): ${hooks.join('; ')}`);
+    }
+  }
+  if (opts.gitInit) gitInit(result);
+}
+
+function manifestWarnings(path: string): string[] {
+  return validateManifest(readFileSync(path, 'utf-8'), path).map(formatIssue);
+}
+
+function ensureEmptyDir(outputDir: string): void {
+  if (existsSync(outputDir) && readdirSync(outputDir).length > 0) {
+    throw new Error(`Output directory is not empty: ${outputDir}`);
+  }
+  mkdirSync(outputDir, { recursive: true });
+}
+
+// ── Cloning ─────────────────────────────────────────────────────────
+//
+// `create <type> <name> --from <type-path>` starts from an existing type
+// instead of a template. Files are copied as-is except for identity:
+// the manifest gets the new name, topic, and vendor at version 0.1.0,
+// and sources have their SKILL_* constants and registry path rewritten.
+
+// Build output, dependencies, and runtime state never belong in a clone
+const CLONE_SKIP = new Set(['node_modules', '.git', '.venv', '__pycache__', 'dist', 'state', 'output']);
+
+// Manifest keys describing the original's place in a catalog
+const CLONE_DROP_KEYS = ['aliases', 'deprecated', 'replaced_by', 'author'];
+
+const TEXT_EXTENSIONS = /\.(m?[jt]s|cjs|py|go|json|toml|ya?ml|md|hbs|txt|sh)$|(^|\/)Makefile$/;
+
+function copyTree(src: string, dst: string, out: string[], root = src): void {
+  for (const entry of readdirSync(src, { withFileTypes: true })) {
+    if (CLONE_SKIP.has(entry.name)) continue;
+    const from = join(src, entry.name);
+    const to = join(dst, entry.name);
+    if (entry.isDirectory()) {
+      mkdirSync(to, { recursive: true });
+      copyTree(from, to, out, root);
+    } else if (entry.isFile()) {
+      writeFileSync(to, readFileSync(from));
+      out.push(relative(root, from).split(sep).join('/'));
+    }
+  }
+}
+
+function rewriteManifest(path: string, data: ScaffoldData, typeName: string): void {
+  const doc = parseDocument(readFileSync(path, 'utf-8'));
+  doc.set('name', data.name);
+  doc.set('version', data.version);
+  for (const key of CLONE_DROP_KEYS) doc.delete(key);
+  if (typeName === 'skill') {
+    doc.set('topic', data.topic);
+    if (data.vendor) doc.set('vendor', data.vendor);
+    else doc.delete('vendor');
+  }
+  writeFileSync(path, doc.toString());
+}
+
+interface Identity {
+  name: string;
+  topic: string;
+  vendor: string;
+  registryPath: string;
+}
+
+function rewriteSource(content: string, from: Identity, data: ScaffoldData): string {
+  const constants: Record<string, [string, string]> = {
+    NAME: [from.name, data.name],
+    TOPIC: [from.topic, data.topic],
+    VENDOR: [from.vendor, data.vendor],
+  };
+  let out = content;
+  for (const [key, [oldValue, newValue]] of Object.entries(constants)) {
+    // SKILL_NAME = 'x' (JS), SKILL_NAME = "x" (Python), SkillName = "x" (Go)
+    const pattern = new RegExp(`(SKILL_${key}|Skill${key.charAt(0)}${key.slice(1).toLowerCase()})(\\s*:?=\\s*)(['"\`])${escapeRegExp(oldValue)}\\3`, 'g');
+    out = out.replace(pattern, `$1$2$3${newValue}$3`);
+  }
+  if (from.registryPath && from.registryPath !== data.skillPath) {
+    out = out.split(from.registryPath).join(data.skillPath);
+  }
+  return out;
+}
+
+function escapeRegExp(s: string): string {
+  return s.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+function rewritePackageFiles(outputDir: string, data: ScaffoldData): void {
+  const pkgPath = join(outputDir, 'package.json');
+  if (existsSync(pkgPath)) {
+    const pkg = JSON.parse(readFileSync(pkgPath, 'utf-8')) as Record<string, unknown>;
+    pkg.name = data.packageName;
+    pkg.version = data.version;
+    writeFileSync(pkgPath, `${JSON.stringify(pkg, null, 2)}\n`);
+  }
+  const pyprojectPath = join(outputDir, 'pyproject.toml');
+  if (existsSync(pyprojectPath)) {
+    const pyproject = readFileSync(pyprojectPath, 'utf-8')
+      .replace(/^name = ".*"$/m, `name = "agentx-skill-${data.topic}-${data.name}"`)
+      .replace(/^version = ".*"$/m, `version = "${data.version}"`);
+    writeFileSync(pyprojectPath, pyproject);
+  }
+}
+
+/** Creates a new type by copying an existing one and renaming it. */
+export function cloneType(
+  typeName: string,
+  source: ResolvedType,
+  data: ScaffoldData,
+  outputDir: string,
+  opts: PostGenerateOptions = {},
+): ScaffoldResult {
+  if (source.category !== typeName) {
+    throw new Error(`${source.typePath} is a ${source.category}, not a ${typeName}`);
+  }
+  ensureEmptyDir(outputDir);
+
+  const original = parseDocument(readFileSync(source.manifestPath, 'utf-8')).toJS() as Record<string, unknown>;
+  const from: Identity = {
+    name: String(original.name ?? ''),
+    topic: String(original.topic ?? ''),
+    vendor: String(original.vendor ?? ''),
+    registryPath: typeName === 'skill' ? nameFromPath(source.typePath) : '',
+  };
+
+  const files: string[] = [];
+  copyTree(source.sourceDir, outputDir, files);
+
+  const manifestName = relative(source.sourceDir, source.manifestPath);
+  rewriteManifest(join(outputDir, manifestName), data, typeName);
+  for (const file of files) {
+    if (file === manifestName || !TEXT_EXTENSIONS.test(file)) continue;
+    const path = join(outputDir, file);
+    const content = readFileSync(path, 'utf-8');
+    const rewritten = rewriteSource(content, from, data);
+    if (rewritten !== content) writeFileSync(path, rewritten);
+  }
+  rewritePackageFiles(outputDir, data);
+
+  const result: ScaffoldResult = { outputDir, files, warnings: manifestWarnings(join(outputDir, manifestName)), steps: [] };
+  // A clone has no template set, so no hooks
+  postGenerate(result, [], opts);
+  return result;
 }
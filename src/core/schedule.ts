@@ -0,0 +1,320 @@
+import { join, basename } from 'node:path';
+import { homedir } from 'node:os';
+import { randomBytes } from 'node:crypto';
+import { readFileSync, writeFileSync, appendFileSync, mkdirSync, existsSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { getUserdataRoot } from './userdata.js';
+import { executeType, findManifest } from './executor.js';
+import { acquireLock, isLocked } from './lock.js';
+import { parseCron, nextRun } from '../utils/cron.js';
+import { APP_NAME, envVar } from '../config/branding.js';
+import { secretInputVar } from './runtime.js';
+import { maskInputs } from '../utils/input-parser.js';
+import { MASK } from '../utils/redact.js';
+import { logger } from '../utils/log.js';
+import type { InputField } from '../types/manifest.js';
+
+const log = logger('schedule');
+
+// ── Schedules ───────────────────────────────────────────────────────
+//
+// `agentx schedule add <type-path> --cron "0 9 * * 1-5"` runs a skill or
+// workflow on a cron schedule. Everything lives under userdata:
+//
+//   schedules/schedules.yaml   the schedules
+//   schedules/history.jsonl    one line per scheduled run, newest last
+//   schedules/tick.json        when schedules were last checked
+//
+// A tick runs every schedule that came due since the previous tick, once
+// even if it came due several times (say, while the machine slept). Ticks
+// come from `agentx scheduler run` in the foreground, or from an OS entry
+// (systemd timer, launchd agent, Task Scheduler task) that runs
+// `agentx scheduler tick` every minute. Runs go through the same
+// non-interactive executor as `serve http`, so skill output history is
+// recorded as usual.
+//
+// schedules.yaml never holds secrets: secret inputs are saved masked, and
+// a tick takes their values from AGENTX_INPUT_<NAME> in its environment.
+
+const SCHEDULES_DIR = 'schedules';
+const SCHEDULES_FILE = 'schedules.yaml';
+const HISTORY_FILE = 'history.jsonl';
+const TICK_FILE = 'tick.json';
+const HISTORY_LIMIT = 500;
+const STDERR_TAIL = 2000;
+const TICK_MS = 60_000;
+
+export interface Schedule {
+  id: string;
+  typePath: string;
+  cron: string;
+  inputs: Record<string, string>;
+  createdAt: string;
+}
+
+export interface ScheduledRun {
+  id: string;
+  typePath: string;
+  /** When the schedule came due. */
+  dueAt: string;
+  startedAt: string;
+  finishedAt: string;
+  exitCode?: number;
+  /** Why the run could not start, or the end of stderr when it failed. */
+  error?: string;
+}
+
+export function schedulesDir(): string {
+  return join(getUserdataRoot(), SCHEDULES_DIR);
+}
+
+// ── Store ───────────────────────────────────────────────────────────
+
+export function loadSchedules(): Schedule[] {
+  try {
+    const data = yaml.load(readFileSync(join(schedulesDir(), SCHEDULES_FILE), 'utf-8')) as { schedules?: Schedule[] } | undefined;
+    return data?.schedules ?? [];
+  } catch {
+    return [];
+  }
+}
+
+function saveSchedules(schedules: Schedule[]): void {
+  mkdirSync(schedulesDir(), { recursive: true });
+  const masked = schedules.map((s) => ({ ...s, inputs: maskInputs(s.inputs) }));
+  writeFileSync(join(schedulesDir(), SCHEDULES_FILE), yaml.dump({ schedules: masked }, { lineWidth: -1 }));
+}
+
+/** A schedule's inputs with masked secrets taken from AGENTX_INPUT_<NAME>, or left out. */
+function runInputs(schedule: Schedule): Record<string, string> {
+  const inputs: Record<string, string> = {};
+  for (const [name, value] of Object.entries(schedule.inputs)) {
+    if (value !== MASK) inputs[name] = value;
+    else if (process.env[secretInputVar(name)] !== undefined) inputs[name] = process.env[secretInputVar(name)]!;
+  }
+  return inputs;
+}
+
+export interface AddScheduleOptions {
+  /** Schedule id; defaults to the type's name and a random suffix. */
+  id?: string;
+  inputs?: Record<string, string>;
+}
+
+/** Validates and stores a schedule for an installed skill or workflow. */
+export function addSchedule(typePath: string, cron: string, installedRoot: string, opts: AddScheduleOptions = {}): Schedule {
+  parseCron(cron);
+  const dir = join(installedRoot, typePath);
+  const manifestPath = existsSync(dir) ? findManifest(dir) : null;
+  if (!manifestPath) throw new Error(`Type not installed: ${typePath}`);
+  const manifest = yaml.load(readFileSync(manifestPath, 'utf-8')) as { type?: string; mode?: string; inputs?: InputField[] };
+  if (manifest.type !== 'skill' && manifest.type !== 'workflow') {
+    throw new Error(`Cannot schedule ${typePath}: only skills and workflows are runnable`);
+  }
+  if (manifest.mode === 'daemon') {
+    throw new Error(`${typePath} is a daemon skill; start it with \`${APP_NAME} daemon start\` instead`);
+  }
+
+  const schedules = loadSchedules();
+  const id = opts.id ?? `${basename(typePath)}-${randomBytes(2).toString('hex')}`;
+  if (!/^[a-z0-9][a-z0-9._-]*$/i.test(id)) throw new Error(`Invalid schedule id "${id}"`);
+  if (schedules.some((s) => s.id === id)) throw new Error(`A schedule named ${id} already exists`);
+
+  const inputs = maskInputs(opts.inputs ?? {}, manifest.inputs);
+  const schedule: Schedule = { id, typePath, cron, inputs, createdAt: new Date().toISOString() };
+  saveSchedules([...schedules, schedule]);
+  return schedule;
+}
+
+export function removeSchedule(id: string): boolean {
+  const schedules = loadSchedules();
+  const kept = schedules.filter((s) => s.id !== id);
+  if (kept.length === schedules.length) return false;
+  saveSchedules(kept);
+  return true;
+}
+
+// ── History ─────────────────────────────────────────────────────────
+
+export function readRunHistory(id?: string): ScheduledRun[] {
+  let raw: string;
+  try {
+    raw = readFileSync(join(schedulesDir(), HISTORY_FILE), 'utf-8');
+  } catch {
+    return [];
+  }
+  const runs: ScheduledRun[] = [];
+  for (const line of raw.split('\n')) {
+    if (!line.trim()) continue;
+    try {
+      const run = JSON.parse(line) as ScheduledRun;
+      if (!id || run.id === id) runs.push(run);
+    } catch {
+      // A line cut short by a crash
+    }
+  }
+  return runs;
+}
+
+function recordRun(run: ScheduledRun): void {
+  const path = join(schedulesDir(), HISTORY_FILE);
+  appendFileSync(path, JSON.stringify(run) + '\n');
+  const runs = readRunHistory();
+  if (runs.length > HISTORY_LIMIT) {
+    writeFileSync(path, runs.slice(-HISTORY_LIMIT).map((r) => JSON.stringify(r)).join('\n') + '\n');
+  }
+}
+
+// ── Ticks ───────────────────────────────────────────────────────────
+
+function readLastTick(): Date | null {
+  try {
+    const { lastTick } = JSON.parse(readFileSync(join(schedulesDir(), TICK_FILE), 'utf-8')) as { lastTick: string };
+    return new Date(lastTick);
+  } catch {
+    return null;
+  }
+}
+
+/** Schedules due in (since, now], with the time each came due. */
+export function dueSchedules(schedules: Schedule[], since: Date, now: Date): { schedule: Schedule; dueAt: Date }[] {
+  const due: { schedule: Schedule; dueAt: Date }[] = [];
+  for (const schedule of schedules) {
+    try {
+      const at = nextRun(schedule.cron, since);
+      if (at && at <= now) due.push({ schedule, dueAt: at });
+    } catch (err) {
+      log.warn('invalid schedule', { id: schedule.id, error: String(err) });
+    }
+  }
+  return due;
+}
+
+/**
+ * Runs every schedule due since the last tick and records the results.
+ * Returns null, running nothing, when another tick is still busy; the
+ * schedules it misses run on the next tick.
+ */
+export async function tick(installedRoot: string, now = new Date()): Promise<ScheduledRun[] | null> {
+  const dir = schedulesDir();
+  if (isLocked(dir)) return null;
+  let release: () => void;
+  try {
+    release = await acquireLock(dir, { command: 'scheduler tick', timeoutMs: 0 });
+  } catch {
+    return null;
+  }
+
+  try {
+    const since = readLastTick() ?? new Date(now.getTime() - TICK_MS);
+    const due = dueSchedules(loadSchedules(), since, now);
+    // Recorded first, so a crash mid-run doesn't repeat the runs
+    writeFileSync(join(dir, TICK_FILE), JSON.stringify({ lastTick: now.toISOString() }));
+
+    const runs: ScheduledRun[] = [];
+    for (const { schedule, dueAt } of due) {
+      const startedAt = new Date().toISOString();
+      const run: ScheduledRun = { id: schedule.id, typePath: schedule.typePath, dueAt: dueAt.toISOString(), startedAt, finishedAt: '' };
+      try {
+        const result = await executeType(schedule.typePath, runInputs(schedule), installedRoot);
+        run.exitCode = result.exitCode;
+        if (result.exitCode !== 0 && result.stderr) run.error = result.stderr.slice(-STDERR_TAIL);
+      } catch (err) {
+        run.error = err instanceof Error ? err.message : String(err);
+      }
+      run.finishedAt = new Date().toISOString();
+      log.info('scheduled run', { id: run.id, type: run.typePath, exitCode: run.exitCode, error: run.error });
+      recordRun(run);
+      runs.push(run);
+    }
+    return runs;
+  } finally {
+    release();
+  }
+}
+
+// ── OS entries ──────────────────────────────────────────────────────
+
+export type SchedulerPlatform = 'systemd' | 'launchd' | 'windows';
+
+export interface SchedulerEntry {
+  platform: SchedulerPlatform;
+  /** Files to write; none for Task Scheduler, which is set up by command. */
+  files: { path: string; content: string }[];
+  /** What to run to turn the entry on. */
+  activate: string;
+}
+
+export function defaultSchedulerPlatform(): SchedulerPlatform {
+  if (process.platform === 'darwin') return 'launchd';
+  if (process.platform === 'win32') return 'windows';
+  return 'systemd';
+}
+
+function quoteArg(arg: string): string {
+  return /^[\w@%+=:,./-]+$/.test(arg) ? arg : `"${arg.replace(/(["\\$`])/g, '\\$1')}"`;
+}
+
+function xmlEscape(s: string): string {
+  return s.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+}
+
+/**
+ * An OS entry that runs `<command> scheduler tick` every minute.
+ * command is the CLI's own command line (node and the script).
+ */
+export function schedulerEntry(platform: SchedulerPlatform, command: string[]): SchedulerEntry {
+  const argv = [...command, 'scheduler', 'tick'];
+  const homeVar = envVar('HOME');
+  const home = process.env[homeVar];
+  const name = `${APP_NAME}-scheduler`;
+
+  switch (platform) {
+    case 'systemd': {
+      const unitDir = join(process.env.XDG_CONFIG_HOME ?? join(homedir(), '.config'), 'systemd', 'user');
+      const env = home ? `Environment=${homeVar}=${quoteArg(home)}\n` : '';
+      return {
+        platform,
+        files: [
+          {
+            path: join(unitDir, `${name}.service`),
+            content:
+              `[Unit]\nDescription=Run due ${APP_NAME} schedules\n\n` +
+              `[Service]\nType=oneshot\n${env}ExecStart=${argv.map(quoteArg).join(' ')}\n`,
+          },
+          {
+            path: join(unitDir, `${name}.timer`),
+            content:
+              `[Unit]\nDescription=Check ${APP_NAME} schedules every minute\n\n` +
+              `[Timer]\nOnCalendar=*-*-* *:*:00\nPersistent=true\n\n[Install]\nWantedBy=timers.target\n`,
+          },
+        ],
+        activate: `systemctl --user daemon-reload && systemctl --user enable --now ${name}.timer`,
+      };
+    }
+    case 'launchd': {
+      const label = `dev.${APP_NAME}.scheduler`;
+      const path = join(homedir(), 'Library', 'LaunchAgents', `${label}.plist`);
+      const logPath = join(schedulesDir(), 'scheduler.log');
+      const env = home
+        ? `  <key>EnvironmentVariables</key>\n  <dict>\n    <key>${homeVar}</key>\n    <string>${xmlEscape(home)}</string>\n  </dict>\n`
+        : '';
+      const content =
+        '<?xml version="1.0" encoding="UTF-8"?>\n' +
+        '<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">\n' +
+        '<plist version="1.0">\n<dict>\n' +
+        `  <key>Label</key>\n  <string>${label}</string>\n` +
+        `  <key>ProgramArguments</key>\n  <array>\n${argv.map((a) => `    <string>${xmlEscape(a)}</string>\n`).join('')}  </array>\n` +
+        env +
+        '  <key>StartInterval</key>\n  <integer>60</integer>\n' +
+        `  <key>StandardOutPath</key>\n  <string>${xmlEscape(logPath)}</string>\n` +
+        `  <key>StandardErrorPath</key>\n  <string>${xmlEscape(logPath)}</string>\n` +
+        '</dict>\n</plist>\n';
+      return { platform, files: [{ path, content }], activate: `launchctl load -w ${quoteArg(path)}` };
+    }
+    case 'windows': {
+      const run = argv.map((a) => (/\s/.test(a) ? `\\"${a}\\"` : a)).join(' ');
+      return { platform, files: [], activate: `schtasks /Create /SC MINUTE /MO 1 /TN ${name} /TR "${run}" /F` };
+    }
+  }
+}
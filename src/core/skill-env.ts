@@ -0,0 +1,97 @@
+import { join } from 'node:path';
+import { readFileSync, existsSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { SkillManifest } from '../types/manifest.js';
+import { envVar } from '../config/branding.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { redactValue } from '../utils/redact.js';
+import { getEnvDir, getVendorEnvPath, getSkillRegistryPath } from './userdata.js';
+import { resolveType, nameFromPath } from './registry.js';
+import { buildSkillEnv, isolatedEnv, tokenFiles } from './runtime.js';
+
+// ── Skill environment ───────────────────────────────────────────────
+//
+// What a skill's process ends up with, for `agentx env export`: allowed
+// host variables, env/default.env (where not already set), then
+// env/<vendor>.env, AGENTX_* paths, tokens.env and the active account's
+// token set, and the no-network hints of network: false. The shared env
+// files are loaded by the skill itself, so this follows the scaffolded
+// skills' order.
+
+export type EnvFormat = 'dotenv' | 'shell' | 'json';
+
+export const ENV_FORMATS: EnvFormat[] = ['dotenv', 'shell', 'json'];
+
+export interface SkillEnvVar {
+  key: string;
+  value: string;
+  /** "host", "agentx", "network: false", or the file that set it. */
+  from: string;
+}
+
+function readEnvFile(path: string): [string, string][] {
+  if (!existsSync(path)) return [];
+  return parseEnvFile(readFileSync(path, 'utf-8')).map((e) => [e.key, e.value.replace(/^(['"])(.*)\1$/, '$2')]);
+}
+
+/** The skill's environment, sorted by key. */
+export function resolveSkillEnv(typePath: string, installedRoot: string): SkillEnvVar[] {
+  const resolved = resolveType(typePath, [{ name: 'installed', basePath: installedRoot }]);
+  if (!resolved || resolved.category !== 'skill') {
+    throw new Error(`Skill not installed: ${typePath}`);
+  }
+  const manifest = yaml.load(readFileSync(resolved.manifestPath, 'utf-8')) as SkillManifest;
+  const skillDir = join(installedRoot, resolved.typePath);
+  const vars = new Map<string, SkillEnvVar>();
+  const set = (key: string, value: string, from: string) => vars.set(key, { key, value, from });
+
+  const host = isolatedEnv({ ...manifest, network: undefined }, {});
+  for (const [k, v] of Object.entries(host)) set(k, v, 'host');
+
+  const defaultPath = join(getEnvDir(), 'default.env');
+  for (const [k, v] of readEnvFile(defaultPath)) if (!vars.has(k)) set(k, v, defaultPath);
+  if (manifest.vendor) {
+    const vendorPath = getVendorEnvPath(manifest.vendor);
+    for (const [k, v] of readEnvFile(vendorPath)) set(k, v, vendorPath);
+  }
+
+  for (const [k, v] of Object.entries(buildSkillEnv(skillDir, manifest))) {
+    if (k.startsWith(envVar(''))) set(k, v, 'agentx');
+  }
+  const registryPath = getSkillRegistryPath(nameFromPath(resolved.typePath));
+  for (const file of tokenFiles(registryPath).reverse()) {
+    for (const [k, v] of readEnvFile(file)) if (v) set(k, v, file);
+  }
+
+  if (manifest.network === false) {
+    for (const [k, v] of Object.entries(isolatedEnv(manifest, {}))) {
+      if (host[k] !== v) set(k, v, 'network: false');
+    }
+  }
+  return [...vars.values()].sort((a, b) => a.key.localeCompare(b.key));
+}
+
+/** vars with secret-looking or registered secret values masked. */
+export function redactEnv(vars: SkillEnvVar[]): SkillEnvVar[] {
+  return vars.map((v) => ({ ...v, value: redactValue(v.key, v.value) }));
+}
+
+const SAFE_VALUE = /^[\w@%+=:,./-]*$/;
+
+/** Renders vars for a shell (`export K='v'`), an env file, or JSON. */
+export function formatEnv(vars: SkillEnvVar[], format: EnvFormat): string {
+  switch (format) {
+    case 'json':
+      return JSON.stringify(Object.fromEntries(vars.map((v) => [v.key, v.value])), null, 2) + '\n';
+    case 'shell':
+      return vars
+        .map((v) => `export ${v.key}=${SAFE_VALUE.test(v.value) ? v.value : `'${v.value.replace(/'/g, `'\\''`)}'`}`)
+        .join('\n') + '\n';
+    case 'dotenv':
+      return vars
+        .map((v) => `${v.key}=${SAFE_VALUE.test(v.value) ? v.value : JSON.stringify(v.value)}`)
+        .join('\n') + '\n';
+    default:
+      throw new Error(`Unknown format "${format}" (expected ${ENV_FORMATS.join(', ')})`);
+  }
+}
@@ -0,0 +1,167 @@
+import { join, dirname } from 'node:path';
+import { tmpdir } from 'node:os';
+import { mkdtempSync, mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { SkillManifest, SkillTest } from '../types/manifest.js';
+import { envVar } from '../config/branding.js';
+import { runSkill, tokensPathFor } from './runtime.js';
+import { diffLines } from '../utils/merge.js';
+
+// ── Skill self-tests ────────────────────────────────────────────────
+//
+// Skills declare cases under `tests:` in their manifest:
+//
+//   tests:
+//     - name: summarizes recent commits
+//       inputs: { repoPath: ., days: 7 }
+//       env: { GITHUB_TOKEN: test }
+//       expect:
+//         exit_code: 0
+//         json: { summary: { total: 3 } }
+//
+// Each case runs through the normal runtime against a throwaway userdata
+// directory, so tests neither read the user's tokens and config nor
+// leave state behind.
+
+export interface CaseResult {
+  name: string;
+  passed: boolean;
+  /** Why the case failed, one line each (diffs span several). */
+  failures: string[];
+  durationMs: number;
+  exitCode: number;
+  stdout: string;
+  stderr: string;
+}
+
+export interface TestReport {
+  skill: string;
+  passed: number;
+  failed: number;
+  cases: CaseResult[];
+}
+
+/**
+ * Where actual differs from expected, as a dotted path, or null when
+ * actual contains everything expected. Objects match on the expected
+ * keys only; arrays and scalars must be equal.
+ */
+export function jsonMismatch(expected: unknown, actual: unknown, path = '$'): string | null {
+  if (Array.isArray(expected)) {
+    if (!Array.isArray(actual) || actual.length !== expected.length) {
+      return `${path}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`;
+    }
+    for (let i = 0; i < expected.length; i++) {
+      const m = jsonMismatch(expected[i], actual[i], `${path}[${i}]`);
+      if (m) return m;
+    }
+    return null;
+  }
+  if (expected !== null && typeof expected === 'object') {
+    if (actual === null || typeof actual !== 'object' || Array.isArray(actual)) {
+      return `${path}: expected an object, got ${JSON.stringify(actual)}`;
+    }
+    for (const [key, value] of Object.entries(expected)) {
+      const m = jsonMismatch(value, (actual as Record<string, unknown>)[key], `${path}.${key}`);
+      if (m) return m;
+    }
+    return null;
+  }
+  return expected === actual ? null : `${path}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`;
+}
+
+/** Failed expectations of a case against a run's output. */
+export function checkExpectations(test: SkillTest, exitCode: number, stdout: string): string[] {
+  const expect = test.expect ?? {};
+  const failures: string[] = [];
+
+  const wantExit = expect.exit_code ?? 0;
+  if (exitCode !== wantExit) failures.push(`exit code ${exitCode}, expected ${wantExit}`);
+
+  if (expect.stdout !== undefined && stdout.trim() !== expect.stdout.trim()) {
+    failures.push('stdout differs (- expected, + actual):');
+    failures.push(...diffLines(expect.stdout.trim(), stdout.trim()).map((l) => `  ${l}`));
+  }
+
+  const contains = expect.contains === undefined ? [] : [expect.contains].flat();
+  for (const needle of contains) {
+    if (!stdout.includes(needle)) failures.push(`stdout does not contain ${JSON.stringify(needle)}`);
+  }
+
+  if (expect.matches !== undefined && !new RegExp(expect.matches, 'm').test(stdout)) {
+    failures.push(`stdout does not match /${expect.matches}/`);
+  }
+
+  if (expect.json !== undefined) {
+    let parsed: unknown;
+    try {
+      parsed = JSON.parse(stdout);
+    } catch {
+      failures.push('stdout is not JSON');
+      return failures;
+    }
+    const mismatch = jsonMismatch(expect.json, parsed);
+    if (mismatch) failures.push(mismatch);
+  }
+  return failures;
+}
+
+function stringInputs(inputs: Record<string, unknown> = {}): Record<string, string> {
+  return Object.fromEntries(
+    Object.entries(inputs).map(([k, v]) => [k, typeof v === 'string' ? v : JSON.stringify(v)]),
+  );
+}
+
+/** Runs a skill's declared tests, optionally only those whose name includes filter. */
+export async function runSkillTests(
+  skill: string,
+  skillDir: string,
+  manifest: SkillManifest,
+  filter?: string,
+): Promise<TestReport> {
+  const tests = (manifest.tests ?? []).filter((t) => !filter || t.name.includes(filter));
+  const userdataVar = envVar('USERDATA');
+  const previous = process.env[userdataVar];
+  const cases: CaseResult[] = [];
+
+  for (const test of tests) {
+    // A fresh userdata per case, seeded with the registry's default config
+    const userdata = mkdtempSync(join(tmpdir(), 'agentx-test-'));
+    process.env[userdataVar] = userdata;
+    try {
+      const registry = dirname(tokensPathFor(skillDir));
+      mkdirSync(registry, { recursive: true });
+      if (manifest.registry?.config) {
+        writeFileSync(join(registry, 'config.yaml'), yaml.dump(manifest.registry.config));
+      }
+
+      const started = Date.now();
+      const out = await runSkill(skillDir, manifest, stringInputs(test.inputs), test.env);
+      const failures = checkExpectations(test, out.exitCode, out.stdout);
+      cases.push({
+        name: test.name,
+        passed: failures.length === 0,
+        failures,
+        durationMs: Date.now() - started,
+        ...out,
+      });
+    } catch (err) {
+      cases.push({
+        name: test.name,
+        passed: false,
+        failures: [err instanceof Error ? err.message : String(err)],
+        durationMs: 0,
+        exitCode: -1,
+        stdout: '',
+        stderr: '',
+      });
+    } finally {
+      if (previous === undefined) delete process.env[userdataVar];
+      else process.env[userdataVar] = previous;
+      rmSync(userdata, { recursive: true, force: true });
+    }
+  }
+
+  const passed = cases.filter((c) => c.passed).length;
+  return { skill, passed, failed: cases.length - passed, cases };
+}
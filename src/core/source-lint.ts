@@ -0,0 +1,164 @@
+import { join, basename } from 'node:path';
+import { existsSync, readFileSync, readdirSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { Source } from '../types/registry.js';
+import type { BaseManifest } from '../types/manifest.js';
+import { ExtensionManifestSchema } from '../config/schema.js';
+import {
+  discoverTypes,
+  resolveType,
+  extractDependencies,
+  isManifestFile,
+} from './registry.js';
+import { mergeStrategy } from './merge.js';
+import { validateManifestFile, sourceSnippet, type ManifestIssue, type ValidateOptions } from './manifest.js';
+
+// ── Source validation ───────────────────────────────────────────────
+//
+// `validate <path|source>` checks a whole catalog or extension tree, for
+// extension authors' CI:
+//
+//   - every manifest against its schema (unknown fields are errors
+//     with --strict, warnings otherwise);
+//   - the manifest's type against the directory it lives in;
+//   - references (persona, context, skills, workflow steps) resolve
+//     within this source or the sources consulted before it;
+//   - no directory holds two manifests, no alias names another type or
+//     is claimed twice, and no type is shadowed by an earlier source
+//     unless extension.yaml declares a merge strategy for it;
+//   - extension.yaml itself, and that its merge entries name types here.
+
+export interface FileIssues {
+  file: string;
+  issues: ManifestIssue[];
+}
+
+export interface SourceValidation {
+  source: string;
+  root: string;
+  types: number;
+  /** Files with issues, sorted by path. */
+  results: FileIssues[];
+}
+
+const EXTENSION_MANIFEST = 'extension.yaml';
+
+/** An issue at the first line of file mentioning needle, else at 1:1. */
+function issueNear(file: string, needle: string, path: string, message: string): ManifestIssue {
+  let raw = '';
+  try {
+    raw = readFileSync(file, 'utf-8');
+  } catch {
+    // Reported at 1:1
+  }
+  const lines = raw.split(/\r?\n/);
+  const idx = needle ? lines.findIndex((l) => l.includes(needle)) : -1;
+  const line = idx === -1 ? 1 : idx + 1;
+  const col = idx === -1 ? 1 : lines[idx].indexOf(needle) + 1;
+  return { file, line, col, path, message, snippet: raw ? sourceSnippet(raw, line, col) : '' };
+}
+
+function readBase(manifestPath: string): BaseManifest | null {
+  try {
+    return yaml.load(readFileSync(manifestPath, 'utf-8')) as BaseManifest;
+  } catch {
+    return null; // Reported by schema validation
+  }
+}
+
+/**
+ * Validates every type in source. sources is the full resolution order;
+ * those before source are where its types may be shadowed, and all of
+ * them may satisfy its references.
+ */
+export function validateSource(source: Source, sources: Source[], opts: ValidateOptions = {}): SourceValidation {
+  if (!existsSync(source.basePath)) {
+    throw new Error(`Source not found: ${source.basePath}`);
+  }
+  const byFile = new Map<string, ManifestIssue[]>();
+  const add = (issue: ManifestIssue) => byFile.set(issue.file, [...(byFile.get(issue.file) ?? []), issue]);
+
+  const position = sources.findIndex((s) => s.basePath === source.basePath);
+  const earlier = position === -1 ? sources : sources.slice(0, position);
+  const others = sources.filter((s) => s.basePath !== source.basePath);
+  const lookup = [source, ...others];
+
+  const types = discoverTypes([source]);
+  const typePaths = new Set(types.map((t) => t.typePath));
+  const aliases = new Map<string, string>();
+
+  for (const t of types) {
+    const manifestName = basename(t.manifestPath);
+    for (const extra of readdirSync(t.sourceDir).filter((n) => isManifestFile(n) && n !== manifestName)) {
+      add(issueNear(join(t.sourceDir, extra), '', '', `Duplicate manifest for ${t.typePath}; ${manifestName} is the one used`));
+    }
+
+    validateManifestFile(t.manifestPath, opts).forEach(add);
+    const base = readBase(t.manifestPath);
+    if (!base) continue;
+
+    if (base.type && base.type !== t.category) {
+      add(issueNear(t.manifestPath, `type:`, 'type', `Manifest type "${base.type}" does not match its directory (${t.typePath})`));
+    }
+
+    let deps: string[] = [];
+    try {
+      deps = extractDependencies(t.manifestPath);
+    } catch {
+      // Reported by schema validation
+    }
+    for (const dep of deps) {
+      if (!resolveType(dep, lookup)) {
+        add(issueNear(t.manifestPath, dep, '', `Unresolved reference ${dep}: no source provides it`));
+      }
+    }
+
+    for (const alias of Array.isArray(base.aliases) ? base.aliases.map(String) : []) {
+      if (typePaths.has(alias)) {
+        add(issueNear(t.manifestPath, alias, 'aliases', `Alias ${alias} is the path of another type`));
+      } else if (aliases.has(alias)) {
+        add(issueNear(t.manifestPath, alias, 'aliases', `Alias ${alias} is also claimed by ${aliases.get(alias)}`));
+      } else {
+        aliases.set(alias, t.typePath);
+      }
+    }
+
+    const shadow = earlier.find((s) => existsSync(join(s.basePath, t.typePath)));
+    if (shadow && !mergeStrategy(source, t.typePath)) {
+      add(
+        issueNear(
+          t.manifestPath,
+          '',
+          '',
+          `${t.typePath} is also provided by ${shadow.name}, which shadows it; declare merge: append or override in ${EXTENSION_MANIFEST}`,
+        ),
+      );
+    }
+  }
+
+  const extPath = join(source.basePath, EXTENSION_MANIFEST);
+  if (existsSync(extPath)) {
+    let parsed: ReturnType<typeof ExtensionManifestSchema.safeParse> | null = null;
+    try {
+      parsed = ExtensionManifestSchema.safeParse(yaml.load(readFileSync(extPath, 'utf-8')));
+    } catch (err) {
+      add(issueNear(extPath, '', '', `Unparseable ${EXTENSION_MANIFEST}: ${err instanceof Error ? err.message.split('\n')[0] : err}`));
+    }
+    if (parsed && !parsed.success) {
+      for (const i of parsed.error.issues) {
+        const path = i.path.map(String);
+        add(issueNear(extPath, path[path.length - 1] ?? '', path.join('.'), i.message));
+      }
+    }
+    for (const typePath of Object.keys(parsed?.success ? (parsed.data.merge ?? {}) : {})) {
+      if (!typePaths.has(typePath)) {
+        add(issueNear(extPath, typePath, `merge.${typePath}`, `merge lists ${typePath}, which this source does not provide`));
+      }
+    }
+  }
+
+  const results = [...byFile.entries()]
+    .map(([file, issues]) => ({ file, issues: issues.sort((a, b) => a.line - b.line) }))
+    .sort((a, b) => a.file.localeCompare(b.file));
+  return { source: source.name, root: source.basePath, types: types.length, results };
+}
@@ -0,0 +1,112 @@
+import { join, relative, resolve, sep } from 'node:path';
+import { readFileSync, readdirSync, statSync, existsSync, rmSync } from 'node:fs';
+import yaml from 'js-yaml';
+import type { SkillManifest } from '../types/manifest.js';
+import { getSkillRegistryPath } from './userdata.js';
+import { discoverTypes, nameFromPath } from './registry.js';
+import { parseSize } from '../utils/units.js';
+
+// ── Skill state ─────────────────────────────────────────────────────
+//
+// Skills keep files between runs in userdata/skills/<path>/state/.
+// Manifests may cap its size with registry.state_max_size; doctor
+// reports skills over their cap.
+
+export interface StateFile {
+  /** Path within state/, '/'-separated. */
+  path: string;
+  bytes: number;
+  modified: Date;
+}
+
+export interface StateOverLimit {
+  typePath: string;
+  bytes: number;
+  limit: number;
+}
+
+export function stateDir(typePath: string): string {
+  return join(getSkillRegistryPath(nameFromPath(typePath)), 'state');
+}
+
+export function listState(typePath: string): StateFile[] {
+  const root = stateDir(typePath);
+  const files: StateFile[] = [];
+  const walk = (dir: string) => {
+    for (const entry of readdirSync(dir, { withFileTypes: true })) {
+      const full = join(dir, entry.name);
+      if (entry.isDirectory()) {
+        walk(full);
+      } else if (entry.isFile()) {
+        const st = statSync(full);
+        files.push({ path: relative(root, full).split(sep).join('/'), bytes: st.size, modified: st.mtime });
+      }
+    }
+  };
+  if (existsSync(root)) walk(root);
+  return files.sort((a, b) => a.path.localeCompare(b.path));
+}
+
+export function stateSize(typePath: string): number {
+  return listState(typePath).reduce((sum, f) => sum + f.bytes, 0);
+}
+
+function statePath(typePath: string, file: string): string {
+  const root = stateDir(typePath);
+  const path = resolve(root, file);
+  if (!path.startsWith(root + sep)) {
+    throw new Error(`${file} is outside ${typePath}'s state`);
+  }
+  return path;
+}
+
+export function readState(typePath: string, file: string): string {
+  const path = statePath(typePath, file);
+  if (!existsSync(path)) {
+    throw new Error(`${typePath} has no state file ${file}`);
+  }
+  return readFileSync(path, 'utf-8');
+}
+
+export interface ClearOptions {
+  /** Only files last modified more than this many ms ago. */
+  olderThanMs?: number;
+  /** Only this file. */
+  file?: string;
+  /** Report what would be removed without removing it. */
+  dryRun?: boolean;
+}
+
+/** Removes state files and returns the ones removed. */
+export function clearState(typePath: string, opts: ClearOptions = {}): StateFile[] {
+  const cutoff = opts.olderThanMs === undefined ? Infinity : Date.now() - opts.olderThanMs;
+  const targets = listState(typePath).filter(
+    (f) => (!opts.file || f.path === opts.file) && f.modified.getTime() <= cutoff,
+  );
+  if (opts.file && targets.length === 0 && opts.olderThanMs === undefined) {
+    throw new Error(`${typePath} has no state file ${opts.file}`);
+  }
+  if (!opts.dryRun) {
+    for (const f of targets) rmSync(statePath(typePath, f.path), { force: true });
+  }
+  return targets;
+}
+
+/** Installed skills whose state has grown past registry.state_max_size. */
+export function stateOverLimits(installedRoot: string, warnings?: string[]): StateOverLimit[] {
+  const over: StateOverLimit[] = [];
+  const skills = discoverTypes([{ name: 'installed', basePath: installedRoot }]).filter((t) => t.category === 'skill');
+  for (const skill of skills) {
+    try {
+      const manifest = yaml.load(readFileSync(skill.manifestPath, 'utf-8')) as SkillManifest;
+      const cap = manifest.registry?.state_max_size;
+      if (!cap) continue;
+      const limit = parseSize(cap);
+      const bytes = stateSize(skill.typePath);
+      if (bytes > limit) over.push({ typePath: skill.typePath, bytes, limit });
+    } catch (err) {
+      warnings?.push(`${skill.typePath}: ${err instanceof Error ? err.message : String(err)}`);
+    }
+  }
+  return over;
+}
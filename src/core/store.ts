@@ -0,0 +1,333 @@
+import { join, dirname, relative, sep } from 'node:path';
+import { createHash } from 'node:crypto';
+import {
+  readFileSync,
+  writeFileSync,
+  readdirSync,
+  statSync,
+  lstatSync,
+  existsSync,
+  mkdirSync,
+  linkSync,
+  copyFileSync,
+  chmodSync,
+  renameSync,
+  rmSync,
+} from 'node:fs';
+import { getStoreRoot } from './userdata.js';
+import { fsyncTree } from '../utils/fs.js';
+
+// ── Layout ──────────────────────────────────────────────────────────
+//
+// ~/.agentx/store/
+//   objects/<aa>/<sha256-rest>[x]   file contents; "x" marks executables
+//   types/<typePath>/<version>.json snapshot: file list of one version
+//   types/<typePath>/current        version materialized under installed/
+//
+// Installed trees are hardlinks into objects/, so identical files across
+// types and versions occupy disk once and switching versions only
+// relinks. Objects are read-only; a skill that rewrites its own files
+// gets EACCES instead of silently corrupting every other copy. Trees
+// are deleted with removeTree, which copes with Windows refusing to
+// delete read-only files.
+//
+// Reinstalling from the same directory is incremental, as rsync is: a
+// file whose size, mtime, and executable bit match the installed
+// snapshot keeps its object without being read again, so only changed
+// files are hashed and copied into the store. The installed tree itself
+// is still relinked in full; hardlinks make that cheap. diffFiles
+// reports what a new snapshot added, updated, and removed.
+
+const OBJECTS_DIR = 'objects';
+const TYPES_DIR = 'types';
+const CURRENT_FILE = 'current';
+const SKIP_DIRS = new Set(['node_modules', '.git', 'dist']);
+const EXEC_SUFFIX = 'x';
+
+export interface StoreFile {
+  path: string;
+  object: string;
+  size: number;
+  /** Source file mtime at ingest; absent in snapshots from older versions. */
+  mtimeMs?: number;
+}
+
+export interface TypeSnapshot {
+  typePath: string;
+  version: string;
+  files: StoreFile[];
+  createdAt: string;
+  /** Source the type was installed from (catalog, an extension, ...). */
+  source?: string;
+  /** Directory the files were ingested from. */
+  sourceDir?: string;
+}
+
+export interface FileChanges {
+  added: string[];
+  updated: string[];
+  removed: string[];
+  unchanged: number;
+}
+
+/** Directories never ingested (dependencies and VCS metadata). */
+export function isSkippedDir(name: string): boolean {
+  return SKIP_DIRS.has(name);
+}
+
+/** sha256 of a stored file's content, without the executable marker. */
+export function objectDigest(object: string): string {
+  return object.endsWith(EXEC_SUFFIX) ? object.slice(0, -EXEC_SUFFIX.length) : object;
+}
+
+// ── Objects ─────────────────────────────────────────────────────────
+
+export function objectPath(object: string): string {
+  return join(getStoreRoot(), OBJECTS_DIR, object.slice(0, 2), object.slice(2));
+}
+
+function putObject(srcPath: string): StoreFile['object'] {
+  const st = statSync(srcPath);
+  const executable = (st.mode & 0o111) !== 0;
+  const sha = createHash('sha256').update(readFileSync(srcPath)).digest('hex');
+  const object = executable ? sha + EXEC_SUFFIX : sha;
+
+  const dst = objectPath(object);
+  if (!existsSync(dst)) {
+    mkdirSync(dirname(dst), { recursive: true });
+    const tmp = `${dst}.tmp-${process.pid}`;
+    copyFileSync(srcPath, tmp);
+    chmodSync(tmp, executable ? 0o555 : 0o444);
+    renameSync(tmp, dst);
+  }
+  return object;
+}
+
+function walk(root: string, dir: string, known: Map<string, StoreFile>, out: StoreFile[]): void {
+  for (const entry of readdirSync(dir, { withFileTypes: true })) {
+    const full = join(dir, entry.name);
+    if (entry.isDirectory()) {
+      if (!SKIP_DIRS.has(entry.name)) walk(root, full, known, out);
+    } else if (entry.isFile()) {
+      const path = relative(root, full).split(sep).join('/');
+      const st = statSync(full);
+      const prev = known.get(path);
+      const unchanged =
+        prev &&
+        prev.size === st.size &&
+        prev.mtimeMs === st.mtimeMs &&
+        prev.object.endsWith(EXEC_SUFFIX) === ((st.mode & 0o111) !== 0) &&
+        existsSync(objectPath(prev.object));
+      out.push({ path, object: unchanged ? prev.object : putObject(full), size: st.size, mtimeMs: st.mtimeMs });
+    }
+  }
+}
+
+/**
+ * Copies a source tree's files into the object store and lists them.
+ * Files of previous (ingested from the same directory) whose size,
+ * mtime, and executable bit are unchanged keep their object without
+ * being read.
+ */
+export function ingestDir(srcDir: string, previous: StoreFile[] = []): StoreFile[] {
+  const files: StoreFile[] = [];
+  walk(srcDir, srcDir, new Map(previous.map((f) => [f.path, f])), files);
+  return files.sort((a, b) => a.path.localeCompare(b.path));
+}
+
+/**
+ * Files added, updated, and removed going from before to after. A file
+ * is updated when its content or executable bit changed: both are part
+ * of its object name.
+ */
+export function diffFiles(before: StoreFile[], after: StoreFile[]): FileChanges {
+  const old = new Map(before.map((f) => [f.path, f.object]));
+  const changes: FileChanges = { added: [], updated: [], removed: [], unchanged: 0 };
+  for (const f of after) {
+    const object = old.get(f.path);
+    if (object === undefined) changes.added.push(f.path);
+    else if (object !== f.object) changes.updated.push(f.path);
+    else changes.unchanged++;
+    old.delete(f.path);
+  }
+  changes.removed = [...old.keys()];
+  return changes;
+}
+
+// ── Snapshots ───────────────────────────────────────────────────────
+
+function typeDir(typePath: string): string {
+  return join(getStoreRoot(), TYPES_DIR, typePath);
+}
+
+function snapshotPath(typePath: string, version: string): string {
+  return join(typeDir(typePath), `${version}.json`);
+}
+
+export function loadSnapshot(typePath: string, version: string): TypeSnapshot | null {
+  try {
+    return JSON.parse(readFileSync(snapshotPath(typePath, version), 'utf-8')) as TypeSnapshot;
+  } catch {
+    return null;
+  }
+}
+
+/** Stored snapshots of a type, oldest first. */
+export function listSnapshots(typePath: string): TypeSnapshot[] {
+  let names: string[];
+  try {
+    names = readdirSync(typeDir(typePath)).filter((n) => n.endsWith('.json'));
+  } catch {
+    return [];
+  }
+  return names
+    .map((n) => loadSnapshot(typePath, n.slice(0, -'.json'.length)))
+    .filter((s): s is TypeSnapshot => s !== null)
+    .sort((a, b) => a.createdAt.localeCompare(b.createdAt));
+}
+
+/** The snapshot of the version materialized under installed/, if any. */
+export function currentSnapshot(typePath: string): TypeSnapshot | null {
+  const version = readCurrent(typePath);
+  return version ? loadSnapshot(typePath, version) : null;
+}
+
+/**
+ * Ingests a source tree as one version of a type. Re-storing a version
+ * replaces it. Unchanged files of the current snapshot are not re-read
+ * when it came from the same directory.
+ */
+export function storeType(
+  typePath: string,
+  sourceDir: string,
+  version: string,
+  source?: string,
+): TypeSnapshot {
+  const current = currentSnapshot(typePath);
+  const snapshot: TypeSnapshot = {
+    typePath,
+    version,
+    files: ingestDir(sourceDir, current?.sourceDir === sourceDir ? current.files : []),
+    createdAt: new Date().toISOString(),
+    source,
+    sourceDir,
+  };
+  mkdirSync(typeDir(typePath), { recursive: true });
+  writeFileSync(snapshotPath(typePath, version), JSON.stringify(snapshot, null, 2));
+  return snapshot;
+}
+
+// ── Materialization ─────────────────────────────────────────────────
+
+export function readCurrent(typePath: string): string | null {
+  try {
+    return readFileSync(join(typeDir(typePath), CURRENT_FILE), 'utf-8').trim() || null;
+  } catch {
+    return null;
+  }
+}
+
+export function writeCurrent(typePath: string, version: string): void {
+  mkdirSync(typeDir(typePath), { recursive: true });
+  writeFileSync(join(typeDir(typePath), CURRENT_FILE), version);
+}
+
+export function clearCurrent(typePath: string): void {
+  rmSync(join(typeDir(typePath), CURRENT_FILE), { force: true });
+}
+
+function linkOrCopy(object: string, dst: string): void {
+  const src = objectPath(object);
+  try {
+    linkSync(src, dst);
+  } catch {
+    // Cross-device or no hardlink support: fall back to a private copy
+    copyFileSync(src, dst);
+    chmodSync(dst, object.endsWith(EXEC_SUFFIX) ? 0o755 : 0o644);
+  }
+}
+
+/**
+ * Builds the snapshot's tree next to its destination and flushes it to
+ * disk. Returns the staging directory, for swapStaged.
+ */
+export function stageSnapshot(snapshot: TypeSnapshot, installedRoot: string): string {
+  const tmp = `${join(installedRoot, snapshot.typePath)}.tmp-${process.pid}`;
+  removeTree(tmp);
+  mkdirSync(tmp, { recursive: true });
+  try {
+    for (const file of snapshot.files) {
+      const target = join(tmp, file.path);
+      mkdirSync(dirname(target), { recursive: true });
+      linkOrCopy(file.object, target);
+    }
+    fsyncTree(tmp);
+  } catch (err) {
+    removeTree(tmp);
+    throw err;
+  }
+  return tmp;
+}
+
+/**
+ * Renames a staged tree into place and records its version as current.
+ * The previous tree is moved aside, not deleted; its path is returned
+ * (null when there was none) for the caller to remove or restore.
+ */
+export function swapStaged(snapshot: TypeSnapshot, staged: string, installedRoot: string): string | null {
+  const dst = join(installedRoot, snapshot.typePath);
+  const old = `${dst}.old-${process.pid}`;
+  removeTree(old);
+
+  const hadPrevious = existsSync(dst);
+  if (hadPrevious) renameSync(dst, old);
+  try {
+    renameSync(staged, dst);
+  } catch (err) {
+    if (hadPrevious) renameSync(old, dst);
+    throw err;
+  }
+  writeCurrent(snapshot.typePath, snapshot.version);
+  return hadPrevious ? old : null;
+}
+
+/**
+ * Builds the snapshot's tree next to the destination and swaps it into
+ * place, so the installed directory is never half-written.
+ */
+export function materialize(snapshot: TypeSnapshot, installedRoot: string): void {
+  const previous = swapStaged(snapshot, stageSnapshot(snapshot, installedRoot), installedRoot);
+  if (previous) removeTree(previous);
+}
+
+// ── Removal ─────────────────────────────────────────────────────────
+
+/**
+ * Deletes an installed tree (or a single file, such as an object).
+ * Windows refuses to delete read-only files, and installed files are
+ * links to read-only objects, so there each file is made writable
+ * first. Links share that bit with their object, which is made
+ * read-only again once the tree is gone.
+ */
+export function removeTree(path: string): void {
+  const objects = new Set<string>();
+  if (process.platform === 'win32' && existsSync(path)) clearReadOnly(path, objects);
+  rmSync(path, { recursive: true, force: true });
+  for (const object of objects) {
+    if (existsSync(objectPath(object))) chmodSync(objectPath(object), object.endsWith(EXEC_SUFFIX) ? 0o555 : 0o444);
+  }
+}
+
+function clearReadOnly(path: string, objects: Set<string>): void {
+  const st = lstatSync(path);
+  if (st.isDirectory()) {
+    for (const name of readdirSync(path)) clearReadOnly(join(path, name), objects);
+    return;
+  }
+  if (!st.isFile() || (st.mode & 0o200) !== 0) return;
+  if (st.nlink > 1) {
+    const sha = createHash('sha256').update(readFileSync(path)).digest('hex');
+    objects.add(sha).add(sha + EXEC_SUFFIX);
+  }
+  chmodSync(path, 0o666);
+}
@@ -0,0 +1,177 @@
+import { readFileSync, writeFileSync, appendFileSync, renameSync, rmSync, mkdirSync } from 'node:fs';
+import { dirname } from 'node:path';
+import * as settings from '../config/settings.js';
+import { httpFetch } from '../utils/http.js';
+import { getStatsPath, getTelemetryOutboxPath } from './userdata.js';
+import { currentVersion } from './updater.js';
+import { isOffline } from './offline.js';
+
+// ── Telemetry ───────────────────────────────────────────────────────
+//
+// Off unless opted into. With telemetry.local, each command's name,
+// duration, and outcome (and the skill, for runs) are aggregated into
+// ~/.agentx/stats.json for `agentx stats`. With telemetry.enabled the
+// same is kept locally and an anonymous event, without arguments, paths,
+// or skill names, is queued for telemetry.endpoint. Queued events are
+// sent in batches by a background `agentx stats flush`.
+
+export type TelemetryMode = 'off' | 'local' | 'remote';
+
+export interface UsageEvent {
+  /** Command path, e.g. "catalog update". */
+  command: string;
+  durationMs: number;
+  success: boolean;
+  version: string;
+  platform: string;
+  at: string;
+}
+
+export interface UsageTotals {
+  count: number;
+  failures: number;
+  totalMs: number;
+  maxMs: number;
+}
+
+export interface UsageStats {
+  since: string;
+  commands: Record<string, UsageTotals>;
+  skills: Record<string, UsageTotals>;
+}
+
+/** Queued events that trigger a background flush. */
+export const FLUSH_BATCH = 20;
+
+export function telemetryMode(): TelemetryMode {
+  if (settings.get('telemetry.enabled') === 'true') return 'remote';
+  if (settings.get('telemetry.local') === 'true') return 'local';
+  return 'off';
+}
+
+// ── Local stats ─────────────────────────────────────────────────────
+
+export function readStats(): UsageStats {
+  try {
+    return JSON.parse(readFileSync(getStatsPath(), 'utf-8')) as UsageStats;
+  } catch {
+    return { since: new Date().toISOString(), commands: {}, skills: {} };
+  }
+}
+
+export function resetStats(): void {
+  rmSync(getStatsPath(), { force: true });
+}
+
+function add(totals: Record<string, UsageTotals>, key: string, event: UsageEvent): void {
+  const t = (totals[key] ??= { count: 0, failures: 0, totalMs: 0, maxMs: 0 });
+  t.count++;
+  if (!event.success) t.failures++;
+  t.totalMs += event.durationMs;
+  t.maxMs = Math.max(t.maxMs, event.durationMs);
+}
+
+function writeStats(stats: UsageStats): void {
+  const path = getStatsPath();
+  mkdirSync(dirname(path), { recursive: true });
+  const tmp = `${path}.${process.pid}.tmp`;
+  writeFileSync(tmp, JSON.stringify(stats, null, 2));
+  renameSync(tmp, path);
+}
+
+/**
+ * Records one finished command. Synchronous, so it can run from an
+ * exit handler. Returns the number of queued events; never throws.
+ */
+export function recordUsage(event: UsageEvent, skill?: string): number {
+  const mode = telemetryMode();
+  if (mode === 'off') return 0;
+  try {
+    const stats = readStats();
+    add(stats.commands, event.command, event);
+    if (skill) add(stats.skills, skill, event);
+    writeStats(stats);
+
+    if (mode !== 'remote') return 0;
+    const outbox = getTelemetryOutboxPath();
+    appendFileSync(outbox, JSON.stringify(event) + '\n');
+    return readFileSync(outbox, 'utf-8').split('\n').filter(Boolean).length;
+  } catch {
+    // Telemetry must never break a command
+    return 0;
+  }
+}
+
+// ── Sending ─────────────────────────────────────────────────────────
+
+/**
+ * Sends queued events to telemetry.endpoint as { events: [...] } and
+ * empties the queue. Returns how many were sent; 0 when telemetry is
+ * not enabled, no endpoint is set, or offline.
+ */
+export async function flushTelemetry(): Promise<number> {
+  const endpoint = settings.get('telemetry.endpoint');
+  const outbox = getTelemetryOutboxPath();
+  if (telemetryMode() !== 'remote' || !endpoint || isOffline()) return 0;
+
+  // Claim the queue so events recorded meanwhile go to a fresh file
+  const sending = `${outbox}.${process.pid}.sending`;
+  try {
+    renameSync(outbox, sending);
+  } catch {
+    return 0; // Empty, or another flush took it
+  }
+  const events = readFileSync(sending, 'utf-8')
+    .split('\n')
+    .filter(Boolean)
+    .map((line) => JSON.parse(line) as UsageEvent);
+
+  try {
+    const res = await httpFetch(endpoint, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ events }),
+    });
+    if (!res.ok) throw new Error(`Telemetry endpoint returned HTTP ${res.status} ${res.statusText}`);
+  } catch (err) {
+    // Put the events back for the next attempt
+    appendFileSync(outbox, events.map((e) => JSON.stringify(e) + '\n').join(''));
+    throw err;
+  } finally {
+    rmSync(sending, { force: true });
+  }
+  return events.length;
+}
+
+// ── Current invocation ──────────────────────────────────────────────
+
+let startedAt = 0;
+let command = '';
+let skill: string | undefined;
+
+/** Called by the CLI middleware when a command starts. */
+export function startUsage(commandPath: string): void {
+  startedAt = Date.now();
+  command = commandPath;
+  skill = undefined;
+}
+
+/** Attributes the current command to a skill, for `agentx stats`. */
+export function noteSkill(typePath: string): void {
+  skill = typePath;
+}
+
+/** Records the current command; returns the number of queued events. */
+export function finishUsage(exitCode: number): number {
+  if (!command) return 0;
+  const event: UsageEvent = {
+    command,
+    durationMs: Date.now() - startedAt,
+    success: exitCode === 0,
+    version: currentVersion(),
+    platform: process.platform,
+    at: new Date().toISOString(),
+  };
+  command = '';
+  return recordUsage(event, skill);
+}
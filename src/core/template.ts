@@ -0,0 +1,186 @@
+import { join } from 'node:path';
+import { readFileSync, readdirSync, existsSync } from 'node:fs';
+import Handlebars from 'handlebars';
+import yaml from 'js-yaml';
+import type { Source } from '../types/registry.js';
+import type { TemplateManifest } from '../types/manifest.js';
+import { truncateToTokens } from './tokens.js';
+import { listFiles } from '../utils/fs.js';
+
+export type TemplateEngine = typeof Handlebars;
+
+export interface EngineOptions {
+  /** Sources whose templates/_partials/ directories provide partials. */
+  partialSources?: Source[];
+}
+
+const PARTIALS_DIR = join('templates', '_partials');
+const PARTIAL_EXT = /\.(hbs|handlebars|md)$/;
+const DEFAULT_DATE_FORMAT = 'YYYY-MM-DD';
+
+// ── Helpers ─────────────────────────────────────────────────────────
+//
+// Helpers are pure formatting functions. Templates come from catalogs
+// and extensions, so nothing here may touch the filesystem, network,
+// or environment.
+
+// Handlebars appends an options hash to every helper call; strip it to
+// get the positional arguments.
+function positional(args: unknown[]): unknown[] {
+  return args.slice(0, -1);
+}
+
+function pad(n: number): string {
+  return String(n).padStart(2, '0');
+}
+
+/** Formats with YYYY, MM, DD, HH, mm, ss tokens (UTC). */
+export function formatDate(value: unknown, format = DEFAULT_DATE_FORMAT): string {
+  const d = value === undefined ? new Date() : new Date(value as string | number);
+  if (Number.isNaN(d.getTime())) return String(value);
+  return format
+    .replace('YYYY', String(d.getUTCFullYear()))
+    .replace('MM', pad(d.getUTCMonth() + 1))
+    .replace('DD', pad(d.getUTCDate()))
+    .replace('HH', pad(d.getUTCHours()))
+    .replace('mm', pad(d.getUTCMinutes()))
+    .replace('ss', pad(d.getUTCSeconds()));
+}
+
+function registerHelpers(hbs: TemplateEngine): void {
+  hbs.registerHelper('json', (value: unknown) => JSON.stringify(value ?? null, null, 2));
+
+  hbs.registerHelper('upper', (value: unknown) => String(value ?? '').toUpperCase());
+
+  // {{date}} → today; {{date value}}; {{date value "YYYY-MM-DD HH:mm"}}
+  hbs.registerHelper('date', (...args: unknown[]) => {
+    const [value, format] = positional(args);
+    return formatDate(value, typeof format === 'string' ? format : undefined);
+  });
+
+  // {{join list}} → "a, b"; {{join list " | "}}
+  hbs.registerHelper('join', (...args: unknown[]) => {
+    const [list, sep] = positional(args);
+    if (!Array.isArray(list)) return String(list ?? '');
+    return list.join(typeof sep === 'string' ? sep : ', ');
+  });
+
+  // {{truncateTokens text 500}}
+  hbs.registerHelper('truncateTokens', (...args: unknown[]) => {
+    const [text, max] = positional(args);
+    return truncateToTokens(String(text ?? ''), Number(max));
+  });
+}
+
+// ── Partials ────────────────────────────────────────────────────────
+
+/**
+ * Registers every file under <source>/templates/_partials/ as a partial
+ * named by its path without extension ({{> header}}, {{> report/footer}}).
+ * Sources are searched in order and the first definition of a name wins,
+ * matching type resolution.
+ */
+export function registerPartials(hbs: TemplateEngine, sources: Source[]): string[] {
+  const registered: string[] = [];
+  for (const source of sources) {
+    const dir = join(source.basePath, PARTIALS_DIR);
+    for (const file of listFiles(dir)) {
+      if (!PARTIAL_EXT.test(file)) continue;
+      const name = file.replace(PARTIAL_EXT, '');
+      if (registered.includes(name)) continue;
+      hbs.registerPartial(name, readFileSync(join(dir, file), 'utf-8'));
+      registered.push(name);
+    }
+  }
+  return registered;
+}
+
+// ── Engine ──────────────────────────────────────────────────────────
+
+/**
+ * Creates an isolated Handlebars environment so helpers registered for
+ * prompt and report templates never leak into the integration templates,
+ * which use the global instance.
+ */
+export function createEngine(opts: EngineOptions = {}): TemplateEngine {
+  const hbs = Handlebars.create();
+  registerHelpers(hbs);
+  if (opts.partialSources) registerPartials(hbs, opts.partialSources);
+  return hbs;
+}
+
+// Output is Markdown or plain text consumed by AI tools, never HTML, so
+// values are emitted verbatim instead of being entity-escaped.
+export function renderString(
+  source: string,
+  data: unknown,
+  engine: TemplateEngine = createEngine(),
+): string {
+  return engine.compile(source, { noEscape: true })(data);
+}
+
+export function renderFile(
+  path: string,
+  data: unknown,
+  engine: TemplateEngine = createEngine(),
+): string {
+  return renderString(readFileSync(path, 'utf-8'), data, engine);
+}
+
+// ── Template types ──────────────────────────────────────────────────
+//
+// A templates/<name> type is a manifest and a Handlebars file: template.hbs,
+// or the type's only .hbs file. `agentx template render` fills it with
+// data from --var flags and an --input-file. Rendering is strict: a
+// required variable without a value, or a {{field}} with no value, is an
+// error rather than an empty string. Declared optional variables without
+// a value render as "".
+
+const TEMPLATE_FILE = 'template.hbs';
+const TEMPLATE_MANIFESTS = ['manifest.yaml', 'manifest.json', 'template.yaml'];
+
+/** The Handlebars file of the template type in dir. */
+export function templateFile(dir: string): string {
+  if (existsSync(join(dir, TEMPLATE_FILE))) return join(dir, TEMPLATE_FILE);
+  const hbs = readdirSync(dir).filter((f) => f.endsWith('.hbs'));
+  if (hbs.length === 1) return join(dir, hbs[0]);
+  throw new Error(
+    hbs.length === 0
+      ? `No ${TEMPLATE_FILE} in ${dir}`
+      : `Several .hbs files in ${dir} (${hbs.join(', ')}); name the main one ${TEMPLATE_FILE}`,
+  );
+}
+
+/** Renders the template type in dir; data fills its declared variables and any other field. */
+export function renderTemplateType(
+  dir: string,
+  data: Record<string, unknown>,
+  engine: TemplateEngine = createEngine(),
+): string {
+  const manifestPath = TEMPLATE_MANIFESTS.map((f) => join(dir, f)).find((p) => existsSync(p));
+  if (!manifestPath) throw new Error(`No manifest in ${dir}`);
+  const manifest = yaml.load(readFileSync(manifestPath, 'utf-8')) as TemplateManifest;
+  const values: Record<string, unknown> = {};
+  const missing: string[] = [];
+  for (const v of manifest.variables ?? []) {
+    const value = data[v.name] ?? v.default;
+    if (value !== undefined) values[v.name] = value;
+    else if (v.required) missing.push(v.name);
+    else values[v.name] = '';
+  }
+  if (missing.length > 0) {
+    throw new Error(`Template ${manifest.name} requires: ${missing.map((m) => `--var ${m}=...`).join(' ')}`);
+  }
+
+  const file = templateFile(dir);
+  // Strict mode would take {{date}} for a missing field unless the
+  // registered helpers are declared known
+  const knownHelpers = Object.fromEntries(Object.keys(engine.helpers).map((name) => [name, true]));
+  try {
+    return engine.compile(readFileSync(file, 'utf-8'), { noEscape: true, strict: true, knownHelpers })({ ...data, ...values });
+  } catch (err) {
+    const field = /"([^"]+)" not defined/.exec(err instanceof Error ? err.message : '')?.[1];
+    if (field) throw new Error(`Template ${manifest.name} uses {{${field}}}, which has no value; pass --var ${field}=...`);
+    throw err;
+  }
+}
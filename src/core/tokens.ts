@@ -0,0 +1,27 @@
+import { getEncoding, type Tiktoken } from 'js-tiktoken';
+
+// cl100k_base is the encoding used by GPT-4 class models and is a close
+// enough approximation for Claude-family tokenizers for budgeting purposes.
+const ENCODING = 'cl100k_base';
+
+let encoder: Tiktoken | null = null;
+
+function getEncoder(): Tiktoken {
+  if (!encoder) {
+    encoder = getEncoding(ENCODING);
+  }
+  return encoder;
+}
+
+export function countTokens(text: string): number {
+  if (!text) return 0;
+  return getEncoder().encode(text).length;
+}
+
+export function truncateToTokens(text: string, maxTokens: number): string {
+  if (maxTokens <= 0) return '';
+  const enc = getEncoder();
+  const tokens = enc.encode(text);
+  if (tokens.length <= maxTokens) return text;
+  return enc.decode(tokens.slice(0, maxTokens));
+}
@@ -0,0 +1,313 @@
+import { join, basename, dirname, resolve } from 'node:path';
+import { existsSync, readFileSync, readdirSync, writeFileSync, mkdirSync, rmSync, cpSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { readGeneratedVersion } from '../integrations/index.js';
+import { parseToolName, type ToolName } from '../types/integrations.js';
+import { loadProject, saveProject, projectConfigPath, projectExtensionsDir } from './linker.js';
+import { trustProjectExtension } from './trust.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('import');
+
+// ── Importing AI tool files ─────────────────────────────────────────
+//
+// `agentx import` migrates a project that predates agentx. It reads the
+// instruction files AI tools already use and turns them into types in a
+// local extension, .agentx/extensions/<name>/:
+//
+//   - every top-level section (## heading, or # when there is no single
+//     document title) becomes context/<project>/<section>;
+//   - the text before the first section, when there is any, becomes the
+//     persona personas/<project>: its first paragraph the description,
+//     its bullets the conventions;
+//   - presets/<name>.yaml lists them, with the tools the files were for.
+//
+// Sections with the same heading in several files become one type when
+// their text matches, else one per tool. The original files are copied
+// to .agentx/imported/, since `link sync` regenerates some of them.
+
+export interface ToolFile {
+  /** Tool the file configures; cursor has no integration, so it only feeds types. */
+  tool: ToolName | 'cursor';
+  /** Relative to the project. */
+  path: string;
+}
+
+const TOOL_FILES: ToolFile[] = [
+  { tool: 'claude-code', path: 'CLAUDE.md' },
+  { tool: 'claude-code', path: '.claude/CLAUDE.md' },
+  { tool: 'copilot', path: '.github/copilot-instructions.md' },
+  { tool: 'cursor', path: '.cursorrules' },
+];
+
+const IMPORTED_DIR = 'imported';
+const VERSION = '1.0.0';
+const MAX_SLUG = 40;
+// Opening of a preamble that describes who the assistant is
+const PERSONA_OPENING = /^(you are|you're|act as|as an? )/i;
+
+export interface InstructionSection {
+  title: string;
+  body: string;
+}
+
+export interface ParsedInstructions {
+  /** The single # heading that titles the whole document, if any. */
+  title: string | null;
+  /** Text before the first section. */
+  preamble: string;
+  sections: InstructionSection[];
+}
+
+export interface ImportOptions {
+  /** Extension name; defaults to <project directory>-local. */
+  name?: string;
+  /** Replace an existing extension of the same name. */
+  force?: boolean;
+  /** Work out the types without writing anything. */
+  dryRun?: boolean;
+}
+
+export interface ImportResult {
+  extension: string;
+  dir: string;
+  /** Files the types came from. */
+  files: ToolFile[];
+  /** Files skipped because agentx generated them. */
+  generated: string[];
+  context: string[];
+  persona: string | null;
+  preset: string;
+  /** Tools for project.yaml, from the files found. */
+  tools: ToolName[];
+}
+
+export function slugify(text: string): string {
+  const slug = text
+    .toLowerCase()
+    .replace(/[`*_]/g, '')
+    .replace(/[^a-z0-9]+/g, '-')
+    .slice(0, MAX_SLUG)
+    .replace(/^-+|-+$/g, '');
+  return slug;
+}
+
+/** Tool files present in the project, and generated ones that were skipped. */
+export function findToolFiles(projectPath: string): { files: ToolFile[]; generated: string[] } {
+  const files: ToolFile[] = [];
+  const generated: string[] = [];
+  for (const f of TOOL_FILES) {
+    const path = join(projectPath, f.path);
+    if (!existsSync(path)) continue;
+    if (readGeneratedVersion(path)) generated.push(f.path);
+    else files.push(f);
+  }
+  return { files, generated };
+}
+
+/** Splits markdown into a title, a preamble, and top-level sections. */
+export function parseInstructions(markdown: string): ParsedInstructions {
+  const lines = markdown.replace(/\r\n/g, '\n').split('\n');
+  const headings: { line: number; level: number; text: string }[] = [];
+  let fence: string | null = null;
+  lines.forEach((line, i) => {
+    const marker = /^\s*(```|~~~)/.exec(line)?.[1];
+    if (marker) {
+      if (fence === null) fence = marker;
+      else if (fence === marker) fence = null;
+      return;
+    }
+    const m = fence === null ? /^(#{1,6})\s+(.+?)\s*#*\s*$/.exec(line) : null;
+    if (m) headings.push({ line: i, level: m[1].length, text: m[2] });
+  });
+
+  if (headings.length === 0) {
+    // Plain rules, as in most .cursorrules: an opening "You are ..."
+    // paragraph is the preamble, the rest one section
+    const text = markdown.trim();
+    const [first, ...rest] = text.split(/\n\s*\n/);
+    if (PERSONA_OPENING.test(first ?? '')) {
+      const body = rest.join('\n\n').trim();
+      return { title: null, preamble: first.trim(), sections: body ? [{ title: 'Rules', body }] : [] };
+    }
+    return { title: null, preamble: '', sections: text ? [{ title: 'Rules', body: text }] : [] };
+  }
+
+  const h1s = headings.filter((h) => h.level === 1);
+  const titled = h1s.length === 1 && headings[0].level === 1 && headings.length > 1;
+  const title = titled ? headings[0].text : null;
+  const rest = titled ? headings.slice(1) : headings;
+  const level = Math.min(...rest.map((h) => h.level));
+  const splits = rest.filter((h) => h.level === level);
+
+  const preambleLines = lines.slice(0, splits[0].line).filter((_, i) => !(titled && i === headings[0].line));
+  const sections = splits
+    .map((h, i) => ({
+      title: h.text,
+      body: lines.slice(h.line + 1, i + 1 < splits.length ? splits[i + 1].line : lines.length).join('\n').trim(),
+    }))
+    .filter((s) => s.body);
+  return { title, preamble: preambleLines.join('\n').trim(), sections };
+}
+
+/** Persona fields from preamble text: first paragraph, then its bullets. */
+export function personaFromPreamble(preamble: string): { description: string; conventions: string[] } | null {
+  const paragraphs = preamble.split(/\n\s*\n/).map((p) => p.trim()).filter(Boolean);
+  const bullet = /^\s*(?:[-*+]|\d+[.)])\s+(.*)$/;
+  const prose = paragraphs.find((p) => !bullet.test(p.split('\n')[0]));
+  const conventions = preamble
+    .split('\n')
+    .map((l) => bullet.exec(l)?.[1].trim())
+    .filter((c): c is string => Boolean(c));
+  if (!prose && conventions.length === 0) return null;
+  return {
+    description: (prose ?? 'Imported project guidance').replace(/\s*\n\s*/g, ' '),
+    conventions,
+  };
+}
+
+const normalize = (text: string) => text.replace(/\s+/g, ' ').trim();
+
+function write(dir: string, rel: string, content: string): void {
+  mkdirSync(dirname(join(dir, rel)), { recursive: true });
+  writeFileSync(join(dir, rel), content);
+}
+
+/**
+ * Converts the project's AI tool files into a local extension. With
+ * dryRun, only reports what it would write.
+ */
+export function importToolFiles(projectPath: string, opts: ImportOptions = {}): ImportResult {
+  const project = slugify(basename(resolve(projectPath))) || 'project';
+  const name = opts.name ?? `${project}-local`;
+  if (slugify(name) !== name) {
+    throw new Error(`Invalid extension name "${name}": use lowercase letters, digits, and hyphens`);
+  }
+  const { files, generated } = findToolFiles(projectPath);
+  if (files.length === 0) {
+    const found = generated.length > 0 ? ` (${generated.join(', ')} were generated by agentx)` : '';
+    throw new Error(`No AI tool files to import: looked for ${TOOL_FILES.map((f) => f.path).join(', ')}${found}`);
+  }
+  const dir = join(projectExtensionsDir(projectPath), name);
+  if (existsSync(dir) && readdirSync(dir).length > 0 && !opts.force && !opts.dryRun) {
+    throw new Error(`Extension ${name} already exists at ${dir}; pass --force to replace it`);
+  }
+
+  // Section slug → the types made from it, one per distinct text
+  const bySlug = new Map<string, { title: string; body: string; from: ToolFile[] }[]>();
+  const preambles: string[] = [];
+  for (const file of files) {
+    const parsed = parseInstructions(readFileSync(join(projectPath, file.path), 'utf-8'));
+    if (parsed.preamble && !preambles.some((p) => normalize(p) === normalize(parsed.preamble))) {
+      preambles.push(parsed.preamble);
+    }
+    parsed.sections.forEach((s, i) => {
+      const slug = slugify(s.title) || `section-${i + 1}`;
+      const variants = bySlug.get(slug) ?? [];
+      const same = variants.find((v) => normalize(v.body) === normalize(s.body));
+      if (same) same.from.push(file);
+      else variants.push({ ...s, from: [file] });
+      bySlug.set(slug, variants);
+    });
+  }
+
+  const contextTypes: { typePath: string; title: string; body: string; from: ToolFile[] }[] = [];
+  for (const [slug, variants] of bySlug) {
+    for (const v of variants) {
+      const suffix = variants.length > 1 ? `-${v.from[0].tool}` : '';
+      contextTypes.push({ typePath: `context/${project}/${slug}${suffix}`, ...v });
+    }
+  }
+  const persona = personaFromPreamble(preambles.join('\n\n'));
+  const personaPath = persona ? `personas/${project}` : null;
+  const tools = [...new Set(files.map((f) => parseToolName(f.tool)).filter((t): t is ToolName => t !== null))];
+  const result: ImportResult = {
+    extension: name,
+    dir,
+    files,
+    generated,
+    context: contextTypes.map((t) => t.typePath),
+    persona: personaPath,
+    preset: name,
+    tools,
+  };
+  if (opts.dryRun) return result;
+
+  rmSync(dir, { recursive: true, force: true });
+  const sourceList = files.map((f) => f.path).join(', ');
+  write(dir, 'extension.yaml', yaml.dump({ name, description: `Imported from ${sourceList}` }, { lineWidth: -1 }));
+  for (const t of contextTypes) {
+    const typeName = t.typePath.split('/').pop()!;
+    write(
+      dir,
+      `${t.typePath}/context.yaml`,
+      yaml.dump(
+        {
+          name: typeName,
+          type: 'context',
+          version: VERSION,
+          description: `${t.title} (imported from ${t.from.map((f) => f.path).join(', ')})`,
+          tags: ['imported'],
+          format: 'markdown',
+          sources: ['content.md'],
+        },
+        { lineWidth: -1 },
+      ),
+    );
+    write(dir, `${t.typePath}/content.md`, `# ${t.title}\n\n${t.body}\n`);
+  }
+  if (persona && personaPath) {
+    write(
+      dir,
+      `${personaPath}/persona.yaml`,
+      yaml.dump(
+        {
+          name: project,
+          type: 'persona',
+          version: VERSION,
+          description: persona.description,
+          tags: ['imported'],
+          ...(persona.conventions.length > 0 ? { conventions: persona.conventions } : {}),
+          context: result.context,
+        },
+        { lineWidth: -1 },
+      ),
+    );
+  }
+  write(
+    dir,
+    `presets/${name}.yaml`,
+    yaml.dump(
+      {
+        name,
+        description: `Types imported from ${sourceList}`,
+        ...(tools.length > 0 ? { tools } : {}),
+        ...(personaPath ? { personas: [personaPath] } : {}),
+        context: result.context,
+      },
+      { lineWidth: -1 },
+    ),
+  );
+
+  // Keep the originals: link sync overwrites .claude/CLAUDE.md and
+  // .github/copilot-instructions.md
+  for (const f of files) {
+    const copy = join(projectPath, '.agentx', IMPORTED_DIR, f.path);
+    mkdirSync(dirname(copy), { recursive: true });
+    cpSync(join(projectPath, f.path), copy);
+  }
+  // The user just wrote it from their own files
+  trustProjectExtension(dir);
+  log.info('imported', { extension: name, files: files.length, context: result.context.length, persona: personaPath });
+  return result;
+}
+
+/** Adds the extension to project.yaml's extensions, creating no other entries. */
+export function wireProject(projectPath: string, extension: string): void {
+  if (!existsSync(projectConfigPath(projectPath))) return;
+  const config = loadProject(projectPath);
+  const extensions = config.extensions ?? [];
+  if (!extensions.some((e) => e.name === extension)) {
+    saveProject(projectPath, { ...config, extensions: [...extensions, { name: extension }] });
+  }
+}
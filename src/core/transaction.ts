@@ -0,0 +1,190 @@
+import { existsSync, renameSync, rmSync, readFileSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import type { ResolvedType } from '../types/registry.js';
+import { stageType, initSkillRegistry, nameFromPath, installNodeDeps } from './registry.js';
+import { swapStaged, readCurrent, writeCurrent, clearCurrent, removeTree, type FileChanges } from './store.js';
+import { migrateSkillRegistry, describeMigration, type MigrateOptions } from './registry-migrate.js';
+import { prefetchContext } from './context-sources.js';
+import { rebuildContentIndex } from './content-index.js';
+import { getSkillsDir } from './userdata.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('install');
+
+// ── Install transactions ────────────────────────────────────────────
+//
+// An install plan applies several types, and each one may still fail
+// after its files are in place (npm install, registry init). Each type's
+// new tree is staged and flushed next to its destination, then renamed
+// into place, but the tree it replaced is kept aside until the whole
+// plan succeeds. If any step fails, rollback() swaps every type back, in
+// reverse order, removes skill registries the plan created, and puts back
+// registry files an upgrade migrated, so the installed root is never left
+// half-updated.
+
+interface Applied {
+  typePath: string;
+  /** Where the replaced tree was moved; null for a new type. */
+  previous: string | null;
+  previousVersion: string | null;
+}
+
+export class InstallTransaction {
+  private readonly applied: Applied[] = [];
+  private readonly createdRegistries: string[] = [];
+  /** Registry files as they were before migration, by path. */
+  private readonly migratedFiles = new Map<string, string>();
+  private settled = false;
+
+  constructor(private readonly installedRoot: string) {}
+
+  /**
+   * Stages the type and swaps it in. Returns the installed version and
+   * how its files differ from the version it replaced.
+   */
+  install(resolved: ResolvedType): { version: string; changes: FileChanges } {
+    this.assertOpen();
+    const previousVersion = readCurrent(resolved.typePath);
+    const { snapshot, staged, changes } = stageType(resolved, this.installedRoot);
+    const previous = swapStaged(snapshot, staged, this.installedRoot);
+    this.applied.push({ typePath: resolved.typePath, previous, previousVersion });
+    log.info('installed', {
+      type: resolved.typePath,
+      version: snapshot.version,
+      source: snapshot.source,
+      added: changes.added.length,
+      updated: changes.updated.length,
+      removed: changes.removed.length,
+    });
+    return { version: snapshot.version, changes };
+  }
+
+  /**
+   * initSkillRegistry, remembering a registry it creates so rollback
+   * removes it. An existing registry is migrated to the new version (see
+   * core/registry-migrate.ts), and the changes are returned as warnings.
+   */
+  async initSkillRegistry(resolved: ResolvedType, skillsDir: string, opts: MigrateOptions = {}): Promise<string[]> {
+    this.assertOpen();
+    const regDir = join(skillsDir, nameFromPath(resolved.typePath));
+    const existed = existsSync(regDir);
+    if (existed) {
+      for (const file of ['tokens.env', 'config.yaml']) {
+        const path = join(regDir, file);
+        if (existsSync(path) && !this.migratedFiles.has(path)) this.migratedFiles.set(path, readFileSync(path, 'utf-8'));
+      }
+    }
+    const warnings = initSkillRegistry(resolved, skillsDir);
+    if (!existed && existsSync(regDir)) this.createdRegistries.push(regDir);
+    if (existed) {
+      const from = this.applied.find((a) => a.typePath === resolved.typePath)?.previousVersion ?? null;
+      const migration = await migrateSkillRegistry(resolved, skillsDir, from, opts);
+      if (migration) warnings.push(...describeMigration(migration));
+    }
+    return warnings;
+  }
+
+  /** Keeps the new trees and deletes the replaced ones. */
+  commit(): void {
+    this.assertOpen();
+    this.settled = true;
+    for (const a of this.applied) {
+      if (a.previous) removeTree(a.previous);
+    }
+  }
+
+  /**
+   * Restores every type to what it was before the transaction. Returns
+   * the type paths that could not be restored (empty when all were).
+   */
+  rollback(): string[] {
+    if (this.settled) return [];
+    this.settled = true;
+    const failed: string[] = [];
+    for (const a of [...this.applied].reverse()) {
+      const dst = join(this.installedRoot, a.typePath);
+      try {
+        removeTree(dst);
+        if (a.previous) renameSync(a.previous, dst);
+        if (a.previousVersion) writeCurrent(a.typePath, a.previousVersion);
+        else clearCurrent(a.typePath);
+      } catch (err) {
+        log.error('rollback failed', { type: a.typePath, error: String(err) });
+        failed.push(a.typePath);
+      }
+    }
+    for (const dir of this.createdRegistries) rmSync(dir, { recursive: true, force: true });
+    for (const [path, content] of this.migratedFiles) writeFileSync(path, content);
+    log.warn('install rolled back', { types: this.applied.map((a) => a.typePath), failed });
+    return failed;
+  }
+
+  private assertOpen(): void {
+    if (this.settled) throw new Error('Install transaction already committed or rolled back');
+  }
+}
+
+export interface InstallAllOptions extends MigrateOptions {
+  /** Called before each type is installed. */
+  onStart?: (resolved: ResolvedType) => void;
+  /** Called once a type and its steps have succeeded. */
+  onInstalled?: (resolved: ResolvedType, version: string, changes: FileChanges) => void;
+  /** Called with each warning as it happens; they are returned too. */
+  onWarning?: (warning: string) => void;
+}
+
+/** installAll failed on typePath and rolled back; unrestored lists types it couldn't put back. */
+export class InstallError extends Error {
+  constructor(
+    readonly typePath: string,
+    readonly reason: string,
+    readonly unrestored: string[],
+  ) {
+    const restored = unrestored.length ? `; could not restore ${unrestored.join(', ')}` : '; all changes were rolled back';
+    super(`Installing ${typePath} failed: ${reason}${restored}`);
+    this.name = 'InstallError';
+  }
+}
+
+/**
+ * Installs types, dependencies first, in one transaction with the
+ * per-type steps every install takes (npm install, context prefetch,
+ * skill registry). Returns warnings; throws an InstallError after
+ * rolling back.
+ */
+export async function installAll(
+  types: ResolvedType[],
+  installedRoot: string,
+  opts: InstallAllOptions = {},
+): Promise<string[]> {
+  const warnings: string[] = [];
+  const report = (w: string) => {
+    warnings.push(w);
+    opts.onWarning?.(w);
+  };
+  const tx = new InstallTransaction(installedRoot);
+  let current = '';
+  try {
+    for (const resolved of types) {
+      current = resolved.typePath;
+      opts.onStart?.(resolved);
+      const { version, changes } = tx.install(resolved);
+      const npmWarning = installNodeDeps(join(installedRoot, resolved.typePath));
+      if (npmWarning) report(npmWarning);
+      if (resolved.category === 'context') {
+        for (const w of await prefetchContext(resolved.typePath, installedRoot, true)) report(w);
+      }
+      if (resolved.category === 'skill') {
+        for (const w of await tx.initSkillRegistry(resolved, getSkillsDir(), opts)) report(w);
+      }
+      opts.onInstalled?.(resolved, version, changes);
+    }
+    tx.commit();
+  } catch (err) {
+    const unrestored = tx.rollback();
+    throw new InstallError(current, err instanceof Error ? err.message : String(err), unrestored);
+  }
+
+  if (types.some((t) => t.category === 'context')) rebuildContentIndex(installedRoot);
+  return warnings;
+}
@@ -0,0 +1,251 @@
+import { join, relative, resolve, sep, isAbsolute } from 'node:path';
+import { createHash } from 'node:crypto';
+import {
+  readFileSync,
+  writeFileSync,
+  readdirSync,
+  statSync,
+  existsSync,
+  mkdirSync,
+} from 'node:fs';
+import yaml from 'js-yaml';
+import type { CapabilityKind } from '../config/schema.js';
+import { getHomeRoot } from './userdata.js';
+
+// ── Types ───────────────────────────────────────────────────────────
+
+/** What a contribution is: an extension capability, or a skill's registry migration script. */
+export type ContributionKind = CapabilityKind | 'migration';
+
+/**
+ * An executable piece of an extension: a scaffold template set, a hook
+ * command, a detection rule, or a skill's registry migration script.
+ * baseDir is the extension checkout (or skill directory) that path is
+ * relative to.
+ */
+export interface Contribution {
+  extension: string;
+  kind: ContributionKind;
+  name: string;
+  description: string;
+  baseDir: string;
+  path?: string;
+  command?: string;
+  /** The lifecycle event a hook runs on. */
+  event?: string;
+}
+
+export interface TrustEntry {
+  extension: string;
+  kind: ContributionKind;
+  name: string;
+  digest: string;
+  approvedAt: string;
+}
+
+/**
+ * Consent for a project-local extension (.agentx/extensions/<name>),
+ * which arrives with a clone. Keyed by the extension's absolute path and
+ * invalidated by any change to its files.
+ */
+export interface ProjectExtensionTrust {
+  path: string;
+  digest: string;
+  approvedAt: string;
+}
+
+interface TrustStore {
+  entries: TrustEntry[];
+  projectExtensions?: ProjectExtensionTrust[];
+}
+
+const TRUST_FILE = 'trust.yaml';
+
+export function trustStorePath(): string {
+  return join(getHomeRoot(), TRUST_FILE);
+}
+
+// ── Store I/O ───────────────────────────────────────────────────────
+
+function loadStore(): TrustStore {
+  try {
+    const data = yaml.load(readFileSync(trustStorePath(), 'utf-8')) as TrustStore | undefined;
+    return { entries: data?.entries ?? [], projectExtensions: data?.projectExtensions ?? [] };
+  } catch {
+    return { entries: [], projectExtensions: [] };
+  }
+}
+
+function saveStore(store: TrustStore): void {
+  mkdirSync(getHomeRoot(), { recursive: true });
+  writeFileSync(trustStorePath(), yaml.dump(store, { lineWidth: -1 }), { mode: 0o600 });
+}
+
+export function loadTrustStore(): TrustEntry[] {
+  return loadStore().entries;
+}
+
+function saveTrustStore(entries: TrustEntry[]): void {
+  saveStore({ ...loadStore(), entries });
+}
+
+export function loadProjectExtensionTrust(): ProjectExtensionTrust[] {
+  return loadStore().projectExtensions ?? [];
+}
+
+// ── Digests ─────────────────────────────────────────────────────────
+
+function hashTree(hash: ReturnType<typeof createHash>, root: string, dir: string): void {
+  const entries = readdirSync(dir, { withFileTypes: true })
+    .sort((a, b) => a.name.localeCompare(b.name));
+  for (const entry of entries) {
+    if (entry.name === '.git' || entry.name === 'node_modules') continue;
+    const full = join(dir, entry.name);
+    if (entry.isDirectory()) {
+      hashTree(hash, root, full);
+    } else if (entry.isFile()) {
+      hash.update(relative(root, full).split(sep).join('/'));
+      hash.update('\0');
+      hash.update(readFileSync(full));
+      hash.update('\0');
+    }
+  }
+}
+
+/**
+ * Files inside baseDir that a command names, such as the script in
+ * "./hooks/notify.sh" or "bash detect/java.sh". Words that are not
+ * files in the checkout (echo, $VARS, system binaries) are skipped.
+ */
+function commandFiles(command: string, baseDir: string): string[] {
+  const files: string[] = [];
+  for (const word of command.split(/\s+/)) {
+    const token = word.replace(/^['"]|['"]$/g, '');
+    if (!token || token.startsWith('-') || token.includes('$')) continue;
+    const target = resolve(baseDir, token);
+    const rel = relative(baseDir, target);
+    if (!rel || rel.startsWith('..') || isAbsolute(rel)) continue;
+    if (existsSync(target) && statSync(target).isFile()) files.push(target);
+  }
+  return files;
+}
+
+/**
+ * Fingerprints what a contribution would execute. Any change to the
+ * command, to the files it points at, or to a script the command runs
+ * from the checkout invalidates earlier approval.
+ */
+export function contributionDigest(c: Contribution): string {
+  const hash = createHash('sha256');
+  hash.update(`${c.extension}\0${c.kind}\0${c.name}\0${c.command ?? ''}\0`);
+  if (c.command) {
+    for (const file of commandFiles(c.command, c.baseDir)) {
+      hash.update(relative(c.baseDir, file).split(sep).join('/'));
+      hash.update('\0');
+      hash.update(readFileSync(file));
+      hash.update('\0');
+    }
+  }
+  if (c.path) {
+    const target = join(c.baseDir, c.path);
+    if (existsSync(target)) {
+      if (statSync(target).isDirectory()) {
+        hashTree(hash, target, target);
+      } else {
+        hash.update(readFileSync(target));
+      }
+    }
+  }
+  return hash.digest('hex');
+}
+
+/** Fingerprints every file of an extension checkout. */
+export function extensionDigest(dir: string): string {
+  const hash = createHash('sha256');
+  hashTree(hash, dir, dir);
+  return hash.digest('hex');
+}
+
+// ── Consent ─────────────────────────────────────────────────────────
+
+function sameContribution(e: TrustEntry, c: Contribution): boolean {
+  return e.extension === c.extension && e.kind === c.kind && e.name === c.name;
+}
+
+export function isTrusted(c: Contribution): boolean {
+  const digest = contributionDigest(c);
+  return loadTrustStore().some((e) => sameContribution(e, c) && e.digest === digest);
+}
+
+export function recordTrust(c: Contribution): TrustEntry {
+  const entry: TrustEntry = {
+    extension: c.extension,
+    kind: c.kind,
+    name: c.name,
+    digest: contributionDigest(c),
+    approvedAt: new Date().toISOString(),
+  };
+  const entries = loadTrustStore().filter((e) => !sameContribution(e, c));
+  entries.push(entry);
+  saveTrustStore(entries);
+  return entry;
+}
+
+/** Removes approvals for an extension, or one named contribution of it. */
+export function revokeTrust(extension: string, name?: string): number {
+  const entries = loadTrustStore();
+  const kept = entries.filter(
+    (e) => e.extension !== extension || (name !== undefined && e.name !== name),
+  );
+  saveTrustStore(kept);
+  return entries.length - kept.length;
+}
+
+/** Whether the project-local extension at dir was trusted as it is now. */
+export function isProjectExtensionTrusted(dir: string): boolean {
+  const path = resolve(dir);
+  const entry = loadProjectExtensionTrust().find((e) => e.path === path);
+  return entry !== undefined && entry.digest === extensionDigest(path);
+}
+
+/** Records consent to load the project-local extension at dir as it is now. */
+export function trustProjectExtension(dir: string): ProjectExtensionTrust {
+  const path = resolve(dir);
+  const entry: ProjectExtensionTrust = { path, digest: extensionDigest(path), approvedAt: new Date().toISOString() };
+  const store = loadStore();
+  saveStore({ ...store, projectExtensions: [...(store.projectExtensions ?? []).filter((e) => e.path !== path), entry] });
+  return entry;
+}
+
+export function describeContribution(c: Contribution): string {
+  const lines = [
+    c.kind === 'migration'
+      ? `Source "${c.extension}" wants to run a registry migration script for ${c.name}`
+      : `Extension "${c.extension}" wants to run a ${c.kind}: ${c.name}`,
+  ];
+  if (c.description) lines.push(`  ${c.description}`);
+  if (c.command) lines.push(`  Command: ${c.command}`);
+  if (c.event) lines.push(`  On:      ${c.event}`);
+  if (c.path) lines.push(`  Files:   ${join(c.baseDir, c.path)}`);
+  lines.push(`  Runs from: ${c.baseDir}`);
+  return lines.join('\n');
+}
+
+/**
+ * Gate for executing an extension contribution. Approved contributions
+ * pass silently; otherwise approve() decides (typically an interactive
+ * confirm) and a yes is remembered in the trust store.
+ */
+export async function ensureTrusted(
+  c: Contribution,
+  approve: (c: Contribution) => Promise<boolean>,
+): Promise<void> {
+  if (isTrusted(c)) return;
+  if (!(await approve(c))) {
+    throw new Error(
+      `${c.kind} "${c.name}" from extension "${c.extension}" is not trusted. ` +
+        'Run the command interactively to review and approve it.',
+    );
+  }
+  recordTrust(c);
+}
@@ -0,0 +1,131 @@
+import { join, dirname } from 'node:path';
+import { tmpdir } from 'node:os';
+import { mkdtempSync, mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { envVar } from '../config/branding.js';
+
+// ── Sample catalog ──────────────────────────────────────────────────
+//
+// A miniature catalog exercising every link of the type model: a prompt
+// composes a persona, which pulls in context, plus a runnable skill.
+
+export const TUTORIAL_PROMPT = 'prompts/tutorial/code-review';
+export const TUTORIAL_PERSONA = 'personas/tutorial-reviewer';
+export const TUTORIAL_CONTEXT = 'context/tutorial/team-conventions';
+export const TUTORIAL_SKILL = 'skills/tutorial/word-count';
+
+const SAMPLE_FILES: Record<string, string> = {
+  [`${TUTORIAL_CONTEXT}/manifest.yaml`]: `name: team-conventions
+type: context
+version: "1.0.0"
+description: Coding conventions for the tutorial team
+format: markdown
+sources:
+  - conventions.md
+`,
+  [`${TUTORIAL_CONTEXT}/conventions.md`]: `- Prefer small, focused functions.
+- Every public function has a doc comment.
+- Errors are returned to the caller, never swallowed.
+`,
+  [`${TUTORIAL_PERSONA}/manifest.yaml`]: `name: tutorial-reviewer
+type: persona
+version: "1.0.0"
+description: A friendly reviewer who explains the why behind each comment
+expertise:
+  - code review
+  - readability
+tone: encouraging
+conventions:
+  - Point out one strength before any criticism
+context:
+  - ${TUTORIAL_CONTEXT}
+`,
+  [`${TUTORIAL_SKILL}/manifest.yaml`]: `name: word-count
+type: skill
+version: "1.0.0"
+description: Counts words and characters in a piece of text
+runtime: node
+topic: tutorial
+inputs:
+  - name: text
+    type: string
+    required: true
+    description: Text to measure
+`,
+  [`${TUTORIAL_SKILL}/index.mjs`]: `const [, , command, raw] = process.argv;
+if (command !== 'run') {
+  console.error('usage: index.mjs run <json-args>');
+  process.exit(2);
+}
+const { text = '' } = JSON.parse(raw ?? '{}');
+const words = text.trim() ? text.trim().split(/\\s+/).length : 0;
+console.log(JSON.stringify({ words, characters: text.length }, null, 2));
+`,
+  [`${TUTORIAL_PROMPT}/manifest.yaml`]: `name: code-review
+type: prompt
+version: "1.0.0"
+description: Review a change the way the tutorial team likes it
+persona: ${TUTORIAL_PERSONA}
+context:
+  - ${TUTORIAL_CONTEXT}
+skills:
+  - ${TUTORIAL_SKILL}
+`,
+};
+
+// ── Sandbox ─────────────────────────────────────────────────────────
+
+export interface Sandbox {
+  root: string;
+  home: string;
+  /** Catalog checkout; types live under catalog/ inside it. */
+  catalogRepo: string;
+  project: string;
+}
+
+const SANDBOX_ENV = ['HOME', 'INSTALLED', 'USERDATA', 'CATALOG', 'EXTENSIONS'];
+
+/** Creates a throwaway AgentX home, sample catalog, and empty project. */
+export function createSandbox(): Sandbox {
+  const root = mkdtempSync(join(tmpdir(), 'agentx-tutorial-'));
+  const sandbox: Sandbox = {
+    root,
+    home: join(root, 'home'),
+    catalogRepo: join(root, 'catalog-repo'),
+    project: join(root, 'my-project'),
+  };
+
+  for (const [rel, content] of Object.entries(SAMPLE_FILES)) {
+    const path = join(sandbox.catalogRepo, 'catalog', rel);
+    mkdirSync(dirname(path), { recursive: true });
+    writeFileSync(path, content);
+  }
+  mkdirSync(sandbox.home, { recursive: true });
+  mkdirSync(sandbox.project, { recursive: true });
+  return sandbox;
+}
+
+/**
+ * Points every AgentX path at the sandbox so the tutorial can use the
+ * real commands' code without touching the user's setup. Returns a
+ * function restoring the previous environment.
+ */
+export function enterSandbox(sandbox: Sandbox): () => void {
+  const saved = new Map(SANDBOX_ENV.map((k) => [envVar(k), process.env[envVar(k)]]));
+
+  process.env[envVar('HOME')] = sandbox.home;
+  process.env[envVar('INSTALLED')] = join(sandbox.home, 'installed');
+  process.env[envVar('USERDATA')] = join(sandbox.home, 'userdata');
+  process.env[envVar('CATALOG')] = sandbox.catalogRepo;
+  process.env[envVar('EXTENSIONS')] = join(sandbox.home, 'extensions');
+
+  return () => {
+    for (const [key, value] of saved) {
+      if (value === undefined) delete process.env[key];
+      else process.env[key] = value;
+    }
+  };
+}
+
+export function removeSandbox(sandbox: Sandbox): void {
+  rmSync(sandbox.root, { recursive: true, force: true });
+}
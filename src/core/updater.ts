@@ -1,5 +1,9 @@
 import { execFileSync } from 'node:child_process';
 import { NPM_PACKAGE } from '../config/branding.js';
+import { isOffline, requireOnline } from './offline.js';
+import { childEnv, timeoutFor, tlsHint } from '../utils/http.js';
+
+const NPM_REGISTRY = 'https://registry.npmjs.org/';
 
 declare const __VERSION__: string;
 
@@ -8,21 +12,31 @@ export function currentVersion(): string {
 }
 
 export async function checkForUpdate(): Promise<string | null> {
+  if (isOffline()) return null;
+  let latest: string;
   try {
-    const latest = execFileSync('npm', ['view', NPM_PACKAGE, 'version'], {
+    latest = execFileSync('npm', ['view', NPM_PACKAGE, 'version'], {
       encoding: 'utf-8',
+      env: childEnv(),
+      timeout: timeoutFor(NPM_REGISTRY),
+      stdio: ['ignore', 'pipe', 'pipe'],
     }).trim();
-
-    if (latest && latest !== currentVersion()) {
-      return latest;
-    }
-    return null;
-  } catch {
-    return null;
+  } catch (err) {
+    throw npmError('Checking for updates', err);
   }
+  return latest && latest !== currentVersion() ? latest : null;
+}
+
+/** The npm error line from a failed call, with a hint for certificate failures. */
+function npmError(what: string, err: unknown): Error {
+  const stderr = String((err as { stderr?: string | Buffer }).stderr ?? '').trim();
+  const detail = stderr.split('\n').find((l) => /npm (ERR!|error)/.test(l)) ?? (err as Error).message;
+  const hint = tlsHint(stderr);
+  return new Error(`${what} failed: ${detail}${hint ? `\n${hint}` : ''}`, { cause: err });
 }
 
 export async function update(version?: string): Promise<void> {
+  requireOnline('Updating the CLI');
   const pkg = version ? `${NPM_PACKAGE}@${version}` : NPM_PACKAGE;
-  execFileSync('npm', ['install', '-g', pkg], { stdio: 'inherit' });
+  execFileSync('npm', ['install', '-g', pkg], { stdio: 'inherit', env: childEnv() });
 }
@@ -1,15 +1,20 @@
 import { homedir } from 'node:os';
-import { join } from 'node:path';
+import { join, dirname, resolve } from 'node:path';
 import {
   readFileSync,
   writeFileSync,
   readdirSync,
   mkdirSync,
   existsSync,
+  lstatSync,
+  chmodSync,
+  renameSync,
+  rmSync,
+  rmdirSync,
 } from 'node:fs';
 import yaml from 'js-yaml';
 import { HOME_DIR, envVar } from '../config/branding.js';
-import { createSymlink, readSymlinkTarget } from '../utils/platform.js';
+import { createSymlink, readSymlinkTarget, removeSymlink, isSymlink } from '../utils/platform.js';
 import { ensureDir, fileExists } from '../utils/fs.js';
 
 // ── Directory constants ─────────────────────────────────────────────
@@ -26,6 +31,15 @@ const DEFAULT_PROFILE_FILE = 'default.yaml';
 const CATALOG_REPO_DIR = 'catalog-repo';
 const CATALOG_DIR = 'catalog';
 const EXTENSIONS_DIR = 'extensions';
+const CACHE_DIR = 'cache';
+const STORE_DIR = 'store';
+const CREDENTIALS_FILE = 'credentials.yaml';
+const DEBUG_LOG_FILE = 'debug.log';
+const LOGS_DIR = 'logs';
+const STATS_FILE = 'stats.json';
+const TELEMETRY_OUTBOX_FILE = 'telemetry-outbox.jsonl';
+const SCAFFOLD_TEMPLATES_DIR = 'templates';
+const ARCHIVE_DIR = 'archive';
 
 const DIR_PERM_SECURE = 0o700;
 const DIR_PERM_NORMAL = 0o755;
@@ -89,6 +103,41 @@ export function getExtensionsRoot(): string {
   return process.env[envVar('EXTENSIONS')] ?? join(getHomeRoot(), EXTENSIONS_DIR);
 }
 
+export function getCacheDir(): string {
+  return join(getHomeRoot(), CACHE_DIR);
+}
+
+export function getStoreRoot(): string {
+  return join(getHomeRoot(), STORE_DIR);
+}
+
+export function getCredentialsPath(): string {
+  return join(getHomeRoot(), CREDENTIALS_FILE);
+}
+
+/** User-defined scaffold template sets for `agentx create --template`. */
+export function getScaffoldTemplatesDir(): string {
+  return join(getHomeRoot(), SCAFFOLD_TEMPLATES_DIR);
+}
+
+export function getDebugLogPath(): string {
+  return join(getHomeRoot(), DEBUG_LOG_FILE);
+}
+
+export function getLogsDir(): string {
+  return join(getHomeRoot(), LOGS_DIR);
+}
+
+/** Local usage stats, kept when telemetry is on or local-only. */
+export function getStatsPath(): string {
+  return join(getHomeRoot(), STATS_FILE);
+}
+
+/** Telemetry events waiting to be sent. */
+export function getTelemetryOutboxPath(): string {
+  return join(getHomeRoot(), TELEMETRY_OUTBOX_FILE);
+}
+
 export function getConfigDir(): string {
   return getHomeRoot();
 }
@@ -97,6 +146,11 @@ export function getConfigPath(): string {
   return join(getConfigDir(), 'config.yaml');
 }
 
+/** Project settings, layered over the user's config.yaml. */
+export function getProjectConfigPath(projectPath: string): string {
+  return join(projectPath, '.agentx', 'config.yaml');
+}
+
 export function catalogExists(): boolean {
   const root = getCatalogRoot();
   if (!existsSync(root)) return false;
@@ -156,8 +210,7 @@ export function switchProfile(name: string): void {
   }
   const linkPath = join(profilesDir, ACTIVE_PROFILE_LINK);
   try {
-    const { unlinkSync } = require('node:fs') as typeof import('node:fs');
-    unlinkSync(linkPath);
+    removeSymlink(linkPath);
   } catch {
     // Link doesn't exist yet
   }
@@ -202,9 +255,23 @@ export function listEnvFiles(): { shared: string[]; skillSpecific: string[] } {
   return { shared, skillSpecific };
 }
 
-export function resolveEnvTarget(target: string): string {
+const ACCOUNT_NAME = /^[a-z0-9._-]+$/;
+
+/** Returns name if it can be an account; it becomes part of a file name (tokens.<account>.env). */
+export function checkAccountName(name: string): string {
+  if (!ACCOUNT_NAME.test(name)) {
+    throw new Error(`Invalid account name "${name}" (use lowercase letters, digits, ".", "_", and "-")`);
+  }
+  return name;
+}
+
+/** A shared env file, or a skill's tokens.env (tokens.<account>.env for an account). */
+export function resolveEnvTarget(target: string, account?: string): string {
   if (target.includes('/')) {
-    return join(getSkillsDir(), target, 'tokens.env');
+    return join(getSkillsDir(), target, account ? `tokens.${checkAccountName(account)}.env` : 'tokens.env');
+  }
+  if (account) {
+    throw new Error(`--account applies to skill token files (e.g. scm/git/commit-analyzer), not shared env "${target}"`);
   }
   return join(getEnvDir(), `${target}.env`);
 }
@@ -270,7 +337,8 @@ export function initGlobal(log: (msg: string) => void): void {
 
   // Create active profile symlink
   const linkPath = join(profilesDir, ACTIVE_PROFILE_LINK);
-  if (!existsSync(linkPath)) {
+  // A dangling link is left for doctor --fix to repoint
+  if (!existsSync(linkPath) && !isSymlink(linkPath)) {
     createSymlink(join(profilesDir, DEFAULT_PROFILE_FILE), linkPath);
     log(`  Created: ${linkPath}`);
   }
@@ -294,3 +362,211 @@ function ensureLogFile(
     log(`  Created: ${path}`);
   }
 }
+
+// ── Reconciliation ──────────────────────────────────────────────────
+//
+// `doctor --check-userdata` compares the tree with what initGlobal
+// creates and what installed skills use, and `doctor --fix` repairs it:
+//
+//   - missing directories and default files are recreated;
+//   - a missing or dangling active profile link is pointed at
+//     default.yaml, else the first profile;
+//   - env/, profiles/, and token files readable by others are made
+//     owner-only again (directories 0700, files 0600);
+//   - skill registries whose skill is no longer installed are orphans,
+//     archived under userdata/archive/ or deleted only when asked.
+
+export type UserdataIssueKind = 'missing' | 'active-profile' | 'permissions' | 'orphan';
+
+export interface UserdataIssue {
+  kind: UserdataIssueKind;
+  path: string;
+  detail: string;
+  /** The registry's skill path, for orphans. */
+  skillPath?: string;
+}
+
+export type OrphanAction = 'archive' | 'delete' | 'keep';
+
+export const ORPHAN_ACTIONS: OrphanAction[] = ['archive', 'delete', 'keep'];
+
+// Subdirectories initSkillRegistry creates
+const REGISTRY_DIRS = new Set(['state', 'output', 'templates']);
+const TOKEN_FILE = /^tokens(\.[\w-]+)?\.env$/;
+
+export function getArchiveDir(): string {
+  return join(getUserdataRoot(), ARCHIVE_DIR);
+}
+
+/**
+ * Skill registries under skills/, as skill paths. A registry is the
+ * first directory holding files or registry subdirectories, or an empty
+ * leaf below a topic.
+ */
+export function listSkillRegistries(): string[] {
+  const root = getSkillsDir();
+  const found: string[] = [];
+  const walk = (rel: string, depth: number) => {
+    let entries;
+    try {
+      entries = readdirSync(join(root, rel), { withFileTypes: true });
+    } catch {
+      return;
+    }
+    const isRegistry = entries.some((e) => e.isFile() || (e.isDirectory() && REGISTRY_DIRS.has(e.name)));
+    if (depth >= 2 && (isRegistry || entries.length === 0)) {
+      found.push(rel);
+      return;
+    }
+    for (const e of entries) {
+      if (e.isDirectory()) walk(rel ? `${rel}/${e.name}` : e.name, depth + 1);
+    }
+  };
+  walk('', 0);
+  return found.sort();
+}
+
+function activeProfileIssue(): UserdataIssue | null {
+  const profilesDir = getProfilesDir();
+  if (!existsSync(profilesDir)) return null; // Reported as missing
+  const link = join(profilesDir, ACTIVE_PROFILE_LINK);
+  let target: string;
+  try {
+    target = readSymlinkTarget(link);
+  } catch {
+    return { kind: 'active-profile', path: link, detail: 'active profile link is missing' };
+  }
+  if (!existsSync(resolve(profilesDir, target))) {
+    return { kind: 'active-profile', path: link, detail: `active profile link points at missing ${target}` };
+  }
+  return null;
+}
+
+/** Group- or world-accessible paths that should be owner-only. */
+function loosePermissions(): UserdataIssue[] {
+  if (process.platform === 'win32') return [];
+  const issues: UserdataIssue[] = [];
+  const check = (path: string, want: number) => {
+    const st = lstatSync(path);
+    if (!st.isSymbolicLink() && (st.mode & 0o077) !== 0) {
+      const mode = (st.mode & 0o777).toString(8);
+      issues.push({ kind: 'permissions', path, detail: `mode ${mode}, expected ${want.toString(8)}` });
+    }
+  };
+  for (const dir of [getEnvDir(), getProfilesDir()]) {
+    if (!existsSync(dir)) continue;
+    check(dir, DIR_PERM_SECURE);
+    for (const e of readdirSync(dir, { withFileTypes: true })) {
+      if (e.isFile()) check(join(dir, e.name), FILE_PERM_SECURE);
+    }
+  }
+  for (const rel of listSkillRegistries()) {
+    const dir = join(getSkillsDir(), rel);
+    for (const e of readdirSync(dir, { withFileTypes: true })) {
+      if (e.isFile() && TOKEN_FILE.test(e.name)) check(join(dir, e.name), FILE_PERM_SECURE);
+    }
+  }
+  return issues;
+}
+
+/** Everything doctor --fix would repair, in repair order. */
+export function checkUserdata(installedRoot = getInstalledRoot()): UserdataIssue[] {
+  const issues: UserdataIssue[] = [];
+  for (const [path, what] of [
+    [getUserdataRoot(), 'userdata directory'],
+    [getEnvDir(), 'env directory'],
+    [getProfilesDir(), 'profiles directory'],
+    [getSkillsDir(), 'skills directory'],
+  ] as const) {
+    if (!existsSync(path)) issues.push({ kind: 'missing', path, detail: `${what} is missing` });
+  }
+  const profile = activeProfileIssue();
+  if (profile) issues.push(profile);
+  issues.push(...loosePermissions());
+  for (const rel of listSkillRegistries()) {
+    if (!existsSync(join(installedRoot, 'skills', rel))) {
+      issues.push({
+        kind: 'orphan',
+        path: join(getSkillsDir(), rel),
+        detail: `registry for skills/${rel}, which is not installed`,
+        skillPath: `skills/${rel}`,
+      });
+    }
+  }
+  return issues;
+}
+
+function restoreActiveProfile(): string {
+  const profilesDir = getProfilesDir();
+  const link = join(profilesDir, ACTIVE_PROFILE_LINK);
+  try {
+    removeSymlink(link);
+  } catch {
+    // Link was missing
+  }
+  const profiles = listProfiles();
+  const name = profiles.includes('default') ? 'default' : profiles[0];
+  if (!name) writeFileSync(join(profilesDir, DEFAULT_PROFILE_FILE), DEFAULT_PROFILE_CONTENT, { mode: FILE_PERM_SECURE });
+  const target = join(profilesDir, `${name ?? 'default'}.yaml`);
+  createSymlink(target, link);
+  return `Pointed the active profile at ${target}`;
+}
+
+/** Removes now-empty directories from dir up to, not including, stop. */
+function pruneEmpty(dir: string, stop: string): void {
+  for (let d = dir; d.startsWith(stop + '/') && d !== stop; d = dirname(d)) {
+    try {
+      rmdirSync(d);
+    } catch {
+      return; // Not empty
+    }
+  }
+}
+
+function removeOrphan(issue: UserdataIssue, action: OrphanAction): string {
+  const rel = issue.skillPath!.replace(/^skills\//, '');
+  if (action === 'keep') return `Kept orphaned registry ${rel}`;
+  if (action === 'delete') {
+    rmSync(issue.path, { recursive: true, force: true });
+    pruneEmpty(dirname(issue.path), getSkillsDir());
+    return `Deleted orphaned registry ${rel}`;
+  }
+  const stamp = new Date().toISOString().replace(/[:.]/g, '-');
+  const dest = join(getArchiveDir(), SKILLS_DIR, `${rel}-${stamp}`);
+  mkdirSync(dirname(dest), { recursive: true, mode: DIR_PERM_SECURE });
+  renameSync(issue.path, dest);
+  pruneEmpty(dirname(issue.path), getSkillsDir());
+  return `Archived orphaned registry ${rel} to ${dest}`;
+}
+
+/**
+ * Repairs issues from checkUserdata and describes each repair. Orphaned
+ * registries are only touched as orphanAction says (default: kept).
+ */
+export function repairUserdata(
+  issues: UserdataIssue[],
+  orphanAction: (issue: UserdataIssue) => OrphanAction = () => 'keep',
+): string[] {
+  const done: string[] = [];
+  if (issues.some((i) => i.kind === 'missing')) {
+    initGlobal((msg) => done.push(msg.trim()));
+  }
+  for (const issue of issues) {
+    switch (issue.kind) {
+      case 'active-profile':
+        // initGlobal already created a missing link
+        if (activeProfileIssue()) done.push(restoreActiveProfile());
+        break;
+      case 'permissions': {
+        const dir = lstatSync(issue.path).isDirectory();
+        chmodSync(issue.path, dir ? DIR_PERM_SECURE : FILE_PERM_SECURE);
+        done.push(`Restricted ${issue.path} to ${(dir ? DIR_PERM_SECURE : FILE_PERM_SECURE).toString(8)}`);
+        break;
+      }
+      case 'orphan':
+        done.push(removeOrphan(issue, orphanAction(issue)));
+        break;
+    }
+  }
+  return done;
+}
@@ -0,0 +1,216 @@
+import { join, normalize, isAbsolute, sep, posix } from 'node:path';
+import { execFileSync } from 'node:child_process';
+import * as zlib from 'node:zlib';
+import { readFileSync, writeFileSync, existsSync, mkdirSync, renameSync, rmSync, readdirSync, realpathSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { logger } from '../utils/log.js';
+
+const log = logger('vendored-deps');
+
+// ── Vendored dependencies ───────────────────────────────────────────
+//
+// A Node skill for air-gapped machines can ship its dependencies:
+//
+//   vendored_deps: node_modules.tar.zst
+//
+// The archive sits next to the manifest and holds a top-level
+// node_modules/ directory (`tar -cf - node_modules | zstd`). Install
+// unpacks it instead of running npm, so no registry access is needed,
+// offline or not. .tar.gz and .tgz archives work too. zstd is decoded
+// by Node where it can be (22.15 and later), and by the zstd command
+// otherwise.
+//
+// Archives come from catalogs, so every member is checked before tar
+// runs: no absolute or ../ paths, no devices, no symlinks leading out
+// of the archive, and nothing written through a symlink. After
+// unpacking, symlinks are resolved once more before node_modules/ moves
+// into place.
+
+const MANIFEST_NAMES = ['manifest.yaml', 'manifest.json', 'skill.yaml', 'workflow.yaml'];
+const ARCHIVE_PATTERN = /\.(tar\.zst|tar\.gz|tgz)$/;
+const STAGING_DIR = '.node_modules.partial';
+
+/** Rejects archive paths that are absolute, leave the type, or aren't tarballs. */
+export function checkVendoredPath(path: string): string | null {
+  if (isAbsolute(path) || normalize(path).split(sep).includes('..')) {
+    return `vendored_deps must be a path inside the type: ${path}`;
+  }
+  if (!ARCHIVE_PATTERN.test(path)) return `vendored_deps must be a .tar.zst, .tar.gz, or .tgz archive: ${path}`;
+  return null;
+}
+
+/** The vendored archive a type's manifest declares, or null. */
+export function vendoredArchive(typeDir: string): string | null {
+  const name = MANIFEST_NAMES.find((n) => existsSync(join(typeDir, n)));
+  if (!name) return null;
+  const manifest = yaml.load(readFileSync(join(typeDir, name), 'utf-8')) as { vendored_deps?: string } | undefined;
+  const path = manifest?.vendored_deps;
+  if (!path) return null;
+  const problem = checkVendoredPath(path);
+  if (problem) throw new Error(problem);
+  const archive = join(typeDir, path);
+  if (!existsSync(archive)) throw new Error(`Vendored dependencies not found: ${archive}`);
+  return archive;
+}
+
+type ZstdZlib = typeof zlib & { zstdDecompressSync?: (buf: Buffer) => Buffer };
+
+/** Decompresses archive into the plain tar file at tarPath. */
+function decompress(archive: string, tarPath: string): void {
+  if (!archive.endsWith('.tar.zst')) {
+    writeFileSync(tarPath, zlib.gunzipSync(readFileSync(archive)));
+    return;
+  }
+  const zstd = (zlib as ZstdZlib).zstdDecompressSync;
+  if (zstd) {
+    writeFileSync(tarPath, zstd(readFileSync(archive)));
+  } else {
+    execFileSync('zstd', ['-d', '-q', '-f', '-o', tarPath, archive], { stdio: 'ignore' });
+  }
+}
+
+interface TarMember {
+  name: string;
+  /** ustar typeflag: '0' file, '1' hard link, '2' symlink, '5' directory, ... */
+  type: string;
+  linkname: string;
+}
+
+function parsePax(body: Buffer): Record<string, string> {
+  const fields: Record<string, string> = {};
+  let off = 0;
+  while (off < body.length) {
+    const space = body.indexOf(0x20, off);
+    const len = space === -1 ? 0 : Number(body.subarray(off, space).toString());
+    if (!len) break;
+    const record = body.subarray(space + 1, off + len - 1).toString('utf-8');
+    const eq = record.indexOf('=');
+    if (eq > 0) fields[record.slice(0, eq)] = record.slice(eq + 1);
+    off += len;
+  }
+  return fields;
+}
+
+/** A tar's members, with pax and GNU long names applied. */
+function tarMembers(tar: Buffer): TarMember[] {
+  const members: TarMember[] = [];
+  let pax: Record<string, string> = {};
+  let longName: string | null = null;
+  let longLink: string | null = null;
+  for (let off = 0; off + 512 <= tar.length; ) {
+    const header = tar.subarray(off, off + 512);
+    if (header.every((b) => b === 0)) break;
+    const field = (start: number, len: number) => {
+      const raw = header.subarray(start, start + len);
+      const end = raw.indexOf(0);
+      return raw.subarray(0, end === -1 ? len : end).toString('utf-8');
+    };
+    const size = parseInt(field(124, 12).trim() || '0', 8);
+    const type = field(156, 1) || '0';
+    const body = tar.subarray(off + 512, off + 512 + size);
+    off += 512 + Math.ceil(size / 512) * 512;
+
+    if (type === 'x') pax = parsePax(body);
+    else if (type === 'L') longName = body.toString('utf-8').replace(/\0+$/, '');
+    else if (type === 'K') longLink = body.toString('utf-8').replace(/\0+$/, '');
+    else if (type !== 'g') {
+      // Only POSIX ustar headers have a name prefix; GNU ones keep times there
+      const prefix = header.subarray(257, 263).toString('latin1') === 'ustar\0' ? field(345, 155) : '';
+      const name = pax.path ?? longName ?? (prefix ? `${prefix}/${field(0, 100)}` : field(0, 100));
+      members.push({ name, type, linkname: pax.linkpath ?? longLink ?? field(157, 100) });
+      pax = {};
+      longName = longLink = null;
+    }
+  }
+  return members;
+}
+
+/** A member path normalized, or null when it is absolute or leaves the archive. */
+function memberPath(path: string): string | null {
+  if (posix.isAbsolute(path)) return null;
+  const normal = posix.normalize(path).replace(/\/$/, '');
+  return normal.split('/').includes('..') ? null : normal;
+}
+
+const MEMBER_TYPES = new Set(['0', '1', '2', '5', '7']);
+
+/** Throws for the first member that could write outside the archive. */
+function checkMembers(members: TarMember[]): void {
+  const symlinks = new Set<string>();
+  for (const m of members) {
+    const unsafe = (why: string) => new Error(`${m.name} ${why}`);
+    const name = memberPath(m.name);
+    if (name === null) throw unsafe('is outside the archive');
+    if (!MEMBER_TYPES.has(m.type)) throw unsafe('is not a file, directory, or link');
+    const parts = name.split('/');
+    for (let i = 1; i < parts.length; i++) {
+      const parent = parts.slice(0, i).join('/');
+      if (symlinks.has(parent)) throw unsafe(`is written through the symlink ${parent}`);
+    }
+    if (m.type === '1' && memberPath(m.linkname) === null) throw unsafe(`links to ${m.linkname}, outside the archive`);
+    if (m.type === '2') {
+      if (posix.isAbsolute(m.linkname) || memberPath(posix.join(posix.dirname(name), m.linkname)) === null) {
+        throw unsafe(`links to ${m.linkname}, outside the archive`);
+      }
+      symlinks.add(name);
+    }
+  }
+}
+
+/** Throws when a symlink under dir resolves outside root (through other symlinks, say). */
+function checkSymlinks(dir: string, root: string): void {
+  for (const entry of readdirSync(dir, { withFileTypes: true })) {
+    const path = join(dir, entry.name);
+    if (entry.isSymbolicLink()) {
+      let target: string;
+      try {
+        target = realpathSync(path);
+      } catch {
+        continue; // Dangling, and its own target was checked before unpacking
+      }
+      if (target !== root && !target.startsWith(root + sep)) {
+        throw new Error(`${path} links outside the archive, to ${target}`);
+      }
+    } else if (entry.isDirectory()) {
+      checkSymlinks(path, root);
+    }
+  }
+}
+
+function extract(archive: string, dest: string): void {
+  const tarPath = join(dest, '.deps.tar');
+  try {
+    decompress(archive, tarPath);
+    checkMembers(tarMembers(readFileSync(tarPath)));
+    execFileSync('tar', ['-xf', tarPath, '-C', dest], { stdio: 'ignore' });
+  } finally {
+    rmSync(tarPath, { force: true });
+  }
+  checkSymlinks(dest, realpathSync(dest));
+}
+
+/**
+ * Unpacks the archive's node_modules/ into typeDir, replacing any there.
+ * The archive is unpacked beside it first, so a bad archive leaves the
+ * type as it was.
+ */
+export function unpackVendoredDeps(typeDir: string, archive: string): void {
+  const staging = join(typeDir, STAGING_DIR);
+  rmSync(staging, { recursive: true, force: true });
+  mkdirSync(staging, { recursive: true });
+  try {
+    try {
+      extract(archive, staging);
+    } catch (err) {
+      throw new Error(`Cannot unpack ${archive}: ${err instanceof Error ? err.message : String(err)}`);
+    }
+    const modules = join(staging, 'node_modules');
+    if (!existsSync(modules)) throw new Error(`${archive} has no top-level node_modules/ directory`);
+    const target = join(typeDir, 'node_modules');
+    rmSync(target, { recursive: true, force: true });
+    renameSync(modules, target);
+    log.debug('unpacked', { dir: typeDir, archive });
+  } finally {
+    rmSync(staging, { recursive: true, force: true });
+  }
+}
@@ -0,0 +1,230 @@
+import { join, relative, sep } from 'node:path';
+import { existsSync, readFileSync } from 'node:fs';
+import { ProjectFileSchema } from '../config/schema.js';
+import type { Source } from '../types/registry.js';
+import { loadProject, projectConfigPath, projectExtensionsDir, previewSync, checkPins, type ProjectConfig } from './linker.js';
+import { validateYamlFile, type ManifestIssue } from './manifest.js';
+import { validateSource } from './source-lint.js';
+import { buildSources } from './extension.js';
+import { resolveType, isPinned, installedTypePaths } from './registry.js';
+import { verifyType } from './integrity.js';
+import { pinnedTypeDirs } from './versions.js';
+
+// ── CI verification ─────────────────────────────────────────────────
+//
+// `agentx verify --ci` runs every check a CI job needs on a project and
+// reports them together, with an exit code that depends only on whether
+// a check failed:
+//
+//   project      .agentx/project.yaml matches its schema
+//   manifests    types in project-local extensions validate (strict)
+//   links        generated tool files match what link sync would write
+//   pins         active types are installed, at their pinned versions
+//   lockfiles    linked Node skills have a package-lock.json that
+//                matches the dependencies in their package.json
+//   extensions   sources named in project.yaml exist, and prefer: pins
+//                point at sources that provide the types
+//   integrity    installed types match what was installed
+//
+// Outside a project every project check is skipped, so only integrity
+// runs. Checks that need a readable project.yaml are skipped when it
+// isn't.
+
+export const VERIFY_CHECKS = ['project', 'manifests', 'links', 'pins', 'lockfiles', 'extensions', 'integrity'] as const;
+export type VerifyCheckName = (typeof VERIFY_CHECKS)[number];
+
+export type VerifyStatus = 'pass' | 'fail' | 'skip';
+
+export interface VerifyFailure {
+  /** What failed: a file, type path, or source. */
+  subject: string;
+  message: string;
+  file?: string;
+  line?: number;
+}
+
+export interface VerifyCheck {
+  name: VerifyCheckName;
+  title: string;
+  status: VerifyStatus;
+  /** Why a check was skipped, or what a passing check covered. */
+  detail?: string;
+  failures: VerifyFailure[];
+}
+
+export interface VerifyReport {
+  project: string;
+  /** False when any check failed; skipped checks don't count. */
+  passed: boolean;
+  checks: VerifyCheck[];
+}
+
+const TITLES: Record<VerifyCheckName, string> = {
+  project: 'Project config',
+  manifests: 'Local type manifests',
+  links: 'Generated tool files',
+  pins: 'Active types and pins',
+  lockfiles: 'Skill lockfiles',
+  extensions: 'Extension sources and pins',
+  integrity: 'Installed type integrity',
+};
+
+function result(name: VerifyCheckName, failures: VerifyFailure[], detail?: string): VerifyCheck {
+  const sorted = [...failures].sort((a, b) => a.subject.localeCompare(b.subject) || a.message.localeCompare(b.message));
+  return { name, title: TITLES[name], status: sorted.length ? 'fail' : 'pass', ...(detail ? { detail } : {}), failures: sorted };
+}
+
+function skipped(name: VerifyCheckName, detail: string): VerifyCheck {
+  return { name, title: TITLES[name], status: 'skip', detail, failures: [] };
+}
+
+function issueFailure(projectPath: string, issue: ManifestIssue): VerifyFailure {
+  const file = relative(projectPath, issue.file);
+  const where = issue.path ? ` (at ${issue.path})` : '';
+  return { subject: file, message: `${issue.message}${where}`, file, line: issue.line };
+}
+
+function activeTypes(config: ProjectConfig): string[] {
+  return Object.values(config.active).flatMap((refs) => refs ?? []);
+}
+
+function checkManifests(projectPath: string, sources: Source[]): VerifyCheck {
+  const localRoot = projectExtensionsDir(projectPath);
+  const local = sources.filter((s) => s.basePath === localRoot || s.basePath.startsWith(localRoot + sep));
+  if (local.length === 0) return skipped('manifests', 'no project-local extensions');
+  const validations = local.map((s) => validateSource(s, sources, { strict: true }));
+  const failures = validations.flatMap((v) => v.results.flatMap((f) => f.issues.map((i) => issueFailure(projectPath, i))));
+  const types = validations.reduce((n, v) => n + v.types, 0);
+  return result('manifests', failures, `${types} type(s) in ${local.map((s) => s.name).join(', ')}`);
+}
+
+async function checkLinks(projectPath: string): Promise<VerifyCheck> {
+  const changes = await previewSync(projectPath);
+  const failures = changes
+    .filter((c) => c.action !== 'unchanged')
+    .map((c) => ({ subject: c.path, message: `${c.tool}: link sync would ${c.action} this ${c.kind}`, file: c.path }));
+  return result('links', failures, `${changes.length} generated file(s) and link(s)`);
+}
+
+async function checkActive(config: ProjectConfig, installedRoot: string): Promise<VerifyCheck> {
+  const failures: VerifyFailure[] = activeTypes(config)
+    .filter((t) => !existsSync(join(installedRoot, t)))
+    .map((t) => ({ subject: t, message: 'linked but not installed' }));
+  for (const m of await checkPins(config, installedRoot)) {
+    if (m.installed !== null) failures.push({ subject: m.typePath, message: m.message });
+  }
+  return result('pins', failures);
+}
+
+interface PackageDeps {
+  dependencies?: Record<string, string>;
+  devDependencies?: Record<string, string>;
+  optionalDependencies?: Record<string, string>;
+}
+
+const DEPENDENCY_FIELDS = ['dependencies', 'devDependencies', 'optionalDependencies'] as const;
+
+/** How the root package recorded in dir's package-lock.json differs from its package.json. */
+function lockfileDrift(dir: string): string[] {
+  const pkg = JSON.parse(readFileSync(join(dir, 'package.json'), 'utf-8')) as PackageDeps;
+  const lockPath = join(dir, 'package-lock.json');
+  if (!existsSync(lockPath)) {
+    const declares = DEPENDENCY_FIELDS.some((f) => Object.keys(pkg[f] ?? {}).length > 0);
+    return declares ? ['package.json declares dependencies but there is no package-lock.json'] : [];
+  }
+  const lock = JSON.parse(readFileSync(lockPath, 'utf-8')) as { packages?: Record<string, PackageDeps> };
+  const root = lock.packages?.[''];
+  if (!root) return ['package-lock.json has no root package; regenerate it with npm 7 or later'];
+  const drift: string[] = [];
+  for (const field of DEPENDENCY_FIELDS) {
+    const want = pkg[field] ?? {};
+    const have = root[field] ?? {};
+    for (const name of new Set([...Object.keys(want), ...Object.keys(have)])) {
+      if (want[name] === have[name]) continue;
+      drift.push(`${field}.${name}: package.json has ${want[name] ?? 'nothing'}, package-lock.json has ${have[name] ?? 'nothing'}`);
+    }
+  }
+  return drift;
+}
+
+function checkLockfiles(config: ProjectConfig, installedRoot: string): VerifyCheck {
+  const dirs = pinnedTypeDirs(installedRoot, config.pins);
+  const failures: VerifyFailure[] = [];
+  let checked = 0;
+  for (const typePath of config.active.skills ?? []) {
+    const dir = dirs[typePath] ?? join(installedRoot, typePath);
+    if (!existsSync(join(dir, 'package.json'))) continue;
+    checked++;
+    try {
+      for (const message of lockfileDrift(dir)) failures.push({ subject: typePath, message });
+    } catch (err) {
+      failures.push({ subject: typePath, message: `package.json or package-lock.json is unreadable: ${(err as Error).message}` });
+    }
+  }
+  if (checked === 0) return skipped('lockfiles', 'no linked Node skills');
+  return result('lockfiles', failures, `${checked} Node skill(s)`);
+}
+
+function checkExtensions(config: ProjectConfig, sources: Source[]): VerifyCheck {
+  const names = new Set(sources.map((s) => s.name));
+  const failures: VerifyFailure[] = [];
+  const named = [
+    ...(config.resolution ?? []).filter((n) => n !== 'core').map((n) => [n, 'resolution']),
+    ...(config.extensions ?? []).map((e) => [e.name, 'extensions']),
+    ...Object.values(config.prefer ?? {}).map((n) => [n, 'prefer']),
+  ];
+  for (const [name, key] of named) {
+    if (!names.has(name)) failures.push({ subject: name, message: `named in ${key} but not installed` });
+  }
+  for (const typePath of activeTypes(config)) {
+    const pinned = sources.filter((s) => isPinned(s, typePath));
+    for (const source of pinned) {
+      if (!resolveType(typePath, [source])) {
+        failures.push({ subject: typePath, message: `pinned to ${source.name} by prefer, which doesn't provide it` });
+      }
+    }
+  }
+  return result('extensions', failures, `${sources.length} source(s)`);
+}
+
+function checkIntegrity(installedRoot: string): VerifyCheck {
+  const paths = existsSync(installedRoot) ? installedTypePaths(installedRoot) : [];
+  const failures = paths.flatMap((p) =>
+    verifyType(p, installedRoot).changes.map((c) => ({ subject: p, message: `${c.path} ${c.kind}` })),
+  );
+  return result('integrity', failures, `${paths.length} installed type(s)`);
+}
+
+/** Runs every check against the project; never throws for a failing check. */
+export async function runVerify(projectPath: string, installedRoot: string): Promise<VerifyReport> {
+  const checks: VerifyCheck[] = [];
+  const configPath = projectConfigPath(projectPath);
+
+  if (!existsSync(configPath)) {
+    const detail = `no ${relative(projectPath, configPath)} here`;
+    for (const name of ['project', 'manifests', 'links', 'pins', 'lockfiles', 'extensions'] as const) {
+      checks.push(skipped(name, detail));
+    }
+    checks.push(checkIntegrity(installedRoot));
+    return { project: projectPath, passed: checks.every((c) => c.status !== 'fail'), checks };
+  }
+
+  let config: ProjectConfig | null = null;
+  const issues = validateYamlFile(configPath, ProjectFileSchema);
+  checks.push(result('project', issues.map((i) => issueFailure(projectPath, i))));
+  if (issues.length === 0) config = loadProject(projectPath);
+
+  const sources = buildSources(projectPath);
+  checks.push(checkManifests(projectPath, sources));
+  if (config) {
+    checks.push(await checkLinks(projectPath));
+    checks.push(await checkActive(config, installedRoot));
+    checks.push(checkLockfiles(config, installedRoot));
+    checks.push(checkExtensions(config, sources));
+  } else {
+    for (const name of ['links', 'pins', 'lockfiles', 'extensions'] as const) checks.push(skipped(name, 'project.yaml is invalid'));
+  }
+  checks.push(checkIntegrity(installedRoot));
+
+  return { project: projectPath, passed: checks.every((c) => c.status !== 'fail'), checks };
+}
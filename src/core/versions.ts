@@ -0,0 +1,138 @@
+import { join, basename } from 'node:path';
+import { readFileSync, readdirSync, existsSync, mkdirSync, renameSync } from 'node:fs';
+import yaml from 'js-yaml';
+import { loadSnapshot, readCurrent, stageSnapshot, removeTree } from './store.js';
+import { findManifest } from './executor.js';
+import { loadProject, projectConfigPath } from './linker.js';
+import { APP_NAME } from '../config/branding.js';
+import { compareVersions } from '../utils/version.js';
+import { logger } from '../utils/log.js';
+
+const log = logger('versions');
+
+// ── Side-by-side versions ───────────────────────────────────────────
+//
+// installed/<type-path>/ holds a type's default version, the one the
+// store marks current. Other versions can be installed next to it:
+//
+//   installed/.versions/<type-path>/<version>/
+//
+// (kept apart from the default so its files, hashes, and links stay
+// exactly what was installed). A project pin (`skills/x@1.4.2` in
+// project.yaml) selects the version that link sync, run, and prompt
+// composition use; without a pin they use the default. Versions are
+// materialized from the store like the default, so only versions that
+// were installed at some point can be added.
+
+export const VERSIONS_DIR = '.versions';
+
+/** Type path → the directory of the version a project selects. */
+export type TypeDirs = Record<string, string>;
+
+export interface InstalledVersion {
+  version: string;
+  path: string;
+  default: boolean;
+}
+
+export function versionDir(installedRoot: string, typePath: string, version: string): string {
+  return join(installedRoot, VERSIONS_DIR, typePath, version);
+}
+
+/** The version installed at installed/<type-path>, or null when it isn't installed. */
+export function defaultVersion(installedRoot: string, typePath: string): string | null {
+  const dir = join(installedRoot, typePath);
+  if (!existsSync(dir)) return null;
+  const current = readCurrent(typePath);
+  if (current) return current;
+  // Installed before the store kept versions
+  const manifestPath = findManifest(dir);
+  const manifest = manifestPath ? (yaml.load(readFileSync(manifestPath, 'utf-8')) as { version?: string }) : null;
+  return manifest?.version ?? null;
+}
+
+/** The default version first, then side-by-side versions in directory order. */
+export function installedVersions(installedRoot: string, typePath: string): InstalledVersion[] {
+  const versions: InstalledVersion[] = [];
+  const def = defaultVersion(installedRoot, typePath);
+  if (def) versions.push({ version: def, path: join(installedRoot, typePath), default: true });
+  const dir = join(installedRoot, VERSIONS_DIR, typePath);
+  if (existsSync(dir)) {
+    for (const entry of readdirSync(dir, { withFileTypes: true })) {
+      if (!entry.isDirectory() || entry.name.includes('.tmp-') || entry.name === def) continue;
+      versions.push({ version: entry.name, path: join(dir, entry.name), default: false });
+    }
+  }
+  return versions;
+}
+
+/** Where version of typePath is installed, default or side by side; null if nowhere. */
+export function resolveTypeDir(installedRoot: string, typePath: string, version?: string): string | null {
+  const def = join(installedRoot, typePath);
+  if (!version) return existsSync(def) ? def : null;
+  return installedVersions(installedRoot, typePath).find((v) => compareVersions(v.version, version) === 0)?.path ?? null;
+}
+
+/** Directories for pinned types installed side by side; the rest use installed/<type-path>. */
+export function pinnedTypeDirs(installedRoot: string, pins: Record<string, string> = {}): TypeDirs {
+  const dirs: TypeDirs = {};
+  for (const [typePath, version] of Object.entries(pins)) {
+    const found = installedVersions(installedRoot, typePath).find((v) => compareVersions(v.version, version) === 0);
+    if (found && !found.default) dirs[typePath] = found.path;
+  }
+  return dirs;
+}
+
+/** pinnedTypeDirs for the project at projectPath; empty outside a project. */
+export function projectTypeDirs(projectPath: string, installedRoot: string): TypeDirs {
+  if (!existsSync(projectConfigPath(projectPath))) return {};
+  return pinnedTypeDirs(installedRoot, loadProject(projectPath).pins);
+}
+
+export interface SelectedVersion {
+  dir: string;
+  /** The pinned version, or null for the default. */
+  version: string | null;
+}
+
+/**
+ * The version of typePath that runs in the project at projectPath: its
+ * pin when that is installed side by side, else the default.
+ */
+export function selectVersion(projectPath: string, installedRoot: string, typePath: string): SelectedVersion {
+  const dir = projectTypeDirs(projectPath, installedRoot)[typePath];
+  // A side-by-side directory is named after its version
+  if (dir) return { dir, version: basename(dir) };
+  return { dir: join(installedRoot, typePath), version: null };
+}
+
+/**
+ * Installs a stored version of typePath next to the default. Returns
+ * its directory. The caller installs its dependencies.
+ */
+export function addVersion(installedRoot: string, typePath: string, version: string): string {
+  const snapshot = loadSnapshot(typePath, version);
+  if (!snapshot) {
+    throw new Error(`Version ${version} of ${typePath} is not stored. See \`${APP_NAME} rollback ${typePath} --list\`.`);
+  }
+  if (defaultVersion(installedRoot, typePath) === version) {
+    throw new Error(`${typePath}@${version} is already the default version`);
+  }
+  const relPath = join(VERSIONS_DIR, typePath, version);
+  const dst = join(installedRoot, relPath);
+  // stageSnapshot builds next to the destination it is given
+  const staged = stageSnapshot({ ...snapshot, typePath: relPath }, installedRoot);
+  mkdirSync(join(installedRoot, VERSIONS_DIR, typePath), { recursive: true });
+  removeTree(dst);
+  renameSync(staged, dst);
+  log.info('added version', { type: typePath, version });
+  return dst;
+}
+
+export function removeVersion(installedRoot: string, typePath: string, version: string): boolean {
+  const dir = versionDir(installedRoot, typePath, version);
+  if (!existsSync(dir)) return false;
+  removeTree(dir);
+  log.info('removed version', { type: typePath, version });
+  return true;
+}
@@ -0,0 +1,148 @@
+import type { InputField, WorkflowManifest } from '../types/manifest.js';
+import type { StepOutput } from './publish.js';
+
+// ── Workflow inputs ─────────────────────────────────────────────────
+//
+// A skill step's inputs may refer to the workflow's inputs and to the
+// output of an earlier step:
+//
+//   repo: "{{inputs.repoPath}}"                          a workflow input
+//   env: "{{steps.check-pipeline.outputs.envConfig}}"    a path into its JSON output
+//   log: "{{steps.fetch.outputs}}"                       its whole output, trimmed
+//   url: https://ci.example.com/{{inputs.id}}/log        references embed in text
+//
+// This is the {{ }} syntax publish steps use for their templates.
+// Anything else is a literal. Output paths use the dot form of
+// `agentx pipe --map` (items.0.id). References are checked when the
+// manifest is parsed, so a typo fails `agentx validate` rather than
+// step N of a run.
+
+export interface WorkflowIssue {
+  /** Schema path of the offending value (steps.2.inputs.repo). */
+  path: (string | number)[];
+  message: string;
+}
+
+const REFERENCE = /\{\{([^}]*)\}\}/g;
+
+type Reference = { kind: 'input'; name: string } | { kind: 'step'; id: string; path: string };
+
+const USAGE = '{{inputs.<name>}} or {{steps.<id>.outputs[.<path>]}}';
+
+/** Parses the text between ${ and }; null when it isn't a reference form. */
+function parseReference(expr: string): Reference | null {
+  const input = /^inputs\.([A-Za-z0-9_-]+)$/.exec(expr.trim());
+  if (input) return { kind: 'input', name: input[1] };
+  const step = /^steps\.([A-Za-z0-9_-]+)\.outputs(?:\.(.+))?$/.exec(expr.trim());
+  if (step) return { kind: 'step', id: step[1], path: step[2] ?? '.' };
+  return null;
+}
+
+export function pathSegments(path: string): string[] {
+  return path === '.' ? [] : path.replace(/^\./, '').split('.');
+}
+
+/** The value at path in a skill's output, as an input string. */
+export function extractOutput(stdout: string, path: string, from: string): string {
+  if (path === '.') return stdout.trim();
+  let value: unknown;
+  try {
+    value = JSON.parse(stdout);
+  } catch {
+    throw new Error(`${from} did not print JSON, so "${path}" can't be read from its output`);
+  }
+  for (const segment of pathSegments(path)) {
+    if (value === null || typeof value !== 'object' || !(segment in (value as object))) {
+      throw new Error(`${from}'s output has no "${path}"`);
+    }
+    value = (value as Record<string, unknown>)[segment];
+  }
+  return typeof value === 'string' ? value : JSON.stringify(value);
+}
+
+const DEFAULT_TYPES: Record<InputField['type'], (v: unknown) => boolean> = {
+  string: (v) => typeof v === 'string',
+  file: (v) => typeof v === 'string',
+  number: (v) => typeof v === 'number',
+  boolean: (v) => typeof v === 'boolean',
+  array: (v) => Array.isArray(v),
+  object: (v) => typeof v === 'object' && v !== null && !Array.isArray(v),
+};
+
+/**
+ * Authoring mistakes a schema can't see: duplicate step ids, step inputs
+ * referring to undeclared workflow inputs or to steps that haven't run
+ * yet, and input defaults of the wrong type.
+ */
+export function workflowIssues(workflow: WorkflowManifest): WorkflowIssue[] {
+  const issues: WorkflowIssue[] = [];
+  const declared = new Set((workflow.inputs ?? []).map((f) => f.name));
+
+  (workflow.inputs ?? []).forEach((field, i) => {
+    if (field.default !== undefined && !DEFAULT_TYPES[field.type](field.default)) {
+      issues.push({ path: ['inputs', i, 'default'], message: `Default of "${field.name}" is not a ${field.type}` });
+    }
+  });
+
+  const ids = workflow.steps.map((s) => s.id);
+  workflow.steps.forEach((step, i) => {
+    if (ids.indexOf(step.id) !== i) {
+      issues.push({ path: ['steps', i, 'id'], message: `Duplicate step id "${step.id}"` });
+    }
+    if ('publish' in step) return;
+    for (const [name, value] of Object.entries(step.inputs ?? {})) {
+      if (typeof value !== 'string') continue;
+      const path = ['steps', i, 'inputs', name];
+      for (const [, expr] of value.matchAll(REFERENCE)) {
+        const ref = parseReference(expr);
+        if (!ref) {
+          issues.push({ path, message: `Unsupported reference "{{${expr}}}"; use ${USAGE}` });
+        } else if (ref.kind === 'input' && !declared.has(ref.name)) {
+          const known = [...declared].join(', ') || 'none';
+          issues.push({ path, message: `Unknown workflow input "${ref.name}" (declared inputs: ${known})` });
+        } else if (ref.kind === 'step') {
+          const at = ids.indexOf(ref.id);
+          if (at === -1) issues.push({ path, message: `Unknown step "${ref.id}"` });
+          else if (at >= i) issues.push({ path, message: `Step "${ref.id}" has not run before step "${step.id}"` });
+        }
+      }
+    }
+  });
+  return issues;
+}
+
+/**
+ * A step's inputs with references filled in from the workflow inputs and
+ * the outputs so far. A value that is a single reference to a workflow
+ * input without a value is left out, so the skill's own default applies.
+ */
+export function resolveStepInputs(
+  stepInputs: Record<string, unknown> | undefined,
+  inputs: Record<string, string>,
+  outputs: Map<string, StepOutput>,
+): Record<string, string> {
+  const resolved: Record<string, string> = {};
+  const valueOf = (expr: string): string | undefined => {
+    const ref = parseReference(expr);
+    if (!ref) throw new Error(`Unsupported reference "{{${expr}}}"; use ${USAGE}`);
+    if (ref.kind === 'input') return inputs[ref.name];
+    const out = outputs.get(ref.id);
+    if (!out) throw new Error(`Step "${ref.id}" has not run yet`);
+    return extractOutput(out.stdout, ref.path, ref.id);
+  };
+
+  for (const [name, value] of Object.entries(stepInputs ?? {})) {
+    if (typeof value !== 'string') {
+      resolved[name] = JSON.stringify(value);
+      continue;
+    }
+    const whole = /^\{\{([^}]*)\}\}$/.exec(value);
+    if (whole) {
+      const v = valueOf(whole[1]);
+      if (v !== undefined) resolved[name] = v;
+      continue;
+    }
+    resolved[name] = value.replace(REFERENCE, (_, expr: string) => valueOf(expr) ?? '');
+  }
+  return resolved;
+}
@@ -3,9 +3,10 @@
  * These will be replaced by `npx toolz` calls later.
  */
 
-import { readFileSync, mkdirSync, symlinkSync, lstatSync, readdirSync, statSync, existsSync, unlinkSync } from 'node:fs';
+import { readFileSync, mkdirSync, readdirSync, statSync } from 'node:fs';
 import { join } from 'node:path';
 import yaml from 'js-yaml';
+import { createLink, checkLink, type LinkKind } from '../utils/platform.js';
 
 export interface LoadedManifest {
   manifest: Record<string, unknown>;
@@ -14,7 +15,12 @@ export interface LoadedManifest {
 
 /** Load a manifest.yaml from the installed types directory. */
 export function loadManifest(installedPath: string, ref: string): LoadedManifest | null {
-  const manifestPath = join(installedPath, ref, 'manifest.yaml');
+  return loadManifestAt(join(installedPath, ref));
+}
+
+/** Load the manifest.yaml in a type directory. */
+export function loadManifestAt(dir: string): LoadedManifest | null {
+  const manifestPath = join(dir, 'manifest.yaml');
   try {
     const raw = readFileSync(manifestPath, 'utf-8');
     const manifest = yaml.load(raw) as Record<string, unknown>;
@@ -24,15 +30,12 @@ export function loadManifest(installedPath: string, ref: string): LoadedManifest
   }
 }
 
-/** Create a symlink, replacing any existing one. */
-export function createSymlink(target: string, linkPath: string): void {
-  try {
-    lstatSync(linkPath);
-    unlinkSync(linkPath);
-  } catch {
-    // Link doesn't exist — that's fine
-  }
-  symlinkSync(target, linkPath);
+/**
+ * Create a symlink, replacing any existing one. Falls back to a junction
+ * or a copy where symlinks aren't available; copy forces a copy.
+ */
+export function createSymlink(target: string, linkPath: string, copy = false): LinkKind {
+  return createLink(target, linkPath, { copy });
 }
 
 /** Flatten a type ref like "context/security/owasp" → "context--security--owasp". */
@@ -65,28 +68,30 @@ export function ensureDir(dirPath: string): void {
   mkdirSync(dirPath, { recursive: true });
 }
 
-/** Validate symlinks in a directory, returning total and valid counts. */
-export function validateSymlinks(dirPath: string): { total: number; valid: number } {
+/**
+ * Count managed links in a directory and how many resolve. Copies made
+ * in place of symlinks count as valid only while they match their target.
+ */
+export function validateSymlinks(dirPath: string): { total: number; valid: number; copies: number; drifted: number } {
+  const counts = { total: 0, valid: 0, copies: 0, drifted: 0 };
+  let entries: string[];
   try {
-    const entries = readdirSync(dirPath);
-    let total = 0;
-    let valid = 0;
-    for (const entry of entries) {
-      const fullPath = join(dirPath, entry);
-      try {
-        const stat = lstatSync(fullPath);
-        if (stat.isSymbolicLink()) {
-          total++;
-          if (existsSync(fullPath)) {
-            valid++;
-          }
-        }
-      } catch {
-        // Skip unreadable entries
-      }
-    }
-    return { total, valid };
+    entries = readdirSync(dirPath);
   } catch {
-    return { total: 0, valid: 0 };
+    return counts;
+  }
+  for (const entry of entries) {
+    if (entry.endsWith('.target')) continue;
+    try {
+      const link = checkLink(join(dirPath, entry));
+      if (!link) continue;
+      counts.total++;
+      if (link.kind === 'copy') counts.copies++;
+      if (link.health === 'valid') counts.valid++;
+      if (link.health === 'drifted') counts.drifted++;
+    } catch {
+      // Skip unreadable entries
+    }
   }
+  return counts;
 }
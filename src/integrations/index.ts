@@ -1,10 +1,12 @@
-import { readFileSync, existsSync, writeFileSync } from 'node:fs';
+import { readFileSync, existsSync, writeFileSync, readdirSync, rmSync, utimesSync } from 'node:fs';
 import { join, dirname } from 'node:path';
 import { fileURLToPath } from 'node:url';
 import Handlebars from 'handlebars';
-import { loadManifest, createSymlink, flattenRef, isStale, ensureDir, validateSymlinks } from './helpers.js';
+import { loadManifest, loadManifestAt, createSymlink, flattenRef, isStale, ensureDir, validateSymlinks } from './helpers.js';
 import { PROVIDERS } from './providers.js';
 import type { ProviderConfig } from './providers.js';
+import { isManagedLink, removeLink } from '../utils/platform.js';
+import { APP_NAME } from '../config/branding.js';
 
 const __dirname = dirname(fileURLToPath(import.meta.url));
 const TEMPLATES_DIR = join(__dirname, '..', 'src', 'integrations', 'templates');
@@ -12,17 +14,93 @@ const TEMPLATES_DIR = join(__dirname, '..', 'src', 'integrations', 'templates');
 // Register a helper to produce {{varName}} literal curly braces in command templates
 Handlebars.registerHelper('curly', (value: string) => `{{${value}}}`);
 
+// First line of every generated main document. Records which CLI
+// version wrote it so a much older or newer CLI can detect the skew.
+const GENERATED_MARKER = new RegExp(`^<!-- Generated by ${APP_NAME} (\\S+)\\b.*-->`);
+
+function generatedHeader(cliVersion: string): string {
+  return `<!-- Generated by ${APP_NAME} ${cliVersion}. Regenerate with \`${APP_NAME} link sync\`; manual edits are overwritten. -->\n\n`;
+}
+
+// Last line of every generated command file, so clean() can tell them
+// from hand-written commands. It carries no version, so a CLI upgrade
+// alone never changes a command file.
+const COMMAND_MARKER = `<!-- Generated by ${APP_NAME}; regenerate with \`${APP_NAME} link sync\`. -->`;
+
+/** content without its generated-by header, so output stamped by different CLI versions compares equal. */
+function withoutHeader(content: string): string {
+  const newline = content.indexOf('\n');
+  return GENERATED_MARKER.test(newline < 0 ? content : content.slice(0, newline)) ? content.slice(newline + 1) : content;
+}
+
+/** Whether a file on disk already holds what would be generated, whichever CLI version stamped it. */
+export function sameGenerated(existing: string, generated: string): boolean {
+  return existing === generated || withoutHeader(existing) === withoutHeader(generated);
+}
+
+/** CLI version recorded in a generated document, or null if unmarked. */
+export function readGeneratedVersion(path: string): string | null {
+  try {
+    const firstLine = readFileSync(path, 'utf8').split('\n', 1)[0];
+    return GENERATED_MARKER.exec(firstLine)?.[1] ?? null;
+  } catch {
+    return null;
+  }
+}
+
+function mainDocPathFor(provider: ProviderConfig, projectPath: string): string {
+  return provider.mainDoc.atProjectRoot
+    ? join(projectPath, provider.mainDoc.filename)
+    : join(projectPath, provider.configDir, provider.mainDoc.filename);
+}
+
 function loadHbsTemplate(provider: string, name: string): Handlebars.TemplateDelegate {
   const templatePath = join(TEMPLATES_DIR, provider, name);
   const source = readFileSync(templatePath, 'utf8');
   return Handlebars.compile(source);
 }
 
+export interface MainDocData {
+  persona: Record<string, unknown> | null;
+  skills: Record<string, unknown>[] | null;
+  workflows: Record<string, unknown>[] | null;
+  hasContext: boolean;
+}
+
+/** A tool's main document, without the generated-by header. */
+export function renderMainDoc(toolName: string, data: MainDocData): string {
+  const provider = PROVIDERS[toolName];
+  if (!provider) {
+    throw new Error(`Unknown tool: ${toolName}`);
+  }
+  return loadHbsTemplate(toolName, provider.mainDoc.template)(data);
+}
+
+/** Where a tool's main document and context directory go, relative to the project. */
+export function toolLayout(toolName: string): { mainDoc: string; contextDir: string } {
+  const provider = PROVIDERS[toolName];
+  if (!provider) {
+    throw new Error(`Unknown tool: ${toolName}`);
+  }
+  return {
+    mainDoc: mainDocPathFor(provider, ''),
+    contextDir: join(provider.configDir, provider.context.subdir),
+  };
+}
+
 export interface GenerateInput {
   toolName: string;
   projectConfig: { active?: Record<string, string[]> };
   installedPath: string;
+  /** Directories of pinned side-by-side versions, used instead of installedPath/<ref>. */
+  typeDirs?: Record<string, string>;
+  /** Directories to link instead of installed types (project overrides applied). */
+  overlays?: Record<string, string>;
   projectPath?: string;
+  /** Version of the CLI doing the generation, stamped into the main document. */
+  cliVersion?: string;
+  /** Copy context into the tool directory instead of symlinking it. */
+  copyLinks?: boolean;
 }
 
 export interface GenerateOutput {
@@ -32,29 +110,56 @@ export interface GenerateOutput {
   warnings: string[];
 }
 
+export interface PlannedFile {
+  path: string;
+  content: string;
+}
+
+export interface PlannedLink {
+  path: string;
+  /** Absolute path the link points at. */
+  target: string;
+}
+
+export interface GeneratePlan {
+  files: PlannedFile[];
+  links: PlannedLink[];
+  warnings: string[];
+}
+
 /**
- * Generate AI tool configuration files for a project.
+ * What generate() would write for a tool, without touching the project:
+ * the rendered files and the context links.
  */
-export async function generate(input: GenerateInput): Promise<GenerateOutput> {
-  const { toolName, projectConfig, installedPath, projectPath = '.' } = input;
+export async function planGenerate(input: GenerateInput): Promise<GeneratePlan> {
+  const {
+    toolName,
+    projectConfig,
+    installedPath,
+    typeDirs = {},
+    overlays = {},
+    projectPath = '.',
+    cliVersion,
+  } = input;
+  const load = (ref: string) => (typeDirs[ref] ? loadManifestAt(typeDirs[ref]) : loadManifest(installedPath, ref));
 
   const provider = PROVIDERS[toolName];
   if (!provider) {
     throw new Error(`Unknown tool: ${toolName}`);
   }
 
-  const result: GenerateOutput = { created: [], updated: [], symlinked: [], warnings: [] };
+  const plan: GeneratePlan = { files: [], links: [], warnings: [] };
   const active = projectConfig.active || {};
 
   // Load persona data
   let personaData: Record<string, unknown> | null = null;
   const personas = active.personas || [];
   if (personas.length > 0) {
-    const loaded = loadManifest(installedPath, personas[0]);
+    const loaded = load(personas[0]);
     if (loaded) {
       personaData = loaded.manifest;
     } else {
-      result.warnings.push(`Persona not found: ${personas[0]}`);
+      plan.warnings.push(`Persona not found: ${personas[0]}`);
     }
   }
 
@@ -64,39 +169,30 @@ export async function generate(input: GenerateInput): Promise<GenerateOutput> {
 
   if (provider.renders.skills) {
     for (const ref of active.skills || []) {
-      const loaded = loadManifest(installedPath, ref);
+      const loaded = load(ref);
       if (loaded) {
         skills.push({ ...loaded.manifest, ref });
       } else {
-        result.warnings.push(`Skill not found: ${ref}`);
+        plan.warnings.push(`Skill not found: ${ref}`);
       }
     }
   }
 
   if (provider.renders.workflows) {
     for (const ref of active.workflows || []) {
-      const loaded = loadManifest(installedPath, ref);
+      const loaded = load(ref);
       if (loaded) {
         workflows.push({ ...loaded.manifest, ref });
       } else {
-        result.warnings.push(`Workflow not found: ${ref}`);
+        plan.warnings.push(`Workflow not found: ${ref}`);
       }
     }
   }
 
   const contextRefs = active.context || [];
 
-  // --- Generate main document ---
+  // --- Main document ---
   const configDir = join(projectPath, provider.configDir);
-
-  let mainDocDir: string;
-  if (provider.mainDoc.atProjectRoot) {
-    mainDocDir = projectPath;
-  } else {
-    mainDocDir = configDir;
-    ensureDir(configDir);
-  }
-
   const mainDocTemplate = loadHbsTemplate(toolName, provider.mainDoc.template);
   const mainDocContent = mainDocTemplate({
     persona: personaData,
@@ -104,62 +200,71 @@ export async function generate(input: GenerateInput): Promise<GenerateOutput> {
     workflows: workflows.length > 0 ? workflows : null,
     hasContext: contextRefs.length > 0,
   });
+  plan.files.push({
+    path: mainDocPathFor(provider, projectPath),
+    content: (cliVersion ? generatedHeader(cliVersion) : '') + mainDocContent,
+  });
 
-  const mainDocPath = join(mainDocDir, provider.mainDoc.filename);
-  const existed = existsSync(mainDocPath);
-  writeFileSync(mainDocPath, mainDocContent);
-  (existed ? result.updated : result.created).push(mainDocPath);
-
-  // --- Generate command files (if supported) ---
+  // --- Command files (if supported) ---
   if (provider.commands.supported && provider.commands.template) {
-    ensureDir(configDir);
     const commandsDir = join(configDir, 'commands');
-    ensureDir(commandsDir);
-
     const commandTemplate = loadHbsTemplate(toolName, provider.commands.template);
 
-    for (const skill of skills) {
-      const commandPath = join(commandsDir, `${skill.name}.md`);
-      const cmdExisted = existsSync(commandPath);
-      const content = commandTemplate({
-        description: skill.description,
-        ref: skill.ref,
-        inputs: skill.inputs || null,
+    for (const item of [...skills, ...workflows]) {
+      plan.files.push({
+        path: join(commandsDir, `${item.name}.md`),
+        content: `${commandTemplate({
+          description: item.description,
+          ref: item.ref,
+          inputs: item.inputs || null,
+        }).trimEnd()}\n\n${COMMAND_MARKER}\n`,
       });
-      writeFileSync(commandPath, content);
-      (cmdExisted ? result.updated : result.created).push(commandPath);
     }
+  }
 
-    for (const workflow of workflows) {
-      const commandPath = join(commandsDir, `${workflow.name}.md`);
-      const cmdExisted = existsSync(commandPath);
-      const content = commandTemplate({
-        description: workflow.description,
-        ref: workflow.ref,
-        inputs: workflow.inputs || null,
-      });
-      writeFileSync(commandPath, content);
-      (cmdExisted ? result.updated : result.created).push(commandPath);
+  // --- Context links ---
+  const contextDir = join(configDir, provider.context.subdir);
+  for (const ref of contextRefs) {
+    const target = overlays[ref] ?? typeDirs[ref] ?? join(installedPath, ref);
+    if (!existsSync(target)) {
+      plan.warnings.push(`Context not found: ${ref}`);
+      continue;
     }
+    plan.links.push({ path: join(contextDir, flattenRef(ref)), target });
   }
 
-  // --- Create context symlinks ---
-  ensureDir(configDir);
-  const contextDir = join(configDir, provider.context.subdir);
-  ensureDir(contextDir);
+  return plan;
+}
 
-  for (const ref of contextRefs) {
-    const flatName = flattenRef(ref);
-    const linkPath = join(contextDir, flatName);
-    const target = join(installedPath, ref);
+/**
+ * Generate AI tool configuration files for a project.
+ */
+export async function generate(input: GenerateInput): Promise<GenerateOutput> {
+  const { toolName, projectPath = '.', copyLinks = false } = input;
+  const plan = await planGenerate(input);
+  const provider = PROVIDERS[toolName];
+  const result: GenerateOutput = { created: [], updated: [], symlinked: [], warnings: plan.warnings };
 
-    if (!existsSync(target)) {
-      result.warnings.push(`Context not found: ${ref}`);
+  const configDir = join(projectPath, provider.configDir);
+  ensureDir(join(configDir, provider.context.subdir));
+  if (provider.commands.supported && provider.commands.template) ensureDir(join(configDir, 'commands'));
+
+  for (const file of plan.files) {
+    const existing = existsSync(file.path) ? readFileSync(file.path, 'utf8') : null;
+    if (existing !== null && sameGenerated(existing, file.content)) {
+      // Unchanged apart from the CLI version: keep the file, but mark it
+      // current so status doesn't report it stale against project.yaml
+      const now = new Date();
+      utimesSync(file.path, now, now);
       continue;
     }
+    writeFileSync(file.path, file.content);
+    (existing !== null ? result.updated : result.created).push(file.path);
+  }
 
-    createSymlink(target, linkPath);
-    result.symlinked.push(linkPath);
+  for (const link of plan.links) {
+    createSymlink(link.target, link.path, copyLinks);
+    result.symlinked.push(link.path);
   }
 
   return result;
@@ -174,7 +279,10 @@ export interface StatusOutput {
   tool: string;
   status: string;
   files: string[];
-  symlinks: { total: number; valid: number };
+  /** copies: links made by copying; drifted: copies that no longer match. */
+  symlinks: { total: number; valid: number; copies: number; drifted: number };
+  /** CLI version that generated the main document, if recorded. */
+  generatedBy: string | null;
 }
 
 /**
@@ -190,12 +298,7 @@ export async function status(input: StatusInput): Promise<StatusOutput> {
 
   const projectYaml = join(projectPath, '.agentx', 'project.yaml');
 
-  let mainDocPath: string;
-  if (provider.mainDoc.atProjectRoot) {
-    mainDocPath = join(projectPath, provider.mainDoc.filename);
-  } else {
-    mainDocPath = join(projectPath, provider.configDir, provider.mainDoc.filename);
-  }
+  const mainDocPath = mainDocPathFor(provider, projectPath);
 
   const contextDir = join(projectPath, provider.configDir, provider.context.subdir);
 
@@ -209,7 +312,7 @@ export async function status(input: StatusInput): Promise<StatusOutput> {
   let statusValue = 'up-to-date';
   if (!existsSync(mainDocPath)) {
     statusValue = 'not-generated';
-  } else if (isStale(projectYaml, files)) {
+  } else if (isStale(projectYaml, files) || symlinkInfo.valid < symlinkInfo.total) {
     statusValue = 'stale';
   }
 
@@ -217,6 +320,63 @@ export async function status(input: StatusInput): Promise<StatusOutput> {
     tool: toolName,
     status: statusValue,
     files,
-    symlinks: { total: symlinkInfo.total, valid: symlinkInfo.valid },
+    symlinks: symlinkInfo,
+    generatedBy: readGeneratedVersion(mainDocPath),
   };
 }
+
+export interface CleanInput {
+  toolName: string;
+  projectPath: string;
+}
+
+/**
+ * What clean() would remove: the main document and command files that
+ * carry the generated marker, and context links (symlinks or copies).
+ * Hand-written documents and commands are kept, even ones that mention
+ * `agentx run`.
+ */
+export function cleanTargets(input: CleanInput): string[] {
+  const { toolName, projectPath } = input;
+
+  const provider = PROVIDERS[toolName];
+  if (!provider) {
+    throw new Error(`Unknown tool: ${toolName}`);
+  }
+
+  const targets: string[] = [];
+  const mainDocPath = mainDocPathFor(provider, projectPath);
+  if (readGeneratedVersion(mainDocPath) !== null) targets.push(mainDocPath);
+
+  const configDir = join(projectPath, provider.configDir);
+  if (provider.commands.supported) {
+    const commandsDir = join(configDir, 'commands');
+    for (const name of existsSync(commandsDir) ? readdirSync(commandsDir) : []) {
+      const path = join(commandsDir, name);
+      if (name.endsWith('.md') && readFileSync(path, 'utf8').includes(COMMAND_MARKER)) targets.push(path);
+    }
+  }
+
+  const contextDir = join(configDir, provider.context.subdir);
+  for (const name of existsSync(contextDir) ? readdirSync(contextDir) : []) {
+    const path = join(contextDir, name);
+    if (!name.endsWith('.target') && isManagedLink(path)) targets.push(path);
+  }
+  return targets;
+}
+
+/**
+ * Removes what generate() produces (see cleanTargets) so the next
+ * generation starts from a clean slate.
+ */
+export async function clean(input: CleanInput): Promise<string[]> {
+  const removed = cleanTargets(input);
+  for (const path of removed) {
+    if (isManagedLink(path)) {
+      removeLink(path);
+    } else {
+      rmSync(path, { force: true });
+    }
+  }
+  return removed;
+}
@@ -1,3 +1,4 @@
 export * from './manifest.js';
 export * from './registry.js';
 export * from './integrations.js';
+export * from './output.js';
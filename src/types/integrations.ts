@@ -19,6 +19,20 @@ export interface GenerateResult {
   warnings: string[];
 }
 
+/** A generated file or context link that link sync would change. */
+export interface SyncChange {
+  tool: ToolName;
+  /** Relative to the project. */
+  path: string;
+  kind: 'file' | 'link';
+  action: 'create' | 'update' | 'remove' | 'unchanged';
+  /** Unified diff of a file's contents; empty for links. */
+  diff: string;
+  /** Where a link will point, and where it points now when that differs. */
+  target?: string;
+  previousTarget?: string;
+}
+
 export interface StatusResult {
   tool: string;
   status: string;
@@ -26,5 +40,10 @@ export interface StatusResult {
   symlinks: {
     total: number;
     valid: number;
+    /** Links made by copying, where symlinks are unavailable or with --force-copy. */
+    copies: number;
+    /** Copies whose contents no longer match their target. */
+    drifted: number;
   };
+  generatedBy?: string | null;
 }
@@ -11,8 +11,16 @@ import type {
   InputFieldSchema,
   OutputDeclarationSchema,
   RegistryBlockSchema,
+  RegistryTokenSchema,
   WorkflowStepSchema,
+  SkillStepSchema,
+  PublishStepSchema,
+  PublishSchema,
+  SkillTestSchema,
   TemplateVariableSchema,
+  CapabilitySchema,
+  ExtensionManifestSchema,
+  PresetSchema,
 } from '../config/schema.js';
 
 export type ContextManifest = z.infer<typeof ContextManifestSchema>;
@@ -27,8 +35,16 @@ export type CLIDependency = z.infer<typeof CLIDependencySchema>;
 export type InputField = z.infer<typeof InputFieldSchema>;
 export type OutputDeclaration = z.infer<typeof OutputDeclarationSchema>;
 export type RegistryBlock = z.infer<typeof RegistryBlockSchema>;
+export type RegistryToken = z.infer<typeof RegistryTokenSchema>;
 export type WorkflowStep = z.infer<typeof WorkflowStepSchema>;
+export type SkillStep = z.infer<typeof SkillStepSchema>;
+export type PublishStep = z.infer<typeof PublishStepSchema>;
+export type Publish = z.infer<typeof PublishSchema>;
+export type SkillTest = z.infer<typeof SkillTestSchema>;
 export type TemplateVariable = z.infer<typeof TemplateVariableSchema>;
+export type Capability = z.infer<typeof CapabilitySchema>;
+export type ExtensionManifest = z.infer<typeof ExtensionManifestSchema>;
+export type Preset = z.infer<typeof PresetSchema>;
 
 export type BaseManifest = {
   name: string;
@@ -38,4 +54,8 @@ export type BaseManifest = {
   tags?: string[];
   author?: string;
   vendor?: string | null;
+  deprecated?: boolean;
+  replaced_by?: string;
+  /** Former type paths that still resolve to this type. */
+  aliases?: string[];
 };
@@ -0,0 +1,64 @@
+// ── JSON output schemas ─────────────────────────────────────────────
+//
+// What `--json` prints for commands whose text output is meant for
+// people. Wrapper tooling depends on these shapes: add fields freely,
+// but renaming or removing one is a breaking change. With
+// --output-format=json the same payload is the envelope's `data`.
+
+export interface ExtensionListJson {
+  extensions: {
+    name: string;
+    path: string;
+    branch: string;
+    /** ok, uninitialized, modified, missing, or archive. */
+    status: string;
+  }[];
+}
+
+export interface LinkStatusJson {
+  tools: {
+    tool: string;
+    /** From the integration, e.g. linked, partial, not-linked, error. */
+    status: string;
+    files: string[];
+    /** copies: links made by copying (Windows, --force-copy); drifted: copies out of date. */
+    symlinks: { total: number; valid: number; copies: number; drifted: number };
+    generatedBy: string | null;
+  }[];
+  /** Set when the project's configs came from another CLI version. */
+  versionSkew: { current: string; recorded: string[]; direction: 'older' | 'newer' } | null;
+}
+
+export interface InstallSummaryJson {
+  /** The requested type, after following aliases and replacements. */
+  root: string;
+  installed: {
+    typePath: string;
+    category: string;
+    version: string;
+    /** Files compared with the version installed before. */
+    files: { added: string[]; updated: string[]; removed: string[]; unchanged: number };
+  }[];
+  /** Types in the plan that were already installed. */
+  alreadyInstalled: number;
+  cliDependencies: { name: string; available: boolean }[];
+  warnings: string[];
+}
+
+export type DoctorStatus = 'ok' | 'warn' | 'fail' | 'info';
+
+export interface DoctorCheckJson {
+  section: string;
+  name: string;
+  status: DoctorStatus;
+  detail?: string;
+}
+
+export interface DoctorReportJson {
+  mode: string;
+  /** False when any check failed; warnings do not count. */
+  healthy: boolean;
+  checks: DoctorCheckJson[];
+  /** Repairs made by --fix, before the checks ran. */
+  fixed?: string[];
+}
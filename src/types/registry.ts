@@ -3,6 +3,12 @@ import type { ManifestType } from '../config/schema.js';
 export interface Source {
   name: string;
   basePath: string;
+  /** project.yaml `extensions[].priority`; higher is consulted first (default 0). */
+  priority?: number;
+  /** Type path globs pinned to this source by project.yaml `prefer:`. */
+  pinned?: string[];
+  /** A project-local extension (.agentx/extensions), which arrives with a clone. */
+  local?: boolean;
 }
 
 export interface ResolvedType {
@@ -11,6 +17,21 @@ export interface ResolvedType {
   sourceDir: string;
   sourceName: string;
   category: ManifestType;
+  /** The path it was requested as, when reached through a manifest alias. */
+  aliasOf?: string;
+  /** Later sources whose copy of this context is appended (merge: append). */
+  appended?: AppendedSource[];
+  /**
+   * The .agentx directory of the project when a project-local extension
+   * provides or appends to the type; merged copies are written there.
+   */
+  projectDir?: string;
+}
+
+export interface AppendedSource {
+  sourceName: string;
+  sourceDir: string;
+  manifestPath: string;
 }
 
 export interface DependencyNode {
@@ -45,4 +66,7 @@ export interface DiscoveredType extends ResolvedType {
   version: string;
   description: string;
   tags: string[];
+  deprecated?: boolean;
+  replacedBy?: string;
+  aliases?: string[];
 }
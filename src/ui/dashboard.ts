@@ -0,0 +1,334 @@
+import { spawn } from 'node:child_process';
+import { emitKeypressEvents } from 'node:readline';
+import chalk from 'chalk';
+import type { DashboardData } from '../core/dashboard.js';
+import { subscribe, type Subscription } from '../core/notify.js';
+import { formatBytes } from '../utils/units.js';
+import { t } from './i18n.js';
+
+// ── Dashboard ───────────────────────────────────────────────────────
+//
+// `agentx ui`: four panes (installed types, projects, extensions, recent
+// runs) drawn with plain ANSI escapes in the alternate screen, so it
+// needs no extra dependency. One pane has focus; its selected row is
+// what the pane's actions act on. An action suspends the dashboard and
+// runs the regular command (`agentx install`, `link sync`, ...) in the
+// terminal, so it prompts, locks, and reports exactly as it does from
+// the shell; the data is reloaded when it returns. It is also reloaded
+// when another agentx process installs or uninstalls types.
+
+export interface PaneAction {
+  key: string;
+  label: string;
+  /** The agentx arguments to run for a row. */
+  argv: (row: number) => string[];
+  /** Directory to run in; the current one when unset. */
+  cwd?: (row: number) => string;
+  /** Whether the action applies to a row; every row when unset. */
+  enabled?: (row: number) => boolean;
+}
+
+export interface Pane {
+  title: string;
+  columns: string[];
+  rows: string[][];
+  actions: PaneAction[];
+}
+
+export interface DashboardState {
+  panes: Pane[];
+  focus: number;
+  /** Selected row per pane. */
+  selected: number[];
+  status: string;
+}
+
+const shortDate = (iso: string | null) => (iso ? iso.slice(0, 16).replace('T', ' ') : t('dashboard.never'));
+
+/** The panes for a load of dashboard data. */
+export function buildPanes(data: DashboardData): Pane[] {
+  const { installed, projects, extensions, runs } = data;
+  return [
+    {
+      title: t('dashboard.installed'),
+      columns: [t('dashboard.col.type'), t('dashboard.col.version'), t('dashboard.col.latest')],
+      rows: installed.map((type) => [type.typePath, type.version, type.latest ?? '']),
+      actions: [
+        { key: 'i', label: t('dashboard.action.install'), argv: (r) => ['install', installed[r].typePath] },
+        {
+          key: 'r',
+          label: t('dashboard.action.run'),
+          argv: (r) => ['run', installed[r].typePath],
+          enabled: (r) => installed[r].category === 'skill' || installed[r].category === 'workflow',
+        },
+      ],
+    },
+    {
+      title: t('dashboard.projects'),
+      columns: [t('dashboard.col.project'), t('dashboard.col.linked'), t('dashboard.col.pending'), t('dashboard.col.lastSync')],
+      rows: projects.map((p) => [
+        p.path,
+        p.error ? '?' : String(p.linked),
+        p.error ? t('dashboard.unreadable') : p.pending === 0 ? t('dashboard.inSync') : t('dashboard.changes', { count: p.pending }),
+        shortDate(p.lastSync),
+      ]),
+      actions: [{ key: 's', label: t('dashboard.action.linkSync'), argv: () => ['link', 'sync'], cwd: (r) => projects[r].path }],
+    },
+    {
+      title: t('dashboard.extensions'),
+      columns: [t('dashboard.col.extension'), t('dashboard.col.status')],
+      rows: extensions.map((e) => [e.name, e.status]),
+      actions: [{ key: 's', label: t('dashboard.action.syncAll'), argv: () => ['extension', 'sync'] }],
+    },
+    {
+      title: t('dashboard.runs'),
+      columns: [t('dashboard.col.skill'), t('dashboard.col.when'), t('dashboard.col.output')],
+      rows: runs.map((r) => [r.typePath, shortDate(r.at), formatBytes(r.bytes)]),
+      actions: [
+        { key: 'o', label: t('dashboard.action.showOutput'), argv: (r) => ['output', 'show', runs[r].typePath, '--run', String(runs[r].run)] },
+        { key: 'r', label: t('dashboard.action.runAgain'), argv: (r) => ['run', runs[r].typePath] },
+      ],
+    },
+  ];
+}
+
+// ── Rendering ───────────────────────────────────────────────────────
+
+function fit(text: string, width: number): string {
+  if (width <= 0) return '';
+  if (text.length <= width) return text.padEnd(width);
+  return width === 1 ? '…' : `${text.slice(0, width - 1)}…`;
+}
+
+/** Column widths for a pane: the first column takes what the others leave. */
+function columnWidths(pane: Pane, width: number): number[] {
+  const rest = pane.columns.slice(1).map((c, i) => Math.max(c.length, ...pane.rows.map((r) => r[i + 1].length)));
+  const first = Math.max(8, width - rest.reduce((n, w) => n + w + 1, 0));
+  return [first, ...rest];
+}
+
+function row(cells: string[], widths: number[], width: number): string {
+  return fit(cells.map((c, i) => fit(c, widths[i])).join(' '), width);
+}
+
+/** Lines of one pane, width by height, borders included. */
+export function renderPane(pane: Pane, width: number, height: number, focused: boolean, selected: number): string[] {
+  const inner = width - 2;
+  const body = Math.max(0, height - 3);
+  const border = focused ? chalk.cyan : chalk.dim;
+  const title = ` ${pane.title} (${pane.rows.length}) `;
+  const shown = title.slice(0, inner);
+  const lines = [border(`┌${shown}${'─'.repeat(inner - shown.length)}┐`)];
+
+  const widths = columnWidths(pane, inner);
+  lines.push(border('│') + chalk.bold(row(pane.columns, widths, inner)) + border('│'));
+
+  // Scroll so the selection stays in view
+  const offset = Math.max(0, Math.min(selected - body + 1, pane.rows.length - body));
+  for (let i = 0; i < body; i++) {
+    const index = offset + i;
+    const cells = pane.rows[index];
+    let text = cells ? row(cells, widths, inner) : ' '.repeat(inner);
+    if (cells && focused && index === selected) text = chalk.inverse(text);
+    lines.push(border('│') + text + border('│'));
+  }
+  if (pane.rows.length === 0 && body > 0) lines[2] = border('│') + chalk.dim(fit(` ${t('dashboard.empty')}`, inner)) + border('│');
+  lines.push(border(`└${'─'.repeat(inner)}┘`));
+  return lines;
+}
+
+function keyHelp(pane: Pane): string {
+  const actions = pane.actions.map((a) => `${a.key} ${a.label}`);
+  return [t('dashboard.key.pane'), t('dashboard.key.select'), ...actions, t('dashboard.key.reload'), t('dashboard.key.quit')].join('  ·  ');
+}
+
+/** The whole screen: panes in a 2×2 grid, then the key help and status lines. */
+export function renderDashboard(state: DashboardState, width: number, height: number): string[] {
+  const left = Math.floor(width / 2);
+  const right = width - left;
+  const top = Math.floor((height - 2) / 2);
+  const bottom = height - 2 - top;
+  const pane = (i: number, w: number, h: number) =>
+    renderPane(state.panes[i], w, h, state.focus === i, state.selected[i]);
+
+  const lines: string[] = [];
+  const [a, b, c, d] = [pane(0, left, top), pane(1, right, top), pane(2, left, bottom), pane(3, right, bottom)];
+  for (let i = 0; i < top; i++) lines.push(a[i] + b[i]);
+  for (let i = 0; i < bottom; i++) lines.push(c[i] + d[i]);
+  lines.push(chalk.dim(fit(keyHelp(state.panes[state.focus]), width)));
+  // The status may be colored, so it's cleared to the end rather than padded
+  lines.push(`${state.status}\x1b[K`);
+  return lines;
+}
+
+// ── Terminal ────────────────────────────────────────────────────────
+
+const ENTER_SCREEN = '\x1b[?1049h\x1b[?25l';
+const LEAVE_SCREEN = '\x1b[?25h\x1b[?1049l';
+
+interface Key {
+  name?: string;
+  sequence?: string;
+  ctrl?: boolean;
+  shift?: boolean;
+}
+
+/** Runs agentx with args in the terminal, and waits for a key after it exits. */
+function runCommand(argv: string[], cwd: string | undefined): Promise<number> {
+  return new Promise((resolve) => {
+    const child = spawn(process.execPath, [process.argv[1], ...argv], { cwd, stdio: 'inherit' });
+    child.on('error', () => resolve(1));
+    child.on('close', (code) => {
+      process.stdout.write(chalk.dim(`\n${t('dashboard.pressAnyKey')}`));
+      process.stdin.setRawMode(true);
+      process.stdin.resume();
+      process.stdin.once('data', () => resolve(code ?? 1));
+    });
+  });
+}
+
+/**
+ * Shows the dashboard until the user quits. load is called at start,
+ * after every action, and when installed types change elsewhere.
+ */
+export async function runDashboard(load: () => Promise<DashboardData>): Promise<void> {
+  if (!process.stdin.isTTY || !process.stdout.isTTY) {
+    throw new Error(t('dashboard.needsTerminal'));
+  }
+
+  let data = await load();
+  const state: DashboardState = { panes: buildPanes(data), focus: 0, selected: [0, 0, 0, 0], status: '' };
+  if (data.warnings.length) state.status = chalk.yellow(t('dashboard.sourceWarnings', { count: data.warnings.length }));
+
+  const draw = () => {
+    const lines = renderDashboard(state, process.stdout.columns || 80, process.stdout.rows || 24);
+    process.stdout.write(`\x1b[H${lines.join('\n')}`);
+  };
+  /** Reloads the data; a failure keeps the old data and shows in the status line. */
+  const reload = async (status: string) => {
+    state.status = chalk.dim(t('dashboard.loading'));
+    draw();
+    try {
+      data = await load();
+    } catch (err) {
+      state.status = chalk.red(t('dashboard.reloadFailed', { message: (err as Error).message }));
+      return;
+    }
+    state.panes = buildPanes(data);
+    state.selected = state.selected.map((s, i) => Math.max(0, Math.min(s, state.panes[i].rows.length - 1)));
+    state.status = status;
+  };
+
+  emitKeypressEvents(process.stdin);
+  // Whether the terminal is in the dashboard's screen and raw mode, so
+  // every way out (quit, an error, process exit) restores it exactly once
+  let onScreen = false;
+  const enter = () => {
+    onScreen = true;
+    process.stdin.setRawMode(true);
+    process.stdout.write(`${ENTER_SCREEN}\x1b[2J`);
+    draw();
+  };
+  const leave = () => {
+    if (!onScreen) return;
+    onScreen = false;
+    process.stdout.write(LEAVE_SCREEN);
+    process.stdin.setRawMode(false);
+  };
+  process.once('exit', leave);
+
+  let changes = null as Subscription | null;
+  try {
+    await new Promise<void>((resolve, reject) => {
+      let busy = false;
+      const onResize = () => draw();
+
+      // An action reloads when it returns anyway, so a change that lands
+      // while one runs (or while a reload is in flight) is dropped
+      changes = subscribe(
+        (event) => {
+          if (busy) return;
+          busy = true;
+          reload(chalk.dim(t('dashboard.externalChange', { types: event.typePaths.join(', ') }))).then(() => {
+            busy = false;
+            draw();
+          }, quit);
+        },
+        { socket: true },
+      );
+
+      const quit = (err?: unknown) => {
+        process.stdin.off('keypress', onKey);
+        process.stdout.off('resize', onResize);
+        if (err) reject(err);
+        else resolve();
+      };
+
+      const act = async (action: PaneAction) => {
+        const pane = state.panes[state.focus];
+        const index = state.selected[state.focus];
+        if (pane.rows.length === 0) return;
+        if (action.enabled && !action.enabled(index)) {
+          state.status = chalk.yellow(t('dashboard.notApplicable', { action: action.label, row: pane.rows[index][0] }));
+          draw();
+          return;
+        }
+        const argv = action.argv(index);
+        busy = true;
+        process.stdin.off('keypress', onKey);
+        leave();
+        console.log(chalk.bold(`$ agentx ${argv.join(' ')}`));
+        const code = await runCommand(argv, action.cwd?.(index));
+        enter();
+        const command = `agentx ${argv.join(' ')}`;
+        await reload(code === 0 ? chalk.green(`✓ ${command}`) : chalk.red(`✗ ${t('dashboard.exited', { command, code })}`));
+        draw();
+        process.stdin.on('keypress', onKey);
+        busy = false;
+      };
+
+      function onKey(_str: string | undefined, key: Key = {}) {
+        if (busy) return;
+        const pane = state.panes[state.focus];
+        const move = (delta: number) => {
+          const max = Math.max(0, pane.rows.length - 1);
+          state.selected[state.focus] = Math.max(0, Math.min(max, state.selected[state.focus] + delta));
+        };
+        if (key.name === 'q' || (key.ctrl && key.name === 'c')) return quit();
+        if (key.name === 'tab') state.focus = (state.focus + (key.shift ? 3 : 1)) % 4;
+        else if (key.sequence && /^[1-4]$/.test(key.sequence)) state.focus = Number(key.sequence) - 1;
+        else if (key.name === 'up' || key.name === 'k') move(-1);
+        else if (key.name === 'down' || key.name === 'j') move(1);
+        else if (key.name === 'pageup') move(-10);
+        else if (key.name === 'pagedown') move(10);
+        else if (key.sequence === 'R') {
+          busy = true;
+          reload('').then(() => {
+            busy = false;
+            draw();
+          }, quit);
+          return;
+        } else {
+          const action = pane.actions.find((a) => a.key === key.sequence);
+          if (action) {
+            // reload() reports its own failures, so this only rejects when
+            // the terminal itself is gone; leave the dashboard with the error
+            act(action).catch(quit);
+            return;
+          }
+        }
+        draw();
+      }
+
+      process.stdin.on('keypress', onKey);
+      process.stdout.on('resize', onResize);
+      process.stdin.resume();
+      enter();
+    });
+  } finally {
+    changes?.close();
+    leave();
+    process.off('exit', leave);
+    process.stdin.pause();
+  }
+}
@@ -0,0 +1,60 @@
+import * as settings from '../config/settings.js';
+import { en, type MessageKey } from './messages/en.js';
+import { es } from './messages/es.js';
+
+// ── Messages ────────────────────────────────────────────────────────
+//
+// User-facing CLI text is looked up by key in a per-locale catalog
+// (ui/messages/<locale>.ts) instead of being written inline. English is
+// the source catalog; every other catalog is typed against its keys, so
+// a missing translation fails to compile. Placeholders are {name} and are
+// filled by t(). The locale is the `locale` setting, else the language of
+// LC_ALL, LC_MESSAGES, or LANG (the first one set, as POSIX does); an
+// unsupported language falls back to English.
+//
+// Only text for people is translated. Data (--json), log lines, error
+// codes, and anything scripts match on stay in English.
+
+export const LOCALES = ['en', 'es'] as const;
+export type Locale = (typeof LOCALES)[number];
+
+export type MessageParams = Record<string, string | number>;
+
+const CATALOGS: Record<Locale, Record<MessageKey, string>> = { en, es };
+
+let selected: Locale | null = null;
+
+function isLocale(value: string): value is Locale {
+  return (LOCALES as readonly string[]).includes(value);
+}
+
+/** The language part of a locale name: es_MX.UTF-8 -> es. */
+function language(name: string): string {
+  return name.split(/[_.@-]/)[0].toLowerCase();
+}
+
+export function resolveLocale(
+  configured: string = settings.get('locale'),
+  env: NodeJS.ProcessEnv = process.env,
+): Locale {
+  if (configured && isLocale(language(configured))) return language(configured) as Locale;
+  const name = env.LC_ALL || env.LC_MESSAGES || env.LANG || '';
+  const lang = language(name);
+  return isLocale(lang) ? lang : 'en';
+}
+
+/** The locale messages are shown in; resolved on first use. */
+export function currentLocale(): Locale {
+  selected ??= resolveLocale();
+  return selected;
+}
+
+/** Sets the locale for this process; null resolves it again from settings and the environment. */
+export function setLocale(locale: Locale | null): void {
+  selected = locale;
+}
+
+export function t(key: MessageKey, params: MessageParams = {}): string {
+  const template = CATALOGS[currentLocale()][key] || en[key];
+  return template.replace(/\{(\w+)\}/g, (match, name: string) => (name in params ? String(params[name]) : match));
+}
@@ -0,0 +1,577 @@
+// English, the source catalog: every key the CLI looks up, and the
+// fallback for keys another locale leaves empty. See ui/i18n.ts.
+
+export const en = {
+  'common.didYouMean': 'Did you mean:',
+
+  'error.seeExplain': 'see `{command}`',
+
+  'prompt.decisionNeeded': 'A decision is needed: {question}{hint}',
+  'prompt.inputDisabled': 'Input needed but prompts are disabled: {question}{hint}',
+  'prompt.passYes': 'pass --yes',
+  'prompt.allow': 'Allow this?',
+
+  'lock.waiting': 'Waiting for another agentx process (pid {pid}, `{command}`) to finish...',
+  'trace.summary': 'Traced {count} filesystem calls: {kinds}. Details in {path}',
+
+  'explain.col.id': 'Code or topic',
+  'explain.col.about': 'About',
+  'explain.notFound': 'Nothing to explain for "{query}". Run `agentx explain` to list codes and topics.',
+  'explain.fixes': 'To fix it:',
+  'explain.seeAlso': 'See also: {items}',
+
+  'test.noMatch': 'No test cases match --case.',
+  'test.noTests': '{skill} declares no tests.',
+  'test.summary': '{passed} passed, {failed} failed',
+
+  'bench.progress': 'run {n}/{total}',
+  'bench.summary': '{skill} {version} ({runtime}): {iterations} run(s), {warmup} warmup',
+  'bench.noCpu': 'CPU time is not measured on this platform.',
+  'bench.against': 'Against {skill} {version}:',
+  'bench.written': 'Report written to {path}',
+  'bench.failed': '{failed} of {total} run(s) exited non-zero',
+
+  'dashboard.installed': 'Installed',
+  'dashboard.projects': 'Projects',
+  'dashboard.extensions': 'Extensions',
+  'dashboard.runs': 'Recent runs',
+  'dashboard.col.type': 'Type',
+  'dashboard.col.version': 'Version',
+  'dashboard.col.latest': 'Latest',
+  'dashboard.col.project': 'Project',
+  'dashboard.col.linked': 'Linked',
+  'dashboard.col.pending': 'Pending',
+  'dashboard.col.lastSync': 'Last sync',
+  'dashboard.col.extension': 'Extension',
+  'dashboard.col.status': 'Status',
+  'dashboard.col.skill': 'Skill',
+  'dashboard.col.when': 'When',
+  'dashboard.col.output': 'Output',
+  'dashboard.action.install': 'install/upgrade',
+  'dashboard.action.run': 'run',
+  'dashboard.action.linkSync': 'link sync',
+  'dashboard.action.syncAll': 'sync all',
+  'dashboard.action.showOutput': 'show output',
+  'dashboard.action.runAgain': 'run again',
+  'dashboard.key.pane': 'tab/1-4 pane',
+  'dashboard.key.select': '↑↓ select',
+  'dashboard.key.reload': 'R reload',
+  'dashboard.key.quit': 'q quit',
+  'dashboard.never': 'never',
+  'dashboard.inSync': 'in sync',
+  'dashboard.changes': '{count} change(s)',
+  'dashboard.unreadable': 'unreadable',
+  'dashboard.empty': 'nothing here',
+  'dashboard.loading': 'Loading…',
+  'dashboard.sourceWarnings': '{count} warning(s) while reading sources',
+  'dashboard.notApplicable': "{action} doesn't apply to {row}",
+  'dashboard.exited': '{command} exited {code}',
+  'dashboard.externalChange': 'Reloaded: another agentx process changed {types}',
+  'dashboard.reloadFailed': 'Reload failed: {message}',
+  'dashboard.pressAnyKey': 'Press any key to return to the dashboard',
+  'dashboard.needsTerminal': 'agentx ui needs an interactive terminal',
+
+  'common.notInstalled': 'Type not installed: {type}. Run `agentx install {type}` first.',
+  'common.notInstalledHint': 'Type not installed: {type}.{hint}',
+  'common.inputPrecedence': 'Inputs are taken from {order}, highest first',
+  'run.cacheIgnoredDev': '--cache is ignored with --dev; source edits would not change the cache key',
+  'run.noManifest': 'No manifest found in: {dir}',
+  'run.invalidWorkflow': 'Invalid workflow {workflow}; check it with `{command}`',
+  'run.publishSkipped': '{step}: publish to {to} skipped in the sandbox',
+  'run.published': '{step}: published {bytes} bytes ({contentType}) to {destination}',
+  'run.stepNotFound': 'Workflow step skill not found in any source: {skill}',
+  'run.stepNotInstalled': 'Workflow step skill not installed: {skill}',
+  'run.stepNoManifest': 'No manifest for workflow step: {skill}',
+  'run.notRunnable': 'Cannot run type: {type}. Only skills and workflows are runnable.',
+  'run.devFrom': 'Running {type} from {dir}',
+  'run.manifestModified': 'The manifest of {type} was modified after install. Review the changes (`{verify}`), then run `{accept}`.',
+  'run.manifestGuard': '{message} Set run.manifest_guard to "warn" to run anyway.',
+  'run.tokensMissing': '{type} requires {names}; set it in the environment or in {path}',
+  'run.needsValue': '{type} needs {name}{about}:',
+  'run.tokenRequired': '{name} is required by {type}',
+  'run.saveToken': 'Save {name} to {path} for future runs?',
+  'run.tokenSaved': 'Saved {name} to {path}',
+  'run.noAccount': '{type} has no token set for account "{account}" ({path}).{known} Create it with `{command}`.',
+  'run.knownAccounts': ' Its accounts: {accounts}.',
+  'run.mayUseNetwork': '{type} may access the network, which the sandbox does not block:',
+  'run.sandboxUnchanged': '{type} left its registry unchanged.',
+  'run.sandboxChanged': '{type} changed its registry (discarded):',
+  'run.stdinNeedsPipe': '--stdin-input needs input piped on stdin',
+
+  'common.typeNotFound': 'Type not found: {type}{hint}',
+  'common.moved': '{from} has moved to {to}.',
+  'common.deprecated': '{type} is deprecated{successor}.',
+  'common.useInstead': '; use {type} instead',
+  'common.cancelled': 'Cancelled.',
+  'install.jsonNeedsYes': 'install --json cannot ask for confirmation; pass --yes',
+  'install.installInstead': 'Install {type} instead?',
+  'install.dependencyDeprecated': 'Dependency {type} is deprecated{successor}.',
+  'install.nothingToInstall': 'Nothing to install — all types already present.',
+  'install.proceed': 'Proceed with installation?',
+  'install.installing': 'Installing {name}...',
+  'install.done': 'done ({changes})',
+  'install.failed': 'failed',
+  'install.notRestored': '; could not restore {types}',
+  'install.rolledBack': '; all changes were rolled back',
+  'install.installFailed': 'Installing {type} failed: {message}{restored}',
+  'install.installed': 'Installed {count} type(s).',
+  'install.plan': 'Install plan:',
+  'install.toInstall': 'Types to install: {counts}',
+  'install.alreadyInstalled': 'Already installed: {count}',
+  'install.cliDependencies': 'CLI dependencies:',
+  'install.noFileChanges': 'no file changes',
+  'install.filesAdded': '{count} added',
+  'install.filesUpdated': '{count} updated',
+  'install.filesRemoved': '{count} removed',
+  'install.filesUnchanged': '{count} unchanged',
+
+  'col.tool': 'Tool',
+  'col.action': 'Action',
+  'col.path': 'Path',
+  'col.status': 'Status',
+  'col.type': 'Type',
+  'col.version': 'Version',
+  'col.name': 'Name',
+  'col.description': 'Description',
+  'col.category': 'Category',
+  'col.source': 'Source',
+  'link.upToDate': 'Generated files are up to date; nothing would change.',
+  'link.linkPath': '{path} (link)',
+  'link.none': '(none)',
+  'link.removed': '(removed)',
+  'link.dryRun': 'Dry run: {count} change(s) not applied.',
+  'link.versionSkew': "This project's configs were generated by {app} {recorded}; you are running {current} ({direction}). Generated formats may differ. Run `{command}` to normalize them.",
+  'link.linked': 'Linked: {type}',
+  'link.unlinked': 'Unlinked: {type}',
+  'link.synced': '{tool}: {created} created, {updated} updated, {symlinked} symlinked',
+  'link.noTools': 'No tools configured.',
+  'link.col.files': 'Files',
+  'link.col.symlinks': 'Symlinks',
+  'link.copied': '({count} copied)',
+  'link.broken': 'Some links are broken or their copies are out of date. Run `{command}` to repair them.',
+
+  'doctor.invalidOrphans': 'Invalid --orphans "{value}". Use one of: {actions}',
+  'doctor.orphanQuestion': '{skill} is no longer installed. What should happen to its registry?',
+  'doctor.orphanArchive': 'Archive it (userdata/archive/)',
+  'doctor.orphanDelete': 'Delete it',
+  'doctor.orphanKeep': 'Keep it',
+  'doctor.noRepairs': 'Userdata tree needs no repairs.',
+  'doctor.title': 'AgentX Doctor',
+  'doctor.mode': 'Mode: {mode}',
+  'doctor.section.runtime': 'Runtime',
+  'doctor.section.userdata': 'Userdata',
+  'doctor.section.cli': 'CLI Dependencies',
+  'doctor.section.state': 'Skill State',
+  'doctor.section.manifest': 'Manifest Validation',
+  'doctor.complete': 'Doctor complete.',
+
+  'extension.adding': 'Adding extension {name}...',
+  'extension.added': 'Extension added: {name}',
+  'extension.creating': 'Creating extension {name}...',
+  'extension.created': 'Created extension {name} at {dir}',
+  'extension.pushed': 'Pushed to {remote} ({branch})',
+  'extension.publishHint': 'Publish it to a git remote, then: {command}',
+  'extension.removed': 'Extension removed: {name}',
+  'extension.none': 'No extensions found.',
+  'extension.col.branch': 'Branch',
+  'extension.syncing': 'Syncing extensions...',
+  'extension.synced': 'Extensions synced.',
+  'extension.trustReview': 'Project extension {name} at {path} will be loaded as a source, with its types, scaffolds, hooks, and detection rules. Review its files first.',
+  'extension.trusted': 'Trusted project extension: {name}',
+  'extension.trustDeclined': 'Not trusted: {name}',
+  'extension.trustNone': 'Every project extension is already trusted.',
+  'extension.trustUnknown': 'No project extension named {name} in {dir}',
+
+  'init.global': 'Initializing global userdata...',
+  'init.cloningCatalog': 'Cloning catalog...',
+  'init.globalDone': 'Global initialization complete.',
+  'init.alreadyInitialized': 'Project already initialized.',
+  'init.installingPreset': 'Installing preset {preset}...',
+  'init.presetInstalled': 'Installed {count} type(s) for preset {preset}.',
+  'init.initialized': 'Project initialized with tools: {tools}',
+  'init.suggests': '{rule} suggests: {suggestions}',
+  'init.presetLinked': 'Linked {count} type(s) from preset {preset}.',
+
+  'common.fileNotFound': 'File not found: {path}',
+  'env.shared': 'Shared:',
+  'env.skillSpecific': 'Skill-specific:',
+  'env.none': 'No environment files found.',
+  'env.editorFailed': 'Failed to open editor: {error}',
+  'env.masked': 'Secret values are masked; pass --reveal to print them.',
+
+  'common.unknownSource': 'Unknown source: {source}',
+  'catalog.platformTeam': 'Platform-team mode: use `git pull` in your catalog repository.',
+  'catalog.updating': 'Updating catalog...',
+  'catalog.updated': 'Catalog updated.',
+  'catalog.updateFailed': 'Failed to update catalog: {error}',
+  'catalog.status.mode': 'Mode',
+  'catalog.status.path': 'Path',
+  'catalog.status.repo': 'Repo URL',
+  'catalog.status.mirror': 'Mirror',
+  'catalog.status.updated': 'Updated',
+  'catalog.daysAgo': '{date} ({days} days ago)',
+  'catalog.notInstalled': 'Catalog not installed. Run `{command}` to clone.',
+  'catalog.stale': 'Catalog is stale. Run `{command}`.',
+  'catalog.upToDate': 'Catalog is up to date.',
+  'catalog.bundleWritten': 'Wrote {path}. Upload it as catalog/<ref>.tar.gz, or extensions/<name>/<ref>.tar.gz, under the mirror.',
+  'catalog.col.types': 'Types',
+  'catalog.total': '{count} type(s) in {sources}',
+  'catalog.noSources': 'no sources',
+  'catalog.byCategory': 'By category',
+  'catalog.byTopic': 'By topic',
+  'catalog.byVendor': 'By vendor',
+  'catalog.contextTokens': 'Context tokens: {tokens} in {count} type(s)',
+  'catalog.col.context': 'Context',
+  'catalog.col.tokens': 'Tokens',
+  'catalog.missingDescription': 'Missing a description',
+  'catalog.missingTags': 'Missing tags',
+  'catalog.withoutTests': 'Skills without tests',
+  'catalog.orphans': 'Not used by any prompt',
+
+  'tutorial.step': 'Step {n}/{total}: {title}',
+  'tutorial.continue': 'Continue?',
+  'tutorial.welcome': 'Welcome to the AgentX tutorial.',
+  'tutorial.intro': 'Everything happens in a throwaway sandbox; your real setup is not touched.\nSandbox: {sandbox}\n\nAgentX manages six kinds of types. Dependencies only point one way:\n  prompt -> persona -> context, and prompts also pull in skills and workflows.\nA sample catalog with one of each has been written to the sandbox.',
+  'tutorial.install.title': 'Install a prompt and its dependencies',
+  'tutorial.install.done': 'Installed {count} type(s) with `{command}`.',
+  'tutorial.install.explain': 'Installing a prompt resolves the whole tree: its persona, the context that\npersona needs, and its skills. Installed types live under ~/.agentx/installed/.',
+  'tutorial.link.title': 'Link types into a project',
+  'tutorial.link.done': 'Ran `agentx init --tools claude-code` and `agentx link add` for the persona and skill.',
+  'tutorial.link.explain': 'Linking records the types in .agentx/project.yaml and generates the files\neach AI tool reads (here CLAUDE.md and friends). Re-run `agentx link sync`\nafter changing installed types to regenerate them.',
+  'tutorial.run.title': 'Run a skill',
+  'tutorial.ran': 'Ran `{command}`.',
+  'tutorial.run.explain': 'Skills are executable. Their tokens, config, and saved output live in a\nper-skill registry under ~/.agentx/userdata/skills/, never in the project.',
+  'tutorial.compose.title': 'Compose the prompt',
+  'tutorial.compose.done': 'Ran `{command}` ({tokens} tokens).',
+  'tutorial.compose.explain': 'Composition stitches persona, context, and skill descriptions into one prompt.\nAdd --copy to put it on the clipboard, or --format xml|json for other layouts.',
+  'tutorial.next.title': 'Next steps',
+  'tutorial.next.init': 'set up ~/.agentx and clone the real catalog',
+  'tutorial.next.search': 'find types to install',
+  'tutorial.next.install': 'install one with its dependencies',
+  'tutorial.next.link': 'use it in the current project',
+  'tutorial.next.create': 'author your own',
+  'tutorial.complete': 'Tutorial complete.',
+  'tutorial.kept': 'Sandbox kept at {path}',
+
+  'create.invalidName': 'Invalid {label}: "{name}". Must be lowercase alphanumeric with hyphens.',
+  'create.created': 'Created {type} at {path}',
+  'create.fromConflict': '--from copies an existing type; it cannot be combined with --template or --var',
+  'create.invalidVar': 'Invalid --var "{pair}". Expected key=value.',
+  'create.topicRequired': "required option '--topic <topic>' not specified",
+  'create.unsupportedRuntime': 'Unsupported runtime: "{runtime}". Expected {expected}.',
+  'create.runtimeMismatch': "--runtime {runtime} doesn't match {target} ({actual})",
+  'create.runtimeMismatchSet': "--runtime {runtime} doesn't match template set {set} ({actual})",
+  'create.origin.builtin': 'built-in',
+  'create.origin.extension': 'extension {name}',
+  'create.origin.user': 'user',
+  'create.var.required': 'required',
+  'create.var.default': 'default: {value}',
+  'create.var.optional': 'optional',
+  'create.hook': 'hook: {hook}',
+  'create.addOwn': 'Add your own under ~/.agentx/templates/<name>/ with a scaffold.yaml',
+
+  'import.skippedGenerated': 'Skipped {file}: generated by agentx',
+  'import.wouldCreate': 'Would create extension {extension} from {files}',
+  'import.created': 'Created extension {extension} from {files}',
+  'import.linkHint': 'To install and link its types, run `{command}`',
+  'import.linkHintInit': 'To install and link its types, run `agentx init`, then `{command}`',
+  'import.installing': 'Installing {extension}...',
+  'import.imported': 'Imported {files} into extension {extension}',
+  'import.originalsCopied': 'The original files were copied to .agentx/imported/',
+  'verify.accepted': 'Accepted {type}@{version} ({count} files).',
+  'verify.skipped': '{title}: skipped',
+  'verify.problems': '{title}: {count} problem(s)',
+  'verify.failed': '{failed} of {total} check(s) failed.',
+  'verify.untracked': '{type}: installed before integrity tracking; reinstall to track it',
+  'verify.clean': '{clean}/{total} installed type(s) match their install.',
+  'search.noTypes': 'No types found.',
+  'search.deprecated': 'deprecated',
+  'search.deprecatedBy': 'deprecated, use {type}',
+  'search.queryRequired': 'A query is required with {flag}',
+  'search.noContent': 'No matching context content.',
+  'search.noRelated': 'No related context found.',
+  'common.invalidValue': 'Invalid {flag} value: "{value}"',
+  'col.score': 'Score',
+  'col.excerpt': 'Excerpt',
+  'registry.targetRequired': 'Give a skill type path or --all',
+  'registry.nothingToExport': 'No skill registries to export.',
+  'registry.recipientIgnored': '--recipient has no effect without --include-secrets',
+  'registry.exported': 'Exported {count} skill registries to {path}',
+  'registry.secretsEncrypted': '{count} token file(s) encrypted with age',
+  'registry.secretsSkipped': 'Tokens were not exported; pass --include-secrets to include them encrypted',
+  'registry.imported': 'Imported {count} skill registries',
+  'registry.secretsRestored': 'Restored {count} token file(s)',
+  'common.keptExisting': 'Kept {count} existing file(s); pass --force to overwrite:',
+
+  'registry.exportedOne': 'Exported {count} skill registry to {path}',
+  'registry.importedOne': 'Imported {count} skill registry',
+
+  'prompt.passVar': 'pass --var {name}=...',
+  'prompt.pathRequired': 'Interactive mode not yet implemented. Provide a prompt type path.',
+  'prompt.summary': '{bytes} bytes, {tokens} tokens',
+  'prompt.written': 'Written to: {path} ({summary})',
+  'prompt.copied': 'Copied to clipboard ({summary}).',
+  'prompt.notInstalled': 'not installed',
+  'prompt.notInSources': 'not in any source',
+  'prompt.unchanged': '{type} would not change.',
+  'col.file': 'File',
+  'col.size': 'Size',
+  'col.modified': 'Modified',
+  'col.skill': 'Skill',
+  'state.none': 'No state for {skill}.',
+  'state.noneAtAll': 'No skill has state.',
+  'state.nothingToClear': 'Nothing to clear for {skill}.',
+  'state.wouldDelete': 'Would delete {count} file(s), {bytes}.',
+  'state.confirmDelete': 'Delete {count} state file(s) ({bytes}) of {skill}?',
+  'state.deleted': 'Deleted {count} state file(s) of {skill}.',
+
+  'common.wrote': 'Wrote {path}',
+  'scheduler.finished': '{id}: {type} finished',
+  'scheduler.failedToStart': '{id}: {type} failed to start: {error}',
+  'scheduler.exited': '{id}: {type} exited with {code}',
+  'scheduler.running': 'Running schedules as they come due. Press Ctrl+C to stop.',
+  'scheduler.busySkipped': 'Another scheduler tick is still running; skipped this minute',
+  'scheduler.busy': 'Another scheduler tick is still running.',
+  'scheduler.unknownPlatform': 'Unknown platform "{platform}". Expected one of: {expected}',
+  'scheduler.activate': 'To turn it on, run:\n  {command}',
+  'col.since': 'Since',
+  'daemon.running': 'running (pid {pid}, {restarts} restart(s))',
+  'daemon.gaveUp': 'stopped: gave up after repeated crashes',
+  'daemon.stopped': 'stopped',
+  'daemon.exitSignal': 'signal {signal}',
+  'daemon.exitCode': 'code {code}',
+  'daemon.stoppedExit': 'stopped (last exit {exit} at {at})',
+  'daemon.started': 'Started {type} (supervisor pid {pid})',
+  'daemon.followHint': 'Follow its output with `{command}`',
+  'daemon.stoppedType': 'Stopped {type}',
+  'daemon.notRunning': '{type} is not running.',
+  'daemon.none': 'No daemon skills have been started.',
+  'daemon.noLog': '{type} has no daemon log yet.',
+  'daemon.waiting': '{type} is not running; waiting for output anyway',
+  'daemon.keptCrashing': '{type} kept crashing; gave up after {restarts} restart(s)',
+
+  'versions.default': 'default',
+  'versions.sideBySide': 'side by side',
+  'versions.storedOnly': 'stored only',
+  'versions.notInstalled': '{type} is not installed.',
+  'versions.added': 'Installed {type}@{version} side by side. Projects pinning it now use it.',
+  'versions.notSideBySide': '{type}@{version} is not installed side by side.',
+  'versions.removed': 'Removed {type}@{version}. It stays in the store.',
+  'versions.notStored': 'Version {version} of {type} is not stored. See `{command}`.',
+  'versions.alreadyDefault': '{type}@{version} is already the default.',
+  'versions.nowDefault': '{type} now defaults to {version}.',
+  'versions.nowDefaultKept': '{type} now defaults to {version} ({previous} stays installed side by side).',
+  'validate.unknownTarget': 'Not a manifest file, directory, or source: {target} (sources: {sources})',
+  'validate.noSources': 'none',
+  'validate.validFile': 'Valid: {file}',
+  'validate.invalidFile': 'Invalid: {file} ({count} issue(s))',
+  'validate.validSource': 'Valid: {source} ({types} type(s) in {root})',
+  'validate.invalidSource': 'Invalid: {source} ({count} issue(s) in {files} file(s) of {types} type(s))',
+  'sources.unknown': 'Unknown source "{name}". Use "catalog", an extension name, or a host (e.g. git.acme.com).',
+  'sources.tokenFor': 'Token for {host}:',
+  'sources.tokenHint': 'pass --token-stdin',
+  'sources.noToken': 'No token given.',
+  'sources.storedKeychain': 'Stored token for {host} in the OS keychain.',
+  'sources.storedFile': 'Stored token for {host} in {path} (mode 0600).',
+  'sources.noKeychain': 'No OS keychain found (macOS Keychain or secret-tool); using a file instead.',
+  'sources.removed': 'Removed stored token for {host}.',
+  'sources.noStored': 'No stored token for {host}.',
+  'sources.noCredentials': 'No credentials for {host}. Set {env}, add it to ~/.netrc, or run `{command}`.',
+  'sources.login': 'login {user}',
+
+  'cache.rebuilt': 'Registry index rebuilt ({count} types).',
+  'cache.name.registry': 'registry index',
+  'cache.name.run': 'run',
+  'cache.name.remote': 'remote context',
+  'cache.name.embedding': 'embedding',
+  'cache.cleared': 'Cleared {caches} cache(s).',
+  'cache.reinstallNode': 'Reinstall Node skills to restore their dependencies: `{command}`.',
+  'cache.stats.workspace': 'Workspace',
+  'cache.stats.entries': 'Entries',
+  'cache.stats.entriesValue': '{entries} ({blobs} unique outputs, {size})',
+  'cache.stats.hitRateValue': '{rate} ({hits} hits, {misses} misses)',
+  'cache.stats.node': 'Node deps',
+  'cache.stats.nodeValue': '{entries} lockfile(s), {size}',
+  'col.hits': 'Hits',
+  'col.misses': 'Misses',
+  'col.hitRate': 'Hit rate',
+
+  'backup.recipientConflict': '--recipient encrypts the backup; it cannot be combined with --plaintext',
+  'backup.created': 'Backed up {count} file(s) to {path}',
+  'backup.encrypted': 'Encrypted with age; keep the passphrase or identity to restore it',
+  'backup.redacted': 'Unencrypted: {count} secret value(s) were blanked and must be set again after restoring',
+  'backup.restored': 'Restored {count} file(s)',
+  'backup.activeProfile': 'Active profile: {profile}',
+  'backup.missingSecrets': 'This backup was made without {count} secret value(s); set them with `agentx env edit`',
+  'update.current': 'Current version: {version}',
+  'update.available': 'New version available: {version}',
+  'update.runHint': 'Run `agentx update` to install.',
+  'update.latest': 'Already on the latest version.',
+  'update.installing': 'Installing version {version}...',
+  'update.updatedTo': 'Updated to {version}',
+  'update.latestVersion': 'Already on the latest version ({version}).',
+  'update.updating': 'Updating...',
+  'update.updated': 'Updated successfully.',
+  'pack.packed': 'Packed {root} with {count} dependencies into {path}',
+  'pack.packedOne': 'Packed {root} with {count} dependency into {path}',
+  'pack.noSecrets': 'Tokens and skill registries are not included; recipients set their own with `agentx env edit`.',
+  'pack.imported': 'Imported {root}: {count} type(s) installed',
+  'pack.kept': 'Kept {count} installed type(s); pass --force to replace them:',
+
+  'col.state': 'State',
+  'overrides.none': 'No overrides in this project.',
+  'overrides.conflict': 'conflict',
+  'overrides.ok': 'ok',
+  'overrides.created': 'Created {path}. Edit it, then run `{command}`.',
+  'overrides.removed': 'Removed override of {file}.',
+  'overrides.noConflicts': 'No conflicted overrides.',
+  'overrides.skipped': 'Skipped {file}',
+  'overrides.resolved': 'Resolved {file} ({resolution})',
+  'overrides.syncHint': 'Run `{command}` to apply.',
+  'overrides.askResolution': '{file} has conflicts with upstream:',
+  'overrides.choice.ours': 'Keep my override in every conflict',
+  'overrides.choice.theirs': 'Take upstream and drop my override',
+  'overrides.choice.merged': 'I resolved the markers by hand',
+  'overrides.choice.skip': 'Skip for now',
+  'overrides.resolutionHint': 'pass --ours, --theirs, or --merged',
+  'info.installed': 'installed',
+  'info.notInstalled': 'not installed',
+  'info.from': 'from {source}',
+  'info.metadata': 'Metadata',
+  'info.dependencies': 'Dependencies',
+  'info.cliDependencies': 'CLI dependencies',
+  'info.tokens': 'Tokens',
+  'info.set': 'set',
+  'info.unset': 'unset',
+  'info.registryConfig': 'Registry config',
+  'info.linkedBy': 'Linked by',
+  'info.notLinked': 'No projects found linking this type.',
+  'col.token': 'Token',
+  'col.required': 'Required',
+  'col.from': 'From',
+  'col.key': 'Key',
+  'col.value': 'Value',
+  'col.default': 'Default',
+  'gc.typesSkipped': 'Installed types were not checked: {reason}',
+  'gc.nothing': 'Nothing to collect.',
+  'col.kind': 'Kind',
+  'col.target': 'Target',
+  'col.why': 'Why',
+  'gc.summary': '{count} item(s), {size} reclaimable ({projects} known project(s)).',
+  'gc.confirm': 'Delete {count} item(s) ({size})?',
+  'gc.deleteFailed': 'Could not delete {target}: {error}',
+  'gc.reclaimed': 'Reclaimed {size}.',
+  'gc.reinstallHint': 'Reinstall a removed type with `{command}`.',
+  'gc.unreachable': 'Could not reach {count} recorded project(s); what they use may be collected only with {flag}: {paths}',
+  'gc.needsForce': '{count} installed type(s) or version(s) may still be used by projects {app} does not know about; pass {flag} to delete them too.',
+
+  'common.yes': 'yes',
+  'common.no': 'no',
+
+  'githooks.notGit': 'Not inside a git repository.',
+  'githooks.notProject': '{root} is not an {app} project; run `{command}` at the repository root first.',
+  'githooks.lefthook': 'Run `lefthook install` if lefthook is not yet active in this clone.',
+  'githooks.installed': 'Commits now fail while generated tool files are out of date.',
+  'githooks.none': 'No hooks to remove.',
+  'githooks.removed': 'Removed hook from {path}',
+  'stats.cleared': 'Usage stats cleared.',
+  'stats.off': 'Usage stats are off. `{command}` keeps them on this machine only.',
+  'stats.header': 'Telemetry: {mode}   Since: {since}   File: {path}',
+  'stats.mostUsed': 'Most-used skills',
+  'stats.slowest': 'Slowest commands',
+  'stats.sent': 'Sent {count} telemetry events.',
+  'col.runs': 'Runs',
+  'col.failed': 'Failed',
+  'col.avg': 'Avg',
+  'col.max': 'Max',
+  'col.command': 'Command',
+  'schedule.error': 'error: {error}',
+  'schedule.unknown': 'unknown',
+  'schedule.exit': 'exit {code}',
+  'schedule.never': 'never',
+  'schedule.added': 'Scheduled {type} as {id}; next run {next}',
+  'schedule.howItRuns': 'Schedules run while `{run}` is running, or after `{install}` sets up an OS timer.',
+  'schedule.none': 'No schedules. Add one with `{command}`.',
+  'schedule.notFound': 'No schedule named {id}',
+  'schedule.removed': 'Removed schedule {id}',
+  'schedule.noRuns': 'No scheduled runs yet.',
+  'col.id': 'Id',
+  'col.cron': 'Cron',
+  'col.nextRun': 'Next run',
+  'col.lastRun': 'Last run',
+  'col.due': 'Due',
+  'col.took': 'Took',
+  'col.result': 'Result',
+
+  'profile.none': 'No profiles found. Run `agentx init --global` first.',
+  'profile.active': 'active',
+  'profile.switched': 'Switched to profile: {name}',
+  'profile.noActive': 'No active profile.',
+  'projects.none': 'No known projects. Projects are recorded by init, import, and the link commands.',
+  'projects.noneToSync': 'No known projects to sync.',
+  'projects.synced': 'Synced {path}',
+  'col.project': 'Project',
+  'col.tools': 'Tools',
+  'col.linked': 'Linked',
+  'col.lastSync': 'Last sync',
+  'config.set': 'Set {key} = {value} ({scope})',
+  'config.unset': 'Unset {key} ({scope})',
+  'config.notSet': '{key} is not set in the {scope} config.',
+  'config.none': 'No settings. See `config list --all` for the known keys.',
+
+  'rollback.noSnapshots': 'No stored versions for {type}. Install it first.',
+  'col.stored': 'Stored',
+  'rollback.current': 'current',
+  'rollback.noOlder': 'No version older than {version} is stored for {type}.',
+  'rollback.noOlderThanCurrent': 'No version older than the current one is stored for {type}.',
+  'rollback.done': '{type} is now at {version}.',
+  'rollback.doneFrom': '{type} is now at {version} (was {previous}).',
+  'output.none': 'No output history for {skill}.',
+  'col.run': 'Run',
+  'col.saved': 'Saved',
+  'output.invalidRun': '--run must be a positive number',
+  'output.entry': 'Run {run}, saved {saved}',
+
+  'graph.unknownFormat': 'Unknown graph format: {format}. Use json or dot.',
+  'graph.notProject': 'Not an agentx project. Run `agentx init` or pass --workspace.',
+  'graph.written': 'Wrote graph of {projects} project(s), {links} link(s) to {path}',
+  'trust.none': 'No approved contributions.',
+  'trust.nothingToRevoke': 'Nothing to revoke.',
+  'trust.revoked': 'Revoked {count} approval(s) for {target}.',
+  'col.extension': 'Extension',
+  'col.approved': 'Approved',
+  'col.digest': 'Digest',
+  'template.notInstalled': 'Template not installed: {type}.{hint}',
+  'template.written': 'Written to: {path}',
+  'serve.tokenRequired': 'The HTTP API requires a token: run `{command}` or set {env}',
+  'serve.listening': 'Serving the {app} API on {url}',
+  'serve.authHint': 'Send `Authorization: Bearer <serve.token>` with every request. Press Ctrl+C to stop.',
+
+  'list.none': 'No installed types found.',
+  'col.installed': 'Installed',
+  'list.outdated': '{count} type(s) have a newer version. Run `{command}` to update.',
+  'health.unknownBadgeFormat': 'Unknown badge format: "{format}". Expected svg or json.',
+  'health.title': 'Project health: {score} (grade {grade})',
+  'health.weight': 'weight {weight}',
+  'export.done': 'Exported {count} file(s) to {path}',
+  'col.priority': 'Priority',
+  'col.provides': 'Provides',
+  'col.notes': 'Notes',
+
+  'version.line': '{app} version {version} (commit: {commit}, built: {date})',
+  'preset.none': 'No presets found. Catalogs and extensions define them in presets/<name>.yaml.',
+  'pipe.failed': '{type} exited with code {code}; stopped the pipe',
+  'deps.noDependents': 'Nothing references {type}.',
+  'uninstall.removed': 'Removed: {type}',
+
+  'test.notSkill': '{skill} is not a skill (no skill.yaml)',
+  'test.skillNotFound': 'Skill not found: {skill}.{hint}',
+
+  'bench.col.baseline': 'baseline',
+  'bench.col.current': 'current',
+  'bench.col.change': 'change',
+
+  'doctor.orphanHint': 'pass --orphans archive|delete|keep',
+} as const;
+
+export type MessageKey = keyof typeof en;
@@ -0,0 +1,576 @@
+import type { MessageKey } from './en.js';
+
+// Spanish. Commands, flags, and placeholders stay as they are in English.
+
+export const es: Record<MessageKey, string> = {
+  'common.didYouMean': '¿Quisiste decir alguno de estos?',
+
+  'error.seeExplain': 'consulta `{command}`',
+
+  'prompt.decisionNeeded': 'Se necesita una decisión: {question}{hint}',
+  'prompt.inputDisabled': 'Se necesita una respuesta, pero las preguntas están desactivadas: {question}{hint}',
+  'prompt.passYes': 'usa --yes',
+  'prompt.allow': '¿Permitir esto?',
+
+  'lock.waiting': 'Esperando a que termine otro proceso de agentx (pid {pid}, `{command}`)...',
+  'trace.summary': 'Se registraron {count} llamadas al sistema de archivos: {kinds}. Detalles en {path}',
+
+  'explain.col.id': 'Código o tema',
+  'explain.col.about': 'Descripción',
+  'explain.notFound': 'No hay explicación para "{query}". Ejecuta `agentx explain` para ver los códigos y temas.',
+  'explain.fixes': 'Cómo solucionarlo:',
+  'explain.seeAlso': 'Ver también: {items}',
+
+  'test.noMatch': 'Ningún caso de prueba coincide con --case.',
+  'test.noTests': '{skill} no declara pruebas.',
+  'test.summary': '{passed} correctas, {failed} fallidas',
+
+  'bench.progress': 'ejecución {n}/{total}',
+  'bench.summary': '{skill} {version} ({runtime}): {iterations} ejecución(es), {warmup} de calentamiento',
+  'bench.noCpu': 'El tiempo de CPU no se mide en esta plataforma.',
+  'bench.against': 'Comparado con {skill} {version}:',
+  'bench.written': 'Informe guardado en {path}',
+  'bench.failed': '{failed} de {total} ejecución(es) terminaron con un código distinto de cero',
+
+  'dashboard.installed': 'Instalados',
+  'dashboard.projects': 'Proyectos',
+  'dashboard.extensions': 'Extensiones',
+  'dashboard.runs': 'Ejecuciones recientes',
+  'dashboard.col.type': 'Tipo',
+  'dashboard.col.version': 'Versión',
+  'dashboard.col.latest': 'Última',
+  'dashboard.col.project': 'Proyecto',
+  'dashboard.col.linked': 'Enlazados',
+  'dashboard.col.pending': 'Pendiente',
+  'dashboard.col.lastSync': 'Última sincronización',
+  'dashboard.col.extension': 'Extensión',
+  'dashboard.col.status': 'Estado',
+  'dashboard.col.skill': 'Skill',
+  'dashboard.col.when': 'Cuándo',
+  'dashboard.col.output': 'Salida',
+  'dashboard.action.install': 'instalar/actualizar',
+  'dashboard.action.run': 'ejecutar',
+  'dashboard.action.linkSync': 'link sync',
+  'dashboard.action.syncAll': 'sincronizar todo',
+  'dashboard.action.showOutput': 'ver salida',
+  'dashboard.action.runAgain': 'volver a ejecutar',
+  'dashboard.key.pane': 'tab/1-4 panel',
+  'dashboard.key.select': '↑↓ seleccionar',
+  'dashboard.key.reload': 'R recargar',
+  'dashboard.key.quit': 'q salir',
+  'dashboard.never': 'nunca',
+  'dashboard.inSync': 'sincronizado',
+  'dashboard.changes': '{count} cambio(s)',
+  'dashboard.unreadable': 'ilegible',
+  'dashboard.empty': 'no hay nada',
+  'dashboard.loading': 'Cargando…',
+  'dashboard.sourceWarnings': '{count} advertencia(s) al leer las fuentes',
+  'dashboard.notApplicable': '{action} no se aplica a {row}',
+  'dashboard.exited': '{command} terminó con el código {code}',
+  'dashboard.externalChange': 'Recargado: otro proceso de agentx cambió {types}',
+  'dashboard.reloadFailed': 'No se pudo recargar: {message}',
+  'dashboard.pressAnyKey': 'Pulsa cualquier tecla para volver al panel',
+  'dashboard.needsTerminal': 'agentx ui necesita una terminal interactiva',
+
+  'common.notInstalled': 'Tipo no instalado: {type}. Ejecuta primero `agentx install {type}`.',
+  'common.notInstalledHint': 'Tipo no instalado: {type}.{hint}',
+  'common.inputPrecedence': 'Las entradas se toman de {order}, de mayor a menor prioridad',
+  'run.cacheIgnoredDev': '--cache se ignora con --dev; los cambios en el código fuente no cambiarían la clave de caché',
+  'run.noManifest': 'No se encontró ningún manifiesto en: {dir}',
+  'run.invalidWorkflow': 'Flujo de trabajo no válido: {workflow}; revísalo con `{command}`',
+  'run.publishSkipped': '{step}: la publicación en {to} se omite en el sandbox',
+  'run.published': '{step}: se publicaron {bytes} bytes ({contentType}) en {destination}',
+  'run.stepNotFound': 'La skill del paso no está en ninguna fuente: {skill}',
+  'run.stepNotInstalled': 'La skill del paso no está instalada: {skill}',
+  'run.stepNoManifest': 'No hay manifiesto para el paso del flujo: {skill}',
+  'run.notRunnable': 'No se puede ejecutar el tipo: {type}. Solo se pueden ejecutar skills y flujos de trabajo.',
+  'run.devFrom': 'Ejecutando {type} desde {dir}',
+  'run.manifestModified': 'El manifiesto de {type} se modificó después de instalarlo. Revisa los cambios (`{verify}`) y luego ejecuta `{accept}`.',
+  'run.manifestGuard': '{message} Configura run.manifest_guard como "warn" para ejecutarlo de todos modos.',
+  'run.tokensMissing': '{type} requiere {names}; defínelo en el entorno o en {path}',
+  'run.needsValue': '{type} necesita {name}{about}:',
+  'run.tokenRequired': '{type} requiere {name}',
+  'run.saveToken': '¿Guardar {name} en {path} para próximas ejecuciones?',
+  'run.tokenSaved': 'Se guardó {name} en {path}',
+  'run.noAccount': '{type} no tiene tokens para la cuenta "{account}" ({path}).{known} Créalos con `{command}`.',
+  'run.knownAccounts': ' Sus cuentas: {accounts}.',
+  'run.mayUseNetwork': '{type} puede acceder a la red, y el sandbox no lo impide:',
+  'run.sandboxUnchanged': '{type} no cambió su registro.',
+  'run.sandboxChanged': '{type} cambió su registro (cambios descartados):',
+  'run.stdinNeedsPipe': '--stdin-input necesita datos canalizados por stdin',
+
+  'common.typeNotFound': 'Tipo no encontrado: {type}{hint}',
+  'common.moved': '{from} se movió a {to}.',
+  'common.deprecated': '{type} está obsoleto{successor}.',
+  'common.useInstead': '; usa {type} en su lugar',
+  'common.cancelled': 'Cancelado.',
+  'install.jsonNeedsYes': 'install --json no puede pedir confirmación; pasa --yes',
+  'install.installInstead': '¿Instalar {type} en su lugar?',
+  'install.dependencyDeprecated': 'La dependencia {type} está obsoleta{successor}.',
+  'install.nothingToInstall': 'Nada que instalar: todos los tipos ya están presentes.',
+  'install.proceed': '¿Continuar con la instalación?',
+  'install.installing': 'Instalando {name}...',
+  'install.done': 'listo ({changes})',
+  'install.failed': 'falló',
+  'install.notRestored': '; no se pudo restaurar {types}',
+  'install.rolledBack': '; se revirtieron todos los cambios',
+  'install.installFailed': 'Falló la instalación de {type}: {message}{restored}',
+  'install.installed': 'Se instalaron {count} tipo(s).',
+  'install.plan': 'Plan de instalación:',
+  'install.toInstall': 'Tipos por instalar: {counts}',
+  'install.alreadyInstalled': 'Ya instalados: {count}',
+  'install.cliDependencies': 'Dependencias de CLI:',
+  'install.noFileChanges': 'sin cambios de archivos',
+  'install.filesAdded': '{count} añadidos',
+  'install.filesUpdated': '{count} actualizados',
+  'install.filesRemoved': '{count} eliminados',
+  'install.filesUnchanged': '{count} sin cambios',
+
+  'col.tool': 'Herramienta',
+  'col.action': 'Acción',
+  'col.path': 'Ruta',
+  'col.status': 'Estado',
+  'col.type': 'Tipo',
+  'col.version': 'Versión',
+  'col.name': 'Nombre',
+  'col.description': 'Descripción',
+  'col.category': 'Categoría',
+  'col.source': 'Fuente',
+  'link.upToDate': 'Los archivos generados están al día; no cambiaría nada.',
+  'link.linkPath': '{path} (enlace)',
+  'link.none': '(ninguno)',
+  'link.removed': '(eliminado)',
+  'link.dryRun': 'Simulación: {count} cambio(s) sin aplicar.',
+  'link.versionSkew': 'La configuración de este proyecto se generó con {app} {recorded}; estás usando {current} ({direction}). Los formatos generados pueden variar. Ejecuta `{command}` para normalizarlos.',
+  'link.linked': 'Enlazado: {type}',
+  'link.unlinked': 'Desenlazado: {type}',
+  'link.synced': '{tool}: {created} creados, {updated} actualizados, {symlinked} enlazados',
+  'link.noTools': 'No hay herramientas configuradas.',
+  'link.col.files': 'Archivos',
+  'link.col.symlinks': 'Enlaces',
+  'link.copied': '({count} copiados)',
+  'link.broken': 'Algunos enlaces están rotos o sus copias están desactualizadas. Ejecuta `{command}` para repararlos.',
+
+  'doctor.invalidOrphans': '--orphans "{value}" no es válido. Usa uno de: {actions}',
+  'doctor.orphanQuestion': '{skill} ya no está instalada. ¿Qué hacemos con su registro?',
+  'doctor.orphanArchive': 'Archivarlo (userdata/archive/)',
+  'doctor.orphanDelete': 'Eliminarlo',
+  'doctor.orphanKeep': 'Conservarlo',
+  'doctor.noRepairs': 'El árbol de userdata no necesita reparaciones.',
+  'doctor.title': 'Diagnóstico de AgentX',
+  'doctor.mode': 'Modo: {mode}',
+  'doctor.section.runtime': 'Entorno de ejecución',
+  'doctor.section.userdata': 'Datos de usuario',
+  'doctor.section.cli': 'Dependencias de CLI',
+  'doctor.section.state': 'Estado de las skills',
+  'doctor.section.manifest': 'Validación del manifiesto',
+  'doctor.complete': 'Diagnóstico completado.',
+
+  'extension.adding': 'Añadiendo la extensión {name}...',
+  'extension.added': 'Extensión añadida: {name}',
+  'extension.creating': 'Creando la extensión {name}...',
+  'extension.created': 'Se creó la extensión {name} en {dir}',
+  'extension.pushed': 'Publicada en {remote} ({branch})',
+  'extension.publishHint': 'Publícala en un remoto de git y luego ejecuta: {command}',
+  'extension.removed': 'Extensión eliminada: {name}',
+  'extension.none': 'No se encontraron extensiones.',
+  'extension.col.branch': 'Rama',
+  'extension.syncing': 'Sincronizando extensiones...',
+  'extension.synced': 'Extensiones sincronizadas.',
+  'extension.trustReview': 'La extensión del proyecto {name} en {path} se cargará como origen, con sus tipos, plantillas, hooks y reglas de detección. Revise sus archivos antes.',
+  'extension.trusted': 'Extensión del proyecto de confianza: {name}',
+  'extension.trustDeclined': 'Sin confianza: {name}',
+  'extension.trustNone': 'Todas las extensiones del proyecto ya son de confianza.',
+  'extension.trustUnknown': 'No hay ninguna extensión del proyecto llamada {name} en {dir}',
+
+  'init.global': 'Inicializando los datos de usuario globales...',
+  'init.cloningCatalog': 'Clonando el catálogo...',
+  'init.globalDone': 'Inicialización global completada.',
+  'init.alreadyInitialized': 'El proyecto ya está inicializado.',
+  'init.installingPreset': 'Instalando el preset {preset}...',
+  'init.presetInstalled': 'Se instalaron {count} tipo(s) para el preset {preset}.',
+  'init.initialized': 'Proyecto inicializado con las herramientas: {tools}',
+  'init.suggests': '{rule} sugiere: {suggestions}',
+  'init.presetLinked': 'Se enlazaron {count} tipo(s) del preset {preset}.',
+
+  'common.fileNotFound': 'Archivo no encontrado: {path}',
+  'env.shared': 'Compartidos:',
+  'env.skillSpecific': 'Por skill:',
+  'env.none': 'No se encontraron archivos de entorno.',
+  'env.editorFailed': 'No se pudo abrir el editor: {error}',
+  'env.masked': 'Los valores secretos están ocultos; pasa --reveal para mostrarlos.',
+
+  'common.unknownSource': 'Fuente desconocida: {source}',
+  'catalog.platformTeam': 'Modo platform-team: usa `git pull` en tu repositorio del catálogo.',
+  'catalog.updating': 'Actualizando el catálogo...',
+  'catalog.updated': 'Catálogo actualizado.',
+  'catalog.updateFailed': 'No se pudo actualizar el catálogo: {error}',
+  'catalog.status.mode': 'Modo',
+  'catalog.status.path': 'Ruta',
+  'catalog.status.repo': 'URL repo',
+  'catalog.status.mirror': 'Espejo',
+  'catalog.status.updated': 'Última',
+  'catalog.daysAgo': '{date} (hace {days} días)',
+  'catalog.notInstalled': 'El catálogo no está instalado. Ejecuta `{command}` para clonarlo.',
+  'catalog.stale': 'El catálogo está desactualizado. Ejecuta `{command}`.',
+  'catalog.upToDate': 'El catálogo está al día.',
+  'catalog.bundleWritten': 'Se escribió {path}. Súbelo al espejo como catalog/<ref>.tar.gz, o extensions/<name>/<ref>.tar.gz.',
+  'catalog.col.types': 'Tipos',
+  'catalog.total': '{count} tipo(s) en {sources}',
+  'catalog.noSources': 'ninguna fuente',
+  'catalog.byCategory': 'Por categoría',
+  'catalog.byTopic': 'Por tema',
+  'catalog.byVendor': 'Por proveedor',
+  'catalog.contextTokens': 'Tokens de contexto: {tokens} en {count} tipo(s)',
+  'catalog.col.context': 'Contexto',
+  'catalog.col.tokens': 'Tokens',
+  'catalog.missingDescription': 'Sin descripción',
+  'catalog.missingTags': 'Sin etiquetas',
+  'catalog.withoutTests': 'Skills sin pruebas',
+  'catalog.orphans': 'Sin uso en ningún prompt',
+
+  'tutorial.step': 'Paso {n}/{total}: {title}',
+  'tutorial.continue': '¿Continuar?',
+  'tutorial.welcome': 'Te damos la bienvenida al tutorial de AgentX.',
+  'tutorial.intro': 'Todo ocurre en un sandbox desechable; tu configuración real no se toca.\nSandbox: {sandbox}\n\nAgentX gestiona seis clases de tipos. Las dependencias van en un solo sentido:\n  prompt -> persona -> context, y los prompts también incluyen skills y workflows.\nSe ha escrito en el sandbox un catálogo de ejemplo con uno de cada clase.',
+  'tutorial.install.title': 'Instala un prompt y sus dependencias',
+  'tutorial.install.done': 'Se instalaron {count} tipo(s) con `{command}`.',
+  'tutorial.install.explain': 'Instalar un prompt resuelve todo el árbol: su persona, el contexto que\nnecesita esa persona y sus skills. Los tipos instalados viven en ~/.agentx/installed/.',
+  'tutorial.link.title': 'Enlaza tipos en un proyecto',
+  'tutorial.link.done': 'Se ejecutaron `agentx init --tools claude-code` y `agentx link add` para la persona y la skill.',
+  'tutorial.link.explain': 'Enlazar registra los tipos en .agentx/project.yaml y genera los archivos\nque lee cada herramienta de IA (aquí CLAUDE.md y compañía). Vuelve a ejecutar\n`agentx link sync` tras cambiar los tipos instalados para regenerarlos.',
+  'tutorial.run.title': 'Ejecuta una skill',
+  'tutorial.ran': 'Se ejecutó `{command}`.',
+  'tutorial.run.explain': 'Las skills son ejecutables. Sus tokens, configuración y salida guardada viven en un\nregistro por skill en ~/.agentx/userdata/skills/, nunca en el proyecto.',
+  'tutorial.compose.title': 'Compón el prompt',
+  'tutorial.compose.done': 'Se ejecutó `{command}` ({tokens} tokens).',
+  'tutorial.compose.explain': 'La composición une persona, contexto y descripciones de skills en un solo prompt.\nAñade --copy para copiarlo al portapapeles, o --format xml|json para otros formatos.',
+  'tutorial.next.title': 'Siguientes pasos',
+  'tutorial.next.init': 'prepara ~/.agentx y clona el catálogo real',
+  'tutorial.next.search': 'busca tipos para instalar',
+  'tutorial.next.install': 'instala uno con sus dependencias',
+  'tutorial.next.link': 'úsalo en el proyecto actual',
+  'tutorial.next.create': 'crea los tuyos',
+  'tutorial.complete': 'Tutorial completado.',
+  'tutorial.kept': 'Sandbox conservado en {path}',
+
+  'create.invalidName': '{label} no válido: "{name}". Debe usar minúsculas, números y guiones.',
+  'create.created': 'Se creó {type} en {path}',
+  'create.fromConflict': '--from copia un tipo existente; no se puede combinar con --template ni --var',
+  'create.invalidVar': '--var "{pair}" no válido. Se esperaba clave=valor.',
+  'create.topicRequired': "falta la opción obligatoria '--topic <topic>'",
+  'create.unsupportedRuntime': 'Runtime no admitido: "{runtime}". Se esperaba {expected}.',
+  'create.runtimeMismatch': '--runtime {runtime} no coincide con {target} ({actual})',
+  'create.runtimeMismatchSet': '--runtime {runtime} no coincide con el conjunto de plantillas {set} ({actual})',
+  'create.origin.builtin': 'integrado',
+  'create.origin.extension': 'extensión {name}',
+  'create.origin.user': 'usuario',
+  'create.var.required': 'obligatorio',
+  'create.var.default': 'por defecto: {value}',
+  'create.var.optional': 'opcional',
+  'create.hook': 'hook: {hook}',
+  'create.addOwn': 'Añade los tuyos en ~/.agentx/templates/<nombre>/ con un scaffold.yaml',
+
+  'import.skippedGenerated': 'Se omitió {file}: lo generó agentx',
+  'import.wouldCreate': 'Se crearía la extensión {extension} a partir de {files}',
+  'import.created': 'Se creó la extensión {extension} a partir de {files}',
+  'import.linkHint': 'Para instalar y enlazar sus tipos, ejecuta `{command}`',
+  'import.linkHintInit': 'Para instalar y enlazar sus tipos, ejecuta `agentx init` y luego `{command}`',
+  'import.installing': 'Instalando {extension}...',
+  'import.imported': 'Se importó {files} en la extensión {extension}',
+  'import.originalsCopied': 'Los archivos originales se copiaron a .agentx/imported/',
+  'verify.accepted': 'Se aceptó {type}@{version} ({count} archivos).',
+  'verify.skipped': '{title}: omitido',
+  'verify.problems': '{title}: {count} problema(s)',
+  'verify.failed': 'Fallaron {failed} de {total} comprobación(es).',
+  'verify.untracked': '{type}: se instaló antes del control de integridad; reinstálalo para controlarlo',
+  'verify.clean': '{clean}/{total} tipo(s) instalado(s) coinciden con su instalación.',
+  'search.noTypes': 'No se encontraron tipos.',
+  'search.deprecated': 'obsoleto',
+  'search.deprecatedBy': 'obsoleto, usa {type}',
+  'search.queryRequired': 'Se necesita una consulta con {flag}',
+  'search.noContent': 'Ningún contenido de contexto coincide.',
+  'search.noRelated': 'No se encontró contexto relacionado.',
+  'common.invalidValue': 'Valor de {flag} no válido: "{value}"',
+  'col.score': 'Puntuación',
+  'col.excerpt': 'Extracto',
+  'registry.targetRequired': 'Indica la ruta de una skill o --all',
+  'registry.nothingToExport': 'No hay registros de skills que exportar.',
+  'registry.recipientIgnored': '--recipient no tiene efecto sin --include-secrets',
+  'registry.exported': 'Se exportaron {count} registros de skills a {path}',
+  'registry.secretsEncrypted': '{count} archivo(s) de tokens cifrados con age',
+  'registry.secretsSkipped': 'No se exportaron los tokens; pasa --include-secrets para incluirlos cifrados',
+  'registry.imported': 'Se importaron {count} registros de skills',
+  'registry.secretsRestored': 'Se restauraron {count} archivo(s) de tokens',
+  'common.keptExisting': 'Se conservaron {count} archivo(s) existentes; pasa --force para sobrescribir:',
+
+  'registry.exportedOne': 'Se exportó {count} registro de skill a {path}',
+  'registry.importedOne': 'Se importó {count} registro de skill',
+
+  'prompt.passVar': 'pasa --var {name}=...',
+  'prompt.pathRequired': 'El modo interactivo aún no existe. Indica la ruta de un prompt.',
+  'prompt.summary': '{bytes} bytes, {tokens} tokens',
+  'prompt.written': 'Escrito en: {path} ({summary})',
+  'prompt.copied': 'Copiado al portapapeles ({summary}).',
+  'prompt.notInstalled': 'no instalado',
+  'prompt.notInSources': 'en ninguna fuente',
+  'prompt.unchanged': '{type} no cambiaría.',
+  'col.file': 'Archivo',
+  'col.size': 'Tamaño',
+  'col.modified': 'Modificado',
+  'col.skill': 'Skill',
+  'state.none': '{skill} no tiene estado.',
+  'state.noneAtAll': 'Ninguna skill tiene estado.',
+  'state.nothingToClear': 'Nada que borrar en {skill}.',
+  'state.wouldDelete': 'Se borrarían {count} archivo(s), {bytes}.',
+  'state.confirmDelete': '¿Borrar {count} archivo(s) de estado ({bytes}) de {skill}?',
+  'state.deleted': 'Se borraron {count} archivo(s) de estado de {skill}.',
+
+  'common.wrote': 'Se escribió {path}',
+  'scheduler.finished': '{id}: {type} terminó',
+  'scheduler.failedToStart': '{id}: {type} no pudo arrancar: {error}',
+  'scheduler.exited': '{id}: {type} salió con {code}',
+  'scheduler.running': 'Ejecutando las programaciones cuando toca. Pulsa Ctrl+C para parar.',
+  'scheduler.busySkipped': 'Otra pasada del planificador sigue en curso; se omitió este minuto',
+  'scheduler.busy': 'Otra pasada del planificador sigue en curso.',
+  'scheduler.unknownPlatform': 'Plataforma desconocida "{platform}". Se esperaba una de: {expected}',
+  'scheduler.activate': 'Para activarlo, ejecuta:\n  {command}',
+  'col.since': 'Desde',
+  'daemon.running': 'en marcha (pid {pid}, {restarts} reinicio(s))',
+  'daemon.gaveUp': 'detenido: se abandonó tras fallos repetidos',
+  'daemon.stopped': 'detenido',
+  'daemon.exitSignal': 'señal {signal}',
+  'daemon.exitCode': 'código {code}',
+  'daemon.stoppedExit': 'detenido (última salida {exit} a las {at})',
+  'daemon.started': 'Se inició {type} (pid del supervisor {pid})',
+  'daemon.followHint': 'Sigue su salida con `{command}`',
+  'daemon.stoppedType': 'Se detuvo {type}',
+  'daemon.notRunning': '{type} no está en marcha.',
+  'daemon.none': 'No se ha iniciado ninguna skill daemon.',
+  'daemon.noLog': '{type} aún no tiene log de daemon.',
+  'daemon.waiting': '{type} no está en marcha; se espera su salida de todos modos',
+  'daemon.keptCrashing': '{type} siguió fallando; se abandonó tras {restarts} reinicio(s)',
+
+  'versions.default': 'por defecto',
+  'versions.sideBySide': 'en paralelo',
+  'versions.storedOnly': 'solo almacenada',
+  'versions.notInstalled': '{type} no está instalado.',
+  'versions.added': 'Se instaló {type}@{version} en paralelo. Los proyectos que la fijan ya la usan.',
+  'versions.notSideBySide': '{type}@{version} no está instalada en paralelo.',
+  'versions.removed': 'Se quitó {type}@{version}. Sigue en el almacén.',
+  'versions.notStored': 'La versión {version} de {type} no está almacenada. Consulta `{command}`.',
+  'versions.alreadyDefault': '{type}@{version} ya es la versión por defecto.',
+  'versions.nowDefault': '{type} usa ahora {version} por defecto.',
+  'versions.nowDefaultKept': '{type} usa ahora {version} por defecto ({previous} sigue instalada en paralelo).',
+  'validate.unknownTarget': 'No es un manifiesto, directorio ni fuente: {target} (fuentes: {sources})',
+  'validate.noSources': 'ninguna',
+  'validate.validFile': 'Válido: {file}',
+  'validate.invalidFile': 'No válido: {file} ({count} problema(s))',
+  'validate.validSource': 'Válido: {source} ({types} tipo(s) en {root})',
+  'validate.invalidSource': 'No válido: {source} ({count} problema(s) en {files} archivo(s) de {types} tipo(s))',
+  'sources.unknown': 'Fuente desconocida "{name}". Usa "catalog", el nombre de una extensión o un host (p. ej. git.acme.com).',
+  'sources.tokenFor': 'Token para {host}:',
+  'sources.tokenHint': 'pasa --token-stdin',
+  'sources.noToken': 'No se indicó ningún token.',
+  'sources.storedKeychain': 'Token de {host} guardado en el llavero del sistema.',
+  'sources.storedFile': 'Token de {host} guardado en {path} (modo 0600).',
+  'sources.noKeychain': 'No hay llavero del sistema (Llavero de macOS o secret-tool); se usa un archivo.',
+  'sources.removed': 'Se quitó el token guardado de {host}.',
+  'sources.noStored': 'No hay token guardado para {host}.',
+  'sources.noCredentials': 'No hay credenciales para {host}. Define {env}, añádelo a ~/.netrc o ejecuta `{command}`.',
+  'sources.login': 'usuario {user}',
+
+  'cache.rebuilt': 'Índice del registro reconstruido ({count} tipos).',
+  'cache.name.registry': 'índice del registro',
+  'cache.name.run': 'ejecuciones',
+  'cache.name.remote': 'contexto remoto',
+  'cache.name.embedding': 'embeddings',
+  'cache.cleared': 'Se vaciaron las cachés: {caches}.',
+  'cache.reinstallNode': 'Reinstala las skills de Node para recuperar sus dependencias: `{command}`.',
+  'cache.stats.workspace': 'Espacio',
+  'cache.stats.entries': 'Entradas',
+  'cache.stats.entriesValue': '{entries} ({blobs} salidas únicas, {size})',
+  'cache.stats.hitRateValue': '{rate} ({hits} aciertos, {misses} fallos)',
+  'cache.stats.node': 'Deps Node',
+  'cache.stats.nodeValue': '{entries} lockfile(s), {size}',
+  'col.hits': 'Aciertos',
+  'col.misses': 'Fallos',
+  'col.hitRate': 'Aciertos %',
+
+  'backup.recipientConflict': '--recipient cifra la copia; no se puede combinar con --plaintext',
+  'backup.created': 'Se copiaron {count} archivo(s) en {path}',
+  'backup.encrypted': 'Cifrado con age; guarda la frase de paso o la identidad para restaurarlo',
+  'backup.redacted': 'Sin cifrar: se vaciaron {count} valor(es) secreto(s) que habrá que definir de nuevo tras restaurar',
+  'backup.restored': 'Se restauraron {count} archivo(s)',
+  'backup.activeProfile': 'Perfil activo: {profile}',
+  'backup.missingSecrets': 'Esta copia se hizo sin {count} valor(es) secreto(s); defínelos con `agentx env edit`',
+  'update.current': 'Versión actual: {version}',
+  'update.available': 'Hay una versión nueva: {version}',
+  'update.runHint': 'Ejecuta `agentx update` para instalarla.',
+  'update.latest': 'Ya tienes la última versión.',
+  'update.installing': 'Instalando la versión {version}...',
+  'update.updatedTo': 'Actualizado a {version}',
+  'update.latestVersion': 'Ya tienes la última versión ({version}).',
+  'update.updating': 'Actualizando...',
+  'update.updated': 'Actualización completada.',
+  'pack.packed': 'Se empaquetó {root} con {count} dependencias en {path}',
+  'pack.packedOne': 'Se empaquetó {root} con {count} dependencia en {path}',
+  'pack.noSecrets': 'No se incluyen tokens ni registros de skills; quien lo reciba define los suyos con `agentx env edit`.',
+  'pack.imported': 'Se importó {root}: {count} tipo(s) instalado(s)',
+  'pack.kept': 'Se conservaron {count} tipo(s) instalados; pasa --force para reemplazarlos:',
+
+  'col.state': 'Estado',
+  'overrides.none': 'Este proyecto no tiene overrides.',
+  'overrides.conflict': 'conflicto',
+  'overrides.ok': 'ok',
+  'overrides.created': 'Se creó {path}. Edítalo y luego ejecuta `{command}`.',
+  'overrides.removed': 'Se quitó el override de {file}.',
+  'overrides.noConflicts': 'No hay overrides con conflictos.',
+  'overrides.skipped': 'Se omitió {file}',
+  'overrides.resolved': 'Se resolvió {file} ({resolution})',
+  'overrides.syncHint': 'Ejecuta `{command}` para aplicarlo.',
+  'overrides.askResolution': '{file} tiene conflictos con upstream:',
+  'overrides.choice.ours': 'Mantener mi override en cada conflicto',
+  'overrides.choice.theirs': 'Tomar upstream y descartar mi override',
+  'overrides.choice.merged': 'Resolví los marcadores a mano',
+  'overrides.choice.skip': 'Omitir por ahora',
+  'overrides.resolutionHint': 'pasa --ours, --theirs o --merged',
+  'info.installed': 'instalado',
+  'info.notInstalled': 'no instalado',
+  'info.from': 'de {source}',
+  'info.metadata': 'Metadatos',
+  'info.dependencies': 'Dependencias',
+  'info.cliDependencies': 'Dependencias de CLI',
+  'info.tokens': 'Tokens',
+  'info.set': 'definido',
+  'info.unset': 'sin definir',
+  'info.registryConfig': 'Configuración del registro',
+  'info.linkedBy': 'Enlazado por',
+  'info.notLinked': 'Ningún proyecto enlaza este tipo.',
+  'col.token': 'Token',
+  'col.required': 'Obligatorio',
+  'col.from': 'Origen',
+  'col.key': 'Clave',
+  'col.value': 'Valor',
+  'col.default': 'Por defecto',
+  'gc.typesSkipped': 'No se comprobaron los tipos instalados: {reason}',
+  'gc.nothing': 'No hay nada que recoger.',
+  'col.kind': 'Clase',
+  'col.target': 'Objetivo',
+  'col.why': 'Motivo',
+  'gc.summary': '{count} elemento(s), {size} recuperables ({projects} proyecto(s) conocidos).',
+  'gc.confirm': '¿Borrar {count} elemento(s) ({size})?',
+  'gc.deleteFailed': 'No se pudo borrar {target}: {error}',
+  'gc.reclaimed': 'Se recuperaron {size}.',
+  'gc.reinstallHint': 'Reinstala un tipo borrado con `{command}`.',
+  'gc.unreachable': 'No se pudo acceder a {count} proyecto(s) registrado(s); lo que usan solo se recoge con {flag}: {paths}',
+  'gc.needsForce': '{count} tipo(s) o versión(es) instalados pueden seguir en uso por proyectos que {app} no conoce; pasa {flag} para borrarlos también.',
+
+  'common.yes': 'sí',
+  'common.no': 'no',
+
+  'githooks.notGit': 'No estás dentro de un repositorio git.',
+  'githooks.notProject': '{root} no es un proyecto de {app}; ejecuta antes `{command}` en la raíz del repositorio.',
+  'githooks.lefthook': 'Ejecuta `lefthook install` si lefthook aún no está activo en este clon.',
+  'githooks.installed': 'Ahora los commits fallan mientras los archivos generados estén desactualizados.',
+  'githooks.none': 'No hay hooks que quitar.',
+  'githooks.removed': 'Se quitó el hook de {path}',
+  'stats.cleared': 'Estadísticas de uso borradas.',
+  'stats.off': 'Las estadísticas de uso están desactivadas. `{command}` las guarda solo en esta máquina.',
+  'stats.header': 'Telemetría: {mode}   Desde: {since}   Archivo: {path}',
+  'stats.mostUsed': 'Skills más usadas',
+  'stats.slowest': 'Comandos más lentos',
+  'stats.sent': 'Se enviaron {count} eventos de telemetría.',
+  'col.runs': 'Ejecuciones',
+  'col.failed': 'Fallidas',
+  'col.avg': 'Media',
+  'col.max': 'Máx',
+  'col.command': 'Comando',
+  'schedule.error': 'error: {error}',
+  'schedule.unknown': 'desconocido',
+  'schedule.exit': 'salida {code}',
+  'schedule.never': 'nunca',
+  'schedule.added': 'Se programó {type} como {id}; próxima ejecución {next}',
+  'schedule.howItRuns': 'Las programaciones se ejecutan mientras `{run}` esté en marcha, o tras configurar un temporizador del sistema con `{install}`.',
+  'schedule.none': 'No hay programaciones. Añade una con `{command}`.',
+  'schedule.notFound': 'No hay ninguna programación llamada {id}',
+  'schedule.removed': 'Se quitó la programación {id}',
+  'schedule.noRuns': 'Aún no hay ejecuciones programadas.',
+  'col.id': 'Id',
+  'col.cron': 'Cron',
+  'col.nextRun': 'Próxima',
+  'col.lastRun': 'Última',
+  'col.due': 'Prevista',
+  'col.took': 'Duración',
+  'col.result': 'Resultado',
+
+  'profile.none': 'No hay perfiles. Ejecuta antes `agentx init --global`.',
+  'profile.active': 'activo',
+  'profile.switched': 'Perfil cambiado a: {name}',
+  'profile.noActive': 'No hay perfil activo.',
+  'projects.none': 'No hay proyectos conocidos. Los registran init, import y los comandos link.',
+  'projects.noneToSync': 'No hay proyectos conocidos que sincronizar.',
+  'projects.synced': 'Se sincronizó {path}',
+  'col.project': 'Proyecto',
+  'col.tools': 'Herramientas',
+  'col.linked': 'Enlazados',
+  'col.lastSync': 'Última sincr.',
+  'config.set': 'Se definió {key} = {value} ({scope})',
+  'config.unset': 'Se quitó {key} ({scope})',
+  'config.notSet': '{key} no está definido en la configuración {scope}.',
+  'config.none': 'No hay ajustes. Consulta `config list --all` para ver las claves conocidas.',
+
+  'rollback.noSnapshots': 'No hay versiones almacenadas de {type}. Instálalo antes.',
+  'col.stored': 'Almacenada',
+  'rollback.current': 'actual',
+  'rollback.noOlder': 'No hay ninguna versión de {type} anterior a {version} almacenada.',
+  'rollback.noOlderThanCurrent': 'No hay ninguna versión de {type} anterior a la actual almacenada.',
+  'rollback.done': '{type} está ahora en {version}.',
+  'rollback.doneFrom': '{type} está ahora en {version} (antes {previous}).',
+  'output.none': '{skill} no tiene historial de salidas.',
+  'col.run': 'Ejecución',
+  'col.saved': 'Guardada',
+  'output.invalidRun': '--run debe ser un número positivo',
+  'output.entry': 'Ejecución {run}, guardada {saved}',
+
+  'graph.unknownFormat': 'Formato de grafo desconocido: {format}. Usa json o dot.',
+  'graph.notProject': 'No es un proyecto de agentx. Ejecuta `agentx init` o pasa --workspace.',
+  'graph.written': 'Se escribió el grafo de {projects} proyecto(s) y {links} enlace(s) en {path}',
+  'trust.none': 'No hay contribuciones aprobadas.',
+  'trust.nothingToRevoke': 'Nada que revocar.',
+  'trust.revoked': 'Se revocaron {count} aprobación(es) de {target}.',
+  'col.extension': 'Extensión',
+  'col.approved': 'Aprobada',
+  'col.digest': 'Digest',
+  'template.notInstalled': 'Plantilla no instalada: {type}.{hint}',
+  'template.written': 'Escrito en: {path}',
+  'serve.tokenRequired': 'La API HTTP necesita un token: ejecuta `{command}` o define {env}',
+  'serve.listening': 'Sirviendo la API de {app} en {url}',
+  'serve.authHint': 'Envía `Authorization: Bearer <serve.token>` en cada petición. Pulsa Ctrl+C para parar.',
+
+  'list.none': 'No hay tipos instalados.',
+  'col.installed': 'Instalado',
+  'list.outdated': '{count} tipo(s) tienen una versión más reciente. Ejecuta `{command}` para actualizar.',
+  'health.unknownBadgeFormat': 'Formato de insignia desconocido: "{format}". Se esperaba svg o json.',
+  'health.title': 'Salud del proyecto: {score} (nota {grade})',
+  'health.weight': 'peso {weight}',
+  'export.done': 'Se exportaron {count} archivo(s) a {path}',
+  'col.priority': 'Prioridad',
+  'col.provides': 'Lo ofrece',
+  'col.notes': 'Notas',
+
+  'version.line': '{app} versión {version} (commit: {commit}, compilado: {date})',
+  'preset.none': 'No hay presets. Los catálogos y extensiones los definen en presets/<nombre>.yaml.',
+  'pipe.failed': '{type} salió con código {code}; se detuvo el pipe',
+  'deps.noDependents': 'Nada hace referencia a {type}.',
+  'uninstall.removed': 'Eliminado: {type}',
+
+  'test.notSkill': '{skill} no es una skill (no tiene skill.yaml)',
+  'test.skillNotFound': 'Skill no encontrada: {skill}.{hint}',
+
+  'bench.col.baseline': 'referencia',
+  'bench.col.current': 'actual',
+  'bench.col.change': 'cambio',
+
+  'doctor.orphanHint': 'pasa --orphans archive|delete|keep',
+};
@@ -1,9 +1,119 @@
 import chalk from 'chalk';
+import { isAgentxError } from '../core/errors.js';
+import { APP_NAME } from '../config/branding.js';
+import { t } from './i18n.js';
 
-export const ok = (msg: string) => console.log(chalk.green('✓'), msg);
-export const fail = (msg: string) => console.error(chalk.red('✗'), msg);
-export const warn = (msg: string) => console.error(chalk.yellow('⚠'), msg);
-export const info = (msg: string) => console.log(chalk.blue('ℹ'), msg);
+// ── Output channels ─────────────────────────────────────────────────
+//
+// stdout carries command data and, in text mode, the human success and
+// info lines (ok, info) that have always been a command's visible
+// result. Warnings and errors go to stderr. With --output-format=json,
+// stdout holds only the JSON: ok and info move to stderr, and data is
+// wrapped in an envelope that also carries the warnings collected during
+// the run, so JSON consumers never have to scrape stderr.
+
+export type OutputFormat = 'text' | 'json';
+
+export interface Diagnostic {
+  level: 'warning' | 'error';
+  message: string;
+  /** Subsystem that raised it (registry, compose, ...). */
+  scope?: string;
+  /** Stable error code (core/errors.ts), e.g. AGX-REG-004. */
+  code?: string;
+}
+
+export interface JsonEnvelope<T> {
+  data: T;
+  warnings: Diagnostic[];
+}
+
+let format: OutputFormat = 'text';
+let quiet = false;
+const diagnostics: Diagnostic[] = [];
+
+export function setOutputFormat(f: string): void {
+  if (f !== 'text' && f !== 'json') {
+    throw new Error(`Invalid output format "${f}" (expected text or json)`);
+  }
+  format = f;
+}
+
+/** With quiet set, ok() and info() print nothing; warnings and errors still do. */
+export function setQuiet(q: boolean): void {
+  quiet = q;
+}
+
+export function outputFormat(): OutputFormat {
+  return format;
+}
+
+/** Whether a command should print JSON: its own --json flag or --output-format=json. */
+export function wantsJson(opts: { json?: boolean } = {}): boolean {
+  return Boolean(opts.json) || format === 'json';
+}
+
+/** Diagnostics recorded so far in this process. */
+export function collectedDiagnostics(): Diagnostic[] {
+  return [...diagnostics];
+}
+
+export function resetDiagnostics(): void {
+  diagnostics.length = 0;
+}
+
+function prefix(d: Diagnostic): string {
+  const level = d.scope ? `${d.level}[${d.scope}]` : d.level;
+  return d.code ? `${level} ${d.code}:` : `${level}:`;
+}
+
+function report(d: Diagnostic, glyph: string): void {
+  diagnostics.push(d);
+  console.error(glyph, `${prefix(d)} ${d.message}`);
+}
+
+/**
+ * Prints data as JSON on stdout. With --output-format=json it is wrapped in
+ * { data, warnings }; a plain --json keeps the bare payload.
+ */
+export function emitJson(data: unknown): void {
+  const payload: unknown =
+    format === 'json'
+      ? ({ data, warnings: diagnostics.filter((d) => d.level === 'warning') } satisfies JsonEnvelope<unknown>)
+      : data;
+  process.stdout.write(JSON.stringify(payload, null, 2) + '\n');
+}
+
+/** Where human status lines go: stdout, unless stdout is reserved for JSON. */
+const status = (...args: string[]) => (format === 'json' ? console.error(...args) : console.log(...args));
+
+export const ok = (msg: string) => {
+  if (!quiet) status(chalk.green('✓'), msg);
+};
+export const fail = (msg: string, scope?: string) =>
+  report({ level: 'error', message: msg, scope }, chalk.red('✗'));
+export const warn = (msg: string, scope?: string) =>
+  report({ level: 'warning', message: msg, scope }, chalk.yellow('⚠'));
+export const info = (msg: string) => {
+  if (!quiet) status(chalk.blue('ℹ'), msg);
+};
+/** Like info, but always on stderr: for commands whose stdout is data (a skill's output, an env file). */
+export const note = (msg: string) => {
+  if (!quiet) console.error(chalk.blue('ℹ'), msg);
+};
+
+/**
+ * Reports a caught error. Errors with a code print it, with a hint to
+ * look it up; others print as String(err) does.
+ */
+export function failError(err: unknown, scope?: string): void {
+  if (!isAgentxError(err)) {
+    fail(String(err), scope);
+    return;
+  }
+  report({ level: 'error', message: err.message, scope, code: err.code }, chalk.red('✗'));
+  console.error(chalk.dim(`  ${t('error.seeExplain', { command: `${APP_NAME} explain ${err.code}` })}`));
+}
 
 export function die(msg: string): never {
   fail(msg);
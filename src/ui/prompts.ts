@@ -1,16 +1,95 @@
-import { confirm, select, input } from '@inquirer/prompts';
+import { confirm, select, input, password } from '@inquirer/prompts';
+import { executionContext } from '../config/context.js';
+import { t } from './i18n.js';
 
-export async function askConfirm(message: string, defaultValue = true): Promise<boolean> {
+// ── Prompts ─────────────────────────────────────────────────────────
+//
+// With --non-interactive (or --yes) nothing is asked: a prompt with a
+// safe default takes it, --yes answers confirmations with yes, and a
+// prompt that needs a decision (a secret, a choice without a default, a
+// confirmation that defaults to yes or is destructive) fails with the
+// question and how to answer it up front. A confirmation's safe default
+// is no; only --yes goes ahead. Without a terminal, or with --no-input, every prompt
+// fails that way.
+
+export interface PromptOptions {
+  /** How to answer without a prompt, e.g. "pass --token-stdin". */
+  hint?: string;
+}
+
+export interface ConfirmOptions extends PromptOptions {
+  /** Even a no default is not a safe answer; only --yes may skip the question. */
+  mandatory?: boolean;
+}
+
+/** Whether prompts can be shown (a terminal, not CI, no --no-input or --non-interactive). */
+export function canPrompt(): boolean {
+  const ctx = executionContext();
+  return ctx.interactive && ctx.answers === 'ask';
+}
+
+/** Whether --yes was given, answering every confirmation with yes. */
+export function assumeYes(): boolean {
+  return executionContext().answers === 'yes';
+}
+
+function answeringDefaults(): boolean {
+  return executionContext().answers !== 'ask';
+}
+
+// Without input, a prompt would hang or guess; fail with the question
+// so the user knows which flag or setting answers it.
+function requireInput(message: string, opts: PromptOptions = {}): void {
+  const params = { question: message.trim(), hint: opts.hint ? ` (${opts.hint})` : '' };
+  if (answeringDefaults()) {
+    throw new Error(t('prompt.decisionNeeded', params));
+  }
+  if (!canPrompt()) {
+    throw new Error(t('prompt.inputDisabled', params));
+  }
+}
+
+export async function askConfirm(
+  message: string,
+  defaultValue = true,
+  opts: ConfirmOptions = {},
+): Promise<boolean> {
+  if (assumeYes()) return true;
+  // Taking a yes default would act without anyone agreeing to it
+  if (answeringDefaults() && !opts.mandatory && !defaultValue) return false;
+  requireInput(message, { hint: opts.hint ?? t('prompt.passYes') });
   return confirm({ message, default: defaultValue });
 }
 
 export async function askSelect<T extends string>(
   message: string,
   choices: { name: string; value: T }[],
+  defaultValue?: T,
+  opts: PromptOptions = {},
 ): Promise<T> {
-  return select({ message, choices });
+  if (answeringDefaults() && defaultValue !== undefined) return defaultValue;
+  requireInput(message, opts);
+  return select({ message, choices, default: defaultValue });
 }
 
-export async function askInput(message: string, defaultValue?: string): Promise<string> {
+export async function askInput(message: string, defaultValue?: string, opts: PromptOptions = {}): Promise<string> {
+  if (answeringDefaults() && defaultValue !== undefined) return defaultValue;
+  requireInput(message, opts);
   return input({ message, default: defaultValue });
 }
+
+/** Reads a secret without echoing it. Secrets have no default. */
+export async function askSecret(message: string, opts: PromptOptions = {}): Promise<string> {
+  requireInput(message, opts);
+  return password({ message, mask: '*' });
+}
+
+/**
+ * Shows what is about to run and asks for consent. Without a TTY there is
+ * nobody to ask, so consent is never assumed, not even with --yes.
+ */
+export async function askApproval(details: string): Promise<boolean> {
+  if (!canPrompt()) return false;
+  console.error(details);
+  return askConfirm(t('prompt.allow'), false);
+}
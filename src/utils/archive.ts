@@ -0,0 +1,89 @@
+import { execFileSync } from 'node:child_process';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { fetchWithCredentials } from './http.js';
+
+// Downloads of repository snapshots over HTTPS, used in place of
+// `git clone` on machines without git or behind a mirror. Only hosts
+// with a well-known archive URL scheme are supported directly; mirrors
+// serve prebuilt bundles (see core/mirror.ts).
+
+const GITHUB = /^(?:https:\/\/|git@)github\.com[/:]([^/]+)\/([^/]+?)(?:\.git)?\/?$/;
+const GITLAB = /^(?:https:\/\/|git@)gitlab\.com[/:](.+)\/([^/]+?)(?:\.git)?\/?$/;
+
+/**
+ * Tarball URL for a ref of a GitHub or GitLab repository, or null.
+ * Authenticated downloads go through the hosts' APIs, which accept
+ * tokens; the public archive endpoints don't.
+ */
+export function archiveUrl(gitUrl: string, ref = 'main', authenticated = false): string | null {
+  const gh = GITHUB.exec(gitUrl);
+  if (gh) {
+    const [, owner, repo] = gh;
+    return authenticated
+      ? `https://api.github.com/repos/${owner}/${repo}/tarball/${ref}`
+      : `https://codeload.github.com/${owner}/${repo}/tar.gz/${ref}`;
+  }
+  const gl = GITLAB.exec(gitUrl);
+  if (gl) {
+    const [, group, repo] = gl;
+    return authenticated
+      ? `https://gitlab.com/api/v4/projects/${encodeURIComponent(`${group}/${repo}`)}/repository/archive.tar.gz?sha=${ref}`
+      : `https://gitlab.com/${group}/${repo}/-/archive/${ref}/${repo}-${ref}.tar.gz`;
+  }
+  return null;
+}
+
+/**
+ * Fetches a snapshot of gitUrl at ref and unpacks it into targetDir
+ * (which must not exist). Requires `tar`, which ships with macOS,
+ * Linux, and Windows 10+. Pass an Authorization header value for
+ * private repositories.
+ */
+export async function downloadArchive(
+  gitUrl: string,
+  ref: string,
+  targetDir: string,
+  authorization?: string,
+): Promise<void> {
+  const url = archiveUrl(gitUrl, ref, Boolean(authorization));
+  if (!url) {
+    throw new Error(
+      `Cannot download ${gitUrl} without git: only GitHub and GitLab URLs have an archive fallback. ` +
+        'Install git from https://git-scm.com/downloads.',
+    );
+  }
+
+  await downloadTarball(url, targetDir, authorization ? { Authorization: authorization } : {});
+}
+
+/**
+ * Fetches a .tar.gz from url and unpacks its single top-level directory
+ * into targetDir (which must not exist). credentials are auth headers,
+ * dropped if the download redirects to another origin.
+ */
+export async function downloadTarball(
+  url: string,
+  targetDir: string,
+  credentials: Record<string, string> = {},
+): Promise<void> {
+  const res = await fetchWithCredentials(url, credentials);
+  if (!res.ok) {
+    throw new Error(`Failed to download ${url}: HTTP ${res.status} ${res.statusText}`);
+  }
+
+  const tarball = join(tmpdir(), `agentx-archive-${process.pid}-${Date.now()}.tar.gz`);
+  try {
+    writeFileSync(tarball, Buffer.from(await res.arrayBuffer()));
+    mkdirSync(targetDir, { recursive: true });
+    execFileSync('tar', ['-xzf', tarball, '-C', targetDir, '--strip-components=1'], {
+      stdio: 'ignore',
+    });
+  } catch (err) {
+    rmSync(targetDir, { recursive: true, force: true });
+    throw err;
+  } finally {
+    rmSync(tarball, { force: true });
+  }
+}
@@ -0,0 +1,130 @@
+// ── Cron expressions ────────────────────────────────────────────────
+//
+// The five standard fields (minute hour day-of-month month day-of-week),
+// each `*`, a number, a range `a-b`, a step `*/n` or `a-b/n`, or a comma
+// list of those. Months and weekdays also take names (jan, mon), and
+// Sunday is 0 or 7. As in cron, when both day fields are restricted a
+// day matching either one matches. @hourly, @daily, @weekly, @monthly,
+// and @yearly are shorthands. Times are local.
+
+export interface CronSchedule {
+  minutes: Set<number>;
+  hours: Set<number>;
+  days: Set<number>;
+  months: Set<number>;
+  weekdays: Set<number>;
+  /** Whether day-of-month / day-of-week were `*`. */
+  anyDay: boolean;
+  anyWeekday: boolean;
+}
+
+const MACROS: Record<string, string> = {
+  '@hourly': '0 * * * *',
+  '@daily': '0 0 * * *',
+  '@midnight': '0 0 * * *',
+  '@weekly': '0 0 * * 0',
+  '@monthly': '0 0 1 * *',
+  '@yearly': '0 0 1 1 *',
+  '@annually': '0 0 1 1 *',
+};
+
+const MONTH_NAMES = ['jan', 'feb', 'mar', 'apr', 'may', 'jun', 'jul', 'aug', 'sep', 'oct', 'nov', 'dec'];
+const DAY_NAMES = ['sun', 'mon', 'tue', 'wed', 'thu', 'fri', 'sat'];
+
+interface FieldSpec {
+  name: string;
+  min: number;
+  max: number;
+  names?: string[];
+  /** Value of names[0]. */
+  nameBase?: number;
+}
+
+const FIELDS: FieldSpec[] = [
+  { name: 'minute', min: 0, max: 59 },
+  { name: 'hour', min: 0, max: 23 },
+  { name: 'day of month', min: 1, max: 31 },
+  { name: 'month', min: 1, max: 12, names: MONTH_NAMES, nameBase: 1 },
+  { name: 'day of week', min: 0, max: 7, names: DAY_NAMES, nameBase: 0 },
+];
+
+// Far enough to reach Feb 29 from any date
+const SEARCH_LIMIT_MINUTES = 8 * 366 * 24 * 60;
+
+function parseValue(raw: string, spec: FieldSpec, expr: string): number {
+  const named = spec.names?.indexOf(raw.toLowerCase()) ?? -1;
+  const value = named >= 0 ? named + (spec.nameBase ?? 0) : /^\d+$/.test(raw) ? Number(raw) : NaN;
+  if (Number.isNaN(value) || value < spec.min || value > spec.max) {
+    throw new Error(`Invalid ${spec.name} "${raw}" in cron expression "${expr}"`);
+  }
+  return value;
+}
+
+function parseField(field: string, spec: FieldSpec, expr: string): Set<number> {
+  const values = new Set<number>();
+  for (const part of field.split(',')) {
+    const [range, stepRaw] = part.split('/');
+    const step = stepRaw === undefined ? 1 : Number(stepRaw);
+    if (!Number.isInteger(step) || step < 1) throw new Error(`Invalid step "${stepRaw}" in cron expression "${expr}"`);
+
+    let lo: number;
+    let hi: number;
+    if (range === '*') {
+      lo = spec.min;
+      hi = spec.max;
+    } else if (range.includes('-')) {
+      const [a, b] = range.split('-');
+      lo = parseValue(a, spec, expr);
+      hi = parseValue(b, spec, expr);
+      if (lo > hi) throw new Error(`Invalid range "${range}" in cron expression "${expr}"`);
+    } else {
+      lo = parseValue(range, spec, expr);
+      hi = stepRaw === undefined ? lo : spec.max;
+    }
+    for (let v = lo; v <= hi; v += step) values.add(v);
+  }
+  return values;
+}
+
+export function parseCron(expr: string): CronSchedule {
+  const trimmed = expr.trim();
+  const fields = (MACROS[trimmed.toLowerCase()] ?? trimmed).split(/\s+/);
+  if (fields.length !== FIELDS.length) {
+    throw new Error(`Invalid cron expression "${expr}": expected 5 fields (minute hour day month weekday)`);
+  }
+  const [minutes, hours, days, months, weekdays] = fields.map((f, i) => parseField(f, FIELDS[i], expr));
+  if (weekdays.delete(7)) weekdays.add(0);
+  return { minutes, hours, days, months, weekdays, anyDay: fields[2] === '*', anyWeekday: fields[4] === '*' };
+}
+
+function dayMatches(s: CronSchedule, d: Date): boolean {
+  const dom = s.days.has(d.getDate());
+  const dow = s.weekdays.has(d.getDay());
+  if (s.anyDay || s.anyWeekday) return dom && dow;
+  return dom || dow;
+}
+
+/** The first time after `after` (to the minute) the schedule fires, or null if it never does. */
+export function nextRun(schedule: CronSchedule | string, after: Date): Date | null {
+  const s = typeof schedule === 'string' ? parseCron(schedule) : schedule;
+  const t = new Date(after.getTime());
+  t.setSeconds(0, 0);
+  t.setMinutes(t.getMinutes() + 1);
+
+  for (let i = 0; i < SEARCH_LIMIT_MINUTES; i++) {
+    if (!s.months.has(t.getMonth() + 1)) {
+      t.setMonth(t.getMonth() + 1, 1);
+      t.setHours(0, 0);
+    } else if (!dayMatches(s, t)) {
+      t.setDate(t.getDate() + 1);
+      t.setHours(0, 0);
+    } else if (!s.hours.has(t.getHours())) {
+      t.setHours(t.getHours() + 1, 0);
+    } else if (!s.minutes.has(t.getMinutes())) {
+      t.setMinutes(t.getMinutes() + 1);
+    } else {
+      return t;
+    }
+  }
+  return null;
+}
@@ -0,0 +1,51 @@
+import { diffLines } from './merge.js';
+
+// ── Unified diffs ───────────────────────────────────────────────────
+//
+// `diff -u` style output for showing what a change would do to generated
+// text (composed prompts, linked tool files). Built on the line diff the
+// merge code uses, so no external diff is needed.
+
+export interface UnifiedDiffOptions {
+  /** Names on the ---/+++ lines. */
+  fromLabel?: string;
+  toLabel?: string;
+  /** Unchanged lines kept around each change. */
+  context?: number;
+}
+
+const DEFAULT_CONTEXT = 3;
+
+/** A unified diff from a to b; empty when they are the same. */
+export function unifiedDiff(a: string, b: string, opts: UnifiedDiffOptions = {}): string {
+  if (a === b) return '';
+  const context = opts.context ?? DEFAULT_CONTEXT;
+  // Both ending in a newline leaves a shared empty last "line"; drop it
+  const lines = diffLines(a, b).filter((l, i, all) => !(i === all.length - 1 && l === ' '));
+
+  const changed = lines.map((l, i) => (l[0] !== ' ' ? i : -1)).filter((i) => i >= 0);
+  const out = [`--- ${opts.fromLabel ?? 'a'}`, `+++ ${opts.toLabel ?? 'b'}`];
+
+  let k = 0;
+  while (k < changed.length) {
+    // Merge changes whose context would touch into one hunk
+    const start = Math.max(0, changed[k] - context);
+    let end = changed[k];
+    while (k + 1 < changed.length && changed[k + 1] - end <= 2 * context) end = changed[++k];
+    end = Math.min(lines.length - 1, end + context);
+    k++;
+
+    let aStart = 1;
+    let bStart = 1;
+    for (const l of lines.slice(0, start)) {
+      if (l[0] !== '+') aStart++;
+      if (l[0] !== '-') bStart++;
+    }
+    const hunk = lines.slice(start, end + 1);
+    const aLen = hunk.filter((l) => l[0] !== '+').length;
+    const bLen = hunk.filter((l) => l[0] !== '-').length;
+    out.push(`@@ -${aLen === 0 ? aStart - 1 : aStart},${aLen} +${bLen === 0 ? bStart - 1 : bStart},${bLen} @@`);
+    out.push(...hunk);
+  }
+  return out.join('\n') + '\n';
+}
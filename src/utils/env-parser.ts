@@ -3,8 +3,6 @@ export interface EnvEntry {
   value: string;
 }
 
-const SENSITIVE_PATTERNS = ['TOKEN', 'SECRET', 'PASSWORD', 'KEY', 'CREDENTIAL'];
-
 export function parseEnvFile(content: string): EnvEntry[] {
   const entries: EnvEntry[] = [];
   for (const line of content.split('\n')) {
@@ -20,10 +18,4 @@ export function parseEnvFile(content: string): EnvEntry[] {
   return entries;
 }
 
-export function redactValue(key: string, value: string): string {
-  const upper = key.toUpperCase();
-  if (SENSITIVE_PATTERNS.some((p) => upper.includes(p))) {
-    return value.length >= 4 ? value.slice(0, 4) + '***' : '***';
-  }
-  return value;
-}
+export { redactValue } from './redact.js';
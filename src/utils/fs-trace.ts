@@ -0,0 +1,176 @@
+import fs from 'node:fs';
+import { syncBuiltinESMExports } from 'node:module';
+import { dirname } from 'node:path';
+import { performance } from 'node:perf_hooks';
+
+// ── Filesystem tracing ──────────────────────────────────────────────
+//
+// `--trace-fs` wraps the node:fs functions the CLI uses and records each
+// call with its path and duration. Named imports elsewhere see the
+// wrappers too: syncBuiltinESMExports() rebinds them. Entries are kept
+// in memory and written to the debug log once, at exit, with the
+// original functions so the log write itself isn't traced.
+
+export type FsOpKind = 'read' | 'write' | 'symlink' | 'stat';
+
+export interface FsOp {
+  kind: FsOpKind;
+  fn: string;
+  path: string;
+  ms: number;
+  /** Error code (ENOENT, EACCES, ...) when the call threw. */
+  error?: string;
+}
+
+const SYNC_OPS: Record<string, FsOpKind> = {
+  readFileSync: 'read',
+  readdirSync: 'read',
+  readlinkSync: 'read',
+  writeFileSync: 'write',
+  appendFileSync: 'write',
+  copyFileSync: 'write',
+  renameSync: 'write',
+  rmSync: 'write',
+  unlinkSync: 'write',
+  mkdirSync: 'write',
+  chmodSync: 'write',
+  linkSync: 'write',
+  symlinkSync: 'symlink',
+  statSync: 'stat',
+  lstatSync: 'stat',
+  existsSync: 'stat',
+};
+
+const ASYNC_OPS: Record<string, FsOpKind> = {
+  readFile: 'read',
+  readdir: 'read',
+  readlink: 'read',
+  writeFile: 'write',
+  appendFile: 'write',
+  copyFile: 'write',
+  rename: 'write',
+  rm: 'write',
+  unlink: 'write',
+  mkdir: 'write',
+  chmod: 'write',
+  symlink: 'symlink',
+  stat: 'stat',
+  lstat: 'stat',
+};
+
+// Two-path calls are logged as "from -> to"
+const TWO_PATHS = new Set(['copyFile', 'rename', 'link', 'symlink']);
+
+type AnyFn = (...args: unknown[]) => unknown;
+
+let ops: FsOp[] | null = null;
+const originals = new Map<object, Map<string, AnyFn>>();
+
+function describePath(fn: string, args: unknown[]): string {
+  const str = (v: unknown) => (typeof v === 'string' ? v : v instanceof URL ? v.pathname : String(v));
+  const base = fn.replace(/Sync$/, '');
+  return TWO_PATHS.has(base) ? `${str(args[0])} -> ${str(args[1])}` : str(args[0]);
+}
+
+function record(kind: FsOpKind, fn: string, args: unknown[], start: number, err?: unknown): void {
+  const op: FsOp = { kind, fn, path: describePath(fn, args), ms: performance.now() - start };
+  if (err) op.error = (err as NodeJS.ErrnoException).code ?? 'error';
+  ops?.push(op);
+}
+
+function patch(target: object, table: Record<string, FsOpKind>, async: boolean): void {
+  const saved = new Map<string, AnyFn>();
+  const obj = target as Record<string, AnyFn>;
+  for (const [name, kind] of Object.entries(table)) {
+    const original = obj[name];
+    if (typeof original !== 'function') continue;
+    saved.set(name, original);
+    obj[name] = async
+      ? async function (this: unknown, ...args: unknown[]) {
+          const start = performance.now();
+          try {
+            const result = await original.apply(this, args);
+            record(kind, name, args, start);
+            return result;
+          } catch (err) {
+            record(kind, name, args, start, err);
+            throw err;
+          }
+        }
+      : function (this: unknown, ...args: unknown[]) {
+          const start = performance.now();
+          try {
+            const result = original.apply(this, args);
+            record(kind, name, args, start);
+            return result;
+          } catch (err) {
+            record(kind, name, args, start, err);
+            throw err;
+          }
+        };
+  }
+  originals.set(target, saved);
+}
+
+function formatOp(op: FsOp): string {
+  const status = op.error ? ` ${op.error}` : '';
+  return `fs ${op.kind.padEnd(7)} ${op.ms.toFixed(2).padStart(8)}ms ${op.fn} ${op.path}${status}`;
+}
+
+/**
+ * Starts tracing filesystem calls. At exit, appends them to logPath
+ * under a header naming the command, then hands them to onExit.
+ */
+export function enableFsTrace(
+  logPath: string,
+  onExit?: (traced: FsOp[]) => void,
+  command = process.argv.slice(2).join(' '),
+): void {
+  if (ops) return;
+  ops = [];
+  const startedAt = new Date().toISOString();
+  const { appendFileSync, mkdirSync } = fs;
+
+  patch(fs, SYNC_OPS, false);
+  patch(fs.promises, ASYNC_OPS, true);
+  syncBuiltinESMExports();
+
+  process.once('exit', () => {
+    if (!ops) return; // Already disabled
+    const traced = disableFsTrace();
+    try {
+      mkdirSync(dirname(logPath), { recursive: true });
+      appendFileSync(logPath, [`# ${startedAt} ${command}`, ...traced.map(formatOp), ''].join('\n'));
+    } catch {
+      // Best-effort debug output
+    }
+    onExit?.(traced);
+  });
+}
+
+/** Restores the original functions and returns what was traced. */
+export function disableFsTrace(): FsOp[] {
+  for (const [target, saved] of originals) {
+    for (const [name, fn] of saved) (target as Record<string, AnyFn>)[name] = fn;
+  }
+  originals.clear();
+  syncBuiltinESMExports();
+  const traced = ops ?? [];
+  ops = null;
+  return traced;
+}
+
+/** Operation counts and total time per kind, for an end-of-command summary. */
+export function summarizeFsOps(traced: FsOp[]): Record<FsOpKind, { count: number; ms: number }> {
+  const summary = {
+    read: { count: 0, ms: 0 },
+    write: { count: 0, ms: 0 },
+    symlink: { count: 0, ms: 0 },
+    stat: { count: 0, ms: 0 },
+  };
+  for (const op of traced) {
+    summary[op.kind].count++;
+    summary[op.kind].ms += op.ms;
+  }
+  return summary;
+}
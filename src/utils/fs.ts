@@ -3,8 +3,11 @@ import {
   readdirSync,
   copyFileSync,
   statSync,
+  openSync,
+  fsyncSync,
+  closeSync,
 } from 'node:fs';
-import { join } from 'node:path';
+import { join, relative, sep } from 'node:path';
 
 const SKIP_DIRS = new Set(['node_modules', '.git', 'dist']);
 
@@ -13,16 +16,40 @@ export function copyDir(src: string, dest: string): void {
   for (const entry of readdirSync(src, { withFileTypes: true })) {
     const srcPath = join(src, entry.name);
     const destPath = join(dest, entry.name);
+    // node_modules may be a link into the shared cache
+    if (SKIP_DIRS.has(entry.name)) continue;
     if (entry.isDirectory()) {
-      if (!SKIP_DIRS.has(entry.name)) {
-        copyDir(srcPath, destPath);
-      }
+      copyDir(srcPath, destPath);
     } else {
       copyFileSync(srcPath, destPath);
     }
   }
 }
 
+/**
+ * Flushes every file under dir, and the directories themselves, to
+ * disk. Directory fsync is not possible on Windows and is skipped there.
+ */
+export function fsyncTree(dir: string): void {
+  const sync = (path: string) => {
+    let fd: number | undefined;
+    try {
+      fd = openSync(path, 'r');
+      fsyncSync(fd);
+    } catch {
+      // EISDIR/EPERM on platforms that can't fsync directories
+    } finally {
+      if (fd !== undefined) closeSync(fd);
+    }
+  };
+  for (const entry of readdirSync(dir, { withFileTypes: true })) {
+    const path = join(dir, entry.name);
+    if (entry.isDirectory()) fsyncTree(path);
+    else sync(path);
+  }
+  sync(dir);
+}
+
 export function ensureDir(path: string, mode?: number): void {
   mkdirSync(path, { recursive: true, mode });
 }
@@ -42,3 +69,83 @@ export function fileExists(path: string): boolean {
     return false;
   }
 }
+
+// ── Globs ───────────────────────────────────────────────────────────
+
+export function isGlob(pattern: string): boolean {
+  return /[*?{[]/.test(pattern);
+}
+
+/** Converts a glob (`**`, `*`, `?`, `{a,b}`, `[abc]`) to an anchored RegExp over posix paths. */
+export function globToRegExp(pattern: string): RegExp {
+  let re = '';
+  let inGroup = false;
+  for (let i = 0; i < pattern.length; i++) {
+    const c = pattern[i];
+    if (c === '*') {
+      if (pattern[i + 1] === '*') {
+        if (pattern[i + 2] === '/') {
+          re += '(?:.*/)?';
+          i += 2;
+        } else {
+          re += '.*';
+          i += 1;
+        }
+      } else {
+        re += '[^/]*';
+      }
+    } else if (c === '?') {
+      re += '[^/]';
+    } else if (c === '{') {
+      re += '(?:';
+      inGroup = true;
+    } else if (c === '}' && inGroup) {
+      re += ')';
+      inGroup = false;
+    } else if (c === ',' && inGroup) {
+      re += '|';
+    } else if (c === '[') {
+      const end = pattern.indexOf(']', i);
+      if (end === -1) {
+        re += '\\[';
+      } else {
+        re += '[' + pattern.slice(i + 1, end).replace(/^!/, '^') + ']';
+        i = end;
+      }
+    } else {
+      re += c.replace(/[.+^$()|\\]/g, '\\$&');
+    }
+  }
+  return new RegExp(`^${re}$`);
+}
+
+function walkFiles(root: string, dir: string, out: string[]): void {
+  let entries;
+  try {
+    entries = readdirSync(dir, { withFileTypes: true });
+  } catch {
+    return;
+  }
+  for (const entry of entries) {
+    if (entry.name.startsWith('.') || SKIP_DIRS.has(entry.name)) continue;
+    const full = join(dir, entry.name);
+    if (entry.isDirectory()) {
+      walkFiles(root, full, out);
+    } else if (entry.isFile()) {
+      out.push(relative(root, full).split(sep).join('/'));
+    }
+  }
+}
+
+/** Lists files under baseDir as posix paths relative to baseDir. */
+export function listFiles(baseDir: string): string[] {
+  const out: string[] = [];
+  walkFiles(baseDir, baseDir, out);
+  return out;
+}
+
+/** Files under baseDir matching a glob, as posix paths relative to baseDir. */
+export function globFiles(baseDir: string, pattern: string): string[] {
+  const re = globToRegExp(pattern);
+  return listFiles(baseDir).filter((f) => re.test(f));
+}
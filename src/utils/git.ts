@@ -1,12 +1,84 @@
 import { execFileSync } from 'node:child_process';
+import { existsSync } from 'node:fs';
+import { dirname, join, resolve } from 'node:path';
+
+let gitAvailable: boolean | undefined;
+
+/** Whether the git CLI is on PATH. Checked once per process. */
+export function hasGit(): boolean {
+  if (gitAvailable === undefined) {
+    try {
+      execFileSync('git', ['--version'], { stdio: 'ignore' });
+      gitAvailable = true;
+    } catch {
+      gitAvailable = false;
+    }
+  }
+  return gitAvailable;
+}
+
+/**
+ * Throws an actionable error when git is missing. Use at the entry of
+ * features that genuinely need it (submodules, pulling a clone).
+ */
+export function requireGit(feature: string): void {
+  if (hasGit()) return;
+  throw new Error(
+    `${feature} requires git, which was not found on PATH. ` +
+      'Install it from https://git-scm.com/downloads and try again.',
+  );
+}
+
+// Walks up looking for a .git entry, for machines without the git CLI.
+function findDotGit(start: string): string | null {
+  let dir = resolve(start);
+  for (;;) {
+    if (existsSync(join(dir, '.git'))) return dir;
+    const parent = dirname(dir);
+    if (parent === dir) return null;
+    dir = parent;
+  }
+}
 
 export function findRepoRoot(cwd?: string): string | null {
+  if (!hasGit()) return findDotGit(cwd ?? process.cwd());
   try {
     return execFileSync('git', ['rev-parse', '--show-toplevel'], {
       cwd: cwd ?? process.cwd(),
       encoding: 'utf-8',
+      stdio: ['ignore', 'pipe', 'ignore'],
     }).trim();
   } catch {
     return null;
   }
 }
+
+export function remoteUrl(cwd: string, remote = 'origin'): string | null {
+  if (!hasGit()) return null;
+  try {
+    return execFileSync('git', ['config', '--get', `remote.${remote}.url`], {
+      cwd,
+      encoding: 'utf-8',
+      stdio: ['ignore', 'pipe', 'ignore'],
+    }).trim() || null;
+  } catch {
+    return null;
+  }
+}
+
+/** Directory git runs hooks from, honoring core.hooksPath. */
+export function hooksDir(repoRoot: string): string {
+  if (hasGit()) {
+    try {
+      const path = execFileSync('git', ['rev-parse', '--git-path', 'hooks'], {
+        cwd: repoRoot,
+        encoding: 'utf-8',
+        stdio: ['ignore', 'pipe', 'ignore'],
+      }).trim();
+      return resolve(repoRoot, path);
+    } catch {
+      // Fall through to the default location
+    }
+  }
+  return join(repoRoot, '.git', 'hooks');
+}
@@ -0,0 +1,174 @@
+import { readFileSync } from 'node:fs';
+import { rootCertificates } from 'node:tls';
+import { EnvHttpProxyAgent, fetch, type Dispatcher, type RequestInit, type Response } from 'undici';
+
+// ── HTTP client ─────────────────────────────────────────────────────
+//
+// Every HTTP request the CLI makes (archives, mirror bundles, remote
+// context, publish steps, embeddings) goes through httpFetch, so proxies
+// and corporate TLS work the same everywhere:
+//
+//   - HTTPS_PROXY / HTTP_PROXY / NO_PROXY (either case) are honored.
+//   - A CA bundle (http.ca_bundle, else NODE_EXTRA_CA_CERTS) is trusted
+//     in addition to the built-in roots, for TLS-intercepting proxies.
+//   - Connect, header, and idle-body timeouts default to http.timeout and
+//     can be raised per host (http.host_timeouts: nexus.acme.io=2m).
+//
+// Child processes that do their own HTTP (npm) get the same settings
+// through childEnv().
+
+export interface HttpConfig {
+  /** PEM file of extra CA certificates; null for the built-in roots only. */
+  caBundle: string | null;
+  timeoutMs: number;
+  /** Per-host overrides of timeoutMs. */
+  hostTimeouts: Record<string, number>;
+}
+
+const DEFAULTS: HttpConfig = {
+  caBundle: process.env.NODE_EXTRA_CA_CERTS || null,
+  timeoutMs: 30_000,
+  hostTimeouts: {},
+};
+
+let config: HttpConfig = { ...DEFAULTS };
+const dispatchers = new Map<number, Dispatcher>();
+
+export function configureHttp(partial: Partial<HttpConfig>): void {
+  config = { ...config, ...partial };
+  dispatchers.clear();
+}
+
+/** Restores the defaults; for tests. */
+export function resetHttp(): void {
+  config = { ...DEFAULTS };
+  dispatchers.clear();
+}
+
+export function timeoutFor(url: string): number {
+  try {
+    return config.hostTimeouts[new URL(url).hostname] ?? config.timeoutMs;
+  } catch {
+    return config.timeoutMs;
+  }
+}
+
+function trustedCAs(): string[] | undefined {
+  if (!config.caBundle) return undefined;
+  let pem: string;
+  try {
+    pem = readFileSync(config.caBundle, 'utf-8');
+  } catch (err) {
+    throw new Error(`Cannot read CA bundle ${config.caBundle}: ${(err as Error).message}`);
+  }
+  // Setting ca replaces the defaults, so keep the built-in roots
+  return [...rootCertificates, pem];
+}
+
+function dispatcherFor(timeoutMs: number): Dispatcher {
+  let dispatcher = dispatchers.get(timeoutMs);
+  if (!dispatcher) {
+    const ca = trustedCAs();
+    dispatcher = new EnvHttpProxyAgent({
+      connect: { timeout: timeoutMs, ca },
+      requestTls: { ca },
+      proxyTls: { ca },
+      headersTimeout: timeoutMs,
+      bodyTimeout: timeoutMs,
+    });
+    dispatchers.set(timeoutMs, dispatcher);
+  }
+  return dispatcher;
+}
+
+const TLS_CODES = new Set([
+  'SELF_SIGNED_CERT_IN_CHAIN',
+  'DEPTH_ZERO_SELF_SIGNED_CERT',
+  'UNABLE_TO_GET_ISSUER_CERT_LOCALLY',
+  'UNABLE_TO_VERIFY_LEAF_SIGNATURE',
+  'CERT_UNTRUSTED',
+]);
+
+const TIMEOUT_CODES = new Set([
+  'UND_ERR_CONNECT_TIMEOUT',
+  'UND_ERR_HEADERS_TIMEOUT',
+  'UND_ERR_BODY_TIMEOUT',
+]);
+
+/** Hint for a TLS verification failure, or null for other errors. */
+export function tlsHint(text: string): string | null {
+  for (const code of TLS_CODES) {
+    if (text.includes(code)) {
+      return 'The certificate chain is not trusted. Behind a TLS-intercepting proxy, point http.ca_bundle at your corporate CA bundle (`agentx config set http.ca_bundle /path/to/ca.pem`).';
+    }
+  }
+  if (/self[- ]signed certificate|unable to get local issuer certificate/i.test(text)) {
+    return tlsHint('SELF_SIGNED_CERT_IN_CHAIN');
+  }
+  return null;
+}
+
+/** Turns undici's "fetch failed" into a message naming the cause. */
+function describe(url: string, err: unknown): Error {
+  const cause = (err as { cause?: { code?: string; message?: string } }).cause;
+  const code = cause?.code ?? '';
+  const detail = cause?.message ?? (err as Error).message ?? String(err);
+  const host = (() => {
+    try {
+      return new URL(url).hostname;
+    } catch {
+      return url;
+    }
+  })();
+
+  let hint = tlsHint(`${code} ${detail}`);
+  if (!hint && TIMEOUT_CODES.has(code)) {
+    hint = `No response within ${timeoutFor(url) / 1000}s; raise it with \`agentx config set http.host_timeouts ${host}=2m\`.`;
+  }
+  if (!hint && (code === 'ENOTFOUND' || code === 'ECONNREFUSED' || code === 'ECONNRESET')) {
+    hint = 'If this network needs a proxy, set HTTPS_PROXY (and NO_PROXY for internal hosts).';
+  }
+  return new Error(`Request to ${host} failed: ${detail}${hint ? `\n${hint}` : ''}`, { cause: err });
+}
+
+/** fetch with the configured proxy, CA bundle, and timeouts. */
+export async function httpFetch(url: string, init: RequestInit = {}): Promise<Response> {
+  try {
+    return await fetch(url, { ...init, dispatcher: dispatcherFor(timeoutFor(url)) });
+  } catch (err) {
+    throw describe(url, err);
+  }
+}
+
+const REDIRECT_STATUSES = new Set([301, 302, 303, 307, 308]);
+const MAX_REDIRECTS = 10;
+
+/**
+ * httpFetch that follows redirects itself so credentials (auth headers)
+ * only go to the origin they were meant for: a redirect to another
+ * origin, such as a CDN or an http:// downgrade, is requested without
+ * them.
+ */
+export async function fetchWithCredentials(
+  url: string,
+  credentials: Record<string, string>,
+  init: RequestInit = {},
+): Promise<Response> {
+  const origin = new URL(url).origin;
+  let current = url;
+  for (let hops = 0; hops <= MAX_REDIRECTS; hops++) {
+    const sameOrigin = new URL(current).origin === origin;
+    const headers = { ...(init.headers as Record<string, string> | undefined), ...(sameOrigin ? credentials : {}) };
+    const res = await httpFetch(current, { ...init, headers, redirect: 'manual' });
+    const location = res.headers.get('location');
+    if (!REDIRECT_STATUSES.has(res.status) || !location) return res;
+    await res.body?.cancel();
+    current = new URL(location, current).toString();
+  }
+  throw new Error(`Too many redirects fetching ${url}`);
+}
+
+/** Environment for child processes (npm) that make their own requests. */
+export function childEnv(env: NodeJS.ProcessEnv = process.env): NodeJS.ProcessEnv {
+  return config.caBundle ? { ...env, NODE_EXTRA_CA_CERTS: config.caBundle } : env;
+}
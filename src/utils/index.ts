@@ -3,3 +3,7 @@ export * from './fs.js';
 export * from './git.js';
 export * from './env-parser.js';
 export * from './input-parser.js';
+export * from './version.js';
+export * from './merge.js';
+export * from './units.js';
+export * from './log.js';
@@ -1,17 +1,116 @@
+import { readFileSync, existsSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { resolve } from 'node:path';
+import yaml from 'js-yaml';
 import type { InputField } from '../types/manifest.js';
+import { isSensitiveKey, MASK } from './redact.js';
 
-export function parseInputArgs(args: string[]): Record<string, string> {
+/** How run inputs are merged, highest first; quoted in validation errors. */
+export const INPUT_PRECEDENCE = '-i/--stdin-input flags > --input-file > manifest defaults';
+
+/**
+ * Parses key=value pairs. A key the schema declares as an array may
+ * repeat; its values are collected into a JSON array. For other keys
+ * the last value wins.
+ */
+export function parseInputArgs(args: string[], schema: InputField[] = []): Record<string, string> {
+  const arrays = new Set(schema.filter((f) => f.type === 'array').map((f) => f.name));
   const result: Record<string, string> = {};
+  const lists: Record<string, string[]> = {};
   for (const arg of args) {
     const eqIndex = arg.indexOf('=');
     if (eqIndex === -1) {
       throw new Error(`Invalid input format: "${arg}". Expected key=value.`);
     }
-    result[arg.slice(0, eqIndex)] = arg.slice(eqIndex + 1);
+    const key = arg.slice(0, eqIndex);
+    const value = arg.slice(eqIndex + 1);
+    if (arrays.has(key)) (lists[key] ??= []).push(value);
+    else result[key] = value;
   }
+  for (const [key, values] of Object.entries(lists)) result[key] = JSON.stringify(values);
   return result;
 }
 
+/** Skills receive inputs as strings; other values are passed on as JSON. */
+function toInputValue(value: unknown): string {
+  return typeof value === 'string' ? value : JSON.stringify(value);
+}
+
+/** Reads a YAML or JSON file holding a mapping, keeping its values as they are. */
+export function readDataFile(path: string): Record<string, unknown> {
+  let data: unknown;
+  try {
+    data = yaml.load(readFileSync(path, 'utf-8'));
+  } catch (err) {
+    throw new Error(`Cannot read input file ${path}: ${err instanceof Error ? err.message : String(err)}`);
+  }
+  if (data === undefined || data === null) return {};
+  if (typeof data !== 'object' || Array.isArray(data)) {
+    throw new Error(`Input file ${path} must hold a mapping of input names to values`);
+  }
+  return data as Record<string, unknown>;
+}
+
+/** Reads inputs from a YAML or JSON file holding a mapping. */
+export function readInputFile(path: string): Record<string, string> {
+  return Object.fromEntries(Object.entries(readDataFile(path)).map(([k, v]) => [k, toInputValue(v)]));
+}
+
+export interface InputSources {
+  /** -i key=value pairs. */
+  flags?: Record<string, string>;
+  /** --input-file contents. */
+  file?: Record<string, string>;
+  /** --stdin-input: the input name and what was piped in. */
+  stdin?: { name: string; value: string };
+}
+
+function isJsonArray(value: string): boolean {
+  try {
+    return Array.isArray(JSON.parse(value));
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * Puts typed inputs in the form skills receive them: a file input
+ * becomes an absolute path (~ expanded, relative to cwd), and an array
+ * input given as one plain value becomes a one-item JSON array.
+ */
+export function normalizeInputs(
+  inputs: Record<string, string>,
+  schema: InputField[] = [],
+  cwd: string = process.cwd(),
+): Record<string, string> {
+  const normalized = { ...inputs };
+  for (const field of schema) {
+    const value = normalized[field.name];
+    if (value === undefined) continue;
+    if (field.type === 'file' && value) {
+      normalized[field.name] = resolve(cwd, value.replace(/^~(?=$|[/\\])/, homedir()));
+    } else if (field.type === 'array' && !isJsonArray(value)) {
+      normalized[field.name] = JSON.stringify([value]);
+    }
+  }
+  return normalized;
+}
+
+/** Merges inputs by INPUT_PRECEDENCE, filling the rest from the manifest's defaults, then normalizes them. */
+export function mergeInputs(sources: InputSources, schema: InputField[] = []): Record<string, string> {
+  const { flags = {}, file = {}, stdin } = sources;
+  if (stdin && stdin.name in flags) {
+    throw new Error(`Input "${stdin.name}" is given both with -i and --stdin-input; pass it only once`);
+  }
+  const merged: Record<string, string> = {};
+  for (const field of schema) {
+    if (field.default !== undefined) merged[field.name] = toInputValue(field.default);
+  }
+  Object.assign(merged, file, flags);
+  if (stdin) merged[stdin.name] = stdin.value;
+  return normalizeInputs(merged, schema);
+}
+
 export function validateInputs(
   provided: Record<string, string>,
   schema: InputField[],
@@ -21,6 +120,23 @@ export function validateInputs(
     if (field.required && !(field.name in provided)) {
       errors.push(`Missing required input: ${field.name}`);
     }
+    const value = provided[field.name];
+    if (field.type === 'file' && value && !existsSync(value)) {
+      // A secret's value is never echoed, even as a path
+      errors.push(`Input ${field.name}: file not found${field.secret ? '' : `: ${value}`}`);
+    }
   }
   return errors;
 }
+
+/**
+ * inputs as they may be recorded or shown (run lists, daemon state,
+ * schedules): values of inputs the schema marks secret or sensitive, or
+ * whose names look secret (redact.patterns included), become MASK.
+ */
+export function maskInputs(inputs: Record<string, string>, schema: InputField[] = []): Record<string, string> {
+  const secret = new Set(schema.filter((f) => f.secret || f.sensitive).map((f) => f.name));
+  return Object.fromEntries(
+    Object.entries(inputs).map(([name, value]) => [name, secret.has(name) || isSensitiveKey(name) ? MASK : value]),
+  );
+}
@@ -0,0 +1,239 @@
+// ── JSON Schema validation ──────────────────────────────────────────
+//
+// Checks a parsed JSON value against a JSON Schema, for skill outputs.
+// It covers the keywords output schemas use in practice (types, enum and
+// const, properties and required, items, bounds, pattern, the anyOf /
+// oneOf / allOf / not combinators, and local $ref) without pulling in a
+// full validator. A schema using a keyword outside that set is rejected
+// up front rather than silently checked less strictly; annotations such
+// as title, description, and format are allowed and ignored.
+//
+// Paths in messages use the dot form of `agentx pipe --map`: `items.0.id`,
+// with `.` for the whole value.
+
+export type JsonSchema = Record<string, unknown> | boolean;
+
+const VALIDATION_KEYWORDS = new Set([
+  '$ref',
+  'type',
+  'enum',
+  'const',
+  'minLength',
+  'maxLength',
+  'pattern',
+  'minimum',
+  'maximum',
+  'exclusiveMinimum',
+  'exclusiveMaximum',
+  'multipleOf',
+  'minItems',
+  'maxItems',
+  'uniqueItems',
+  'items',
+  'properties',
+  'required',
+  'additionalProperties',
+  'minProperties',
+  'maxProperties',
+  'allOf',
+  'anyOf',
+  'oneOf',
+  'not',
+]);
+
+/** Keywords that describe a schema without constraining values. */
+const ANNOTATION_KEYWORDS = new Set([
+  '$schema',
+  '$id',
+  '$comment',
+  '$defs',
+  'definitions',
+  'title',
+  'description',
+  'default',
+  'examples',
+  'format',
+  'readOnly',
+  'writeOnly',
+  'deprecated',
+]);
+
+/** The JSON Schema type name of a value; integers are also numbers. */
+function typeOf(value: unknown): string {
+  if (value === null) return 'null';
+  if (Array.isArray(value)) return 'array';
+  if (typeof value === 'number') return Number.isInteger(value) ? 'integer' : 'number';
+  return typeof value;
+}
+
+function matchesType(value: unknown, type: string): boolean {
+  const actual = typeOf(value);
+  return actual === type || (type === 'number' && actual === 'integer');
+}
+
+function child(path: string, key: string | number): string {
+  return path === '.' ? String(key) : `${path}.${key}`;
+}
+
+/** Structural equality, for enum, const, and uniqueItems. */
+function equal(a: unknown, b: unknown): boolean {
+  if (a === b) return true;
+  if (typeof a !== 'object' || typeof b !== 'object' || a === null || b === null) return false;
+  if (Array.isArray(a) !== Array.isArray(b)) return false;
+  const keys = Object.keys(a);
+  if (keys.length !== Object.keys(b).length) return false;
+  return keys.every((k) => equal((a as Record<string, unknown>)[k], (b as Record<string, unknown>)[k]));
+}
+
+/** Resolves a `#/...` reference against the root schema. */
+function resolveRef(root: JsonSchema, ref: string): JsonSchema {
+  if (!ref.startsWith('#')) throw new Error(`Unsupported $ref "${ref}": only references within the schema (#/...) are`);
+  let node: unknown = root;
+  for (const raw of ref.slice(1).split('/').filter(Boolean)) {
+    const key = decodeURIComponent(raw).replace(/~1/g, '/').replace(/~0/g, '~');
+    node = node && typeof node === 'object' ? (node as Record<string, unknown>)[key] : undefined;
+  }
+  if (node === undefined) throw new Error(`Unresolved $ref "${ref}"`);
+  return node as JsonSchema;
+}
+
+/** Throws naming the first keyword this validator can't enforce, anywhere in schema. */
+function assertSupported(schema: JsonSchema, path: string): void {
+  if (typeof schema === 'boolean') return;
+  for (const key of Object.keys(schema)) {
+    if (!VALIDATION_KEYWORDS.has(key) && !ANNOTATION_KEYWORDS.has(key)) {
+      throw new Error(`Unsupported keyword "${key}" at ${path}`);
+    }
+  }
+  if (Array.isArray(schema.items)) throw new Error(`Unsupported tuple form of "items" at ${path}`);
+
+  const sub = (s: unknown, at: string) => {
+    if (typeof s === 'boolean' || (s && typeof s === 'object')) assertSupported(s as JsonSchema, at);
+  };
+  for (const key of ['properties', '$defs', 'definitions']) {
+    for (const [name, s] of Object.entries((schema[key] ?? {}) as Record<string, unknown>)) sub(s, `${path}/${key}/${name}`);
+  }
+  for (const key of ['allOf', 'anyOf', 'oneOf']) {
+    ((schema[key] ?? []) as unknown[]).forEach((s, i) => sub(s, `${path}/${key}/${i}`));
+  }
+  for (const key of ['items', 'additionalProperties', 'not']) sub(schema[key], `${path}/${key}`);
+}
+
+function check(value: unknown, schema: JsonSchema, root: JsonSchema, path: string, errors: string[]): void {
+  if (schema === true) return;
+  if (schema === false) {
+    errors.push(`${path}: not allowed`);
+    return;
+  }
+  if (typeof schema.$ref === 'string') {
+    check(value, resolveRef(root, schema.$ref), root, path, errors);
+    return;
+  }
+
+  if (schema.type !== undefined) {
+    const types = Array.isArray(schema.type) ? (schema.type as string[]) : [schema.type as string];
+    if (!types.some((t) => matchesType(value, t))) {
+      errors.push(`${path}: expected ${types.join(' or ')}, got ${typeOf(value)}`);
+      return;
+    }
+  }
+  if (Array.isArray(schema.enum) && !schema.enum.some((e) => equal(e, value))) {
+    errors.push(`${path}: must be one of ${schema.enum.map((e) => JSON.stringify(e)).join(', ')}`);
+  }
+  if ('const' in schema && !equal(schema.const, value)) {
+    errors.push(`${path}: must be ${JSON.stringify(schema.const)}`);
+  }
+
+  if (typeof value === 'string') {
+    if (typeof schema.minLength === 'number' && value.length < schema.minLength) {
+      errors.push(`${path}: shorter than ${schema.minLength} characters`);
+    }
+    if (typeof schema.maxLength === 'number' && value.length > schema.maxLength) {
+      errors.push(`${path}: longer than ${schema.maxLength} characters`);
+    }
+    if (typeof schema.pattern === 'string' && !new RegExp(schema.pattern, 'u').test(value)) {
+      errors.push(`${path}: does not match /${schema.pattern}/`);
+    }
+  }
+
+  if (typeof value === 'number') {
+    if (typeof schema.minimum === 'number' && value < schema.minimum) errors.push(`${path}: less than ${schema.minimum}`);
+    if (typeof schema.maximum === 'number' && value > schema.maximum) errors.push(`${path}: greater than ${schema.maximum}`);
+    if (typeof schema.exclusiveMinimum === 'number' && value <= schema.exclusiveMinimum) {
+      errors.push(`${path}: not greater than ${schema.exclusiveMinimum}`);
+    }
+    if (typeof schema.exclusiveMaximum === 'number' && value >= schema.exclusiveMaximum) {
+      errors.push(`${path}: not less than ${schema.exclusiveMaximum}`);
+    }
+    if (typeof schema.multipleOf === 'number' && !Number.isInteger(value / schema.multipleOf)) {
+      errors.push(`${path}: not a multiple of ${schema.multipleOf}`);
+    }
+  }
+
+  if (Array.isArray(value)) {
+    if (typeof schema.minItems === 'number' && value.length < schema.minItems) {
+      errors.push(`${path}: fewer than ${schema.minItems} items`);
+    }
+    if (typeof schema.maxItems === 'number' && value.length > schema.maxItems) {
+      errors.push(`${path}: more than ${schema.maxItems} items`);
+    }
+    if (schema.uniqueItems === true && value.some((v, i) => value.findIndex((w) => equal(v, w)) !== i)) {
+      errors.push(`${path}: items are not unique`);
+    }
+    if (schema.items !== undefined && !Array.isArray(schema.items)) {
+      value.forEach((item, i) => check(item, schema.items as JsonSchema, root, child(path, i), errors));
+    }
+  }
+
+  if (typeOf(value) === 'object') {
+    const object = value as Record<string, unknown>;
+    const properties = (schema.properties ?? {}) as Record<string, JsonSchema>;
+    const count = Object.keys(object).length;
+    if (typeof schema.minProperties === 'number' && count < schema.minProperties) {
+      errors.push(`${path}: fewer than ${schema.minProperties} properties`);
+    }
+    if (typeof schema.maxProperties === 'number' && count > schema.maxProperties) {
+      errors.push(`${path}: more than ${schema.maxProperties} properties`);
+    }
+    for (const name of (schema.required ?? []) as string[]) {
+      if (!(name in object)) errors.push(`${child(path, name)}: required`);
+    }
+    for (const [name, v] of Object.entries(object)) {
+      if (name in properties) {
+        check(v, properties[name], root, child(path, name), errors);
+      } else if (schema.additionalProperties === false) {
+        errors.push(`${child(path, name)}: not allowed`);
+      } else if (typeof schema.additionalProperties === 'object') {
+        check(v, schema.additionalProperties as JsonSchema, root, child(path, name), errors);
+      }
+    }
+  }
+
+  const passes = (s: JsonSchema) => validateJson(value, s, root).length === 0;
+  if (Array.isArray(schema.allOf)) {
+    for (const s of schema.allOf as JsonSchema[]) check(value, s, root, path, errors);
+  }
+  if (Array.isArray(schema.anyOf) && !(schema.anyOf as JsonSchema[]).some(passes)) {
+    errors.push(`${path}: matches none of anyOf`);
+  }
+  if (Array.isArray(schema.oneOf)) {
+    const matched = (schema.oneOf as JsonSchema[]).filter(passes).length;
+    if (matched !== 1) errors.push(`${path}: matches ${matched} of oneOf, expected exactly 1`);
+  }
+  if (schema.not !== undefined && passes(schema.not as JsonSchema)) {
+    errors.push(`${path}: matches a schema under not`);
+  }
+}
+
+/**
+ * The ways value breaks schema, one message per violation; empty when it
+ * conforms. root is what $refs resolve against, the schema itself unless
+ * checking a subschema. Throws when the schema uses a keyword this
+ * validator doesn't enforce.
+ */
+export function validateJson(value: unknown, schema: JsonSchema, root: JsonSchema = schema): string[] {
+  if (schema === root) assertSupported(root, '#');
+  const errors: string[] = [];
+  check(value, schema, root, '.', errors);
+  return errors;
+}
@@ -0,0 +1,133 @@
+import fs from 'node:fs';
+import { join } from 'node:path';
+import chalk from 'chalk';
+import { scrubText, scrubFields } from './redact.js';
+
+// ── Logging ─────────────────────────────────────────────────────────
+//
+// Diagnostic logs, separate from command output (ui/output.ts). Modules
+// take a scoped logger once and log freely; what is shown is decided per
+// invocation: stderr gets entries at or above the console level (warn by
+// default, debug with --verbose, error with --quiet), as text or, with
+// --log-json, one JSON object per line. Every entry at info and above,
+// plus debug entries when verbose, also goes to a rotating log file
+// under ~/.agentx/logs/ as JSON lines.
+//
+// The fs functions are captured at load so that --trace-fs does not
+// trace the log's own writes.
+
+const { appendFileSync, mkdirSync, renameSync, rmSync, statSync } = fs;
+
+export const LOG_LEVELS = ['debug', 'info', 'warn', 'error'] as const;
+export type LogLevel = (typeof LOG_LEVELS)[number];
+
+export interface LogEntry {
+  time: string;
+  level: LogLevel;
+  scope: string;
+  msg: string;
+  [field: string]: unknown;
+}
+
+export interface LogConfig {
+  /** Lowest level written to stderr. */
+  level: LogLevel;
+  json: boolean;
+  /** Directory for the log file; null disables it. */
+  dir: string | null;
+  /** Rotate when the file grows past this many bytes. */
+  maxBytes: number;
+  /** Rotated files kept besides the current one. */
+  maxFiles: number;
+}
+
+export interface Logger {
+  debug(msg: string, fields?: Record<string, unknown>): void;
+  info(msg: string, fields?: Record<string, unknown>): void;
+  warn(msg: string, fields?: Record<string, unknown>): void;
+  error(msg: string, fields?: Record<string, unknown>): void;
+}
+
+export const LOG_FILE = 'agentx.log';
+
+const DEFAULTS: LogConfig = { level: 'warn', json: false, dir: null, maxBytes: 1024 * 1024, maxFiles: 5 };
+
+let config: LogConfig = { ...DEFAULTS };
+
+export function isLogLevel(value: string): value is LogLevel {
+  return (LOG_LEVELS as readonly string[]).includes(value);
+}
+
+function rank(level: LogLevel): number {
+  return LOG_LEVELS.indexOf(level);
+}
+
+export function configureLogging(next: Partial<LogConfig>): void {
+  config = { ...config, ...next };
+}
+
+/** Restores the defaults; for tests. */
+export function resetLogging(): void {
+  config = { ...DEFAULTS };
+}
+
+const LEVEL_STYLE: Record<LogLevel, (s: string) => string> = {
+  debug: chalk.dim,
+  info: chalk.blue,
+  warn: chalk.yellow,
+  error: chalk.red,
+};
+
+export function formatEntry(entry: LogEntry): string {
+  const { time, level, scope, msg, ...fields } = entry;
+  const extra = Object.entries(fields)
+    .map(([k, v]) => `${k}=${typeof v === 'string' ? v : JSON.stringify(v)}`)
+    .join(' ');
+  return `${time.slice(11, 23)} ${LEVEL_STYLE[level](level.padEnd(5))} [${scope}] ${msg}${extra ? ` ${extra}` : ''}`;
+}
+
+function rotate(path: string): void {
+  for (let i = config.maxFiles - 1; i >= 1; i--) {
+    try {
+      renameSync(`${path}.${i}`, `${path}.${i + 1}`);
+    } catch {
+      // No such generation yet
+    }
+  }
+  if (config.maxFiles > 0) renameSync(path, `${path}.1`);
+  else rmSync(path, { force: true });
+}
+
+function writeFile(entry: LogEntry): void {
+  if (!config.dir) return;
+  const path = join(config.dir, LOG_FILE);
+  try {
+    mkdirSync(config.dir, { recursive: true });
+    try {
+      if (statSync(path).size >= config.maxBytes) rotate(path);
+    } catch {
+      // No log file yet
+    }
+    appendFileSync(path, JSON.stringify(entry) + '\n');
+  } catch {
+    // Logging must never fail a command
+  }
+}
+
+function write(level: LogLevel, scope: string, msg: string, fields: Record<string, unknown> = {}): void {
+  const entry: LogEntry = { time: new Date().toISOString(), level, scope, msg: scrubText(msg), ...scrubFields(fields) };
+  if (rank(level) >= rank(config.level)) {
+    process.stderr.write((config.json ? JSON.stringify(entry) : formatEntry(entry)) + '\n');
+  }
+  if (rank(level) >= rank('info') || config.level === 'debug') writeFile(entry);
+}
+
+/** A logger whose entries are tagged with scope (registry, linker, ...). */
+export function logger(scope: string): Logger {
+  return {
+    debug: (msg, fields) => write('debug', scope, msg, fields),
+    info: (msg, fields) => write('info', scope, msg, fields),
+    warn: (msg, fields) => write('warn', scope, msg, fields),
+    error: (msg, fields) => write('error', scope, msg, fields),
+  };
+}
@@ -0,0 +1,222 @@
+import { writeFileSync, readFileSync, mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { spawnSync } from 'node:child_process';
+
+// ── Three-way merge ─────────────────────────────────────────────────
+//
+// Merges two edits of a common base line by line. Regions only one side
+// changed take that side; regions both changed the same way take either;
+// anything else becomes a conflict wrapped in git-style markers.
+
+export interface MergeLabels {
+  ours: string;
+  base: string;
+  theirs: string;
+}
+
+export interface MergeResult {
+  text: string;
+  conflicts: number;
+}
+
+/** A merge driver: the built-in line merge, `git merge-file`, or a custom command. */
+export type MergeDriver = (base: string, ours: string, theirs: string, labels: MergeLabels) => MergeResult;
+
+const DEFAULT_LABELS: MergeLabels = { ours: 'ours', base: 'base', theirs: 'theirs' };
+
+// LCS tables grow with the product of line counts; beyond this, use git.
+const MAX_LCS_CELLS = 25_000_000;
+
+/** Splits into lines, each keeping its newline. */
+function splitLines(text: string): string[] {
+  return text === '' ? [] : text.split(/(?<=\n)/);
+}
+
+/**
+ * For each line of a, the index of the line of b it is matched to by a
+ * longest common subsequence, or -1.
+ */
+function matchLines(a: string[], b: string[]): number[] {
+  const n = a.length;
+  const m = b.length;
+  if ((n + 1) * (m + 1) > MAX_LCS_CELLS) {
+    throw new Error(`File too large for the built-in merge (${n}×${m} lines); set overrides.merge_driver to "git"`);
+  }
+
+  // lcs[i][j] = LCS length of a[i..] and b[j..]
+  const lcs = new Uint32Array((n + 1) * (m + 1));
+  const at = (i: number, j: number) => i * (m + 1) + j;
+  for (let i = n - 1; i >= 0; i--) {
+    for (let j = m - 1; j >= 0; j--) {
+      lcs[at(i, j)] = a[i] === b[j]
+        ? lcs[at(i + 1, j + 1)] + 1
+        : Math.max(lcs[at(i + 1, j)], lcs[at(i, j + 1)]);
+    }
+  }
+
+  const match = new Array<number>(n).fill(-1);
+  let i = 0;
+  let j = 0;
+  while (i < n && j < m) {
+    if (a[i] === b[j]) {
+      match[i++] = j++;
+    } else if (lcs[at(i + 1, j)] >= lcs[at(i, j + 1)]) {
+      i++;
+    } else {
+      j++;
+    }
+  }
+  return match;
+}
+
+const same = (a: string[], b: string[]) => a.length === b.length && a.every((line, i) => line === b[i]);
+
+function withNewline(lines: string[]): string {
+  const text = lines.join('');
+  return text === '' || text.endsWith('\n') ? text : `${text}\n`;
+}
+
+export function merge3(
+  base: string,
+  ours: string,
+  theirs: string,
+  labels: MergeLabels = DEFAULT_LABELS,
+): MergeResult {
+  const o = splitLines(base);
+  const a = splitLines(ours);
+  const b = splitLines(theirs);
+  const toA = matchLines(o, a);
+  const toB = matchLines(o, b);
+
+  const out: string[] = [];
+  let conflicts = 0;
+  let i = 0;
+  let ia = 0;
+  let ib = 0;
+
+  while (i < o.length || ia < a.length || ib < b.length) {
+    // Stable line: unchanged on both sides
+    if (i < o.length && toA[i] === ia && toB[i] === ib) {
+      out.push(o[i]);
+      i++;
+      ia++;
+      ib++;
+      continue;
+    }
+
+    // Unstable chunk runs to the next base line both sides kept
+    let k = i;
+    while (k < o.length && (toA[k] === -1 || toB[k] === -1)) k++;
+    const endA = k < o.length ? toA[k] : a.length;
+    const endB = k < o.length ? toB[k] : b.length;
+
+    const chunkO = o.slice(i, k);
+    const chunkA = a.slice(ia, endA);
+    const chunkB = b.slice(ib, endB);
+
+    if (same(chunkA, chunkO)) {
+      out.push(...chunkB);
+    } else if (same(chunkB, chunkO) || same(chunkA, chunkB)) {
+      out.push(...chunkA);
+    } else {
+      conflicts++;
+      out.push(
+        `<<<<<<< ${labels.ours}\n`,
+        withNewline(chunkA),
+        `||||||| ${labels.base}\n`,
+        withNewline(chunkO),
+        '=======\n',
+        withNewline(chunkB),
+        `>>>>>>> ${labels.theirs}\n`,
+      );
+    }
+
+    i = k;
+    ia = endA;
+    ib = endB;
+  }
+
+  return { text: out.join(''), conflicts };
+}
+
+/**
+ * A line diff from a to b: unchanged lines prefixed with ' ', removed
+ * with '-', added with '+'.
+ */
+export function diffLines(a: string, b: string): string[] {
+  const la = a.split('\n');
+  const lb = b.split('\n');
+  const match = matchLines(la, lb);
+  const out: string[] = [];
+  let j = 0;
+  for (let i = 0; i < la.length; i++) {
+    if (match[i] === -1) {
+      out.push(`-${la[i]}`);
+      continue;
+    }
+    while (j < match[i]) out.push(`+${lb[j++]}`);
+    out.push(` ${la[i]}`);
+    j++;
+  }
+  while (j < lb.length) out.push(`+${lb[j++]}`);
+  return out;
+}
+
+/** Whether text still holds conflict markers from a merge. */
+export function hasConflictMarkers(text: string): boolean {
+  return /^<<<<<<< .*$/m.test(text) && /^>>>>>>> .*$/m.test(text);
+}
+
+// ── Drivers ─────────────────────────────────────────────────────────
+
+type MergePaths = { base: string; ours: string; theirs: string };
+
+function withTempFiles<T>(base: string, ours: string, theirs: string, fn: (paths: MergePaths) => T): T {
+  const dir = mkdtempSync(join(tmpdir(), 'agentx-merge-'));
+  try {
+    const paths = { base: join(dir, 'base'), ours: join(dir, 'ours'), theirs: join(dir, 'theirs') };
+    writeFileSync(paths.base, base);
+    writeFileSync(paths.ours, ours);
+    writeFileSync(paths.theirs, theirs);
+    return fn(paths);
+  } finally {
+    rmSync(dir, { recursive: true, force: true });
+  }
+}
+
+/** `git merge-file --diff3`: exits with the number of conflicts. */
+export const gitMergeDriver: MergeDriver = (base, ours, theirs, labels) =>
+  withTempFiles(base, ours, theirs, (p) => {
+    const res = spawnSync(
+      'git',
+      ['merge-file', '-p', '--diff3', '-L', labels.ours, '-L', labels.base, '-L', labels.theirs, p.ours, p.base, p.theirs],
+      { encoding: 'utf-8' },
+    );
+    if (res.error || res.status === null || res.status < 0) {
+      throw new Error(`git merge-file failed: ${res.error?.message ?? res.stderr}`);
+    }
+    return { text: res.stdout, conflicts: res.status };
+  });
+
+/**
+ * A custom driver in git's merge-driver convention: %O, %A, and %B are
+ * replaced by the base, ours, and theirs files; the command leaves its
+ * result in %A and exits non-zero if conflicts remain.
+ */
+export function commandMergeDriver(command: string): MergeDriver {
+  return (base, ours, theirs) =>
+    withTempFiles(base, ours, theirs, (p) => {
+      const expanded = command.replace(/%O/g, p.base).replace(/%A/g, p.ours).replace(/%B/g, p.theirs);
+      const res = spawnSync(expanded, { shell: true, encoding: 'utf-8' });
+      if (res.error) throw new Error(`Merge driver failed: ${res.error.message}`);
+      return { text: readFileSync(p.ours, 'utf-8'), conflicts: res.status === 0 ? 0 : 1 };
+    });
+}
+
+/** Resolves an overrides.merge_driver setting: "builtin" (default), "git", or a command. */
+export function mergeDriverFor(setting: string): MergeDriver {
+  if (!setting || setting === 'builtin') return merge3;
+  if (setting === 'git') return gitMergeDriver;
+  return commandMergeDriver(setting);
+}
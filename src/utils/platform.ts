@@ -7,8 +7,14 @@ import {
   readFileSync,
   writeFileSync,
   existsSync,
+  statSync,
+  cpSync,
+  rmSync,
+  readdirSync,
 } from 'node:fs';
-import { resolve, dirname } from 'node:path';
+import { resolve, dirname, join } from 'node:path';
+import { execFileSync } from 'node:child_process';
+import { createHash } from 'node:crypto';
 
 const isWindows = process.platform === 'win32';
 
@@ -59,3 +65,155 @@ export function isSymlink(path: string): boolean {
     return false;
   }
 }
+
+// ── Managed links ───────────────────────────────────────────────────
+//
+// Links agentx places into tool directories (context types linked into
+// .claude/context/, ...). Windows without Developer Mode can't create
+// symlinks, so directories fall back to a junction and, failing that, to
+// a copy with a .target sidecar naming the original. A copy can drift
+// from its target; checkLink compares their contents so `link status`
+// can report it and `link sync` can refresh it.
+
+export type LinkKind = 'symlink' | 'junction' | 'copy';
+
+export type LinkHealth = 'valid' | 'broken' | 'drifted';
+
+export interface LinkCheck {
+  /** Junctions read back as symlinks. */
+  kind: LinkKind;
+  target: string;
+  health: LinkHealth;
+}
+
+const SIDECAR = '.target';
+
+/** sha256 over a file, or over a directory's relative paths and contents. */
+function contentHash(path: string): string {
+  const hash = createHash('sha256');
+  const walk = (abs: string, rel: string) => {
+    if (statSync(abs).isDirectory()) {
+      for (const name of readdirSync(abs).sort()) {
+        if (name.endsWith(SIDECAR)) continue;
+        walk(join(abs, name), rel ? `${rel}/${name}` : name);
+      }
+    } else {
+      hash.update(`${rel}\0`).update(readFileSync(abs)).update('\0');
+    }
+  };
+  walk(path, '');
+  return hash.digest('hex');
+}
+
+/** Whether path is a link (or sidecar copy) agentx manages. */
+export function isManagedLink(path: string): boolean {
+  return isSymlink(path) || existsSync(`${path}${SIDECAR}`);
+}
+
+/** Health of a managed link, or null when path is not one. */
+export function checkLink(path: string): LinkCheck | null {
+  if (isSymlink(path)) {
+    return { kind: 'symlink', target: readlinkSync(path), health: existsSync(path) ? 'valid' : 'broken' };
+  }
+  const sidecar = `${path}${SIDECAR}`;
+  if (!existsSync(sidecar)) return null;
+  const target = resolve(dirname(path), readFileSync(sidecar, 'utf-8').trim());
+  let health: LinkHealth = 'broken';
+  if (existsSync(target) && existsSync(path)) {
+    health = contentHash(target) === contentHash(path) ? 'valid' : 'drifted';
+  }
+  return { kind: 'copy', target, health };
+}
+
+/** Removes a managed link, its copy, and its sidecar. Refuses anything else. */
+export function removeLink(path: string): void {
+  let stat;
+  try {
+    stat = lstatSync(path);
+  } catch {
+    rmSync(`${path}${SIDECAR}`, { force: true });
+    return;
+  }
+  if (stat.isSymbolicLink() || stat.isFile()) {
+    unlinkSync(path);
+  } else if (existsSync(`${path}${SIDECAR}`)) {
+    rmSync(path, { recursive: true, force: true });
+  } else {
+    throw new Error(`${path} is a directory agentx did not create; move it away to link here`);
+  }
+  rmSync(`${path}${SIDECAR}`, { force: true });
+}
+
+/**
+ * Links path to target, replacing a previous managed link. Uses a
+ * symlink; on Windows, falls back to a junction for directories and
+ * then to a copy. With copy set, always copies, keeping an existing copy
+ * whose contents still match. Returns what was created.
+ */
+export function createLink(target: string, path: string, opts: { copy?: boolean } = {}): LinkKind {
+  const absTarget = resolve(dirname(path), target);
+  if (opts.copy) {
+    const existing = checkLink(path);
+    if (existing?.kind === 'copy' && existing.target === absTarget && existing.health === 'valid') return 'copy';
+  }
+  removeLink(path);
+
+  if (!opts.copy) {
+    const isDir = statSync(absTarget).isDirectory();
+    try {
+      symlinkSync(target, path, isDir ? 'dir' : 'file');
+      return 'symlink';
+    } catch (err) {
+      if (!isWindows) throw err;
+    }
+    if (isDir) {
+      try {
+        symlinkSync(absTarget, path, 'junction');
+        return 'junction';
+      } catch {
+        // Fall through to a copy
+      }
+    }
+  }
+  cpSync(absTarget, path, { recursive: true, dereference: true });
+  writeFileSync(`${path}${SIDECAR}`, absTarget, 'utf-8');
+  return 'copy';
+}
+
+// ── Clipboard ───────────────────────────────────────────────────────
+
+interface ClipboardCommand {
+  cmd: string;
+  args: string[];
+}
+
+function clipboardCommands(): ClipboardCommand[] {
+  switch (process.platform) {
+    case 'darwin':
+      return [{ cmd: 'pbcopy', args: [] }];
+    case 'win32':
+      return [{ cmd: 'clip', args: [] }];
+    default:
+      // Wayland first, then the two common X11 tools
+      return [
+        { cmd: 'wl-copy', args: [] },
+        { cmd: 'xclip', args: ['-selection', 'clipboard'] },
+        { cmd: 'xsel', args: ['--clipboard', '--input'] },
+      ];
+  }
+}
+
+export function copyToClipboard(text: string): void {
+  const candidates = clipboardCommands();
+  for (const { cmd, args } of candidates) {
+    try {
+      execFileSync(cmd, args, { input: text, stdio: ['pipe', 'ignore', 'ignore'] });
+      return;
+    } catch {
+      // Try the next tool
+    }
+  }
+  throw new Error(
+    `No clipboard tool available (tried: ${candidates.map((c) => c.cmd).join(', ')})`,
+  );
+}
@@ -0,0 +1,81 @@
+// ── Secret redaction ────────────────────────────────────────────────
+//
+// Anything that shows or records values (env show, doctor, logs) masks
+// secrets the same way. A value is secret when its name looks like one
+// (TOKEN, SECRET, PASSWORD, KEY, CREDENTIAL, or a redact.patterns regex)
+// or when it was registered as a secret, e.g. a token or input a
+// manifest marks `sensitive: true`. Registered values and text matching
+// redact.patterns are also masked inside free text.
+
+const DEFAULT_KEY_PATTERNS = [/TOKEN/i, /SECRET/i, /PASSWORD/i, /KEY/i, /CREDENTIAL/i];
+
+/** Shorter values are not masked inside text; they match too much. */
+const MIN_SECRET_LENGTH = 4;
+
+export const MASK = '***';
+
+let patterns: RegExp[] = [];
+const secrets = new Set<string>();
+
+/** Compiles redact.patterns; throws naming the first invalid regex. */
+export function compilePatterns(sources: string[]): RegExp[] {
+  return sources.map((source) => {
+    try {
+      return new RegExp(source);
+    } catch (err) {
+      throw new Error(`Invalid redaction pattern "${source}": ${(err as Error).message}`);
+    }
+  });
+}
+
+export function configureRedaction(next: { patterns: RegExp[] }): void {
+  patterns = next.patterns;
+}
+
+/** Masks value wherever it appears in later output. */
+export function addSecret(value: string | undefined): void {
+  if (value && value.length >= MIN_SECRET_LENGTH) secrets.add(value);
+}
+
+/** Forgets patterns and secrets; for tests. */
+export function resetRedaction(): void {
+  patterns = [];
+  secrets.clear();
+}
+
+export function isSensitiveKey(key: string): boolean {
+  return DEFAULT_KEY_PATTERNS.some((p) => p.test(key)) || patterns.some((p) => p.test(key));
+}
+
+/** The value to show for key: its first four characters when secret. */
+export function redactValue(key: string, value: string): string {
+  if (isSensitiveKey(key) || secrets.has(value)) {
+    return value.length >= MIN_SECRET_LENGTH ? value.slice(0, MIN_SECRET_LENGTH) + MASK : MASK;
+  }
+  return scrubText(value);
+}
+
+/** Masks registered secrets and redact.patterns matches in text. */
+export function scrubText(text: string): string {
+  let out = text;
+  // Longest first, so a secret containing another is masked whole
+  for (const secret of [...secrets].sort((a, b) => b.length - a.length)) {
+    out = out.split(secret).join(MASK);
+  }
+  for (const pattern of patterns) {
+    out = out.replace(new RegExp(pattern.source, pattern.flags.includes('g') ? pattern.flags : pattern.flags + 'g'), MASK);
+  }
+  return out;
+}
+
+/** scrubText over a structure; values under sensitive keys are redacted. */
+export function scrubFields<T>(value: T, key?: string): T {
+  if (typeof value === 'string') {
+    return (key !== undefined ? redactValue(key, value) : scrubText(value)) as T;
+  }
+  if (Array.isArray(value)) return value.map((v) => scrubFields(v)) as T;
+  if (value && typeof value === 'object') {
+    return Object.fromEntries(Object.entries(value).map(([k, v]) => [k, scrubFields(v, k)])) as T;
+  }
+  return value;
+}
@@ -0,0 +1,54 @@
+// ── Sizes and durations ─────────────────────────────────────────────
+//
+// Human-friendly values used in manifests, settings, and flags: sizes
+// like "512KB" or "10MB" (binary multiples), durations like "30m",
+// "12h", "7d", and counts like `-n 20`.
+
+const SIZE = /^(\d+(?:\.\d+)?)\s*(B|KB|KIB|MB|MIB|GB|GIB)?$/i;
+const SIZE_UNITS: Record<string, number> = {
+  B: 1,
+  KB: 1024,
+  KIB: 1024,
+  MB: 1024 ** 2,
+  MIB: 1024 ** 2,
+  GB: 1024 ** 3,
+  GIB: 1024 ** 3,
+};
+
+const DURATION = /^(\d+)\s*(s|m|h|d|w)$/;
+const DURATION_UNITS: Record<string, number> = {
+  s: 1000,
+  m: 60_000,
+  h: 3_600_000,
+  d: 86_400_000,
+  w: 604_800_000,
+};
+
+/** Bytes in a size such as "10MB"; a bare number is bytes. */
+export function parseSize(value: string): number {
+  const m = SIZE.exec(value.trim());
+  if (!m) throw new Error(`Invalid size "${value}" (expected e.g. 512KB, 10MB, 1GB)`);
+  return Math.round(Number(m[1]) * SIZE_UNITS[(m[2] ?? 'B').toUpperCase()]);
+}
+
+/** Milliseconds in a duration such as "7d". */
+export function parseDuration(value: string): number {
+  const m = DURATION.exec(value.trim());
+  if (!m) throw new Error(`Invalid duration "${value}" (expected e.g. 30m, 12h, 7d, 2w)`);
+  return Number(m[1]) * DURATION_UNITS[m[2]];
+}
+
+/** A whole number of at least min, such as a flag's iteration count. */
+export function parseCount(value: string, name: string, min = 1): number {
+  const n = /^\d+$/.test(value.trim()) ? Number(value.trim()) : Number.NaN;
+  if (!Number.isSafeInteger(n) || n < min) {
+    throw new Error(`Invalid ${name} "${value}" (expected a whole number of at least ${min})`);
+  }
+  return n;
+}
+
+export function formatBytes(n: number): string {
+  if (n < 1024) return `${n} B`;
+  if (n < 1024 * 1024) return `${(n / 1024).toFixed(1)} KiB`;
+  return `${(n / (1024 * 1024)).toFixed(1)} MiB`;
+}
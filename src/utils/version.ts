@@ -0,0 +1,25 @@
+// Comparison for the relaxed semver accepted in manifests: an optional
+// leading "v", any number of numeric parts, and an optional prerelease.
+
+function parse(v: string): { parts: number[]; pre: string } {
+  const [main, ...pre] = v.trim().replace(/^v/, '').split('-');
+  return {
+    parts: main.split('.').map((p) => parseInt(p, 10) || 0),
+    pre: pre.join('-'),
+  };
+}
+
+/** Negative when a < b, positive when a > b, 0 when equal. */
+export function compareVersions(a: string, b: string): number {
+  const pa = parse(a);
+  const pb = parse(b);
+  for (let i = 0; i < Math.max(pa.parts.length, pb.parts.length); i++) {
+    const d = (pa.parts[i] ?? 0) - (pb.parts[i] ?? 0);
+    if (d !== 0) return d;
+  }
+  // A release sorts after its prereleases: 1.0.0-rc.1 < 1.0.0
+  if (pa.pre === pb.pre) return 0;
+  if (!pa.pre) return 1;
+  if (!pb.pre) return -1;
+  return pa.pre.localeCompare(pb.pre, undefined, { numeric: true });
+}
@@ -0,0 +1,63 @@
+import { bench, describe, afterAll } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  discoverAll,
+  discoverAllAsync,
+  discoverAllCached,
+} from '../../src/core/registry.js';
+import type { Source } from '../../src/types/registry.js';
+
+// A synthetic catalog the size of a large enterprise extension set:
+// 2,000 types spread over categories and nested topic/vendor folders.
+// Search must stay under 200ms on a warm cache.
+
+const TYPE_COUNT = 2000;
+const SEARCH_BUDGET_MS = 200;
+const CATEGORIES = ['skills', 'workflows', 'prompts', 'personas', 'context', 'templates'];
+
+const root = join(tmpdir(), `agentx-bench-${process.pid}`);
+const catalogDir = join(root, 'catalog');
+const cachePath = join(root, 'registry-index.db');
+const sources: Source[] = [{ name: 'catalog', basePath: catalogDir }];
+
+for (let i = 0; i < TYPE_COUNT; i++) {
+  const category = CATEGORIES[i % CATEGORIES.length];
+  const dir = join(catalogDir, category, `topic-${i % 20}`, `vendor-${i % 7}`, `type-${i}`);
+  mkdirSync(dir, { recursive: true });
+  writeFileSync(
+    join(dir, 'manifest.yaml'),
+    `name: type-${i}\ntype: ${category}\nversion: "1.0.${i}"\ndescription: Benchmark type ${i}\ntags: [bench, t${i % 10}]\n`,
+  );
+}
+
+afterAll(() => {
+  rmSync(root, { recursive: true, force: true });
+});
+
+describe(`discovery (${TYPE_COUNT} types)`, () => {
+  bench('discoverAll (sequential)', () => {
+    discoverAll(sources);
+  });
+
+  bench('discoverAllAsync (worker pool)', async () => {
+    await discoverAllAsync(sources);
+  });
+});
+
+describe(`search (${TYPE_COUNT} types, warm cache)`, () => {
+  bench(
+    'discoverAllCached + filter',
+    async () => {
+      const start = performance.now();
+      const types = await discoverAllCached(sources, cachePath);
+      types.filter((t) => t.description.toLowerCase().includes('type 1999'));
+      const elapsed = performance.now() - start;
+      if (elapsed > SEARCH_BUDGET_MS) {
+        throw new Error(`search took ${elapsed.toFixed(0)}ms, budget is ${SEARCH_BUDGET_MS}ms`);
+      }
+    },
+    { setup: () => discoverAllCached(sources, cachePath).then(() => undefined) },
+  );
+});
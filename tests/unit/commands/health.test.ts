@@ -0,0 +1,62 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { Command } from 'commander';
+import { mkdirSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { registerHealth } from '../../../src/commands/health.js';
+import { initProject } from '../../../src/core/linker.js';
+import { setOutputFormat, resetDiagnostics } from '../../../src/ui/output.js';
+
+describe('health command', () => {
+  let root: string;
+  let stdout: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-health-command-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    const projectDir = join(root, 'project');
+    mkdirSync(projectDir, { recursive: true });
+    initProject(projectDir, []);
+    vi.spyOn(process, 'cwd').mockReturnValue(projectDir);
+
+    stdout = '';
+    vi.spyOn(process.stdout, 'write').mockImplementation((chunk) => {
+      stdout += String(chunk);
+      return true;
+    });
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => void (stdout += args.join(' ') + '\n'));
+    vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    setOutputFormat('text');
+    resetDiagnostics();
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  const run = (...args: string[]) => {
+    const program = new Command().exitOverride();
+    registerHealth(program);
+    return program.parseAsync(['node', 'agentx', 'health', ...args]);
+  };
+
+  it('wraps the report in the envelope under --output-format json', async () => {
+    setOutputFormat('json');
+    await run();
+    const payload = JSON.parse(stdout);
+    expect(payload).toMatchObject({ data: { score: expect.any(Number), grade: expect.any(String) }, warnings: [] });
+  });
+
+  it('prints the bare report for --json in text mode', async () => {
+    await run('--json');
+    expect(JSON.parse(stdout)).toMatchObject({ score: expect.any(Number), checks: expect.any(Array) });
+  });
+
+  it('keeps badges raw in JSON mode', async () => {
+    setOutputFormat('json');
+    await run('--badge');
+    expect(stdout.startsWith('<svg')).toBe(true);
+  });
+});
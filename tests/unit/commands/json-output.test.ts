@@ -0,0 +1,144 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { Command } from 'commander';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { registerInstall } from '../../../src/commands/install.js';
+import { registerLink } from '../../../src/commands/link.js';
+import { registerExtension } from '../../../src/commands/extension.js';
+import { registerDoctor } from '../../../src/commands/doctor.js';
+import { initProject } from '../../../src/core/linker.js';
+import { setOutputFormat, resetDiagnostics } from '../../../src/ui/output.js';
+import { executionContext, setExecutionContext, resetExecutionContext } from '../../../src/config/context.js';
+
+// Wrapper tooling parses these shapes (src/types/output.ts); the tests
+// pin their keys so a rename shows up as a failure here first.
+
+vi.mock('../../../src/core/extension.js', async (importOriginal) => ({
+  ...(await importOriginal<typeof import('../../../src/core/extension.js')>()),
+  listExtensions: async () => [{ name: 'acme', path: '/x/acme', branch: 'main', status: 'ok' }],
+}));
+
+const keys = (o: object) => Object.keys(o).sort();
+
+describe('--json output schemas', () => {
+  let root: string;
+  let projectDir: string;
+  let stdout: string;
+  let stderr: string[];
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-json-output-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    projectDir = join(root, 'project');
+    mkdirSync(projectDir, { recursive: true });
+    vi.spyOn(process, 'cwd').mockReturnValue(projectDir);
+
+    const persona = join(root, 'home', 'catalog-repo', 'catalog', 'personas', 'reviewer');
+    mkdirSync(persona, { recursive: true });
+    writeFileSync(
+      join(persona, 'manifest.yaml'),
+      'name: reviewer\ntype: persona\nversion: "1.2.0"\ndescription: Reviews code\ndeprecated: true\n',
+    );
+
+    stdout = '';
+    stderr = [];
+    vi.spyOn(process.stdout, 'write').mockImplementation((chunk) => {
+      stdout += String(chunk);
+      return true;
+    });
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => void (stdout += args.join(' ') + '\n'));
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => void stderr.push(args.join(' ')));
+    vi.spyOn(process, 'exit').mockImplementation((code) => {
+      throw new Error(`exit ${code}`);
+    });
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    resetExecutionContext();
+    setOutputFormat('text');
+    resetDiagnostics();
+    process.exitCode = undefined;
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  const run = (register: (program: Command) => void, ...args: string[]) => {
+    const program = new Command().exitOverride();
+    register(program);
+    return program.parseAsync(['node', 'agentx', ...args]);
+  };
+
+  it('install: summarizes the install and keeps warnings off stdout', async () => {
+    await run(registerInstall, 'install', 'personas/reviewer', '--yes', '--json');
+
+    const summary = JSON.parse(stdout);
+    expect(keys(summary)).toEqual(['alreadyInstalled', 'cliDependencies', 'installed', 'root', 'warnings']);
+    expect(summary).toMatchObject({ root: 'personas/reviewer', alreadyInstalled: 0, cliDependencies: [] });
+    expect(summary.installed).toHaveLength(1);
+    expect(keys(summary.installed[0])).toEqual(['category', 'files', 'typePath', 'version']);
+    expect(summary.installed[0]).toMatchObject({ typePath: 'personas/reviewer', category: 'persona', version: '1.2.0' });
+    expect(keys(summary.installed[0].files)).toEqual(['added', 'removed', 'unchanged', 'updated']);
+    expect(summary.warnings).toEqual(['personas/reviewer is deprecated.']);
+    expect(stderr).toEqual([expect.stringContaining('personas/reviewer is deprecated.')]);
+  });
+
+  it('install: carries warnings in the envelope under --output-format json', async () => {
+    setOutputFormat('json');
+    await run(registerInstall, 'install', 'personas/reviewer', '--yes');
+
+    const envelope = JSON.parse(stdout);
+    expect(keys(envelope)).toEqual(['data', 'warnings']);
+    expect(envelope.data.root).toBe('personas/reviewer');
+    expect(envelope.warnings).toEqual([
+      { level: 'warning', scope: 'deprecated', message: 'personas/reviewer is deprecated.' },
+    ]);
+    expect(stderr.some((line) => line.includes('Installed'))).toBe(false);
+    expect(stderr.some((line) => line.includes('deprecated'))).toBe(true);
+  });
+
+  it('install: refuses --json when it would have to prompt', async () => {
+    setExecutionContext({ ...executionContext(), interactive: true, answers: 'ask' });
+    await expect(run(registerInstall, 'install', 'personas/reviewer', '--json')).rejects.toThrow('exit 1');
+    expect(stdout).toBe('');
+    expect(stderr).toEqual([expect.stringContaining('install --json cannot ask for confirmation; pass --yes')]);
+  });
+
+  it('link status: reports each tool with its links', async () => {
+    initProject(projectDir, ['claude-code', 'no-such-tool']);
+    await run(registerLink, 'link', 'status', '--json');
+
+    const report = JSON.parse(stdout);
+    expect(keys(report)).toEqual(['tools', 'versionSkew']);
+    expect(report.versionSkew).toBeNull();
+    expect(report.tools.map((t: { tool: string }) => t.tool)).toEqual(['claude-code', 'no-such-tool']);
+    for (const tool of report.tools) {
+      expect(keys(tool)).toEqual(['files', 'generatedBy', 'status', 'symlinks', 'tool']);
+      expect(keys(tool.symlinks)).toEqual(['copies', 'drifted', 'total', 'valid']);
+    }
+    expect(report.tools[1]).toMatchObject({ status: 'error', generatedBy: null });
+  });
+
+  it('extension list: lists each extension with its status', async () => {
+    await run(registerExtension, 'extension', 'list', '--json');
+    expect(JSON.parse(stdout)).toEqual({
+      extensions: [{ name: 'acme', path: '/x/acme', branch: 'main', status: 'ok' }],
+    });
+  });
+
+  it('doctor: reports checks and exits 1 when one fails', async () => {
+    const manifest = join(root, 'broken.yaml');
+    writeFileSync(manifest, 'name: broken\n');
+    await run(registerDoctor, 'doctor', '--check-manifest', manifest, '--json');
+
+    const report = JSON.parse(stdout);
+    expect(keys(report)).toEqual(['checks', 'healthy', 'mode']);
+    expect(report).toMatchObject({ mode: 'platform-team', healthy: false });
+    expect(report.checks).toHaveLength(1);
+    expect(keys(report.checks[0])).toEqual(['detail', 'name', 'section', 'status']);
+    expect(report.checks[0]).toMatchObject({ section: 'Manifest Validation', name: manifest, status: 'fail' });
+    expect(process.exitCode).toBe(1);
+    expect(stderr).toEqual([]);
+  });
+});
@@ -0,0 +1,80 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { Command } from 'commander';
+import { rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { buildContext, installMiddleware, use } from '../../../src/commands/middleware.js';
+import { executionContext, resetExecutionContext } from '../../../src/config/context.js';
+import { outputFormat, setOutputFormat, setQuiet } from '../../../src/ui/output.js';
+import { resetLogging } from '../../../src/utils/log.js';
+
+describe('middleware', () => {
+  let home: string;
+  let prevHome: string | undefined;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-middleware-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+  });
+
+  afterEach(() => {
+    resetExecutionContext();
+    setOutputFormat('text');
+    setQuiet(false);
+    resetLogging();
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('turns prompts off for --no-input and CI', () => {
+    expect(buildContext({ input: false }, {}).interactive).toBe(false);
+    expect(buildContext({}, { CI: 'true' }).interactive).toBe(false);
+    expect(buildContext({}, { AGENTX_NO_INPUT: '1' }).interactive).toBe(false);
+  });
+
+  it('reads flags before environment defaults', () => {
+    expect(buildContext({}, { AGENTX_OUTPUT_FORMAT: 'json' }).output).toBe('json');
+    expect(buildContext({ outputFormat: 'text' }, { AGENTX_OUTPUT_FORMAT: 'json' }).output).toBe('text');
+    expect(buildContext({}, { NO_COLOR: '' }).color).toBe(false);
+    expect(buildContext({}, { AGENTX_TRACE_FS: '0' }).traceFs).toBe(false);
+  });
+
+  it('goes offline from --offline or AGENTX_OFFLINE', () => {
+    expect(buildContext({}, {}).offline).toBe(false);
+    expect(buildContext({ offline: true }, {}).offline).toBe(true);
+    expect(buildContext({}, { AGENTX_OFFLINE: '1' }).offline).toBe(true);
+  });
+
+  it('answers prompts from --non-interactive and --yes', () => {
+    expect(buildContext({}, {}).answers).toBe('ask');
+    expect(buildContext({ nonInteractive: true }, {}).answers).toBe('defaults');
+    expect(buildContext({}, { AGENTX_NONINTERACTIVE: '1' }).answers).toBe('defaults');
+    expect(buildContext({ yes: true }, { AGENTX_NONINTERACTIVE: '1' }).answers).toBe('yes');
+  });
+
+  it('maps --verbose and --quiet to log levels', () => {
+    expect(buildContext({}, {}).logLevel).toBe('warn');
+    expect(buildContext({ verbose: true }, {}).logLevel).toBe('debug');
+    expect(buildContext({ quiet: true }, {}).logLevel).toBe('error');
+    expect(buildContext({}, { AGENTX_LOG_LEVEL: 'info' }).logLevel).toBe('info');
+    expect(buildContext({}, { AGENTX_LOG_LEVEL: 'loud' }).logLevel).toBe('warn');
+  });
+
+  it('builds the context once and runs middleware before the action', async () => {
+    const seen: string[] = [];
+    use((ctx, command) => {
+      seen.push(`${command.name()}:${ctx.output}`);
+    });
+
+    const program = installMiddleware(new Command().exitOverride());
+    program.command('probe').action(() => {
+      seen.push(`action:${executionContext().output}:${outputFormat()}`);
+    });
+    await program.parseAsync(['node', 'agentx', '--output-format', 'json', '--no-input', 'probe']);
+
+    expect(seen).toEqual(['probe:json', 'action:json:json']);
+    expect(executionContext().interactive).toBe(false);
+  });
+});
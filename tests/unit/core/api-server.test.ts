@@ -0,0 +1,142 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createConnection, type Server, type AddressInfo } from 'node:net';
+import * as settings from '../../../src/config/settings.js';
+import { createApiServer, parseAddr } from '../../../src/core/api-server.js';
+
+describe('parseAddr', () => {
+  it('reads Go-style listen addresses', () => {
+    expect(parseAddr(':7777')).toEqual({ port: 7777 });
+    expect(parseAddr('127.0.0.1:8080')).toEqual({ host: '127.0.0.1', port: 8080 });
+    expect(parseAddr('[::1]:9000')).toEqual({ host: '::1', port: 9000 });
+    expect(() => parseAddr('7777')).toThrow(/Invalid address/);
+  });
+});
+
+describe('HTTP API', () => {
+  let root: string;
+  let server: Server;
+  let base: string;
+
+  const auth = { Authorization: 'Bearer s3cret-token' };
+
+  beforeEach(async () => {
+    root = join(tmpdir(), `agentx-serve-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    const config = join(root, 'home', 'config.yaml');
+    mkdirSync(join(root, 'home'), { recursive: true });
+    writeFileSync(config, 'run.manifest_guard: off\n');
+    settings.init(config);
+
+    const installedRoot = join(root, 'home', 'installed');
+    const skill = join(installedRoot, 'skills/demo/greet');
+    mkdirSync(skill, { recursive: true });
+    writeFileSync(
+      join(skill, 'manifest.yaml'),
+      'name: greet\ntype: skill\nversion: "1.0.0"\ndescription: Say hello\nruntime: node\ntopic: demo\n' +
+        'inputs:\n  - name: who\n    required: true\n  - name: passphrase\n    type: string\n    secret: true\n',
+    );
+    writeFileSync(
+      join(skill, 'index.mjs'),
+      'const args = JSON.parse(process.argv[3]);\nconsole.log(`hello ${args.who}`);\nconsole.error("done");\n',
+    );
+
+    server = createApiServer({ token: 's3cret-token', installedRoot });
+    await new Promise<void>((resolve) => server.listen(0, '127.0.0.1', resolve));
+    base = `http://127.0.0.1:${(server.address() as AddressInfo).port}/v1`;
+  });
+
+  afterEach(async () => {
+    await new Promise((resolve) => server.close(resolve));
+    settings.init('');
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('rejects requests without the token', async () => {
+    expect((await fetch(`${base}/types`)).status).toBe(401);
+    expect((await fetch(`${base}/types`, { headers: { Authorization: 'Bearer wrong' } })).status).toBe(401);
+  });
+
+  it('lists installed types', async () => {
+    const res = await fetch(`${base}/types?category=skill`, { headers: auth });
+    expect(await res.json()).toEqual([
+      { typePath: 'skills/demo/greet', category: 'skill', version: '1.0.0', description: 'Say hello' },
+    ]);
+  });
+
+  it.skipIf(process.platform === 'win32')('lists types again after another process installs', async () => {
+    const list = async () =>
+      ((await (await fetch(`${base}/types`, { headers: auth })).json()) as { typePath: string }[]).map((t) => t.typePath);
+    expect(await list()).toEqual(['skills/demo/greet']);
+
+    const other = join(root, 'home', 'installed', 'context/demo/notes');
+    mkdirSync(other, { recursive: true });
+    writeFileSync(join(other, 'manifest.yaml'), 'name: notes\ntype: context\nversion: "1.0.0"\ndescription: Notes\n');
+    // Still served from memory until a change is announced
+    expect(await list()).toEqual(['skills/demo/greet']);
+
+    await new Promise<void>((resolve, reject) => {
+      const event = { seq: 1, kind: 'install', typePaths: ['context/demo/notes'], pid: process.pid + 1, at: 'now' };
+      const conn = createConnection(join(root, 'home', 'notify', `${process.pid}.sock`), () => {
+        conn.end(JSON.stringify(event) + '\n');
+      });
+      conn.on('close', () => resolve());
+      conn.on('error', reject);
+    });
+    await new Promise((resolve) => setTimeout(resolve, 50));
+    expect((await list()).sort()).toEqual(['context/demo/notes', 'skills/demo/greet']);
+  });
+
+  it('runs a skill and streams its output', async () => {
+    const started = await fetch(`${base}/runs`, {
+      method: 'POST',
+      headers: { ...auth, 'Content-Type': 'application/json' },
+      body: JSON.stringify({ typePath: 'skills/demo/greet', inputs: { who: 'web' } }),
+    });
+    expect(started.status).toBe(202);
+    const { id, status } = (await started.json()) as { id: string; status: string };
+    expect(status).toBe('running');
+
+    const events = await (await fetch(`${base}/runs/${id}/events`, { headers: auth })).text();
+    expect(events).toContain('event: stdout\ndata: hello web');
+    expect(events).toMatch(/event: exit\ndata: \{.*"status":"succeeded"/);
+
+    const run = await (await fetch(`${base}/runs/${id}`, { headers: auth })).json();
+    expect(run).toMatchObject({ status: 'succeeded', exitCode: 0, stdout: 'hello web\n', stderr: 'done\n' });
+    expect(await (await fetch(`${base}/runs`, { headers: auth })).json()).toHaveLength(1);
+  });
+
+  it('masks secret inputs in run records and events', async () => {
+    const started = await fetch(`${base}/runs`, {
+      method: 'POST',
+      headers: { ...auth, 'Content-Type': 'application/json' },
+      body: JSON.stringify({ typePath: 'skills/demo/greet', inputs: { who: 'web', passphrase: 'hunter22', apiToken: 'tok-123456' } }),
+    });
+    const { id, inputs } = (await started.json()) as { id: string; inputs: Record<string, string> };
+    expect(inputs).toEqual({ who: 'web', passphrase: '***', apiToken: '***' });
+
+    const events = await (await fetch(`${base}/runs/${id}/events`, { headers: auth })).text();
+    expect(events).toContain('hello web');
+    const listed = await (await fetch(`${base}/runs`, { headers: auth })).text();
+    for (const body of [events, listed]) {
+      expect(body).not.toContain('hunter22');
+      expect(body).not.toContain('tok-123456');
+    }
+  });
+
+  it('reports failed runs and bad requests', async () => {
+    const post = (body: unknown) =>
+      fetch(`${base}/runs`, { method: 'POST', headers: auth, body: JSON.stringify(body) });
+    expect((await post({ inputs: {} })).status).toBe(400);
+    expect((await post({ typePath: '../../etc' })).status).toBe(400);
+
+    const { id } = (await (await post({ typePath: 'skills/demo/greet' })).json()) as { id: string };
+    await (await fetch(`${base}/runs/${id}/events`, { headers: auth })).text();
+    const run = await (await fetch(`${base}/runs/${id}`, { headers: auth })).json();
+    expect(run).toMatchObject({ status: 'failed', error: 'Missing required input: who' });
+    expect((await fetch(`${base}/runs/nope`, { headers: auth })).status).toBe(404);
+  });
+});
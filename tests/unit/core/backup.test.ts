@@ -0,0 +1,81 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, writeFileSync, mkdirSync, rmSync, existsSync, statSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createBackup, restoreBackup } from '../../../src/core/backup.js';
+import { initGlobal, switchProfile, activeProfileName, getUserdataRoot } from '../../../src/core/userdata.js';
+
+describe('userdata backups', () => {
+  let home: string;
+  let archive: string;
+  let registry: string;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-backup-test-${Date.now()}`);
+    process.env.AGENTX_HOME = home;
+    archive = join(home, 'backup.tar.gz');
+    initGlobal(() => {});
+
+    const userdata = getUserdataRoot();
+    writeFileSync(join(userdata, 'env', 'aws.env'), 'AWS_REGION=us-east-1\nAWS_SECRET_ACCESS_KEY=abc123\n');
+    writeFileSync(join(userdata, 'profiles', 'work.yaml'), 'name: work\n');
+    switchProfile('work');
+    registry = join(userdata, 'skills', 'scm', 'git', 'commit');
+    mkdirSync(join(registry, 'state'), { recursive: true });
+    mkdirSync(join(registry, 'output'), { recursive: true });
+    writeFileSync(join(registry, 'config.yaml'), 'style: conventional\n');
+    writeFileSync(join(registry, 'settings.yaml'), 'api_key: k-123456\nremote:\n  auth: ghp_abcdef\n');
+    writeFileSync(join(registry, 'state', 'last.json'), '{}');
+    writeFileSync(join(registry, 'output', 'latest.json'), '{}');
+    writeFileSync(join(registry, 'tokens.env'), '# GitHub\nGITHUB_ORG=acme\nGH_PAT=ghp_abcdef\n');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('blanks secrets in a plaintext backup and restores the rest onto an empty machine', () => {
+    expect(createBackup(archive, { plaintext: true })).toMatchObject({ encrypted: false, redacted: 5 });
+
+    rmSync(getUserdataRoot(), { recursive: true });
+    const result = restoreBackup(archive);
+
+    expect(result).toMatchObject({ skipped: [], redacted: 5, activeProfile: 'work' });
+    const userdata = getUserdataRoot();
+    expect(readFileSync(join(userdata, 'env', 'aws.env'), 'utf-8')).toBe(
+      'AWS_REGION=us-east-1\n# Value removed by a plaintext agentx backup\nAWS_SECRET_ACCESS_KEY=\n',
+    );
+    expect(readFileSync(join(registry, 'tokens.env'), 'utf-8')).toContain('\nGITHUB_ORG=\n');
+    expect(readFileSync(join(registry, 'config.yaml'), 'utf-8')).toBe('style: conventional\n');
+    const settings = readFileSync(join(registry, 'settings.yaml'), 'utf-8');
+    expect(settings).not.toContain('k-123456');
+    expect(settings).not.toContain('ghp_abcdef');
+    expect(existsSync(join(registry, 'state', 'last.json'))).toBe(true);
+    expect(existsSync(join(registry, 'output'))).toBe(false);
+    if (process.platform !== 'win32') {
+      expect(statSync(join(registry, 'tokens.env')).mode & 0o777).toBe(0o600);
+      expect(statSync(join(userdata, 'env')).mode & 0o777).toBe(0o700);
+    }
+  });
+
+  it('keeps existing files and the active profile unless forced', () => {
+    createBackup(archive, { plaintext: true });
+    writeFileSync(join(registry, 'config.yaml'), 'style: local\n');
+    switchProfile('default');
+
+    expect(restoreBackup(archive).skipped).toContain('skills/scm/git/commit/config.yaml');
+    expect(readFileSync(join(registry, 'config.yaml'), 'utf-8')).toBe('style: local\n');
+    expect(activeProfileName()).toBe('default');
+
+    restoreBackup(archive, { overwrite: true });
+    expect(readFileSync(join(registry, 'config.yaml'), 'utf-8')).toBe('style: conventional\n');
+    expect(activeProfileName()).toBe('work');
+  });
+
+  it('refuses missing files and non-backups', () => {
+    expect(() => restoreBackup(join(home, 'missing.tar.gz'))).toThrow(/Backup not found/);
+    writeFileSync(join(home, 'notes.txt'), 'x');
+    expect(() => restoreBackup(join(home, 'notes.txt'))).toThrow();
+  });
+});
@@ -0,0 +1,66 @@
+import { describe, it, expect } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { percentile, summarize, parseTimes, compareBench, benchSkill, type BenchReport } from '../../../src/core/bench.js';
+import type { SkillManifest } from '../../../src/types/manifest.js';
+
+function report(p50: number, p95: number, cpu: boolean): BenchReport {
+  const stats = { min: p50, mean: p50, p50, p90: p95, p95, p99: p95, max: p95 };
+  return {
+    skill: 'skills/x', version: '1.0.0', runtime: 'node', iterations: 1, warmup: 0, inputs: [], failed: 0,
+    wallMs: stats, cpuMs: cpu ? stats : null, outputBytes: { ...stats, p50: 100, p95: 100 },
+    runs: [], platform: 'linux-x64', cliVersion: '0.0.0', at: '',
+  };
+}
+
+describe('bench', () => {
+  it('takes nearest-rank percentiles', () => {
+    const sorted = Array.from({ length: 20 }, (_, i) => i + 1);
+    expect(percentile(sorted, 50)).toBe(10);
+    expect(percentile(sorted, 95)).toBe(19);
+    expect(percentile(sorted, 99)).toBe(20);
+    expect(percentile([7], 90)).toBe(7);
+    expect(percentile([], 50)).toBe(0);
+  });
+
+  it('summarizes unsorted values', () => {
+    expect(summarize([30, 10, 20])).toEqual({ min: 10, mean: 20, p50: 20, p90: 30, p95: 30, p99: 30, max: 30 });
+  });
+
+  it("reads the children's CPU time from times", () => {
+    expect(parseTimes('0m0.010s 0m0.002s\n0m1.250s 0m0.050s\n')).toBe(1300);
+    expect(parseTimes('1m0.000s 0m0.000s\n1m2.000s 0m0.500s')).toBe(62500);
+    expect(parseTimes('')).toBeNull();
+  });
+
+  it.skipIf(process.platform === 'win32')('kills runs that exceed limits.timeout', async () => {
+    const root = join(tmpdir(), `agentx-bench-test-${Date.now()}`);
+    const dir = join(root, 'hang');
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'index.mjs'), 'setInterval(() => {}, 1000);\n');
+    process.env.AGENTX_HOME = join(root, 'home');
+    const manifest = {
+      name: 'hang', type: 'skill', version: '1.0.0', description: 'd', runtime: 'node', topic: 't', limits: { timeout: '1s' },
+    } as unknown as SkillManifest;
+    try {
+      const result = await benchSkill('skills/t/hang', dir, manifest, {}, { iterations: 1 });
+      expect(result.failed).toBe(1);
+      expect(result.runs[0]).toMatchObject({ exitCode: 124, timedOut: true });
+      await expect(benchSkill('skills/t/hang', dir, manifest, {}, { iterations: 1, warmup: Number.NaN })).rejects.toThrow(/warmup/);
+    } finally {
+      delete process.env.AGENTX_HOME;
+      rmSync(root, { recursive: true, force: true });
+    }
+  });
+
+  it('compares p50 and p95 against a baseline', () => {
+    const deltas = compareBench(report(100, 200, true), report(150, 200, false));
+    expect(deltas).toEqual([
+      { metric: 'wallMs', stat: 'p50', baseline: 100, current: 150, change: 0.5 },
+      { metric: 'wallMs', stat: 'p95', baseline: 200, current: 200, change: 0 },
+      { metric: 'outputBytes', stat: 'p50', baseline: 100, current: 100, change: 0 },
+      { metric: 'outputBytes', stat: 'p95', baseline: 100, current: 100, change: 0 },
+    ]);
+  });
+});
@@ -0,0 +1,84 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { catalogStats } from '../../../src/core/catalog-stats.js';
+
+describe('catalogStats', () => {
+  let root: string;
+
+  function write(typePath: string, manifest: string, files: Record<string, string> = {}): void {
+    const dir = join(root, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), manifest);
+    for (const [name, content] of Object.entries(files)) writeFileSync(join(dir, name), content);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-catalog-stats-test-${Date.now()}`);
+    write(
+      'prompts/review',
+      'name: review\ntype: prompt\nversion: "1.0.0"\ndescription: Review\ntags: [review]\npersona: personas/reviewer\n',
+    );
+    write(
+      'personas/reviewer',
+      'name: reviewer\ntype: persona\nversion: "1.0.0"\ndescription: Reviewer\ncontext:\n  - context/lang/go\n',
+    );
+    write(
+      'context/lang/go',
+      'name: go\ntype: context\nversion: "1.0.0"\ndescription: Go\ntags: [go]\nformat: markdown\nsources: [content.md]\n',
+      { 'content.md': '# Go\n\nUse gofmt. Handle every error.\n' },
+    );
+    write(
+      'skills/scm/git/commit-analyzer',
+      'name: commit-analyzer\ntype: skill\nversion: "1.0.0"\ndescription: Commits\ntags: [git]\nruntime: node\ntopic: scm\n',
+    );
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('counts types and lists the ones that need curating', () => {
+    const stats = catalogStats([{ name: 'catalog', basePath: root }]);
+    expect(stats.total).toBe(4);
+    expect(stats.byCategory).toEqual({ context: 1, persona: 1, prompt: 1, skill: 1 });
+    expect(stats.byTopic).toEqual({ '(none)': 2, lang: 1, scm: 1 });
+    expect(stats.byVendor).toEqual({ '(none)': 3, git: 1 });
+    expect(stats.missingTags).toEqual(['personas/reviewer']);
+    expect(stats.skillsWithoutTests).toEqual(['skills/scm/git/commit-analyzer']);
+    // The context is reached through the persona
+    expect(stats.orphans).toEqual(['skills/scm/git/commit-analyzer']);
+    expect(stats.contextTokens).toHaveLength(1);
+    expect(stats.contextTokens[0].tokens).toBeGreaterThan(0);
+    expect(stats.totalContextTokens).toBe(stats.contextTokens[0].tokens);
+  });
+
+  it("reads the manifest of the source that provides a type", () => {
+    const ext = `${root}-ext`;
+    const dir = join(ext, 'skills/scm/git/commit-analyzer');
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      'name: commit-analyzer\ntype: skill\nversion: "2.0.0"\ndescription: Commits\ntags: [git]\nruntime: node\ntopic: scm\ntests:\n  - name: smoke\n',
+    );
+    try {
+      const stats = catalogStats([
+        { name: 'ext', basePath: ext },
+        { name: 'catalog', basePath: root },
+      ]);
+      expect(stats.total).toBe(4);
+      expect(stats.skillsWithoutTests).toEqual([]);
+    } finally {
+      rmSync(ext, { recursive: true, force: true });
+    }
+  });
+
+  it('reports a broken context and keeps going', () => {
+    write('context/broken', 'name: broken\ntype: context\nversion: "1.0.0"\ndescription: Broken\ntags: [x]\nsources: ["missing/*.md"]\n');
+    const warnings: string[] = [];
+    const stats = catalogStats([{ name: 'catalog', basePath: root }], warnings);
+    expect(stats.contextTokens.map((c) => c.typePath)).toEqual(['context/lang/go', 'context/broken']);
+    expect(warnings).toEqual(['catalog: Context glob matched no files: missing/*.md']);
+  });
+});
@@ -2,7 +2,7 @@ import { describe, it, expect, beforeEach, afterEach } from 'vitest';
 import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
 import { join } from 'node:path';
 import { tmpdir } from 'node:os';
-import { compose, render } from '../../../src/core/compose.js';
+import { compose, render, applyBudget, resolveVariables, applyVariables } from '../../../src/core/compose.js';
 
 describe('compose', () => {
   let installedDir: string;
@@ -123,4 +123,250 @@ persona: personas/test`,
     expect(md).toContain('**Expertise:** Testing');
     expect(md).toContain('**Tone:** direct');
   });
+
+  it('records token counts and drops lowest-priority context over budget', () => {
+    for (const name of ['first', 'second']) {
+      const ctxDir = join(installedDir, `context/${name}`);
+      mkdirSync(ctxDir, { recursive: true });
+      writeFileSync(
+        join(ctxDir, 'manifest.yaml'),
+        `name: ${name}
+type: context
+version: "1.0.0"
+description: ${name} context
+format: markdown
+sources:
+  - content.md`,
+      );
+      writeFileSync(join(ctxDir, 'content.md'), `${name} `.repeat(200));
+    }
+
+    const promptDir = join(installedDir, 'prompts/budget');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(
+      join(promptDir, 'manifest.yaml'),
+      `name: budget
+type: prompt
+version: "1.0.0"
+description: Budget prompt
+context:
+  - context/first
+  - context/second`,
+    );
+
+    const composed = compose('prompts/budget', installedDir);
+    expect(composed.context[0].tokens).toBeGreaterThan(0);
+    expect(composed.tokens.total).toBe(composed.tokens.context);
+
+    const budgeted = applyBudget(composed, composed.context[0].tokens);
+    expect(budgeted.context.length).toBe(1);
+    expect(budgeted.context[0].name).toBe('First');
+    expect(budgeted.tokens.total).toBeLessThanOrEqual(budgeted.tokens.max!);
+    expect(budgeted.warnings.some((w) => w.includes('Dropped context "Second"'))).toBe(true);
+  });
+
+  it('renders through the prompt template when one is declared', () => {
+    const personaDir = join(installedDir, 'personas/test');
+    mkdirSync(personaDir, { recursive: true });
+    writeFileSync(
+      join(personaDir, 'manifest.yaml'),
+      `name: test
+type: persona
+version: "1.0.0"
+description: Test's persona
+tone: direct`,
+    );
+
+    const promptDir = join(installedDir, 'prompts/templated');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(
+      join(promptDir, 'manifest.yaml'),
+      `name: templated
+type: prompt
+version: "1.0.0"
+description: Templated prompt
+persona: personas/test
+template: prompt.hbs`,
+    );
+    writeFileSync(join(promptDir, 'prompt.hbs'), 'You are {{persona.description}} ({{persona.tone}}).');
+
+    const composed = compose('prompts/templated', installedDir);
+    expect(render(composed)).toBe("You are Test's persona (direct).");
+    expect(render(composed, { builtin: true })).toContain('# Persona: test');
+  });
+
+  it('resolves shared partials and helpers in prompt templates', () => {
+    const personaDir = join(installedDir, 'personas/test');
+    mkdirSync(personaDir, { recursive: true });
+    writeFileSync(
+      join(personaDir, 'manifest.yaml'),
+      `name: test
+type: persona
+version: "1.0.0"
+description: Test persona
+expertise: [java, aws]`,
+    );
+
+    const promptDir = join(installedDir, 'prompts/partials');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(
+      join(promptDir, 'manifest.yaml'),
+      `name: partials
+type: prompt
+version: "1.0.0"
+description: Partials prompt
+persona: personas/test
+template: prompt.hbs`,
+    );
+    writeFileSync(join(promptDir, 'prompt.hbs'), '{{> header}}{{join persona.expertise " / "}}');
+
+    const partialsDir = join(installedDir, 'templates/_partials');
+    mkdirSync(partialsDir, { recursive: true });
+    writeFileSync(join(partialsDir, 'header.hbs'), '{{upper persona.name}}: ');
+
+    const composed = compose('prompts/partials', installedDir);
+    const output = render(composed, {
+      partialSources: [{ name: 'installed', basePath: installedDir }],
+    });
+    expect(output).toBe('TEST: java / aws');
+  });
+
+  it('falls back to the built-in layout when the template is missing', () => {
+    const promptDir = join(installedDir, 'prompts/missing-template');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(
+      join(promptDir, 'manifest.yaml'),
+      `name: missing-template
+type: prompt
+version: "1.0.0"
+description: Missing template
+template: prompt.hbs`,
+    );
+
+    const composed = compose('prompts/missing-template', installedDir);
+    expect(composed.template).toBeNull();
+    expect(composed.warnings[0]).toContain('Prompt template not found');
+  });
+
+  it('renders xml and json formats', () => {
+    const personaDir = join(installedDir, 'personas/test');
+    mkdirSync(personaDir, { recursive: true });
+    writeFileSync(
+      join(personaDir, 'manifest.yaml'),
+      `name: test
+type: persona
+version: "1.0.0"
+description: Test
+tone: direct`,
+    );
+
+    const promptDir = join(installedDir, 'prompts/formats');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(
+      join(promptDir, 'manifest.yaml'),
+      `name: formats
+type: prompt
+version: "1.0.0"
+description: Formats prompt
+persona: personas/test`,
+    );
+
+    const composed = compose('prompts/formats', installedDir);
+    const xml = render(composed, { format: 'xml' });
+    expect(xml).toContain('<prompt name="formats">');
+    expect(xml).toContain('<tone>direct</tone>');
+
+    const json = JSON.parse(render(composed, { format: 'json' }));
+    expect(json.promptName).toBe('formats');
+    expect(json.persona.tone).toBe('direct');
+
+    expect(() => render(composed, { format: 'yaml' })).toThrow('Unknown output format');
+  });
+
+  it('expands glob and directory context sources in order', () => {
+    const ctxDir = join(installedDir, 'context/docs');
+    mkdirSync(join(ctxDir, 'guide/advanced'), { recursive: true });
+    writeFileSync(
+      join(ctxDir, 'manifest.yaml'),
+      `name: docs
+type: context
+version: "1.0.0"
+description: Docs context
+format: markdown
+sources:
+  - guide/
+  - "*.txt"`,
+    );
+    writeFileSync(join(ctxDir, 'guide/10-advanced.md'), 'ten');
+    writeFileSync(join(ctxDir, 'guide/2-setup.md'), 'two');
+    writeFileSync(join(ctxDir, 'guide/README.md'), 'readme');
+    writeFileSync(join(ctxDir, 'guide/advanced/deep.md'), 'deep');
+    writeFileSync(join(ctxDir, 'notes.txt'), 'notes');
+
+    const promptDir = join(installedDir, 'prompts/docs');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(
+      join(promptDir, 'manifest.yaml'),
+      `name: docs
+type: prompt
+version: "1.0.0"
+description: Docs prompt
+context:
+  - context/docs`,
+    );
+
+    const composed = compose('prompts/docs', installedDir);
+    expect(composed.context.map((c) => c.source)).toEqual([
+      'guide/README.md',
+      'guide/2-setup.md',
+      'guide/10-advanced.md',
+      'guide/advanced/deep.md',
+      'notes.txt',
+    ]);
+  });
+
+  it('fills declared variables from flags, project values, and defaults', () => {
+    const ctxDir = join(installedDir, 'context/review');
+    mkdirSync(ctxDir, { recursive: true });
+    writeFileSync(
+      join(ctxDir, 'manifest.yaml'),
+      'name: review\ntype: context\nversion: "1.0.0"\ndescription: d\nformat: markdown\nsources:\n  - content.md',
+    );
+    writeFileSync(join(ctxDir, 'content.md'), 'Review PR {{pr_number}} in {{ service_name }}. Keep {{.Values}} as is.');
+
+    const promptDir = join(installedDir, 'prompts/review-pr');
+    mkdirSync(promptDir, { recursive: true });
+    writeFileSync(
+      join(promptDir, 'manifest.yaml'),
+      `name: review-pr
+type: prompt
+version: "1.0.0"
+description: Review a pull request
+context:
+  - context/review
+template: prompt.hbs
+variables:
+  - name: pr_number
+    required: true
+  - name: service_name
+    default: api
+  - name: reviewer`,
+    );
+    writeFileSync(join(promptDir, 'prompt.hbs'), '#{{pr_number}} ({{vars.service_name}}): {{#each context}}{{content}}{{/each}}');
+
+    const composed = compose('prompts/review-pr', installedDir);
+    expect(resolveVariables(composed, {}).missing.map((v) => v.name)).toEqual(['pr_number']);
+    expect(() => resolveVariables(composed, { flags: { pr: '1' } })).toThrow(/no variable\(s\): pr/);
+
+    const { values, missing } = resolveVariables(composed, {
+      flags: { pr_number: '482' },
+      project: { pr_number: 7, service_name: 'payments' },
+    });
+    expect(missing).toEqual([]);
+    expect(values).toEqual({ pr_number: '482', service_name: 'payments' });
+
+    const filled = applyVariables(composed, values);
+    expect(render(filled)).toBe('#482 (payments): Review PR 482 in payments. Keep {{.Values}} as is.');
+    expect(render(filled, { builtin: true })).toContain('Review PR 482 in payments.');
+  });
 });
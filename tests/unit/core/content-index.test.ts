@@ -0,0 +1,51 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { tokenize, rebuildContentIndex, searchContent } from '../../../src/core/content-index.js';
+
+describe('content index', () => {
+  let root: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-content-index-test-${Date.now()}`);
+    process.env.AGENTX_HOME = root;
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  function installContext(name: string, content: string): void {
+    const dir = join(root, 'installed', 'context', name);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'context.yaml'), `name: ${name}\nsources:\n  - guide.md\n`);
+    writeFileSync(join(dir, 'guide.md'), content);
+  }
+
+  it('splits words in any script and keeps accented letters', () => {
+    expect(tokenize('Die Größe der Übersetzung')).toEqual(['die', 'größe', 'der', 'übersetzung']);
+    expect(tokenize('Configuración: café, niño')).toEqual(['configuración', 'café', 'niño']);
+    expect(tokenize('Привет, мир! 日本語の文書')).toEqual(['привет', 'мир', '日本語の文書']);
+    expect(tokenize('a b c-d OAuth2')).toEqual(['oauth2']);
+  });
+
+  it('treats composed and decomposed accents as the same term', () => {
+    expect(tokenize('cafe\u0301')).toEqual(tokenize('caf\u00e9'));
+    expect(tokenize('ＡＰＩ')).toEqual(['api']);
+  });
+
+  it('finds non-ASCII words in installed context', () => {
+    installContext('es-guide', '# Guía\n\nLa configuración de seguridad usa OAuth2.\n');
+    installContext('de-guide', '# Leitfaden\n\nDie Größe des Heaps begrenzen.\n');
+    rebuildContentIndex(join(root, 'installed'));
+
+    expect(searchContent('configuración').map((h) => h.typePath)).toEqual(['context/es-guide']);
+    expect(searchContent('größe').map((h) => h.typePath)).toEqual(['context/de-guide']);
+    // A decomposed query matches the composed text
+    const [hit] = searchContent('configuracio\u0301n de seguridad');
+    expect(hit.typePath).toBe('context/es-guide');
+    expect(hit.snippet).toContain('configuración de seguridad');
+  });
+});
@@ -0,0 +1,78 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  hostOf,
+  hostEnvVar,
+  parseNetrc,
+  resolveCredential,
+  authorizationHeader,
+  securityQuote,
+} from '../../../src/core/credentials.js';
+
+describe('credentials', () => {
+  let root: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-credentials-test-${Date.now()}`);
+    mkdirSync(root, { recursive: true });
+    process.env.AGENTX_HOME = root;
+    process.env.NETRC = join(root, 'netrc');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    delete process.env.NETRC;
+    delete process.env[hostEnvVar('git.acme.com')];
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('extracts hosts from https and scp-style URLs', () => {
+    expect(hostOf('https://Git.Acme.com/team/catalog.git')).toBe('git.acme.com');
+    expect(hostOf('git@github.com:acme/knowledge.git')).toBe('github.com');
+    expect(hostOf('not a url')).toBeNull();
+    expect(hostEnvVar('git.acme.com')).toBe('AGENTX_TOKEN_GIT_ACME_COM');
+  });
+
+  it('parses netrc machines and defaults', () => {
+    const entries = parseNetrc(`
+machine git.acme.com login ci password s3cret
+# comment
+default
+  login anon
+  password guest
+`);
+    expect(entries.get('git.acme.com')).toEqual({ login: 'ci', password: 's3cret' });
+    expect(entries.get('default')).toEqual({ login: 'anon', password: 'guest' });
+  });
+
+  it('never sends the netrc default entry to an unnamed host', () => {
+    writeFileSync(process.env.NETRC!, 'machine git.acme.com login ci password s3cret\ndefault login anon password guest\n');
+    expect(resolveCredential('git.acme.com')).toMatchObject({ from: 'netrc', secret: 's3cret' });
+    expect(resolveCredential('evil.example.com')).toBeNull();
+  });
+
+  it('prefers env over the credentials file over netrc', () => {
+    writeFileSync(process.env.NETRC!, 'machine git.acme.com login ci password from-netrc\n');
+    expect(resolveCredential('git.acme.com')).toMatchObject({ from: 'netrc', secret: 'from-netrc' });
+
+    writeFileSync(join(root, 'credentials.yaml'), 'hosts:\n  git.acme.com: from-file\n');
+    expect(resolveCredential('git.acme.com')).toMatchObject({ from: 'file', secret: 'from-file' });
+
+    process.env[hostEnvVar('git.acme.com')] = 'from-env';
+    expect(resolveCredential('git.acme.com')).toMatchObject({ from: 'env', secret: 'from-env' });
+  });
+
+  it('builds bearer or basic authorization headers', () => {
+    expect(authorizationHeader({ host: 'h', secret: 't', from: 'env' })).toBe('Bearer t');
+    expect(authorizationHeader({ host: 'h', username: 'u', secret: 'p', from: 'netrc' })).toBe(
+      `Basic ${Buffer.from('u:p').toString('base64')}`,
+    );
+  });
+
+  it('quotes keychain words for the security -i command line', () => {
+    expect(securityQuote('github.com')).toBe('"github.com"');
+    expect(securityQuote('a "b" \\c')).toBe('"a \\"b\\" \\\\c"');
+  });
+});
@@ -0,0 +1,134 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createConnection } from 'node:net';
+import { storeType, materialize } from '../../../src/core/store.js';
+import { superviseDaemon, daemonStatus, daemonDir, daemonLogPath, listDaemons, loadDaemonSkill } from '../../../src/core/daemon.js';
+
+describe('daemon skills', () => {
+  let root: string;
+  let installedRoot: string;
+
+  function skill(name: string, manifest: string, script: string): string {
+    const dir = join(installedRoot, 'skills/monitoring', name);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      `name: ${name}\ntype: skill\nversion: "1.0.0"\ndescription: d\nruntime: node\ntopic: monitoring\n${manifest}`,
+    );
+    writeFileSync(join(dir, 'index.mjs'), script);
+    return `skills/monitoring/${name}`;
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-daemon-test-${Date.now()}`);
+    process.env.AGENTX_HOME = root;
+    installedRoot = join(root, 'installed');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('refuses skills without mode: daemon', () => {
+    const typePath = skill('once', '', 'console.log(1)\n');
+    expect(() => loadDaemonSkill(typePath, installedRoot)).toThrow(/not a daemon skill/);
+  });
+
+  it('restarts a crashing skill until the policy gives up, capturing its output', async () => {
+    const typePath = skill(
+      'spikes',
+      'mode: daemon\ndaemon:\n  restart: on-failure\n  max_restarts: 2\n  restart_delay: 0s\n',
+      'const args = JSON.parse(process.argv[3]);\nconsole.log(`polling ${args.service}`);\nprocess.exit(3);\n',
+    );
+
+    const state = await superviseDaemon(typePath, installedRoot, { service: 'api' });
+    expect(state).toMatchObject({ restarts: 2, gaveUp: true, lastExit: { code: 3 } });
+
+    const log = readFileSync(daemonLogPath(typePath), 'utf-8');
+    expect(log.match(/polling api/g)).toHaveLength(3);
+    expect(log).toContain('agentx: exited with code 3');
+
+    expect(existsSync(join(daemonDir(typePath), 'daemon.pid'))).toBe(false);
+    expect(daemonStatus(typePath)).toMatchObject({ running: false, state: { typePath, inputs: { service: 'api' } } });
+    expect(listDaemons().map((d) => d.typePath)).toEqual([typePath]);
+  });
+
+  it('masks secret inputs in state.json', async () => {
+    const typePath = skill(
+      'secretive',
+      'mode: daemon\ndaemon:\n  restart: never\ninputs:\n  - name: service\n    type: string\n  - name: key\n    type: string\n    secret: true\n',
+      'process.exit(0);\n',
+    );
+    await superviseDaemon(typePath, installedRoot, { service: 'api', key: 'k-0123456789' });
+
+    const raw = readFileSync(join(daemonDir(typePath), 'state.json'), 'utf-8');
+    expect(raw).not.toContain('k-0123456789');
+    expect(JSON.parse(raw).inputs).toEqual({ service: 'api', key: '***' });
+  });
+
+  it('does not restart a clean exit under on-failure', async () => {
+    const typePath = skill('oneshot', 'mode: daemon\ndaemon:\n  restart: on-failure\n  restart_delay: 0s\n', 'console.log("done");\nprocess.exit(0);\n');
+    const state = await superviseDaemon(typePath, installedRoot, {});
+    expect(state).toMatchObject({ restarts: 0, lastExit: { code: 0, signal: null } });
+    expect(state.gaveUp).toBeUndefined();
+    expect(readFileSync(daemonLogPath(typePath), 'utf-8').match(/agentx: started/g)).toHaveLength(1);
+  });
+
+  it('refuses a skill whose manifest was edited after install', () => {
+    const src = join(root, 'src', 'edited');
+    mkdirSync(src, { recursive: true });
+    writeFileSync(join(src, 'manifest.yaml'), 'name: edited\ntype: skill\nversion: "1.0.0"\nruntime: node\nmode: daemon\n');
+    const typePath = 'skills/monitoring/edited';
+    materialize(storeType(typePath, src, '1.0.0'), installedRoot);
+
+    const manifest = join(installedRoot, typePath, 'manifest.yaml');
+    // Replace rather than write through: store objects are hard-linked
+    rmSync(manifest);
+    writeFileSync(manifest, 'name: edited\ntype: skill\nversion: "1.0.0"\nruntime: shell\nmode: daemon\n');
+    expect(() => loadDaemonSkill(typePath, installedRoot)).toThrow(/manifest of .* was modified after install/);
+  });
+
+  it('stops the skill when aborted', async () => {
+    const typePath = skill('watch', 'mode: daemon\n', 'console.log("up");\nsetInterval(() => {}, 1000);\n');
+    const stop = new AbortController();
+    const running = superviseDaemon(typePath, installedRoot, {}, { signal: stop.signal });
+    await new Promise((resolve) => setTimeout(resolve, 300));
+    expect(daemonStatus(typePath).running).toBe(true);
+
+    stop.abort();
+    const state = await running;
+    expect(state.restarts).toBe(0);
+    expect(state.lastExit?.signal).toBe('SIGTERM');
+    expect(daemonStatus(typePath).running).toBe(false);
+  });
+
+  it.skipIf(process.platform === 'win32')('restarts on a reinstall and stops on an uninstall', async () => {
+    const typePath = skill('reload', 'mode: daemon\n', 'console.log("up");\nsetInterval(() => {}, 1000);\n');
+    const running = superviseDaemon(typePath, installedRoot, {});
+    const tick = () => new Promise((resolve) => setTimeout(resolve, 300));
+    const send = (kind: string, seq: number) =>
+      new Promise<void>((resolve, reject) => {
+        const conn = createConnection(join(root, 'notify', `${process.pid}.sock`), () => {
+          conn.end(JSON.stringify({ seq, kind, typePaths: [typePath], pid: process.pid + 1, at: 'now' }) + '\n');
+        });
+        conn.on('close', () => resolve());
+        conn.on('error', reject);
+      });
+    await tick();
+
+    await send('update', 1);
+    await tick();
+    expect(daemonStatus(typePath).running).toBe(true);
+
+    await send('uninstall', 2);
+    const state = await running;
+    expect(state.restarts).toBe(0);
+    const log = readFileSync(daemonLogPath(typePath), 'utf-8');
+    expect(log.match(/agentx: started/g)).toHaveLength(2);
+    expect(log).toContain('changed; starting the installed version');
+    expect(log).toContain('was uninstalled; stopping');
+  });
+});
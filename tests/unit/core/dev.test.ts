@@ -0,0 +1,76 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { resolveDev, prepareDevSkills } from '../../../src/core/dev.js';
+import { compose } from '../../../src/core/compose.js';
+import type { Source } from '../../../src/types/registry.js';
+
+function makeType(dir: string, manifest: string, files: Record<string, string> = {}): void {
+  mkdirSync(dir, { recursive: true });
+  writeFileSync(join(dir, 'manifest.yaml'), manifest);
+  for (const [name, content] of Object.entries(files)) writeFileSync(join(dir, name), content);
+}
+
+describe('dev mode', () => {
+  let root: string;
+  let catalogDir: string;
+  let installedRoot: string;
+  let sources: Source[];
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-dev-test-${Date.now()}`);
+    catalogDir = join(root, 'catalog');
+    installedRoot = join(root, 'installed');
+    mkdirSync(installedRoot, { recursive: true });
+    sources = [{ name: 'catalog', basePath: catalogDir }];
+
+    makeType(
+      join(catalogDir, 'context/style'),
+      'name: style\ntype: context\nversion: "1.0.0"\ndescription: d\naliases: [context/old-style]\nsources: [style.md]\n',
+      { 'style.md': 'Prefer small functions.' },
+    );
+    makeType(join(catalogDir, 'personas/reviewer'), 'name: reviewer\ntype: persona\nversion: "1.0.0"\ndescription: d\ncontext: [context/old-style]\n');
+    makeType(
+      join(catalogDir, 'skills/scm/lint'),
+      'name: lint\ntype: skill\nversion: "1.0.0"\ndescription: d\nruntime: node\ntopic: scm\nregistry:\n  output: true\n',
+    );
+    makeType(
+      join(catalogDir, 'prompts/review'),
+      'name: review\ntype: prompt\nversion: "1.0.0"\ndescription: d\npersona: personas/reviewer\ncontext: [context/style]\nskills: [skills/scm/lint]\n',
+    );
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('maps a type and everything it references to its source directory', () => {
+    const dev = resolveDev('prompts/review', sources, installedRoot);
+    expect(dev.dirs['prompts/review']).toBe(join(catalogDir, 'prompts/review'));
+    expect(dev.dirs['personas/reviewer']).toBe(join(catalogDir, 'personas/reviewer'));
+    expect(dev.dirs['skills/scm/lint']).toBe(join(catalogDir, 'skills/scm/lint'));
+    // Referenced by its former path from the persona
+    expect(dev.dirs['context/old-style']).toBe(join(catalogDir, 'context/style'));
+  });
+
+  it('composes a prompt that was never installed', () => {
+    const dev = resolveDev('prompts/review', sources, installedRoot);
+    const composed = compose('prompts/review', installedRoot, dev.dirs);
+    expect(composed.context.map((c) => c.content).join('\n')).toContain('Prefer small functions.');
+
+    writeFileSync(join(catalogDir, 'context/style/style.md'), 'Prefer pure functions.');
+    const again = compose('prompts/review', installedRoot, resolveDev('prompts/review', sources, installedRoot).dirs);
+    expect(again.context.map((c) => c.content).join('\n')).toContain('Prefer pure functions.');
+  });
+
+  it('creates the registry of a skill that was never installed', () => {
+    const skillsDir = join(root, 'skills');
+    prepareDevSkills(resolveDev('prompts/review', sources, installedRoot), skillsDir);
+    expect(existsSync(join(skillsDir, 'scm/lint'))).toBe(true);
+  });
+
+  it('fails for a type in no source', () => {
+    expect(() => resolveDev('prompts/reveiw', sources, installedRoot)).toThrow(/not found in any source.*\n.*prompts\/review/s);
+  });
+});
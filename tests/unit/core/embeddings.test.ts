@@ -0,0 +1,84 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  chunkText,
+  localEmbedder,
+  searchSemantic,
+  updateEmbeddingIndex,
+  type Embedder,
+} from '../../../src/core/embeddings.js';
+
+describe('embeddings', () => {
+  let root: string;
+  let installedRoot: string;
+
+  function context(typePath: string, content: string): void {
+    const dir = join(installedRoot, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      `name: ${typePath.split('/').pop()}\ntype: context\nversion: "1.0.0"\ndescription: d\nformat: markdown\nsources:\n  - doc.md\n`,
+    );
+    writeFileSync(join(dir, 'doc.md'), content);
+  }
+
+  // Counts texts passed through, to observe incremental updates
+  function counting(inner: Embedder): Embedder & { calls: number } {
+    const e = {
+      id: inner.id,
+      calls: 0,
+      embed: async (texts: string[]) => {
+        e.calls += texts.length;
+        return inner.embed(texts);
+      },
+    };
+    return e;
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-embeddings-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+
+    context('context/security/auth', 'Authentication errors return 401 with a retry hint.\n\nRefresh expired tokens before retrying auth requests.');
+    context('context/frontend/styling', 'Use CSS modules for component styling.\n\nPrefer design tokens over raw colors.');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('packs paragraphs into chunks', () => {
+    expect(chunkText('a\n\nb\n\n\n')).toEqual(['a\n\nb']);
+    const long = 'x'.repeat(1000);
+    expect(chunkText(`${long}\n\n${long}`)).toHaveLength(2);
+  });
+
+  it('produces unit vectors', async () => {
+    const [v] = await localEmbedder().embed(['handle auth errors']);
+    expect(Math.hypot(...v)).toBeCloseTo(1, 5);
+  });
+
+  it('ranks the most related context type first', async () => {
+    const hits = await searchSemantic('how do we handle auth errors', { installedRoot });
+    expect(hits[0].typePath).toBe('context/security/auth');
+    expect(hits[0].score).toBeGreaterThan(0);
+  });
+
+  it('only embeds changed chunks on update', async () => {
+    const embedder = counting(localEmbedder());
+    await updateEmbeddingIndex(embedder, { installedRoot });
+    expect(embedder.calls).toBe(2);
+
+    embedder.calls = 0;
+    await updateEmbeddingIndex(embedder, { installedRoot });
+    expect(embedder.calls).toBe(0);
+
+    context('context/frontend/styling', 'Use Tailwind for component styling.');
+    await updateEmbeddingIndex(embedder, { installedRoot });
+    expect(embedder.calls).toBe(1);
+  });
+});
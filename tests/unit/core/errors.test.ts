@@ -0,0 +1,32 @@
+import { describe, it, expect } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { AgentxError, ERROR_CODES, errorCode } from '../../../src/core/errors.js';
+import { parsePin, loadProject } from '../../../src/core/linker.js';
+
+describe('error codes', () => {
+  it('uses the AGX-<area>-<number> form with a unique slug each', () => {
+    for (const code of Object.keys(ERROR_CODES)) expect(code).toMatch(/^AGX-(REG|LNK|EXT|RUN)-\d{3}$/);
+    const slugs = Object.values(ERROR_CODES);
+    expect(new Set(slugs).size).toBe(slugs.length);
+  });
+
+  it('carries its code through String() and errorCode', () => {
+    const err = new AgentxError('AGX-REG-004', 'Type not found: skills/x');
+    expect(String(err)).toBe('AGX-REG-004: Type not found: skills/x');
+    expect(err.slug).toBe('type-not-found');
+    expect(errorCode(err)).toBe('AGX-REG-004');
+    expect(errorCode(new Error('plain'))).toBeNull();
+  });
+
+  it('is thrown by core modules', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'agentx-errors-'));
+    try {
+      expect(() => parsePin('skills/x@')).toThrow(expect.objectContaining({ code: 'AGX-REG-002' }));
+      expect(() => loadProject(dir)).toThrow(expect.objectContaining({ code: 'AGX-LNK-003' }));
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
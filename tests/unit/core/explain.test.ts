@@ -0,0 +1,35 @@
+import { describe, it, expect } from 'vitest';
+import { explain, explanations, suggestExplanations } from '../../../src/core/explain.js';
+import { ERROR_CODES } from '../../../src/core/errors.js';
+
+describe('explain', () => {
+  it('explains every error code with at least one fix', () => {
+    for (const code of Object.keys(ERROR_CODES)) {
+      const e = explain(code);
+      expect(e?.id).toBe(code);
+      expect(e?.fixes.length).toBeGreaterThan(0);
+    }
+  });
+
+  it('finds codes case-insensitively and by slug', () => {
+    expect(explain('agx-reg-004')?.title).toBe('Type not found');
+    expect(explain('type-not-found')?.id).toBe('AGX-REG-004');
+  });
+
+  it('explains topics and their aliases', () => {
+    expect(explain('skill registry')?.id).toBe('skill-registry');
+    expect(explain('Profiles')?.id).toBe('profile');
+    expect(explain('nope')).toBeNull();
+  });
+
+  it('lists codes before topics', () => {
+    const ids = explanations().map((e) => e.id);
+    expect(ids[0]).toMatch(/^AGX-/);
+    expect(ids).toContain('linking');
+  });
+
+  it('suggests close matches', () => {
+    expect(suggestExplanations('AGX-REG-04')).toContain('AGX-REG-004');
+    expect(suggestExplanations('linkng')).toContain('linking');
+  });
+});
@@ -0,0 +1,54 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, writeFileSync, mkdirSync, statSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createExtension } from '../../../src/core/extension-scaffold.js';
+import { loadExtensionManifest } from '../../../src/core/extension.js';
+import { validateManifestFile } from '../../../src/core/manifest.js';
+import { discoverTypes } from '../../../src/core/registry.js';
+
+describe('extension create', () => {
+  let outDir: string;
+
+  beforeEach(() => {
+    outDir = join(tmpdir(), `agentx-ext-create-test-${Date.now()}`);
+  });
+
+  afterEach(() => {
+    rmSync(outDir, { recursive: true, force: true });
+  });
+
+  it('lays out an extension whose sample types validate and resolve', async () => {
+    const result = await createExtension('acme-corp', outDir, { description: 'Acme "internal" types' });
+
+    expect(result.pushed).toBe(false);
+    expect(result.warnings).toEqual([]);
+    expect(result.files).toEqual(
+      expect.arrayContaining([
+        'extension.yaml',
+        'README.md',
+        'scripts/lint-manifests.sh',
+        '.github/workflows/lint.yml',
+        'context/acme-corp/getting-started/context.yaml',
+        'personas/acme-corp-developer/persona.yaml',
+      ]),
+    );
+    expect(loadExtensionManifest(outDir)).toMatchObject({ name: 'acme-corp', description: 'Acme "internal" types' });
+    expect(statSync(join(outDir, 'scripts/lint-manifests.sh')).mode & 0o111).not.toBe(0);
+
+    const persona = join(outDir, 'personas/acme-corp-developer/persona.yaml');
+    expect(readFileSync(persona, 'utf-8')).toContain('context:\n  - context/acme-corp/getting-started');
+    expect(validateManifestFile(persona, { strict: true })).toEqual([]);
+    expect(validateManifestFile(join(outDir, 'context/acme-corp/getting-started/context.yaml'), { strict: true })).toEqual([]);
+
+    const types = discoverTypes([{ name: 'acme-corp', basePath: outDir }]).map((t) => t.typePath);
+    expect(types.sort()).toEqual(['context/acme-corp/getting-started', 'personas/acme-corp-developer']);
+  });
+
+  it('refuses invalid names and non-empty directories', async () => {
+    await expect(createExtension('Acme', outDir)).rejects.toThrow('Invalid extension name "Acme"');
+    mkdirSync(outDir, { recursive: true });
+    writeFileSync(join(outDir, 'notes.txt'), 'x');
+    await expect(createExtension('acme', outDir)).rejects.toThrow('Output directory is not empty');
+  });
+});
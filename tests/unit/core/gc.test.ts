@@ -0,0 +1,112 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync, utimesSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { planGc, applyGc } from '../../../src/core/gc.js';
+import { recordProject, projectsRegistryPath } from '../../../src/core/projects.js';
+import { initProject, loadProject, saveProject } from '../../../src/core/linker.js';
+import { getCacheDir, getSkillsDir } from '../../../src/core/userdata.js';
+
+describe('gc', () => {
+  let root: string;
+  let installedRoot: string;
+  let project: string;
+  const old = new Date(Date.now() - 90 * 86_400_000);
+
+  function install(typePath: string, manifest: string): void {
+    const dir = join(installedRoot, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), manifest);
+  }
+
+  function write(path: string, content: string, mtime?: Date): void {
+    mkdirSync(join(path, '..'), { recursive: true });
+    writeFileSync(path, content);
+    if (mtime) utimesSync(path, mtime, mtime);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-gc-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+
+    install('prompts/review', 'name: review\ntype: prompt\nversion: "1.0.0"\ncontext:\n  - context/lang/typescript\n');
+    install('context/lang/typescript', 'name: typescript\ntype: context\nversion: "1.0.0"\n');
+    install('skills/scm/git/commit-analyzer', 'name: commit-analyzer\ntype: skill\nversion: "1.0.0"\n');
+
+    project = join(root, 'project');
+    initProject(project, []);
+    saveProject(project, { ...loadProject(project), active: { prompts: ['prompts/review'] } });
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('leaves installed types alone until a project is known', () => {
+    const plan = planGc(installedRoot);
+    expect(plan.typesSkipped).toMatch(/no known projects/);
+    expect(plan.items.filter((i) => i.kind === 'type')).toEqual([]);
+  });
+
+  it('collects types no known project uses only with force, and never registries', () => {
+    recordProject(project, []);
+    write(join(getSkillsDir(), 'scm', 'git', 'commit-analyzer', 'tokens.env'), 'TOKEN=x\n');
+    write(join(getSkillsDir(), 'scm', 'gone', 'config.yaml'), 'a: 1\n');
+
+    const plan = planGc(installedRoot);
+    expect(plan.projects).toBe(1);
+    expect(plan.items.map((i) => [i.kind, i.target, i.force])).toEqual([['type', 'skills/scm/git/commit-analyzer', true]]);
+
+    // An unrecorded project may still use it
+    const kept = applyGc(plan, installedRoot);
+    expect(kept.kept.map((i) => i.target)).toEqual(['skills/scm/git/commit-analyzer']);
+    expect(existsSync(join(installedRoot, 'skills/scm/git/commit-analyzer'))).toBe(true);
+
+    const result = applyGc(plan, installedRoot, { force: true });
+    expect(result.failed).toEqual([]);
+    expect(result.bytes).toBe(plan.bytes);
+    expect(existsSync(join(installedRoot, 'skills/scm/git/commit-analyzer'))).toBe(false);
+    expect(existsSync(join(installedRoot, 'context/lang/typescript'))).toBe(true);
+    expect(existsSync(join(getSkillsDir(), 'scm', 'git', 'commit-analyzer', 'tokens.env'))).toBe(true);
+    expect(existsSync(join(getSkillsDir(), 'scm', 'gone'))).toBe(true);
+  });
+
+  it('plans without rewriting the projects registry or forgetting unreachable projects', () => {
+    recordProject(project, []);
+    recordProject(join(root, 'unmounted'), []);
+    const before = readFileSync(projectsRegistryPath(), 'utf-8');
+
+    const plan = planGc(installedRoot);
+    expect(plan.projects).toBe(1);
+    expect(plan.unreachable).toEqual([join(root, 'unmounted')]);
+    expect(readFileSync(projectsRegistryPath(), 'utf-8')).toBe(before);
+  });
+
+  it('collects stale run caches and old output history', () => {
+    saveProject(project, { ...loadProject(project), active: { skills: ['skills/scm/git/commit-analyzer'] } });
+    recordProject(project, []);
+    const history = join(getSkillsDir(), 'scm', 'git', 'commit-analyzer', 'output', 'history');
+    write(join(history, 'a.json'), '{}', old);
+    write(join(history, 'b.json'), '{}');
+    write(join(getCacheDir(), 'runs', 'abc', 'stats.json'), '{}', old);
+    utimesSync(join(getCacheDir(), 'runs', 'abc'), old, old);
+    write(join(getCacheDir(), 'runs', 'def', 'stats.json'), '{}');
+
+    const plan = planGc(installedRoot);
+    expect(plan.items.map((i) => `${i.kind} ${i.target}`).sort()).toEqual([
+      'output-history skills/scm/git/commit-analyzer',
+      'run-cache cache/runs/abc',
+      'type context/lang/typescript',
+      'type prompts/review',
+    ]);
+    expect(plan.items.find((i) => i.kind === 'output-history')?.paths).toEqual([join(history, 'a.json')]);
+
+    applyGc(plan, installedRoot);
+    expect(existsSync(join(history, 'a.json'))).toBe(false);
+    expect(existsSync(join(history, 'b.json'))).toBe(true);
+    expect(existsSync(join(installedRoot, 'prompts/review'))).toBe(true);
+    expect(existsSync(join(getCacheDir(), 'runs', 'def'))).toBe(true);
+  });
+});
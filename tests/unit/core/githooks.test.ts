@@ -0,0 +1,82 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync, statSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import yaml from 'js-yaml';
+import { installGitHooks, uninstallGitHooks, detectHookManager, gitHookCommand } from '../../../src/core/githooks.js';
+
+type Lefthook = Record<string, { commands: Record<string, { run: string }> }>;
+
+describe('githooks', () => {
+  let root: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-githooks-test-${Date.now()}`);
+    mkdirSync(root, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('adds a block to existing husky hooks and replaces it on reinstall', () => {
+    mkdirSync(join(root, '.husky'));
+    writeFileSync(join(root, '.husky', 'pre-commit'), 'npx lint-staged\n');
+    expect(detectHookManager(root)).toBe('husky');
+
+    installGitHooks(root);
+    const { paths } = installGitHooks(root, { sync: true });
+    expect(paths).toEqual([join(root, '.husky', 'pre-commit'), join(root, '.husky', 'post-merge')]);
+
+    const preCommit = readFileSync(paths[0], 'utf-8');
+    expect(preCommit.startsWith('npx lint-staged\n')).toBe(true);
+    expect(preCommit.match(/>>> agentx links >>>/g)).toHaveLength(1);
+    expect(preCommit).toContain('agentx link status --quiet ||');
+    expect(readFileSync(paths[1], 'utf-8')).toContain(`  ${gitHookCommand('post-merge', { sync: true })}\n`);
+    expect(statSync(paths[1]).mode & 0o111).not.toBe(0);
+
+    expect(uninstallGitHooks(root)).toEqual(paths);
+    expect(readFileSync(paths[0], 'utf-8')).toBe('npx lint-staged\n');
+    expect(existsSync(paths[1])).toBe(false);
+  });
+
+  it('writes hooks to the git hooks directory without a hook manager', () => {
+    mkdirSync(join(root, '.git'));
+    expect(detectHookManager(root)).toBe('git');
+
+    const { manager, paths } = installGitHooks(root);
+    expect(manager).toBe('git');
+    expect(paths).toEqual([join(root, '.git', 'hooks', 'pre-commit'), join(root, '.git', 'hooks', 'post-merge')]);
+    expect(readFileSync(paths[0], 'utf-8')).toMatch(/^#!\/bin\/sh\n\n# >>> agentx links >>>/);
+    expect(statSync(paths[0]).mode & 0o111).not.toBe(0);
+
+    expect(uninstallGitHooks(root)).toEqual(paths);
+    expect(paths.some((p) => existsSync(p))).toBe(false);
+  });
+
+  it('adds lefthook commands next to existing ones', () => {
+    const original = '# Run before every commit\npre-commit:\n  commands:\n    lint:\n      run: npm run lint\n';
+    writeFileSync(join(root, 'lefthook.yml'), original);
+    expect(installGitHooks(root).manager).toBe('lefthook');
+
+    const config = yaml.load(readFileSync(join(root, 'lefthook.yml'), 'utf-8')) as Lefthook;
+    expect(Object.keys(config['pre-commit'].commands)).toEqual(['lint', 'agentx-links']);
+    expect(config['post-merge'].commands['agentx-links'].run).toBe(gitHookCommand('post-merge'));
+
+    uninstallGitHooks(root);
+    expect(readFileSync(join(root, 'lefthook.yml'), 'utf-8')).toBe(original);
+  });
+
+  it('removes the lefthook entries it created, not just the command', () => {
+    writeFileSync(join(root, 'lefthook.yml'), 'pre-commit:\n  parallel: true\n');
+    installGitHooks(root);
+    uninstallGitHooks(root);
+    expect(yaml.load(readFileSync(join(root, 'lefthook.yml'), 'utf-8'))).toEqual({ 'pre-commit': { parallel: true } });
+  });
+
+  it('refuses to edit a hook that is not a shell script', () => {
+    mkdirSync(join(root, '.husky'));
+    writeFileSync(join(root, '.husky', 'pre-commit'), '#!/usr/bin/env python3\nprint("hi")\n');
+    expect(() => installGitHooks(root)).toThrow(/not a shell script/);
+  });
+});
@@ -0,0 +1,59 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, statSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createHash } from 'node:crypto';
+import { buildGraph, graphSalt, graphSaltPath } from '../../../src/core/graph.js';
+import { initProject } from '../../../src/core/linker.js';
+
+describe('graph', () => {
+  let root: string;
+  let workspace: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-graph-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    workspace = join(root, 'workspace');
+    mkdirSync(join(workspace, 'api'), { recursive: true });
+    initProject(join(workspace, 'api'), []);
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  const projectIds = (salt?: string) =>
+    buildGraph(workspace, [join(workspace, 'api')], join(root, 'installed'), { salt }).nodes.map((n) => n.id);
+
+  it('creates a private random salt once and reuses it', () => {
+    const salt = graphSalt();
+    expect(salt).toMatch(/^[0-9a-f]{64}$/);
+    expect(graphSalt()).toBe(salt);
+    expect(readFileSync(graphSaltPath(), 'utf-8').trim()).toBe(salt);
+    if (process.platform !== 'win32') expect(statSync(graphSaltPath()).mode & 0o777).toBe(0o600);
+  });
+
+  it('salts project hashes with the install salt by default', () => {
+    // What an unsalted hash of the project's identity would be
+    const unsalted = createHash('sha256').update('\0api').digest('hex').slice(0, 12);
+    const ids = projectIds();
+    expect(ids).toHaveLength(1);
+    expect(ids).not.toContain(unsalted);
+    expect(projectIds()).toEqual(ids);
+    expect(projectIds(graphSalt())).toEqual(ids);
+  });
+
+  it('differs between installs unless the salt is shared', () => {
+    const first = projectIds();
+    rmSync(graphSaltPath());
+    expect(projectIds()).not.toEqual(first);
+    expect(projectIds('team-secret')).toEqual(projectIds('team-secret'));
+  });
+
+  it('regenerates a salt file left empty', () => {
+    mkdirSync(join(root, 'home'), { recursive: true });
+    writeFileSync(graphSaltPath(), '');
+    expect(graphSalt()).toMatch(/^[0-9a-f]{64}$/);
+  });
+});
@@ -0,0 +1,96 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { assessProject, gradeFor, badgeJson, badgeSvg, type HealthReport } from '../../../src/core/health.js';
+import { initProject, loadProject, saveProject } from '../../../src/core/linker.js';
+
+describe('project health', () => {
+  let root: string;
+  let projectDir: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-health-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    projectDir = join(root, 'project');
+    mkdirSync(projectDir, { recursive: true });
+    initProject(projectDir, []);
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  function installSkill(typePath: string, manifest: string): void {
+    const dir = join(root, 'home', 'installed', typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), manifest);
+  }
+
+  function link(...refs: string[]): void {
+    const config = loadProject(projectDir);
+    for (const ref of refs) {
+      const section = ref.split('/')[0] as keyof typeof config.active;
+      config.active[section] = [...(config.active[section] ?? []), ref];
+    }
+    saveProject(projectDir, config);
+  }
+
+  it('grades scores on the usual letter scale', () => {
+    expect([100, 90, 89, 80, 79, 70, 60, 59, 0].map(gradeFor)).toEqual(['A', 'A', 'B', 'B', 'C', 'C', 'D', 'F', 'F']);
+  });
+
+  it('scores token completeness, counting defaults as set', async () => {
+    installSkill(
+      'skills/scm/git/commit-analyzer',
+      [
+        'name: commit-analyzer',
+        'registry:',
+        '  tokens:',
+        '    - { name: GIT_TOKEN, required: true }',
+        '    - { name: GIT_HOST, required: true, default: github.com }',
+        '    - { name: GIT_ORG, required: true }',
+        '    - { name: GIT_TEAM }',
+        '',
+      ].join('\n'),
+    );
+    const registry = join(root, 'home', 'userdata', 'skills', 'scm', 'git', 'commit-analyzer');
+    mkdirSync(registry, { recursive: true });
+    writeFileSync(join(registry, 'tokens.env'), 'GIT_TOKEN=abc\n');
+    link('skills/scm/git/commit-analyzer');
+
+    const tokens = (await assessProject(projectDir)).checks.find((c) => c.name === 'tokens')!;
+    expect(tokens.score).toBe(67);
+    expect(tokens.details).toEqual(['skills/scm/git/commit-analyzer: GIT_ORG not set']);
+  });
+
+  it('flags linked types that are not installed and weighs every check', async () => {
+    installSkill('skills/a', 'name: a\n');
+    link('skills/a', 'context/missing');
+
+    const report = await assessProject(projectDir);
+    const byName = Object.fromEntries(report.checks.map((c) => [c.name, c]));
+    expect(byName.policy).toMatchObject({ score: 50, details: ['Linked but not installed: context/missing'] });
+    expect(byName.links).toMatchObject({ score: 0, details: ['No tools configured'] });
+    expect(byName.catalog).toMatchObject({ score: 0, details: ['Catalog not installed'] });
+    expect(byName.tokens.score).toBe(100);
+    // (0×3 + 0×1 + 100×2 + 50×2) / 8
+    expect(report).toMatchObject({ score: 38, grade: 'F' });
+  });
+
+  it('renders the score as a shields.io endpoint and an SVG badge', () => {
+    const report: HealthReport = { score: 92, grade: 'A', checks: [] };
+    expect(JSON.parse(badgeJson(report))).toEqual({
+      schemaVersion: 1,
+      label: 'agentx health',
+      message: '92% (A)',
+      color: '4c1',
+    });
+
+    const svg = badgeSvg({ ...report, score: 55, grade: 'F' });
+    expect(svg).toMatch(/^<svg /);
+    expect(svg).toContain('aria-label="agentx health: 55% (F)"');
+    expect(svg).toContain('fill="#e05d44"');
+  });
+});
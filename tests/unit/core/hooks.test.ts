@@ -0,0 +1,82 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import * as settings from '../../../src/config/settings.js';
+import { listHooks, runHooks } from '../../../src/core/hooks.js';
+import { initProject, loadProject, saveProject } from '../../../src/core/linker.js';
+
+describe('lifecycle hooks', () => {
+  let root: string;
+  let project: string;
+  let log: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-hooks-test-${Date.now()}`);
+    project = join(root, 'project');
+    log = join(root, 'hooks.log');
+    mkdirSync(join(root, 'home'), { recursive: true });
+    process.env.AGENTX_HOME = join(root, 'home');
+    const config = join(root, 'home', 'config.yaml');
+    writeFileSync(
+      config,
+      `hooks.post-install:\n  - echo "user $AGENTX_HOOK_EVENT $AGENTX_HOOK_STATUS $AGENTX_TYPE_PATH" >> ${log}\n` +
+        `hooks.pre-run: exit 3\n`,
+    );
+    settings.init(config);
+    initProject(project, ['claude-code']);
+  });
+
+  afterEach(() => {
+    settings.init('');
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  function declare(hooks: Record<string, string[]>): void {
+    saveProject(project, { ...loadProject(project), hooks });
+  }
+
+  it('runs user hooks with the event environment', async () => {
+    const result = await runHooks('post-install', {
+      typePaths: ['skills/scm/git/commit', 'context/git/conventions'],
+      status: 'failed',
+    });
+    expect(result).toMatchObject({ ran: [{ scope: 'user', exitCode: 0 }], warnings: [] });
+    expect(readFileSync(log, 'utf-8')).toBe('user post-install failed skills/scm/git/commit\n');
+  });
+
+  it('fails pre-* events and only warns for post-* events', async () => {
+    await expect(runHooks('pre-run', { typePaths: ['skills/x'] })).rejects.toThrow(/pre-run hook `exit 3` failed \(exit 3\)/);
+
+    settings.init('');
+    declare({ 'post-run': ['exit 1'] });
+    const result = await runHooks('post-run', { projectPath: project, exitCode: 2 }, { approve: async () => true });
+    expect(result.warnings).toEqual(['post-run hook `exit 1` failed (exit 1)']);
+  });
+
+  it('runs project hooks from the project root once approved', async () => {
+    declare({ 'post-link-sync': ['pwd > synced.txt'] });
+    expect(listHooks('post-link-sync', project)).toEqual([
+      { event: 'post-link-sync', scope: 'project', command: 'pwd > synced.txt', cwd: project },
+    ]);
+
+    const skipped = await runHooks('post-link-sync', { projectPath: project });
+    expect(skipped.ran).toEqual([]);
+    expect(skipped.warnings[0]).toMatch(/not approved yet/);
+
+    let asked = 0;
+    const approve = async () => {
+      asked++;
+      return true;
+    };
+    await runHooks('post-link-sync', { projectPath: project }, { approve });
+    await runHooks('post-link-sync', { projectPath: project }, { approve });
+    expect(asked).toBe(1);
+    expect(existsSync(join(project, 'synced.txt'))).toBe(true);
+
+    // A changed command needs approval again
+    declare({ 'post-link-sync': ['pwd > other.txt'] });
+    expect((await runHooks('post-link-sync', { projectPath: project })).ran).toEqual([]);
+  });
+});
@@ -0,0 +1,116 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { inspectType } from '../../../src/core/info.js';
+import { initProject, loadProject, saveProject } from '../../../src/core/linker.js';
+import { getSkillRegistryPath, getEnvDir } from '../../../src/core/userdata.js';
+
+describe('inspectType', () => {
+  let root: string;
+  let catalogDir: string;
+  let installedRoot: string;
+  const skill = 'skills/scm/git/commit-analyzer';
+
+  function write(path: string, content: string): void {
+    mkdirSync(join(path, '..'), { recursive: true });
+    writeFileSync(path, content);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-info-test-${Date.now()}`);
+    catalogDir = join(root, 'catalog');
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+
+    write(
+      join(catalogDir, skill, 'manifest.yaml'),
+      `name: commit-analyzer
+type: skill
+version: "1.0.0"
+description: Analyze commits
+runtime: node
+topic: scm
+registry:
+  tokens:
+    - name: GIT_TOKEN
+      required: true
+    - name: GIT_HOST
+  config:
+    days: 30
+`,
+    );
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  const inspect = (projectsRoot?: string) =>
+    inspectType(skill, {
+      installedRoot,
+      sources: [{ name: 'catalog', basePath: catalogDir }],
+      projectsRoot,
+    });
+
+  it('describes a type available in a source', () => {
+    const info = inspect();
+    expect(info).toMatchObject({ installed: false, source: 'catalog', category: 'skill' });
+    expect(info.manifest.version).toBe('1.0.0');
+    expect(info.config).toEqual([{ key: 'days', default: 30, value: 30 }]);
+  });
+
+  it('reports token status from the skill registry', () => {
+    const registry = getSkillRegistryPath('scm/git/commit-analyzer');
+    write(join(registry, 'tokens.env'), 'GIT_TOKEN=abc\nGIT_HOST=\n');
+    write(join(registry, 'config.yaml'), 'days: 7\n');
+
+    const info = inspect();
+    expect(info.tokens.map((t) => [t.name, t.set])).toEqual([
+      ['GIT_TOKEN', true],
+      ['GIT_HOST', false],
+    ]);
+    expect(info.config[0].value).toBe(7);
+  });
+
+  it('names the layer a token comes from in runtime precedence order', () => {
+    const registry = getSkillRegistryPath('scm/git/commit-analyzer');
+    write(join(registry, 'tokens.env'), 'GIT_TOKEN=abc\n');
+    write(join(getEnvDir(), 'default.env'), 'GIT_TOKEN=shared\nGIT_HOST=git.example.com\n');
+    process.env.GIT_TOKEN = 'from-env';
+    process.env.GIT_HOST = 'env.example.com';
+    try {
+      // tokens.env beats the environment, which beats default.env
+      expect(inspect().tokens.map((t) => [t.name, t.from])).toEqual([
+        ['GIT_TOKEN', join(registry, 'tokens.env')],
+        ['GIT_HOST', 'environment'],
+      ]);
+      delete process.env.GIT_HOST;
+      expect(inspect().tokens[1].from).toBe(join(getEnvDir(), 'default.env'));
+    } finally {
+      delete process.env.GIT_TOKEN;
+      delete process.env.GIT_HOST;
+    }
+  });
+
+  it('lists projects that link the type', () => {
+    const projects = join(root, 'projects');
+    for (const name of ['uses-it', 'does-not']) {
+      const dir = join(projects, name);
+      mkdirSync(dir, { recursive: true });
+      initProject(dir, []);
+    }
+    const using = join(projects, 'uses-it');
+    const config = loadProject(using);
+    saveProject(using, { ...config, active: { ...config.active, skills: [skill] } });
+
+    expect(inspect(projects).linkedBy).toEqual([using]);
+  });
+
+  it('fails for unknown types', () => {
+    expect(() =>
+      inspectType('skills/nope', { installedRoot, sources: [] }),
+    ).toThrow('Type not found');
+  });
+});
@@ -0,0 +1,80 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { listInstalled } from '../../../src/core/installed.js';
+import { installType } from '../../../src/core/registry.js';
+import type { DiscoveredType } from '../../../src/types/registry.js';
+
+describe('listInstalled', () => {
+  let root: string;
+  let catalogDir: string;
+  let installedRoot: string;
+
+  function install(typePath: string, manifest: string): void {
+    const dir = join(catalogDir, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), manifest);
+    installType(
+      {
+        typePath,
+        manifestPath: join(dir, 'manifest.yaml'),
+        sourceDir: dir,
+        sourceName: 'acme',
+        category: typePath.startsWith('skills') ? 'skill' : 'persona',
+      },
+      installedRoot,
+    );
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-installed-test-${Date.now()}`);
+    catalogDir = join(root, 'catalog');
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+
+    install('skills/scm/git/commit-analyzer', 'name: commit-analyzer\ntype: skill\nversion: "1.0.0"\ndescription: d\n');
+    install('personas/reviewer', 'name: reviewer\ntype: persona\nversion: "2.0.0"\ndescription: d\n');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('reports source, install date, and topic', () => {
+    const skill = listInstalled(installedRoot).find((t) => t.category === 'skill');
+    expect(skill).toMatchObject({ version: '1.0.0', source: 'acme', topic: 'scm' });
+    expect(skill?.installedAt).toMatch(/^\d{4}-\d{2}-\d{2}T/);
+  });
+
+  it('filters by type and topic', () => {
+    expect(listInstalled(installedRoot, { type: 'persona' }).map((t) => t.typePath)).toEqual([
+      'personas/reviewer',
+    ]);
+    expect(listInstalled(installedRoot, { topic: 'scm' })).toHaveLength(1);
+    expect(listInstalled(installedRoot, { topic: 'cloud' })).toHaveLength(0);
+  });
+
+  it('flags types with a newer available version', () => {
+    const available = [
+      { typePath: 'skills/scm/git/commit-analyzer', version: '1.2.0' },
+      { typePath: 'personas/reviewer', version: '1.9.0' },
+    ] as DiscoveredType[];
+
+    const latest = Object.fromEntries(
+      listInstalled(installedRoot, {}, available).map((t) => [t.typePath, t.latest]),
+    );
+    expect(latest).toEqual({ 'skills/scm/git/commit-analyzer': '1.2.0', 'personas/reviewer': null });
+  });
+
+  it('takes the highest version when several sources offer a type', () => {
+    const available = [
+      { typePath: 'skills/scm/git/commit-analyzer', version: '1.10.0' },
+      { typePath: 'skills/scm/git/commit-analyzer', version: '1.2.0' },
+    ] as DiscoveredType[];
+
+    const analyzer = listInstalled(installedRoot, {}, available).find((t) => t.typePath === 'skills/scm/git/commit-analyzer');
+    expect(analyzer?.latest).toBe('1.10.0');
+  });
+});
@@ -0,0 +1,69 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { storeType, materialize } from '../../../src/core/store.js';
+import { verifyType, acceptType } from '../../../src/core/integrity.js';
+
+describe('integrity', () => {
+  let root: string;
+  let installedRoot: string;
+  const typePath = 'skills/test/sample';
+
+  // Installed files are read-only hardlinks; editors replace them instead
+  function replace(rel: string, content: string): void {
+    const path = join(installedRoot, typePath, rel);
+    rmSync(path, { force: true });
+    writeFileSync(path, content);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-integrity-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+
+    const src = join(root, 'src');
+    mkdirSync(src, { recursive: true });
+    writeFileSync(join(src, 'manifest.yaml'), 'name: sample\ntype: skill\nversion: 1.0.0\n');
+    writeFileSync(join(src, 'index.mjs'), 'export default 1;\n');
+    materialize(storeType(typePath, src, '1.0.0', 'catalog'), installedRoot);
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('reports a freshly installed type as unchanged', () => {
+    expect(verifyType(typePath, installedRoot)).toEqual({
+      typePath,
+      version: '1.0.0',
+      changes: [],
+      manifestChanged: false,
+    });
+  });
+
+  it('flags manifest edits separately from other changes', () => {
+    replace('index.mjs', 'export default 2;\n');
+    writeFileSync(join(installedRoot, typePath, 'extra.md'), 'notes');
+    let report = verifyType(typePath, installedRoot);
+    expect(report.changes).toEqual([
+      { path: 'extra.md', kind: 'added' },
+      { path: 'index.mjs', kind: 'modified' },
+    ]);
+    expect(report.manifestChanged).toBe(false);
+
+    replace('manifest.yaml', 'name: sample\ntype: skill\nversion: 1.0.0\nruntime: bash\n');
+    report = verifyType(typePath, installedRoot);
+    expect(report.manifestChanged).toBe(true);
+  });
+
+  it('accepting records the edits as the reviewed state', () => {
+    replace('manifest.yaml', 'name: sample\ntype: skill\nversion: 1.0.0\nruntime: bash\n');
+    const snapshot = acceptType(typePath, installedRoot);
+
+    expect(snapshot.version).toBe('1.0.0');
+    expect(snapshot.source).toBe('catalog');
+    expect(verifyType(typePath, installedRoot).changes).toEqual([]);
+  });
+});
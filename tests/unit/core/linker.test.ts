@@ -1,13 +1,23 @@
-import { describe, it, expect, beforeEach, afterEach } from 'vitest';
-import { mkdirSync, writeFileSync, readFileSync, rmSync } from 'node:fs';
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, readdirSync } from 'node:fs';
 import { join } from 'node:path';
 import { tmpdir } from 'node:os';
 import {
   initProject,
   loadProject,
   projectConfigPath,
+  saveProject,
+  isSignificantSkew,
+  checkVersionSkew,
+  parsePin,
+  checkPins,
+  addType,
+  previewSync,
+  sync,
 } from '../../../src/core/linker.js';
 
+vi.mock('../../../src/core/updater.js', () => ({ currentVersion: () => '1.4.0' }));
+
 describe('linker', () => {
   let projectDir: string;
 
@@ -50,4 +60,140 @@ describe('linker', () => {
       expect(projectConfigPath('/test')).toBe('/test/.agentx/project.yaml');
     });
   });
+
+  describe('version skew', () => {
+    it('treats major (or 0.x minor) differences as significant', () => {
+      expect(isSignificantSkew('1.2.0', '1.9.3')).toBe(false);
+      expect(isSignificantSkew('1.2.0', '2.0.0')).toBe(true);
+      expect(isSignificantSkew('0.3.0', '0.4.1')).toBe(true);
+      expect(isSignificantSkew('dev', '2.0.0')).toBe(false);
+    });
+
+    it('round-trips the generating version through project.yaml', () => {
+      initProject(projectDir, []);
+      saveProject(projectDir, { ...loadProject(projectDir), generatedBy: '2.1.0' });
+      expect(readFileSync(projectConfigPath(projectDir), 'utf-8')).toContain('generated_by: 2.1.0');
+      expect(loadProject(projectDir).generatedBy).toBe('2.1.0');
+    });
+
+    it('reports when the CLI is older than the generating version', async () => {
+      initProject(projectDir, []);
+      expect(await checkVersionSkew(projectDir)).toBeNull();
+
+      saveProject(projectDir, { ...loadProject(projectDir), generatedBy: '2.1.0' });
+      expect(await checkVersionSkew(projectDir)).toEqual({
+        current: '1.4.0',
+        recorded: ['2.1.0'],
+        direction: 'older',
+      });
+    });
+  });
+
+  describe('version pins', () => {
+    it('splits pins out of active entries and writes them back', () => {
+      expect(parsePin('skills/scm/git/commit-analyzer@1.4.2')).toEqual({
+        typePath: 'skills/scm/git/commit-analyzer',
+        version: '1.4.2',
+      });
+      expect(parsePin('skills/x')).toEqual({ typePath: 'skills/x' });
+      expect(() => parsePin('skills/x@')).toThrow(/Missing version/);
+
+      initProject(projectDir, []);
+      writeFileSync(
+        projectConfigPath(projectDir),
+        'tools: []\nactive:\n  skills:\n    - skills/a@1.4.2\n    - skills/b\n',
+      );
+      const config = loadProject(projectDir);
+      expect(config.active.skills).toEqual(['skills/a', 'skills/b']);
+      expect(config.pins).toEqual({ 'skills/a': '1.4.2' });
+
+      saveProject(projectDir, config);
+      const saved = readFileSync(projectConfigPath(projectDir), 'utf-8');
+      expect(saved).toContain('- skills/a@1.4.2');
+      expect(saved).not.toContain('pins');
+    });
+
+    it('reports pinned types installed at another version with a hint', async () => {
+      const home = join(projectDir, 'home');
+      process.env.AGENTX_HOME = home;
+      try {
+        const installedRoot = join(home, 'installed');
+        for (const [name, version] of [['a', '1.4.2'], ['b', '2.0.0'], ['c', '1.0.0']]) {
+          mkdirSync(join(installedRoot, 'skills', name), { recursive: true });
+          writeFileSync(join(installedRoot, 'skills', name, 'manifest.yaml'), `name: ${name}\nversion: "${version}"\n`);
+        }
+        initProject(projectDir, []);
+        const config = {
+          ...loadProject(projectDir),
+          pins: { 'skills/a': '1.4.2', 'skills/b': '1.5.0', 'skills/c': '1.1.0', 'skills/d': '1.0.0' },
+        };
+
+        const mismatches = await checkPins(config, installedRoot);
+        expect(mismatches.map((m) => [m.typePath, m.installed])).toEqual([
+          ['skills/b', '2.0.0'],
+          ['skills/c', '1.0.0'],
+          ['skills/d', null],
+        ]);
+        expect(mismatches[0].message).toMatch(/pinned to 1.5.0 but is installed at 2.0.0; .*downgrade/);
+        expect(mismatches[1].message).toMatch(/upgrade with/);
+        expect(mismatches[2].message).toMatch(/is not installed/);
+        expect(await checkPins(config, installedRoot, ['skills/a'])).toEqual([]);
+      } finally {
+        delete process.env.AGENTX_HOME;
+      }
+    });
+  });
+
+  describe('addType', () => {
+    it('refuses a type that is not installed, suggesting close matches', async () => {
+      const home = join(projectDir, 'home');
+      process.env.AGENTX_HOME = home;
+      try {
+        const skillDir = join(home, 'installed', 'skills', 'scm', 'git', 'commit-analyzer');
+        mkdirSync(skillDir, { recursive: true });
+        writeFileSync(join(skillDir, 'manifest.yaml'), 'name: commit-analyzer\nversion: "1.0.0"\n');
+        initProject(projectDir, []);
+        const before = readFileSync(projectConfigPath(projectDir), 'utf-8');
+
+        await expect(addType(projectDir, 'skills/scm/git/commit-analyzr')).rejects.toMatchObject({
+          code: 'AGX-REG-003',
+          message: expect.stringContaining('Did you mean:\n  skills/scm/git/commit-analyzer'),
+        });
+        await expect(addType(projectDir, 'personas/nobody@1.0.0')).rejects.toThrow(
+          'Run `agentx install personas/nobody` first.',
+        );
+        expect(readFileSync(projectConfigPath(projectDir), 'utf-8')).toBe(before);
+      } finally {
+        delete process.env.AGENTX_HOME;
+      }
+    });
+  });
+
+  describe('previewSync', () => {
+    it('reports the planned files and links without writing them', async () => {
+      const home = join(projectDir, 'home');
+      process.env.AGENTX_HOME = home;
+      try {
+        const contextDir = join(home, 'installed', 'context', 'lang', 'go');
+        mkdirSync(contextDir, { recursive: true });
+        writeFileSync(join(contextDir, 'manifest.yaml'), 'name: go\nversion: "1.0.0"\n');
+        initProject(projectDir, ['claude-code']);
+        saveProject(projectDir, { ...loadProject(projectDir), active: { context: ['context/lang/go'] } });
+        const before = readdirSync(projectDir, { recursive: true }).sort();
+
+        const changes = await previewSync(projectDir);
+        expect(changes.map((c) => [c.kind, c.action, c.path])).toEqual([
+          ['file', 'create', join('.claude', 'CLAUDE.md')],
+          ['link', 'create', join('.claude', 'context', 'context--lang--go')],
+        ]);
+        expect(changes[0].diff).toMatch(/^--- \/dev\/null\n\+\+\+ b\/\.claude\/CLAUDE\.md/);
+        expect(readdirSync(projectDir, { recursive: true }).sort()).toEqual(before);
+
+        await sync(projectDir);
+        expect((await previewSync(projectDir)).map((c) => c.action)).toEqual(['unchanged', 'unchanged']);
+      } finally {
+        delete process.env.AGENTX_HOME;
+      }
+    });
+  });
 });
@@ -0,0 +1,55 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync, mkdirSync, rmSync, existsSync, readFileSync, readdirSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { acquireLock, isLocked, LOCK_FILE } from '../../../src/core/lock.js';
+
+describe('advisory locks', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-lock-test-${Date.now()}`);
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => rmSync(dir, { recursive: true, force: true }));
+
+  it('records the owner and releases', async () => {
+    const release = await acquireLock(dir, { command: 'install' });
+    const owner = JSON.parse(readFileSync(join(dir, LOCK_FILE), 'utf-8'));
+    expect(owner).toMatchObject({ pid: process.pid, command: 'install' });
+    expect(isLocked(dir)).toBe(true);
+
+    // Re-entrant within the process
+    const inner = await acquireLock(dir, { command: 'link sync' });
+    inner();
+    expect(existsSync(join(dir, LOCK_FILE))).toBe(true);
+
+    release();
+    expect(existsSync(join(dir, LOCK_FILE))).toBe(false);
+  });
+
+  it('times out while another live process holds the lock', async () => {
+    // The parent process is alive and is not us
+    writeFileSync(join(dir, LOCK_FILE), JSON.stringify({ pid: process.ppid, command: 'install', startedAt: 'now' }));
+    let waitedOn = 0;
+    await expect(
+      acquireLock(dir, { command: 'link sync', timeoutMs: 300, onWait: (o) => (waitedOn = o.pid) }),
+    ).rejects.toThrow(/Another agentx process is running \(pid \d+, `agentx install`/);
+    expect(waitedOn).toBe(process.ppid);
+  });
+
+  it('takes over a stale lock', async () => {
+    writeFileSync(join(dir, LOCK_FILE), JSON.stringify({ pid: 2 ** 22 + 12345, command: 'install', startedAt: 'then' }));
+    const release = await acquireLock(dir, { command: 'install', timeoutMs: 0 });
+    expect(JSON.parse(readFileSync(join(dir, LOCK_FILE), 'utf-8')).pid).toBe(process.pid);
+    // The stale lock and the staging file are both gone
+    expect(readdirSync(dir)).toEqual([LOCK_FILE]);
+    release();
+    expect(readdirSync(dir)).toEqual([]);
+  });
+
+  it('names the lock file after the app', () => {
+    expect(LOCK_FILE).toBe('agentx.lock');
+  });
+});
@@ -1,5 +1,16 @@
 import { describe, it, expect } from 'vitest';
-import { parseManifest, detectType, parseBase } from '../../../src/core/manifest.js';
+import {
+  parseManifest,
+  detectType,
+  parseBase,
+  validateManifest,
+  manifestErrors,
+  formatIssue,
+  ManifestError,
+  findUnknownKeys,
+} from '../../../src/core/manifest.js';
+import { ManifestSchema } from '../../../src/config/schema.js';
+import { load as yamlLoad } from 'js-yaml';
 
 describe('manifest', () => {
   describe('detectType', () => {
@@ -76,4 +87,101 @@ topic: scm`;
       expect(() => parseManifest('not valid yaml: [[')).toThrow();
     });
   });
+
+  describe('validateManifest', () => {
+    it('locates schema violations by line and column', () => {
+      const raw = `name: commit-analyzer
+type: skill
+version: "1.0.0"
+description: Analyzes commits
+runtime: ruby
+topic: scm`;
+      const issues = validateManifest(raw, 'skill.yaml');
+      expect(issues).toHaveLength(1);
+      expect(issues[0]).toMatchObject({ file: 'skill.yaml', line: 5, col: 10, path: 'runtime' });
+      expect(issues[0].snippet).toContain('> 5 | runtime: ruby');
+    });
+
+    it('points missing keys at the enclosing mapping', () => {
+      const raw = `name: spring-boot
+type: context
+version: "1.0.0"
+description: Spring Boot context
+sources:
+  - content.md`;
+      const [issue] = validateManifest(raw);
+      expect(issue.path).toBe('format');
+      expect(issue.line).toBe(1);
+    });
+
+    it('reports YAML syntax errors with their position', () => {
+      const issues = validateManifest('name: x\ntags: [a, b\n', 'bad.yaml');
+      expect(issues).toHaveLength(1);
+      expect(issues[0].file).toBe('bad.yaml');
+      expect(issues[0].line).toBeGreaterThan(1);
+    });
+
+    it('throws a ManifestError carrying the issues', () => {
+      try {
+        parseManifest('name: x\ntype: context', 'ctx.yaml');
+        expect.unreachable();
+      } catch (err) {
+        expect(err).toBeInstanceOf(ManifestError);
+        expect((err as ManifestError).message).toMatch(/^ctx\.yaml:\d+:\d+: /);
+      }
+    });
+  });
+
+  describe('strict mode', () => {
+    const skill = `name: commit-analyzer
+type: skill
+version: "1.0.0"
+description: Analyzes commits
+runtime: node
+topic: scm
+regitry:
+  tokens:
+    - name: GITHUB_TOKEN
+inputs:
+  - name: days
+    type: number
+    requird: true`;
+
+    it('warns about unknown keys with suggestions by default', () => {
+      const issues = validateManifest(skill, 'skill.yaml');
+      expect(issues.every((i) => i.warning)).toBe(true);
+      expect(manifestErrors(issues)).toEqual([]);
+      expect(formatIssue(issues[0])).toMatch(/^skill\.yaml:7:1: warning: Unknown field "regitry"/);
+    });
+
+    it('reports unknown keys as errors when strict', () => {
+      const issues = validateManifest(skill, 'skill.yaml', { strict: true });
+      expect(issues.some((i) => i.warning)).toBe(false);
+      expect(issues.map((i) => [i.path, i.line, i.message])).toEqual([
+        ['regitry', 7, 'Unknown field "regitry". Did you mean "registry"?'],
+        ['inputs.0.requird', 13, 'Unknown field "requird". Did you mean "required"?'],
+      ]);
+    });
+
+    it('ignores unknown keys when parsing unless asked', () => {
+      expect(parseManifest(skill).type).toBe('skill');
+      expect(manifestErrors(validateManifest(skill, 'skill.yaml', { strict: false }))).toEqual([]);
+      expect(() => parseManifest(skill, 'skill.yaml', { strict: true })).toThrow(ManifestError);
+    });
+
+    it('checks union members against the intended schema', () => {
+      const workflow = `name: review
+type: workflow
+version: "1.0"
+description: Review
+runtime: node
+steps:
+  - id: deliver
+    publish:
+      to: out.json
+      form: analyze`;
+      const [issue] = findUnknownKeys(ManifestSchema, yamlLoad(workflow));
+      expect(issue).toEqual({ path: ['steps', 0, 'publish', 'form'], suggestion: 'from' });
+    });
+  });
 });
@@ -0,0 +1,94 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import yaml from 'js-yaml';
+import { resolveType, installType } from '../../../src/core/registry.js';
+import { sourceLabel, contentDir } from '../../../src/core/merge.js';
+import { trustProjectExtension } from '../../../src/core/trust.js';
+import type { Source } from '../../../src/types/registry.js';
+
+describe('merge strategies', () => {
+  let root: string;
+  let installedRoot: string;
+  let sources: Source[];
+
+  function context(base: string, typePath: string, content: string): void {
+    const dir = join(base, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      `name: ${typePath.split('/').pop()}\ntype: context\nversion: "1.0.0"\ndescription: d\nformat: markdown\nsources:\n  - content.md\n`,
+    );
+    writeFileSync(join(dir, 'content.md'), content);
+  }
+
+  function extension(merge: string): void {
+    writeFileSync(join(root, 'acme', 'extension.yaml'), `name: acme\nmerge:\n${merge}`);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-merge-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+    sources = [
+      { name: 'catalog', basePath: join(root, 'catalog') },
+      { name: 'acme', basePath: join(root, 'acme') },
+    ];
+    context(sources[0].basePath, 'context/spring-boot/security', '# Security\n');
+    context(sources[1].basePath, 'context/spring-boot/security', 'Use Acme SSO.\n');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('shadows later sources by default', () => {
+    const resolved = resolveType('context/spring-boot/security', sources)!;
+    expect(sourceLabel(resolved)).toBe('catalog');
+    expect(resolved.appended).toBeUndefined();
+  });
+
+  it('lets an extension override an earlier source', () => {
+    extension('  context/spring-boot/security: override\n');
+    expect(resolveType('context/spring-boot/security', sources)?.sourceName).toBe('acme');
+  });
+
+  it('appends an extension context after the earlier one', () => {
+    extension('  context/spring-boot/security: append\n');
+    const resolved = resolveType('context/spring-boot/security', sources)!;
+    expect(sourceLabel(resolved)).toBe('catalog+acme');
+
+    installType(resolved, installedRoot);
+    const dir = join(installedRoot, 'context/spring-boot/security');
+    const manifest = yaml.load(readFileSync(join(dir, 'manifest.yaml'), 'utf-8')) as { sources: string[] };
+    expect(manifest.sources).toEqual(['content.md', 'merged/acme/content.md']);
+    expect(readFileSync(join(dir, 'content.md'), 'utf-8')).toBe('# Security\n');
+    expect(readFileSync(join(dir, 'merged/acme/content.md'), 'utf-8')).toBe('### From acme\n\nUse Acme SSO.\n');
+  });
+
+  it('ignores append for types other than context', () => {
+    for (const base of [sources[0].basePath, sources[1].basePath]) {
+      mkdirSync(join(base, 'personas/java-dev'), { recursive: true });
+      writeFileSync(join(base, 'personas/java-dev/manifest.yaml'), 'name: java-dev\ntype: persona\nversion: "1.0.0"\ndescription: d\n');
+    }
+    extension('  personas/java-dev: append\n');
+    const resolved = resolveType('personas/java-dev', sources)!;
+    expect(sourceLabel(resolved)).toBe('catalog');
+  });
+
+  it('takes merge strategies from a project-local extension only once trusted, merging into the project', () => {
+    const base = join(root, 'project', '.agentx', 'extensions', 'local');
+    const local: Source = { name: 'local', basePath: base, local: true };
+    context(base, 'context/spring-boot/security', 'Project rules.\n');
+    writeFileSync(join(base, 'extension.yaml'), 'name: local\nmerge:\n  context/spring-boot/security: append\n');
+    expect(sourceLabel(resolveType('context/spring-boot/security', [...sources, local])!)).toBe('catalog');
+
+    trustProjectExtension(base);
+    const resolved = resolveType('context/spring-boot/security', [...sources, local])!;
+    expect(sourceLabel(resolved)).toBe('catalog+local');
+    expect(contentDir(resolved)).toBe(join(root, 'project', '.agentx', 'merge', 'context/spring-boot/security'));
+    expect(existsSync(join(root, 'home', 'cache', 'merge'))).toBe(false);
+  });
+});
@@ -0,0 +1,63 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { createServer, type Server } from 'node:http';
+import type { AddressInfo } from 'node:net';
+import { readFileSync, writeFileSync, mkdirSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { mirrorUrl, bundleUrl, mirrorHeaders, createBundle, fetchBundle } from '../../../src/core/mirror.js';
+
+describe('mirror', () => {
+  let dir: string;
+  const saved = { ...process.env };
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-mirror-test-${Date.now()}`);
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    process.env = { ...saved };
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('builds bundle URLs under the mirror', () => {
+    process.env.AGENTX_MIRROR = 'https://nexus.acme.io/repository/agentx/';
+    const base = mirrorUrl()!;
+    expect(base).toBe('https://nexus.acme.io/repository/agentx');
+    expect(bundleUrl(base, 'catalog', 'main')).toBe(`${base}/catalog/main.tar.gz`);
+    expect(bundleUrl(base, 'extensions', 'release/2', 'acme')).toBe(`${base}/extensions/acme/release%2F2.tar.gz`);
+  });
+
+  it('authenticates with the mirror host credential', () => {
+    process.env.AGENTX_TOKEN_NEXUS_ACME_IO = 's3cret';
+    expect(mirrorHeaders('https://nexus.acme.io/x.tar.gz')).toEqual({ Authorization: 'Bearer s3cret' });
+  });
+
+  it('round-trips a bundle through a mirror', async () => {
+    const checkout = join(dir, 'catalog-repo');
+    mkdirSync(join(checkout, 'catalog', 'skills'), { recursive: true });
+    mkdirSync(join(checkout, '.git'));
+    writeFileSync(join(checkout, 'catalog', 'skills', 'README.md'), 'hello');
+    const bundle = join(dir, 'main.tar.gz');
+    createBundle(checkout, bundle);
+
+    let server: Server | undefined;
+    try {
+      server = createServer((req, res) => {
+        if (req.url === '/catalog/main.tar.gz') res.end(readFileSync(bundle));
+        else res.writeHead(404).end();
+      });
+      await new Promise<void>((r) => server!.listen(0, '127.0.0.1', r));
+      const base = `http://127.0.0.1:${(server.address() as AddressInfo).port}`;
+
+      const target = join(dir, 'out');
+      await fetchBundle(bundleUrl(base, 'catalog', 'main'), target);
+      expect(readFileSync(join(target, 'catalog', 'skills', 'README.md'), 'utf-8')).toBe('hello');
+      expect(existsSync(join(target, '.git'))).toBe(false);
+
+      await expect(fetchBundle(bundleUrl(base, 'catalog', 'v2'), join(dir, 'missing'))).rejects.toThrow(/404/);
+    } finally {
+      server?.close();
+    }
+  });
+});
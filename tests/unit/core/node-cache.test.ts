@@ -0,0 +1,73 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync, mkdirSync, rmSync, readFileSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { setExecutionContext, resetExecutionContext, executionContext } from '../../../src/config/context.js';
+import {
+  nodeCacheKey,
+  nodeCacheDir,
+  linkCachedModules,
+  nodeCacheStats,
+  clearNodeCache,
+} from '../../../src/core/node-cache.js';
+import { installNodeDeps } from '../../../src/core/registry.js';
+
+describe('node_modules cache', () => {
+  let home: string;
+
+  function skill(name: string, pkg: object, lock?: string): string {
+    const dir = join(home, 'installed', name);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'package.json'), JSON.stringify(pkg));
+    if (lock) writeFileSync(join(dir, 'package-lock.json'), lock);
+    return dir;
+  }
+
+  function seed(key: string): void {
+    const modules = join(nodeCacheDir(), key, 'node_modules', 'left-pad');
+    mkdirSync(modules, { recursive: true });
+    writeFileSync(join(modules, 'index.js'), 'module.exports = 1;\n');
+  }
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-node-cache-test-${Date.now()}`);
+    process.env.AGENTX_HOME = home;
+    mkdirSync(home, { recursive: true });
+  });
+
+  afterEach(() => {
+    resetExecutionContext();
+    delete process.env.AGENTX_HOME;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('keys dependencies by lockfile and skips skills that cannot share them', () => {
+    const a = skill('a', { name: 'a' }, '{"lockfileVersion": 3}');
+    const b = skill('b', { name: 'b' }, '{"lockfileVersion": 3}');
+    const c = skill('c', { name: 'c' }, '{"lockfileVersion": 3, "packages": {}}');
+
+    expect(nodeCacheKey(a)).toMatch(/^[0-9a-f]{32}$/);
+    expect(nodeCacheKey(a)).toBe(nodeCacheKey(b));
+    expect(nodeCacheKey(a)).not.toBe(nodeCacheKey(c));
+    expect(nodeCacheKey(skill('nolock', { name: 'x' }))).toBeNull();
+    expect(nodeCacheKey(skill('scripted', { scripts: { postinstall: 'node build.js' } }, '{}'))).toBeNull();
+  });
+
+  it('links a cached entry into each skill, even offline', () => {
+    const a = skill('a', { name: 'a' }, '{"lockfileVersion": 3}');
+    const b = skill('b', { name: 'b' }, '{"lockfileVersion": 3}');
+    expect(linkCachedModules(a)).toBe(false);
+
+    seed(nodeCacheKey(a)!);
+    setExecutionContext({ ...executionContext(), offline: true });
+    expect(linkCachedModules(a)).toBe(true);
+    expect(installNodeDeps(b)).toBeNull();
+    expect(readFileSync(join(b, 'node_modules', 'left-pad', 'index.js'), 'utf-8')).toContain('module.exports');
+
+    const stats = nodeCacheStats();
+    expect(stats.entries).toBe(1);
+    expect(stats.bytes).toBeGreaterThan(0);
+    clearNodeCache();
+    expect(existsSync(nodeCacheDir())).toBe(false);
+  });
+});
@@ -0,0 +1,70 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, readdirSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createConnection } from 'node:net';
+import { notifyChange, readLastChange, subscribe, type ChangeEvent } from '../../../src/core/notify.js';
+
+const send = (socketPath: string, events: Partial<ChangeEvent>[]) =>
+  new Promise<void>((resolve, reject) => {
+    const conn = createConnection(socketPath, () => {
+      conn.end(events.map((e) => JSON.stringify(e)).join('\n') + '\n');
+    });
+    conn.on('close', () => resolve());
+    conn.on('error', reject);
+  });
+
+const tick = (ms = 50) => new Promise((r) => setTimeout(r, ms));
+
+describe('change notifications', () => {
+  let root: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-notify-test-${Date.now()}`);
+    process.env.AGENTX_HOME = root;
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('bumps the sequence file without leaving temp files behind', async () => {
+    await notifyChange('install', ['skills/a']);
+    const second = await notifyChange('uninstall', ['skills/a']);
+
+    expect(second.seq).toBe(2);
+    expect(readLastChange()).toMatchObject({ seq: 2, kind: 'uninstall', typePaths: ['skills/a'] });
+    expect(readdirSync(root)).toEqual(['installed.seq']);
+  });
+
+  it.skipIf(process.platform === 'win32')('delivers socket events once per sequence number', async () => {
+    const seen: number[] = [];
+    const sub = subscribe((e) => seen.push(e.seq), { socket: true });
+    await tick();
+
+    const socketPath = join(root, 'notify', `${process.pid}.sock`);
+    const other = { kind: 'install' as const, typePaths: [], pid: process.pid + 1, at: 'now' };
+    await send(socketPath, [
+      { ...other, seq: 1 },
+      { ...other, seq: 1 },
+      { ...other, seq: 2, pid: process.pid },
+      { ...other, seq: 3 },
+    ]);
+    await tick();
+    sub.close();
+
+    // Duplicates and events from this process are dropped
+    expect(seen).toEqual([1, 3]);
+  });
+
+  it.skipIf(process.platform === 'win32')('falls back to polling when the socket cannot listen', async () => {
+    // Longer than a unix socket path may be, so listen fails asynchronously
+    process.env.AGENTX_HOME = join(root, 'x'.repeat(120));
+    mkdirSync(process.env.AGENTX_HOME, { recursive: true });
+
+    const sub = subscribe(() => {}, { socket: true });
+    await tick();
+    expect(() => sub.close()).not.toThrow();
+  });
+});
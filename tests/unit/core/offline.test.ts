@@ -0,0 +1,51 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync, mkdirSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { setExecutionContext, resetExecutionContext, executionContext } from '../../../src/config/context.js';
+import { requireOnline } from '../../../src/core/offline.js';
+import { installNodeDeps } from '../../../src/core/registry.js';
+import { fetchRemoteSources } from '../../../src/core/context-sources.js';
+import { checkForUpdate } from '../../../src/core/updater.js';
+import { syncExtensions } from '../../../src/core/extension.js';
+
+describe('offline mode', () => {
+  let home: string;
+  let prevHome: string | undefined;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-offline-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+    mkdirSync(home, { recursive: true });
+    setExecutionContext({ ...executionContext(), offline: true });
+  });
+
+  afterEach(() => {
+    resetExecutionContext();
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('skips npm install', () => {
+    writeFileSync(join(home, 'package.json'), '{}');
+    expect(installNodeDeps(home)).toMatch(/^\[SKIP\] offline: npm install/);
+  });
+
+  it('skips remote context fetches', async () => {
+    const warnings = await fetchRemoteSources(['https://example.com/guide.md']);
+    expect(warnings).toEqual(['[SKIP] offline: fetch https://example.com/guide.md']);
+  });
+
+  it('skips update checks and extension syncs', async () => {
+    expect(await checkForUpdate()).toBeNull();
+    expect(await syncExtensions(home)).toEqual(['[SKIP] offline: extension sync']);
+  });
+
+  it('fails fast where the network is required', () => {
+    expect(() => requireOnline('Adding an extension')).toThrow(/offline mode is on/);
+    resetExecutionContext();
+    expect(() => requireOnline('Adding an extension')).not.toThrow();
+  });
+});
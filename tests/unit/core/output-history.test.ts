@@ -0,0 +1,62 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync, mkdirSync, rmSync, readdirSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  outputStamp,
+  recordOutput,
+  listHistory,
+  readOutput,
+  historyRetention,
+  DEFAULT_RETENTION,
+} from '../../../src/core/output-history.js';
+import type { SkillManifest } from '../../../src/types/manifest.js';
+
+describe('output history', () => {
+  let home: string;
+  let prevHome: string | undefined;
+  let registry: string;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-output-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+    registry = join(home, 'userdata', 'skills', 'scm', 'commit');
+    mkdirSync(join(registry, 'output'), { recursive: true });
+  });
+
+  afterEach(() => {
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('archives only outputs that changed during the run', () => {
+    expect(recordOutput(registry, null, 5)).toBeNull();
+
+    writeFileSync(join(registry, 'output', 'latest.json'), '{"n":1}');
+    expect(recordOutput(registry, null, 5)).not.toBeNull();
+
+    const unchanged = outputStamp(registry);
+    expect(recordOutput(registry, unchanged, 5)).toBeNull();
+    expect(listHistory('skills/scm/commit')).toHaveLength(1);
+  });
+
+  it('prunes to the retention count and reads runs back', async () => {
+    for (let n = 1; n <= 4; n++) {
+      writeFileSync(join(registry, 'output', 'latest.json'), JSON.stringify({ n }));
+      recordOutput(registry, null, 3);
+      await new Promise((r) => setTimeout(r, 5));
+    }
+    expect(readdirSync(join(registry, 'output', 'history'))).toHaveLength(3);
+    expect(readOutput('skills/scm/commit', 1).output).toEqual({ n: 4 });
+    expect(readOutput('skills/scm/commit', 3).output).toEqual({ n: 2 });
+    expect(readOutput('skills/scm/commit').output).toEqual({ n: 4 });
+    expect(() => readOutput('skills/scm/commit', 4)).toThrow(/3 run/);
+  });
+
+  it('prefers the manifest retention over the default', () => {
+    expect(historyRetention({ registry: { output: { history: 2 } } } as SkillManifest)).toBe(2);
+    expect(historyRetention({} as SkillManifest)).toBe(DEFAULT_RETENTION);
+  });
+});
@@ -0,0 +1,55 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { checkOutput, outputSchemaMode } from '../../../src/core/output-schema.js';
+import * as settings from '../../../src/config/settings.js';
+
+describe('output schemas', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-output-schema-test-${Date.now()}`);
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    settings.init('');
+  });
+
+  it('checks output against an embedded or referenced schema', () => {
+    const schema = { type: 'object', required: ['count'], properties: { count: { type: 'number' } } };
+    writeFileSync(join(dir, 'output.schema.yaml'), 'type: object\nrequired: [count]\n');
+
+    expect(checkOutput(dir, { format: 'json', schema }, '{"count": 3}')).toEqual([]);
+    expect(checkOutput(dir, { format: 'json', schema }, '{"count": "3"}')).toEqual(['count: expected number, got string']);
+    expect(checkOutput(dir, { format: 'yaml', schema: 'output.schema.yaml' }, 'total: 1\n')).toEqual(['count: required']);
+    expect(checkOutput(dir, { format: 'json' }, 'not json')).toEqual([]);
+  });
+
+  it('keeps YAML output to JSON types', () => {
+    const schema = { type: 'object', properties: { on: { type: 'string' } } };
+    expect(checkOutput(dir, { format: 'yaml', schema }, 'on: 2026-10-16\n')).toEqual([]);
+  });
+
+  it('rejects schemas using keywords it cannot enforce', () => {
+    const schema = { type: 'object', patternProperties: { '^x-': { type: 'string' } } };
+    expect(checkOutput(dir, { format: 'json', schema }, '{}')).toEqual([
+      'outputs.schema: Unsupported keyword "patternProperties" at #',
+    ]);
+  });
+
+  it('reports unparseable output and unreadable schemas as violations', () => {
+    expect(checkOutput(dir, { format: 'json', schema: {} }, 'not json')[0]).toMatch(/^output is not valid JSON/);
+    expect(checkOutput(dir, { format: 'json', schema: 'missing.json' }, '{}')[0]).toMatch(/^Cannot read outputs.schema/);
+  });
+
+  it('reads the strictness from run.output_schema', () => {
+    expect(outputSchemaMode()).toBe('warn');
+    const config = join(dir, 'config.yaml');
+    writeFileSync(config, 'run.output_schema: fail\n');
+    settings.init(config);
+    expect(outputSchemaMode()).toBe('fail');
+  });
+});
@@ -0,0 +1,108 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, readlinkSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  addOverride,
+  listOverrides,
+  mergeOverrides,
+  resolveOverride,
+  buildOverlays,
+  overlayDirs,
+} from '../../../src/core/overrides.js';
+import { merge3 } from '../../../src/utils/merge.js';
+
+describe('overrides', () => {
+  let root: string;
+  let projectPath: string;
+  let installedRoot: string;
+  const typePath = 'context/team/style';
+
+  function upstream(content: string): void {
+    writeFileSync(join(installedRoot, typePath, 'content.md'), content);
+  }
+
+  function override(content?: string): string {
+    const path = join(projectPath, '.agentx/overrides', typePath, 'content.md');
+    if (content !== undefined) writeFileSync(path, content);
+    return readFileSync(path, 'utf-8');
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-overrides-test-${Date.now()}`);
+    projectPath = join(root, 'project');
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+    mkdirSync(join(installedRoot, typePath), { recursive: true });
+    writeFileSync(join(installedRoot, typePath, 'manifest.yaml'), 'name: style\ntype: context\n');
+    upstream('# Style\n\nUse tabs.\n\nWrap at 80.\n');
+    addOverride(projectPath, installedRoot, typePath, 'content.md');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('attributes override files to their type', () => {
+    expect(listOverrides(projectPath, installedRoot)).toEqual([
+      { typePath, file: 'content.md', conflicted: false },
+    ]);
+  });
+
+  it('merges non-overlapping upstream changes into the override', () => {
+    override('# Style\n\nUse spaces.\n\nWrap at 80.\n');
+    upstream('# Style\n\nUse tabs.\n\nWrap at 100.\n');
+
+    expect(mergeOverrides(projectPath, installedRoot, merge3)).toEqual([
+      { typePath, file: 'content.md', status: 'merged' },
+    ]);
+    expect(override()).toBe('# Style\n\nUse spaces.\n\nWrap at 100.\n');
+    expect(mergeOverrides(projectPath, installedRoot, merge3)[0].status).toBe('unchanged');
+  });
+
+  it('leaves conflicts marked until resolved', () => {
+    override('# Style\n\nUse spaces.\n\nWrap at 80.\n');
+    upstream('# Style\n\nUse two spaces.\n\nWrap at 80.\n');
+
+    expect(mergeOverrides(projectPath, installedRoot, merge3)[0].status).toBe('conflict');
+    expect(listOverrides(projectPath, installedRoot)[0].conflicted).toBe(true);
+    expect(() => resolveOverride(projectPath, installedRoot, typePath, 'content.md', 'merged')).toThrow(/markers/);
+
+    resolveOverride(projectPath, installedRoot, typePath, 'content.md', 'ours');
+    expect(override()).toBe('# Style\n\nUse spaces.\n\nWrap at 80.\n');
+    expect(mergeOverrides(projectPath, installedRoot, merge3)[0].status).toBe('unchanged');
+  });
+
+  it('keeps our side of two-way conflict markers', () => {
+    override('# Style\n\n<<<<<<< override\nUse spaces.\n=======\nUse two spaces.\n>>>>>>> upstream\n\nWrap at 80.\n');
+    resolveOverride(projectPath, installedRoot, typePath, 'content.md', 'ours');
+    expect(override()).toBe('# Style\n\nUse spaces.\n\nWrap at 80.\n');
+  });
+
+  it('refuses to keep our side when markers are malformed', () => {
+    const broken = '<<<<<<< override\nUse spaces.\n>>>>>>> upstream\n';
+    override(broken);
+    expect(() => resolveOverride(projectPath, installedRoot, typePath, 'content.md', 'ours')).toThrow(/by hand/);
+    expect(override()).toBe(broken);
+  });
+
+  it('drops the override when taking upstream', () => {
+    resolveOverride(projectPath, installedRoot, typePath, 'content.md', 'theirs');
+    expect(listOverrides(projectPath, installedRoot)).toEqual([]);
+  });
+
+  it('builds an overlay linking overridden files from the project', () => {
+    const overlays = buildOverlays(projectPath, installedRoot, [typePath]);
+    const dir = overlays[typePath];
+    expect(readlinkSync(join(dir, 'content.md'))).toBe(join(projectPath, '.agentx/overrides', typePath, 'content.md'));
+    expect(readlinkSync(join(dir, 'manifest.yaml'))).toBe(join(installedRoot, typePath, 'manifest.yaml'));
+  });
+
+  it('names overlay directories without building them', () => {
+    const dirs = overlayDirs(projectPath, installedRoot, [typePath, 'context/other']);
+    expect(dirs).toEqual({ [typePath]: join(projectPath, '.agentx/overlay', 'context--team--style') });
+    expect(existsSync(dirs[typePath])).toBe(false);
+    expect(buildOverlays(projectPath, installedRoot, [typePath])).toEqual(dirs);
+  });
+});
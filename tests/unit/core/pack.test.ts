@@ -0,0 +1,78 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { exportPack, importPack } from '../../../src/core/pack.js';
+
+describe('prompt packs', () => {
+  let root: string;
+  let source: string;
+  let target: string;
+  let archive: string;
+
+  function type(installedRoot: string, typePath: string, manifest: string, files: Record<string, string> = {}): void {
+    const dir = join(installedRoot, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), `name: ${typePath.split('/').pop()}\nversion: "1.0.0"\ndescription: d\n${manifest}`);
+    for (const [name, content] of Object.entries(files)) writeFileSync(join(dir, name), content);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-pack-test-${Date.now()}`);
+    source = join(root, 'source');
+    target = join(root, 'target');
+    archive = join(root, 'review.tar.gz');
+    process.env.AGENTX_HOME = join(root, 'home');
+
+    type(source, 'personas/java-dev', 'type: persona\ncontext:\n  - context/spring-boot\n');
+    type(source, 'context/spring-boot', 'type: context\n', { 'content.md': '# Spring Boot\n', '.env': 'API_TOKEN=x\n' });
+    type(source, 'prompts/java-review', 'type: prompt\npersona: personas/java-dev\n');
+    type(source, 'context/unrelated', 'type: context\n');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('exports a prompt with its dependencies, dependencies first', async () => {
+    const result = await exportPack('prompts/java-review', source, archive);
+    expect(result.root).toBe('prompts/java-review');
+    expect(result.types).toEqual(['context/spring-boot', 'personas/java-dev', 'prompts/java-review']);
+    expect(existsSync(archive)).toBe(true);
+  });
+
+  it('installs a pack and leaves secrets behind', async () => {
+    await exportPack('prompts/java-review', source, archive);
+    const result = await importPack(archive, target);
+
+    expect(result.installed).toEqual(['context/spring-boot', 'personas/java-dev', 'prompts/java-review']);
+    expect(readFileSync(join(target, 'context/spring-boot/content.md'), 'utf-8')).toBe('# Spring Boot\n');
+    expect(existsSync(join(target, 'context/spring-boot/.env'))).toBe(false);
+    expect(existsSync(join(target, 'context/unrelated'))).toBe(false);
+  });
+
+  it('keeps installed types unless forced', async () => {
+    await exportPack('prompts/java-review', source, archive);
+    type(target, 'personas/java-dev', 'type: persona\n# local edit\n');
+
+    const kept = await importPack(archive, target);
+    expect(kept.skipped).toEqual(['personas/java-dev']);
+    expect(readFileSync(join(target, 'personas/java-dev/manifest.yaml'), 'utf-8')).toContain('local edit');
+
+    const forced = await importPack(archive, target, { overwrite: true });
+    expect(forced.installed).toContain('personas/java-dev');
+    expect(readFileSync(join(target, 'personas/java-dev/manifest.yaml'), 'utf-8')).not.toContain('local edit');
+  });
+
+  it('refuses a prompt whose dependencies are not installed', async () => {
+    type(source, 'prompts/broken', 'type: prompt\npersona: personas/missing\n');
+    await expect(exportPack('prompts/broken', source, archive)).rejects.toThrow(
+      'not installed: personas/missing (needed by prompts/broken)',
+    );
+  });
+
+  it('only packs prompts', async () => {
+    await expect(exportPack('personas/java-dev', source, archive)).rejects.toThrow(/is not a prompt/);
+  });
+});
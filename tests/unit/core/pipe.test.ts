@@ -0,0 +1,116 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { setupSkillHome, removeSkillHome, type SkillHome } from '../helpers/skill-home.js';
+import { parsePipeArgs, validatePipe, runPipe } from '../../../src/core/pipe.js';
+import { extractOutput } from '../../../src/core/workflow-inputs.js';
+
+describe('parsePipeArgs', () => {
+  it('splits stages on -- and attaches inputs and maps to each', () => {
+    expect(parsePipeArgs(['skills/a', '-i', 'id=42', '--', 'skills/b', '--map', 'body=text', '--input', 'tone=dry'])).toEqual([
+      { typePath: 'skills/a', inputs: { id: '42' }, maps: [] },
+      { typePath: 'skills/b', inputs: { tone: 'dry' }, maps: [{ from: 'body', to: 'text' }] },
+    ]);
+  });
+
+  it('rejects malformed pipes', () => {
+    expect(() => parsePipeArgs([])).toThrow(/at least one skill/);
+    expect(() => parsePipeArgs(['--', 'skills/a'])).toThrow(/before `--`/);
+    expect(() => parsePipeArgs(['skills/a', 'skills/b'])).toThrow(/Expected `--`/);
+    expect(() => parsePipeArgs(['skills/a', '--map', 'body'])).toThrow(/output=input/);
+    expect(() => parsePipeArgs(['skills/a', '--verbose'])).toThrow(/Unknown pipe option/);
+  });
+});
+
+describe('extractOutput', () => {
+  it('reads dot paths from JSON output', () => {
+    const out = '{"body":"hi","items":[{"id":7}],"meta":{"n":1}}\n';
+    expect(extractOutput(out, 'body', 'a')).toBe('hi');
+    expect(extractOutput(out, 'items.0.id', 'a')).toBe('7');
+    expect(extractOutput(out, 'meta', 'a')).toBe('{"n":1}');
+    expect(extractOutput(out, '.', 'a')).toBe(out.trim());
+    expect(() => extractOutput(out, 'missing', 'a')).toThrow(/no "missing"/);
+    expect(() => extractOutput('plain', 'body', 'a')).toThrow(/did not print JSON/);
+  });
+});
+
+describe('pipes', () => {
+  let home: SkillHome;
+  let installedRoot: string;
+  const skill = (name: string, extra: string, script: string) => home.skill(name, extra, script);
+
+  beforeEach(() => {
+    home = setupSkillHome('pipe', 'text');
+    installedRoot = home.installedRoot;
+  });
+
+  afterEach(() => removeSkillHome(home));
+
+  it('validates maps against both manifests before running', () => {
+    const fetch = skill(
+      'fetch',
+      'outputs:\n  format: json\n  schema: schema.json\n',
+      'console.log(JSON.stringify({ body: "x" }))\n',
+    );
+    writeFileSync(
+      join(installedRoot, fetch, 'schema.json'),
+      JSON.stringify({ type: 'object', properties: { body: { type: 'string' } } }),
+    );
+    const plain = skill('plain', '', 'console.log("x")\n');
+    const summarize = skill(
+      'summarize',
+      'inputs:\n  - name: text\n    type: string\n    required: true\n',
+      'console.log("ok")\n',
+    );
+
+    expect(validatePipe(parsePipeArgs([fetch, '--', summarize, '--map', 'body=text']), installedRoot)).toEqual([]);
+    expect(validatePipe(parsePipeArgs([fetch, '--', summarize, '--map', 'title=text']), installedRoot)).toEqual([
+      `${fetch}'s output schema has no "title"`,
+    ]);
+    expect(validatePipe(parsePipeArgs([fetch, '--', summarize, '--map', 'body=txt']), installedRoot)).toEqual([
+      `${summarize} has no input "txt" (its inputs: text)`,
+      `${summarize} requires input "text"; pass -i text=... or --map <output>=text`,
+    ]);
+    expect(validatePipe(parsePipeArgs([plain, '--', summarize, '--map', 'body=text']), installedRoot)).toEqual([
+      `${plain} does not declare JSON output, so only --map .=text can use it`,
+    ]);
+    expect(validatePipe(parsePipeArgs([plain, '--', summarize, '--map', '.=text']), installedRoot)).toEqual([]);
+    expect(validatePipe(parsePipeArgs([summarize, '--map', '.=text']), installedRoot)).toEqual([
+      `${summarize}: --map needs a skill before it to map from`,
+    ]);
+    expect(validatePipe(parsePipeArgs(['skills/text/nope']), installedRoot)).toEqual(['Type not installed: skills/text/nope']);
+  });
+
+  it('feeds each output to the next skill and stops at the first failure', async () => {
+    const fetch = skill(
+      'fetch',
+      'inputs:\n  - name: id\n    type: string\n    required: true\noutputs:\n  format: json\n',
+      'const args = JSON.parse(process.argv[3]);\nconsole.log(JSON.stringify({ body: `issue ${args.id}` }));\n',
+    );
+    const shout = skill(
+      'shout',
+      'inputs:\n  - name: text\n    type: string\n    required: true\n',
+      'const args = JSON.parse(process.argv[3]);\nconsole.log(args.text.toUpperCase());\n',
+    );
+    const broken = skill('broken', 'inputs:\n  - name: text\n    type: string\n', 'process.exit(4);\n');
+
+    const seen: number[] = [];
+    const result = await runPipe(parsePipeArgs([fetch, '-i', 'id=42', '--', shout, '--map', 'body=text']), installedRoot, {
+      onOutput: (stage, stream) => stream === 'stdout' && seen.push(stage),
+    });
+    expect(result.exitCode).toBe(0);
+    expect(result.stdout.trim()).toBe('ISSUE 42');
+    expect(seen).toContain(0);
+    expect(seen).toContain(1);
+
+    const failed = await runPipe(
+      parsePipeArgs([fetch, '-i', 'id=1', '--', broken, '--map', 'body=text', '--', shout, '--map', '.=text']),
+      installedRoot,
+    );
+    expect(failed.exitCode).toBe(4);
+    expect(failed.stages).toEqual([
+      { typePath: fetch, exitCode: 0 },
+      { typePath: broken, exitCode: 4 },
+    ]);
+  });
+});
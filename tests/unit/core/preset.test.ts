@@ -0,0 +1,85 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { listPresets, loadPreset, presetTypes, installPreset } from '../../../src/core/preset.js';
+import type { Source } from '../../../src/types/registry.js';
+
+describe('presets', () => {
+  let root: string;
+  let catalog: string;
+  let extension: string;
+  let installedRoot: string;
+  let sources: Source[];
+
+  function preset(base: string, name: string, body: string): void {
+    mkdirSync(join(base, 'presets'), { recursive: true });
+    writeFileSync(join(base, 'presets', `${name}.yaml`), `name: ${name}\n${body}`);
+  }
+
+  function type(typePath: string, manifest: string): void {
+    const dir = join(catalog, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), `name: ${typePath.split('/').pop()}\nversion: "1.0.0"\ndescription: d\n${manifest}`);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-preset-test-${Date.now()}`);
+    catalog = join(root, 'catalog');
+    extension = join(root, 'ext');
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+    sources = [
+      { name: 'catalog', basePath: catalog },
+      { name: 'acme', basePath: extension },
+    ];
+
+    type('context/spring-boot', 'type: context\n');
+    type('personas/java-dev', 'type: persona\ncontext:\n  - context/spring-boot\n');
+    preset(catalog, 'java-spring', 'description: Spring services\ntools: [claude-code]\npersonas: [personas/java-dev]\n');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('lists presets of all sources, the first source winning a name', () => {
+    preset(extension, 'java-spring', 'description: shadowed\n');
+    preset(extension, 'go-service', 'skills: []\n');
+    const presets = listPresets(sources);
+    expect(presets.map((p) => [p.name, p.source])).toEqual([
+      ['go-service', 'acme'],
+      ['java-spring', 'catalog'],
+    ]);
+    expect(presets[1].description).toBe('Spring services');
+  });
+
+  it('reports invalid presets without failing the list', () => {
+    preset(extension, 'Bad_Name', '');
+    const warnings: string[] = [];
+    expect(listPresets(sources, warnings).map((p) => p.name)).toEqual(['java-spring']);
+    expect(warnings[0]).toContain('Bad_Name.yaml');
+  });
+
+  it('suggests close names for an unknown preset', () => {
+    expect(() => loadPreset('java-sprng', sources)).toThrow(/Did you mean:\n {2}java-spring/);
+  });
+
+  it('installs the listed types with their dependencies', async () => {
+    const p = loadPreset('java-spring', sources);
+    expect(presetTypes(p)).toEqual(['personas/java-dev']);
+
+    const { installed } = await installPreset(p, sources, installedRoot);
+    expect(installed).toEqual(['context/spring-boot', 'personas/java-dev']);
+    expect(existsSync(join(installedRoot, 'personas/java-dev/manifest.yaml'))).toBe(true);
+  });
+
+  it('installs nothing when a listed type is unknown', async () => {
+    preset(catalog, 'broken', 'personas: [personas/java-dev]\nskills: [skills/missing]\n');
+    await expect(installPreset(loadPreset('broken', sources), sources, installedRoot)).rejects.toThrow(
+      'Preset broken lists types no source provides: skills/missing',
+    );
+    expect(existsSync(join(installedRoot, 'personas/java-dev'))).toBe(false);
+  });
+});
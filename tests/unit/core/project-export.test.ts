@@ -0,0 +1,68 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, lstatSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { exportProject } from '../../../src/core/project-export.js';
+import { initProject, loadProject, saveProject } from '../../../src/core/linker.js';
+
+describe('plain exports', () => {
+  let root: string;
+  let installedRoot: string;
+  let project: string;
+  let out: string;
+
+  function installed(typePath: string, manifest: string, files: Record<string, string> = {}): void {
+    const dir = join(installedRoot, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), manifest);
+    for (const [name, content] of Object.entries(files)) writeFileSync(join(dir, name), content);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-export-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    project = join(root, 'project');
+    out = join(root, 'out');
+    installed(
+      'personas/java-dev',
+      'name: java-dev\ntype: persona\nversion: "1.0.0"\ndescription: Senior Java developer\ntone: direct\nconventions:\n  - Prefer records\n',
+    );
+    installed(
+      'context/spring-boot/security',
+      'name: security\ntype: context\nversion: "1.0.0"\ndescription: d\nformat: markdown\nsources:\n  - content.md\n',
+      { 'content.md': 'Use method security.\n' },
+    );
+    installed('skills/scm/git/commit', 'name: commit\ntype: skill\nversion: "1.0.0"\ndescription: Write commit messages\n');
+
+    initProject(project, ['claude-code']);
+    const config = loadProject(project);
+    config.active.personas = ['personas/java-dev'];
+    config.active.context = ['context/spring-boot/security'];
+    config.active.skills = ['skills/scm/git/commit'];
+    saveProject(project, config);
+  });
+
+  afterEach(() => rmSync(root, { recursive: true, force: true }));
+
+  it('writes a generic instructions file and context as plain files', () => {
+    const result = exportProject(project, out, installedRoot);
+    expect(result).toMatchObject({ tool: 'generic', files: ['INSTRUCTIONS.md', 'context/spring-boot--security.md'], warnings: [] });
+
+    const doc = readFileSync(join(out, 'INSTRUCTIONS.md'), 'utf-8');
+    expect(doc).toContain('Senior Java developer');
+    expect(doc).toContain('- Prefer records');
+    expect(doc).toContain('- [Security](context/spring-boot--security.md)');
+    expect(doc).toContain('- **commit**: Write commit messages');
+    expect(doc).not.toMatch(/agentx/i);
+
+    const context = join(out, 'context/spring-boot--security.md');
+    expect(lstatSync(context).isSymbolicLink()).toBe(false);
+    expect(readFileSync(context, 'utf-8')).toBe('# Security\n\nUse method security.\n');
+  });
+
+  it('rejects unknown tools and reports missing types', () => {
+    expect(() => exportProject(project, out, installedRoot, { tool: 'cursor' })).toThrow(/Unknown export tool/);
+    rmSync(join(installedRoot, 'skills'), { recursive: true });
+    expect(exportProject(project, out, installedRoot).warnings).toEqual(['Skill not found: skills/scm/git/commit']);
+  });
+});
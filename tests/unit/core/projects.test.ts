@@ -0,0 +1,62 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { recordProject, knownProjects, loadKnownProjects, syncKnownProjects } from '../../../src/core/projects.js';
+import { LOCK_FILE } from '../../../src/core/lock.js';
+import { initProject, linkTypes, sync } from '../../../src/core/linker.js';
+
+describe('known projects', () => {
+  let root: string;
+  let project: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-projects-test-${Date.now()}`);
+    project = join(root, 'project');
+    process.env.AGENTX_HOME = join(root, 'home');
+    initProject(project, []);
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('records tools and keeps the last sync across updates', () => {
+    const synced = new Date('2026-01-02T03:04:05Z');
+    recordProject(project, ['claude-code'], synced);
+    recordProject(project, ['claude-code', 'cursor']);
+    expect(knownProjects()).toEqual([{ path: project, tools: ['claude-code', 'cursor'], lastSync: synced.toISOString() }]);
+  });
+
+  it('is updated by link commands and sync', async () => {
+    await linkTypes(project, []);
+    expect(knownProjects()).toEqual([{ path: project, tools: [] }]);
+    await sync(project);
+    expect(knownProjects()[0].lastSync).toMatch(/^\d{4}-\d{2}-\d{2}T/);
+  });
+
+  it('skips projects that can't be reached without forgetting them', () => {
+    recordProject(project, []);
+    recordProject(join(root, 'elsewhere'), []);
+    expect(knownProjects().map((p) => p.path)).toEqual([project]);
+    expect(loadKnownProjects().map((p) => p.path).sort()).toEqual([join(root, 'elsewhere'), project].sort());
+  });
+
+  it('syncs the other projects when one is locked', async () => {
+    const busy = join(root, 'busy');
+    initProject(busy, []);
+    recordProject(project, []);
+    recordProject(busy, []);
+    // Held by a live process other than this one
+    const owner = { pid: process.ppid, command: 'link sync', startedAt: new Date().toISOString() };
+    writeFileSync(join(busy, '.agentx', LOCK_FILE), JSON.stringify(owner));
+
+    const results = await syncKnownProjects({ lockTimeoutMs: 0 });
+    expect(results.map((r) => [r.path, r.ok])).toEqual([
+      [busy, false],
+      [project, true],
+    ]);
+    expect(results[0].error).toMatch(/is running/);
+  });
+});
@@ -0,0 +1,71 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { diffPrompt } from '../../../src/core/prompt-diff.js';
+
+describe('diffPrompt', () => {
+  let root: string;
+  let installedRoot: string;
+  let catalog: string;
+
+  function write(base: string, typePath: string, manifest: string): void {
+    const dir = join(base, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), manifest);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-prompt-diff-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    catalog = join(root, 'catalog');
+    process.env.AGENTX_HOME = join(root, 'home');
+
+    const prompt = 'name: review\ntype: prompt\nversion: "1.0.0"\npersona: personas/reviewer\n';
+    write(installedRoot, 'prompts/review', prompt);
+    write(catalog, 'prompts/review', prompt);
+    write(
+      installedRoot,
+      'personas/reviewer',
+      'name: reviewer\ntype: persona\nversion: "1.0.0"\ndescription: Careful reviewer\ntone: terse\n',
+    );
+    write(
+      catalog,
+      'personas/reviewer',
+      'name: reviewer\ntype: persona\nversion: "1.1.0"\ndescription: Careful reviewer\ntone: friendly\n',
+    );
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('diffs the installed composition against the latest sources', () => {
+    const result = diffPrompt('prompts/review', installedRoot, [{ name: 'catalog', basePath: catalog }], {
+      render: { builtin: true },
+    });
+    expect(result.current).toContain('terse');
+    expect(result.latest).toContain('friendly');
+    expect(result.diff).toMatch(/^--- prompts\/review \(installed\)\n\+\+\+ prompts\/review \(latest\)\n@@ /);
+    expect(result.diff).toMatch(/^-.*terse/m);
+    expect(result.diff).toMatch(/^\+.*friendly/m);
+    expect(result.types).toEqual([
+      { typePath: 'prompts/review', installed: '1.0.0', latest: '1.0.0' },
+      { typePath: 'personas/reviewer', installed: '1.0.0', latest: '1.1.0' },
+    ]);
+  });
+
+  it('reports no diff when the sources match what is installed', () => {
+    const result = diffPrompt('prompts/review', installedRoot, [{ name: 'installed', basePath: installedRoot }], {
+      render: { builtin: true },
+    });
+    expect(result.diff).toBe('');
+  });
+
+  it('fails when no source provides the prompt', () => {
+    expect(() => diffPrompt('prompts/missing', installedRoot, [{ name: 'catalog', basePath: catalog }])).toThrow(
+      /No source provides prompts\/missing/,
+    );
+  });
+});
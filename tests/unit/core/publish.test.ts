@@ -0,0 +1,58 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { runPublish, publishBody, contentTypeFor, type PublishContext } from '../../../src/core/publish.js';
+
+describe('publish', () => {
+  let dir: string;
+  let ctx: PublishContext;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-publish-test-${Date.now()}`);
+    ctx = {
+      workflow: 'code-review',
+      inputs: { repo: 'api' },
+      outputs: new Map([
+        ['analyze', { stdout: '{"commits": 3}\n' }],
+        ['summarize', { stdout: 'All good.\n' }],
+      ]),
+      root: dir,
+    };
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes the previous step to a templated path', async () => {
+    const result = await runPublish({ to: `${dir}/{{workflow}}/{{inputs.repo}}.md` }, ctx);
+
+    expect(result.destination).toBe(join(dir, 'code-review', 'api.md'));
+    expect(result.contentType).toBe('text/markdown');
+    expect(readFileSync(result.destination, 'utf-8')).toBe('All good.\n');
+  });
+
+  it('refuses local destinations outside the root', async () => {
+    await expect(runPublish({ to: '../escape.md' }, ctx)).rejects.toThrow(/must be inside/);
+    await expect(runPublish({ to: `${dir}-sibling/x.md` }, ctx)).rejects.toThrow(/must be inside/);
+    await expect(runPublish({ to: '/etc/agentx-publish' }, ctx)).rejects.toThrow(/must be inside/);
+  });
+
+  it('combines several steps into one JSON document', () => {
+    const { body, json } = publishBody(['analyze', 'summarize'], ctx);
+    expect(json).toBe(true);
+    expect(JSON.parse(body)).toEqual({ analyze: { commits: 3 }, summarize: 'All good.\n' });
+  });
+
+  it('picks content types from the setting, extension, then payload', () => {
+    expect(contentTypeFor('out.bin', true, 'application/octet-stream')).toBe('application/octet-stream');
+    expect(contentTypeFor('https://x.test/r.csv?v=1', true)).toBe('text/csv');
+    expect(contentTypeFor('https://x.test/hook', true)).toBe('application/json');
+    expect(contentTypeFor('report', false)).toBe('text/plain');
+  });
+
+  it('rejects steps that have not run yet', async () => {
+    await expect(runPublish({ to: join(dir, 'x'), from: ['later'] }, ctx)).rejects.toThrow(/has not run/);
+  });
+});
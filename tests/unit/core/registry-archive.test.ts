@@ -0,0 +1,63 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, writeFileSync, mkdirSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { exportRegistries, importRegistries } from '../../../src/core/registry-archive.js';
+
+describe('registry archives', () => {
+  let home: string;
+  let prevHome: string | undefined;
+  let registry: string;
+  const typePath = 'skills/scm/git/commit';
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-registry-archive-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+
+    registry = join(home, 'userdata', 'skills', 'scm', 'git', 'commit');
+    mkdirSync(join(registry, 'state'), { recursive: true });
+    mkdirSync(join(registry, 'output'), { recursive: true });
+    writeFileSync(join(registry, 'config.yaml'), 'style: conventional\n');
+    writeFileSync(join(registry, 'state', 'last.json'), '{}');
+    writeFileSync(join(registry, 'output', 'latest.json'), '{}');
+    writeFileSync(join(registry, 'tokens.env'), 'GITHUB_TOKEN=ghp_secret\n');
+  });
+
+  afterEach(() => {
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('round-trips config and state without tokens or output', () => {
+    const archive = join(home, 'export.tar.gz');
+    expect(exportRegistries([typePath], archive)).toEqual({ skills: [typePath], secrets: 0 });
+
+    rmSync(join(home, 'userdata'), { recursive: true });
+    const result = importRegistries(archive);
+
+    expect(result).toEqual({ skills: [typePath], secrets: 0, skipped: [] });
+    expect(readFileSync(join(registry, 'config.yaml'), 'utf-8')).toBe('style: conventional\n');
+    expect(existsSync(join(registry, 'state', 'last.json'))).toBe(true);
+    expect(existsSync(join(registry, 'tokens.env'))).toBe(false);
+    expect(existsSync(join(registry, 'output'))).toBe(false);
+  });
+
+  it('keeps existing files unless overwriting', () => {
+    const archive = join(home, 'export.tar.gz');
+    exportRegistries([typePath], archive);
+    writeFileSync(join(registry, 'config.yaml'), 'style: local\n');
+
+    expect(importRegistries(archive).skipped).toEqual(['scm/git/commit/config.yaml', 'scm/git/commit/state/last.json']);
+    expect(readFileSync(join(registry, 'config.yaml'), 'utf-8')).toBe('style: local\n');
+
+    importRegistries(archive, { overwrite: true });
+    expect(readFileSync(join(registry, 'config.yaml'), 'utf-8')).toBe('style: conventional\n');
+  });
+
+  it('refuses skills without a registry and non-archives', () => {
+    expect(() => exportRegistries(['skills/none/here'], join(home, 'x.tar.gz'))).toThrow(/no registry/);
+    expect(() => importRegistries(join(home, 'missing.tar.gz'))).toThrow(/Archive not found/);
+  });
+});
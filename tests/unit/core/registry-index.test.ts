@@ -0,0 +1,157 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  discoverAllCached,
+  forgetIndexedSource,
+  searchTypes,
+  indexedDependents,
+  findDependents,
+  reverseDependencyIndex,
+} from '../../../src/core/registry.js';
+import { RegistryIndex } from '../../../src/core/registry-index.js';
+import type { Source } from '../../../src/types/registry.js';
+
+function makeManifest(dir: string, content: string): void {
+  mkdirSync(dir, { recursive: true });
+  writeFileSync(join(dir, 'manifest.yaml'), content);
+}
+
+describe('registry index', () => {
+  let testDir: string;
+  let catalogDir: string;
+  let extDir: string;
+  let indexPath: string;
+  let sources: Source[];
+
+  beforeEach(() => {
+    testDir = join(tmpdir(), `agentx-index-test-${Date.now()}`);
+    catalogDir = join(testDir, 'catalog');
+    extDir = join(testDir, 'ext');
+    indexPath = join(testDir, 'registry-index.db');
+    sources = [
+      { name: 'catalog', basePath: catalogDir },
+      { name: 'ext', basePath: extDir },
+    ];
+
+    makeManifest(join(catalogDir, 'context/spring-boot'), 'name: spring-boot\ntype: context\nversion: "1.0.0"\ndescription: Spring Boot conventions\ntags: [java, Spring]\n');
+    makeManifest(join(catalogDir, 'personas/senior-java-dev'), 'name: senior-java-dev\ntype: persona\nversion: "1.0.0"\ndescription: Java reviewer\ncontext: [context/spring-boot]\n');
+    makeManifest(
+      join(catalogDir, 'skills/scm/git/commit-analyzer'),
+      'name: commit-analyzer\ntype: skill\nversion: "2.0.0"\ndescription: Summarizes commits\nruntime: node\ntopic: scm\ncli_dependencies:\n  - name: git\n',
+    );
+    makeManifest(join(extDir, 'skills/cloud/aws/cost-report'), 'name: cost-report\ntype: skill\nversion: "0.1.0"\ndescription: 100% of spend\nruntime: node\ntopic: cloud\nvendor: aws\n');
+  });
+
+  afterEach(() => {
+    rmSync(testDir, { recursive: true, force: true });
+  });
+
+  it('filters on category, tags, topic, vendor, and CLI dependency', async () => {
+    const paths = async (filter: Parameters<typeof searchTypes>[1]) =>
+      (await searchTypes(sources, filter, { cachePath: indexPath })).map((t) => t.typePath);
+
+    expect(await paths({ category: 'skill' })).toEqual(['skills/cloud/aws/cost-report', 'skills/scm/git/commit-analyzer']);
+    expect(await paths({ tags: ['spring'] })).toEqual(['context/spring-boot']);
+    expect(await paths({ topic: 'scm' })).toEqual(['skills/scm/git/commit-analyzer']);
+    expect(await paths({ vendor: 'aws' })).toEqual(['skills/cloud/aws/cost-report']);
+    expect(await paths({ cli: 'git' })).toEqual(['skills/scm/git/commit-analyzer']);
+    expect(await paths({ text: 'JAVA' })).toEqual(['personas/senior-java-dev']);
+    expect(await paths({ text: '100%' })).toEqual(['skills/cloud/aws/cost-report']);
+    expect(await paths({ text: '%' })).toEqual(['skills/cloud/aws/cost-report']);
+  });
+
+  it('only matches the copy of a type that wins its path', async () => {
+    makeManifest(join(extDir, 'context/spring-boot'), 'name: spring-boot\ntype: context\nversion: "9.0.0"\ndescription: Shadowed copy\ntags: [shadowed]\n');
+    expect(await searchTypes(sources, { tags: ['shadowed'] }, { cachePath: indexPath })).toEqual([]);
+    const [hit] = await searchTypes(sources, { text: 'spring' }, { cachePath: indexPath });
+    expect(hit.version).toBe('1.0.0');
+  });
+
+  it('re-parses only the manifests that changed', async () => {
+    await discoverAllCached(sources, indexPath);
+    const index = RegistryIndex.open(indexPath);
+    const before = index.stamps('catalog').get('context/spring-boot');
+    const extIndexedAt = index.source('ext')!.indexedAt;
+    index.close();
+
+    makeManifest(join(catalogDir, 'context/spring-boot'), 'name: spring-boot\ntype: context\nversion: "1.1.0"\ndescription: Spring Boot 3 conventions\n');
+    const types = await discoverAllCached(sources, indexPath);
+    expect(types.find((t) => t.typePath === 'context/spring-boot')?.version).toBe('1.1.0');
+
+    const after = RegistryIndex.open(indexPath);
+    expect(after.stamps('catalog').get('context/spring-boot')).not.toBe(before);
+    expect(after.source('ext')!.indexedAt).toBe(extIndexedAt);
+    after.close();
+  });
+
+  it('drops removed and unparseable manifests', async () => {
+    await discoverAllCached(sources, indexPath);
+    rmSync(join(catalogDir, 'personas'), { recursive: true });
+    writeFileSync(join(catalogDir, 'context/spring-boot/manifest.yaml'), 'name: [unclosed\n');
+
+    const warnings: string[] = [];
+    const paths = (await discoverAllCached(sources, indexPath, warnings)).map((t) => t.typePath);
+    expect(paths).not.toContain('personas/senior-java-dev');
+    expect(paths).not.toContain('context/spring-boot');
+    expect(warnings).toHaveLength(1);
+
+    // Still reported while it stays broken, not only the first time
+    const again: string[] = [];
+    await discoverAllCached(sources, indexPath, again);
+    expect(again).toEqual(warnings);
+  });
+
+  it('answers reverse dependencies like a full manifest scan', async () => {
+    const dependents = await indexedDependents(sources, indexPath);
+    const scanned = reverseDependencyIndex(sources);
+    expect(dependents.get('context/spring-boot')?.map((t) => t.typePath)).toEqual(
+      scanned.get('context/spring-boot')?.map((t) => t.typePath),
+    );
+
+    const tree = findDependents('context/spring-boot', sources, join(testDir, 'installed'), { dependents });
+    expect(tree.children.map((c) => c.typePath)).toEqual(['personas/senior-java-dev']);
+  });
+
+  it('prunes sources that were removed', async () => {
+    await discoverAllCached(sources, indexPath);
+    forgetIndexedSource('ext', indexPath);
+    let index = RegistryIndex.open(indexPath);
+    expect(index.sources().map((s) => s.name)).toEqual(['catalog']);
+    index.close();
+
+    // A source whose directory is gone is dropped even when not listed
+    await discoverAllCached(sources, indexPath);
+    rmSync(extDir, { recursive: true });
+    await discoverAllCached([sources[0]], indexPath);
+    index = RegistryIndex.open(indexPath);
+    expect(index.sources().map((s) => s.name)).toEqual(['catalog']);
+    expect(index.types('ext')).toEqual([]);
+    index.close();
+  });
+
+  it('rebuilds an index it cannot read', async () => {
+    writeFileSync(indexPath, 'not a database');
+    expect(await discoverAllCached(sources, indexPath)).toHaveLength(4);
+  });
+
+  it('leaves a busy index alone and scans instead', async () => {
+    await discoverAllCached(sources, indexPath);
+    const open = RegistryIndex.open.bind(RegistryIndex);
+    vi.spyOn(RegistryIndex, 'open').mockImplementation((path) => {
+      if (path === indexPath) throw Object.assign(new Error('database is locked'), { code: 'ERR_SQLITE_ERROR', errcode: 5 });
+      return open(path);
+    });
+    makeManifest(join(extDir, 'prompts/new'), 'name: new\ntype: prompt\nversion: "1.0.0"\ndescription: New\n');
+
+    const paths = (await discoverAllCached(sources, indexPath)).map((t) => t.typePath);
+    expect(paths).toContain('prompts/new');
+    expect(existsSync(indexPath)).toBe(true);
+    vi.restoreAllMocks();
+    // The file on disk was not touched, so it still lacks the new type
+    const index = RegistryIndex.open(indexPath);
+    expect(index.types('ext').map((t) => t.typePath)).not.toContain('prompts/new');
+    index.close();
+  });
+});
@@ -0,0 +1,127 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import yaml from 'js-yaml';
+import { migrateSkillRegistry, describeMigration } from '../../../src/core/registry-migrate.js';
+import { initSkillRegistry } from '../../../src/core/registry.js';
+import type { ResolvedType } from '../../../src/types/registry.js';
+
+describe('registry migration', () => {
+  let root: string;
+  let skillsDir: string;
+  const accept = async () => true;
+
+  function skill(version: string, registry: string): ResolvedType {
+    const dir = join(root, 'catalog', version, 'skills/scm/commit');
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      `name: commit\ntype: skill\nversion: "${version}"\ndescription: d\nruntime: node\nregistry:\n${registry}`,
+    );
+    return { typePath: 'skills/scm/commit', manifestPath: join(dir, 'manifest.yaml'), sourceDir: dir, sourceName: 'catalog', category: 'skill' };
+  }
+
+  const registryFile = (name: string) => readFileSync(join(skillsDir, 'scm/commit', name), 'utf-8');
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-migrate-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    skillsDir = join(root, 'skills');
+    initSkillRegistry(
+      skill('1.0.0', '  tokens:\n    - name: GH_TOKEN\n    - name: OLD_URL\n    - name: LEGACY\n  config:\n    days: 30\n    legacy: true\n'),
+      skillsDir,
+    );
+    writeFileSync(join(skillsDir, 'scm/commit/tokens.env'), 'GH_TOKEN=ghp_saved\nOLD_URL=https://x\nLEGACY=1\n');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('appends new keys, carries renamed tokens, and flags removed ones', async () => {
+    const next = skill(
+      '2.0.0',
+      [
+        '  tokens:',
+        '    - name: GH_TOKEN',
+        '    - name: API_URL',
+        '      renamed_from: OLD_URL',
+        '    - name: SLACK_TOKEN',
+        '      description: Posts summaries',
+        '      required: true',
+        '  config:',
+        '    days: 30',
+        '    branch: main',
+        '',
+      ].join('\n'),
+    );
+    const m = await migrateSkillRegistry(next, skillsDir, '1.0.0');
+
+    expect(m).toMatchObject({
+      addedTokens: ['SLACK_TOKEN'],
+      renamedTokens: [{ from: 'OLD_URL', to: 'API_URL' }],
+      removedTokens: ['LEGACY'],
+      addedConfig: ['branch'],
+      removedConfig: ['legacy'],
+    });
+    const tokens = registryFile('tokens.env');
+    expect(tokens).toContain('GH_TOKEN=ghp_saved');
+    expect(tokens).toContain('API_URL=https://x');
+    expect(tokens).toContain('# Added in 2.0.0: Posts summaries\n# (required)\nSLACK_TOKEN=');
+    expect(yaml.load(registryFile('config.yaml'))).toEqual({ days: 30, legacy: true, branch: 'main' });
+
+    const lines = describeMigration(m!);
+    expect(lines.some((l) => l.includes('SLACK_TOKEN'))).toBe(true);
+    expect(lines.join('\n')).not.toContain('ghp_saved');
+  });
+
+  it('returns null when the registry already matches', async () => {
+    const same = skill('1.0.1', '  tokens:\n    - name: GH_TOKEN\n    - name: OLD_URL\n    - name: LEGACY\n  config:\n    days: 30\n    legacy: true\n');
+    expect(await migrateSkillRegistry(same, skillsDir, '1.0.0')).toBeNull();
+  });
+
+  const script = [
+    "import { readFileSync, writeFileSync } from 'node:fs';",
+    "const path = process.env.AGENTX_SKILL_REGISTRY + '/config.yaml';",
+    "writeFileSync(path, readFileSync(path, 'utf-8').replace('days: 30', 'days: 60') + `migrated_from: '${process.env.AGENTX_MIGRATE_FROM}'\\n`);",
+    "writeFileSync(process.env.AGENTX_SKILL_REGISTRY + '/seen.txt', process.env.STRAY_SECRET ?? 'unset');",
+  ].join('\n');
+  const withScript = (migrate: string) =>
+    skill('2.0.0', `  tokens:\n    - name: GH_TOKEN\n    - name: OLD_URL\n    - name: LEGACY\n  config:\n    days: 30\n    legacy: true\n  migrate: ${migrate}\n`);
+
+  it('runs an approved migration script with the skill environment and reports the keys it changed', async () => {
+    const next = withScript('migrate.mjs');
+    writeFileSync(join(next.sourceDir, 'migrate.mjs'), script);
+    process.env.STRAY_SECRET = 'not-for-scripts';
+    try {
+      const m = await migrateSkillRegistry(next, skillsDir, '1.0.0', { approve: accept });
+      expect(m?.scriptChanges).toEqual(['config.days', 'config.migrated_from']);
+      expect(yaml.load(registryFile('config.yaml'))).toMatchObject({ days: 60, migrated_from: '1.0.0' });
+      expect(registryFile('seen.txt')).toBe('unset');
+    } finally {
+      delete process.env.STRAY_SECRET;
+    }
+  });
+
+  it('skips a migration script that is not approved', async () => {
+    const next = withScript('migrate.mjs');
+    writeFileSync(join(next.sourceDir, 'migrate.mjs'), script);
+    const m = await migrateSkillRegistry(next, skillsDir, '1.0.0');
+    expect(m).toMatchObject({ scriptChanges: [], skippedScript: 'migrate.mjs' });
+    expect(describeMigration(m!).join('\n')).toMatch(/skipped migration script migrate\.mjs: not approved/);
+    expect(yaml.load(registryFile('config.yaml'))).toEqual({ days: 30, legacy: true });
+  });
+
+  it('refuses a migration script outside the skill directory', async () => {
+    const next = withScript('../../../../outside.mjs');
+    await expect(migrateSkillRegistry(next, skillsDir, '1.0.0', { approve: accept })).rejects.toThrow(/outside the skill directory/);
+  });
+
+  it('fails with the script error', async () => {
+    const next = skill('2.0.0', '  tokens:\n    - name: GH_TOKEN\n  migrate: migrate.mjs\n');
+    writeFileSync(join(next.sourceDir, 'migrate.mjs'), "console.error('bad state'); process.exit(3);");
+    await expect(migrateSkillRegistry(next, skillsDir, '1.0.0', { approve: accept })).rejects.toThrow(/migrate\.mjs failed: bad state/);
+  });
+});
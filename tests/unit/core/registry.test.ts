@@ -10,6 +10,14 @@ import {
   buildInstallPlan,
   categoryFromPath,
   nameFromPath,
+  levenshtein,
+  suggestTypePaths,
+  discoverAllCached,
+  discoverAllAsync,
+  discoverAll,
+  findDependents,
+  canonicalTypePath,
+  deprecationOf,
 } from '../../../src/core/registry.js';
 import type { Source } from '../../../src/types/registry.js';
 
@@ -103,6 +111,61 @@ topic: test
     });
   });
 
+  describe('aliases and deprecation', () => {
+    beforeEach(() => {
+      makeManifest(join(catalogDir, 'skills/scm/git/commit'), `
+name: commit
+type: skill
+version: "2.0.0"
+description: renamed
+runtime: node
+topic: scm
+aliases:
+  - skills/git/commit-analyzer
+`);
+      makeManifest(join(catalogDir, 'skills/git/old-differ'), `
+name: old-differ
+type: skill
+version: "1.0.0"
+description: retired
+runtime: node
+topic: scm
+deprecated: true
+replaced_by: skills/scm/git/commit
+`);
+    });
+
+    it('resolves a former path through aliases', () => {
+      const result = resolveType('skills/git/commit-analyzer', sources);
+      expect(result!.typePath).toBe('skills/scm/git/commit');
+      expect(result!.aliasOf).toBe('skills/git/commit-analyzer');
+    });
+
+    it('plans installs under the new path', () => {
+      const tree = buildDependencyTree('skills/git/commit-analyzer', sources, installedDir);
+      expect(tree.typePath).toBe('skills/scm/git/commit');
+      expect(flattenTree(tree).map((t) => t.typePath)).toEqual(['skills/scm/git/commit']);
+    });
+
+    it('maps installed references to their canonical path', () => {
+      const installed = join(installedDir, 'skills/scm/git/commit');
+      makeManifest(installed, 'name: commit\ntype: skill\nversion: "2.0.0"\naliases: [skills/git/commit-analyzer]\n');
+      expect(canonicalTypePath('skills/git/commit-analyzer', installedDir)).toBe('skills/scm/git/commit');
+      expect(canonicalTypePath('skills/unknown', installedDir)).toBe('skills/unknown');
+    });
+
+    it('reports deprecation and the replacement', () => {
+      expect(deprecationOf(resolveType('skills/git/old-differ', sources)!)).toEqual({
+        typePath: 'skills/git/old-differ',
+        replacedBy: 'skills/scm/git/commit',
+      });
+      expect(deprecationOf(resolveType('skills/scm/git/commit', sources)!)).toBeNull();
+
+      const found = discoverAll(sources).find((t) => t.typePath === 'skills/git/old-differ');
+      expect(found?.deprecated).toBe(true);
+    });
+  });
+
   describe('discoverTypes', () => {
     it('discovers types across categories', () => {
       makeManifest(join(catalogDir, 'skills/scm/git/commit-analyzer'), `
@@ -282,4 +345,91 @@ context:
       expect(plan.root.children.length).toBe(0);
     });
   });
+
+  describe('findDependents', () => {
+    beforeEach(() => {
+      makeManifest(join(catalogDir, 'context/spring-boot'), 'name: spring-boot\ntype: context\nversion: "1.0.0"\ndescription: d\n');
+      makeManifest(join(catalogDir, 'personas/java-dev'), 'name: java-dev\ntype: persona\nversion: "1.0.0"\ndescription: d\ncontext:\n  - context/spring-boot\n');
+      makeManifest(join(catalogDir, 'prompts/java-review'), 'name: java-review\ntype: prompt\nversion: "1.0.0"\ndescription: d\npersona: personas/java-dev\ncontext:\n  - context/spring-boot\n');
+    });
+
+    const paths = (node: { typePath: string; children: { typePath: string }[] }) =>
+      node.children.map((c) => c.typePath).sort();
+
+    it('finds direct dependents', () => {
+      const tree = findDependents('context/spring-boot', sources, installedDir);
+      expect(paths(tree)).toEqual(['personas/java-dev', 'prompts/java-review']);
+      expect(tree.children.every((c) => c.children.length === 0)).toBe(true);
+    });
+
+    it('follows dependents transitively', () => {
+      const tree = findDependents('context/spring-boot', sources, installedDir, { transitive: true });
+      const persona = tree.children.find((c) => c.typePath === 'personas/java-dev')!;
+      expect(persona.children.map((c) => c.typePath)).toEqual(['prompts/java-review']);
+    });
+  });
+
+  describe('discoverAllAsync', () => {
+    it('matches discoverAll, including source precedence', async () => {
+      const extDir = join(testDir, 'ext');
+      for (const p of ['skills/scm/git/commit-analyzer', 'context/spring-boot', 'personas/java-dev']) {
+        makeManifest(join(catalogDir, p), `name: ${p.split('/').pop()}\ntype: x\nversion: "1.0.0"\ndescription: core\n`);
+      }
+      makeManifest(join(extDir, 'context/spring-boot'), 'name: spring-boot\ntype: context\nversion: "2.0.0"\ndescription: ext\n');
+      const both = [{ name: 'ext', basePath: extDir }, ...sources];
+
+      const byPath = (a: { typePath: string }, b: { typePath: string }) =>
+        a.typePath.localeCompare(b.typePath);
+      const parallel = await discoverAllAsync(both);
+      expect([...parallel].sort(byPath)).toEqual([...discoverAll(both)].sort(byPath));
+      expect(parallel.find((t) => t.typePath === 'context/spring-boot')?.description).toBe('ext');
+    });
+  });
+
+  describe('discoverAllCached', () => {
+    it('picks up in-place manifest edits', async () => {
+      const cachePath = join(testDir, 'registry-index.db');
+      const manifest = (description: string) => `
+name: senior-java-dev
+type: persona
+version: "1.0.0"
+description: ${description}
+`;
+      makeManifest(join(catalogDir, 'personas/senior-java-dev'), manifest('before'));
+      expect((await discoverAllCached(sources, cachePath))[0].description).toBe('before');
+
+      writeFileSync(
+        join(catalogDir, 'personas/senior-java-dev/manifest.yaml'),
+        manifest('after, and longer'),
+      );
+      expect((await discoverAllCached(sources, cachePath))[0].description).toBe('after, and longer');
+    });
+  });
+
+  describe('fuzzy matching', () => {
+    const known = [
+      'skills/scm/git/commit-analyzer',
+      'skills/scm/git/branch-cleaner',
+      'personas/senior-java-dev',
+    ];
+
+    it('computes edit distance', () => {
+      expect(levenshtein('kitten', 'sitting')).toBe(3);
+      expect(levenshtein('same', 'same')).toBe(0);
+    });
+
+    it('suggests the closest type path for a typo', () => {
+      expect(suggestTypePaths('skills/scm/git/comit-analyzer', known)).toEqual([
+        'skills/scm/git/commit-analyzer',
+      ]);
+    });
+
+    it('catches a wrong category directory', () => {
+      expect(suggestTypePaths('skills/senior-java-dev', known)).toEqual(['personas/senior-java-dev']);
+    });
+
+    it('returns nothing when no path is close', () => {
+      expect(suggestTypePaths('context/kubernetes/networking', known)).toEqual([]);
+    });
+  });
 });
@@ -0,0 +1,104 @@
+import { describe, it, expect } from 'vitest';
+import { toJUnit, toSarif, verifySuites, validationSuites, parseReportFormat } from '../../../src/core/report.js';
+import type { VerifyReport } from '../../../src/core/verify.js';
+
+const report: VerifyReport = {
+  project: '/work/app',
+  passed: false,
+  checks: [
+    {
+      name: 'project',
+      title: 'Project config',
+      status: 'fail',
+      failures: [{ subject: '.agentx/project.yaml', message: 'Unknown field "prefers" <typo>', file: '.agentx/project.yaml', line: 3 }],
+    },
+    { name: 'manifests', title: 'Local type manifests', status: 'skip', detail: 'no project-local extensions', failures: [] },
+    { name: 'pins', title: 'Active types and pins', status: 'pass', failures: [] },
+  ],
+};
+
+describe('reports', () => {
+  it('writes verify checks as JUnit test cases', () => {
+    const xml = toJUnit(verifySuites(report).suites);
+    expect(xml).toContain('<testsuites name="agentx" tests="3" failures="1" skipped="1">');
+    expect(xml).toContain(
+      '<failure message="1 problem(s)" type="agentx/project">.agentx/project.yaml:3: Unknown field &quot;prefers&quot; &lt;typo&gt;</failure>',
+    );
+    expect(xml).toContain('<skipped message="no project-local extensions"/>');
+    expect(xml).toContain('<testcase classname="agentx verify" name="Active types and pins"/>');
+  });
+
+  it('writes each problem as a located SARIF result', () => {
+    const sarif = JSON.parse(toSarif(verifySuites(report).suites, verifySuites(report).rules));
+    expect(sarif.version).toBe('2.1.0');
+    expect(sarif.runs[0].tool.driver.rules.map((r: { id: string }) => r.id)).toEqual([
+      'agentx/project',
+      'agentx/manifests',
+      'agentx/pins',
+    ]);
+    expect(sarif.runs[0].results).toEqual([
+      {
+        ruleId: 'agentx/project',
+        level: 'error',
+        message: { text: 'Unknown field "prefers" <typo>' },
+        locations: [{ physicalLocation: { artifactLocation: { uri: '.agentx/project.yaml' }, region: { startLine: 3 } } }],
+      },
+    ]);
+  });
+
+  it('gives every SARIF result a location', () => {
+    const drift: VerifyReport = {
+      project: '/work/app',
+      passed: false,
+      checks: [
+        {
+          name: 'links',
+          title: 'Generated tool files',
+          status: 'fail',
+          failures: [{ subject: 'CLAUDE.md', message: 'claude-code: link sync would update this file', file: 'CLAUDE.md' }],
+        },
+        {
+          name: 'pins',
+          title: 'Active types and pins',
+          status: 'fail',
+          failures: [{ subject: 'skills/scm/git/commit-analyzer', message: 'linked but not installed' }],
+        },
+      ],
+    };
+    const { suites, rules } = verifySuites(drift);
+    const results = JSON.parse(toSarif(suites, rules)).runs[0].results;
+    expect(results).toHaveLength(2);
+    for (const r of results) expect(r.locations).toHaveLength(1);
+    expect(results.map((r: { locations: { physicalLocation: { artifactLocation: { uri: string } } }[] }) => r.locations[0].physicalLocation.artifactLocation.uri)).toEqual([
+      'CLAUDE.md',
+      '.agentx/project.yaml',
+    ]);
+    expect(results[1].message.text).toBe('skills/scm/git/commit-analyzer: linked but not installed');
+  });
+
+  it('makes validate file paths relative to the root', () => {
+    const { suites } = validationSuites(
+      [
+        {
+          source: 'corp-ext',
+          root: '/work/app/ext',
+          types: 2,
+          results: [
+            {
+              file: '/work/app/ext/skills/a/manifest.yaml',
+              issues: [{ file: '/work/app/ext/skills/a/manifest.yaml', line: 2, col: 1, path: 'name', message: 'Required', snippet: '' }],
+            },
+          ],
+        },
+      ],
+      '/work/app',
+    );
+    expect(suites[0].cases[0].problems).toEqual([
+      { message: 'Required (at name)', file: 'ext/skills/a/manifest.yaml', line: 2, col: 1 },
+    ]);
+  });
+
+  it('rejects unknown formats', () => {
+    expect(() => parseReportFormat('xml')).toThrow(/Unknown format "xml"/);
+  });
+});
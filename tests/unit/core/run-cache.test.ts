@@ -0,0 +1,75 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync, statSync, utimesSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { lookupRun, storeRun, runCacheStats, type RunCacheKey } from '../../../src/core/run-cache.js';
+
+describe('run cache', () => {
+  let root: string;
+  let workspace: string;
+  const output = { exitCode: 0, stdout: '{"commits":3}\n', stderr: '' };
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-run-cache-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    workspace = join(root, 'workspace');
+    mkdirSync(join(workspace, 'src'), { recursive: true });
+    writeFileSync(join(workspace, 'src', 'a.txt'), 'alpha');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  const key = (overrides: Partial<RunCacheKey> = {}): RunCacheKey => ({
+    skillPath: 'skills/scm/git/commit-analyzer',
+    version: '1.0.0',
+    inputs: { path: 'src' },
+    env: { GIT_TOKEN: 'abc' },
+    ...overrides,
+  });
+
+  it('misses, then hits an identical run', () => {
+    expect(lookupRun(workspace, key(), workspace)).toBeNull();
+    storeRun(workspace, key(), output, workspace);
+    expect(lookupRun(workspace, key(), workspace)).toEqual(output);
+
+    const stats = runCacheStats(workspace);
+    expect([stats.hits, stats.misses]).toEqual([1, 1]);
+  });
+
+  it('never stores failed runs', () => {
+    storeRun(workspace, key(), { ...output, exitCode: 1 }, workspace);
+    expect(lookupRun(workspace, key(), workspace)).toBeNull();
+  });
+
+  it('misses when a file in a directory input changes, even with the same size and mtime', () => {
+    storeRun(workspace, key(), output, workspace);
+    const file = join(workspace, 'src', 'a.txt');
+    const { atime, mtime } = statSync(file);
+    writeFileSync(file, 'alphA');
+    utimesSync(file, atime, mtime);
+
+    expect(lookupRun(workspace, key(), workspace)).toBeNull();
+  });
+
+  it('hits when a directory input is rewritten with the same contents', () => {
+    storeRun(workspace, key(), output, workspace);
+    const file = join(workspace, 'src', 'a.txt');
+    writeFileSync(file, 'alpha');
+    utimesSync(file, new Date(0), new Date(0));
+
+    expect(lookupRun(workspace, key(), workspace)).toEqual(output);
+  });
+
+  it('misses when the skill environment, token account, or version changes', () => {
+    storeRun(workspace, key(), output, workspace);
+
+    expect(lookupRun(workspace, key({ env: { GIT_TOKEN: 'rotated' } }), workspace)).toBeNull();
+    expect(lookupRun(workspace, key({ env: { GIT_TOKEN: 'abc', GIT_HOST: 'example.com' } }), workspace)).toBeNull();
+    expect(lookupRun(workspace, key({ account: 'work' }), workspace)).toBeNull();
+    expect(lookupRun(workspace, key({ version: '1.0.1' }), workspace)).toBeNull();
+    expect(lookupRun(workspace, key(), workspace)).toEqual(output);
+  });
+});
@@ -0,0 +1,156 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, writeFileSync, mkdirSync, rmSync, statSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  missingTokens,
+  saveToken,
+  tokensPathFor,
+  isolatedEnv,
+  withLimits,
+  selectAccount,
+  accountsFor,
+  resolveTokenValue,
+  skillCommand,
+} from '../../../src/core/runtime.js';
+import type { SkillManifest } from '../../../src/types/manifest.js';
+
+describe('runtime tokens', () => {
+  let home: string;
+  let skillDir: string;
+  let prevHome: string | undefined;
+
+  const manifest = {
+    name: 'commit',
+    type: 'skill',
+    version: '1.0.0',
+    description: 'Commit',
+    runtime: 'node',
+    topic: 'scm',
+    registry: {
+      tokens: [
+        { name: 'AGENTX_TEST_GH_TOKEN', required: true },
+        { name: 'AGENTX_TEST_OPTIONAL', required: false },
+        { name: 'AGENTX_TEST_DEFAULTED', required: true, default: 'x' },
+      ],
+    },
+  } as SkillManifest;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-runtime-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+    skillDir = join(home, 'installed', 'skills', 'scm', 'commit');
+    mkdirSync(skillDir, { recursive: true });
+    delete process.env.AGENTX_TEST_GH_TOKEN;
+  });
+
+  afterEach(() => {
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    delete process.env.AGENTX_TEST_GH_TOKEN;
+    selectAccount(null);
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('reports only required tokens without a value or default', () => {
+    expect(missingTokens(skillDir, manifest).map((t) => t.name)).toEqual(['AGENTX_TEST_GH_TOKEN']);
+
+    process.env.AGENTX_TEST_GH_TOKEN = 'from-env';
+    expect(missingTokens(skillDir, manifest)).toEqual([]);
+  });
+
+  it('saves tokens to tokens.env with owner-only permissions', () => {
+    const path = tokensPathFor(skillDir);
+    expect(path).toBe(join(home, 'userdata', 'skills', 'scm', 'commit', 'tokens.env'));
+
+    mkdirSync(join(path, '..'), { recursive: true });
+    writeFileSync(path, '# tokens\nAGENTX_TEST_GH_TOKEN=\nOTHER=1\n', { mode: 0o644 });
+    saveToken(skillDir, 'AGENTX_TEST_GH_TOKEN', 'ghp_secret');
+
+    expect(readFileSync(path, 'utf-8')).toBe('# tokens\nAGENTX_TEST_GH_TOKEN=ghp_secret\nOTHER=1\n');
+    if (process.platform !== 'win32') expect(statSync(path).mode & 0o777).toBe(0o600);
+    expect(missingTokens(skillDir, manifest)).toEqual([]);
+  });
+
+  it("reads the active account's token set over tokens.env", () => {
+    saveToken(skillDir, 'AGENTX_TEST_GH_TOKEN', 'ghp_personal');
+    saveToken(skillDir, 'AGENTX_TEST_OPTIONAL', 'shared');
+    const workFile = saveToken(skillDir, 'AGENTX_TEST_GH_TOKEN', 'ghp_work', 'work');
+    expect(workFile).toBe(tokensPathFor(skillDir, 'work'));
+    expect(workFile.endsWith('tokens.work.env')).toBe(true);
+    expect(accountsFor(skillDir)).toEqual(['work']);
+
+    expect(resolveTokenValue(skillDir, 'AGENTX_TEST_GH_TOKEN')).toEqual({ value: 'ghp_personal', from: tokensPathFor(skillDir) });
+
+    selectAccount('work');
+    expect(resolveTokenValue(skillDir, 'AGENTX_TEST_GH_TOKEN')).toEqual({ value: 'ghp_work', from: workFile });
+    // Tokens the account doesn't set fall back to tokens.env
+    expect(resolveTokenValue(skillDir, 'AGENTX_TEST_OPTIONAL')?.from).toBe(tokensPathFor(skillDir));
+
+    selectAccount('client-b');
+    expect(resolveTokenValue(skillDir, 'AGENTX_TEST_GH_TOKEN')?.value).toBe('ghp_personal');
+  });
+
+  it('refuses account names that are not [a-z0-9._-]+', () => {
+    for (const name of ['../other', 'a/b', 'Work', '']) {
+      expect(() => selectAccount(name)).toThrow(/Invalid account name/);
+      expect(() => tokensPathFor(skillDir, name || 'x y')).toThrow(/Invalid account name/);
+    }
+    expect(() => selectAccount('client.a_1-b')).not.toThrow();
+  });
+});
+
+describe('runtime isolation', () => {
+  const base = {
+    name: 'lint',
+    type: 'skill',
+    version: '1.0.0',
+    description: 'Lint',
+    runtime: 'python',
+    topic: 'code',
+    registry: { tokens: [{ name: 'LINT_TOKEN' }] },
+  } as SkillManifest;
+
+  const host = { PATH: '/usr/bin', HOME: '/home/me', LC_ALL: 'C', AWS_SECRET_ACCESS_KEY: 'x', LINT_TOKEN: 't', EXTRA: 'e' };
+
+  it('passes only allowed host variables and declared tokens', () => {
+    const env = isolatedEnv(base, { AGENTX_SKILL_PATH: '/s' }, host);
+    expect(env).toEqual({ PATH: '/usr/bin', HOME: '/home/me', LC_ALL: 'C', LINT_TOKEN: 't', AGENTX_SKILL_PATH: '/s' });
+    expect(isolatedEnv({ ...base, host_env: ['EXTRA'] }, {}, host).EXTRA).toBe('e');
+  });
+
+  it('adds no-network hints', () => {
+    const env = isolatedEnv({ ...base, network: false }, {}, host);
+    expect(env.AGENTX_NO_NETWORK).toBe('1');
+    expect(env.HTTPS_PROXY).toBe('http://127.0.0.1:9');
+  });
+
+  it('applies ulimits where supported', () => {
+    const limited = { ...base, limits: { cpu: '30s', memory: '256MB' } };
+    const linux = withLimits('python3', ['main.py'], limited, 'linux');
+    expect(linux.command).toBe('/bin/sh');
+    expect(linux.argv[1]).toBe('ulimit -t 30 && ulimit -v 262144 && exec "$0" "$@"');
+    expect(linux.argv.slice(2)).toEqual(['python3', 'main.py']);
+
+    expect(withLimits('node', ['index.mjs'], { ...limited, runtime: 'node' }, 'linux').argv[1]).toBe(
+      'ulimit -t 30 && exec "$0" "$@"',
+    );
+    expect(withLimits('python', ['main.py'], limited, 'win32')).toEqual({ command: 'python', argv: ['main.py'] });
+  });
+
+  it('passes secret inputs in the environment, not on the command line', () => {
+    const skillDir = join(tmpdir(), `agentx-secret-test-${Date.now()}`);
+    mkdirSync(skillDir, { recursive: true });
+    writeFileSync(join(skillDir, 'main.py'), '');
+    try {
+      const manifest = { ...base, inputs: [{ name: 'api-key', type: 'string', secret: true }] } as SkillManifest;
+      const proc = skillCommand(skillDir, manifest, { 'api-key': 's3cret', path: '.' });
+      expect(proc.argv.join(' ')).not.toContain('s3cret');
+      expect(proc.argv.join(' ')).toContain('"path":"."');
+      expect(proc.env.AGENTX_INPUT_API_KEY).toBe('s3cret');
+    } finally {
+      rmSync(skillDir, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,67 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, writeFileSync, mkdirSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { openSandbox, sandboxChanges, closeSandbox, networkHints } from '../../../src/core/sandbox.js';
+import type { SkillManifest } from '../../../src/types/manifest.js';
+
+describe('sandbox', () => {
+  let home: string;
+  let prevHome: string | undefined;
+  let skillDir: string;
+  let realRegistry: string;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-sandbox-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+    skillDir = join(home, 'installed', 'skills', 'scm', 'commit');
+    mkdirSync(skillDir, { recursive: true });
+    realRegistry = join(home, 'userdata', 'skills', 'scm', 'commit');
+    mkdirSync(join(realRegistry, 'state'), { recursive: true });
+    writeFileSync(join(realRegistry, 'config.yaml'), 'a: 1\n');
+    writeFileSync(join(realRegistry, 'state', 'seen.json'), '[]');
+  });
+
+  afterEach(() => {
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('isolates registry writes and reports them', () => {
+    const sandbox = openSandbox(skillDir);
+    expect(process.env.AGENTX_USERDATA).toBe(sandbox.userdata);
+
+    writeFileSync(join(sandbox.registry, 'config.yaml'), 'a: 2\n');
+    rmSync(join(sandbox.registry, 'state', 'seen.json'));
+    mkdirSync(join(sandbox.registry, 'output'));
+    writeFileSync(join(sandbox.registry, 'output', 'latest.json'), '{}');
+
+    expect(sandboxChanges(sandbox)).toEqual([
+      { path: 'config.yaml', kind: 'modified' },
+      { path: 'output/latest.json', kind: 'added' },
+      { path: 'state/seen.json', kind: 'deleted' },
+    ]);
+
+    closeSandbox(sandbox);
+    expect(process.env.AGENTX_USERDATA).toBeUndefined();
+    expect(existsSync(sandbox.userdata)).toBe(false);
+    expect(readFileSync(join(realRegistry, 'config.yaml'), 'utf-8')).toBe('a: 1\n');
+  });
+
+  it('flags network access in sources and declared CLIs', () => {
+    writeFileSync(join(skillDir, 'index.mjs'), "import https from 'node:https';\nconst r = await fetch(url);\n");
+    writeFileSync(join(skillDir, 'main.py'), 'import requests\n');
+    mkdirSync(join(skillDir, 'node_modules'));
+    writeFileSync(join(skillDir, 'node_modules', 'x.js'), 'fetch(u)');
+
+    const manifest = { cli_dependencies: [{ name: 'gh' }] } as SkillManifest;
+    expect(networkHints(skillDir, manifest)).toEqual([
+      'index.mjs:1: node:https',
+      'index.mjs:2: fetch()',
+      'main.py:1: requests',
+      'runs gh',
+    ]);
+  });
+});
@@ -0,0 +1,206 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, writeFileSync, mkdirSync, existsSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { newScaffoldData, generate, cloneType, listTemplateSets } from '../../../src/core/scaffold.js';
+
+describe('scaffold', () => {
+  let outDir: string;
+
+  beforeEach(() => {
+    outDir = join(tmpdir(), `agentx-scaffold-test-${Date.now()}`);
+  });
+
+  afterEach(() => {
+    rmSync(outDir, { recursive: true, force: true });
+  });
+
+  it('generates a python skill with a valid manifest', () => {
+    const data = newScaffoldData('lint-report', 'skill', 'quality', '', 'python');
+    const result = generate('skill', data, outDir);
+
+    expect(result.files.sort()).toEqual(['Makefile', 'main.py', 'pyproject.toml', 'skill.yaml']);
+    expect(result.warnings).toEqual([]);
+
+    const manifest = readFileSync(join(outDir, 'skill.yaml'), 'utf-8');
+    expect(manifest).toContain('runtime: python');
+    expect(manifest).not.toContain('vendor:');
+
+    const main = readFileSync(join(outDir, 'main.py'), 'utf-8');
+    expect(main).toContain('SKILL_PATH = f"{SKILL_TOPIC}/{SKILL_NAME}"');
+    expect(main).not.toContain('env_vendor');
+    expect(main).not.toContain('{{');
+  });
+
+  it('renders vendor conditionals and trims their lines', () => {
+    const data = newScaffoldData('deploy', 'skill', 'cloud', 'aws', 'python');
+    const result = generate('skill', data, outDir);
+    expect(result.warnings).toEqual([]);
+
+    const manifest = readFileSync(join(outDir, 'skill.yaml'), 'utf-8');
+    expect(manifest).toContain('topic: cloud\nvendor: aws\ncli_dependencies: []');
+
+    const main = readFileSync(join(outDir, 'main.py'), 'utf-8');
+    expect(main).toContain('SKILL_PATH = f"{SKILL_TOPIC}/{SKILL_VENDOR}/{SKILL_NAME}"');
+    expect(main).toContain('load_env(registry["env_vendor"], override=True)');
+  });
+
+  it('renders node skills without leftover directives', () => {
+    const data = newScaffoldData('commit', 'skill', 'scm', '', 'node');
+    const result = generate('skill', data, outDir);
+    expect(result.warnings).toEqual([]);
+    expect(readFileSync(join(outDir, 'index.mjs'), 'utf-8')).not.toMatch(/\{\{-?\s*(if|else|end)/);
+  });
+
+  describe('cloneType', () => {
+    let sourceDir: string;
+
+    beforeEach(() => {
+      sourceDir = join(outDir, 'source');
+      generate('skill', newScaffoldData('commit', 'skill', 'scm', 'git', 'node'), sourceDir);
+      writeFileSync(join(sourceDir, 'skill.yaml'), `${readFileSync(join(sourceDir, 'skill.yaml'), 'utf-8')}aliases:\n  - skills/old/commit\n`);
+      mkdirSync(join(sourceDir, 'state'));
+      writeFileSync(join(sourceDir, 'state', 'cache.json'), '{}');
+    });
+
+    const source = () => ({
+      typePath: 'skills/scm/git/commit',
+      manifestPath: join(sourceDir, 'skill.yaml'),
+      sourceDir,
+      sourceName: 'catalog',
+      category: 'skill' as const,
+    });
+
+    it('renames the copy in its manifest and sources', () => {
+      const data = newScaffoldData('commit-lint', 'skill', 'quality', '', 'node');
+      const result = cloneType('skill', source(), data, join(outDir, 'clone'));
+      expect(result.warnings).toEqual([]);
+
+      const manifest = readFileSync(join(outDir, 'clone', 'skill.yaml'), 'utf-8');
+      expect(manifest).toContain('name: commit-lint');
+      expect(manifest).toContain('version: 0.1.0');
+      expect(manifest).toContain('topic: quality');
+      expect(manifest).not.toContain('vendor:');
+      expect(manifest).not.toContain('aliases');
+
+      const index = readFileSync(join(outDir, 'clone', 'index.mjs'), 'utf-8');
+      expect(index).toContain("const SKILL_NAME   = 'commit-lint';");
+      expect(index).toContain("const SKILL_TOPIC  = 'quality';");
+      expect(index).toContain("const SKILL_VENDOR = '';");
+
+      const pkg = JSON.parse(readFileSync(join(outDir, 'clone', 'package.json'), 'utf-8'));
+      expect(pkg.name).toBe('@agentx/skill-quality-commit-lint');
+      expect(existsSync(join(outDir, 'clone', 'state'))).toBe(false);
+    });
+
+    it('refuses to clone across categories', () => {
+      const data = newScaffoldData('x', 'workflow', '', '', 'node');
+      expect(() => cloneType('workflow', source(), data, join(outDir, 'clone'))).toThrow(/not a workflow/);
+    });
+  });
+
+  describe('user template sets', () => {
+    let home: string;
+    let prevHome: string | undefined;
+
+    beforeEach(() => {
+      home = join(tmpdir(), `agentx-scaffold-home-${Date.now()}`);
+      prevHome = process.env.AGENTX_HOME;
+      process.env.AGENTX_HOME = home;
+
+      const set = join(home, 'templates', 'corp-node');
+      mkdirSync(join(set, 'src'), { recursive: true });
+      writeFileSync(join(set, 'scaffold.yaml'), [
+        'name: corp-node',
+        'type: skill',
+        'runtime: node',
+        'variables:',
+        '  - name: team',
+        '    required: true',
+        '  - name: license',
+        '    default: UNLICENSED',
+        '',
+      ].join('\n'));
+      writeFileSync(join(set, 'skill.yaml.tmpl'), [
+        'name: {{.Name}}',
+        'type: skill',
+        'version: {{.Version}}',
+        'description: Owned by {{.team}}',
+        'topic: {{.Topic}}',
+        'runtime: node',
+        '',
+      ].join('\n'));
+      writeFileSync(join(set, 'src', 'index.mjs.tmpl'), '// {{.license}} {{.Unknown}}\n');
+    });
+
+    afterEach(() => {
+      if (prevHome === undefined) delete process.env.AGENTX_HOME;
+      else process.env.AGENTX_HOME = prevHome;
+      rmSync(home, { recursive: true, force: true });
+    });
+
+    it('renders declared variables and keeps subdirectories', () => {
+      const data = newScaffoldData('my-tool', 'skill', 'scm', '', 'node');
+      const result = generate('skill', data, outDir, { template: 'corp-node', variables: { team: 'platform' } });
+
+      expect(result.files).toEqual(['skill.yaml', 'src/index.mjs']);
+      expect(result.warnings).toEqual([]);
+      expect(readFileSync(join(outDir, 'skill.yaml'), 'utf-8')).toContain('description: Owned by platform');
+      expect(readFileSync(join(outDir, 'src', 'index.mjs'), 'utf-8')).toBe('// UNLICENSED {{.Unknown}}\n');
+      expect(existsSync(join(outDir, 'scaffold.yaml'))).toBe(false);
+    });
+
+    it('rejects missing required and undeclared variables', () => {
+      const data = newScaffoldData('my-tool', 'skill', 'scm', '', 'node');
+      expect(() => generate('skill', data, outDir, { template: 'corp-node' })).toThrow(/--var team=/);
+      expect(() =>
+        generate('skill', data, outDir, { template: 'corp-node', variables: { team: 'a', colour: 'b' } }),
+      ).toThrow(/no variable\(s\): colour/);
+    });
+
+    it('refuses a set made for another type', () => {
+      const data = newScaffoldData('flow', 'workflow', '', '', 'node');
+      expect(() => generate('workflow', data, outDir, { template: 'corp-node' })).toThrow(/creates a skill/);
+    });
+
+    it('runs template hooks in the output directory only when asked', () => {
+      const set = join(home, 'templates', 'corp-node');
+      writeFileSync(join(set, 'scaffold.yaml'), 'name: corp-node\ntype: skill\nhooks:\n  - echo done > hook.txt\n');
+      const data = newScaffoldData('my-tool', 'skill', 'scm', '', 'node');
+
+      const skipped = generate('skill', data, outDir, { template: 'corp-node' });
+      expect(skipped.steps).toEqual([]);
+      expect(skipped.warnings.join('\n')).toMatch(/--run-hooks/);
+      expect(existsSync(join(outDir, 'hook.txt'))).toBe(false);
+
+      rmSync(outDir, { recursive: true, force: true });
+      const ran = generate('skill', data, outDir, { template: 'corp-node', runHooks: true });
+      expect(ran.steps).toEqual(['echo done > hook.txt']);
+      expect(readFileSync(join(outDir, 'hook.txt'), 'utf-8').trim()).toBe('done');
+    });
+
+    it('runs template hooks with only the allowed host variables', () => {
+      const set = join(home, 'templates', 'corp-node');
+      writeFileSync(
+        join(set, 'scaffold.yaml'),
+        'name: corp-node\ntype: skill\nhooks:\n  - echo "[$AGENTX_TEST_SECRET][$AGENTX_SCAFFOLD_DIR]" > hook.txt\n',
+      );
+      process.env.AGENTX_TEST_SECRET = 'leaked';
+      try {
+        generate('skill', newScaffoldData('my-tool', 'skill', 'scm', '', 'node'), outDir, {
+          template: 'corp-node',
+          runHooks: true,
+        });
+      } finally {
+        delete process.env.AGENTX_TEST_SECRET;
+      }
+      expect(readFileSync(join(outDir, 'hook.txt'), 'utf-8').trim()).toBe(`[][${outDir}]`);
+    });
+
+    it('lists user sets ahead of built-ins', () => {
+      const sets = listTemplateSets();
+      expect(sets[0]).toMatchObject({ name: 'corp-node', type: 'skill', builtin: false });
+      expect(sets.map((s) => s.name)).toContain('skill-python');
+    });
+  });
+});
@@ -0,0 +1,71 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { setupSkillHome, removeSkillHome, type SkillHome } from '../helpers/skill-home.js';
+import {
+  addSchedule,
+  removeSchedule,
+  loadSchedules,
+  readRunHistory,
+  tick,
+  schedulerEntry,
+} from '../../../src/core/schedule.js';
+
+describe('schedules', () => {
+  let home: SkillHome;
+  let installedRoot: string;
+  const skill = (name: string, extra: string, script: string) => home.skill(name, extra, script);
+
+  beforeEach(() => {
+    home = setupSkillHome('schedule', 'reports');
+    installedRoot = home.installedRoot;
+  });
+
+  afterEach(() => removeSkillHome(home));
+
+  it('validates and stores schedules', () => {
+    const daily = skill('daily', '', 'console.log("ok")\n');
+    const poller = skill('poller', 'mode: daemon\n', '');
+
+    expect(() => addSchedule(daily, '0 25 * * *', installedRoot)).toThrow(/Invalid hour/);
+    expect(() => addSchedule(poller, '@hourly', installedRoot)).toThrow(/daemon skill/);
+    expect(() => addSchedule('skills/reports/nope', '@hourly', installedRoot)).toThrow(/not installed/);
+
+    const s = addSchedule(daily, '0 9 * * 1-5', installedRoot, { id: 'standup', inputs: { team: 'api' } });
+    expect(loadSchedules()).toEqual([s]);
+    expect(() => addSchedule(daily, '@daily', installedRoot, { id: 'standup' })).toThrow(/already exists/);
+    expect(removeSchedule('standup')).toBe(true);
+    expect(removeSchedule('standup')).toBe(false);
+  });
+
+  it('runs schedules due since the last tick once and records the results', async () => {
+    const ok = skill('ok', '', 'console.log(JSON.parse(process.argv[3]).team)\n');
+    const bad = skill('bad', '', 'console.error("quota exceeded"); process.exit(2)\n');
+    addSchedule(ok, '0 9 * * *', installedRoot, { id: 'ok', inputs: { team: 'api' } });
+    addSchedule(bad, '*/5 * * * *', installedRoot, { id: 'bad' });
+
+    // First tick looks back one minute: only the every-5-minutes schedule is due
+    expect((await tick(installedRoot, new Date('2026-03-06T08:55:00')))?.map((r) => r.id)).toEqual(['bad']);
+    // Two hours later both are due, each run once
+    const runs = await tick(installedRoot, new Date('2026-03-06T10:55:00'));
+    expect(runs?.map((r) => [r.id, r.exitCode])).toEqual([
+      ['ok', 0],
+      ['bad', 2],
+    ]);
+    expect(runs?.[1].error).toContain('quota exceeded');
+    expect(await tick(installedRoot, new Date('2026-03-06T10:55:30'))).toEqual([]);
+
+    expect(readRunHistory('bad')).toHaveLength(2);
+    expect(readRunHistory()[1]).toMatchObject({ id: 'ok', dueAt: new Date('2026-03-06T09:00:00').toISOString() });
+  });
+
+  it('generates OS timer entries that tick every minute', () => {
+    const systemd = schedulerEntry('systemd', ['/usr/bin/node', '/opt/agentx/cli.js']);
+    expect(systemd.files.map((f) => f.path.split('/').pop())).toEqual(['agentx-scheduler.service', 'agentx-scheduler.timer']);
+    expect(systemd.files[0].content).toContain('ExecStart=/usr/bin/node /opt/agentx/cli.js scheduler tick');
+    expect(systemd.files[0].content).toContain(`Environment=AGENTX_HOME=${root}`);
+    expect(systemd.activate).toContain('enable --now agentx-scheduler.timer');
+
+    const launchd = schedulerEntry('launchd', ['/usr/bin/node', '/opt/agentx/cli.js']);
+    expect(launchd.files[0].content).toContain('<integer>60</integer>');
+    expect(schedulerEntry('windows', ['node', 'C:\\agentx\\cli.js']).activate).toMatch(/^schtasks \/Create \/SC MINUTE/);
+  });
+});
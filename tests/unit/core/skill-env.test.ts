@@ -0,0 +1,72 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { resolveSkillEnv, redactEnv, formatEnv } from '../../../src/core/skill-env.js';
+import { selectAccount } from '../../../src/core/runtime.js';
+
+describe('skill environment', () => {
+  let home: string;
+  let installedRoot: string;
+  let registry: string;
+  let prevHome: string | undefined;
+
+  const byKey = (typePath: string) =>
+    Object.fromEntries(resolveSkillEnv(typePath, installedRoot).map((v) => [v.key, v]));
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-skill-env-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+    installedRoot = join(home, 'installed');
+    const skillDir = join(installedRoot, 'skills', 'scm', 'commit');
+    mkdirSync(skillDir, { recursive: true });
+    writeFileSync(
+      join(skillDir, 'manifest.yaml'),
+      'name: commit\ntype: skill\nversion: "1.0.0"\ndescription: d\nruntime: node\nvendor: acme\nnetwork: false\n' +
+        'registry:\n  tokens:\n    - name: GH_TOKEN\n',
+    );
+    mkdirSync(join(home, 'userdata', 'env'), { recursive: true });
+    writeFileSync(join(home, 'userdata', 'env', 'default.env'), 'REGION=us-east-1\nGH_TOKEN=from-default\n');
+    writeFileSync(join(home, 'userdata', 'env', 'acme.env'), 'REGION=eu-west-1\n');
+    registry = join(home, 'userdata', 'skills', 'scm', 'commit');
+    mkdirSync(registry, { recursive: true });
+    writeFileSync(join(registry, 'tokens.env'), 'GH_TOKEN=ghp_personal1\n');
+    writeFileSync(join(registry, 'tokens.work.env'), 'GH_TOKEN=ghp_work1234\n');
+  });
+
+  afterEach(() => {
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    selectAccount(null);
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('layers shared env files, registry tokens, and network hints', () => {
+    const env = byKey('skills/scm/commit');
+    expect(env.REGION).toMatchObject({ value: 'eu-west-1', from: join(home, 'userdata', 'env', 'acme.env') });
+    expect(env.GH_TOKEN).toMatchObject({ value: 'ghp_personal1', from: join(registry, 'tokens.env') });
+    expect(env.AGENTX_SKILL_REGISTRY).toMatchObject({ value: registry, from: 'agentx' });
+    expect(env.AGENTX_NO_NETWORK).toMatchObject({ value: '1', from: 'network: false' });
+    expect(env.PATH?.from).toBe('host');
+  });
+
+  it("uses the selected account's token set", () => {
+    selectAccount('work');
+    expect(byKey('skills/scm/commit').GH_TOKEN.value).toBe('ghp_work1234');
+  });
+
+  it('masks secrets and formats for shells and env files', () => {
+    const vars = redactEnv([
+      { key: 'GH_TOKEN', value: 'ghp_personal1', from: 'x' },
+      { key: 'GREETING', value: "it's here", from: 'x' },
+    ]);
+    expect(formatEnv(vars, 'shell')).toBe("export GH_TOKEN='ghp_***'\nexport GREETING='it'\\''s here'\n");
+    expect(formatEnv(vars, 'dotenv')).toBe('GH_TOKEN="ghp_***"\nGREETING="it\'s here"\n');
+    expect(JSON.parse(formatEnv(vars, 'json'))).toEqual({ GH_TOKEN: 'ghp_***', GREETING: "it's here" });
+  });
+
+  it('rejects types that are not installed skills', () => {
+    expect(() => resolveSkillEnv('skills/scm/missing', installedRoot)).toThrow('Skill not installed: skills/scm/missing');
+  });
+});
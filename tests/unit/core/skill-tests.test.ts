@@ -0,0 +1,36 @@
+import { describe, it, expect } from 'vitest';
+import { checkExpectations, jsonMismatch } from '../../../src/core/skill-tests.js';
+
+describe('skill tests', () => {
+  it('matches JSON by the expected keys only', () => {
+    const actual = { summary: { total: 3, authors: ['a', 'b'] }, extra: true };
+    expect(jsonMismatch({ summary: { total: 3 } }, actual)).toBeNull();
+    expect(jsonMismatch({ summary: { authors: ['a'] } }, actual)).toMatch(/^\$\.summary\.authors:/);
+    expect(jsonMismatch({ summary: { total: 4 } }, actual)).toBe('$.summary.total: expected 4, got 3');
+  });
+
+  it('defaults to expecting a zero exit code', () => {
+    expect(checkExpectations({ name: 'ok' }, 0, '')).toEqual([]);
+    expect(checkExpectations({ name: 'crash' }, 2, '')).toEqual(['exit code 2, expected 0']);
+  });
+
+  it('reports each failed check', () => {
+    const failures = checkExpectations(
+      { name: 'report', expect: { contains: ['total'], matches: '^done$', stdout: 'one\ntwo' } },
+      0,
+      'one\nthree\n',
+    );
+    expect(failures).toEqual([
+      'stdout differs (- expected, + actual):',
+      '   one',
+      '  -two',
+      '  +three',
+      'stdout does not contain "total"',
+      'stdout does not match /^done$/',
+    ]);
+  });
+
+  it('fails JSON expectations on non-JSON output', () => {
+    expect(checkExpectations({ name: 'j', expect: { json: { a: 1 } } }, 0, 'nope')).toEqual(['stdout is not JSON']);
+  });
+});
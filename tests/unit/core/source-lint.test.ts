@@ -0,0 +1,77 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { validateSource } from '../../../src/core/source-lint.js';
+import type { Source } from '../../../src/types/registry.js';
+
+describe('source validation', () => {
+  let root: string;
+  let catalog: Source;
+  let ext: Source;
+
+  function type(base: string, typePath: string, body: string, file = 'manifest.yaml'): void {
+    mkdirSync(join(base, typePath), { recursive: true });
+    writeFileSync(join(base, typePath, file), `name: ${typePath.split('/').pop()}\nversion: "1.0.0"\ndescription: d\n${body}`);
+  }
+
+  const messages = (v: ReturnType<typeof validateSource>) => v.results.flatMap((r) => r.issues.map((i) => i.message));
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-source-lint-test-${Date.now()}`);
+    catalog = { name: 'catalog', basePath: join(root, 'catalog') };
+    ext = { name: 'acme', basePath: join(root, 'acme') };
+    type(catalog.basePath, 'context/spring-boot', 'type: context\nformat: markdown\nsources: [content.md]\n');
+    mkdirSync(ext.basePath, { recursive: true });
+  });
+
+  afterEach(() => rmSync(root, { recursive: true, force: true }));
+
+  it('passes a source whose references resolve in earlier sources', () => {
+    type(ext.basePath, 'personas/java-dev', 'type: persona\ncontext:\n  - context/spring-boot\n');
+    const v = validateSource(ext, [catalog, ext]);
+    expect(v).toMatchObject({ source: 'acme', types: 1, results: [] });
+  });
+
+  it('reports schema errors, dangling references, and type mismatches by file', () => {
+    type(ext.basePath, 'personas/java-dev', 'type: persona\ncontxt: []\ncontext:\n  - context/missing\n');
+    type(ext.basePath, 'skills/scm/commit', 'type: workflow\nsteps: []\n');
+    const v = validateSource(ext, [catalog, ext]);
+
+    expect(v.results.map((r) => r.file)).toEqual([
+      join(ext.basePath, 'personas/java-dev/manifest.yaml'),
+      join(ext.basePath, 'skills/scm/commit/manifest.yaml'),
+    ]);
+    const persona = v.results[0].issues;
+    expect(persona.map((i) => i.message)).toEqual([
+      'Unknown field "contxt". Did you mean "context"?',
+      'Unresolved reference context/missing: no source provides it',
+    ]);
+    expect(persona[0].warning).toBe(true);
+    expect(persona[1].line).toBe(7);
+    expect(messages(v)).toContain('Manifest type "workflow" does not match its directory (skills/scm/commit)');
+  });
+
+  it('detects duplicate manifests, colliding aliases, and shadowed types', () => {
+    type(ext.basePath, 'context/spring-boot', 'type: context\nformat: markdown\nsources: [content.md]\n');
+    type(ext.basePath, 'context/java', 'type: context\nformat: markdown\nsources: [a.md]\naliases: [context/jdk]\n');
+    type(ext.basePath, 'context/kotlin', 'type: context\nformat: markdown\nsources: [a.md]\naliases: [context/jdk]\n');
+    type(ext.basePath, 'context/java', 'type: context\nformat: markdown\nsources: [a.md]\naliases: [context/jdk]\n', 'context.yaml');
+
+    const msgs = messages(validateSource(ext, [catalog, ext]));
+    expect(msgs.some((m) => m.startsWith('Duplicate manifest for context/java;'))).toBe(true);
+    expect(msgs).toContain('Alias context/jdk is also claimed by context/java');
+    expect(msgs.some((m) => m.startsWith('context/spring-boot is also provided by catalog, which shadows it'))).toBe(true);
+  });
+
+  it('accepts shadowing declared in extension.yaml and checks its merge entries', () => {
+    type(ext.basePath, 'context/spring-boot', 'type: context\nformat: markdown\nsources: [content.md]\n');
+    writeFileSync(
+      join(ext.basePath, 'extension.yaml'),
+      'name: acme\nmerge:\n  context/spring-boot: append\n  context/gone: override\n',
+    );
+    expect(messages(validateSource(ext, [catalog, ext]))).toEqual([
+      'merge lists context/gone, which this source does not provide',
+    ]);
+  });
+});
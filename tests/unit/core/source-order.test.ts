@@ -0,0 +1,87 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { orderSources } from '../../../src/core/extension.js';
+import { trustProjectExtension } from '../../../src/core/trust.js';
+import { resolveType, discoverTypes, explainResolution } from '../../../src/core/registry.js';
+import type { Source } from '../../../src/types/registry.js';
+
+describe('source order', () => {
+  let root: string;
+  let raw: Source[];
+
+  function context(source: string, typePath: string): void {
+    const dir = join(root, source, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), `name: x\ntype: context\nversion: "1.0.0"\ndescription: d\nformat: markdown\nsources: [a.md]\n`);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-source-order-test-${Date.now()}`);
+    raw = ['zeta-ext', 'catalog', 'corp-ext'].map((name) => ({ name, basePath: join(root, name) }));
+    for (const s of raw) context(s.name, 'context/security/oauth');
+    context('catalog', 'context/security/csrf');
+    context('zeta-ext', 'context/security/csrf');
+  });
+
+  afterEach(() => rmSync(root, { recursive: true, force: true }));
+
+  it('breaks ties with the catalog first, then extensions by name', () => {
+    expect(orderSources(raw, null).map((s) => s.name)).toEqual(['catalog', 'corp-ext', 'zeta-ext']);
+  });
+
+  it('orders by resolution position, overridden by explicit priorities', () => {
+    const sources = orderSources(raw, {
+      resolution: ['core', 'corp-ext', 'zeta-ext'],
+      extensions: [{ name: 'corp-ext', priority: 5 }],
+    });
+    expect(sources.map((s) => [s.name, s.priority])).toEqual([
+      ['corp-ext', 5],
+      ['zeta-ext', 2],
+      ['catalog', 0],
+    ]);
+    expect(explainResolution('context/security/oauth', sources).reason).toBe(
+      'corp-ext has the highest priority (5) of the 3 sources that provide it',
+    );
+  });
+
+  it('pins matching type paths to the preferred source', () => {
+    const sources = orderSources(raw, { prefer: { 'context/security/*': 'zeta-ext' } });
+    expect(resolveType('context/security/oauth', sources)?.sourceName).toBe('zeta-ext');
+    expect(discoverTypes(sources).map((t) => [t.typePath, t.sourceName]).sort()).toEqual([
+      ['context/security/csrf', 'zeta-ext'],
+      ['context/security/oauth', 'zeta-ext'],
+    ]);
+
+    const r = explainResolution('context/security/oauth', sources);
+    expect(r.candidates[0]).toMatchObject({ source: 'zeta-ext', pinnedBy: 'context/security/*', provides: true });
+    expect(r.reason).toBe('pinned to zeta-ext by prefer: context/security/*');
+  });
+
+  it('ignores prefer pins to an untrusted project-local extension', () => {
+    process.env.AGENTX_HOME = join(root, 'home');
+    context('cloned', 'context/security/oauth');
+    const local: Source = { name: 'cloned', basePath: join(root, 'cloned'), local: true };
+    const prefer = { 'context/security/*': 'cloned' };
+    try {
+      expect(resolveType('context/security/oauth', orderSources([...raw, local], { prefer }))?.sourceName).toBe('catalog');
+
+      trustProjectExtension(local.basePath);
+      expect(resolveType('context/security/oauth', orderSources([...raw, local], { prefer }))?.sourceName).toBe('cloned');
+    } finally {
+      delete process.env.AGENTX_HOME;
+    }
+  });
+
+  it('explains ties and missing types', () => {
+    const sources = orderSources(raw, null);
+    expect(explainResolution('context/security/oauth', sources).reason).toBe(
+      'catalog, corp-ext, zeta-ext tie at priority 0; the catalog comes first, then extensions by name',
+    );
+    expect(explainResolution('context/security/none', sources)).toMatchObject({
+      resolved: null,
+      reason: 'No source provides context/security/none',
+    });
+  });
+});
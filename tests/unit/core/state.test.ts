@@ -0,0 +1,54 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync, mkdirSync, rmSync, existsSync, utimesSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { listState, readState, clearState, stateOverLimits } from '../../../src/core/state.js';
+
+describe('skill state', () => {
+  let home: string;
+  let prevHome: string | undefined;
+  let state: string;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-state-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+    state = join(home, 'userdata', 'skills', 'scm', 'commit', 'state');
+    mkdirSync(join(state, 'cache'), { recursive: true });
+    writeFileSync(join(state, 'seen.json'), '[1,2,3]');
+    writeFileSync(join(state, 'cache', 'old.bin'), 'x'.repeat(100));
+    const longAgo = new Date(Date.now() - 10 * 86_400_000);
+    utimesSync(join(state, 'cache', 'old.bin'), longAgo, longAgo);
+  });
+
+  afterEach(() => {
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('lists and reads state files', () => {
+    const files = listState('skills/scm/commit');
+    expect(files.map((f) => [f.path, f.bytes])).toEqual([['cache/old.bin', 100], ['seen.json', 7]]);
+    expect(readState('skills/scm/commit', 'seen.json')).toBe('[1,2,3]');
+    expect(() => readState('skills/scm/commit', '../tokens.env')).toThrow(/outside/);
+  });
+
+  it('clears only files older than the cutoff', () => {
+    const removed = clearState('skills/scm/commit', { olderThanMs: 7 * 86_400_000 });
+    expect(removed.map((f) => f.path)).toEqual(['cache/old.bin']);
+    expect(existsSync(join(state, 'cache', 'old.bin'))).toBe(false);
+    expect(existsSync(join(state, 'seen.json'))).toBe(true);
+  });
+
+  it('reports skills over their declared cap', () => {
+    const skillDir = join(home, 'installed', 'skills', 'scm', 'commit');
+    mkdirSync(skillDir, { recursive: true });
+    writeFileSync(
+      join(skillDir, 'skill.yaml'),
+      'name: commit\ntype: skill\nversion: 1.0.0\ndescription: d\nruntime: node\nregistry:\n  state_max_size: 64B\n',
+    );
+    const over = stateOverLimits(join(home, 'installed'));
+    expect(over).toEqual([{ typePath: 'skills/scm/commit', bytes: 107, limit: 64 }]);
+  });
+});
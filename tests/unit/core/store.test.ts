@@ -0,0 +1,118 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, statSync, chmodSync, existsSync, utimesSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  storeType,
+  materialize,
+  listSnapshots,
+  readCurrent,
+  diffFiles,
+  objectPath,
+  removeTree,
+} from '../../../src/core/store.js';
+
+describe('store', () => {
+  let root: string;
+  let installedRoot: string;
+  const typePath = 'context/test/sample';
+
+  function writeSource(name: string, files: Record<string, string>): string {
+    const dir = join(root, 'src', name);
+    for (const [rel, content] of Object.entries(files)) {
+      mkdirSync(join(dir, rel, '..'), { recursive: true });
+      writeFileSync(join(dir, rel), content);
+    }
+    return dir;
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-store-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('shares unchanged files between versions', () => {
+    const v1 = storeType(typePath, writeSource('v1', { 'a.md': 'same', 'b.md': 'one' }), '1.0.0');
+    const v2 = storeType(typePath, writeSource('v2', { 'a.md': 'same', 'b.md': 'two' }), '2.0.0');
+
+    expect(v1.files[0].object).toBe(v2.files[0].object);
+    expect(v1.files[1].object).not.toBe(v2.files[1].object);
+    expect(listSnapshots(typePath).map((s) => s.version)).toEqual(['1.0.0', '2.0.0']);
+  });
+
+  it('materializes and switches versions in place', () => {
+    const v1 = storeType(typePath, writeSource('v1', { 'a.md': 'same', 'sub/b.md': 'one' }), '1.0.0');
+    const v2 = storeType(typePath, writeSource('v2', { 'a.md': 'same', 'sub/b.md': 'two' }), '2.0.0');
+    const dir = join(installedRoot, typePath);
+
+    materialize(v2, installedRoot);
+    expect(readFileSync(join(dir, 'sub/b.md'), 'utf-8')).toBe('two');
+    expect(readCurrent(typePath)).toBe('2.0.0');
+
+    const inode = statSync(join(dir, 'a.md')).ino;
+    materialize(v1, installedRoot);
+    expect(readFileSync(join(dir, 'sub/b.md'), 'utf-8')).toBe('one');
+    expect(statSync(join(dir, 'a.md')).ino).toBe(inode);
+    expect(readCurrent(typePath)).toBe('1.0.0');
+  });
+
+  it('re-reads only files whose size or mtime changed on reinstall', () => {
+    const dir = writeSource('v1', { 'a.md': 'aaa', 'b.md': 'bbb' });
+    const v1 = storeType(typePath, dir, '1.0.0');
+    materialize(v1, installedRoot);
+
+    // Same size and mtime: kept without reading, as rsync's quick check does
+    const { atime, mtime } = statSync(join(dir, 'a.md'));
+    writeFileSync(join(dir, 'a.md'), 'AAA');
+    utimesSync(join(dir, 'a.md'), atime, mtime);
+    writeFileSync(join(dir, 'b.md'), 'changed');
+    writeFileSync(join(dir, 'c.md'), 'new');
+
+    const v2 = storeType(typePath, dir, '1.0.0');
+    const byPath = Object.fromEntries(v2.files.map((f) => [f.path, f.object]));
+    expect(byPath['a.md']).toBe(v1.files[0].object);
+    expect(byPath['b.md']).not.toBe(v1.files[1].object);
+    expect(diffFiles(v1.files, v2.files)).toEqual({ added: ['c.md'], updated: ['b.md'], removed: [], unchanged: 1 });
+  });
+
+  it.skipIf(process.platform === 'win32')('reports a file made executable as updated', () => {
+    const dir = writeSource('v1', { 'run.sh': 'echo hi' });
+    const v1 = storeType(typePath, dir, '1.0.0');
+    materialize(v1, installedRoot);
+
+    // chmod leaves size and mtime alone, so the quick check must see the mode
+    chmodSync(join(dir, 'run.sh'), 0o755);
+    const v2 = storeType(typePath, dir, '1.0.0');
+    expect(v2.files[0].object).toBe(`${v1.files[0].object}x`);
+    expect(diffFiles(v1.files, v2.files)).toEqual({ added: [], updated: ['run.sh'], removed: [], unchanged: 0 });
+  });
+
+  it('reports removed files', () => {
+    const v1 = storeType(typePath, writeSource('v1', { 'a.md': 'a', 'old.md': 'x' }), '1.0.0');
+    const v2 = storeType(typePath, writeSource('v2', { 'a.md': 'a' }), '2.0.0');
+    expect(diffFiles(v1.files, v2.files)).toEqual({ added: [], updated: [], removed: ['old.md'], unchanged: 1 });
+  });
+
+  it.skipIf(process.platform === 'win32')('removes read-only links as Windows needs and keeps objects read-only', () => {
+    const v1 = storeType(typePath, writeSource('v1', { 'a.md': 'a', 'sub/b.md': 'b' }), '1.0.0');
+    materialize(v1, installedRoot);
+
+    // Windows refuses to unlink read-only files; take its code path here
+    const platform = Object.getOwnPropertyDescriptor(process, 'platform')!;
+    Object.defineProperty(process, 'platform', { value: 'win32' });
+    try {
+      removeTree(join(installedRoot, typePath));
+    } finally {
+      Object.defineProperty(process, 'platform', platform);
+    }
+
+    expect(existsSync(join(installedRoot, typePath))).toBe(false);
+    for (const f of v1.files) expect(statSync(objectPath(f.object)).mode & 0o777).toBe(0o444);
+  });
+});
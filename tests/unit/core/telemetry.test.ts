@@ -0,0 +1,56 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync, existsSync, mkdirSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import * as settings from '../../../src/config/settings.js';
+import { recordUsage, readStats, telemetryMode, type UsageEvent } from '../../../src/core/telemetry.js';
+
+function event(command: string, durationMs: number, success = true): UsageEvent {
+  return { command, durationMs, success, version: 'dev', platform: 'linux', at: new Date().toISOString() };
+}
+
+describe('telemetry', () => {
+  let home: string;
+  let prevHome: string | undefined;
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-telemetry-test-${Date.now()}`);
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = home;
+    mkdirSync(home, { recursive: true });
+    settings.init(join(home, 'config.yaml'));
+  });
+
+  afterEach(() => {
+    settings.init('');
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('records nothing unless opted in', () => {
+    expect(telemetryMode()).toBe('off');
+    recordUsage(event('install', 100));
+    expect(existsSync(join(home, 'stats.json'))).toBe(false);
+  });
+
+  it('aggregates commands and skills locally', () => {
+    settings.set('telemetry.local', true);
+    recordUsage(event('run', 100), 'skills/scm/commit');
+    recordUsage(event('run', 300, false), 'skills/scm/commit');
+    recordUsage(event('install', 50));
+
+    const stats = readStats();
+    expect(stats.commands.run).toEqual({ count: 2, failures: 1, totalMs: 400, maxMs: 300 });
+    expect(stats.skills['skills/scm/commit'].count).toBe(2);
+    expect(existsSync(join(home, 'telemetry-outbox.jsonl'))).toBe(false);
+  });
+
+  it('queues anonymous events when enabled', () => {
+    settings.set('telemetry.enabled', true);
+    expect(recordUsage(event('run', 100), 'skills/scm/commit')).toBe(1);
+    const queued = readFileSync(join(home, 'telemetry-outbox.jsonl'), 'utf-8');
+    expect(queued).toContain('"command":"run"');
+    expect(queued).not.toContain('scm/commit');
+  });
+});
@@ -0,0 +1,55 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { renderTemplateType, templateFile } from '../../../src/core/template.js';
+
+describe('renderTemplateType', () => {
+  let dir: string;
+
+  function template(body: string, file = 'template.hbs'): void {
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      `name: greeting
+type: template
+version: "1.0.0"
+description: d
+format: handlebars
+variables:
+  - name: name
+    required: true
+  - name: greeting
+    default: Hello
+  - name: signature`,
+    );
+    writeFileSync(join(dir, file), body);
+  }
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-template-test-${Date.now()}`);
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('renders variables, defaults, data, and helpers', () => {
+    template('{{greeting}}, {{name}}!{{#each items}} {{this}}{{/each}} {{upper name}}{{#if signature}} -- {{signature}}{{/if}}');
+    const out = renderTemplateType(dir, { name: 'Ada', items: ['a', 'b'] });
+    expect(out).toBe('Hello, Ada! a b ADA');
+  });
+
+  it('fails on required variables and fields without a value', () => {
+    template('{{name}} {{missing}}');
+    expect(() => renderTemplateType(dir, {})).toThrow(/requires: --var name=\.\.\./);
+    expect(() => renderTemplateType(dir, { name: 'Ada' })).toThrow(/uses \{\{missing\}\}/);
+  });
+
+  it('finds the only .hbs file when there is no template.hbs', () => {
+    template('{{name}}', 'readme.hbs');
+    expect(templateFile(dir)).toBe(join(dir, 'readme.hbs'));
+    writeFileSync(join(dir, 'other.hbs'), '');
+    expect(() => templateFile(dir)).toThrow(/Several \.hbs files/);
+  });
+});
@@ -0,0 +1,124 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import yaml from 'js-yaml';
+import { parseInstructions, importToolFiles, wireProject } from '../../../src/core/tool-import.js';
+import { validateManifestFile } from '../../../src/core/manifest.js';
+import { initProject, loadProject } from '../../../src/core/linker.js';
+import { buildSources } from '../../../src/core/extension.js';
+import { resolveType } from '../../../src/core/registry.js';
+
+const CLAUDE_MD = `# Acme API
+
+You are a senior Kotlin developer on the Acme API.
+
+- Prefer immutable data classes
+- Never log tokens
+
+## Build & Test
+
+Run \`./gradlew check\`.
+
+\`\`\`bash
+## not a heading
+./gradlew test
+\`\`\`
+
+## Code Style
+
+### Naming
+Use camelCase.
+`;
+
+describe('parseInstructions', () => {
+  it('splits under a single document title and keeps subsections and code', () => {
+    const parsed = parseInstructions(CLAUDE_MD);
+    expect(parsed.title).toBe('Acme API');
+    expect(parsed.preamble).toContain('You are a senior Kotlin developer');
+    expect(parsed.sections.map((s) => s.title)).toEqual(['Build & Test', 'Code Style']);
+    expect(parsed.sections[0].body).toContain('## not a heading');
+    expect(parsed.sections[1].body).toBe('### Naming\nUse camelCase.');
+  });
+
+  it('treats plain rules as one section after a persona paragraph', () => {
+    const parsed = parseInstructions('You are an expert in React.\n\nUse hooks.\nAvoid classes.\n');
+    expect(parsed).toEqual({
+      title: null,
+      preamble: 'You are an expert in React.',
+      sections: [{ title: 'Rules', body: 'Use hooks.\nAvoid classes.' }],
+    });
+  });
+});
+
+describe('importToolFiles', () => {
+  let root: string;
+  let project: string;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-import-test-${Date.now()}`);
+    project = join(root, 'acme-api');
+    mkdirSync(join(project, '.github'), { recursive: true });
+    process.env.AGENTX_HOME = join(root, 'home');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('writes valid context, persona, and preset types into a local extension', () => {
+    writeFileSync(join(project, 'CLAUDE.md'), CLAUDE_MD);
+    writeFileSync(join(project, '.github/copilot-instructions.md'), '## Build & Test\n\nRun `./gradlew check`.\n\n## Reviews\n\nTwo approvals.\n');
+    // The persona paragraph repeats CLAUDE.md's; the bullets become rules
+    writeFileSync(join(project, '.cursorrules'), 'You are a senior Kotlin developer on the Acme API.\n\n- Prefer immutable data classes\n- Never log tokens\n');
+
+    const result = importToolFiles(project);
+    expect(result).toMatchObject({
+      extension: 'acme-api-local',
+      persona: 'personas/acme-api',
+      tools: ['claude-code', 'copilot'],
+    });
+    // Build & Test differs (the code block), so each tool keeps its own
+    expect(result.context).toEqual([
+      'context/acme-api/build-test-claude-code',
+      'context/acme-api/build-test-copilot',
+      'context/acme-api/code-style',
+      'context/acme-api/reviews',
+      'context/acme-api/rules',
+    ]);
+
+    const ext = join(project, '.agentx/extensions/acme-api-local');
+    const persona = join(ext, 'personas/acme-api/persona.yaml');
+    expect(validateManifestFile(persona, { strict: true })).toEqual([]);
+    expect(yaml.load(readFileSync(persona, 'utf-8'))).toMatchObject({
+      description: 'You are a senior Kotlin developer on the Acme API.',
+      conventions: ['Prefer immutable data classes', 'Never log tokens'],
+    });
+    const context = join(ext, 'context/acme-api/reviews');
+    expect(validateManifestFile(join(context, 'context.yaml'), { strict: true })).toEqual([]);
+    expect(readFileSync(join(context, 'content.md'), 'utf-8')).toBe('# Reviews\n\nTwo approvals.\n');
+    expect(yaml.load(readFileSync(join(ext, 'presets/acme-api-local.yaml'), 'utf-8'))).toMatchObject({
+      personas: ['personas/acme-api'],
+      tools: ['claude-code', 'copilot'],
+    });
+    expect(existsSync(join(project, '.agentx/imported/.cursorrules'))).toBe(true);
+
+    expect(() => importToolFiles(project)).toThrow(/already exists/);
+  });
+
+  it('makes the extension a source once the project is initialized', () => {
+    writeFileSync(join(project, 'CLAUDE.md'), CLAUDE_MD);
+    importToolFiles(project, { name: 'legacy' });
+    initProject(project, ['claude-code']);
+    wireProject(project, 'legacy');
+
+    expect(loadProject(project).extensions).toEqual([{ name: 'legacy' }]);
+    expect(resolveType('context/acme-api/code-style', buildSources(project))?.sourceName).toBe('legacy');
+  });
+
+  it('skips generated files and reports nothing to import', () => {
+    writeFileSync(join(project, '.github/copilot-instructions.md'), '<!-- Generated by agentx 1.0.0. Regenerate with `agentx link sync`. -->\n\n# x\n');
+    expect(() => importToolFiles(project)).toThrow(/No AI tool files to import.*generated by agentx/);
+  });
+});
@@ -0,0 +1,94 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync, readdirSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { InstallTransaction, installAll, InstallError } from '../../../src/core/transaction.js';
+import { installType } from '../../../src/core/registry.js';
+import { readCurrent } from '../../../src/core/store.js';
+import type { ResolvedType } from '../../../src/types/registry.js';
+
+describe('InstallTransaction', () => {
+  let root: string;
+  let installedRoot: string;
+  let skillsDir: string;
+
+  function source(typePath: string, version: string, extra = ''): ResolvedType {
+    const dir = join(root, 'catalog', version, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      `name: ${typePath.split('/').pop()}\ntype: skill\nversion: "${version}"\ndescription: d\n${extra}`,
+    );
+    return { typePath, manifestPath: join(dir, 'manifest.yaml'), sourceDir: dir, sourceName: 'catalog', category: 'skill' };
+  }
+
+  const manifestOf = (typePath: string) => readFileSync(join(installedRoot, typePath, 'manifest.yaml'), 'utf-8');
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-tx-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    skillsDir = join(root, 'skills');
+    process.env.AGENTX_HOME = join(root, 'home');
+    installType(source('skills/scm/commit', '1.0.0'), installedRoot);
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('keeps every type on commit', () => {
+    const tx = new InstallTransaction(installedRoot);
+    tx.install(source('skills/scm/commit', '2.0.0'));
+    tx.install(source('skills/scm/review', '2.0.0'));
+    tx.commit();
+
+    expect(manifestOf('skills/scm/commit')).toContain('2.0.0');
+    expect(readCurrent('skills/scm/review')).toBe('2.0.0');
+    expect(readdirSync(join(installedRoot, 'skills', 'scm')).sort()).toEqual(['commit', 'review']);
+  });
+
+  it('reports how the files differ from the version replaced', () => {
+    const tx = new InstallTransaction(installedRoot);
+    const { version, changes } = tx.install(source('skills/scm/commit', '2.0.0'));
+    tx.commit();
+
+    expect(version).toBe('2.0.0');
+    expect(changes).toEqual({ added: [], updated: ['manifest.yaml'], removed: [], unchanged: 0 });
+  });
+
+  it('restores every type of the plan on rollback', async () => {
+    const tx = new InstallTransaction(installedRoot);
+    tx.install(source('skills/scm/commit', '2.0.0'));
+    const review = source('skills/scm/review', '2.0.0', 'registry:\n  output: true\n');
+    tx.install(review);
+    await tx.initSkillRegistry(review, skillsDir);
+    expect(existsSync(join(skillsDir, 'scm', 'review'))).toBe(true);
+
+    expect(tx.rollback()).toEqual([]);
+    expect(manifestOf('skills/scm/commit')).toContain('1.0.0');
+    expect(readCurrent('skills/scm/commit')).toBe('1.0.0');
+    expect(existsSync(join(installedRoot, 'skills', 'scm', 'review'))).toBe(false);
+    expect(readCurrent('skills/scm/review')).toBeNull();
+    expect(existsSync(join(skillsDir, 'scm', 'review'))).toBe(false);
+    expect(readdirSync(join(installedRoot, 'skills', 'scm'))).toEqual(['commit']);
+  });
+
+  it('installs a plan with per-type callbacks, or rolls all of it back', async () => {
+    const installed: string[] = [];
+    await installAll([source('skills/scm/commit', '2.0.0')], installedRoot, {
+      onInstalled: (resolved, version) => installed.push(`${resolved.typePath}@${version}`),
+    });
+    expect(installed).toEqual(['skills/scm/commit@2.0.0']);
+
+    // Vendored dependencies that aren't there fail the second type
+    const broken = source('skills/scm/broken', '1.0.0', 'vendored_deps: deps.tgz\n');
+    writeFileSync(join(broken.sourceDir, 'package.json'), '{"name": "broken"}');
+    const failure = await installAll([source('skills/scm/commit', '3.0.0'), broken], installedRoot).catch((e) => e);
+    expect(failure).toBeInstanceOf(InstallError);
+    expect(failure).toMatchObject({ typePath: 'skills/scm/broken', unrestored: [] });
+    expect(failure.message).toMatch(/all changes were rolled back$/);
+    expect(readCurrent('skills/scm/commit')).toBe('2.0.0');
+    expect(existsSync(join(installedRoot, 'skills', 'scm', 'broken'))).toBe(false);
+  });
+});
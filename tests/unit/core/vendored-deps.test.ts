@@ -0,0 +1,91 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync, mkdirSync, rmSync, readFileSync, existsSync, symlinkSync } from 'node:fs';
+import { execFileSync } from 'node:child_process';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { setExecutionContext, resetExecutionContext, executionContext } from '../../../src/config/context.js';
+import { checkVendoredPath, vendoredArchive } from '../../../src/core/vendored-deps.js';
+import { installNodeDeps } from '../../../src/core/registry.js';
+
+describe('vendored dependencies', () => {
+  let home: string;
+  let skill: string;
+
+  function pack(name: string, files: Record<string, string>): void {
+    const src = join(home, 'src');
+    rmSync(src, { recursive: true, force: true });
+    for (const [path, content] of Object.entries(files)) {
+      mkdirSync(join(src, path, '..'), { recursive: true });
+      writeFileSync(join(src, path), content);
+    }
+    execFileSync('tar', ['-czf', join(skill, name), '-C', src, '.']);
+  }
+
+  beforeEach(() => {
+    home = join(tmpdir(), `agentx-vendored-test-${Date.now()}`);
+    process.env.AGENTX_HOME = home;
+    skill = join(home, 'installed', 'skills', 'offline-tool');
+    mkdirSync(skill, { recursive: true });
+    writeFileSync(join(skill, 'package.json'), '{"name": "offline-tool"}');
+    writeFileSync(
+      join(skill, 'manifest.yaml'),
+      'name: offline-tool\ntype: skill\nversion: "1.0.0"\ndescription: d\nruntime: node\ntopic: t\nvendored_deps: deps.tgz\n',
+    );
+    setExecutionContext({ ...executionContext(), offline: true });
+  });
+
+  afterEach(() => {
+    resetExecutionContext();
+    delete process.env.AGENTX_HOME;
+    rmSync(home, { recursive: true, force: true });
+  });
+
+  it('unpacks the archive instead of running npm, even offline', () => {
+    pack('deps.tgz', { 'node_modules/left-pad/index.js': 'module.exports = 1;\n' });
+    mkdirSync(join(skill, 'node_modules', 'stale'), { recursive: true });
+
+    expect(installNodeDeps(skill)).toBeNull();
+    expect(readFileSync(join(skill, 'node_modules', 'left-pad', 'index.js'), 'utf-8')).toContain('module.exports');
+    expect(existsSync(join(skill, 'node_modules', 'stale'))).toBe(false);
+  });
+
+  it('leaves the type as it was when the archive is unusable', () => {
+    pack('deps.tgz', { 'left-pad/index.js': 'module.exports = 1;\n' });
+    mkdirSync(join(skill, 'node_modules', 'kept'), { recursive: true });
+
+    expect(() => installNodeDeps(skill)).toThrow(/no top-level node_modules/);
+    expect(existsSync(join(skill, 'node_modules', 'kept'))).toBe(true);
+
+    rmSync(join(skill, 'deps.tgz'));
+    expect(() => vendoredArchive(skill)).toThrow(/not found/);
+  });
+
+  it('refuses archives with members that reach outside the type', () => {
+    const src = join(home, 'src');
+    const outside = join(home, 'outside');
+    mkdirSync(join(src, 'node_modules'), { recursive: true });
+    mkdirSync(outside);
+    writeFileSync(join(outside, 'evil.js'), 'x');
+    symlinkSync('../../outside', join(src, 'node_modules', 'escape'));
+    mkdirSync(join(skill, 'node_modules', 'kept'), { recursive: true });
+
+    execFileSync('tar', ['-czf', join(skill, 'deps.tgz'), '-C', src, 'node_modules']);
+    expect(() => installNodeDeps(skill)).toThrow(/node_modules\/escape links to \.\.\/\.\.\/outside, outside the archive/);
+
+    // A symlink, then a file written through it
+    symlinkSync(outside, join(src, 'node_modules', 'abs'));
+    execFileSync('tar', ['-czf', join(skill, 'deps.tgz'), '-C', src, 'node_modules/abs', 'node_modules/abs/evil.js']);
+    expect(() => installNodeDeps(skill)).toThrow(/outside the archive/);
+
+    expect(existsSync(join(skill, 'node_modules', 'kept'))).toBe(true);
+    expect(existsSync(join(skill, 'node_modules', 'escape'))).toBe(false);
+  });
+
+  it('only accepts tarball paths inside the type', () => {
+    expect(checkVendoredPath('node_modules.tar.zst')).toBeNull();
+    expect(checkVendoredPath('vendor/deps.tar.gz')).toBeNull();
+    expect(checkVendoredPath('../other/deps.tgz')).toMatch(/inside the type/);
+    expect(checkVendoredPath('/tmp/deps.tgz')).toMatch(/inside the type/);
+    expect(checkVendoredPath('deps.zip')).toMatch(/archive/);
+  });
+});
@@ -0,0 +1,100 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { runVerify } from '../../../src/core/verify.js';
+import { initProject, loadProject, saveProject, projectConfigPath } from '../../../src/core/linker.js';
+
+describe('runVerify', () => {
+  let root: string;
+  let project: string;
+  let installedRoot: string;
+
+  const statuses = async () =>
+    Object.fromEntries((await runVerify(project, installedRoot)).checks.map((c) => [c.name, c.status]));
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-verify-test-${Date.now()}`);
+    project = join(root, 'project');
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+    mkdirSync(installedRoot, { recursive: true });
+    initProject(project, []);
+  });
+
+  afterEach(() => {
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('passes a fresh project, skipping checks with nothing to check', async () => {
+    const report = await runVerify(project, installedRoot);
+    expect(report.passed).toBe(true);
+    expect(await statuses()).toEqual({
+      project: 'pass',
+      manifests: 'skip',
+      links: 'pass',
+      pins: 'pass',
+      lockfiles: 'skip',
+      extensions: 'pass',
+      integrity: 'pass',
+    });
+  });
+
+  it('reports project.yaml schema problems with their line and skips dependent checks', async () => {
+    writeFileSync(projectConfigPath(project), 'tools: []\nactive: {}\nprefers: corp\n');
+    const report = await runVerify(project, installedRoot);
+    expect(report.passed).toBe(false);
+    const check = report.checks.find((c) => c.name === 'project')!;
+    expect(check.failures).toEqual([
+      expect.objectContaining({ file: '.agentx/project.yaml', line: 3, message: expect.stringMatching(/Unknown field "prefers"/) }),
+    ]);
+    expect(report.checks.filter((c) => c.status === 'skip').map((c) => c.name)).toEqual([
+      'manifests',
+      'links',
+      'pins',
+      'lockfiles',
+      'extensions',
+    ]);
+  });
+
+  it('fails types that are linked but not installed and pins to unknown sources', async () => {
+    saveProject(project, {
+      ...loadProject(project),
+      active: { context: ['context/lang/go'] },
+      prefer: { 'context/*': 'corp-ext' },
+    });
+    const report = await runVerify(project, installedRoot);
+    expect(report.checks.find((c) => c.name === 'pins')?.failures).toEqual([
+      { subject: 'context/lang/go', message: 'linked but not installed' },
+    ]);
+    expect(report.checks.find((c) => c.name === 'extensions')?.failures).toEqual([
+      { subject: 'corp-ext', message: 'named in prefer but not installed' },
+    ]);
+  });
+
+  it('skips every project check outside a project', async () => {
+    rmSync(project, { recursive: true, force: true });
+    mkdirSync(project, { recursive: true });
+    const report = await runVerify(project, installedRoot);
+    expect(report.passed).toBe(true);
+    expect(report.checks.filter((c) => c.status !== 'skip').map((c) => c.name)).toEqual(['integrity']);
+  });
+
+  it('fails linked Node skills whose package-lock.json is missing or out of date', async () => {
+    for (const name of ['skills/a/stale', 'skills/a/unlocked']) {
+      mkdirSync(join(installedRoot, name), { recursive: true });
+      writeFileSync(join(installedRoot, name, 'package.json'), JSON.stringify({ dependencies: { yaml: '^2.0.0' } }));
+    }
+    writeFileSync(
+      join(installedRoot, 'skills/a/stale', 'package-lock.json'),
+      JSON.stringify({ lockfileVersion: 3, packages: { '': { dependencies: { yaml: '^1.0.0' } } } }),
+    );
+    saveProject(project, { ...loadProject(project), active: { skills: ['skills/a/stale', 'skills/a/unlocked'] } });
+    const report = await runVerify(project, installedRoot);
+    expect(report.checks.find((c) => c.name === 'lockfiles')?.failures).toEqual([
+      { subject: 'skills/a/stale', message: 'dependencies.yaml: package.json has ^2.0.0, package-lock.json has ^1.0.0' },
+      { subject: 'skills/a/unlocked', message: 'package.json declares dependencies but there is no package-lock.json' },
+    ]);
+  });
+});
@@ -0,0 +1,100 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdirSync, writeFileSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { storeType, materialize } from '../../../src/core/store.js';
+import { installedTypePaths } from '../../../src/core/registry.js';
+import { initProject, loadProject, saveProject } from '../../../src/core/linker.js';
+import {
+  addVersion,
+  removeVersion,
+  installedVersions,
+  resolveTypeDir,
+  pinnedTypeDirs,
+  projectTypeDirs,
+  selectVersion,
+} from '../../../src/core/versions.js';
+import { verifyType } from '../../../src/core/integrity.js';
+import { executeType } from '../../../src/core/executor.js';
+
+describe('side-by-side versions', () => {
+  let root: string;
+  let installedRoot: string;
+  const typePath = 'skills/scm/git/commit-analyzer';
+
+  function store(version: string) {
+    const dir = join(root, 'src', version);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, 'manifest.yaml'), `name: commit-analyzer\ntype: skill\nversion: "${version}"\n`);
+    return storeType(typePath, dir, version);
+  }
+
+  beforeEach(() => {
+    root = join(tmpdir(), `agentx-versions-test-${Date.now()}`);
+    installedRoot = join(root, 'installed');
+    process.env.AGENTX_HOME = join(root, 'home');
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    delete process.env.AGENTX_HOME;
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('installs stored versions next to the default', () => {
+    store('1.4.2');
+    materialize(store('2.0.0'), installedRoot);
+
+    const dir = addVersion(installedRoot, typePath, '1.4.2');
+    expect(readFileSync(join(dir, 'manifest.yaml'), 'utf-8')).toContain('1.4.2');
+    expect(installedVersions(installedRoot, typePath).map((v) => [v.version, v.default])).toEqual([
+      ['2.0.0', true],
+      ['1.4.2', false],
+    ]);
+    expect(resolveTypeDir(installedRoot, typePath)).toBe(join(installedRoot, typePath));
+    expect(resolveTypeDir(installedRoot, typePath, '1.4.2')).toBe(dir);
+    expect(resolveTypeDir(installedRoot, typePath, '3.0.0')).toBeNull();
+    // Side-by-side copies are not separate types
+    expect(installedTypePaths(installedRoot)).toEqual([typePath]);
+
+    expect(() => addVersion(installedRoot, typePath, '2.0.0')).toThrow(/already the default/);
+    expect(() => addVersion(installedRoot, typePath, '0.9.0')).toThrow(/not stored/);
+
+    expect(removeVersion(installedRoot, typePath, '1.4.2')).toBe(true);
+    expect(removeVersion(installedRoot, typePath, '1.4.2')).toBe(false);
+  });
+
+  it('selects pinned side-by-side versions for a project', () => {
+    store('1.4.2');
+    materialize(store('2.0.0'), installedRoot);
+    const dir = addVersion(installedRoot, typePath, '1.4.2');
+
+    expect(pinnedTypeDirs(installedRoot, { [typePath]: '1.4.2' })).toEqual({ [typePath]: dir });
+    expect(pinnedTypeDirs(installedRoot, { [typePath]: '2.0.0' })).toEqual({});
+
+    const project = join(root, 'project');
+    expect(projectTypeDirs(project, installedRoot)).toEqual({});
+    initProject(project, []);
+    saveProject(project, { ...loadProject(project), active: { skills: [typePath] }, pins: { [typePath]: '1.4.2' } });
+    expect(projectTypeDirs(project, installedRoot)).toEqual({ [typePath]: dir });
+  });
+
+  it('refuses to run a pinned version whose manifest was edited', async () => {
+    store('1.4.2');
+    materialize(store('2.0.0'), installedRoot);
+    const dir = addVersion(installedRoot, typePath, '1.4.2');
+    const project = join(root, 'project');
+    initProject(project, []);
+    saveProject(project, { ...loadProject(project), active: { skills: [typePath] }, pins: { [typePath]: '1.4.2' } });
+    vi.spyOn(process, 'cwd').mockReturnValue(project);
+    expect(selectVersion(project, installedRoot, typePath)).toEqual({ dir, version: '1.4.2' });
+
+    // Replace rather than write through: store objects are hard-linked
+    rmSync(join(dir, 'manifest.yaml'));
+    writeFileSync(join(dir, 'manifest.yaml'), 'name: commit-analyzer\ntype: skill\nversion: "1.4.2"\nruntime: shell\n');
+    expect(verifyType(typePath, installedRoot, { dir, version: '1.4.2' }).manifestChanged).toBe(true);
+    // The default version is untouched, so only checking the pinned one catches this
+    expect(verifyType(typePath, installedRoot).manifestChanged).toBe(false);
+    await expect(executeType(typePath, {}, installedRoot)).rejects.toThrow(/manifest of .* was modified after install/);
+  });
+});
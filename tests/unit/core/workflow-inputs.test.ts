@@ -0,0 +1,90 @@
+import { describe, it, expect } from 'vitest';
+import { readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { fileURLToPath } from 'node:url';
+import yaml from 'js-yaml';
+import { workflowIssues, resolveStepInputs } from '../../../src/core/workflow-inputs.js';
+import { validateManifest, validateManifestFile } from '../../../src/core/manifest.js';
+import type { WorkflowManifest } from '../../../src/types/manifest.js';
+
+const workflow = `name: release-check
+type: workflow
+version: "1.0.0"
+description: Checks a release
+runtime: node
+inputs:
+  - name: repoPath
+    type: string
+    required: true
+  - name: days
+    type: number
+    default: "7"
+steps:
+  - id: analyze
+    skill: skills/scm/git/commit-analyzer
+    inputs:
+      repo: "{{inputs.repoPath}}"
+      since: "{{steps.summarize.outputs.date}}"
+  - id: summarize
+    skill: skills/text/summarize
+    inputs:
+      text: "{{steps.analyze.outputs.summary}}"
+      title: Commits in {{inputs.repo}}
+      extra: "{{env.HOME}}"
+  - id: analyze
+    skill: skills/text/summarize
+`;
+
+describe('workflow inputs', () => {
+  it('reports bad references, duplicate ids, and mistyped defaults at their lines', () => {
+    const issues = validateManifest(workflow, 'workflow.yaml');
+    expect(issues.map((i) => [i.path, i.line, i.message])).toEqual([
+      ['inputs.1.default', 12, 'Default of "days" is not a number'],
+      ['steps.0.inputs.since', 18, 'Step "summarize" has not run before step "analyze"'],
+      ['steps.1.inputs.title', 23, 'Unknown workflow input "repo" (declared inputs: repoPath, days)'],
+      ['steps.1.inputs.extra', 24, 'Unsupported reference "{{env.HOME}}"; use {{inputs.<name>}} or {{steps.<id>.outputs[.<path>]}}'],
+      ['steps.2.id', 25, 'Duplicate step id "analyze"'],
+    ]);
+  });
+
+  it('accepts references to declared inputs and earlier steps', () => {
+    const ok = {
+      inputs: [{ name: 'repoPath', type: 'string' }],
+      steps: [
+        { id: 'analyze', skill: 'skills/a', inputs: { repo: '{{inputs.repoPath}}' } },
+        { id: 'summarize', skill: 'skills/b', inputs: { text: '{{steps.analyze.outputs}}', n: 3 } },
+      ],
+    } as unknown as WorkflowManifest;
+    expect(workflowIssues(ok)).toEqual([]);
+  });
+
+  it('fills references from inputs and earlier outputs', () => {
+    const outputs = new Map([['analyze', { stdout: '{"summary":"3 commits","items":[{"sha":"abc"}]}\n' }]]);
+    const resolved = resolveStepInputs(
+      {
+        text: '{{steps.analyze.outputs.summary}}',
+        sha: '{{steps.analyze.outputs.items.0.sha}}',
+        title: 'Commits in {{inputs.repo}} ({{inputs.branch}})',
+        branch: '{{inputs.branch}}',
+        limit: 5,
+      },
+      { repo: '.' },
+      outputs,
+    );
+    expect(resolved).toEqual({ text: '3 commits', sha: 'abc', title: 'Commits in . ()', limit: '5' });
+  });
+
+  it('validates and resolves the catalog workflows', () => {
+    const catalog = fileURLToPath(new URL('../../../catalog/workflows', import.meta.url));
+    const codeReview = join(catalog, 'code-review/manifest.yaml');
+    expect(validateManifestFile(codeReview)).toEqual([]);
+    const manifest = yaml.load(readFileSync(codeReview, 'utf-8')) as WorkflowManifest;
+    const [step] = manifest.steps;
+    expect(resolveStepInputs('skill' in step ? step.inputs : {}, { 'repo-path': '/src/app' }, new Map())).toEqual({
+      'repo-path': '/src/app',
+    });
+
+    const createQuery = yaml.load(readFileSync(join(catalog, 'observability/splunk/create-query/manifest.yaml'), 'utf-8'));
+    expect(workflowIssues(createQuery as WorkflowManifest)).toEqual([]);
+  });
+});
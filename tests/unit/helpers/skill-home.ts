@@ -0,0 +1,43 @@
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import * as settings from '../../../src/config/settings.js';
+
+// Shared fixture for tests that run skills: a fresh AGENTX_HOME with
+// Node skills written straight into installed/. They have no install
+// record, so run.manifest_guard is off.
+
+export interface SkillHome {
+  root: string;
+  installedRoot: string;
+  /** Writes skills/<topic>/<name> with the given manifest lines and index.mjs; returns its type path. */
+  skill(name: string, extra: string, script: string): string;
+}
+
+export function setupSkillHome(label: string, topic: string): SkillHome {
+  const root = join(tmpdir(), `agentx-${label}-test-${Date.now()}`);
+  const installedRoot = join(root, 'installed');
+  process.env.AGENTX_HOME = root;
+  mkdirSync(root, { recursive: true });
+  writeFileSync(join(root, 'config.yaml'), 'run.manifest_guard: off\n');
+  settings.init(join(root, 'config.yaml'));
+
+  const skill = (name: string, extra: string, script: string): string => {
+    const typePath = `skills/${topic}/${name}`;
+    const dir = join(installedRoot, typePath);
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(
+      join(dir, 'manifest.yaml'),
+      `name: ${name}\ntype: skill\nversion: "1.0.0"\ndescription: d\nruntime: node\ntopic: ${topic}\n${extra}`,
+    );
+    writeFileSync(join(dir, 'index.mjs'), script);
+    return typePath;
+  };
+  return { root, installedRoot, skill };
+}
+
+export function removeSkillHome(home: SkillHome): void {
+  settings.init('');
+  delete process.env.AGENTX_HOME;
+  rmSync(home.root, { recursive: true, force: true });
+}
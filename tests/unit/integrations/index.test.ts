@@ -0,0 +1,41 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { cleanTargets, sameGenerated } from '../../../src/integrations/index.js';
+
+const header = (version: string) =>
+  `<!-- Generated by agentx ${version}. Regenerate with \`agentx link sync\`; manual edits are overwritten. -->\n\n`;
+const COMMAND_MARKER = '<!-- Generated by agentx; regenerate with `agentx link sync`. -->';
+
+describe('integrations', () => {
+  let projectDir: string;
+
+  beforeEach(() => {
+    projectDir = join(tmpdir(), `agentx-integrations-test-${Date.now()}`);
+    mkdirSync(join(projectDir, '.claude', 'commands'), { recursive: true });
+  });
+
+  afterEach(() => rmSync(projectDir, { recursive: true, force: true }));
+
+  it('treats output stamped by another CLI version as unchanged', () => {
+    expect(sameGenerated(`${header('1.0.0')}# Project\n`, `${header('1.1.0')}# Project\n`)).toBe(true);
+    expect(sameGenerated(`${header('1.0.0')}# Project\n`, `${header('1.1.0')}# Other\n`)).toBe(false);
+    expect(sameGenerated('# Project\n', `${header('1.1.0')}# Project\n`)).toBe(false);
+  });
+
+  it('cleans only files carrying the generated marker', () => {
+    const commands = join(projectDir, '.claude', 'commands');
+    writeFileSync(join(commands, 'generated.md'), `Analyze\n\n\`agentx run skills/a\`\n\n${COMMAND_MARKER}\n`);
+    writeFileSync(join(commands, 'mine.md'), 'My own wrapper around `agentx run skills/a`\n');
+    writeFileSync(join(projectDir, '.claude', 'CLAUDE.md'), '# Written by hand\n');
+
+    expect(cleanTargets({ toolName: 'claude-code', projectPath: projectDir })).toEqual([join(commands, 'generated.md')]);
+
+    writeFileSync(join(projectDir, '.claude', 'CLAUDE.md'), `${header('1.0.0')}# Project\n`);
+    expect(cleanTargets({ toolName: 'claude-code', projectPath: projectDir })).toEqual([
+      join(projectDir, '.claude', 'CLAUDE.md'),
+      join(commands, 'generated.md'),
+    ]);
+  });
+});
@@ -0,0 +1,57 @@
+import { describe, it, expect, beforeAll, afterAll } from 'vitest';
+import { buildPanes, renderPane, renderDashboard } from '../../../src/ui/dashboard.js';
+import { setLocale } from '../../../src/ui/i18n.js';
+import type { DashboardData } from '../../../src/core/dashboard.js';
+
+const ANSI = /\x1b\[[0-9;?]*[A-Za-z]/g;
+const plain = (s: string) => s.replace(ANSI, '');
+
+const data: DashboardData = {
+  installed: [
+    { typePath: 'skills/scm/git/commit-analyzer', category: 'skill', version: '1.4.2', description: '', topic: 'scm', source: 'catalog', installedAt: null, latest: '1.5.0' },
+    { typePath: 'personas/reviewer', category: 'persona', version: '1.0.0', description: '', topic: null, source: 'catalog', installedAt: null, latest: null },
+  ],
+  projects: [{ path: '/work/app', tools: ['claude-code'], linked: 3, pending: 2, lastSync: '2026-01-02T03:04:05.000Z' }],
+  extensions: [{ name: 'acme', path: '/x/acme', branch: '', status: 'ok' }],
+  runs: [{ typePath: 'skills/scm/git/commit-analyzer', run: 2, at: '2026-01-02T03:04:05.000Z', bytes: 2048 }],
+  warnings: [],
+};
+
+describe('dashboard', () => {
+  beforeAll(() => setLocale('en'));
+  afterAll(() => setLocale(null));
+
+  it('maps rows to the commands their actions run', () => {
+    const [installed, projects, , runs] = buildPanes(data);
+    expect(installed.rows[0]).toEqual(['skills/scm/git/commit-analyzer', '1.4.2', '1.5.0']);
+    const run = installed.actions.find((a) => a.key === 'r')!;
+    expect(run.argv(0)).toEqual(['run', 'skills/scm/git/commit-analyzer']);
+    expect(run.enabled?.(1)).toBe(false);
+    expect(projects.rows[0]).toEqual(['/work/app', '3', '2 change(s)', '2026-01-02 03:04']);
+    expect(projects.actions[0].cwd?.(0)).toBe('/work/app');
+    expect(runs.actions[0].argv(0)).toEqual(['output', 'show', 'skills/scm/git/commit-analyzer', '--run', '2']);
+  });
+
+  it('draws a pane at its exact size, truncating long cells', () => {
+    const [installed] = buildPanes(data);
+    const lines = renderPane(installed, 30, 6, true, 0).map(plain);
+    expect(lines).toHaveLength(6);
+    for (const line of lines) expect(line).toHaveLength(30);
+    expect(lines[0]).toMatch(/^┌ Installed \(2\) ─+┐$/);
+    expect(lines[2]).toContain('…');
+  });
+
+  it('scrolls to keep the selection in view', () => {
+    const pane = { title: 'T', columns: ['N'], rows: Array.from({ length: 10 }, (_, i) => [`row${i}`]), actions: [] };
+    const lines = renderPane(pane, 20, 5, true, 9).map(plain);
+    expect(lines.slice(2, 4).map((l) => l.trim())).toEqual(['│row8              │', '│row9              │']);
+  });
+
+  it('fills the screen with four panes and two footer lines', () => {
+    const state = { panes: buildPanes(data), focus: 1, selected: [0, 0, 0, 0], status: 'ready' };
+    const lines = renderDashboard(state, 100, 24);
+    expect(lines).toHaveLength(24);
+    expect(plain(lines[0])).toHaveLength(100);
+    expect(plain(lines[22])).toContain('s link sync');
+  });
+});
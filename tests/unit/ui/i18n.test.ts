@@ -0,0 +1,39 @@
+import { describe, it, expect, afterEach } from 'vitest';
+import { resolveLocale, setLocale, t, currentLocale } from '../../../src/ui/i18n.js';
+import { en } from '../../../src/ui/messages/en.js';
+import { es } from '../../../src/ui/messages/es.js';
+
+const placeholders = (s: string) => [...s.matchAll(/\{(\w+)\}/g)].map((m) => m[1]).sort();
+
+describe('i18n', () => {
+  afterEach(() => setLocale(null));
+
+  it('prefers the locale setting, then LC_ALL, LC_MESSAGES, and LANG', () => {
+    expect(resolveLocale('es', { LANG: 'en_US.UTF-8' })).toBe('es');
+    expect(resolveLocale('', { LC_ALL: 'es_MX.UTF-8', LANG: 'en_US' })).toBe('es');
+    expect(resolveLocale('', { LC_MESSAGES: 'es', LANG: 'en_US' })).toBe('es');
+    expect(resolveLocale('', { LANG: 'es_ES@euro' })).toBe('es');
+  });
+
+  it('falls back to English for unsupported or unset languages', () => {
+    expect(resolveLocale('', { LANG: 'fr_FR.UTF-8' })).toBe('en');
+    expect(resolveLocale('', { LANG: 'C' })).toBe('en');
+    expect(resolveLocale('', {})).toBe('en');
+  });
+
+  it('fills placeholders in the selected locale', () => {
+    setLocale('es');
+    expect(currentLocale()).toBe('es');
+    expect(t('test.summary', { passed: 3, failed: 1 })).toBe('3 correctas, 1 fallidas');
+    setLocale('en');
+    expect(t('test.summary', { passed: 3, failed: 1 })).toBe('3 passed, 1 failed');
+    expect(t('test.noTests')).toBe('{skill} declares no tests.');
+  });
+
+  it('translates every message with the same placeholders', () => {
+    for (const key of Object.keys(en) as (keyof typeof en)[]) {
+      expect(es[key], key).toBeTruthy();
+      expect(placeholders(es[key]), key).toEqual(placeholders(en[key]));
+    }
+  });
+});
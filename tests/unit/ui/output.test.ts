@@ -0,0 +1,75 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import {
+  emitJson,
+  ok,
+  info,
+  note,
+  warn,
+  setOutputFormat,
+  resetDiagnostics,
+  collectedDiagnostics,
+} from '../../../src/ui/output.js';
+
+describe('output', () => {
+  let stdout: string[];
+  let stderr: string[];
+
+  beforeEach(() => {
+    stdout = [];
+    stderr = [];
+    vi.spyOn(process.stdout, 'write').mockImplementation((chunk) => {
+      stdout.push(String(chunk));
+      return true;
+    });
+    vi.spyOn(console, 'log').mockImplementation((...args) => {
+      stdout.push(args.join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args) => {
+      stderr.push(args.join(' '));
+    });
+    resetDiagnostics();
+  });
+
+  afterEach(() => {
+    setOutputFormat('text');
+    vi.restoreAllMocks();
+  });
+
+  it('keeps warnings off stdout', () => {
+    warn('manifest skipped', 'registry');
+    emitJson([1, 2]);
+
+    expect(JSON.parse(stdout.join(''))).toEqual([1, 2]);
+    expect(stderr.join('\n')).toContain('warning[registry]: manifest skipped');
+  });
+
+  it('wraps data in an envelope with --output-format=json', () => {
+    setOutputFormat('json');
+    warn('stale cache');
+    emitJson({ n: 1 });
+
+    expect(JSON.parse(stdout.join(''))).toEqual({
+      data: { n: 1 },
+      warnings: [{ level: 'warning', message: 'stale cache' }],
+    });
+    expect(collectedDiagnostics()).toHaveLength(1);
+  });
+
+  it('prints success lines on stdout unless stdout is reserved for JSON', () => {
+    ok('installed');
+    info('nothing else to do');
+    note('secrets masked');
+    expect(stdout.join('\n')).toMatch(/installed[\s\S]*nothing else to do/);
+    expect(stderr.join('\n')).toContain('secrets masked');
+
+    stdout.length = 0;
+    setOutputFormat('json');
+    ok('installed');
+    expect(stdout).toEqual([]);
+    expect(stderr.join('\n')).toContain('installed');
+  });
+
+  it('rejects unknown formats', () => {
+    expect(() => setOutputFormat('yaml')).toThrow('Invalid output format');
+  });
+});
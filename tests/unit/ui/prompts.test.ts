@@ -0,0 +1,36 @@
+import { describe, it, expect, afterEach } from 'vitest';
+import { askConfirm, askSelect, askInput, askSecret, canPrompt } from '../../../src/ui/prompts.js';
+import { executionContext, setExecutionContext, resetExecutionContext } from '../../../src/config/context.js';
+import type { Answers } from '../../../src/config/context.js';
+
+function answering(answers: Answers): void {
+  setExecutionContext({ ...executionContext(), interactive: true, answers });
+}
+
+describe('prompts', () => {
+  afterEach(() => resetExecutionContext());
+
+  it('takes safe defaults with --non-interactive', async () => {
+    answering('defaults');
+    expect(canPrompt()).toBe(false);
+    expect(await askConfirm('Save?', false)).toBe(false);
+    expect(await askInput('Name?', 'demo')).toBe('demo');
+    expect(await askSelect('Pick', [{ name: 'A', value: 'a' }], 'a')).toBe('a');
+  });
+
+  it('fails fast where a decision is needed', async () => {
+    answering('defaults');
+    await expect(askConfirm('Proceed?')).rejects.toThrow(/Proceed\? \(pass --yes\)/);
+    await expect(askConfirm('Delete all?', false, { mandatory: true })).rejects.toThrow(/Delete all\? \(pass --yes\)/);
+    await expect(askSecret('Token:', { hint: 'pass --token-stdin' })).rejects.toThrow(/--token-stdin/);
+    await expect(askSelect('Pick', [{ name: 'A', value: 'a' }])).rejects.toThrow(/decision is needed/);
+    await expect(askInput('Name?')).rejects.toThrow(/decision is needed/);
+  });
+
+  it('answers confirmations with yes under --yes', async () => {
+    answering('yes');
+    expect(await askConfirm('Proceed?')).toBe(true);
+    expect(await askConfirm('Delete all?', false, { mandatory: true })).toBe(true);
+    await expect(askSecret('Token:')).rejects.toThrow(/decision is needed/);
+  });
+});
@@ -0,0 +1,84 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { createServer, type Server } from 'node:http';
+import type { AddressInfo } from 'node:net';
+import { execFileSync } from 'node:child_process';
+import { mkdirSync, writeFileSync, readFileSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { archiveUrl, downloadArchive, downloadTarball } from '../../../src/utils/archive.js';
+
+describe('archive downloads', () => {
+  it('maps GitHub and GitLab URLs to their archive endpoints', () => {
+    expect(archiveUrl('https://github.com/acme/catalog.git')).toBe('https://codeload.github.com/acme/catalog/tar.gz/main');
+    expect(archiveUrl('git@github.com:acme/catalog.git', 'v2')).toBe('https://codeload.github.com/acme/catalog/tar.gz/v2');
+    expect(archiveUrl('https://github.com/acme/catalog', 'main', true)).toBe(
+      'https://api.github.com/repos/acme/catalog/tarball/main',
+    );
+    expect(archiveUrl('https://gitlab.com/acme/platform/catalog.git', 'main')).toBe(
+      'https://gitlab.com/acme/platform/catalog/-/archive/main/catalog-main.tar.gz',
+    );
+    expect(archiveUrl('git@gitlab.com:acme/platform/catalog.git', 'main', true)).toBe(
+      'https://gitlab.com/api/v4/projects/acme%2Fplatform%2Fcatalog/repository/archive.tar.gz?sha=main',
+    );
+  });
+
+  it('has no archive fallback for other hosts', async () => {
+    expect(archiveUrl('https://git.acme.io/platform/catalog.git')).toBeNull();
+    await expect(downloadArchive('https://git.acme.io/platform/catalog.git', 'main', '/nonexistent')).rejects.toThrow(
+      /only GitHub and GitLab URLs have an archive fallback/,
+    );
+  });
+
+  describe('downloadTarball', () => {
+    let root: string;
+    let server: Server;
+    let base: string;
+    let tarball: Buffer;
+
+    beforeEach(async () => {
+      root = join(tmpdir(), `agentx-archive-test-${Date.now()}`);
+      const src = join(root, 'src', 'catalog-main');
+      mkdirSync(join(src, 'skills'), { recursive: true });
+      writeFileSync(join(src, 'README.md'), '# Catalog\n');
+      writeFileSync(join(src, 'skills', 'a.yaml'), 'name: a\n');
+      const tgz = join(root, 'catalog.tar.gz');
+      execFileSync('tar', ['-czf', tgz, '-C', join(root, 'src'), 'catalog-main']);
+      tarball = readFileSync(tgz);
+
+      server = createServer((req, res) => {
+        if (req.url === '/catalog.tar.gz') res.end(tarball);
+        else if (req.url === '/garbage.tar.gz') res.end('not a tarball');
+        else {
+          res.statusCode = 404;
+          res.end();
+        }
+      });
+      await new Promise<void>((r) => server.listen(0, '127.0.0.1', r));
+      base = `http://127.0.0.1:${(server.address() as AddressInfo).port}`;
+    });
+
+    afterEach(() => {
+      server.close();
+      rmSync(root, { recursive: true, force: true });
+    });
+
+    it('unpacks the top-level directory into the target', async () => {
+      const target = join(root, 'out');
+      await downloadTarball(`${base}/catalog.tar.gz`, target);
+      expect(readFileSync(join(target, 'README.md'), 'utf-8')).toBe('# Catalog\n');
+      expect(existsSync(join(target, 'skills', 'a.yaml'))).toBe(true);
+    });
+
+    it('reports HTTP failures without creating the target', async () => {
+      const target = join(root, 'out');
+      await expect(downloadTarball(`${base}/missing.tar.gz`, target)).rejects.toThrow(/HTTP 404/);
+      expect(existsSync(target)).toBe(false);
+    });
+
+    it('removes a partially unpacked target when tar fails', async () => {
+      const target = join(root, 'out');
+      await expect(downloadTarball(`${base}/garbage.tar.gz`, target)).rejects.toThrow();
+      expect(existsSync(target)).toBe(false);
+    });
+  });
+});
@@ -0,0 +1,31 @@
+import { describe, it, expect } from 'vitest';
+import { parseCron, nextRun } from '../../../src/utils/cron.js';
+
+const at = (s: string) => new Date(s);
+
+describe('cron', () => {
+  it('parses lists, ranges, steps, and names', () => {
+    const s = parseCron('*/15 9-17 * jan,jul mon-fri');
+    expect([...s.minutes]).toEqual([0, 15, 30, 45]);
+    expect(s.hours.size).toBe(9);
+    expect([...s.months]).toEqual([1, 7]);
+    expect([...s.weekdays]).toEqual([1, 2, 3, 4, 5]);
+    expect([...parseCron('0 0 * * 7').weekdays]).toEqual([0]);
+    expect(() => parseCron('0 9 * *')).toThrow(/expected 5 fields/);
+    expect(() => parseCron('61 * * * *')).toThrow(/Invalid minute "61"/);
+  });
+
+  it('finds the next matching minute in local time', () => {
+    // 2026-03-06 is a Friday
+    expect(nextRun('0 9 * * 1-5', at('2026-03-06T08:59:30'))).toEqual(at('2026-03-06T09:00:00'));
+    expect(nextRun('0 9 * * 1-5', at('2026-03-06T09:00:00'))).toEqual(at('2026-03-09T09:00:00'));
+    expect(nextRun('@monthly', at('2026-03-06T10:00:00'))).toEqual(at('2026-04-01T00:00:00'));
+    expect(nextRun('0 0 29 2 *', at('2026-03-01T00:00:00'))).toEqual(at('2028-02-29T00:00:00'));
+  });
+
+  it('matches either day field when both are restricted', () => {
+    // The 13th, or any Friday
+    expect(nextRun('0 12 13 * fri', at('2026-03-07T00:00:00'))).toEqual(at('2026-03-13T12:00:00'));
+    expect(nextRun('0 12 13 * fri', at('2026-03-13T13:00:00'))).toEqual(at('2026-03-20T12:00:00'));
+  });
+});
@@ -0,0 +1,25 @@
+import { describe, it, expect } from 'vitest';
+import { unifiedDiff } from '../../../src/utils/diff.js';
+
+describe('unifiedDiff', () => {
+  it('is empty when nothing changed', () => {
+    expect(unifiedDiff('one\ntwo\n', 'one\ntwo\n')).toBe('');
+  });
+
+  it('writes hunks with headers and context', () => {
+    const a = ['1', '2', '3', '4', '5', '6', '7', '8', '9', '10', ''].join('\n');
+    const b = ['1', 'two', '3', '4', '5', '6', '7', '8', '9', '10', '11', ''].join('\n');
+    expect(unifiedDiff(a, b, { fromLabel: 'old', toLabel: 'new', context: 1 })).toBe(
+      '--- old\n+++ new\n@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n@@ -10,1 +10,2 @@\n 10\n+11\n',
+    );
+  });
+
+  it('merges changes whose context overlaps', () => {
+    const diff = unifiedDiff('a\nb\nc\nd\n', 'A\nb\nc\nD\n', { context: 1 });
+    expect(diff.match(/^@@/gm)).toHaveLength(1);
+  });
+
+  it('diffs from empty text', () => {
+    expect(unifiedDiff('', 'x\n')).toBe('--- a\n+++ b\n@@ -0,0 +1,1 @@\n+x\n');
+  });
+});
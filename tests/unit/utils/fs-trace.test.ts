@@ -0,0 +1,47 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import fs from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { enableFsTrace, disableFsTrace, summarizeFsOps } from '../../../src/utils/fs-trace.js';
+
+describe('fs-trace', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-fstrace-test-${Date.now()}`);
+    fs.mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    disableFsTrace();
+    fs.rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('records sync and async calls with their paths', async () => {
+    const file = join(dir, 'a.txt');
+    enableFsTrace(join(dir, 'debug.log'));
+    fs.writeFileSync(file, 'x');
+    fs.renameSync(file, join(dir, 'b.txt'));
+    await fs.promises.readFile(join(dir, 'b.txt'), 'utf-8');
+    expect(() => fs.readFileSync(join(dir, 'missing'))).toThrow();
+
+    // Only this test's calls; the runner may touch files meanwhile
+    const traced = disableFsTrace().filter((op) => op.path.startsWith(dir));
+    expect(traced.map((op) => [op.fn, op.kind])).toEqual([
+      ['writeFileSync', 'write'],
+      ['renameSync', 'write'],
+      ['readFile', 'read'],
+      ['readFileSync', 'read'],
+    ]);
+    expect(traced[1].path).toBe(`${file} -> ${join(dir, 'b.txt')}`);
+    expect(traced[3].error).toBe('ENOENT');
+    expect(summarizeFsOps(traced).write.count).toBe(2);
+  });
+
+  it('stops recording once disabled', () => {
+    enableFsTrace(join(dir, 'debug.log'));
+    disableFsTrace();
+    fs.writeFileSync(join(dir, 'a.txt'), 'x');
+    expect(disableFsTrace().filter((op) => op.path.startsWith(dir))).toEqual([]);
+  });
+});
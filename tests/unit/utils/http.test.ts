@@ -0,0 +1,68 @@
+import { describe, it, expect, afterEach } from 'vitest';
+import { createServer } from 'node:http';
+import type { AddressInfo } from 'node:net';
+import { configureHttp, resetHttp, timeoutFor, tlsHint, childEnv, httpFetch, fetchWithCredentials } from '../../../src/utils/http.js';
+
+describe('http client', () => {
+  afterEach(() => resetHttp());
+
+  it('applies per-host timeouts over the default', () => {
+    configureHttp({ timeoutMs: 10_000, hostTimeouts: { 'nexus.acme.io': 120_000 } });
+    expect(timeoutFor('https://nexus.acme.io/repo/x.tar.gz')).toBe(120_000);
+    expect(timeoutFor('https://github.com/acme/repo')).toBe(10_000);
+  });
+
+  it('explains untrusted certificates', () => {
+    expect(tlsHint('npm error code SELF_SIGNED_CERT_IN_CHAIN')).toMatch(/http\.ca_bundle/);
+    expect(tlsHint('unable to get local issuer certificate')).toMatch(/http\.ca_bundle/);
+    expect(tlsHint('ECONNREFUSED')).toBeNull();
+  });
+
+  it('passes the CA bundle to child processes', () => {
+    configureHttp({ caBundle: '/etc/ssl/corp.pem' });
+    expect(childEnv({ PATH: '/bin' })).toEqual({ PATH: '/bin', NODE_EXTRA_CA_CERTS: '/etc/ssl/corp.pem' });
+  });
+
+  it('fetches, and names the cause of a failure', async () => {
+    const server = createServer((_req, res) => res.end('hi'));
+    await new Promise<void>((r) => server.listen(0, '127.0.0.1', r));
+    try {
+      const { port } = server.address() as AddressInfo;
+      expect(await (await httpFetch(`http://127.0.0.1:${port}/`)).text()).toBe('hi');
+
+      configureHttp({ caBundle: '/nonexistent/ca.pem' });
+      await expect(httpFetch(`http://127.0.0.1:${port}/`)).rejects.toThrow(/Cannot read CA bundle/);
+    } finally {
+      server.close();
+    }
+  });
+
+  it('drops credentials when a redirect leaves the origin', async () => {
+    const seen: Record<string, string | undefined> = {};
+    const other = createServer((req, res) => {
+      seen.other = req.headers.authorization;
+      res.end('moved');
+    });
+    await new Promise<void>((r) => other.listen(0, '127.0.0.1', r));
+    const otherUrl = `http://localhost:${(other.address() as AddressInfo).port}/file`;
+    const origin = createServer((req, res) => {
+      if (req.url === '/start') {
+        seen.start = req.headers.authorization;
+        res.writeHead(302, { location: '/same' }).end();
+      } else {
+        seen.same = req.headers.authorization;
+        res.writeHead(302, { location: otherUrl }).end();
+      }
+    });
+    await new Promise<void>((r) => origin.listen(0, '127.0.0.1', r));
+    try {
+      const { port } = origin.address() as AddressInfo;
+      const res = await fetchWithCredentials(`http://127.0.0.1:${port}/start`, { Authorization: 'Bearer s3cret' });
+      expect(await res.text()).toBe('moved');
+      expect(seen).toEqual({ start: 'Bearer s3cret', same: 'Bearer s3cret', other: undefined });
+    } finally {
+      origin.close();
+      other.close();
+    }
+  });
+});
@@ -0,0 +1,73 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { parseInputArgs, readInputFile, mergeInputs, normalizeInputs, validateInputs } from '../../../src/utils/input-parser.js';
+import type { InputField } from '../../../src/types/manifest.js';
+
+const schema: InputField[] = [
+  { name: 'diff', type: 'string', required: true },
+  { name: 'days', type: 'number', default: 30 },
+  { name: 'labels', type: 'array', default: ['bug'] },
+];
+
+describe('input parsing', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-inputs-test-${Date.now()}`);
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('parses key=value flags', () => {
+    expect(parseInputArgs(['a=1', 'b=x=y'])).toEqual({ a: '1', b: 'x=y' });
+    expect(() => parseInputArgs(['a'])).toThrow(/Expected key=value/);
+  });
+
+  it('reads YAML and JSON input files, passing non-strings as JSON', () => {
+    writeFileSync(join(dir, 'in.yaml'), 'diff: |\n  line one\n  line two\ndays: 7\nmeta:\n  team: api\n');
+    writeFileSync(join(dir, 'in.json'), '{"diff": "x", "strict": true}');
+    writeFileSync(join(dir, 'list.yaml'), '- a\n- b\n');
+
+    expect(readInputFile(join(dir, 'in.yaml'))).toEqual({ diff: 'line one\nline two\n', days: '7', meta: '{"team":"api"}' });
+    expect(readInputFile(join(dir, 'in.json'))).toEqual({ diff: 'x', strict: 'true' });
+    expect(() => readInputFile(join(dir, 'list.yaml'))).toThrow(/must hold a mapping/);
+    expect(() => readInputFile(join(dir, 'missing.yaml'))).toThrow(/Cannot read input file/);
+  });
+
+  it('merges flags over the file over manifest defaults', () => {
+    const merged = mergeInputs(
+      { flags: { days: '1' }, file: { days: '7', diff: 'from file', extra: 'kept' } },
+      schema,
+    );
+    expect(merged).toEqual({ diff: 'from file', days: '1', labels: '["bug"]', extra: 'kept' });
+    expect(validateInputs(mergeInputs({}, schema), schema)).toEqual(['Missing required input: diff']);
+  });
+
+  it('passes stdin as the named input', () => {
+    const stdin = { name: 'diff', value: 'piped\n' };
+    expect(mergeInputs({ file: { diff: 'from file' }, stdin }, schema).diff).toBe('piped\n');
+    expect(() => mergeInputs({ flags: { diff: 'x' }, stdin }, schema)).toThrow(/both with -i and --stdin-input/);
+  });
+
+  it('collects a repeated array input and wraps a single value', () => {
+    expect(parseInputArgs(['labels=a', 'labels=b', 'diff=x', 'diff=y'], schema)).toEqual({ labels: '["a","b"]', diff: 'y' });
+    expect(mergeInputs({ flags: { labels: 'solo' } }, schema).labels).toBe('["solo"]');
+  });
+
+  it('resolves file inputs and reports missing files, hiding secret paths', () => {
+    writeFileSync(join(dir, 'spec.yaml'), 'a: 1\n');
+    const files: InputField[] = [
+      { name: 'spec', type: 'file' },
+      { name: 'key', type: 'file', secret: true },
+    ];
+    const inputs = normalizeInputs({ spec: 'spec.yaml', key: 'id_rsa' }, files, dir);
+    expect(inputs.spec).toBe(join(dir, 'spec.yaml'));
+    expect(validateInputs(inputs, files)).toEqual(['Input key: file not found']);
+    expect(validateInputs({ spec: join(dir, 'nope') }, files)).toEqual([`Input spec: file not found: ${join(dir, 'nope')}`]);
+  });
+});
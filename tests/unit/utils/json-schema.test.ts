@@ -0,0 +1,54 @@
+import { describe, it, expect } from 'vitest';
+import { validateJson } from '../../../src/utils/json-schema.js';
+
+describe('validateJson', () => {
+  const schema = {
+    type: 'object',
+    required: ['commits', 'total'],
+    additionalProperties: false,
+    properties: {
+      total: { type: 'integer', minimum: 0 },
+      commits: { type: 'array', items: { $ref: '#/$defs/commit' } },
+      status: { enum: ['ok', 'partial'] },
+    },
+    $defs: {
+      commit: { type: 'object', required: ['sha'], properties: { sha: { type: 'string', pattern: '^[0-9a-f]{7,40}$' } } },
+    },
+  };
+
+  it('accepts conforming values', () => {
+    expect(validateJson({ total: 1, commits: [{ sha: 'abc1234' }], status: 'ok' }, schema)).toEqual([]);
+  });
+
+  it('reports each violation with its path', () => {
+    expect(validateJson({ total: -1.5, commits: [{ sha: 'abc1234' }, { sha: 'XYZ' }, {}], extra: 1 }, schema)).toEqual([
+      'total: expected integer, got number',
+      'commits.1.sha: does not match /^[0-9a-f]{7,40}$/',
+      'commits.2.sha: required',
+      'extra: not allowed',
+    ]);
+    expect(validateJson([], schema)).toEqual(['.: expected object, got array']);
+  });
+
+  it('checks combinators', () => {
+    const either = { oneOf: [{ type: 'string' }, { type: 'number' }] };
+    expect(validateJson('x', either)).toEqual([]);
+    expect(validateJson(true, either)).toEqual(['.: matches 0 of oneOf, expected exactly 1']);
+    expect(validateJson(3, { anyOf: [{ minimum: 5 }, { maximum: 1 }] })).toEqual(['.: matches none of anyOf']);
+    expect(validateJson('a', { not: { const: 'a' } })).toEqual(['.: matches a schema under not']);
+  });
+
+  it('checks multipleOf and property counts', () => {
+    expect(validateJson(7, { multipleOf: 2 })).toEqual(['.: not a multiple of 2']);
+    expect(validateJson({}, { minProperties: 1 })).toEqual(['.: fewer than 1 properties']);
+    expect(validateJson({ a: 1, b: 2 }, { maxProperties: 1 })).toEqual(['.: more than 1 properties']);
+  });
+
+  it('rejects keywords it does not enforce instead of ignoring them', () => {
+    expect(validateJson('x', { title: 'T', description: 'd', format: 'email', type: 'string' })).toEqual([]);
+    expect(() => validateJson({}, { properties: { a: { if: { type: 'string' } } } })).toThrow(
+      'Unsupported keyword "if" at #/properties/a',
+    );
+    expect(() => validateJson([], { items: [{ type: 'string' }] })).toThrow(/tuple form of "items"/);
+  });
+});
@@ -0,0 +1,60 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { readFileSync, readdirSync, rmSync, existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { logger, configureLogging, resetLogging, formatEntry, LOG_FILE } from '../../../src/utils/log.js';
+
+describe('log', () => {
+  let dir: string;
+  let stderr: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-log-test-${Date.now()}`);
+    stderr = vi.spyOn(process.stderr, 'write').mockImplementation(() => true);
+  });
+
+  afterEach(() => {
+    stderr.mockRestore();
+    resetLogging();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('shows entries at or above the console level', () => {
+    configureLogging({ level: 'warn' });
+    const log = logger('test');
+    log.info('quiet');
+    log.warn('loud');
+    expect(stderr).toHaveBeenCalledTimes(1);
+    expect(String(stderr.mock.calls[0][0])).toContain('[test] loud');
+  });
+
+  it('writes JSON lines to stderr with --log-json', () => {
+    configureLogging({ level: 'debug', json: true });
+    logger('test').debug('hello', { n: 1 });
+    const entry = JSON.parse(String(stderr.mock.calls[0][0]));
+    expect(entry).toMatchObject({ level: 'debug', scope: 'test', msg: 'hello', n: 1 });
+  });
+
+  it('logs info and above to the file, debug only when verbose', () => {
+    configureLogging({ level: 'error', dir });
+    const log = logger('test');
+    log.debug('skipped');
+    log.info('kept');
+    const lines = readFileSync(join(dir, LOG_FILE), 'utf-8').trim().split('\n');
+    expect(lines.map((l) => JSON.parse(l).msg)).toEqual(['kept']);
+  });
+
+  it('rotates the file and keeps maxFiles generations', () => {
+    configureLogging({ level: 'error', dir, maxBytes: 10, maxFiles: 2 });
+    const log = logger('test');
+    for (let i = 0; i < 5; i++) log.info(`entry ${i}`);
+    expect(readdirSync(dir).sort()).toEqual([LOG_FILE, `${LOG_FILE}.1`, `${LOG_FILE}.2`]);
+    expect(existsSync(join(dir, `${LOG_FILE}.3`))).toBe(false);
+    expect(JSON.parse(readFileSync(join(dir, LOG_FILE), 'utf-8')).msg).toBe('entry 4');
+  });
+
+  it('formats fields after the message', () => {
+    const line = formatEntry({ time: '2026-01-01T10:00:00.000Z', level: 'info', scope: 'linker', msg: 'generated', tool: 'claude', created: 2 });
+    expect(line).toContain('[linker] generated tool=claude created=2');
+  });
+});
@@ -0,0 +1,43 @@
+import { describe, it, expect } from 'vitest';
+import { merge3, hasConflictMarkers, mergeDriverFor } from '../../../src/utils/merge.js';
+
+const labels = { ours: 'ours', base: 'base', theirs: 'theirs' };
+
+describe('merge3', () => {
+  const base = 'one\ntwo\nthree\nfour\n';
+
+  it('combines edits to different regions', () => {
+    const ours = 'one\nTWO\nthree\nfour\n';
+    const theirs = 'one\ntwo\nthree\nFOUR\nfive\n';
+    expect(merge3(base, ours, theirs, labels)).toEqual({
+      text: 'one\nTWO\nthree\nFOUR\nfive\n',
+      conflicts: 0,
+    });
+  });
+
+  it('takes identical edits once', () => {
+    const both = 'one\n2\nthree\nfour\n';
+    expect(merge3(base, both, both, labels)).toEqual({ text: both, conflicts: 0 });
+  });
+
+  it('marks overlapping edits as conflicts', () => {
+    const result = merge3(base, 'one\nmine\nthree\nfour\n', 'one\nyours\nthree\nfour\n', labels);
+    expect(result.conflicts).toBe(1);
+    expect(result.text).toBe(
+      'one\n<<<<<<< ours\nmine\n||||||| base\ntwo\n=======\nyours\n>>>>>>> theirs\nthree\nfour\n',
+    );
+    expect(hasConflictMarkers(result.text)).toBe(true);
+  });
+
+  it('handles deletions and a missing final newline', () => {
+    const ours = 'one\nthree\nfour\n';
+    const theirs = 'one\ntwo\nthree\nfour';
+    expect(merge3(base, ours, theirs, labels)).toEqual({ text: 'one\nthree\nfour', conflicts: 0 });
+    expect(merge3(base, ours, base, labels)).toEqual({ text: ours, conflicts: 0 });
+  });
+
+  it('defaults to the built-in driver', () => {
+    expect(mergeDriverFor('')).toBe(merge3);
+    expect(mergeDriverFor('builtin')).toBe(merge3);
+  });
+});
@@ -0,0 +1,97 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { writeFileSync, mkdirSync, rmSync, existsSync, readFileSync, statSync, chmodSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createLink, checkLink, removeLink, isManagedLink, copyToClipboard } from '../../../src/utils/platform.js';
+
+describe('managed links', () => {
+  let dir: string;
+  let target: string;
+
+  beforeEach(() => {
+    dir = join(tmpdir(), `agentx-links-test-${Date.now()}`);
+    target = join(dir, 'installed', 'context', 'owasp');
+    mkdirSync(join(target, 'docs'), { recursive: true });
+    writeFileSync(join(target, 'docs', 'top10.md'), 'A01');
+  });
+
+  afterEach(() => rmSync(dir, { recursive: true, force: true }));
+
+  it('symlinks by default', () => {
+    const link = join(dir, 'owasp');
+    expect(createLink(target, link)).toBe('symlink');
+    expect(checkLink(link)).toEqual({ kind: 'symlink', target, health: 'valid' });
+  });
+
+  it('copies with a sidecar and detects drift', () => {
+    const link = join(dir, 'owasp');
+    expect(createLink(target, link, { copy: true })).toBe('copy');
+    expect(readFileSync(join(link, 'docs', 'top10.md'), 'utf-8')).toBe('A01');
+    expect(readFileSync(`${link}.target`, 'utf-8')).toBe(target);
+    expect(checkLink(link)?.health).toBe('valid');
+
+    writeFileSync(join(target, 'docs', 'top10.md'), 'A01 (2025)');
+    expect(checkLink(link)).toEqual({ kind: 'copy', target, health: 'drifted' });
+
+    createLink(target, link, { copy: true });
+    expect(checkLink(link)?.health).toBe('valid');
+
+    rmSync(target, { recursive: true });
+    expect(checkLink(link)?.health).toBe('broken');
+  });
+
+  it('keeps a copy that still matches', () => {
+    const link = join(dir, 'owasp');
+    createLink(target, link, { copy: true });
+    const before = statSync(join(link, 'docs', 'top10.md')).mtimeMs;
+    createLink(target, link, { copy: true });
+    expect(statSync(join(link, 'docs', 'top10.md')).mtimeMs).toBe(before);
+  });
+
+  it('removes links and copies but not other directories', () => {
+    const link = join(dir, 'owasp');
+    createLink(target, link, { copy: true });
+    removeLink(link);
+    expect(existsSync(link) || existsSync(`${link}.target`)).toBe(false);
+
+    const own = join(dir, 'notes');
+    mkdirSync(own);
+    expect(isManagedLink(own)).toBe(false);
+    expect(() => removeLink(own)).toThrow(/did not create/);
+    expect(existsSync(own)).toBe(true);
+  });
+});
+
+describe.skipIf(process.platform !== 'linux')('clipboard', () => {
+  let bin: string;
+  let prevPath: string | undefined;
+
+  beforeEach(() => {
+    bin = join(tmpdir(), `agentx-clipboard-test-${Date.now()}`);
+    mkdirSync(bin, { recursive: true });
+    prevPath = process.env.PATH;
+    process.env.PATH = bin;
+  });
+
+  afterEach(() => {
+    process.env.PATH = prevPath;
+    rmSync(bin, { recursive: true, force: true });
+  });
+
+  function fakeTool(name: string, script: string): void {
+    writeFileSync(join(bin, name), `#!/bin/sh\n${script}\n`);
+    chmodSync(join(bin, name), 0o755);
+  }
+
+  it('falls through to the first tool that works', () => {
+    // No Wayland session: wl-copy fails, xclip takes over
+    fakeTool('wl-copy', 'exit 1');
+    fakeTool('xclip', `/bin/cat > ${join(bin, 'clipboard')}`);
+    copyToClipboard('composed prompt');
+    expect(readFileSync(join(bin, 'clipboard'), 'utf-8')).toBe('composed prompt');
+  });
+
+  it('names the tools it tried when none works', () => {
+    expect(() => copyToClipboard('x')).toThrow('No clipboard tool available (tried: wl-copy, xclip, xsel)');
+  });
+});
@@ -0,0 +1,48 @@
+import { describe, it, expect, afterEach } from 'vitest';
+import {
+  addSecret,
+  compilePatterns,
+  configureRedaction,
+  isSensitiveKey,
+  redactValue,
+  resetRedaction,
+  scrubFields,
+  scrubText,
+} from '../../../src/utils/redact.js';
+
+describe('redact', () => {
+  afterEach(() => resetRedaction());
+
+  it('treats secret-looking names as sensitive', () => {
+    expect(isSensitiveKey('GITHUB_TOKEN')).toBe(true);
+    expect(isSensitiveKey('db_password')).toBe(true);
+    expect(isSensitiveKey('REGION')).toBe(false);
+    expect(redactValue('API_KEY', 'abcdef123')).toBe('abcd***');
+    expect(redactValue('API_KEY', 'ab')).toBe('***');
+    expect(redactValue('REGION', 'us-east-1')).toBe('us-east-1');
+  });
+
+  it('applies configured patterns to names and text', () => {
+    configureRedaction({ patterns: compilePatterns(['^PAT_', 'ghp_[A-Za-z0-9]+']) });
+    expect(isSensitiveKey('PAT_ACME')).toBe(true);
+    expect(scrubText('cloning with ghp_abc123 and ghp_def456')).toBe('cloning with *** and ***');
+  });
+
+  it('rejects invalid patterns', () => {
+    expect(() => compilePatterns(['('])).toThrow(/Invalid redaction pattern "\("/);
+  });
+
+  it('masks registered secrets wherever they appear', () => {
+    addSecret('s3cr3t-value');
+    addSecret('ab');
+    expect(scrubText('curl -H "X-Auth: s3cr3t-value" (ab)')).toBe('curl -H "X-Auth: ***" (ab)');
+    expect(redactValue('HOST_HEADER', 's3cr3t-value')).toBe('s3cr***');
+  });
+
+  it('scrubs nested fields, redacting sensitive keys', () => {
+    addSecret('hunter22');
+    expect(
+      scrubFields({ skill: 'a/b', auth: { token: 'xyz12345' }, args: ['--pw', 'hunter22'], exitCode: 0 }),
+    ).toEqual({ skill: 'a/b', auth: { token: 'xyz1***' }, args: ['--pw', '***'], exitCode: 0 });
+  });
+});
@@ -0,0 +1,31 @@
+import { describe, it, expect } from 'vitest';
+import { parseSize, parseDuration, parseCount, formatBytes } from '../../../src/utils/units.js';
+
+describe('units', () => {
+  it('parses sizes in binary multiples', () => {
+    expect(parseSize('512')).toBe(512);
+    expect(parseSize('10MB')).toBe(10 * 1024 * 1024);
+    expect(parseSize('1.5 KiB')).toBe(1536);
+    expect(() => parseSize('ten megs')).toThrow(/Invalid size/);
+  });
+
+  it('parses durations', () => {
+    expect(parseDuration('30m')).toBe(1_800_000);
+    expect(parseDuration('7d')).toBe(604_800_000);
+    expect(() => parseDuration('7 days')).toThrow(/Invalid duration/);
+  });
+
+  it('parses counts', () => {
+    expect(parseCount('20', '--iterations')).toBe(20);
+    expect(parseCount('0', '--warmup', 0)).toBe(0);
+    expect(() => parseCount('0', '--iterations')).toThrow(/Invalid --iterations "0"/);
+    expect(() => parseCount('ten', '--iterations')).toThrow(/whole number/);
+    expect(() => parseCount('1.5', '--iterations')).toThrow(/whole number/);
+    expect(() => parseCount('-1', '--warmup', 0)).toThrow(/whole number/);
+  });
+
+  it('formats bytes', () => {
+    expect(formatBytes(100)).toBe('100 B');
+    expect(formatBytes(2048)).toBe('2.0 KiB');
+  });
+});
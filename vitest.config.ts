@@ -5,5 +5,8 @@ export default defineConfig({
     globals: false,
     root: '.',
     include: ['tests/**/*.test.ts'],
+    benchmark: {
+      include: ['tests/bench/**/*.bench.ts'],
+    },
   },
 });